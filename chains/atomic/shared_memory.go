@@ -10,7 +10,10 @@ import (
 	"github.com/ava-labs/avalanchego/utils"
 )
 
-var _ SharedMemory = (*sharedMemory)(nil)
+var (
+	_ SharedMemory   = (*sharedMemory)(nil)
+	_ OutboundReader = (*sharedMemory)(nil)
+)
 
 type Requests struct {
 	RemoveRequests [][]byte   `serialize:"true"`
@@ -55,6 +58,18 @@ type SharedMemory interface {
 	Apply(requests map[ids.ID]*Requests, batches ...database.Batch) error
 }
 
+// OutboundReader is implemented by shared memory that can report whether the
+// values a chain sent to its peers are still in shared memory, which is the
+// case until a peer removes them.
+type OutboundReader interface {
+	// HasOutbound reports, for each of [keys], whether the value sent to
+	// [peerChainID] under it is in shared memory.
+	//
+	// Invariant: HasOutbound guarantees that the resulting array is the same
+	//            length as keys.
+	HasOutbound(peerChainID ids.ID, keys [][]byte) ([]bool, error)
+}
+
 // sharedMemory provides the API for a blockchain to interact with shared memory
 // of another blockchain
 type sharedMemory struct {
@@ -82,6 +97,29 @@ func (sm *sharedMemory) Get(peerChainID ids.ID, keys [][]byte) ([][]byte, error)
 	return values, nil
 }
 
+func (sm *sharedMemory) HasOutbound(peerChainID ids.ID, keys [][]byte) ([]bool, error) {
+	sharedID := sharedID(peerChainID, sm.thisChainID)
+	db := sm.m.GetSharedDatabase(sm.m.db, sharedID)
+	defer sm.m.ReleaseSharedDatabase(sharedID)
+
+	s := state{
+		valueDB: outbound.getValueDB(sm.thisChainID, peerChainID, db),
+	}
+
+	has := make([]bool, len(keys))
+	for i, key := range keys {
+		_, err := s.Value(key)
+		switch err {
+		case nil:
+			has[i] = true
+		case database.ErrNotFound:
+		default:
+			return nil, err
+		}
+	}
+	return has, nil
+}
+
 func (sm *sharedMemory) Indexed(
 	peerChainID ids.ID,
 	traits [][]byte,
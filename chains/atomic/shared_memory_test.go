@@ -6,6 +6,8 @@ package atomic
 import (
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
@@ -29,3 +31,40 @@ func TestSharedMemory(t *testing.T) {
 		test(t, chainID0, chainID1, sm0, sm1, testDB)
 	}
 }
+
+func TestSharedMemoryHasOutbound(t *testing.T) {
+	require := require.New(t)
+
+	chainID0 := ids.GenerateTestID()
+	chainID1 := ids.GenerateTestID()
+
+	m := NewMemory(memdb.New())
+	sm0 := m.NewSharedMemory(chainID0)
+	sm1 := m.NewSharedMemory(chainID1)
+
+	require.NoError(sm0.Apply(map[ids.ID]*Requests{chainID1: {
+		PutRequests: []*Element{
+			{Key: []byte{0}, Value: []byte{0}},
+			{Key: []byte{1}, Value: []byte{1}},
+		},
+	}}))
+
+	reader, ok := sm0.(OutboundReader)
+	require.True(ok)
+	has, err := reader.HasOutbound(chainID1, [][]byte{{0}, {1}, {2}})
+	require.NoError(err)
+	require.Equal([]bool{true, true, false}, has)
+
+	// Values are no longer outbound once the peer removes them.
+	require.NoError(sm1.Apply(map[ids.ID]*Requests{chainID0: {
+		RemoveRequests: [][]byte{{0}},
+	}}))
+	has, err = reader.HasOutbound(chainID1, [][]byte{{0}, {1}})
+	require.NoError(err)
+	require.Equal([]bool{false, true}, has)
+
+	// Values sent to other chains aren't outbound to [chainID1].
+	has, err = reader.HasOutbound(ids.GenerateTestID(), [][]byte{{1}})
+	require.NoError(err)
+	require.Equal([]bool{false}, has)
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import "reflect"
+
+// DefaultArenaSlabLen is the default number of values allocated at once for
+// each type placed in an Arena.
+const DefaultArenaSlabLen = 1024
+
+// Arena is a bump allocator for values created while unmarshalling.
+//
+// Values of the same type are carved out of shared slabs, so decoding many
+// values results in few, large allocations rather than many small ones. The
+// memory held by an Arena is released wholesale once the Arena, and every
+// value allocated from it, is no longer referenced.
+//
+// An Arena isn't safe for concurrent use. A nil *Arena is valid and allocates
+// every value individually.
+type Arena struct {
+	slabLen int
+	// type --> slab that values of the type are currently allocated from
+	slabs map[reflect.Type]*slab
+}
+
+type slab struct {
+	values reflect.Value
+	next   int
+}
+
+// NewArena returns an arena that allocates [slabLen] values of a type at a
+// time. If [slabLen] is 0, [DefaultArenaSlabLen] is used.
+func NewArena(slabLen int) *Arena {
+	if slabLen <= 0 {
+		slabLen = DefaultArenaSlabLen
+	}
+	return &Arena{
+		slabLen: slabLen,
+		slabs:   make(map[reflect.Type]*slab),
+	}
+}
+
+// New returns a pointer to a new zero value of type [t].
+func (a *Arena) New(t reflect.Type) reflect.Value {
+	if a == nil {
+		return reflect.New(t)
+	}
+	return a.alloc(t, 1).Index(0).Addr()
+}
+
+// MakeSlice returns a new zeroed slice of type [sliceType] with length and
+// capacity [length].
+func (a *Arena) MakeSlice(sliceType reflect.Type, length int) reflect.Value {
+	if a == nil {
+		return reflect.MakeSlice(sliceType, length, length)
+	}
+	return a.alloc(sliceType.Elem(), length).Convert(sliceType)
+}
+
+// Reset drops the arena's references to its slabs. Values previously
+// allocated from the arena remain valid.
+func (a *Arena) Reset() {
+	a.slabs = make(map[reflect.Type]*slab)
+}
+
+// alloc returns a []t with length and capacity [n].
+func (a *Arena) alloc(t reflect.Type, n int) reflect.Value {
+	if n > a.slabLen {
+		return reflect.MakeSlice(reflect.SliceOf(t), n, n)
+	}
+
+	s, ok := a.slabs[t]
+	if !ok || s.next+n > s.values.Len() {
+		s = &slab{
+			values: reflect.MakeSlice(reflect.SliceOf(t), a.slabLen, a.slabLen),
+		}
+		a.slabs[t] = s
+	}
+
+	// Limit the capacity so that appending to the returned slice can't
+	// overwrite values allocated later.
+	values := s.values.Slice3(s.next, s.next+n, s.next+n)
+	s.next += n
+	return values
+}
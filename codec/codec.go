@@ -15,6 +15,11 @@ var (
 	ErrDoesNotImplementInterface = errors.New("does not implement interface")
 	ErrUnexportedField           = errors.New("unexported field")
 	ErrExtraSpace                = errors.New("trailing buffer space")
+	ErrNonCanonicalEncoding      = errors.New("non-canonical encoding")
+	ErrDuplicateMapKey           = errors.New("map keys have the same encoding")
+	ErrTimeOutOfRange            = errors.New("time can't be represented as unix nanoseconds")
+	ErrMaxDepth                  = errors.New("max nesting depth exceeded")
+	ErrAllocationLimit           = errors.New("allocation limit exceeded")
 )
 
 // Codec marshals and unmarshals
@@ -22,6 +27,35 @@ type Codec interface {
 	MarshalInto(interface{}, *wrappers.Packer) error
 	Unmarshal([]byte, interface{}) error
 
+	// UnmarshalFrom unmarshals the next value in the packer, which may
+	// hold more bytes afterwards.
+	UnmarshalFrom(*wrappers.Packer, interface{}) error
+
+	// UnmarshalWithArena is the same as Unmarshal, but allocates the values
+	// created while unmarshalling from [arena].
+	UnmarshalWithArena([]byte, interface{}, *Arena) error
+
 	// Returns the size, in bytes, of [value] when it's marshaled
 	Size(value interface{}) (int, error)
 }
+
+// Marshaler is implemented by types that marshal themselves rather than being
+// marshalled field by field.
+//
+// A type is only marshalled with its Marshaler if a pointer to it also
+// implements Unmarshaler.
+type Marshaler interface {
+	// CodecSize returns the number of bytes MarshalCodec packs.
+	CodecSize() (int, error)
+
+	// MarshalCodec packs the value into the packer.
+	MarshalCodec(*wrappers.Packer) error
+}
+
+// Unmarshaler is implemented by pointers to types that unmarshal themselves.
+type Unmarshaler interface {
+	// UnmarshalCodec unpacks the value from the packer. It must unpack
+	// exactly the bytes that MarshalCodec packed, and should fail if they
+	// aren't the canonical encoding of the value.
+	UnmarshalCodec(*wrappers.Packer) error
+}
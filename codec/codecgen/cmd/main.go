@@ -0,0 +1,46 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/avalanchego/codec/codecgen"
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/codec/reflectcodec"
+	"github.com/ava-labs/avalanchego/utils/perms"
+)
+
+func main() {
+	var (
+		config codecgen.Config
+		output string
+	)
+	rootCmd := &cobra.Command{
+		Use:   "codecgen",
+		Short: "Generate reflection-free codec marshalling code for the structs of a package",
+		RunE: func(*cobra.Command, []string) error {
+			src, err := codecgen.Generate(config)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(filepath.Join(config.Dir, output), src, perms.ReadWrite)
+		},
+	}
+	rootCmd.Flags().StringVar(&config.Dir, "dir", ".", "Directory of the package holding the types")
+	rootCmd.Flags().StringSliceVar(&config.Types, "types", nil, "[optional] Types to generate code for. Defaults to every struct with a serialized field")
+	rootCmd.Flags().StringVar(&config.TagName, "tag", reflectcodec.DefaultTagName, "Struct tag that marks serialized fields")
+	rootCmd.Flags().Uint32Var(&config.MaxSliceLen, "max-slice-len", linearcodec.DefaultMaxSliceLength, "Maximum length of slices without a len tag")
+	rootCmd.Flags().StringVar(&output, "output", "codecgen.go", "Name of the generated file, in the directory of the package")
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "codecgen failed: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,796 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package codecgen generates reflection-free implementations of
+// [codec.Marshaler] and [codec.Unmarshaler] for structs, from the same struct
+// tags that reflectcodec serializes them by.
+//
+// The generated code produces the same bytes as reflectcodec does for a codec
+// that isn't sparse and that is configured with the same tag and maximum slice
+// length. Once a type has generated methods, reflectcodec marshals it with
+// them, and linearcodec skips reflection entirely when it's marshalled or
+// unmarshalled directly.
+//
+// Serialized fields may hold booleans, integers, strings, byte slices, byte
+// arrays, slices and arrays of supported types, and types that implement
+// [codec.Marshaler] and [codec.Unmarshaler], which includes other generated
+// types. Fields of other types, such as pointers, interfaces and maps, aren't
+// supported; structs holding them must keep using reflection.
+package codecgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/codec/reflectcodec"
+)
+
+// GeneratedHeader marks the files written by codecgen. Such files are ignored
+// when parsing the package that they're generated for.
+const GeneratedHeader = "// Code generated by codecgen. DO NOT EDIT."
+
+const (
+	codecImportPath    = "github.com/ava-labs/avalanchego/codec"
+	wrappersImportPath = "github.com/ava-labs/avalanchego/utils/wrappers"
+	idsImportPath      = "github.com/ava-labs/avalanchego/ids"
+	localImportPrefix  = "github.com/ava-labs/avalanchego"
+)
+
+var (
+	errNoPackage        = errors.New("no package found")
+	errMultiplePackages = errors.New("multiple packages found")
+	errUnknownType      = errors.New("unknown type")
+	errNotStruct        = errors.New("type isn't a struct")
+	errNoSerializedType = errors.New("no serialized types found")
+	errUnexportedField  = errors.New("unexported serialized field")
+	errNullableField    = errors.New("nullable fields aren't supported")
+	errOmitEmptyField   = errors.New("omitempty fields aren't supported")
+	errUnsupportedType  = errors.New("unsupported field type")
+	errInvalidMaxLen    = errors.New("invalid maximum slice length")
+
+	// Byte arrays defined outside of the package being generated.
+	knownByteArrays = map[string]map[string]bool{
+		idsImportPath: {
+			"ID":      true,
+			"ShortID": true,
+			"NodeID":  true,
+		},
+	}
+
+	// How the basic types are packed, by the name of the type.
+	basicTypes = map[string]basicType{
+		"bool":   {sizeConst: "wrappers.BoolLen", packer: "Bool", packed: "bool"},
+		"uint8":  {sizeConst: "wrappers.ByteLen", packer: "Byte", packed: "uint8"},
+		"byte":   {sizeConst: "wrappers.ByteLen", packer: "Byte", packed: "uint8"},
+		"int8":   {sizeConst: "wrappers.ByteLen", packer: "Byte", packed: "uint8"},
+		"uint16": {sizeConst: "wrappers.ShortLen", packer: "Short", packed: "uint16"},
+		"int16":  {sizeConst: "wrappers.ShortLen", packer: "Short", packed: "uint16"},
+		"uint32": {sizeConst: "wrappers.IntLen", packer: "Int", packed: "uint32"},
+		"int32":  {sizeConst: "wrappers.IntLen", packer: "Int", packed: "uint32"},
+		"uint64": {sizeConst: "wrappers.LongLen", packer: "Long", packed: "uint64"},
+		"int64":  {sizeConst: "wrappers.LongLen", packer: "Long", packed: "uint64"},
+		"string": {sizeConst: "wrappers.ShortLen", packer: "Str", packed: "string"},
+	}
+)
+
+// Config describes the code to generate.
+type Config struct {
+	// Dir is the directory of the package holding the types.
+	Dir string
+	// Types to generate methods for. If empty, methods are generated for
+	// every struct in the package with a serialized field.
+	Types []string
+	// TagName marks the serialized fields. Defaults to
+	// [reflectcodec.DefaultTagName].
+	TagName string
+	// MaxSliceLen is the maximum length of slices that don't specify their
+	// own with the [reflectcodec.SliceLenTagName] tag. Defaults to
+	// [linearcodec.DefaultMaxSliceLength].
+	MaxSliceLen uint32
+}
+
+// Generate returns the formatted source of a file implementing
+// [codec.Marshaler] and [codec.Unmarshaler] for the configured types.
+func Generate(config Config) ([]byte, error) {
+	if config.TagName == "" {
+		config.TagName = reflectcodec.DefaultTagName
+	}
+	if config.MaxSliceLen == 0 {
+		config.MaxSliceLen = linearcodec.DefaultMaxSliceLength
+	}
+	if config.MaxSliceLen > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: %d", errInvalidMaxLen, config.MaxSliceLen)
+	}
+
+	pkg, err := parsePackage(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	typeNames := config.Types
+	if len(typeNames) == 0 {
+		typeNames = pkg.serializedStructs(config.TagName)
+		if len(typeNames) == 0 {
+			return nil, fmt.Errorf("%w in %s", errNoSerializedType, config.Dir)
+		}
+	}
+
+	g := &generator{
+		config:  config,
+		pkg:     pkg,
+		imports: map[string]string{},
+	}
+	for _, typeName := range typeNames {
+		if err := g.generateType(typeName); err != nil {
+			return nil, fmt.Errorf("couldn't generate %s: %w", typeName, err)
+		}
+	}
+	return g.source()
+}
+
+type basicType struct {
+	// Size of the type when packed
+	sizeConst string
+	// Suffix of the packer methods that (un)pack the type
+	packer string
+	// Type the packer methods (un)pack
+	packed string
+}
+
+type kind int
+
+const (
+	kindBasic kind = iota
+	kindBytes
+	kindByteArray
+	kindSlice
+	kindArray
+	kindMarshaler
+)
+
+// fieldType is a serialized type, resolved to how it's serialized.
+type fieldType struct {
+	// Type as it's written in the source
+	name string
+	kind kind
+	// Set if [kind] is [kindBasic]
+	basic basicType
+	// The name of the basic type, if [kind] is [kindBasic]
+	basicName string
+	// Set if [kind] is [kindSlice] or [kindArray]
+	elem *fieldType
+}
+
+type pkgInfo struct {
+	name  string
+	fset  *token.FileSet
+	types map[string]*ast.TypeSpec
+	// File that declares each type, which determines the imports that the
+	// type refers to
+	files map[string]*ast.File
+	// Type names in the order they're declared
+	order []string
+}
+
+func parsePackage(dir string) (*pkgInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &pkgInfo{
+		fset:  token.NewFileSet(),
+		types: map[string]*ast.TypeSpec{},
+		files: map[string]*ast.File{},
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(pkg.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if isGenerated(file) {
+			continue
+		}
+		switch {
+		case pkg.name == "":
+			pkg.name = file.Name.Name
+		case pkg.name != file.Name.Name:
+			return nil, fmt.Errorf("%w in %s: %s and %s", errMultiplePackages, dir, pkg.name, file.Name.Name)
+		}
+
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				pkg.types[typeSpec.Name.Name] = typeSpec
+				pkg.files[typeSpec.Name.Name] = file
+				pkg.order = append(pkg.order, typeSpec.Name.Name)
+			}
+		}
+	}
+	if pkg.name == "" {
+		return nil, fmt.Errorf("%w in %s", errNoPackage, dir)
+	}
+	return pkg, nil
+}
+
+// isGenerated returns true iff [file] was written by codecgen.
+func isGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			return false
+		}
+		for _, comment := range group.List {
+			if comment.Text == GeneratedHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serializedStructs returns the structs with a field tagged with [tagName], in
+// the order they're declared.
+func (p *pkgInfo) serializedStructs(tagName string) []string {
+	var typeNames []string
+	for _, typeName := range p.order {
+		structType, ok := p.types[typeName].Type.(*ast.StructType)
+		if !ok || p.types[typeName].TypeParams != nil {
+			continue
+		}
+		for _, field := range structType.Fields.List {
+			// Fields with invalid tags are reported when generating the type.
+			if _, ok, err := serializedTag(field, tagName); ok || err != nil {
+				typeNames = append(typeNames, typeName)
+				break
+			}
+		}
+	}
+	return typeNames
+}
+
+// serializedTag returns the parsed [tagName] tag of [field], and whether the
+// field is serialized.
+func serializedTag(field *ast.Field, tagName string) (reflectcodec.SerializeTag, bool, error) {
+	if field.Tag == nil {
+		return reflectcodec.SerializeTag{}, false, nil
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return reflectcodec.SerializeTag{}, false, nil
+	}
+	return reflectcodec.ParseSerializeTag(reflect.StructTag(tag).Get(tagName))
+}
+
+type generator struct {
+	config Config
+	pkg    *pkgInfo
+
+	// Import paths referenced by the generated code, keyed by the name they
+	// are referenced by
+	imports map[string]string
+	usesFmt bool
+
+	buf bytes.Buffer
+	// Number of temporary variables declared by the current function
+	numVars int
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// newVar returns a name that is unique within the current function.
+func (g *generator) newVar(prefix string) string {
+	name := prefix + strconv.Itoa(g.numVars)
+	g.numVars++
+	return name
+}
+
+type field struct {
+	name   string
+	typ    *fieldType
+	maxLen uint32
+	// Maximum length of a string field, or 0 if it isn't limited.
+	maxStrLen uint32
+}
+
+func (g *generator) generateType(typeName string) error {
+	typeSpec, ok := g.pkg.types[typeName]
+	if !ok {
+		return errUnknownType
+	}
+	structType, ok := typeSpec.Type.(*ast.StructType)
+	if !ok || typeSpec.TypeParams != nil {
+		return errNotStruct
+	}
+
+	file := g.pkg.files[typeName]
+	var fields []field
+	for _, astField := range structType.Fields.List {
+		tag, ok, err := serializedTag(astField, g.config.TagName)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if tag.Nullable {
+			return errNullableField
+		}
+		if tag.OmitEmpty {
+			return errOmitEmptyField
+		}
+
+		maxLen := g.config.MaxSliceLen
+		if astField.Tag != nil {
+			rawTag, _ := strconv.Unquote(astField.Tag.Value)
+			lenTag := reflect.StructTag(rawTag).Get(reflectcodec.SliceLenTagName)
+			if newLen, err := strconv.ParseUint(lenTag, 10, 31); err == nil {
+				maxLen = uint32(newLen)
+			}
+		}
+		// The maxLen option can only tighten the limit.
+		if tag.MaxLen != 0 && tag.MaxLen < maxLen {
+			maxLen = tag.MaxLen
+		}
+
+		typ, err := g.resolve(file, astField.Type, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		var maxStrLen uint32
+		if typ.kind == kindBasic && typ.basic.packed == "string" {
+			maxStrLen = tag.MaxLen
+		}
+
+		names := astField.Names
+		if len(names) == 0 {
+			// Embedded fields are named after their type
+			names = []*ast.Ident{embeddedName(astField.Type)}
+		}
+		for _, name := range names {
+			if !name.IsExported() {
+				return fmt.Errorf("%w: %s", errUnexportedField, name.Name)
+			}
+			fields = append(fields, field{
+				name:      name.Name,
+				typ:       typ,
+				maxLen:    maxLen,
+				maxStrLen: maxStrLen,
+			})
+		}
+	}
+
+	g.printf("var (\n")
+	g.printf("_ codec.Marshaler = (*%s)(nil)\n", typeName)
+	g.printf("_ codec.Unmarshaler = (*%s)(nil)\n", typeName)
+	g.printf(")\n\n")
+
+	g.numVars = 0
+	g.printf("func (v *%s) CodecSize() (int, error) {\n", typeName)
+	g.printf("size := 0\n")
+	for _, f := range fields {
+		g.checkStrLen("v."+f.name, f.maxStrLen, "0, ")
+		g.size("v."+f.name, f.typ, f.maxLen)
+	}
+	g.printf("return size, nil\n")
+	g.printf("}\n\n")
+
+	g.numVars = 0
+	g.printf("func (v *%s) MarshalCodec(p *wrappers.Packer) error {\n", typeName)
+	for _, f := range fields {
+		g.checkStrLen("v."+f.name, f.maxStrLen, "")
+		g.marshal("v."+f.name, f.typ, f.maxLen)
+	}
+	g.printf("return p.Err\n")
+	g.printf("}\n\n")
+
+	g.numVars = 0
+	g.printf("func (v *%s) UnmarshalCodec(p *wrappers.Packer) error {\n", typeName)
+	for _, f := range fields {
+		g.unmarshal("v."+f.name, f.typ, f.maxLen)
+		g.checkStrLen("v."+f.name, f.maxStrLen, "")
+	}
+	g.printf("return p.Err\n")
+	g.printf("}\n\n")
+	return nil
+}
+
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel
+	case *ast.Ident:
+		return expr
+	default:
+		return ast.NewIdent("")
+	}
+}
+
+// resolve returns how values of type [expr], written in [file], are
+// serialized. [resolving] holds the local types being resolved, to detect
+// types that are defined in terms of themselves.
+func (g *generator) resolve(file *ast.File, expr ast.Expr, resolving map[string]bool) (*fieldType, error) {
+	name, err := g.typeName(file, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if basic, ok := basicTypes[expr.Name]; ok && expr.Obj == nil {
+			return &fieldType{
+				name:      name,
+				kind:      kindBasic,
+				basic:     basic,
+				basicName: expr.Name,
+			}, nil
+		}
+
+		typeSpec, ok := g.pkg.types[expr.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownType, expr.Name)
+		}
+		if _, ok := typeSpec.Type.(*ast.StructType); ok || resolving[expr.Name] {
+			// Structs must marshal themselves
+			return &fieldType{
+				name: name,
+				kind: kindMarshaler,
+			}, nil
+		}
+
+		resolving[expr.Name] = true
+		defer delete(resolving, expr.Name)
+
+		underlying, err := g.resolve(g.pkg.files[expr.Name], typeSpec.Type, resolving)
+		if err != nil {
+			return nil, err
+		}
+		if underlying.kind == kindMarshaler {
+			return underlying, nil
+		}
+		// Local types are converted to and from their underlying type.
+		resolved := *underlying
+		resolved.name = name
+		return &resolved, nil
+	case *ast.SelectorExpr:
+		pkgIdent, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnsupportedType, name)
+		}
+		if knownByteArrays[importPath(file, pkgIdent.Name)][expr.Sel.Name] {
+			return &fieldType{
+				name: name,
+				kind: kindByteArray,
+			}, nil
+		}
+		// Types defined in other packages must marshal themselves
+		return &fieldType{
+			name: name,
+			kind: kindMarshaler,
+		}, nil
+	case *ast.ArrayType:
+		elem, err := g.resolve(file, expr.Elt, resolving)
+		if err != nil {
+			return nil, err
+		}
+		isByte := elem.kind == kindBasic && (elem.basicName == "uint8" || elem.basicName == "byte")
+		switch {
+		case expr.Len == nil && isByte:
+			return &fieldType{
+				name: name,
+				kind: kindBytes,
+			}, nil
+		case expr.Len == nil:
+			return &fieldType{
+				name: name,
+				kind: kindSlice,
+				elem: elem,
+			}, nil
+		case isByte:
+			return &fieldType{
+				name: name,
+				kind: kindByteArray,
+			}, nil
+		default:
+			return &fieldType{
+				name: name,
+				kind: kindArray,
+				elem: elem,
+			}, nil
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedType, name)
+	}
+}
+
+// typeName returns [expr] as it's written in the source, and records the
+// imports that it refers to.
+func (g *generator) typeName(file *ast.File, expr ast.Expr) (string, error) {
+	var err error
+	ast.Inspect(expr, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkgIdent, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		path := importPath(file, pkgIdent.Name)
+		if path == "" {
+			err = fmt.Errorf("%w: %s.%s", errUnknownType, pkgIdent.Name, selector.Sel.Name)
+			return false
+		}
+		g.imports[pkgIdent.Name] = path
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := format.Node(&sb, g.pkg.fset, expr); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// importPath returns the path of the package imported by [file] as [name].
+func importPath(file *ast.File, name string) string {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		importName := filepath.Base(path)
+		if spec.Name != nil {
+			importName = spec.Name.Name
+		}
+		if importName == name {
+			return path
+		}
+	}
+	return ""
+}
+
+// checkLen writes code that returns an error if the uint32 [length] exceeds
+// [maxLen]. [returned] precedes the error in the return statement.
+func (g *generator) checkLen(length string, maxLen uint32, returned string) {
+	g.usesFmt = true
+	g.printf("if %s > %d {\n", length, maxLen)
+	g.printf("return %sfmt.Errorf(\"%%w; slice length, %%d, exceeds maximum length, %%d\", codec.ErrMaxSliceLenExceeded, %s, %d)\n",
+		returned,
+		length,
+		maxLen,
+	)
+	g.printf("}\n")
+}
+
+// checkStrLen writes code that returns an error if the string [value] is
+// longer than [maxLen], unless [maxLen] is 0.
+func (g *generator) checkStrLen(value string, maxLen uint32, returned string) {
+	if maxLen == 0 {
+		return
+	}
+	g.usesFmt = true
+	g.printf("if len(%s) > %d {\n", value, maxLen)
+	g.printf("return %sfmt.Errorf(\"%%w; string length, %%d, exceeds maximum length, %%d\", codec.ErrMaxSliceLenExceeded, len(%s), %d)\n",
+		returned,
+		value,
+		maxLen,
+	)
+	g.printf("}\n")
+}
+
+func (g *generator) size(value string, typ *fieldType, maxLen uint32) {
+	switch typ.kind {
+	case kindBasic:
+		if typ.basic.packed == "string" {
+			g.printf("size += %s + len(%s)\n", typ.basic.sizeConst, value)
+		} else {
+			g.printf("size += %s\n", typ.basic.sizeConst)
+		}
+	case kindBytes:
+		g.checkLen("uint32(len("+value+"))", maxLen, "0, ")
+		g.printf("size += wrappers.IntLen + len(%s)\n", value)
+	case kindByteArray:
+		g.printf("size += len(%s)\n", value)
+	case kindSlice, kindArray:
+		if typ.kind == kindSlice {
+			g.checkLen("uint32(len("+value+"))", maxLen, "0, ")
+			g.printf("size += wrappers.IntLen\n")
+		}
+		if typ.elem.kind == kindBasic && typ.elem.basic.packed != "string" {
+			g.printf("size += len(%s) * %s\n", value, typ.elem.basic.sizeConst)
+			return
+		}
+		i := g.newVar("i")
+		g.printf("for %s := range %s {\n", i, value)
+		g.size(value+"["+i+"]", typ.elem, g.config.MaxSliceLen)
+		g.printf("}\n")
+	case kindMarshaler:
+		s := g.newVar("s")
+		g.printf("%s, err := %s.CodecSize()\n", s, value)
+		g.printf("if err != nil {\n")
+		g.printf("return 0, err\n")
+		g.printf("}\n")
+		g.printf("size += %s\n", s)
+	}
+}
+
+func (g *generator) marshal(value string, typ *fieldType, maxLen uint32) {
+	switch typ.kind {
+	case kindBasic:
+		if typ.name == typ.basic.packed {
+			g.printf("p.Pack%s(%s)\n", typ.basic.packer, value)
+		} else {
+			g.printf("p.Pack%s(%s(%s))\n", typ.basic.packer, typ.basic.packed, value)
+		}
+	case kindBytes:
+		g.checkLen("uint32(len("+value+"))", maxLen, "")
+		g.printf("p.PackBytes(%s)\n", value)
+	case kindByteArray:
+		g.printf("p.PackFixedBytes(%s[:])\n", value)
+	case kindSlice, kindArray:
+		if typ.kind == kindSlice {
+			g.checkLen("uint32(len("+value+"))", maxLen, "")
+			g.printf("p.PackInt(uint32(len(%s)))\n", value)
+		}
+		i := g.newVar("i")
+		g.printf("for %s := range %s {\n", i, value)
+		g.marshal(value+"["+i+"]", typ.elem, g.config.MaxSliceLen)
+		g.printf("}\n")
+	case kindMarshaler:
+		g.printf("if err := %s.MarshalCodec(p); err != nil {\n", value)
+		g.printf("return err\n")
+		g.printf("}\n")
+	}
+}
+
+func (g *generator) unmarshal(value string, typ *fieldType, maxLen uint32) {
+	switch typ.kind {
+	case kindBasic:
+		if typ.name == typ.basic.packed {
+			g.printf("%s = p.Unpack%s()\n", value, typ.basic.packer)
+		} else {
+			g.printf("%s = %s(p.Unpack%s())\n", value, typ.name, typ.basic.packer)
+		}
+	case kindBytes:
+		n := g.unmarshalLen(maxLen)
+		if typ.name == "[]byte" {
+			g.printf("%s = p.UnpackFixedBytes(int(%s))\n", value, n)
+		} else {
+			g.printf("%s = %s(p.UnpackFixedBytes(int(%s)))\n", value, typ.name, n)
+		}
+	case kindByteArray:
+		g.printf("copy(%s[:], p.UnpackFixedBytes(len(%s)))\n", value, value)
+	case kindSlice:
+		n := g.unmarshalLen(maxLen)
+		// Only allocate up front as many elements as there are bytes left.
+		c := g.newVar("c")
+		g.printf("%s := int(%s)\n", c, n)
+		g.printf("if remaining := len(p.Bytes) - p.Offset; %s > remaining {\n", c)
+		g.printf("%s = remaining\n", c)
+		g.printf("}\n")
+		g.printf("%s = make(%s, 0, %s)\n", value, typ.name, c)
+
+		i := g.newVar("i")
+		e := g.newVar("e")
+		g.printf("for %s := uint32(0); %s < %s; %s++ {\n", i, i, n, i)
+		g.printf("var %s %s\n", e, typ.elem.name)
+		g.unmarshal(e, typ.elem, g.config.MaxSliceLen)
+		g.printf("%s = append(%s, %s)\n", value, value, e)
+		g.printf("}\n")
+	case kindArray:
+		i := g.newVar("i")
+		g.printf("for %s := range %s {\n", i, value)
+		g.unmarshal(value+"["+i+"]", typ.elem, g.config.MaxSliceLen)
+		g.printf("}\n")
+	case kindMarshaler:
+		g.printf("if err := %s.UnmarshalCodec(p); err != nil {\n", value)
+		g.printf("return err\n")
+		g.printf("}\n")
+	}
+}
+
+// unmarshalLen unpacks the length of a slice and returns the variable holding
+// it.
+func (g *generator) unmarshalLen(maxLen uint32) string {
+	n := g.newVar("n")
+	g.printf("%s := p.UnpackInt()\n", n)
+	g.printf("if p.Err != nil {\n")
+	g.printf("return p.Err\n")
+	g.printf("}\n")
+	g.checkLen(n, maxLen, "")
+	return n
+}
+
+// source returns the formatted source of the generated file.
+func (g *generator) source() ([]byte, error) {
+	var out bytes.Buffer
+	fmt.Fprintln(&out, "// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.")
+	fmt.Fprintln(&out, "// See the file LICENSE for licensing terms.")
+	fmt.Fprintln(&out)
+	fmt.Fprintln(&out, GeneratedHeader)
+	fmt.Fprintln(&out)
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg.name)
+
+	imports := map[string]string{
+		"codec":    codecImportPath,
+		"wrappers": wrappersImportPath,
+	}
+	if g.usesFmt {
+		imports["fmt"] = "fmt"
+	}
+	body := g.buf.Bytes()
+	for name, path := range g.imports {
+		// Types that are only (un)packed through their fields aren't
+		// referenced by name.
+		referenced := regexp.MustCompile(`(^|[^\w.])` + regexp.QuoteMeta(name) + `\.`)
+		if referenced.Match(body) {
+			imports[name] = path
+		}
+	}
+
+	// Group the imports like goimports does.
+	var groups [3][]string
+	for name, path := range imports {
+		spec := strconv.Quote(path)
+		if name != filepath.Base(path) {
+			spec = name + " " + spec
+		}
+		switch {
+		case strings.HasPrefix(path, localImportPrefix):
+			groups[2] = append(groups[2], spec)
+		case strings.Contains(strings.Split(path, "/")[0], "."):
+			groups[1] = append(groups[1], spec)
+		default:
+			groups[0] = append(groups[0], spec)
+		}
+	}
+	fmt.Fprintln(&out, "import (")
+	first := true
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(&out)
+		}
+		first = false
+		sort.Strings(group)
+		for _, spec := range group {
+			fmt.Fprintln(&out, spec)
+		}
+	}
+	fmt.Fprintln(&out, ")")
+	fmt.Fprintln(&out)
+
+	out.Write(body)
+	return format.Source(out.Bytes())
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codecgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate checks that the generated code in codecgentest is up to date.
+func TestGenerate(t *testing.T) {
+	require := require.New(t)
+
+	dir := "codecgentest"
+	src, err := Generate(Config{
+		Dir: dir,
+	})
+	require.NoError(err)
+
+	expected, err := os.ReadFile(filepath.Join(dir, "codecgen.go"))
+	require.NoError(err)
+	require.Equal(string(expected), string(src))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		expectedErr error
+	}{
+		{
+			name: "unsupported field type",
+			config: Config{
+				Dir: filepath.Join("testdata", "unsupported"),
+			},
+			expectedErr: errUnsupportedType,
+		},
+		{
+			name: "unknown type",
+			config: Config{
+				Dir:   "codecgentest",
+				Types: []string{"Missing"},
+			},
+			expectedErr: errUnknownType,
+		},
+		{
+			name: "not a struct",
+			config: Config{
+				Dir:   "codecgentest",
+				Types: []string{"Status"},
+			},
+			expectedErr: errNotStruct,
+		},
+		{
+			name: "no serialized types",
+			config: Config{
+				Dir:     "codecgentest",
+				TagName: "missing",
+			},
+			expectedErr: errNoSerializedType,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Generate(test.config)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package codecgentest holds types with generated marshalling code, to test
+// that it's equivalent to marshalling them with reflection.
+package codecgentest
+
+import "github.com/ava-labs/avalanchego/ids"
+
+//go:generate go run github.com/ava-labs/avalanchego/codec/codecgen/cmd --output codecgen.go
+
+type Status uint32
+
+type Output struct {
+	Amount    uint64        `serialize:"true"`
+	Locktime  uint64        `serialize:"true"`
+	Threshold uint32        `serialize:"true"`
+	Addrs     []ids.ShortID `serialize:"true"`
+}
+
+type Tx struct {
+	NetworkID uint32   `serialize:"true"`
+	Memo      []byte   `serialize:"true" len:"256"`
+	Outs      []Output `serialize:"true"`
+	Status    Status   `serialize:"true"`
+	Name      string   `serialize:"true"`
+	Flags     [4]bool  `serialize:"true"`
+	Delta     int16    `serialize:"true"`
+
+	// Not serialized
+	TxID ids.ID
+}
+
+type Block struct {
+	ParentID  ids.ID     `serialize:"true"`
+	Height    uint64     `serialize:"true"`
+	Timestamp int64      `serialize:"true"`
+	Proposer  ids.NodeID `serialize:"true"`
+	Txs       []Tx       `serialize:"true"`
+	Proofs    [][]byte   `serialize:"true"`
+	Root      [8]byte    `serialize:"true"`
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codecgentest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// reflected decodes values field by field, ignoring their generated code.
+var reflected = codec.DecodeProfile{
+	ReflectedTypes: true,
+}
+
+func newBlock() *Block {
+	return &Block{
+		ParentID:  ids.GenerateTestID(),
+		Height:    10,
+		Timestamp: -1,
+		Proposer:  ids.GenerateTestNodeID(),
+		Txs: []Tx{
+			{
+				NetworkID: 1,
+				Memo:      []byte("memo"),
+				Outs: []Output{
+					{
+						Amount:    1000,
+						Locktime:  5,
+						Threshold: 1,
+						Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+					},
+					{
+						Amount:    2000,
+						Threshold: 2,
+						Addrs:     []ids.ShortID{ids.GenerateTestShortID(), ids.GenerateTestShortID()},
+					},
+				},
+				Status: 3,
+				Name:   "transfer",
+				Flags:  [4]bool{true, false, true, false},
+				Delta:  -7,
+			},
+		},
+		Proofs: [][]byte{{1, 2, 3}, {4}},
+		Root:   [8]byte{1, 2, 3, 4, 5, 6, 7, 8},
+	}
+}
+
+func newManager(t *testing.T) codec.Manager {
+	manager := codec.NewDefaultManager()
+	require.NoError(t, manager.RegisterCodec(0, linearcodec.NewDefault()))
+	return manager
+}
+
+func TestGeneratedMatchesReflection(t *testing.T) {
+	require := require.New(t)
+
+	manager := newManager(t)
+	blk := newBlock()
+	blkBytes, err := manager.Marshal(0, blk)
+	require.NoError(err)
+
+	size, err := manager.Size(0, blk)
+	require.NoError(err)
+	require.Len(blkBytes, size)
+
+	// Reflection reads the bytes written by the generated code.
+	var reflectedBlk Block
+	_, err = manager.UnmarshalWithProfile(blkBytes, &reflectedBlk, reflected)
+	require.NoError(err)
+	require.Equal(blk, &reflectedBlk)
+
+	var parsedBlk Block
+	_, err = manager.Unmarshal(blkBytes, &parsedBlk)
+	require.NoError(err)
+	require.Equal(blk, &parsedBlk)
+
+	// Trailing bytes are rejected like they are by reflection.
+	_, err = manager.Unmarshal(append(blkBytes, 0), &parsedBlk)
+	require.ErrorIs(err, codec.ErrExtraSpace)
+}
+
+func TestGeneratedMaxSliceLen(t *testing.T) {
+	require := require.New(t)
+
+	manager := newManager(t)
+	blk := newBlock()
+	blk.Txs[0].Memo = make([]byte, 257)
+	_, err := manager.Marshal(0, blk)
+	require.ErrorIs(err, codec.ErrMaxSliceLenExceeded)
+
+	// The limit is enforced when unmarshalling as well.
+	tx := &blk.Txs[0]
+	tx.Memo = nil
+	txBytes, err := manager.Marshal(0, tx)
+	require.NoError(err)
+	// After the codec version and the network ID, set the memo length to 257.
+	txBytes[8] = 0x01
+	txBytes[9] = 0x01
+
+	var parsedTx Tx
+	_, err = manager.Unmarshal(txBytes, &parsedTx)
+	require.ErrorIs(err, codec.ErrMaxSliceLenExceeded)
+	_, err = manager.UnmarshalWithProfile(txBytes, &parsedTx, reflected)
+	require.ErrorIs(err, codec.ErrMaxSliceLenExceeded)
+}
@@ -0,0 +1,8 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package unsupported
+
+type Unsupported struct {
+	Balances map[string]uint64 `serialize:"true"`
+}
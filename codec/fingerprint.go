@@ -0,0 +1,24 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var (
+	ErrFingerprintUnsupported = errors.New("codec doesn't support fingerprinting")
+	ErrFingerprintMismatch    = errors.New("codec fingerprint mismatch")
+)
+
+// Fingerprinter is implemented by codecs that can summarize their registered
+// types.
+type Fingerprinter interface {
+	// Fingerprint returns a deterministic hash over the registered type IDs
+	// and the serialized layout of the types they map to. Two codecs with the
+	// same fingerprint serialize registered types identically.
+	Fingerprint() (ids.ID, error)
+}
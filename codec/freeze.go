@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+var ErrRegistryFrozen = errors.New("registry is frozen")
+
+// Freezer is implemented by registries that can be frozen.
+type Freezer interface {
+	// Freeze makes all further type registrations fail with
+	// [ErrRegistryFrozen], so that a late registration can't shift the IDs
+	// of types registered afterwards.
+	Freeze()
+
+	// Registrations returns the audit log of type registrations, in the
+	// order they were attempted, including registrations that failed.
+	Registrations() []Registration
+}
+
+// Registration records an attempt to register a type.
+type Registration struct {
+	// Type that was registered
+	Type string `json:"type"`
+	// ID assigned to [Type]. Empty if the registration failed.
+	ID string `json:"id,omitempty"`
+	// Location, as file:line, of the code that registered [Type]
+	Caller string `json:"caller"`
+	// Reason the registration failed. Empty if it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// NewRegistration records the registration of [valType] as [id], which
+// resulted in [err]. Must be called directly by the registry's RegisterType
+// method, so that the caller of RegisterType is recorded.
+func NewRegistration(valType reflect.Type, id string, err error) Registration {
+	registration := Registration{
+		Type:   fmt.Sprint(valType),
+		Caller: "unknown",
+	}
+	// Skip this function and RegisterType.
+	if _, file, line, ok := runtime.Caller(2); ok {
+		registration.Caller = fmt.Sprintf("%s:%d", file, line)
+	}
+	if err != nil {
+		registration.Error = err.Error()
+	} else {
+		registration.ID = id
+	}
+	return registration
+}
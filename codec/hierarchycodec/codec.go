@@ -4,13 +4,21 @@
 package hierarchycodec
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/codec/reflectcodec"
-	"github.com/ava-labs/avalanchego/utils/bimap"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
@@ -20,16 +28,25 @@ const (
 )
 
 var (
-	_ Codec              = (*hierarchyCodec)(nil)
-	_ codec.Codec        = (*hierarchyCodec)(nil)
-	_ codec.Registry     = (*hierarchyCodec)(nil)
-	_ codec.GeneralCodec = (*hierarchyCodec)(nil)
+	errInvalidTypeID = errors.New("invalid type ID")
+
+	_ Codec                   = (*hierarchyCodec)(nil)
+	_ codec.Codec             = (*hierarchyCodec)(nil)
+	_ codec.Registry          = (*hierarchyCodec)(nil)
+	_ codec.GeneralCodec      = (*hierarchyCodec)(nil)
+	_ codec.Fingerprinter     = (*hierarchyCodec)(nil)
+	_ codec.RegistryDescriber = (*hierarchyCodec)(nil)
+	_ codec.Freezer           = (*hierarchyCodec)(nil)
+	_ codec.JSONCodec         = (*hierarchyCodec)(nil)
+
+	_ reflectcodec.JSONTypeCodec = (*hierarchyCodec)(nil)
 )
 
 // Codec marshals and unmarshals
 type Codec interface {
 	codec.Registry
 	codec.Codec
+	codec.Freezer
 	SkipRegistrations(int)
 	NextGroup()
 }
@@ -39,24 +56,67 @@ type typeID struct {
 	typeID  uint16
 }
 
+// typeRegistry is an immutable snapshot of the types registered with a codec.
+// Registering a type replaces the snapshot rather than modifying it, so that
+// marshalling and unmarshalling interfaces doesn't take a lock.
+type typeRegistry struct {
+	typeIDs map[reflect.Type]typeID
+	types   map[typeID]reflect.Type
+}
+
+// sortedTypeIDs returns the registered type IDs, ordered by group and then by
+// type.
+func (r *typeRegistry) sortedTypeIDs() []typeID {
+	typeIDs := maps.Keys(r.types)
+	slices.SortFunc(typeIDs, func(a, b typeID) bool {
+		if a.groupID != b.groupID {
+			return a.groupID < b.groupID
+		}
+		return a.typeID < b.typeID
+	})
+	return typeIDs
+}
+
 // Codec handles marshaling and unmarshaling of structs
 type hierarchyCodec struct {
 	codec.Codec
 
-	lock            sync.RWMutex
-	currentGroupID  uint16
-	nextTypeID      uint16
-	registeredTypes *bimap.BiMap[typeID, reflect.Type]
+	// Must be held when registering types. Isn't held when reading
+	// [registry].
+	lock           sync.RWMutex
+	currentGroupID uint16
+	nextTypeID     uint16
+	registry       atomic.Pointer[typeRegistry]
+	frozen         bool
+	registrations  []codec.Registration
 }
 
 // New returns a new, concurrency-safe codec
 func New(tagNames []string, maxSliceLen uint32) Codec {
+	hCodec := newHierarchyCodec()
+	hCodec.Codec = reflectcodec.New(hCodec, tagNames, maxSliceLen)
+	return hCodec
+}
+
+// NewSparse returns a new, concurrency-safe codec that omits struct fields
+// equal to their zero value. Its encoding isn't compatible with the encoding of
+// the codec returned by New, so it must be registered under a new codec
+// version. See [reflectcodec.NewSparse].
+func NewSparse(tagNames []string, maxSliceLen uint32) Codec {
+	hCodec := newHierarchyCodec()
+	hCodec.Codec = reflectcodec.NewSparse(hCodec, tagNames, maxSliceLen)
+	return hCodec
+}
+
+func newHierarchyCodec() *hierarchyCodec {
 	hCodec := &hierarchyCodec{
-		currentGroupID:  0,
-		nextTypeID:      0,
-		registeredTypes: bimap.New[typeID, reflect.Type](),
+		currentGroupID: 0,
+		nextTypeID:     0,
 	}
-	hCodec.Codec = reflectcodec.New(hCodec, tagNames, maxSliceLen)
+	hCodec.registry.Store(&typeRegistry{
+		typeIDs: make(map[reflect.Type]typeID),
+		types:   make(map[typeID]reflect.Type),
+	})
 	return hCodec
 }
 
@@ -66,17 +126,23 @@ func NewDefault() Codec {
 }
 
 // SkipRegistrations some number of type IDs
+// Has no effect once the codec is frozen.
 func (c *hierarchyCodec) SkipRegistrations(num int) {
 	c.lock.Lock()
-	c.nextTypeID += uint16(num)
+	if !c.frozen {
+		c.nextTypeID += uint16(num)
+	}
 	c.lock.Unlock()
 }
 
 // NextGroup moves to the next group registry
+// Has no effect once the codec is frozen.
 func (c *hierarchyCodec) NextGroup() {
 	c.lock.Lock()
-	c.currentGroupID++
-	c.nextTypeID = 0
+	if !c.frozen {
+		c.currentGroupID++
+		c.nextTypeID = 0
+	}
 	c.lock.Unlock()
 }
 
@@ -87,9 +153,26 @@ func (c *hierarchyCodec) RegisterType(val interface{}) error {
 	defer c.lock.Unlock()
 
 	valType := reflect.TypeOf(val)
-	if c.registeredTypes.HasValue(valType) {
+	valTypeID := fmt.Sprintf("%d:%d", c.currentGroupID, c.nextTypeID)
+	err := c.registerType(valType)
+	c.registrations = append(c.registrations, codec.NewRegistration(valType, valTypeID, err))
+	return err
+}
+
+// Assumes [c.lock] is held.
+func (c *hierarchyCodec) registerType(valType reflect.Type) error {
+	if c.frozen {
+		return fmt.Errorf("%w: can't register %v", codec.ErrRegistryFrozen, valType)
+	}
+	registry := c.registry.Load()
+	if _, ok := registry.typeIDs[valType]; ok {
 		return fmt.Errorf("%w: %v", codec.ErrDuplicateType, valType)
 	}
+	if checker, ok := c.Codec.(reflectcodec.TypeChecker); ok {
+		if err := checker.CheckType(valType); err != nil {
+			return fmt.Errorf("can't register %v: %w", valType, err)
+		}
+	}
 
 	valTypeID := typeID{
 		groupID: c.currentGroupID,
@@ -97,20 +180,91 @@ func (c *hierarchyCodec) RegisterType(val interface{}) error {
 	}
 	c.nextTypeID++
 
-	c.registeredTypes.Put(valTypeID, valType)
+	registry = &typeRegistry{
+		typeIDs: maps.Clone(registry.typeIDs),
+		types:   maps.Clone(registry.types),
+	}
+	registry.typeIDs[valType] = valTypeID
+	registry.types[valTypeID] = valType
+	c.registry.Store(registry)
 	return nil
 }
 
+func (c *hierarchyCodec) Freeze() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.frozen = true
+}
+
+func (c *hierarchyCodec) Registrations() []codec.Registration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return slices.Clone(c.registrations)
+}
+
+// Fingerprint hashes the registered type IDs, ordered by group and then by
+// type, along with the serialized layout of the types they map to.
+func (c *hierarchyCodec) Fingerprint() (ids.ID, error) {
+	describer, ok := c.Codec.(reflectcodec.TypeDescriber)
+	if !ok {
+		return ids.Empty, codec.ErrFingerprintUnsupported
+	}
+
+	registry := c.registry.Load()
+	typeIDs := registry.sortedTypeIDs()
+
+	var sb strings.Builder
+	for _, valTypeID := range typeIDs {
+		valType := registry.types[valTypeID]
+		description, err := describer.DescribeType(valType)
+		if err != nil {
+			return ids.Empty, fmt.Errorf("couldn't describe type ID %d.%d: %w", valTypeID.groupID, valTypeID.typeID, err)
+		}
+		fmt.Fprintf(&sb, "%d.%d:%s\n", valTypeID.groupID, valTypeID.typeID, description)
+	}
+	return hashing.ComputeHash256Array([]byte(sb.String())), nil
+}
+
+// DescribeRegistry returns the registered type IDs, ordered by group and then
+// by type, along with the serialized layout of the types they map to.
+func (c *hierarchyCodec) DescribeRegistry() (*codec.RegistrySchema, error) {
+	describer, ok := c.Codec.(reflectcodec.SchemaDescriber)
+	if !ok {
+		return nil, codec.ErrRegistrySchemaUnsupported
+	}
+
+	registry := c.registry.Load()
+	typeIDs := registry.sortedTypeIDs()
+
+	schema := &codec.RegistrySchema{
+		TypeIDEncoding: codec.TypeIDEncodingGroupedUint16,
+		Types:          make([]codec.RegisteredTypeSchema, len(typeIDs)),
+	}
+	for i, valTypeID := range typeIDs {
+		valType := registry.types[valTypeID]
+		typeSchema, err := describer.DescribeTypeSchema(valType)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't describe type ID %d.%d: %w", valTypeID.groupID, valTypeID.typeID, err)
+		}
+		schema.Types[i] = codec.RegisteredTypeSchema{
+			GroupID: valTypeID.groupID,
+			TypeID:  uint32(valTypeID.typeID),
+			Name:    valType.String(),
+			Schema:  typeSchema,
+		}
+	}
+	return schema, nil
+}
+
 func (*hierarchyCodec) PrefixSize(reflect.Type) int {
 	// see PackPrefix implementation
 	return wrappers.ShortLen + wrappers.ShortLen
 }
 
 func (c *hierarchyCodec) PackPrefix(p *wrappers.Packer, valueType reflect.Type) error {
-	c.lock.RLock()
-	defer c.lock.RUnlock()
-
-	typeID, ok := c.registeredTypes.GetKey(valueType) // Get the type ID of the value being marshaled
+	typeID, ok := c.registry.Load().typeIDs[valueType] // Get the type ID of the value being marshaled
 	if !ok {
 		return fmt.Errorf("can't marshal unregistered type %q", valueType)
 	}
@@ -121,20 +275,22 @@ func (c *hierarchyCodec) PackPrefix(p *wrappers.Packer, valueType reflect.Type)
 }
 
 func (c *hierarchyCodec) UnpackPrefix(p *wrappers.Packer, valueType reflect.Type) (reflect.Value, error) {
-	c.lock.RLock()
-	defer c.lock.RUnlock()
-
 	groupID := p.UnpackShort()     // Get the group ID
 	typeIDShort := p.UnpackShort() // Get the type ID
 	if p.Err != nil {
 		return reflect.Value{}, fmt.Errorf("couldn't unmarshal interface: %w", p.Err)
 	}
-	t := typeID{
+	return c.newImplementor(typeID{
 		groupID: groupID,
 		typeID:  typeIDShort,
-	}
+	}, valueType)
+}
+
+// newImplementor returns a new value of the type registered under [t], which
+// must implement [valueType].
+func (c *hierarchyCodec) newImplementor(t typeID, valueType reflect.Type) (reflect.Value, error) {
 	// Get a type that implements the interface
-	implementingType, ok := c.registeredTypes.GetValue(t)
+	implementingType, ok := c.registry.Load().types[t]
 	if !ok {
 		return reflect.Value{}, fmt.Errorf("couldn't unmarshal interface: unknown type ID %+v", t)
 	}
@@ -148,3 +304,50 @@ func (c *hierarchyCodec) UnpackPrefix(p *wrappers.Packer, valueType reflect.Type
 	}
 	return reflect.New(implementingType).Elem(), nil // instance of the proper type
 }
+
+// FormatTypeID returns the type ID [valueType] is registered under, formatted
+// as its group ID and its type ID within the group, separated by a dot.
+func (c *hierarchyCodec) FormatTypeID(valueType reflect.Type) (string, error) {
+	t, ok := c.registry.Load().typeIDs[valueType]
+	if !ok {
+		return "", fmt.Errorf("can't marshal unregistered type %q", valueType)
+	}
+	return fmt.Sprintf("%d.%d", t.groupID, t.typeID), nil
+}
+
+func (c *hierarchyCodec) ParseTypeID(s string, valueType reflect.Type) (reflect.Value, error) {
+	groupIDStr, typeIDStr, ok := strings.Cut(s, ".")
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%w %q: missing group ID", errInvalidTypeID, s)
+	}
+	groupID, err := strconv.ParseUint(groupIDStr, 10, 16)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w %q: %w", errInvalidTypeID, s, err)
+	}
+	typeIDShort, err := strconv.ParseUint(typeIDStr, 10, 16)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w %q: %w", errInvalidTypeID, s, err)
+	}
+	return c.newImplementor(typeID{
+		groupID: uint16(groupID),
+		typeID:  uint16(typeIDShort),
+	}, valueType)
+}
+
+// ToJSON returns the JSON representation of [value]. Interface values hold
+// their type ID as "<group ID>.<type ID>".
+func (c *hierarchyCodec) ToJSON(value interface{}) ([]byte, error) {
+	jsonCodec, ok := c.Codec.(codec.JSONCodec)
+	if !ok {
+		return nil, codec.ErrJSONUnsupported
+	}
+	return jsonCodec.ToJSON(value)
+}
+
+func (c *hierarchyCodec) FromJSON(bytes []byte, dest interface{}) error {
+	jsonCodec, ok := c.Codec.(codec.JSONCodec)
+	if !ok {
+		return codec.ErrJSONUnsupported
+	}
+	return jsonCodec.FromJSON(bytes, dest)
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/reflectcodec"
 )
 
 func TestVectors(t *testing.T) {
@@ -23,6 +24,13 @@ func TestMultipleTags(t *testing.T) {
 	}
 }
 
+func TestSparse(t *testing.T) {
+	for _, test := range codec.SparseTests {
+		c := NewSparse([]string{reflectcodec.DefaultTagName}, defaultMaxSliceLength)
+		test(c, t)
+	}
+}
+
 func FuzzStructUnmarshalHierarchyCodec(f *testing.F) {
 	c := NewDefault()
 	codec.FuzzStructUnmarshal(c, f)
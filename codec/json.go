@@ -0,0 +1,27 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import "errors"
+
+var (
+	ErrJSONUnsupported  = errors.New("codec doesn't support JSON")
+	ErrMissingJSONField = errors.New("missing field")
+	ErrJSONTypeMismatch = errors.New("type doesn't match the type ID")
+)
+
+// JSONCodec is implemented by codecs that can represent values as JSON, so
+// that debugging tools can display any registered type in human-readable form
+// and convert it back.
+//
+// The JSON representation holds the same fields, in the same order, as the
+// binary encoding. It isn't meant to be a stable API format.
+type JSONCodec interface {
+	// ToJSON returns the JSON representation of [value].
+	ToJSON(value interface{}) ([]byte, error)
+
+	// FromJSON unmarshals the JSON representation [source] into
+	// [destination], which must be a pointer.
+	FromJSON(source []byte, destination interface{}) error
+}
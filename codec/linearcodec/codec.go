@@ -4,13 +4,23 @@
 package linearcodec
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/codec/reflectcodec"
-	"github.com/ava-labs/avalanchego/utils/bimap"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
@@ -20,53 +30,161 @@ const (
 )
 
 var (
-	_ Codec              = (*linearCodec)(nil)
-	_ codec.Codec        = (*linearCodec)(nil)
-	_ codec.Registry     = (*linearCodec)(nil)
-	_ codec.GeneralCodec = (*linearCodec)(nil)
+	errInvalidTypeID = errors.New("invalid type ID")
+
+	_ Codec                   = (*linearCodec)(nil)
+	_ codec.Codec             = (*linearCodec)(nil)
+	_ codec.Registry          = (*linearCodec)(nil)
+	_ codec.GeneralCodec      = (*linearCodec)(nil)
+	_ codec.Fingerprinter     = (*linearCodec)(nil)
+	_ codec.RegistryDescriber = (*linearCodec)(nil)
+	_ codec.Freezer           = (*linearCodec)(nil)
+	_ codec.JSONCodec         = (*linearCodec)(nil)
+
+	_ reflectcodec.JSONTypeCodec = (*linearCodec)(nil)
+
+	_ codec.ProfileUnmarshaler = (*linearCodec)(nil)
 )
 
 // Codec marshals and unmarshals
 type Codec interface {
 	codec.Registry
 	codec.Codec
+	codec.Freezer
 	SkipRegistrations(int)
+	RegisterAlias(interface{}) error
+	RegisterTypeWithID(interface{}, uint32) error
+}
+
+// Option configures a codec created by this package.
+type Option func(*linearCodec)
+
+// WithVarintTypeIDs prefixes interface values with their type ID encoded as a
+// uvarint rather than as 4 bytes, which saves 3 bytes per interface value if
+// fewer than 128 types are registered. The encoding isn't compatible with the
+// encoding of a codec without this option, so it must be registered under a
+// new codec version.
+func WithVarintTypeIDs() Option {
+	return func(c *linearCodec) {
+		c.varintTypeIDs = true
+	}
+}
+
+// WithMaxDepth sets the maximum number of values that may be nested in one
+// another while unmarshalling. See [reflectcodec.WithMaxDepth].
+func WithMaxDepth(maxDepth uint32) Option {
+	return func(c *linearCodec) {
+		c.codecOpts = append(c.codecOpts, reflectcodec.WithMaxDepth(maxDepth))
+	}
+}
+
+// WithAllocationLimit sets the maximum number of bytes that may be allocated
+// while unmarshalling a single value. See [reflectcodec.WithAllocationLimit].
+func WithAllocationLimit(limit uint64) Option {
+	return func(c *linearCodec) {
+		c.codecOpts = append(c.codecOpts, reflectcodec.WithAllocationLimit(limit))
+	}
+}
+
+// WithUnsafeStrings unmarshals strings as views over the bytes being
+// unmarshalled. The bytes must not be modified afterwards. See
+// [reflectcodec.WithUnsafeStrings].
+func WithUnsafeStrings() Option {
+	return func(c *linearCodec) {
+		c.codecOpts = append(c.codecOpts, reflectcodec.WithUnsafeStrings())
+	}
+}
+
+// typeRegistry is an immutable snapshot of the types registered with a codec.
+// Registering a type replaces the snapshot rather than modifying it, so that
+// marshalling and unmarshalling interfaces doesn't take a lock.
+type typeRegistry struct {
+	// The type IDs types are registered under, and the types registered under
+	// each type ID.
+	typeIDs map[reflect.Type]uint32
+	types   map[uint32]reflect.Type
+	// Type IDs that are unmarshalled into a type registered under another ID.
+	aliases map[uint32]reflect.Type
+}
+
+func (r *typeRegistry) clone() *typeRegistry {
+	return &typeRegistry{
+		typeIDs: maps.Clone(r.typeIDs),
+		types:   maps.Clone(r.types),
+		aliases: maps.Clone(r.aliases),
+	}
 }
 
 // Codec handles marshaling and unmarshaling of structs
 type linearCodec struct {
 	codec.Codec
 
-	lock            sync.RWMutex
-	nextTypeID      uint32
-	registeredTypes *bimap.BiMap[uint32, reflect.Type]
+	// Must be held when registering types. Isn't held when reading
+	// [registry].
+	lock          sync.RWMutex
+	nextTypeID    uint32
+	registry      atomic.Pointer[typeRegistry]
+	frozen        bool
+	registrations []codec.Registration
+	varintTypeIDs bool
+
+	// Options of the underlying reflectcodec.
+	codecOpts []reflectcodec.Option
+
+	// Codecs created for decode profiles, keyed by the profile.
+	profileCodecs sync.Map // string -> codec.Codec
 }
 
 // New returns a new, concurrency-safe codec; it allow to specify
 // both tagNames and maxSlicelenght
-func New(tagNames []string, maxSliceLen uint32) Codec {
-	hCodec := &linearCodec{
-		nextTypeID:      0,
-		registeredTypes: bimap.New[uint32, reflect.Type](),
-	}
-	hCodec.Codec = reflectcodec.New(hCodec, tagNames, maxSliceLen)
+func New(tagNames []string, maxSliceLen uint32, opts ...Option) Codec {
+	hCodec := newLinearCodec(opts)
+	hCodec.Codec = reflectcodec.New(hCodec, tagNames, maxSliceLen, hCodec.codecOpts...)
+	return hCodec
+}
+
+// NewSparse returns a new, concurrency-safe codec that omits struct fields
+// equal to their zero value. Its encoding isn't compatible with the encoding of
+// the codec returned by New, so it must be registered under a new codec
+// version. See [reflectcodec.NewSparse].
+func NewSparse(tagNames []string, maxSliceLen uint32, opts ...Option) Codec {
+	hCodec := newLinearCodec(opts)
+	hCodec.Codec = reflectcodec.NewSparse(hCodec, tagNames, maxSliceLen, hCodec.codecOpts...)
 	return hCodec
 }
 
 // NewDefault is a convenience constructor; it returns a new codec with reasonable default values
-func NewDefault() Codec {
-	return New([]string{reflectcodec.DefaultTagName}, DefaultMaxSliceLength)
+func NewDefault(opts ...Option) Codec {
+	return New([]string{reflectcodec.DefaultTagName}, DefaultMaxSliceLength, opts...)
 }
 
 // NewCustomMaxLength is a convenience constructor; it returns a new codec with custom max length and default tags
-func NewCustomMaxLength(maxSliceLen uint32) Codec {
-	return New([]string{reflectcodec.DefaultTagName}, maxSliceLen)
+func NewCustomMaxLength(maxSliceLen uint32, opts ...Option) Codec {
+	return New([]string{reflectcodec.DefaultTagName}, maxSliceLen, opts...)
+}
+
+func newLinearCodec(opts []Option) *linearCodec {
+	hCodec := &linearCodec{
+		nextTypeID: 0,
+	}
+	hCodec.registry.Store(&typeRegistry{
+		typeIDs: make(map[reflect.Type]uint32),
+		types:   make(map[uint32]reflect.Type),
+		aliases: make(map[uint32]reflect.Type),
+	})
+	for _, opt := range opts {
+		opt(hCodec)
+	}
+	return hCodec
 }
 
 // Skip some number of type IDs
+// Has no effect once the codec is frozen.
 func (c *linearCodec) SkipRegistrations(num int) {
 	c.lock.Lock()
-	c.nextTypeID += uint32(num)
+	if !c.frozen {
+		c.nextTypeID += uint32(num)
+	}
 	c.lock.Unlock()
 }
 
@@ -77,42 +195,272 @@ func (c *linearCodec) RegisterType(val interface{}) error {
 	defer c.lock.Unlock()
 
 	valType := reflect.TypeOf(val)
-	if c.registeredTypes.HasValue(valType) {
+	valTypeID := c.nextTypeID
+	err := c.registerType(valType, valTypeID)
+	if err == nil {
+		c.nextTypeID++
+	}
+	c.registrations = append(c.registrations, codec.NewRegistration(
+		valType,
+		strconv.FormatUint(uint64(valTypeID), 10),
+		err,
+	))
+	return err
+}
+
+// RegisterTypeWithID registers the type of [val] under the type ID [id],
+// rather than under the next type ID. This keeps the type ID of the type
+// stable regardless of the order types are registered in.
+//
+// The next type ID isn't changed, so types registered with RegisterType after
+// [id] is used fail to be registered if they're assigned [id].
+func (c *linearCodec) RegisterTypeWithID(val interface{}, id uint32) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	valType := reflect.TypeOf(val)
+	err := c.registerType(valType, id)
+	c.registrations = append(c.registrations, codec.NewRegistration(
+		valType,
+		strconv.FormatUint(uint64(id), 10),
+		err,
+	))
+	return err
+}
+
+// Assumes [c.lock] is held.
+func (c *linearCodec) registerType(valType reflect.Type, typeID uint32) error {
+	if c.frozen {
+		return fmt.Errorf("%w: can't register %v", codec.ErrRegistryFrozen, valType)
+	}
+	registry := c.registry.Load()
+	if _, ok := registry.typeIDs[valType]; ok {
 		return fmt.Errorf("%w: %v", codec.ErrDuplicateType, valType)
 	}
+	if err := c.checkTypeIDAvailable(typeID); err != nil {
+		return fmt.Errorf("can't register %v: %w", valType, err)
+	}
+	if checker, ok := c.Codec.(reflectcodec.TypeChecker); ok {
+		if err := checker.CheckType(valType); err != nil {
+			return fmt.Errorf("can't register %v: %w", valType, err)
+		}
+	}
+
+	registry = registry.clone()
+	registry.typeIDs[valType] = typeID
+	registry.types[typeID] = valType
+	c.registry.Store(registry)
+	return nil
+}
+
+// Returns an error if a type or alias is registered under [typeID].
+//
+// Assumes [c.lock] is held.
+func (c *linearCodec) checkTypeIDAvailable(typeID uint32) error {
+	registry := c.registry.Load()
+	if valType, ok := registry.types[typeID]; ok {
+		return fmt.Errorf("%w: type ID %d is assigned to %v", codec.ErrDuplicateTypeID, typeID, valType)
+	}
+	if valType, ok := registry.aliases[typeID]; ok {
+		return fmt.Errorf("%w: type ID %d is an alias of %v", codec.ErrDuplicateTypeID, typeID, valType)
+	}
+	return nil
+}
+
+// RegisterAlias assigns the next type ID to the type of [val] as an alias.
+// Values with the alias type ID are unmarshalled into the type of [val], but
+// the type is always marshalled with the type ID it's registered under with
+// RegisterType.
+//
+// This allows a type that was renamed or moved, and is now registered under a
+// new type ID, to be unmarshalled from bytes that were marshalled with the
+// type ID of the type it replaced. RegisterAlias should then be called in place
+// of the RegisterType call that registered the replaced type.
+func (c *linearCodec) RegisterAlias(val interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	valType := reflect.TypeOf(val)
+	valTypeID := c.nextTypeID
+	err := c.registerAlias(valType)
+	c.registrations = append(c.registrations, codec.NewRegistration(
+		valType,
+		strconv.FormatUint(uint64(valTypeID), 10)+" (alias)",
+		err,
+	))
+	return err
+}
+
+// Assumes [c.lock] is held.
+func (c *linearCodec) registerAlias(valType reflect.Type) error {
+	if c.frozen {
+		return fmt.Errorf("%w: can't register alias of %v", codec.ErrRegistryFrozen, valType)
+	}
+	if err := c.checkTypeIDAvailable(c.nextTypeID); err != nil {
+		return fmt.Errorf("can't register alias of %v: %w", valType, err)
+	}
+	if checker, ok := c.Codec.(reflectcodec.TypeChecker); ok {
+		if err := checker.CheckType(valType); err != nil {
+			return fmt.Errorf("can't register alias of %v: %w", valType, err)
+		}
+	}
 
-	c.registeredTypes.Put(c.nextTypeID, valType)
+	registry := c.registry.Load().clone()
+	registry.aliases[c.nextTypeID] = valType
+	c.registry.Store(registry)
 	c.nextTypeID++
 	return nil
 }
 
-func (*linearCodec) PrefixSize(reflect.Type) int {
-	// see PackPrefix implementation
-	return wrappers.IntLen
+func (c *linearCodec) Freeze() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.frozen = true
 }
 
-func (c *linearCodec) PackPrefix(p *wrappers.Packer, valueType reflect.Type) error {
+func (c *linearCodec) Registrations() []codec.Registration {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
-	typeID, ok := c.registeredTypes.GetKey(valueType) // Get the type ID of the value being marshaled
+	return slices.Clone(c.registrations)
+}
+
+// Fingerprint hashes the registered type IDs, in order, along with the
+// serialized layout of the types they map to.
+func (c *linearCodec) Fingerprint() (ids.ID, error) {
+	describer, ok := c.Codec.(reflectcodec.TypeDescriber)
+	if !ok {
+		return ids.Empty, codec.ErrFingerprintUnsupported
+	}
+
+	registry := c.registry.Load()
+	typeIDs := append(maps.Keys(registry.types), maps.Keys(registry.aliases)...)
+	slices.Sort(typeIDs)
+
+	var sb strings.Builder
+	if c.varintTypeIDs {
+		// The type IDs are encoded differently
+		sb.WriteString("varint\n")
+	}
+	for _, typeID := range typeIDs {
+		valType, ok := registry.types[typeID]
+		if !ok {
+			valType = registry.aliases[typeID]
+			fmt.Fprintf(&sb, "%d:alias:", typeID)
+		} else {
+			fmt.Fprintf(&sb, "%d:", typeID)
+		}
+		description, err := describer.DescribeType(valType)
+		if err != nil {
+			return ids.Empty, fmt.Errorf("couldn't describe type ID %d: %w", typeID, err)
+		}
+		fmt.Fprintf(&sb, "%s\n", description)
+	}
+	return hashing.ComputeHash256Array([]byte(sb.String())), nil
+}
+
+// DescribeRegistry returns the registered type IDs, in order, along with the
+// serialized layout of the types they map to.
+func (c *linearCodec) DescribeRegistry() (*codec.RegistrySchema, error) {
+	describer, ok := c.Codec.(reflectcodec.SchemaDescriber)
+	if !ok {
+		return nil, codec.ErrRegistrySchemaUnsupported
+	}
+
+	registry := c.registry.Load()
+	typeIDs := append(maps.Keys(registry.types), maps.Keys(registry.aliases)...)
+	slices.Sort(typeIDs)
+
+	schema := &codec.RegistrySchema{
+		TypeIDEncoding: codec.TypeIDEncodingUint32,
+		Types:          make([]codec.RegisteredTypeSchema, len(typeIDs)),
+	}
+	if c.varintTypeIDs {
+		schema.TypeIDEncoding = codec.TypeIDEncodingUvarint
+	}
+	for i, typeID := range typeIDs {
+		valType, ok := registry.types[typeID]
+		if !ok {
+			valType = registry.aliases[typeID]
+		}
+		typeSchema, err := describer.DescribeTypeSchema(valType)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't describe type ID %d: %w", typeID, err)
+		}
+		schema.Types[i] = codec.RegisteredTypeSchema{
+			TypeID: typeID,
+			Alias:  !ok,
+			Name:   valType.String(),
+			Schema: typeSchema,
+		}
+	}
+	return schema, nil
+}
+
+func (c *linearCodec) PrefixSize(valueType reflect.Type) int {
+	if !c.varintTypeIDs {
+		// see PackPrefix implementation
+		return wrappers.IntLen
+	}
+
+	typeID, ok := c.registry.Load().typeIDs[valueType]
+	if !ok {
+		// Marshalling the value will fail
+		return binary.MaxVarintLen32
+	}
+	var buf [binary.MaxVarintLen32]byte
+	return binary.PutUvarint(buf[:], uint64(typeID))
+}
+
+func (c *linearCodec) PackPrefix(p *wrappers.Packer, valueType reflect.Type) error {
+	typeID, ok := c.registry.Load().typeIDs[valueType] // Get the type ID of the value being marshaled
 	if !ok {
 		return fmt.Errorf("can't marshal unregistered type %q", valueType)
 	}
-	p.PackInt(typeID) // Pack type ID so we know what to unmarshal this into
+	// Pack type ID so we know what to unmarshal this into
+	if c.varintTypeIDs {
+		var buf [binary.MaxVarintLen32]byte
+		n := binary.PutUvarint(buf[:], uint64(typeID))
+		p.PackFixedBytes(buf[:n])
+	} else {
+		p.PackInt(typeID)
+	}
 	return p.Err
 }
 
 func (c *linearCodec) UnpackPrefix(p *wrappers.Packer, valueType reflect.Type) (reflect.Value, error) {
-	c.lock.RLock()
-	defer c.lock.RUnlock()
+	return c.unpackPrefix(p, valueType, c.varintTypeIDs)
+}
 
-	typeID := p.UnpackInt() // Get the type ID
+// unpackPrefix unpacks the prefix of an interface, which holds a uvarint type
+// ID if [varintTypeIDs] is true and a 4 byte type ID otherwise.
+func (c *linearCodec) unpackPrefix(p *wrappers.Packer, valueType reflect.Type, varintTypeIDs bool) (reflect.Value, error) {
+	var typeID uint32 // Get the type ID
+	if varintTypeIDs {
+		var err error
+		typeID, err = unpackUvarint32(p)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("couldn't unmarshal interface: %w", err)
+		}
+	} else {
+		typeID = p.UnpackInt()
+	}
 	if p.Err != nil {
 		return reflect.Value{}, fmt.Errorf("couldn't unmarshal interface: %w", p.Err)
 	}
+	return c.newImplementor(typeID, valueType)
+}
+
+// newImplementor returns a new value of the type registered, or aliased, under
+// [typeID], which must implement [valueType].
+func (c *linearCodec) newImplementor(typeID uint32, valueType reflect.Type) (reflect.Value, error) {
 	// Get a type that implements the interface
-	implementingType, ok := c.registeredTypes.GetValue(typeID)
+	registry := c.registry.Load()
+	implementingType, ok := registry.types[typeID]
+	if !ok {
+		implementingType, ok = registry.aliases[typeID]
+	}
 	if !ok {
 		return reflect.Value{}, fmt.Errorf("couldn't unmarshal interface: unknown type ID %d", typeID)
 	}
@@ -126,3 +474,186 @@ func (c *linearCodec) UnpackPrefix(p *wrappers.Packer, valueType reflect.Type) (
 	}
 	return reflect.New(implementingType).Elem(), nil // instance of the proper type
 }
+
+// FormatTypeID returns the type ID [valueType] is registered under, in
+// decimal.
+func (c *linearCodec) FormatTypeID(valueType reflect.Type) (string, error) {
+	typeID, ok := c.registry.Load().typeIDs[valueType]
+	if !ok {
+		return "", fmt.Errorf("can't marshal unregistered type %q", valueType)
+	}
+	return strconv.FormatUint(uint64(typeID), 10), nil
+}
+
+func (c *linearCodec) ParseTypeID(typeID string, valueType reflect.Type) (reflect.Value, error) {
+	id, err := strconv.ParseUint(typeID, 10, 32)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w %q: %w", errInvalidTypeID, typeID, err)
+	}
+	return c.newImplementor(uint32(id), valueType)
+}
+
+// generated returns [value] as a pointer to a type that marshals itself, such
+// as a type with code generated by codecgen, if it is one.
+//
+// Reflection marshals such a value the same way, so this is only a shortcut
+// past it. Nil pointers are left to reflection to reject.
+func generated(value interface{}) (codec.Marshaler, codec.Unmarshaler, bool) {
+	marshaler, ok := value.(codec.Marshaler)
+	if !ok {
+		return nil, nil, false
+	}
+	unmarshaler, ok := value.(codec.Unmarshaler)
+	if !ok {
+		return nil, nil, false
+	}
+	if v := reflect.ValueOf(value); v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, nil, false
+	}
+	return marshaler, unmarshaler, true
+}
+
+func (c *linearCodec) Size(value interface{}) (int, error) {
+	marshaler, _, ok := generated(value)
+	if !ok {
+		return c.Codec.Size(value)
+	}
+	size, err := marshaler.CodecSize()
+	if err != nil {
+		return 0, fmt.Errorf("couldn't evaluate marshal length of %T: %w", value, err)
+	}
+	return size, nil
+}
+
+func (c *linearCodec) MarshalInto(value interface{}, p *wrappers.Packer) error {
+	marshaler, _, ok := generated(value)
+	if !ok {
+		return c.Codec.MarshalInto(value, p)
+	}
+	if err := marshaler.MarshalCodec(p); err != nil {
+		return fmt.Errorf("couldn't marshal %T: %w", value, err)
+	}
+	return p.Err
+}
+
+// ToJSON returns the JSON representation of [value]. Interface values hold
+// their type ID in decimal.
+func (c *linearCodec) ToJSON(value interface{}) ([]byte, error) {
+	jsonCodec, ok := c.Codec.(codec.JSONCodec)
+	if !ok {
+		return nil, codec.ErrJSONUnsupported
+	}
+	return jsonCodec.ToJSON(value)
+}
+
+func (c *linearCodec) FromJSON(bytes []byte, dest interface{}) error {
+	jsonCodec, ok := c.Codec.(codec.JSONCodec)
+	if !ok {
+		return codec.ErrJSONUnsupported
+	}
+	return jsonCodec.FromJSON(bytes, dest)
+}
+
+func (c *linearCodec) Unmarshal(bytes []byte, dest interface{}) error {
+	return c.UnmarshalWithArena(bytes, dest, nil)
+}
+
+// UnmarshalWithArena unmarshals [bytes] into [dest]. Values that unmarshal
+// themselves don't allocate from [arena].
+func (c *linearCodec) UnmarshalWithArena(bytes []byte, dest interface{}, arena *codec.Arena) error {
+	_, unmarshaler, ok := generated(dest)
+	if !ok {
+		return c.Codec.UnmarshalWithArena(bytes, dest, arena)
+	}
+
+	p := wrappers.Packer{
+		Bytes: bytes,
+	}
+	if err := unmarshal(&p, dest, unmarshaler); err != nil {
+		return err
+	}
+	if p.Offset != len(bytes) {
+		return fmt.Errorf("%w: read %d provided %d",
+			codec.ErrExtraSpace,
+			p.Offset,
+			len(bytes),
+		)
+	}
+	return nil
+}
+
+func (c *linearCodec) UnmarshalFrom(p *wrappers.Packer, dest interface{}) error {
+	_, unmarshaler, ok := generated(dest)
+	if !ok {
+		return c.Codec.UnmarshalFrom(p, dest)
+	}
+	return unmarshal(p, dest, unmarshaler)
+}
+
+func unmarshal(p *wrappers.Packer, dest interface{}, unmarshaler codec.Unmarshaler) error {
+	if err := unmarshaler.UnmarshalCodec(p); err != nil {
+		return fmt.Errorf("couldn't unmarshal %T: %w", dest, err)
+	}
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal %T: %w", dest, p.Err)
+	}
+	return nil
+}
+
+// UnmarshalWithProfile unmarshals [bytes] into [dest] as configured by
+// [profile]. The type IDs registered with this codec are used to unpack
+// interface values.
+func (c *linearCodec) UnmarshalWithProfile(bytes []byte, dest interface{}, profile codec.DecodeProfile) error {
+	key := fmt.Sprintf("%#v", profile)
+	if profileCodec, ok := c.profileCodecs.Load(key); ok {
+		return profileCodec.(codec.Codec).Unmarshal(bytes, dest)
+	}
+
+	profiler, ok := c.Codec.(reflectcodec.ProfileCodec)
+	if !ok {
+		return codec.ErrDecodeProfileUnsupported
+	}
+	typer := &profileTyper{
+		linearCodec:   c,
+		varintTypeIDs: c.varintTypeIDs && !profile.FixedTypeIDs,
+	}
+	profileCodec, _ := c.profileCodecs.LoadOrStore(key, profiler.WithDecodeProfile(typer, profile))
+	return profileCodec.(codec.Codec).Unmarshal(bytes, dest)
+}
+
+// profileTyper unpacks the type IDs of interface values as configured by a
+// decode profile.
+type profileTyper struct {
+	*linearCodec
+	varintTypeIDs bool
+}
+
+func (t *profileTyper) UnpackPrefix(p *wrappers.Packer, valueType reflect.Type) (reflect.Value, error) {
+	return t.unpackPrefix(p, valueType, t.varintTypeIDs)
+}
+
+// unpackUvarint32 unpacks a uvarint that fits in a uint32 and was encoded in
+// as few bytes as possible.
+func unpackUvarint32(p *wrappers.Packer) (uint32, error) {
+	var value uint64
+	for i := 0; i < binary.MaxVarintLen32; i++ {
+		b := p.UnpackByte()
+		if p.Err != nil {
+			return 0, p.Err
+		}
+		value |= uint64(b&0x7f) << (7 * i)
+		if b&0x80 != 0 {
+			continue
+		}
+		switch {
+		case value > math.MaxUint32:
+			return 0, fmt.Errorf("%w: type ID overflows uint32", errInvalidTypeID)
+		case i > 0 && b == 0:
+			// The last byte only holds zero bits, so a shorter encoding
+			// exists.
+			return 0, fmt.Errorf("%w: type ID isn't minimally encoded", codec.ErrNonCanonicalEncoding)
+		}
+		return uint32(value), nil
+	}
+	return 0, fmt.Errorf("%w: type ID is longer than %d bytes", errInvalidTypeID, binary.MaxVarintLen32)
+}
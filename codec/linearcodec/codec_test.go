@@ -4,9 +4,15 @@
 package linearcodec
 
 import (
+	"bytes"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 
 	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/reflectcodec"
 )
 
 func TestVectors(t *testing.T) {
@@ -16,6 +22,583 @@ func TestVectors(t *testing.T) {
 	}
 }
 
+func TestVectorsVarintTypeIDs(t *testing.T) {
+	for _, test := range codec.Tests {
+		c := NewDefault(WithVarintTypeIDs())
+		test(c, t)
+	}
+}
+
+type testInterface interface {
+	test()
+}
+
+type testImplementation struct {
+	Value uint8 `serialize:"true"`
+}
+
+func (*testImplementation) test() {}
+
+type testInterfaceHolder struct {
+	Value testInterface `serialize:"true"`
+}
+
+func TestVarintTypeIDs(t *testing.T) {
+	require := require.New(t)
+
+	// The fixed and varint encodings are registered as different versions of
+	// the same manager.
+	fixedCodec := NewDefault()
+	varintCodec := NewDefault(WithVarintTypeIDs())
+	for _, c := range []Codec{fixedCodec, varintCodec} {
+		c.SkipRegistrations(200)
+		require.NoError(c.RegisterType(&testImplementation{}))
+	}
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, fixedCodec))
+	require.NoError(manager.RegisterCodec(1, varintCodec))
+
+	value := &testInterfaceHolder{
+		Value: &testImplementation{Value: 7},
+	}
+	fixedBytes, err := manager.Marshal(0, value)
+	require.NoError(err)
+	require.Equal([]byte{
+		0x00, 0x00, // codec version
+		0x00, 0x00, 0x00, 0xc8, // type ID
+		0x07, // value
+	}, fixedBytes)
+
+	varintBytes, err := manager.Marshal(1, value)
+	require.NoError(err)
+	require.Equal([]byte{
+		0x00, 0x01, // codec version
+		0xc8, 0x01, // type ID
+		0x07, // value
+	}, varintBytes)
+
+	size, err := manager.Size(1, value)
+	require.NoError(err)
+	require.Len(varintBytes, size)
+
+	var unmarshalled testInterfaceHolder
+	version, err := manager.Unmarshal(varintBytes, &unmarshalled)
+	require.NoError(err)
+	require.Equal(uint16(1), version)
+	require.Equal(value, &unmarshalled)
+
+	// A type ID must be encoded in as few bytes as possible
+	_, err = manager.Unmarshal([]byte{0x00, 0x01, 0xc8, 0x81, 0x00, 0x07}, &unmarshalled)
+	require.ErrorIs(err, codec.ErrNonCanonicalEncoding)
+
+	_, err = manager.Unmarshal([]byte{0x00, 0x01, 0xff, 0xff, 0xff, 0xff, 0x7f, 0x07}, &unmarshalled)
+	require.ErrorIs(err, errInvalidTypeID)
+
+	// The encoding of the type IDs is part of the fingerprint
+	fixedFingerprint, err := manager.Fingerprint(0)
+	require.NoError(err)
+	varintFingerprint, err := manager.Fingerprint(1)
+	require.NoError(err)
+	require.NotEqual(fixedFingerprint, varintFingerprint)
+}
+
+func TestRegisterAlias(t *testing.T) {
+	require := require.New(t)
+
+	// The type was registered under type ID 0 before it was moved, and under
+	// type ID 1 after it was moved.
+	c := NewDefault()
+	require.NoError(c.RegisterAlias(&testImplementation{}))
+	require.NoError(c.RegisterType(&testImplementation{}))
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, c))
+
+	historicalBytes := []byte{
+		0x00, 0x00, // codec version
+		0x00, 0x00, 0x00, 0x00, // type ID
+		0x07, // value
+	}
+	var unmarshalled testInterfaceHolder
+	_, err := manager.Unmarshal(historicalBytes, &unmarshalled)
+	require.NoError(err)
+	require.Equal(&testImplementation{Value: 7}, unmarshalled.Value)
+
+	// The type is marshalled with the type ID it's registered under.
+	bytes, err := manager.Marshal(0, &unmarshalled)
+	require.NoError(err)
+	require.Equal([]byte{
+		0x00, 0x00, // codec version
+		0x00, 0x00, 0x00, 0x01, // type ID
+		0x07, // value
+	}, bytes)
+
+	// Aliases are part of the fingerprint.
+	withoutAlias := NewDefault()
+	withoutAlias.SkipRegistrations(1)
+	require.NoError(withoutAlias.RegisterType(&testImplementation{}))
+	fingerprint, err := c.(codec.Fingerprinter).Fingerprint()
+	require.NoError(err)
+	fingerprintWithoutAlias, err := withoutAlias.(codec.Fingerprinter).Fingerprint()
+	require.NoError(err)
+	require.NotEqual(fingerprint, fingerprintWithoutAlias)
+
+	c.Freeze()
+	err = c.RegisterAlias(&testImplementation{})
+	require.ErrorIs(err, codec.ErrRegistryFrozen)
+}
+
+type otherImplementation struct{}
+
+func (*otherImplementation) test() {}
+
+func TestRegisterTypeWithID(t *testing.T) {
+	require := require.New(t)
+
+	c := NewDefault()
+	require.NoError(c.RegisterTypeWithID(&testImplementation{}, 200))
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, c))
+
+	value := &testInterfaceHolder{
+		Value: &testImplementation{Value: 7},
+	}
+	valueBytes, err := manager.Marshal(0, value)
+	require.NoError(err)
+	require.Equal([]byte{
+		0x00, 0x00, // codec version
+		0x00, 0x00, 0x00, 0xc8, // type ID
+		0x07, // value
+	}, valueBytes)
+
+	var unmarshalled testInterfaceHolder
+	_, err = manager.Unmarshal(valueBytes, &unmarshalled)
+	require.NoError(err)
+	require.Equal(value, &unmarshalled)
+
+	// Type IDs can't be assigned twice, explicitly or in order.
+	err = c.RegisterTypeWithID(&otherImplementation{}, 200)
+	require.ErrorIs(err, codec.ErrDuplicateTypeID)
+
+	c.SkipRegistrations(200)
+	err = c.RegisterType(&otherImplementation{})
+	require.ErrorIs(err, codec.ErrDuplicateTypeID)
+	err = c.RegisterAlias(&otherImplementation{})
+	require.ErrorIs(err, codec.ErrDuplicateTypeID)
+
+	// Failed registrations don't use up the next type ID.
+	c.SkipRegistrations(1)
+	require.NoError(c.RegisterType(&otherImplementation{}))
+	registrations := c.Registrations()
+	require.Equal("201", registrations[len(registrations)-1].ID)
+}
+
+// Interfaces are marshalled and unmarshalled without taking the registration
+// lock, so registering a type while values are being marshalled must not race.
+func TestDescribeRegistry(t *testing.T) {
+	require := require.New(t)
+
+	c := NewDefault(WithVarintTypeIDs())
+	require.NoError(c.RegisterAlias(&testImplementation{}))
+	require.NoError(c.RegisterTypeWithID(&otherImplementation{}, 5))
+	require.NoError(c.RegisterType(&testImplementation{}))
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, c))
+
+	implementationSchema := &codec.TypeSchema{
+		Kind: codec.KindPointer,
+		Elem: &codec.TypeSchema{
+			Kind: codec.KindStruct,
+			Name: "linearcodec.testImplementation",
+			Fields: []codec.FieldSchema{
+				{
+					Name:        "Value",
+					MaxSliceLen: DefaultMaxSliceLength,
+					Type:        &codec.TypeSchema{Kind: "uint8"},
+				},
+			},
+		},
+	}
+	schema, err := manager.DescribeRegistry(0)
+	require.NoError(err)
+	require.Equal(
+		&codec.RegistrySchema{
+			TypeIDEncoding: codec.TypeIDEncodingUvarint,
+			Types: []codec.RegisteredTypeSchema{
+				{
+					TypeID: 0,
+					Alias:  true,
+					Name:   "*linearcodec.testImplementation",
+					Schema: implementationSchema,
+				},
+				{
+					TypeID: 1,
+					Name:   "*linearcodec.testImplementation",
+					Schema: implementationSchema,
+				},
+				{
+					TypeID: 5,
+					Name:   "*linearcodec.otherImplementation",
+					Schema: &codec.TypeSchema{
+						Kind: codec.KindPointer,
+						Elem: &codec.TypeSchema{
+							Kind:   codec.KindStruct,
+							Name:   "linearcodec.otherImplementation",
+							Fields: []codec.FieldSchema{},
+						},
+					},
+				},
+			},
+		},
+		schema,
+	)
+
+	_, err = manager.DescribeRegistry(1)
+	require.ErrorIs(err, codec.ErrUnknownVersion)
+}
+
+type jsonHolder struct {
+	Value    testInterface        `serialize:"true"`
+	Counts   map[string]uint32    `serialize:"true"`
+	Bytes    []byte               `serialize:"true"`
+	Checksum [2]byte              `serialize:"true"`
+	Next     *testInterfaceHolder `serialize:"true,nullable"`
+	Signed   []int16              `serialize:"true"`
+	Skipped  uint64
+}
+
+func TestJSON(t *testing.T) {
+	require := require.New(t)
+
+	c := NewDefault()
+	c.SkipRegistrations(3)
+	require.NoError(c.RegisterType(&testImplementation{}))
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, c))
+
+	value := &jsonHolder{
+		Value: &testImplementation{Value: 7},
+		Counts: map[string]uint32{
+			"bb": 2,
+			"a":  1,
+		},
+		Bytes:    []byte{0x01, 0xab},
+		Checksum: [2]byte{0xff, 0x00},
+		Signed:   []int16{-1, 2},
+		Skipped:  5,
+	}
+	jsonBytes, err := manager.ToJSON(0, value)
+	require.NoError(err)
+	require.JSONEq(`{
+		"Value": {"typeID": "3", "type": "*linearcodec.testImplementation", "value": {"Value": 7}},
+		"Counts": [{"key": "a", "value": 1}, {"key": "bb", "value": 2}],
+		"Bytes": "0x01ab",
+		"Checksum": "0xff00",
+		"Next": null,
+		"Signed": [-1, 2]
+	}`, string(jsonBytes))
+
+	// The JSON representation round-trips, except for the fields that aren't
+	// serialized.
+	var unmarshalled jsonHolder
+	require.NoError(manager.FromJSON(0, jsonBytes, &unmarshalled))
+	value.Skipped = 0
+	require.Equal(value, &unmarshalled)
+
+	err = manager.FromJSON(0, []byte(`{"Value": {"typeID": "three", "value": {}}}`), &unmarshalled)
+	require.ErrorIs(err, errInvalidTypeID)
+}
+
+func TestRegisterTypeWhileMarshalling(t *testing.T) {
+	require := require.New(t)
+
+	c := NewDefault()
+	require.NoError(c.RegisterType(&testImplementation{}))
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, c))
+
+	value := &testInterfaceHolder{
+		Value: &testImplementation{Value: 7},
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		c.SkipRegistrations(10)
+		_ = c.RegisterType(&otherImplementation{})
+	}()
+
+	for i := 0; i < 100; i++ {
+		valueBytes, err := manager.Marshal(0, value)
+		require.NoError(err)
+
+		var unmarshalled testInterfaceHolder
+		_, err = manager.Unmarshal(valueBytes, &unmarshalled)
+		require.NoError(err)
+		require.Equal(value, &unmarshalled)
+	}
+	wg.Wait()
+}
+
+func TestMaxDepth(t *testing.T) {
+	value := &testInterfaceHolder{
+		Value: &testImplementation{Value: 7},
+	}
+
+	// The value of the implementation is nested in the implementation, which
+	// is nested in the pointer to it, which is nested in the interface field
+	// of the holder.
+	tests := []struct {
+		name        string
+		maxDepth    uint32
+		expectedErr error
+	}{
+		{
+			name:        "too deep",
+			maxDepth:    3,
+			expectedErr: codec.ErrMaxDepth,
+		},
+		{
+			name:        "deep enough",
+			maxDepth:    4,
+			expectedErr: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			for _, c := range []Codec{
+				NewDefault(WithMaxDepth(test.maxDepth)),
+				NewSparse([]string{reflectcodec.DefaultTagName}, DefaultMaxSliceLength, WithMaxDepth(test.maxDepth)),
+			} {
+				require.NoError(c.RegisterType(&testImplementation{}))
+				manager := codec.NewDefaultManager()
+				require.NoError(manager.RegisterCodec(0, c))
+
+				bytes, err := manager.Marshal(0, value)
+				require.NoError(err)
+
+				var unmarshalled testInterfaceHolder
+				_, err = manager.Unmarshal(bytes, &unmarshalled)
+				require.ErrorIs(err, test.expectedErr)
+			}
+		})
+	}
+}
+
+type allocationHolder struct {
+	Values []uint64 `serialize:"true"`
+	Name   string   `serialize:"true"`
+}
+
+func TestAllocationLimit(t *testing.T) {
+	value := &allocationHolder{
+		Values: []uint64{1, 2, 3, 4},
+		Name:   "abcd",
+	}
+
+	tests := []struct {
+		name        string
+		limit       uint64
+		bytes       []byte
+		expectedErr error
+	}{
+		{
+			name:        "within limit",
+			limit:       36,
+			expectedErr: nil,
+		},
+		{
+			name:        "string exceeds limit",
+			limit:       35,
+			expectedErr: codec.ErrAllocationLimit,
+		},
+		{
+			name:        "slice exceeds limit",
+			limit:       31,
+			expectedErr: codec.ErrAllocationLimit,
+		},
+		{
+			// The length is checked against the limit before the missing
+			// values are noticed.
+			name:  "oversized length",
+			limit: 1024,
+			bytes: []byte{
+				0x00, 0x00, // codec version
+				0x00, 0x01, 0x00, 0x00, // number of values
+			},
+			expectedErr: codec.ErrAllocationLimit,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			manager := codec.NewDefaultManager()
+			require.NoError(manager.RegisterCodec(0, NewDefault(WithAllocationLimit(test.limit))))
+
+			bytes := test.bytes
+			if bytes == nil {
+				var err error
+				bytes, err = manager.Marshal(0, value)
+				require.NoError(err)
+			}
+
+			var unmarshalled allocationHolder
+			_, err := manager.Unmarshal(bytes, &unmarshalled)
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+}
+
+type maxLenHolder struct {
+	Values []uint32 `serialize:"true,maxLen=2"`
+	Name   string   `serialize:"true,maxLen=4"`
+}
+
+// looseMaxLenHolder has the same layout as maxLenHolder, without its limits.
+type looseMaxLenHolder struct {
+	Values []uint32 `serialize:"true"`
+	Name   string   `serialize:"true"`
+}
+
+func TestMaxLenTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       looseMaxLenHolder
+		expectedErr error
+	}{
+		{
+			name: "within limits",
+			value: looseMaxLenHolder{
+				Values: []uint32{1, 2},
+				Name:   "abcd",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "slice too long",
+			value: looseMaxLenHolder{
+				Values: []uint32{1, 2, 3},
+			},
+			expectedErr: codec.ErrMaxSliceLenExceeded,
+		},
+		{
+			name: "string too long",
+			value: looseMaxLenHolder{
+				Name: "abcde",
+			},
+			expectedErr: codec.ErrMaxSliceLenExceeded,
+		},
+	}
+	for _, c := range []Codec{
+		NewDefault(),
+		NewSparse([]string{reflectcodec.DefaultTagName}, DefaultMaxSliceLength),
+	} {
+		manager := codec.NewDefaultManager()
+		require.NoError(t, manager.RegisterCodec(0, c))
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				require := require.New(t)
+
+				value := maxLenHolder(test.value)
+				_, err := manager.Marshal(0, &value)
+				require.ErrorIs(err, test.expectedErr)
+				_, err = manager.Size(0, &value)
+				require.ErrorIs(err, test.expectedErr)
+
+				bytes, err := manager.Marshal(0, &test.value)
+				require.NoError(err)
+
+				var unmarshalled maxLenHolder
+				_, err = manager.Unmarshal(bytes, &unmarshalled)
+				require.ErrorIs(err, test.expectedErr)
+			})
+		}
+	}
+}
+
+type stringHolder struct {
+	Name string `serialize:"true"`
+}
+
+func TestUnsafeStrings(t *testing.T) {
+	require := require.New(t)
+
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, NewDefault(WithUnsafeStrings(), WithAllocationLimit(1))))
+
+	value := &stringHolder{
+		Name: "abcd",
+	}
+	valueBytes, err := manager.Marshal(0, value)
+	require.NoError(err)
+
+	// Strings aren't allocated, so they aren't charged to the allocation
+	// limit.
+	var unmarshalled stringHolder
+	_, err = manager.Unmarshal(valueBytes, &unmarshalled)
+	require.NoError(err)
+	require.Equal(value, &unmarshalled)
+
+	// Unmarshalled strings are views over the unmarshalled bytes.
+	valueBytes[bytes.Index(valueBytes, []byte("abcd"))] = 'z'
+	require.Equal("zbcd", unmarshalled.Name)
+}
+
+type legacyHolder struct {
+	Value   testInterface `serialize:"true"`
+	Created time.Time     `serialize:"true"`
+	Extra   uint8         `v0:"true"`
+}
+
+func TestUnmarshalWithLegacyProfile(t *testing.T) {
+	require := require.New(t)
+
+	c := NewDefault(WithVarintTypeIDs())
+	c.SkipRegistrations(200)
+	require.NoError(c.RegisterType(&testImplementation{}))
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, c))
+
+	// Encoded with a 4 byte type ID and before times were encoded natively,
+	// when a time.Time was a struct without serialized fields.
+	legacyBytes := []byte{
+		0x00, 0x00, // codec version
+		0x00, 0x00, 0x00, 0xc8, // type ID
+		0x07, // value
+	}
+
+	var unmarshalled legacyHolder
+	version, err := manager.UnmarshalWithProfile(legacyBytes, &unmarshalled, codec.LegacyDecodeProfile)
+	require.NoError(err)
+	require.Zero(version)
+	require.Equal(&testImplementation{Value: 7}, unmarshalled.Value)
+	require.True(unmarshalled.Created.IsZero())
+
+	// The profile can select a different set of tags.
+	profile := codec.LegacyDecodeProfile
+	profile.TagNames = []string{reflectcodec.DefaultTagName, "v0"}
+	unmarshalled = legacyHolder{}
+	_, err = manager.UnmarshalWithProfile(append(legacyBytes, 0x03), &unmarshalled, profile)
+	require.NoError(err)
+	require.Equal(uint8(3), unmarshalled.Extra)
+
+	// The profile doesn't change how the codec decodes values otherwise.
+	value := &legacyHolder{
+		Value:   &testImplementation{Value: 7},
+		Created: time.Unix(1, 0).UTC(),
+	}
+	valueBytes, err := manager.Marshal(0, value)
+	require.NoError(err)
+	unmarshalled = legacyHolder{}
+	_, err = manager.Unmarshal(valueBytes, &unmarshalled)
+	require.NoError(err)
+	require.Equal(value, &unmarshalled)
+}
+
 func TestMultipleTags(t *testing.T) {
 	for _, test := range codec.MultipleTagsTests {
 		c := New([]string{"tag1", "tag2"}, DefaultMaxSliceLength)
@@ -23,6 +606,13 @@ func TestMultipleTags(t *testing.T) {
 	}
 }
 
+func TestSparse(t *testing.T) {
+	for _, test := range codec.SparseTests {
+		c := NewSparse([]string{reflectcodec.DefaultTagName}, DefaultMaxSliceLength)
+		test(c, t)
+	}
+}
+
 func FuzzStructUnmarshalLinearCodec(f *testing.F) {
 	c := NewDefault()
 	codec.FuzzStructUnmarshal(c, f)
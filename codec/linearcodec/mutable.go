@@ -0,0 +1,92 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package linearcodec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/codec/reflectcodec"
+)
+
+var (
+	errUnknownTypeID = errors.New("unknown type ID")
+	errUnknownType   = errors.New("unknown type")
+
+	_ MutableCodec = (*linearCodec)(nil)
+)
+
+// MutableCodec is a Codec whose registered types can be replaced or removed
+// after registration, which allows fuzzers and simulators to inject mock
+// implementations of registered interfaces.
+//
+// It's only implemented when built with the "test" build tag, and the changes
+// are applied even if the codec is frozen. Codecs returned by this package
+// can be converted to a MutableCodec with a type assertion.
+type MutableCodec interface {
+	Codec
+
+	// ReplaceType registers the type of [val] under the type ID [id], in
+	// place of the type or alias registered under it.
+	ReplaceType(id uint32, val interface{}) error
+	// Deregister removes the type of [val], and any alias of it, from the
+	// registered types. Its type ID isn't reused.
+	Deregister(val interface{}) error
+}
+
+func (c *linearCodec) ReplaceType(id uint32, val interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	valType := reflect.TypeOf(val)
+	registry := c.registry.Load()
+	if registeredID, ok := registry.typeIDs[valType]; ok && registeredID != id {
+		return fmt.Errorf("%w: %v is registered under type ID %d", codec.ErrDuplicateType, valType, registeredID)
+	}
+	replacedType, isType := registry.types[id]
+	_, isAlias := registry.aliases[id]
+	if !isType && !isAlias {
+		return fmt.Errorf("%w: %d", errUnknownTypeID, id)
+	}
+	if checker, ok := c.Codec.(reflectcodec.TypeChecker); ok {
+		if err := checker.CheckType(valType); err != nil {
+			return fmt.Errorf("can't register %v: %w", valType, err)
+		}
+	}
+
+	registry = registry.clone()
+	delete(registry.aliases, id)
+	delete(registry.typeIDs, replacedType)
+	registry.typeIDs[valType] = id
+	registry.types[id] = valType
+	c.registry.Store(registry)
+	return nil
+}
+
+func (c *linearCodec) Deregister(val interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	valType := reflect.TypeOf(val)
+	registry := c.registry.Load()
+	id, ok := registry.typeIDs[valType]
+	if !ok {
+		return fmt.Errorf("%w: %v", errUnknownType, valType)
+	}
+
+	registry = registry.clone()
+	delete(registry.typeIDs, valType)
+	delete(registry.types, id)
+	for aliasID, aliasType := range registry.aliases {
+		if aliasType == valType {
+			delete(registry.aliases, aliasID)
+		}
+	}
+	c.registry.Store(registry)
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build test
+
+package linearcodec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/codec"
+)
+
+type mockImplementation struct {
+	Value uint8 `serialize:"true"`
+}
+
+func (*mockImplementation) test() {}
+
+func TestReplaceType(t *testing.T) {
+	require := require.New(t)
+
+	c := NewDefault()
+	require.NoError(c.RegisterType(&testImplementation{}))
+	c.Freeze()
+
+	mutable, ok := c.(MutableCodec)
+	require.True(ok)
+	require.NoError(mutable.ReplaceType(0, &mockImplementation{}))
+
+	manager := codec.NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, c))
+
+	valueBytes := []byte{
+		0x00, 0x00, // codec version
+		0x00, 0x00, 0x00, 0x00, // type ID
+		0x07, // value
+	}
+	var unmarshalled testInterfaceHolder
+	_, err := manager.Unmarshal(valueBytes, &unmarshalled)
+	require.NoError(err)
+	require.Equal(&mockImplementation{Value: 7}, unmarshalled.Value)
+
+	// Only registered type IDs can be replaced.
+	err = mutable.ReplaceType(1, &testImplementation{})
+	require.ErrorIs(err, errUnknownTypeID)
+}
+
+func TestDeregister(t *testing.T) {
+	require := require.New(t)
+
+	c := NewDefault()
+	require.NoError(c.RegisterType(&testImplementation{}))
+	require.NoError(c.RegisterAlias(&testImplementation{}))
+
+	mutable, ok := c.(MutableCodec)
+	require.True(ok)
+	require.NoError(mutable.Deregister(&testImplementation{}))
+	err := mutable.Deregister(&testImplementation{})
+	require.ErrorIs(err, errUnknownType)
+
+	// Neither the type ID nor the alias unmarshal into the type anymore.
+	for _, typeID := range []byte{0x00, 0x01} {
+		var unmarshalled testInterfaceHolder
+		err := c.Unmarshal([]byte{0x00, 0x00, 0x00, typeID, 0x07}, &unmarshalled)
+		require.ErrorContains(err, "unknown type ID")
+	}
+}
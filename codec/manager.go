@@ -6,8 +6,11 @@ package codec
 import (
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
@@ -20,6 +23,10 @@ const (
 	// Larger value --> need less memory allocations but possibly have allocated but unused memory
 	// Smaller value --> need more memory allocations but more efficient use of allocated memory
 	initialSliceCap = 128
+
+	// capacity of the buffer that values are marshaled into by MarshalInto
+	// before being written out
+	streamBufferCap = 4 * units.KiB
 )
 
 var (
@@ -49,10 +56,70 @@ type Manager interface {
 	// RegisterCodec must have been called with that version.
 	Marshal(version uint16, source interface{}) (destination []byte, err error)
 
+	// MarshalInto writes the bytes that Marshal would return for the given
+	// value and version to [w], without holding all of them in memory at
+	// once.
+	// RegisterCodec must have been called with that version.
+	MarshalInto(version uint16, source interface{}, w io.Writer) error
+
+	// HashOf returns the hash of the bytes that Marshal would return for the
+	// given value and version. The marshalled bytes are never exposed, which
+	// allows the buffer they are written into to be reused.
+	// RegisterCodec must have been called with that version.
+	HashOf(version uint16, source interface{}) (ids.ID, error)
+
 	// Unmarshal the given bytes into the given destination. [destination] must
 	// be a pointer or an interface. Returns the version of the codec that
 	// produces the given bytes.
 	Unmarshal(source []byte, destination interface{}) (version uint16, err error)
+
+	// UnmarshalWithArena is the same as Unmarshal, but allocates the values
+	// created while unmarshalling from [arena]. This reduces GC pressure when
+	// decoding many values back-to-back.
+	UnmarshalWithArena(source []byte, destination interface{}, arena *Arena) (version uint16, err error)
+
+	// UnmarshalFrom is the same as Unmarshal, but reads the bytes from [r]
+	// as they are needed. All of [r] must be consumed by the value. Reading
+	// stops with an error as soon as the value is malformed or exceeds the
+	// maximum size.
+	UnmarshalFrom(r io.Reader, destination interface{}) (version uint16, err error)
+
+	// UnmarshalWithProfile is the same as Unmarshal, but decodes the value as
+	// configured by [profile]. This allows payloads encoded by an older
+	// configuration of a codec to be read with the codec that replaced it.
+	// Returns [ErrDecodeProfileUnsupported] if the codec isn't a
+	// [ProfileUnmarshaler].
+	UnmarshalWithProfile(source []byte, destination interface{}, profile DecodeProfile) (version uint16, err error)
+
+	// Fingerprint returns the fingerprint of the codec with the given version.
+	// Nodes can compare fingerprints to detect that their type registrations
+	// have drifted apart.
+	// RegisterCodec must have been called with that version.
+	// Returns [ErrFingerprintUnsupported] if the codec isn't a [Fingerprinter].
+	Fingerprint(version uint16) (ids.ID, error)
+
+	// VerifyFingerprint returns an error wrapping [ErrFingerprintMismatch] if
+	// the fingerprint of the codec with the given version isn't [expected].
+	VerifyFingerprint(version uint16, expected ids.ID) error
+
+	// DescribeRegistry returns the schema of the types registered with the
+	// codec with the given version.
+	// RegisterCodec must have been called with that version.
+	// Returns [ErrRegistrySchemaUnsupported] if the codec isn't a
+	// [RegistryDescriber].
+	DescribeRegistry(version uint16) (*RegistrySchema, error)
+
+	// ToJSON returns the JSON representation of [source] by the codec with
+	// the given version. The version isn't part of the representation.
+	// RegisterCodec must have been called with that version.
+	// Returns [ErrJSONUnsupported] if the codec isn't a [JSONCodec].
+	ToJSON(version uint16, source interface{}) ([]byte, error)
+
+	// FromJSON unmarshals the JSON representation [source] into
+	// [destination] with the codec with the given version.
+	// RegisterCodec must have been called with that version.
+	// Returns [ErrJSONUnsupported] if the codec isn't a [JSONCodec].
+	FromJSON(version uint16, source []byte, destination interface{}) error
 }
 
 // NewManager returns a new codec manager.
@@ -60,6 +127,12 @@ func NewManager(maxSize int) Manager {
 	return &manager{
 		maxSize: maxSize,
 		codecs:  map[uint16]Codec{},
+		bufferPool: sync.Pool{
+			New: func() interface{} {
+				buf := make([]byte, 0, initialSliceCap)
+				return &buf
+			},
+		},
 	}
 }
 
@@ -72,6 +145,11 @@ type manager struct {
 	lock    sync.RWMutex
 	maxSize int
 	codecs  map[uint16]Codec
+
+	// Buffers used by HashOf.
+	// Invariant: Every value returned by [bufferPool] is a *[]byte with
+	// length 0.
+	bufferPool sync.Pool
 }
 
 // RegisterCodec is used to register a new codec version that can be used to
@@ -130,9 +208,77 @@ func (m *manager) Marshal(version uint16, value interface{}) ([]byte, error) {
 	return p.Bytes, c.MarshalInto(value, &p)
 }
 
+func (m *manager) MarshalInto(version uint16, value interface{}, w io.Writer) error {
+	if value == nil {
+		return ErrMarshalNil // can't marshal nil
+	}
+
+	m.lock.RLock()
+	c, exists := m.codecs[version]
+	m.lock.RUnlock()
+	if !exists {
+		return ErrUnknownVersion
+	}
+
+	p := wrappers.Packer{
+		MaxSize: m.maxSize,
+		Bytes:   make([]byte, 0, streamBufferCap),
+		Writer:  w,
+	}
+	p.PackShort(version)
+	if p.Errored() {
+		return ErrCantPackVersion // Should never happen
+	}
+	if err := c.MarshalInto(value, &p); err != nil {
+		return err
+	}
+	p.Flush()
+	return p.Err
+}
+
+func (m *manager) HashOf(version uint16, value interface{}) (ids.ID, error) {
+	if value == nil {
+		return ids.Empty, ErrMarshalNil // can't marshal nil
+	}
+
+	m.lock.RLock()
+	c, exists := m.codecs[version]
+	m.lock.RUnlock()
+	if !exists {
+		return ids.Empty, ErrUnknownVersion
+	}
+
+	buf := m.bufferPool.Get().(*[]byte)
+	p := wrappers.Packer{
+		MaxSize: m.maxSize,
+		Bytes:   *buf,
+	}
+	p.PackShort(version)
+	if p.Errored() {
+		return ids.Empty, ErrCantPackVersion // Should never happen
+	}
+	if err := c.MarshalInto(value, &p); err != nil {
+		return ids.Empty, err
+	}
+	id := hashing.ComputeHash256Array(p.Bytes)
+
+	// The packer may have grown the buffer, so the grown buffer is returned
+	// to the pool.
+	*buf = p.Bytes[:0]
+	m.bufferPool.Put(buf)
+	return id, nil
+}
+
 // Unmarshal unmarshals [bytes] into [dest], where [dest] must be a pointer or
 // interface.
 func (m *manager) Unmarshal(bytes []byte, dest interface{}) (uint16, error) {
+	return m.UnmarshalWithArena(bytes, dest, nil)
+}
+
+// UnmarshalWithArena unmarshals [bytes] into [dest], where [dest] must be a
+// pointer or interface. Values created while unmarshalling are allocated from
+// [arena].
+func (m *manager) UnmarshalWithArena(bytes []byte, dest interface{}, arena *Arena) (uint16, error) {
 	if dest == nil {
 		return 0, ErrUnmarshalNil
 	}
@@ -155,5 +301,154 @@ func (m *manager) Unmarshal(bytes []byte, dest interface{}) (uint16, error) {
 	if !exists {
 		return version, ErrUnknownVersion
 	}
-	return version, c.Unmarshal(p.Bytes[p.Offset:], dest)
+	return version, c.UnmarshalWithArena(p.Bytes[p.Offset:], dest, arena)
+}
+
+func (m *manager) UnmarshalFrom(r io.Reader, dest interface{}) (uint16, error) {
+	if dest == nil {
+		return 0, ErrUnmarshalNil
+	}
+
+	p := wrappers.Packer{
+		MaxSize: m.maxSize,
+		Reader:  r,
+	}
+	version := p.UnpackShort()
+	if p.Errored() { // Make sure the codec version is correct
+		return 0, ErrCantUnpackVersion
+	}
+
+	m.lock.RLock()
+	c, exists := m.codecs[version]
+	m.lock.RUnlock()
+	if !exists {
+		return version, ErrUnknownVersion
+	}
+	if err := c.UnmarshalFrom(&p, dest); err != nil {
+		return version, err
+	}
+
+	// Like Unmarshal, reject bytes beyond the value, whether they were
+	// already read or are still in [r].
+	if p.Offset != len(p.Bytes) {
+		return version, fmt.Errorf("%w: %d unread bytes", ErrExtraSpace, len(p.Bytes)-p.Offset)
+	}
+	var next [1]byte
+	switch _, err := io.ReadFull(r, next[:]); {
+	case err == nil:
+		return version, fmt.Errorf("%w: unread bytes remain in reader", ErrExtraSpace)
+	case !errors.Is(err, io.EOF):
+		return version, err
+	}
+	return version, nil
+}
+
+// UnmarshalWithProfile unmarshals [bytes] into [dest], where [dest] must be a
+// pointer or interface, as configured by [profile].
+func (m *manager) UnmarshalWithProfile(bytes []byte, dest interface{}, profile DecodeProfile) (uint16, error) {
+	if dest == nil {
+		return 0, ErrUnmarshalNil
+	}
+
+	if byteLen := len(bytes); byteLen > m.maxSize {
+		return 0, fmt.Errorf("%w: %d > %d", ErrUnmarshalTooBig, byteLen, m.maxSize)
+	}
+
+	p := wrappers.Packer{
+		Bytes: bytes,
+	}
+	version := p.UnpackShort()
+	if p.Errored() { // Make sure the codec version is correct
+		return 0, ErrCantUnpackVersion
+	}
+
+	m.lock.RLock()
+	c, exists := m.codecs[version]
+	m.lock.RUnlock()
+	if !exists {
+		return version, ErrUnknownVersion
+	}
+
+	unmarshaler, ok := c.(ProfileUnmarshaler)
+	if !ok {
+		return version, fmt.Errorf("%w: version %d", ErrDecodeProfileUnsupported, version)
+	}
+	return version, unmarshaler.UnmarshalWithProfile(p.Bytes[p.Offset:], dest, profile)
+}
+
+func (m *manager) Fingerprint(version uint16) (ids.ID, error) {
+	m.lock.RLock()
+	c, exists := m.codecs[version]
+	m.lock.RUnlock()
+	if !exists {
+		return ids.Empty, ErrUnknownVersion
+	}
+
+	fingerprinter, ok := c.(Fingerprinter)
+	if !ok {
+		return ids.Empty, fmt.Errorf("%w: version %d", ErrFingerprintUnsupported, version)
+	}
+	return fingerprinter.Fingerprint()
+}
+
+func (m *manager) VerifyFingerprint(version uint16, expected ids.ID) error {
+	fingerprint, err := m.Fingerprint(version)
+	if err != nil {
+		return err
+	}
+	if fingerprint != expected {
+		return fmt.Errorf("%w: version %d expected %s but got %s",
+			ErrFingerprintMismatch,
+			version,
+			expected,
+			fingerprint,
+		)
+	}
+	return nil
+}
+
+func (m *manager) DescribeRegistry(version uint16) (*RegistrySchema, error) {
+	m.lock.RLock()
+	c, exists := m.codecs[version]
+	m.lock.RUnlock()
+	if !exists {
+		return nil, ErrUnknownVersion
+	}
+
+	describer, ok := c.(RegistryDescriber)
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", ErrRegistrySchemaUnsupported, version)
+	}
+	return describer.DescribeRegistry()
+}
+
+func (m *manager) ToJSON(version uint16, value interface{}) ([]byte, error) {
+	jsonCodec, err := m.jsonCodec(version)
+	if err != nil {
+		return nil, err
+	}
+	return jsonCodec.ToJSON(value)
+}
+
+func (m *manager) FromJSON(version uint16, bytes []byte, dest interface{}) error {
+	jsonCodec, err := m.jsonCodec(version)
+	if err != nil {
+		return err
+	}
+	return jsonCodec.FromJSON(bytes, dest)
+}
+
+func (m *manager) jsonCodec(version uint16) (JSONCodec, error) {
+	m.lock.RLock()
+	c, exists := m.codecs[version]
+	m.lock.RUnlock()
+	if !exists {
+		return nil, ErrUnknownVersion
+	}
+
+	jsonCodec, ok := c.(JSONCodec)
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", ErrJSONUnsupported, version)
+	}
+	return jsonCodec, nil
 }
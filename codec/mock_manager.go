@@ -8,8 +8,10 @@
 package codec
 
 import (
+	io "io"
 	reflect "reflect"
 
+	ids "github.com/ava-labs/avalanchego/ids"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -36,6 +38,65 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 	return m.recorder
 }
 
+// DescribeRegistry mocks base method.
+func (m *MockManager) DescribeRegistry(arg0 uint16) (*RegistrySchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeRegistry", arg0)
+	ret0, _ := ret[0].(*RegistrySchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeRegistry indicates an expected call of DescribeRegistry.
+func (mr *MockManagerMockRecorder) DescribeRegistry(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeRegistry", reflect.TypeOf((*MockManager)(nil).DescribeRegistry), arg0)
+}
+
+// Fingerprint mocks base method.
+func (m *MockManager) Fingerprint(arg0 uint16) (ids.ID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fingerprint", arg0)
+	ret0, _ := ret[0].(ids.ID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fingerprint indicates an expected call of Fingerprint.
+func (mr *MockManagerMockRecorder) Fingerprint(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fingerprint", reflect.TypeOf((*MockManager)(nil).Fingerprint), arg0)
+}
+
+// FromJSON mocks base method.
+func (m *MockManager) FromJSON(arg0 uint16, arg1 []byte, arg2 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FromJSON", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FromJSON indicates an expected call of FromJSON.
+func (mr *MockManagerMockRecorder) FromJSON(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FromJSON", reflect.TypeOf((*MockManager)(nil).FromJSON), arg0, arg1, arg2)
+}
+
+// HashOf mocks base method.
+func (m *MockManager) HashOf(arg0 uint16, arg1 interface{}) (ids.ID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashOf", arg0, arg1)
+	ret0, _ := ret[0].(ids.ID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashOf indicates an expected call of HashOf.
+func (mr *MockManagerMockRecorder) HashOf(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashOf", reflect.TypeOf((*MockManager)(nil).HashOf), arg0, arg1)
+}
+
 // Marshal mocks base method.
 func (m *MockManager) Marshal(arg0 uint16, arg1 interface{}) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +112,20 @@ func (mr *MockManagerMockRecorder) Marshal(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Marshal", reflect.TypeOf((*MockManager)(nil).Marshal), arg0, arg1)
 }
 
+// MarshalInto mocks base method.
+func (m *MockManager) MarshalInto(arg0 uint16, arg1 interface{}, arg2 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarshalInto", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarshalInto indicates an expected call of MarshalInto.
+func (mr *MockManagerMockRecorder) MarshalInto(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarshalInto", reflect.TypeOf((*MockManager)(nil).MarshalInto), arg0, arg1, arg2)
+}
+
 // RegisterCodec mocks base method.
 func (m *MockManager) RegisterCodec(arg0 uint16, arg1 Codec) error {
 	m.ctrl.T.Helper()
@@ -80,6 +155,21 @@ func (mr *MockManagerMockRecorder) Size(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Size", reflect.TypeOf((*MockManager)(nil).Size), arg0, arg1)
 }
 
+// ToJSON mocks base method.
+func (m *MockManager) ToJSON(arg0 uint16, arg1 interface{}) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToJSON", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ToJSON indicates an expected call of ToJSON.
+func (mr *MockManagerMockRecorder) ToJSON(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToJSON", reflect.TypeOf((*MockManager)(nil).ToJSON), arg0, arg1)
+}
+
 // Unmarshal mocks base method.
 func (m *MockManager) Unmarshal(arg0 []byte, arg1 interface{}) (uint16, error) {
 	m.ctrl.T.Helper()
@@ -94,3 +184,62 @@ func (mr *MockManagerMockRecorder) Unmarshal(arg0, arg1 interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unmarshal", reflect.TypeOf((*MockManager)(nil).Unmarshal), arg0, arg1)
 }
+
+// UnmarshalFrom mocks base method.
+func (m *MockManager) UnmarshalFrom(arg0 io.Reader, arg1 interface{}) (uint16, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarshalFrom", arg0, arg1)
+	ret0, _ := ret[0].(uint16)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnmarshalFrom indicates an expected call of UnmarshalFrom.
+func (mr *MockManagerMockRecorder) UnmarshalFrom(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarshalFrom", reflect.TypeOf((*MockManager)(nil).UnmarshalFrom), arg0, arg1)
+}
+
+// UnmarshalWithArena mocks base method.
+func (m *MockManager) UnmarshalWithArena(arg0 []byte, arg1 interface{}, arg2 *Arena) (uint16, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarshalWithArena", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uint16)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnmarshalWithArena indicates an expected call of UnmarshalWithArena.
+func (mr *MockManagerMockRecorder) UnmarshalWithArena(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarshalWithArena", reflect.TypeOf((*MockManager)(nil).UnmarshalWithArena), arg0, arg1, arg2)
+}
+
+// UnmarshalWithProfile mocks base method.
+func (m *MockManager) UnmarshalWithProfile(arg0 []byte, arg1 interface{}, arg2 DecodeProfile) (uint16, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarshalWithProfile", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uint16)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnmarshalWithProfile indicates an expected call of UnmarshalWithProfile.
+func (mr *MockManagerMockRecorder) UnmarshalWithProfile(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarshalWithProfile", reflect.TypeOf((*MockManager)(nil).UnmarshalWithProfile), arg0, arg1, arg2)
+}
+
+// VerifyFingerprint mocks base method.
+func (m *MockManager) VerifyFingerprint(arg0 uint16, arg1 ids.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyFingerprint", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyFingerprint indicates an expected call of VerifyFingerprint.
+func (mr *MockManagerMockRecorder) VerifyFingerprint(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyFingerprint", reflect.TypeOf((*MockManager)(nil).VerifyFingerprint), arg0, arg1)
+}
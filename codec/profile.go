@@ -0,0 +1,47 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import "errors"
+
+var (
+	ErrDecodeProfileUnsupported = errors.New("codec doesn't support decode profiles")
+
+	// LegacyDecodeProfile decodes payloads that were encoded before codecs
+	// could be configured with options and before time.Time, *big.Int and
+	// Marshaler types were encoded natively.
+	LegacyDecodeProfile = DecodeProfile{
+		FixedTypeIDs:   true,
+		ReflectedTypes: true,
+	}
+)
+
+// DecodeProfile changes how a codec decodes values, so that payloads encoded
+// by an older configuration of the codec can be read without registering a
+// separate codec for them.
+type DecodeProfile struct {
+	// TagNames are the struct tags that mark serialized fields. If empty, the
+	// tags of the codec are used.
+	TagNames []string
+
+	// MaxSliceLen is the maximum length of slices, maps and strings. If 0,
+	// the maximum of the codec is used.
+	MaxSliceLen uint32
+
+	// FixedTypeIDs unpacks the type IDs of interface values as 4 bytes, even
+	// if the codec encodes them as uvarints.
+	FixedTypeIDs bool
+
+	// ReflectedTypes decodes time.Time, *big.Int and types that implement
+	// Marshaler field by field, like any other type.
+	ReflectedTypes bool
+}
+
+// ProfileUnmarshaler is implemented by codecs that can decode values with a
+// DecodeProfile.
+type ProfileUnmarshaler interface {
+	// UnmarshalWithProfile is the same as Unmarshal, but decodes the value as
+	// configured by [profile].
+	UnmarshalWithProfile(source []byte, destination interface{}, profile DecodeProfile) error
+}
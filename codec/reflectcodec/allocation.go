@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/math"
+)
+
+// allocationBudget is the number of bytes that may still be allocated while
+// unmarshalling a single value. A nil budget is unlimited.
+type allocationBudget struct {
+	limit     uint64
+	remaining uint64
+}
+
+// newAllocationBudget returns the budget of a single call to unmarshal, or nil
+// if allocations aren't limited.
+func (c *genericCodec) newAllocationBudget() *allocationBudget {
+	if c.allocationLimit == 0 {
+		return nil
+	}
+	return &allocationBudget{
+		limit:     c.allocationLimit,
+		remaining: c.allocationLimit,
+	}
+}
+
+// spend removes [count] values of [size] bytes from the budget.
+// Returns [codec.ErrAllocationLimit] if the budget doesn't cover them, in
+// which case the values must not be allocated.
+func (b *allocationBudget) spend(count uint64, size uintptr) error {
+	if b == nil {
+		return nil
+	}
+	bytes, err := math.Mul64(count, uint64(size))
+	if err != nil || bytes > b.remaining {
+		return fmt.Errorf("%w: allocating %d values of %d bytes exceeds the limit of %d bytes",
+			codec.ErrAllocationLimit,
+			count,
+			size,
+			b.limit,
+		)
+	}
+	b.remaining -= bytes
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	marshalerType   = reflect.TypeOf((*codec.Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*codec.Unmarshaler)(nil)).Elem()
+)
+
+// isCustom returns true iff [t] marshals itself.
+//
+// That's the case if [t] isn't a pointer or an interface, [t] or [*t]
+// implements [codec.Marshaler], and [*t] implements [codec.Unmarshaler].
+// Pointers and interfaces are handled as usual, so they can be nil, and the
+// value they hold marshals itself.
+func (c *genericCodec) isCustom(t reflect.Type) bool {
+	if c.reflectedTypes {
+		return false
+	}
+	if isCustom, ok := c.customTypes.Load(t); ok {
+		return isCustom.(bool)
+	}
+
+	isCustom := false
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+	default:
+		ptrType := reflect.PointerTo(t)
+		isCustom = (t.Implements(marshalerType) || ptrType.Implements(marshalerType)) &&
+			ptrType.Implements(unmarshalerType)
+	}
+	c.customTypes.Store(t, isCustom)
+	return isCustom
+}
+
+// asMarshaler returns [value] as a [codec.Marshaler]. If only a pointer to
+// [value] implements it and [value] isn't addressable, a copy of [value] is
+// returned.
+func asMarshaler(value reflect.Value) codec.Marshaler {
+	if value.Type().Implements(marshalerType) {
+		return value.Interface().(codec.Marshaler)
+	}
+	if value.CanAddr() {
+		return value.Addr().Interface().(codec.Marshaler)
+	}
+	ptr := reflect.New(value.Type())
+	ptr.Elem().Set(value)
+	return ptr.Interface().(codec.Marshaler)
+}
+
+// [value] must be of a type that marshals itself.
+func customSize(value reflect.Value) (int, error) {
+	size, err := asMarshaler(value).CodecSize()
+	if err != nil {
+		return 0, fmt.Errorf("couldn't evaluate marshal length of %s: %w", value.Type(), err)
+	}
+	return size, nil
+}
+
+// [value] must be of a type that marshals itself.
+func marshalCustom(value reflect.Value, p *wrappers.Packer) error {
+	if err := asMarshaler(value).MarshalCodec(p); err != nil {
+		return fmt.Errorf("couldn't marshal %s: %w", value.Type(), err)
+	}
+	return p.Err
+}
+
+// [value] must be a settable value of a type that unmarshals itself.
+func unmarshalCustom(p *wrappers.Packer, value reflect.Value) error {
+	if err := value.Addr().Interface().(codec.Unmarshaler).UnmarshalCodec(p); err != nil {
+		return fmt.Errorf("couldn't unmarshal %s: %w", value.Type(), err)
+	}
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal %s: %w", value.Type(), p.Err)
+	}
+	return nil
+}
@@ -0,0 +1,597 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const jsonHexPrefix = "0x"
+
+var (
+	_ codec.JSONCodec = (*genericCodec)(nil)
+
+	jsonNull = []byte("null")
+
+	errUnexpectedJSONNull  = errors.New("unexpected null")
+	errUnknownJSONField    = errors.New("unknown field")
+	errInvalidJSONHex      = errors.New("invalid hex string")
+	errWrongJSONArrayLen   = errors.New("wrong array length")
+	errInvalidJSONBigInt   = errors.New("invalid big integer")
+	errMissingJSONTypeInfo = errors.New("interface value is missing its type ID")
+)
+
+// JSONTypeCodec formats the type IDs of interface values in their JSON
+// representation.
+type JSONTypeCodec interface {
+	// FormatTypeID returns the type ID that [t] is registered under.
+	FormatTypeID(t reflect.Type) (string, error)
+
+	// ParseTypeID returns a new value of the type registered under [typeID].
+	// The type must implement [valueType].
+	ParseTypeID(typeID string, valueType reflect.Type) (reflect.Value, error)
+}
+
+// jsonInterface is the JSON representation of a non-nil interface value.
+type jsonInterface struct {
+	TypeID string `json:"typeID"`
+	// The name of the type of the value, which must be the type registered
+	// under [TypeID].
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// jsonMapEntry is the JSON representation of a map entry.
+type jsonMapEntry struct {
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ToJSON returns the JSON representation of [value]:
+//
+//  1. Structs are objects holding their serialized fields, in the order they
+//     are serialized in, keyed by the field names.
+//  2. Integers, bools and strings are JSON numbers, bools and strings.
+//  3. Byte slices and byte arrays are 0x prefixed hex strings. Other slices
+//     and arrays are arrays.
+//  4. Maps are arrays of {"key", "value"} objects, sorted by the binary
+//     encoding of the keys.
+//  5. Nil pointers and interfaces, and empty fields tagged with omitempty, are
+//     null. Interfaces holding a value are {"typeID", "type", "value"}
+//     objects, where "type" is the name of the type registered under
+//     "typeID".
+//  6. time.Time values are RFC 3339 strings in UTC, *big.Int values are
+//     decimal strings, and protobuf messages are in their canonical JSON
+//     form.
+//  7. Types that marshal themselves are 0x prefixed hex strings holding their
+//     binary encoding.
+//
+// Fields of sparse codecs are never omitted. Limits on the lengths of values
+// aren't enforced.
+func (c *genericCodec) ToJSON(value interface{}) ([]byte, error) {
+	if value == nil {
+		return nil, errMarshalNil // can't marshal nil
+	}
+
+	if _, ok := c.typer.(JSONTypeCodec); !ok {
+		return nil, codec.ErrJSONUnsupported
+	}
+	var buf bytes.Buffer
+	if err := c.marshalJSON(&buf, reflect.ValueOf(value), false /*=nullable*/, nil /*=typeStack*/); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalJSON writes the JSON representation of [value] to [buf].
+func (c *genericCodec) marshalJSON(
+	buf *bytes.Buffer,
+	value reflect.Value,
+	nullable bool,
+	typeStack set.Set[reflect.Type],
+) error {
+	valueType := value.Type()
+	if c.isCustom(valueType) {
+		p := wrappers.Packer{
+			MaxSize: math.MaxInt32,
+		}
+		if err := marshalCustom(value, &p); err != nil {
+			return err
+		}
+		return writeJSON(buf, jsonHexPrefix+hex.EncodeToString(p.Bytes))
+	}
+
+	switch valueKind := value.Kind(); valueKind {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(value.Uint(), 10))
+		return nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(value.Int(), 10))
+		return nil
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(value.Bool()))
+		return nil
+	case reflect.String:
+		return writeJSON(buf, value.String())
+	case reflect.Ptr:
+		if value.IsNil() {
+			if !nullable {
+				return errMarshalNil
+			}
+			buf.Write(jsonNull)
+			return nil
+		}
+
+		if isProtoMessage(valueType) {
+			bytes, err := protojson.Marshal(value.Interface().(proto.Message))
+			if err != nil {
+				return fmt.Errorf("couldn't marshal protobuf message %s: %w", valueType, err)
+			}
+			buf.Write(bytes)
+			return nil
+		}
+		if c.isBigInt(valueType) {
+			return writeJSON(buf, value.Interface().(*big.Int).String())
+		}
+		return c.marshalJSON(buf, value.Elem(), false /*=nullable*/, typeStack)
+	case reflect.Interface:
+		if value.IsNil() {
+			if !nullable {
+				return errMarshalNil
+			}
+			buf.Write(jsonNull)
+			return nil
+		}
+
+		underlyingType := value.Elem().Type()
+		if typeStack.Contains(underlyingType) {
+			return fmt.Errorf("%w: %s", errRecursiveInterfaceTypes, underlyingType)
+		}
+		typeID, err := c.typer.(JSONTypeCodec).FormatTypeID(underlyingType)
+		if err != nil {
+			return err
+		}
+
+		typeStack.Add(underlyingType)
+		buf.WriteString(`{"typeID":`)
+		if err := writeJSON(buf, typeID); err != nil {
+			return err
+		}
+		buf.WriteString(`,"type":`)
+		if err := writeJSON(buf, underlyingType.String()); err != nil {
+			return err
+		}
+		buf.WriteString(`,"value":`)
+		if err := c.marshalJSON(buf, value.Elem(), false /*=nullable*/, typeStack); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		typeStack.Remove(underlyingType)
+		return nil
+	case reflect.Slice, reflect.Array:
+		numElts := value.Len()
+		if valueType.Elem().Kind() == reflect.Uint8 {
+			bytes := make([]byte, numElts)
+			for i := range bytes {
+				bytes[i] = byte(value.Index(i).Uint())
+			}
+			return writeJSON(buf, jsonHexPrefix+hex.EncodeToString(bytes))
+		}
+
+		buf.WriteByte('[')
+		for i := 0; i < numElts; i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := c.marshalJSON(buf, value.Index(i), nullable, typeStack); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case reflect.Struct:
+		if c.isTime(valueType) {
+			return writeJSON(buf, value.Interface().(time.Time).UTC().Format(time.RFC3339Nano))
+		}
+
+		serializedFields, err := c.fielder.GetSerializedFields(valueType)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('{')
+		for i, fieldDesc := range serializedFields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, valueType.Field(fieldDesc.Index).Name); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			field := value.Field(fieldDesc.Index)
+			if fieldDesc.OmitEmpty && isEmpty(field) {
+				buf.Write(jsonNull)
+				continue
+			}
+			if err := c.marshalJSON(buf, field, fieldDesc.Nullable, typeStack); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case reflect.Map:
+		// Entries are sorted by the binary encoding of their keys, as they
+		// are when the map is marshalled.
+		type keyTuple struct {
+			key   reflect.Value
+			bytes []byte
+		}
+		keys := value.MapKeys()
+		sortedKeys := make([]keyTuple, len(keys))
+		for i, key := range keys {
+			p := wrappers.Packer{
+				MaxSize: math.MaxInt32,
+			}
+			if err := c.marshal(key, &p, c.maxSliceLen, false /*=nullable*/, typeStack); err != nil {
+				return err
+			}
+			sortedKeys[i] = keyTuple{
+				key:   key,
+				bytes: p.Bytes,
+			}
+		}
+		slices.SortFunc(sortedKeys, func(a, b keyTuple) bool {
+			return bytes.Compare(a.bytes, b.bytes) < 0
+		})
+
+		buf.WriteByte('[')
+		for i, key := range sortedKeys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`{"key":`)
+			if err := c.marshalJSON(buf, key.key, false /*=nullable*/, typeStack); err != nil {
+				return err
+			}
+			buf.WriteString(`,"value":`)
+			if err := c.marshalJSON(buf, value.MapIndex(key.key), nullable, typeStack); err != nil {
+				return err
+			}
+			buf.WriteByte('}')
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", codec.ErrUnsupportedType, valueKind)
+	}
+}
+
+// FromJSON unmarshals the JSON representation [bytes], as returned by ToJSON,
+// into [dest], which must be a pointer.
+//
+// Every serialized field of a struct must be present, and the "type" of an
+// interface value must be the name of the type registered under its "typeID".
+func (c *genericCodec) FromJSON(bytes []byte, dest interface{}) error {
+	if dest == nil {
+		return errUnmarshalNil
+	}
+
+	if _, ok := c.typer.(JSONTypeCodec); !ok {
+		return codec.ErrJSONUnsupported
+	}
+	destPtr := reflect.ValueOf(dest)
+	if destPtr.Kind() != reflect.Ptr {
+		return errNeedPointer
+	}
+	return c.unmarshalJSON(bytes, destPtr.Elem(), false /*=nullable*/, 0 /*=depth*/)
+}
+
+// unmarshalJSON unmarshals the JSON representation [data] into [value], which
+// must be addressable.
+//
+// [depth] is the number of values that [value] is nested in.
+func (c *genericCodec) unmarshalJSON(
+	data []byte,
+	value reflect.Value,
+	nullable bool,
+	depth uint32,
+) error {
+	if depth > c.maxDepth {
+		return fmt.Errorf("%w: %d", codec.ErrMaxDepth, c.maxDepth)
+	}
+
+	valueType := value.Type()
+	data = bytes.TrimSpace(data)
+	if c.isCustom(valueType) {
+		bytes, err := parseJSONHex(data)
+		if err != nil {
+			return fmt.Errorf("couldn't unmarshal %s: %w", valueType, err)
+		}
+		p := wrappers.Packer{
+			Bytes: bytes,
+		}
+		if err := unmarshalCustom(&p, value); err != nil {
+			return err
+		}
+		if p.Offset != len(bytes) {
+			return fmt.Errorf("%w: read %d provided %d",
+				codec.ErrExtraSpace,
+				p.Offset,
+				len(bytes),
+			)
+		}
+		return nil
+	}
+
+	switch valueKind := value.Kind(); valueKind {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(string(data), 10, valueType.Bits())
+		if err != nil {
+			return fmt.Errorf("couldn't unmarshal %s: %w", valueKind, err)
+		}
+		value.SetUint(n)
+		return nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(string(data), 10, valueType.Bits())
+		if err != nil {
+			return fmt.Errorf("couldn't unmarshal %s: %w", valueKind, err)
+		}
+		value.SetInt(n)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(string(data))
+		if err != nil {
+			return fmt.Errorf("couldn't unmarshal bool: %w", err)
+		}
+		value.SetBool(b)
+		return nil
+	case reflect.String:
+		var s string
+		if err := unmarshalJSONNonNull(data, &s); err != nil {
+			return fmt.Errorf("couldn't unmarshal string: %w", err)
+		}
+		value.SetString(s)
+		return nil
+	case reflect.Ptr:
+		if bytes.Equal(data, jsonNull) {
+			if !nullable {
+				return fmt.Errorf("couldn't unmarshal %s: %w", valueType, errUnexpectedJSONNull)
+			}
+			value.Set(reflect.Zero(valueType))
+			return nil
+		}
+
+		if isProtoMessage(valueType) {
+			msg := reflect.New(valueType.Elem())
+			if err := protojson.Unmarshal(data, msg.Interface().(proto.Message)); err != nil {
+				return fmt.Errorf("couldn't unmarshal protobuf message %s: %w", valueType, err)
+			}
+			value.Set(msg)
+			return nil
+		}
+		if c.isBigInt(valueType) {
+			var s string
+			if err := unmarshalJSONNonNull(data, &s); err != nil {
+				return fmt.Errorf("couldn't unmarshal big integer: %w", err)
+			}
+			i, ok := new(big.Int).SetString(s, 10)
+			if !ok {
+				return fmt.Errorf("%w: %q", errInvalidJSONBigInt, s)
+			}
+			value.Set(reflect.ValueOf(i))
+			return nil
+		}
+
+		elem := reflect.New(valueType.Elem())
+		if err := c.unmarshalJSON(data, elem.Elem(), false /*=nullable*/, depth+1); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	case reflect.Interface:
+		if bytes.Equal(data, jsonNull) {
+			if !nullable {
+				return fmt.Errorf("couldn't unmarshal interface: %w", errUnexpectedJSONNull)
+			}
+			value.Set(reflect.Zero(valueType))
+			return nil
+		}
+
+		var intf jsonInterface
+		if err := json.Unmarshal(data, &intf); err != nil {
+			return fmt.Errorf("couldn't unmarshal interface: %w", err)
+		}
+		if intf.TypeID == "" || intf.Value == nil {
+			return fmt.Errorf("couldn't unmarshal interface: %w", errMissingJSONTypeInfo)
+		}
+		intfImplementor, err := c.typer.(JSONTypeCodec).ParseTypeID(intf.TypeID, valueType)
+		if err != nil {
+			return err
+		}
+		if implementingType := intfImplementor.Type().String(); intf.Type != implementingType {
+			return fmt.Errorf("%w: %q is registered under %q but got %q",
+				codec.ErrJSONTypeMismatch,
+				implementingType,
+				intf.TypeID,
+				intf.Type,
+			)
+		}
+		if err := c.unmarshalJSON(intf.Value, intfImplementor, false /*=nullable*/, depth+1); err != nil {
+			return err
+		}
+		value.Set(intfImplementor)
+		return nil
+	case reflect.Slice, reflect.Array:
+		if valueType.Elem().Kind() == reflect.Uint8 {
+			bytes, err := parseJSONHex(data)
+			if err != nil {
+				return fmt.Errorf("couldn't unmarshal %s: %w", valueType, err)
+			}
+			elems, err := c.newJSONElems(value, len(bytes))
+			if err != nil {
+				return err
+			}
+			for i, b := range bytes {
+				elems.Index(i).SetUint(uint64(b))
+			}
+			value.Set(elems)
+			return nil
+		}
+
+		var rawElems []json.RawMessage
+		if err := unmarshalJSONNonNull(data, &rawElems); err != nil {
+			return fmt.Errorf("couldn't unmarshal %s: %w", valueType, err)
+		}
+		elems, err := c.newJSONElems(value, len(rawElems))
+		if err != nil {
+			return err
+		}
+		for i, rawElem := range rawElems {
+			if err := c.unmarshalJSON(rawElem, elems.Index(i), nullable, depth+1); err != nil {
+				return err
+			}
+		}
+		value.Set(elems)
+		return nil
+	case reflect.Struct:
+		if c.isTime(valueType) {
+			var s string
+			if err := unmarshalJSONNonNull(data, &s); err != nil {
+				return fmt.Errorf("couldn't unmarshal time: %w", err)
+			}
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("couldn't unmarshal time: %w", err)
+			}
+			value.Set(reflect.ValueOf(t.UTC()))
+			return nil
+		}
+
+		var rawFields map[string]json.RawMessage
+		if err := unmarshalJSONNonNull(data, &rawFields); err != nil {
+			return fmt.Errorf("couldn't unmarshal %s: %w", valueType, err)
+		}
+		serializedFields, err := c.fielder.GetSerializedFields(valueType)
+		if err != nil {
+			return err
+		}
+		for _, fieldDesc := range serializedFields {
+			name := valueType.Field(fieldDesc.Index).Name
+			rawField, ok := rawFields[name]
+			if !ok {
+				return fmt.Errorf("%w of %s: %s", codec.ErrMissingJSONField, valueType, name)
+			}
+			delete(rawFields, name)
+			field := value.Field(fieldDesc.Index)
+			if fieldDesc.OmitEmpty && bytes.Equal(bytes.TrimSpace(rawField), jsonNull) {
+				field.Set(reflect.Zero(field.Type()))
+				continue
+			}
+			if err := c.unmarshalJSON(rawField, field, fieldDesc.Nullable, depth+1); err != nil {
+				return err
+			}
+		}
+		if len(rawFields) != 0 {
+			names := make([]string, 0, len(rawFields))
+			for name := range rawFields {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("%w of %s: %s", errUnknownJSONField, valueType, strings.Join(names, ", "))
+		}
+		return nil
+	case reflect.Map:
+		var entries []jsonMapEntry
+		if err := unmarshalJSONNonNull(data, &entries); err != nil {
+			return fmt.Errorf("couldn't unmarshal %s: %w", valueType, err)
+		}
+		m := reflect.MakeMapWithSize(valueType, len(entries))
+		for _, entry := range entries {
+			key := reflect.New(valueType.Key()).Elem()
+			if err := c.unmarshalJSON(entry.Key, key, false /*=nullable*/, depth+1); err != nil {
+				return err
+			}
+			if m.MapIndex(key).IsValid() {
+				return fmt.Errorf("%w: %+v", codec.ErrDuplicateMapKey, key)
+			}
+			elem := reflect.New(valueType.Elem()).Elem()
+			if err := c.unmarshalJSON(entry.Value, elem, nullable, depth+1); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		value.Set(m)
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", codec.ErrUnsupportedType, valueKind)
+	}
+}
+
+// newJSONElems returns a new slice, or array, of the type of [value] to
+// unmarshal [numElts] elements into.
+func (*genericCodec) newJSONElems(value reflect.Value, numElts int) (reflect.Value, error) {
+	valueType := value.Type()
+	if value.Kind() == reflect.Slice {
+		return reflect.MakeSlice(valueType, numElts, numElts), nil
+	}
+	if numElts != valueType.Len() {
+		return reflect.Value{}, fmt.Errorf("%w: %s has %d elements but got %d",
+			errWrongJSONArrayLen,
+			valueType,
+			valueType.Len(),
+			numElts,
+		)
+	}
+	return reflect.New(valueType).Elem(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, value interface{}) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf.Write(bytes)
+	return nil
+}
+
+// unmarshalJSONNonNull unmarshals [data] into [dest], unless [data] is null.
+func unmarshalJSONNonNull(data []byte, dest interface{}) error {
+	if bytes.Equal(data, jsonNull) {
+		return errUnexpectedJSONNull
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func parseJSONHex(data []byte) ([]byte, error) {
+	var s string
+	if err := unmarshalJSONNonNull(data, &s); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(s, jsonHexPrefix) {
+		return nil, fmt.Errorf("%w: missing %q prefix", errInvalidJSONHex, jsonHexPrefix)
+	}
+	bytes, err := hex.DecodeString(s[len(jsonHexPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidJSONHex, err)
+	}
+	return bytes, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// canBeEmpty returns true iff the fields of type [t] may be tagged with
+// [OmitEmptyOption].
+func canBeEmpty(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// isEmpty returns true iff [value], which is the value of a field tagged with
+// [OmitEmptyOption], is omitted.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	default:
+		return value.IsNil()
+	}
+}
+
+// omitEmptyFieldSize returns the size of the presence prefix of [field], and
+// whether the field is present.
+func omitEmptyFieldSize(fieldDesc FieldDesc, field reflect.Value) (int, bool) {
+	if !fieldDesc.OmitEmpty {
+		return 0, true
+	}
+	return wrappers.BoolLen, !isEmpty(field)
+}
+
+// marshalOmitEmptyPrefix packs the presence prefix of [field], if it has one,
+// and returns whether the field should be marshalled.
+func marshalOmitEmptyPrefix(fieldDesc FieldDesc, field reflect.Value, p *wrappers.Packer) (bool, error) {
+	if !fieldDesc.OmitEmpty {
+		return true, nil
+	}
+	isPresent := !isEmpty(field)
+	p.PackBool(isPresent)
+	return isPresent, p.Err
+}
+
+// unmarshalOmitEmptyPrefix unpacks the presence prefix of [field], if it has
+// one, and returns whether the field should be unmarshalled. If it shouldn't
+// be, [field] is set to its zero value.
+func unmarshalOmitEmptyPrefix(p *wrappers.Packer, fieldDesc FieldDesc, field reflect.Value) (bool, error) {
+	if !fieldDesc.OmitEmpty {
+		return true, nil
+	}
+	isPresent := p.UnpackBool()
+	if p.Err != nil {
+		return false, fmt.Errorf("couldn't unmarshal presence of field %d: %w", fieldDesc.Index, p.Err)
+	}
+	if !isPresent {
+		field.Set(reflect.Zero(field.Type()))
+	}
+	return isPresent, nil
+}
+
+// checkOmitEmptyCanonical returns an error if [field], which was unmarshalled,
+// is empty despite being marked as present.
+func checkOmitEmptyCanonical(fieldDesc FieldDesc, field reflect.Value) error {
+	if fieldDesc.OmitEmpty && isEmpty(field) {
+		return fmt.Errorf("%w: field %d is present but empty",
+			codec.ErrNonCanonicalEncoding,
+			fieldDesc.Index,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import "github.com/ava-labs/avalanchego/codec"
+
+var _ ProfileCodec = (*genericCodec)(nil)
+
+// ProfileCodec creates codecs that decode values as configured by a
+// [codec.DecodeProfile].
+type ProfileCodec interface {
+	// WithDecodeProfile returns a codec that unmarshals values like this
+	// codec, except as configured by [profile]. Interface values are unpacked
+	// with [typer].
+	//
+	// [profile.FixedTypeIDs] must be handled by [typer].
+	WithDecodeProfile(typer TypeCodec, profile codec.DecodeProfile) codec.Codec
+}
+
+func (c *genericCodec) WithDecodeProfile(typer TypeCodec, profile codec.DecodeProfile) codec.Codec {
+	tagNames := c.tagNames
+	if len(profile.TagNames) > 0 {
+		tagNames = profile.TagNames
+	}
+	maxSliceLen := c.maxSliceLen
+	if profile.MaxSliceLen > 0 {
+		maxSliceLen = profile.MaxSliceLen
+	}
+	return &genericCodec{
+		typer:           typer,
+		tagNames:        tagNames,
+		maxSliceLen:     maxSliceLen,
+		maxDepth:        c.maxDepth,
+		fielder:         NewStructFielder(tagNames, maxSliceLen),
+		sparse:          c.sparse,
+		reflectedTypes:  c.reflectedTypes || profile.ReflectedTypes,
+		allocationLimit: c.allocationLimit,
+		unsafeStrings:   c.unsafeStrings,
+	}
+}
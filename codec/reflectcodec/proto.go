@@ -0,0 +1,97 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+	// Deterministic marshalling is required so that a value always has the
+	// same byte representation, and therefore the same ID.
+	protoMarshalOptions = proto.MarshalOptions{
+		Deterministic: true,
+	}
+)
+
+// isProtoMessage returns true iff [t] is a pointer to a protobuf message.
+//
+// Protobuf messages are serialized as a length prefixed byte slice holding the
+// protobuf encoding of the message. This allows types defined in .proto files
+// to be registered, and embedded, alongside structs using the tag based
+// format.
+func isProtoMessage(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && t.Implements(protoMessageType)
+}
+
+// [value] must be a non-nil pointer to a protobuf message.
+func protoSize(value reflect.Value) int {
+	msg := value.Interface().(proto.Message)
+	return wrappers.IntLen + protoMarshalOptions.Size(msg)
+}
+
+// [value] must be a non-nil pointer to a protobuf message.
+func marshalProto(value reflect.Value, p *wrappers.Packer, maxSliceLen uint32) error {
+	msg := value.Interface().(proto.Message)
+	msgBytes, err := protoMarshalOptions.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal protobuf message %s: %w", value.Type(), err)
+	}
+	if numBytes := len(msgBytes); uint32(numBytes) > maxSliceLen {
+		return fmt.Errorf("%w; protobuf message length, %d, exceeds maximum length, %d",
+			codec.ErrMaxSliceLenExceeded,
+			numBytes,
+			maxSliceLen,
+		)
+	}
+	p.PackBytes(msgBytes)
+	return p.Err
+}
+
+// [value] must be a settable pointer to a protobuf message.
+func unmarshalProto(p *wrappers.Packer, value reflect.Value, maxSliceLen uint32) error {
+	numBytes := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal protobuf message: %w", p.Err)
+	}
+	if numBytes > maxSliceLen {
+		return fmt.Errorf("%w; protobuf message length, %d, exceeds maximum length, %d",
+			codec.ErrMaxSliceLenExceeded,
+			numBytes,
+			maxSliceLen,
+		)
+	}
+	msgBytes := p.UnpackFixedBytes(int(numBytes))
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal protobuf message: %w", p.Err)
+	}
+
+	v := reflect.New(value.Type().Elem())
+	msg := v.Interface().(proto.Message)
+	if err := proto.Unmarshal(msgBytes, msg); err != nil {
+		return fmt.Errorf("couldn't unmarshal protobuf message %s: %w", value.Type(), err)
+	}
+
+	// The protobuf wire format allows a message to be encoded in many ways,
+	// such as with repeated or reordered fields. Only the deterministic
+	// encoding is accepted, so that a value has a single byte representation.
+	canonicalBytes, err := protoMarshalOptions.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal protobuf message %s: %w", value.Type(), err)
+	}
+	if !bytes.Equal(msgBytes, canonicalBytes) {
+		return fmt.Errorf("%w: protobuf message %s", codec.ErrNonCanonicalEncoding, value.Type())
+	}
+	value.Set(v)
+	return nil
+}
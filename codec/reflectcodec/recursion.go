@@ -0,0 +1,104 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+var _ TypeChecker = (*genericCodec)(nil)
+
+// TypeChecker checks types before they are registered.
+type TypeChecker interface {
+	// CheckType returns an error wrapping [codec.ErrRecursiveType] if a
+	// serialized field of [t], or of a type it contains, can hold a struct
+	// that contains it. Values of such types may reference themselves, in
+	// which case marshalling them would never terminate.
+	//
+	// Types held by interfaces aren't known until the interface is
+	// marshalled, so recursion through interfaces is detected at that time.
+	CheckType(t reflect.Type) error
+}
+
+// structField is a serialized field of a struct that was followed while
+// checking a type.
+type structField struct {
+	structType reflect.Type
+	name       string
+}
+
+func (c *genericCodec) CheckType(t reflect.Type) error {
+	var checked set.Set[reflect.Type]
+	return c.checkType(t, nil /*=path*/, &checked)
+}
+
+// checkType checks that no struct reachable from [t] contains itself.
+//
+// [path] is the list of fields followed to reach [t]. [checked] is the set of
+// structs that were already found to not be recursive.
+func (c *genericCodec) checkType(t reflect.Type, path []structField, checked *set.Set[reflect.Type]) error {
+	// Types that marshal themselves are responsible for terminating.
+	if c.isCustom(t) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if isProtoMessage(t) || c.isBigInt(t) {
+			return nil
+		}
+		return c.checkType(t.Elem(), path, checked)
+	case reflect.Slice, reflect.Array:
+		return c.checkType(t.Elem(), path, checked)
+	case reflect.Map:
+		if err := c.checkType(t.Key(), path, checked); err != nil {
+			return err
+		}
+		return c.checkType(t.Elem(), path, checked)
+	case reflect.Struct:
+		if checked.Contains(t) {
+			return nil
+		}
+		for i, field := range path {
+			if field.structType == t {
+				return fmt.Errorf("%w: %s", codec.ErrRecursiveType, formatRecursion(path[i:]))
+			}
+		}
+
+		serializedFields, err := c.fielder.GetSerializedFields(t)
+		if err != nil {
+			return err
+		}
+		for _, fieldDesc := range serializedFields {
+			field := t.Field(fieldDesc.Index)
+			fieldPath := append(path, structField{
+				structType: t,
+				name:       field.Name,
+			})
+			if err := c.checkType(field.Type, fieldPath, checked); err != nil {
+				return err
+			}
+		}
+		checked.Add(t)
+		return nil
+	default:
+		return nil
+	}
+}
+
+// formatRecursion formats the fields that lead from a struct back to itself,
+// e.g. "A.B -> B.A -> A".
+func formatRecursion(path []structField) string {
+	var sb strings.Builder
+	for _, field := range path {
+		fmt.Fprintf(&sb, "%s.%s -> ", field.structType, field.name)
+	}
+	sb.WriteString(path[0].structType.String())
+	return sb.String()
+}
@@ -0,0 +1,253 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/avalanchego/codec"
+)
+
+var (
+	_ TypeDescriber   = (*genericCodec)(nil)
+	_ SchemaDescriber = (*genericCodec)(nil)
+)
+
+// TypeDescriber describes the serialized layout of types.
+type TypeDescriber interface {
+	// DescribeType returns a deterministic description of the serialized
+	// layout of [t].
+	//
+	// The description only depends on the format of the bytes that values of
+	// [t] are serialized into. Renaming a type or one of its fields, or
+	// adding a field that isn't serialized, doesn't change the description.
+	// The exception is types that marshal themselves, which are described by
+	// their name.
+	DescribeType(t reflect.Type) (string, error)
+}
+
+// SchemaDescriber describes the serialized layout of types in a
+// machine-readable form.
+type SchemaDescriber interface {
+	// DescribeTypeSchema returns the serialized layout of [t]. The layout is
+	// the one described by [TypeDescriber.DescribeType], along with the names
+	// of the types and fields it's made of.
+	DescribeTypeSchema(t reflect.Type) (*codec.TypeSchema, error)
+}
+
+func (c *genericCodec) DescribeType(t reflect.Type) (string, error) {
+	var sb strings.Builder
+	if err := c.describe(&sb, t, nil /*=structStack*/); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// describe writes the description of [t] into [sb].
+//
+// [structStack] is the list of struct types currently being described. A
+// struct that references itself is described by its position in the stack to
+// guarantee termination.
+func (c *genericCodec) describe(sb *strings.Builder, t reflect.Type, structStack []reflect.Type) error {
+	// The layout of a type that marshals itself isn't known, so it's
+	// described by the name of the type.
+	if c.isCustom(t) {
+		fmt.Fprintf(sb, "custom(%s)", t)
+		return nil
+	}
+
+	switch kind := t.Kind(); kind {
+	case reflect.Uint8, reflect.Int8,
+		reflect.Uint16, reflect.Int16,
+		reflect.Uint32, reflect.Int32,
+		reflect.Uint64, reflect.Int64,
+		reflect.Bool, reflect.String:
+		sb.WriteString(kind.String())
+		return nil
+	case reflect.Interface:
+		sb.WriteString("interface")
+		return nil
+	case reflect.Ptr:
+		if isProtoMessage(t) {
+			msg := reflect.New(t.Elem()).Interface().(proto.Message)
+			fmt.Fprintf(sb, "proto(%s)", msg.ProtoReflect().Descriptor().FullName())
+			return nil
+		}
+		if c.isBigInt(t) {
+			sb.WriteString("bigint")
+			return nil
+		}
+		sb.WriteString("*")
+		return c.describe(sb, t.Elem(), structStack)
+	case reflect.Slice:
+		sb.WriteString("[]")
+		return c.describe(sb, t.Elem(), structStack)
+	case reflect.Array:
+		fmt.Fprintf(sb, "[%d]", t.Len())
+		return c.describe(sb, t.Elem(), structStack)
+	case reflect.Map:
+		sb.WriteString("map[")
+		if err := c.describe(sb, t.Key(), structStack); err != nil {
+			return err
+		}
+		sb.WriteString("]")
+		return c.describe(sb, t.Elem(), structStack)
+	case reflect.Struct:
+		if c.isTime(t) {
+			sb.WriteString("time")
+			return nil
+		}
+
+		for i, stackType := range structStack {
+			if stackType == t {
+				fmt.Fprintf(sb, "recursive(%d)", i)
+				return nil
+			}
+		}
+
+		serializedFields, err := c.fielder.GetSerializedFields(t)
+		if err != nil {
+			return err
+		}
+
+		structStack = append(structStack, t)
+		if c.sparse {
+			sb.WriteString("sparse ")
+		}
+		sb.WriteString("struct{")
+		for i, fieldDesc := range serializedFields {
+			if i > 0 {
+				sb.WriteString(";")
+			}
+			fmt.Fprintf(sb, "max=%d", fieldDesc.MaxSliceLen)
+			if fieldDesc.Nullable {
+				sb.WriteString(",nullable")
+			}
+			if fieldDesc.MaxLen != 0 {
+				fmt.Fprintf(sb, ",maxLen=%d", fieldDesc.MaxLen)
+			}
+			if fieldDesc.OmitEmpty && !c.sparse {
+				sb.WriteString(",omitempty")
+			}
+			sb.WriteString(" ")
+			if err := c.describe(sb, t.Field(fieldDesc.Index).Type, structStack); err != nil {
+				return err
+			}
+		}
+		sb.WriteString("}")
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", codec.ErrUnsupportedType, t)
+	}
+}
+
+func (c *genericCodec) DescribeTypeSchema(t reflect.Type) (*codec.TypeSchema, error) {
+	return c.describeSchema(t, nil /*=structStack*/)
+}
+
+// describeSchema returns the schema of [t].
+//
+// [structStack] is the list of struct types currently being described, as in
+// [describe].
+func (c *genericCodec) describeSchema(t reflect.Type, structStack []reflect.Type) (*codec.TypeSchema, error) {
+	schema := &codec.TypeSchema{}
+	if t.PkgPath() != "" {
+		// [t] is a named type that isn't predeclared.
+		schema.Name = t.String()
+	}
+
+	if c.isCustom(t) {
+		schema.Kind = codec.KindCustom
+		return schema, nil
+	}
+
+	switch kind := t.Kind(); kind {
+	case reflect.Uint8, reflect.Int8,
+		reflect.Uint16, reflect.Int16,
+		reflect.Uint32, reflect.Int32,
+		reflect.Uint64, reflect.Int64,
+		reflect.Bool, reflect.String:
+		schema.Kind = kind.String()
+		return schema, nil
+	case reflect.Interface:
+		schema.Kind = codec.KindInterface
+		return schema, nil
+	case reflect.Ptr:
+		if isProtoMessage(t) {
+			msg := reflect.New(t.Elem()).Interface().(proto.Message)
+			schema.Kind = codec.KindProto
+			schema.ProtoMessage = string(msg.ProtoReflect().Descriptor().FullName())
+			return schema, nil
+		}
+		if c.isBigInt(t) {
+			schema.Kind = codec.KindBigInt
+			return schema, nil
+		}
+		schema.Kind = codec.KindPointer
+	case reflect.Slice:
+		schema.Kind = codec.KindSlice
+	case reflect.Array:
+		schema.Kind = codec.KindArray
+		schema.Len = t.Len()
+	case reflect.Map:
+		key, err := c.describeSchema(t.Key(), structStack)
+		if err != nil {
+			return nil, err
+		}
+		schema.Kind = codec.KindMap
+		schema.Key = key
+	case reflect.Struct:
+		if c.isTime(t) {
+			schema.Kind = codec.KindTime
+			return schema, nil
+		}
+
+		for i, stackType := range structStack {
+			if stackType == t {
+				schema.Kind = codec.KindRecursive
+				schema.Depth = len(structStack) - 1 - i
+				return schema, nil
+			}
+		}
+
+		serializedFields, err := c.fielder.GetSerializedFields(t)
+		if err != nil {
+			return nil, err
+		}
+
+		structStack = append(structStack, t)
+		schema.Kind = codec.KindStruct
+		schema.Sparse = c.sparse
+		schema.Fields = make([]codec.FieldSchema, len(serializedFields))
+		for i, fieldDesc := range serializedFields {
+			field := t.Field(fieldDesc.Index)
+			fieldSchema, err := c.describeSchema(field.Type, structStack)
+			if err != nil {
+				return nil, err
+			}
+			schema.Fields[i] = codec.FieldSchema{
+				Name:        field.Name,
+				MaxSliceLen: fieldDesc.MaxSliceLen,
+				Nullable:    fieldDesc.Nullable,
+				MaxLen:      fieldDesc.MaxLen,
+				OmitEmpty:   fieldDesc.OmitEmpty && !c.sparse,
+				Type:        fieldSchema,
+			}
+		}
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", codec.ErrUnsupportedType, t)
+	}
+
+	elem, err := c.describeSchema(t.Elem(), structStack)
+	if err != nil {
+		return nil, err
+	}
+	schema.Elem = elem
+	return schema, nil
+}
@@ -0,0 +1,204 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+// NewSparse returns a new, concurrency-safe codec that omits the serialized
+// fields of structs that are equal to their zero value.
+//
+// Each struct is prefixed with a presence bitmap that has one bit per
+// serialized field, in field order, starting from the least significant bit of
+// the first byte. Only the fields whose bit is set follow the bitmap.
+//
+// A field is considered to be zero if it's a nil pointer or interface, an
+// empty slice or map, the zero time, the zero value of a type that marshals
+// itself, or if all of its serialized contents are zero. Absent
+// fields are unmarshalled to their zero value, so nil and empty slices and maps
+// aren't distinguished.
+//
+// The encoding is canonical. Unmarshalling fails if the bitmap has bits set
+// past the last field or if a present field holds its zero value.
+func NewSparse(typer TypeCodec, tagNames []string, maxSliceLen uint32, opts ...Option) codec.Codec {
+	return newGenericCodec(&genericCodec{
+		typer:       typer,
+		tagNames:    tagNames,
+		maxSliceLen: maxSliceLen,
+		maxDepth:    DefaultMaxDepth,
+		fielder:     NewStructFielder(tagNames, maxSliceLen),
+		sparse:      true,
+	}, opts)
+}
+
+func presenceBitmapLen(numFields int) int {
+	return (numFields + 7) / 8
+}
+
+func isPresent(bitmap []byte, fieldIndex int) bool {
+	return bitmap[fieldIndex/8]&(1<<(fieldIndex%8)) != 0
+}
+
+// isZero returns true if [value] is omitted when it's the field of a sparse
+// struct.
+func (c *genericCodec) isZero(value reflect.Value) (bool, error) {
+	if c.isCustom(value.Type()) {
+		return value.IsZero(), nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil(), nil
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0, nil
+	case reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.IsZero(), nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			if isZero, err := c.isZero(value.Index(i)); err != nil || !isZero {
+				return false, err
+			}
+		}
+		return true, nil
+	case reflect.Struct:
+		if c.isTime(value.Type()) {
+			return value.Interface().(time.Time).IsZero(), nil
+		}
+
+		// Fields that aren't serialized are ignored, so that a struct that
+		// is omitted is unmarshalled to the same serialized value.
+		serializedFields, err := c.fielder.GetSerializedFields(value.Type())
+		if err != nil {
+			return false, err
+		}
+		for _, fieldDesc := range serializedFields {
+			if isZero, err := c.isZero(value.Field(fieldDesc.Index)); err != nil || !isZero {
+				return false, err
+			}
+		}
+		return true, nil
+	default:
+		return value.IsZero(), nil
+	}
+}
+
+func (c *genericCodec) sparseStructSize(
+	value reflect.Value,
+	serializedFields []FieldDesc,
+	typeStack set.Set[reflect.Type],
+) (int, error) {
+	size := presenceBitmapLen(len(serializedFields))
+	for _, fieldDesc := range serializedFields {
+		field := value.Field(fieldDesc.Index)
+		isZero, err := c.isZero(field)
+		if err != nil {
+			return 0, err
+		}
+		if isZero {
+			continue
+		}
+
+		if err := fieldDesc.checkStringLen(field); err != nil {
+			return 0, err
+		}
+		fieldSize, _, err := c.size(field, fieldDesc.MaxSliceLen, fieldDesc.Nullable, typeStack)
+		if err != nil {
+			return 0, err
+		}
+		size += fieldSize
+	}
+	return size, nil
+}
+
+func (c *genericCodec) marshalSparseStruct(
+	value reflect.Value,
+	p *wrappers.Packer,
+	serializedFields []FieldDesc,
+	typeStack set.Set[reflect.Type],
+) error {
+	bitmap := make([]byte, presenceBitmapLen(len(serializedFields)))
+	for i, fieldDesc := range serializedFields {
+		isZero, err := c.isZero(value.Field(fieldDesc.Index))
+		if err != nil {
+			return err
+		}
+		if !isZero {
+			bitmap[i/8] |= 1 << (i % 8)
+		}
+	}
+	p.PackFixedBytes(bitmap)
+	if p.Err != nil {
+		return p.Err
+	}
+
+	for i, fieldDesc := range serializedFields {
+		if !isPresent(bitmap, i) {
+			continue
+		}
+		field := value.Field(fieldDesc.Index)
+		if err := fieldDesc.checkStringLen(field); err != nil {
+			return err
+		}
+		if err := c.marshal(field, p, fieldDesc.MaxSliceLen, fieldDesc.Nullable, typeStack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *genericCodec) unmarshalSparseStruct(
+	p *wrappers.Packer,
+	value reflect.Value,
+	serializedFields []FieldDesc,
+	typeStack set.Set[reflect.Type],
+	depth uint32,
+	budget *allocationBudget,
+	arena *codec.Arena,
+) error {
+	numFields := len(serializedFields)
+	bitmap := p.UnpackFixedBytes(presenceBitmapLen(numFields))
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal presence bitmap: %w", p.Err)
+	}
+	if numFields%8 != 0 && bitmap[len(bitmap)-1]>>(numFields%8) != 0 {
+		return fmt.Errorf("%w: presence bitmap has bits set past field %d",
+			codec.ErrNonCanonicalEncoding,
+			numFields-1,
+		)
+	}
+
+	for i, fieldDesc := range serializedFields {
+		field := value.Field(fieldDesc.Index)
+		if !isPresent(bitmap, i) {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+
+		if err := c.unmarshal(p, field, fieldDesc.MaxSliceLen, fieldDesc.Nullable, typeStack, depth+1, budget, arena); err != nil {
+			return err
+		}
+		if err := fieldDesc.checkStringLen(field); err != nil {
+			return err
+		}
+		isZero, err := c.isZero(field)
+		if err != nil {
+			return err
+		}
+		if isZero {
+			return fmt.Errorf("%w: field %d is present but zero",
+				codec.ErrNonCanonicalEncoding,
+				i,
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package reflectcodec
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+
+	// Times that can be represented as a number of nanoseconds since the unix
+	// epoch.
+	minTime = time.Unix(0, math.MinInt64)
+	maxTime = time.Unix(0, math.MaxInt64)
+)
+
+// isTime returns true iff [t] is [time.Time].
+//
+// Times are serialized as the number of nanoseconds since the unix epoch, as
+// an int64. The location and monotonic clock reading of a time aren't
+// serialized, so times are unmarshalled in UTC. Times that can't be
+// represented, including the zero time, can't be marshalled.
+func (c *genericCodec) isTime(t reflect.Type) bool {
+	return !c.reflectedTypes && t == timeType
+}
+
+// [value] must be a [time.Time].
+func timeSize(value reflect.Value) (int, error) {
+	t := value.Interface().(time.Time)
+	if t.Before(minTime) || t.After(maxTime) {
+		return 0, fmt.Errorf("%w: %s", codec.ErrTimeOutOfRange, t)
+	}
+	return wrappers.LongLen, nil
+}
+
+// [value] must be a [time.Time].
+func marshalTime(value reflect.Value, p *wrappers.Packer) error {
+	if _, err := timeSize(value); err != nil {
+		return err
+	}
+	t := value.Interface().(time.Time)
+	p.PackLong(uint64(t.UnixNano()))
+	return p.Err
+}
+
+// [value] must be a settable [time.Time].
+func unmarshalTime(p *wrappers.Packer, value reflect.Value) error {
+	nanos := int64(p.UnpackLong())
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal time: %w", p.Err)
+	}
+	value.Set(reflect.ValueOf(time.Unix(0, nanos).UTC()))
+	return nil
+}
+
+// isBigInt returns true iff [t] is [*big.Int].
+//
+// Big integers are serialized as a bool that's true iff the integer is
+// negative, followed by a length prefixed byte slice holding the big endian
+// absolute value of the integer, without leading zeros. Zero is serialized as
+// a non-negative integer with an empty absolute value.
+func (c *genericCodec) isBigInt(t reflect.Type) bool {
+	return !c.reflectedTypes && t == bigIntType
+}
+
+// [value] must be a non-nil [*big.Int].
+func bigIntSize(value reflect.Value, maxSliceLen uint32) (int, error) {
+	i := value.Interface().(*big.Int)
+	numBytes := (i.BitLen() + 7) / 8
+	if uint32(numBytes) > maxSliceLen {
+		return 0, fmt.Errorf("%w; big integer length, %d, exceeds maximum length, %d",
+			codec.ErrMaxSliceLenExceeded,
+			numBytes,
+			maxSliceLen,
+		)
+	}
+	return wrappers.BoolLen + wrappers.IntLen + numBytes, nil
+}
+
+// [value] must be a non-nil [*big.Int].
+func marshalBigInt(value reflect.Value, p *wrappers.Packer, maxSliceLen uint32) error {
+	i := value.Interface().(*big.Int)
+	if _, err := bigIntSize(value, maxSliceLen); err != nil {
+		return err
+	}
+	p.PackBool(i.Sign() < 0)
+	p.PackBytes(i.Bytes())
+	return p.Err
+}
+
+// [value] must be a settable [*big.Int].
+func unmarshalBigInt(p *wrappers.Packer, value reflect.Value, maxSliceLen uint32) error {
+	negative := p.UnpackBool()
+	numBytes := p.UnpackInt()
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal big integer: %w", p.Err)
+	}
+	if numBytes > maxSliceLen {
+		return fmt.Errorf("%w; big integer length, %d, exceeds maximum length, %d",
+			codec.ErrMaxSliceLenExceeded,
+			numBytes,
+			maxSliceLen,
+		)
+	}
+	absBytes := p.UnpackFixedBytes(int(numBytes))
+	if p.Err != nil {
+		return fmt.Errorf("couldn't unmarshal big integer: %w", p.Err)
+	}
+	switch {
+	case numBytes > 0 && absBytes[0] == 0:
+		return fmt.Errorf("%w: big integer has leading zeros", codec.ErrNonCanonicalEncoding)
+	case numBytes == 0 && negative:
+		return fmt.Errorf("%w: big integer is negative zero", codec.ErrNonCanonicalEncoding)
+	}
+
+	i := new(big.Int).SetBytes(absBytes)
+	if negative {
+		i.Neg(i)
+	}
+	value.Set(reflect.ValueOf(i))
+	return nil
+}
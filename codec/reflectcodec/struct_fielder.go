@@ -4,9 +4,11 @@
 package reflectcodec
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/ava-labs/avalanchego/codec"
@@ -22,14 +24,94 @@ const (
 	// TagValue is the value the tag must have to be serialized, this variant
 	// includes the nullable option
 	TagWithNullableValue = "true,nullable"
+
+	// NullableOption is the tag option marking a field as nullable.
+	NullableOption = "nullable"
+
+	// OmitEmptyOption is the tag option marking a pointer, interface, slice
+	// or map field as omitted when it's nil or empty. The field is prefixed
+	// by a bool that's true iff it's present, rather than being encoded as
+	// zero-length content. Sparse codecs ignore the option, as they already
+	// omit fields that are equal to their zero value.
+	OmitEmptyOption = "omitempty"
+
+	// MaxLenOption is the tag option that specifies the maximum length of a
+	// slice, map or string field, e.g. "true,maxLen=1024".
+	MaxLenOption = "maxLen="
 )
 
-var _ StructFielder = (*structFielder)(nil)
+var (
+	_ StructFielder = (*structFielder)(nil)
+
+	errInvalidMaxLen    = errors.New("invalid maxLen tag option")
+	errInvalidOmitEmpty = errors.New("omitempty tag option on a field that can't be empty")
+)
 
 type FieldDesc struct {
 	Index       int
 	MaxSliceLen uint32
 	Nullable    bool
+	// MaxLen is the maximum length of the field specified by its maxLen tag
+	// option, or 0 if it doesn't have one.
+	MaxLen uint32
+	// OmitEmpty is true if the field is only encoded when it isn't empty. See
+	// [OmitEmptyOption].
+	OmitEmpty bool
+}
+
+// checkStringLen returns an error if [value], which is the value of the field,
+// is a string that is longer than [f.MaxLen].
+func (f FieldDesc) checkStringLen(value reflect.Value) error {
+	if f.MaxLen == 0 || value.Kind() != reflect.String {
+		return nil
+	}
+	if strLen := value.Len(); uint32(strLen) > f.MaxLen {
+		return fmt.Errorf("%w; string length, %d, exceeds maximum length, %d",
+			codec.ErrMaxSliceLenExceeded,
+			strLen,
+			f.MaxLen,
+		)
+	}
+	return nil
+}
+
+// SerializeTag is a parsed serialization tag value.
+type SerializeTag struct {
+	Nullable bool
+	// MaxLen is 0 if the tag doesn't specify a maximum length.
+	MaxLen    uint32
+	OmitEmpty bool
+}
+
+// ParseSerializeTag parses [value], the value of a serialization tag such as
+// "true", "true,nullable", "true,omitempty" or "true,maxLen=1024". Returns
+// false if the tagged field isn't serialized.
+func ParseSerializeTag(value string) (SerializeTag, bool, error) {
+	options := strings.Split(value, ",")
+	if options[0] != TagValue {
+		return SerializeTag{}, false, nil
+	}
+
+	var tag SerializeTag
+	for _, option := range options[1:] {
+		switch {
+		case option == NullableOption:
+			tag.Nullable = true
+		case option == OmitEmptyOption:
+			tag.OmitEmpty = true
+		case strings.HasPrefix(option, MaxLenOption):
+			maxLen, err := strconv.ParseUint(strings.TrimPrefix(option, MaxLenOption), 10, 31)
+			if err != nil || maxLen == 0 {
+				return SerializeTag{}, false, fmt.Errorf("%w: %q", errInvalidMaxLen, value)
+			}
+			tag.MaxLen = uint32(maxLen)
+		default:
+			// Unknown options aren't serialized, as before options were
+			// supported.
+			return SerializeTag{}, false, nil
+		}
+	}
+	return tag, true, nil
 }
 
 // StructFielder handles discovery of serializable fields in a struct.
@@ -89,15 +171,13 @@ func (s *structFielder) GetSerializedFields(t reflect.Type) ([]FieldDesc, error)
 		// any tag with the right value
 		var (
 			captureField bool
-			nullable     bool
+			serializeTag SerializeTag
 		)
 		for _, tag := range s.tags {
-			switch field.Tag.Get(tag) {
-			case TagValue:
-				captureField = true
-			case TagWithNullableValue:
-				captureField = true
-				nullable = true
+			var err error
+			serializeTag, captureField, err = ParseSerializeTag(field.Tag.Get(tag))
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
 			}
 			if captureField {
 				break
@@ -112,16 +192,29 @@ func (s *structFielder) GetSerializedFields(t reflect.Type) ([]FieldDesc, error)
 				field.Name,
 			)
 		}
+		if serializeTag.OmitEmpty && !canBeEmpty(field.Type) {
+			return nil, fmt.Errorf("%w: field %s is a %s",
+				errInvalidOmitEmpty,
+				field.Name,
+				field.Type.Kind(),
+			)
+		}
 		sliceLenField := field.Tag.Get(SliceLenTagName)
 		maxSliceLen := s.maxSliceLen
 
 		if newLen, err := strconv.ParseUint(sliceLenField, 10, 31); err == nil {
 			maxSliceLen = uint32(newLen)
 		}
+		// The maxLen option can only tighten the limit.
+		if serializeTag.MaxLen != 0 && serializeTag.MaxLen < maxSliceLen {
+			maxSliceLen = serializeTag.MaxLen
+		}
 		serializedFields = append(serializedFields, FieldDesc{
 			Index:       i,
 			MaxSliceLen: maxSliceLen,
-			Nullable:    nullable,
+			Nullable:    serializeTag.Nullable,
+			MaxLen:      serializeTag.MaxLen,
+			OmitEmpty:   serializeTag.OmitEmpty,
 		})
 	}
 	s.serializedFieldIndices[t] = serializedFields // cache result
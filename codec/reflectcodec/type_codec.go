@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sync"
+	"unsafe"
 
 	"golang.org/x/exp/slices"
 
@@ -21,6 +23,10 @@ const (
 	// DefaultTagName that enables serialization.
 	DefaultTagName  = "serialize"
 	initialSliceLen = 16
+
+	// DefaultMaxDepth is the default maximum number of values that may be
+	// nested in one another while unmarshalling. See [WithMaxDepth].
+	DefaultMaxDepth = 128
 )
 
 var (
@@ -70,19 +76,103 @@ type TypeCodec interface {
 //     codec.RegisterType([instance of the type that fulfills the interface]).
 //  6. Serialized fields must be exported
 //  7. nil slices are marshaled as empty slices
+//  8. Pointers to protobuf messages are marshaled as a length prefixed byte
+//     slice holding the protobuf encoding of the message
+//  9. If the codec is sparse, struct fields equal to their zero value are
+//     omitted. See [NewSparse].
+//  10. time.Time values are marshaled as unix nanoseconds and *big.Int values
+//     as a sign followed by a length prefixed absolute value. See
+//     [genericCodec.isTime] and [genericCodec.isBigInt].
+//  11. Types that implement codec.Marshaler and codec.Unmarshaler marshal
+//     themselves. See [genericCodec.isCustom].
+//  12. Unmarshalling fails if values are nested deeper than the max depth.
+//     See [WithMaxDepth].
+//  13. Unmarshalling fails if it would allocate more than the allocation
+//     limit. See [WithAllocationLimit].
+//  14. Unmarshalled strings may share memory with the unmarshalled bytes.
+//     See [WithUnsafeStrings].
 type genericCodec struct {
 	typer       TypeCodec
+	tagNames    []string
 	maxSliceLen uint32
+	maxDepth    uint32
 	fielder     StructFielder
+	sparse      bool
+
+	// If non-zero, the maximum number of bytes allocated by a single call to
+	// unmarshal. See [WithAllocationLimit].
+	allocationLimit uint64
+
+	// If true, unmarshalled strings share memory with the unmarshalled bytes.
+	// See [WithUnsafeStrings].
+	unsafeStrings bool
+
+	// If true, types that are usually encoded natively are encoded field by
+	// field. See [genericCodec.WithDecodeProfile].
+	reflectedTypes bool
+
+	// Caches whether types marshal themselves. See [genericCodec.isCustom].
+	customTypes sync.Map // reflect.Type -> bool
+}
+
+// Option configures a codec created by this package.
+type Option func(*genericCodec)
+
+// WithMaxDepth sets the maximum number of values that may be nested in one
+// another while unmarshalling, such as a struct holding a slice of interfaces
+// that hold structs. Unmarshalling more deeply nested values fails with
+// [codec.ErrMaxDepth] rather than exhausting the stack. Defaults to
+// [DefaultMaxDepth].
+func WithMaxDepth(maxDepth uint32) Option {
+	return func(c *genericCodec) {
+		c.maxDepth = maxDepth
+	}
+}
+
+// WithAllocationLimit sets the maximum number of bytes of slices, strings,
+// maps and pointers that may be allocated while unmarshalling a single value.
+// Allocations are checked against the limit before they're made, so a length
+// field that is much larger than the encoded values fails with
+// [codec.ErrAllocationLimit] rather than allocating the memory first.
+// Byte slices reference the unmarshalled bytes, so they don't count towards
+// the limit. By default, allocations aren't limited.
+func WithAllocationLimit(limit uint64) Option {
+	return func(c *genericCodec) {
+		c.allocationLimit = limit
+	}
+}
+
+// WithUnsafeStrings unmarshals strings as views over the bytes being
+// unmarshalled rather than as copies of them, so that unmarshalling strings
+// doesn't allocate.
+//
+// The caller must not modify the unmarshalled bytes for as long as any
+// unmarshalled string is reachable, since strings are assumed to be immutable.
+// The strings also keep the unmarshalled bytes from being garbage collected.
+// Only use this option when the bytes are never reused, such as when decoding
+// API requests or blocks read from the database.
+func WithUnsafeStrings() Option {
+	return func(c *genericCodec) {
+		c.unsafeStrings = true
+	}
 }
 
 // New returns a new, concurrency-safe codec
-func New(typer TypeCodec, tagNames []string, maxSliceLen uint32) codec.Codec {
-	return &genericCodec{
+func New(typer TypeCodec, tagNames []string, maxSliceLen uint32, opts ...Option) codec.Codec {
+	return newGenericCodec(&genericCodec{
 		typer:       typer,
+		tagNames:    tagNames,
 		maxSliceLen: maxSliceLen,
+		maxDepth:    DefaultMaxDepth,
 		fielder:     NewStructFielder(tagNames, maxSliceLen),
+	}, opts)
+}
+
+func newGenericCodec(c *genericCodec, opts []Option) *genericCodec {
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 func (c *genericCodec) Size(value interface{}) (int, error) {
@@ -90,18 +180,27 @@ func (c *genericCodec) Size(value interface{}) (int, error) {
 		return 0, errMarshalNil // can't marshal nil
 	}
 
-	size, _, err := c.size(reflect.ValueOf(value), false /*=nullable*/, nil /*=typeStack*/)
+	size, _, err := c.size(reflect.ValueOf(value), c.maxSliceLen, false /*=nullable*/, nil /*=typeStack*/)
 	return size, err
 }
 
 // size returns the size of the value along with whether the value is constant
 // sized. This function takes into account a `nullable` property which allows
 // pointers and interfaces to serialize nil values
+//
+// Like marshal, size returns an error if a slice, array, map or string is
+// longer than allowed.
 func (c *genericCodec) size(
 	value reflect.Value,
+	maxSliceLen uint32,
 	nullable bool,
 	typeStack set.Set[reflect.Type],
 ) (int, bool, error) {
+	if c.isCustom(value.Type()) {
+		size, err := customSize(value)
+		return size, false, err
+	}
+
 	switch valueKind := value.Kind(); valueKind {
 	case reflect.Uint8:
 		return wrappers.ByteLen, true, nil
@@ -122,6 +221,14 @@ func (c *genericCodec) size(
 	case reflect.Bool:
 		return wrappers.BoolLen, true, nil
 	case reflect.String:
+		strLen := value.Len()
+		if strLen > wrappers.MaxStringLen {
+			return 0, false, fmt.Errorf("%w; string length, %d, exceeds maximum length, %d",
+				codec.ErrMaxSliceLenExceeded,
+				strLen,
+				wrappers.MaxStringLen,
+			)
+		}
 		return wrappers.StringLen(value.String()), false, nil
 	case reflect.Ptr:
 		if value.IsNil() {
@@ -131,7 +238,22 @@ func (c *genericCodec) size(
 			return wrappers.BoolLen, false, nil
 		}
 
-		size, constSize, err := c.size(value.Elem(), false /*=nullable*/, typeStack)
+		if isProtoMessage(value.Type()) {
+			size := protoSize(value)
+			if nullable {
+				return wrappers.BoolLen + size, false, nil
+			}
+			return size, false, nil
+		}
+		if c.isBigInt(value.Type()) {
+			size, err := bigIntSize(value, c.maxSliceLen)
+			if nullable {
+				return wrappers.BoolLen + size, false, err
+			}
+			return size, false, err
+		}
+
+		size, constSize, err := c.size(value.Elem(), c.maxSliceLen, false /*=nullable*/, typeStack)
 		if nullable {
 			return wrappers.BoolLen + size, false, err
 		}
@@ -153,7 +275,7 @@ func (c *genericCodec) size(
 		typeStack.Add(underlyingType)
 
 		prefixSize := c.typer.PrefixSize(underlyingType)
-		valueSize, _, err := c.size(value.Elem(), false /*=nullable*/, typeStack)
+		valueSize, _, err := c.size(value.Elem(), c.maxSliceLen, false /*=nullable*/, typeStack)
 
 		typeStack.Remove(underlyingType)
 		if nullable {
@@ -163,11 +285,18 @@ func (c *genericCodec) size(
 
 	case reflect.Slice:
 		numElts := value.Len()
+		if uint32(numElts) > maxSliceLen {
+			return 0, false, fmt.Errorf("%w; slice length, %d, exceeds maximum length, %d",
+				codec.ErrMaxSliceLenExceeded,
+				numElts,
+				maxSliceLen,
+			)
+		}
 		if numElts == 0 {
 			return wrappers.IntLen, false, nil
 		}
 
-		size, constSize, err := c.size(value.Index(0), nullable, typeStack)
+		size, constSize, err := c.size(value.Index(0), c.maxSliceLen, nullable, typeStack)
 		if err != nil {
 			return 0, false, err
 		}
@@ -179,7 +308,7 @@ func (c *genericCodec) size(
 		}
 
 		for i := 1; i < numElts; i++ {
-			innerSize, _, err := c.size(value.Index(i), nullable, typeStack)
+			innerSize, _, err := c.size(value.Index(i), c.maxSliceLen, nullable, typeStack)
 			if err != nil {
 				return 0, false, err
 			}
@@ -192,8 +321,15 @@ func (c *genericCodec) size(
 		if numElts == 0 {
 			return 0, true, nil
 		}
+		if elemKind := value.Type().Elem().Kind(); elemKind != reflect.Uint8 && uint32(numElts) > c.maxSliceLen {
+			return 0, false, fmt.Errorf("%w; array length, %d, exceeds maximum length, %d",
+				codec.ErrMaxSliceLenExceeded,
+				numElts,
+				c.maxSliceLen,
+			)
+		}
 
-		size, constSize, err := c.size(value.Index(0), nullable, typeStack)
+		size, constSize, err := c.size(value.Index(0), c.maxSliceLen, nullable, typeStack)
 		if err != nil {
 			return 0, false, err
 		}
@@ -205,7 +341,7 @@ func (c *genericCodec) size(
 		}
 
 		for i := 1; i < numElts; i++ {
-			innerSize, _, err := c.size(value.Index(i), nullable, typeStack)
+			innerSize, _, err := c.size(value.Index(i), c.maxSliceLen, nullable, typeStack)
 			if err != nil {
 				return 0, false, err
 			}
@@ -214,17 +350,40 @@ func (c *genericCodec) size(
 		return size, false, nil
 
 	case reflect.Struct:
+		if c.isTime(value.Type()) {
+			// Times aren't reported as constant sized so that every time in
+			// a slice or array is checked to be in range.
+			size, err := timeSize(value)
+			return size, false, err
+		}
+
 		serializedFields, err := c.fielder.GetSerializedFields(value.Type())
 		if err != nil {
 			return 0, false, err
 		}
+		if c.sparse {
+			size, err := c.sparseStructSize(value, serializedFields, typeStack)
+			return size, false, err
+		}
 
 		var (
 			size      int
 			constSize = true
 		)
 		for _, fieldDesc := range serializedFields {
-			innerSize, innerConstSize, err := c.size(value.Field(fieldDesc.Index), fieldDesc.Nullable, typeStack)
+			field := value.Field(fieldDesc.Index)
+			prefixSize, isPresent := omitEmptyFieldSize(fieldDesc, field)
+			size += prefixSize
+			if fieldDesc.OmitEmpty {
+				constSize = false
+			}
+			if !isPresent {
+				continue
+			}
+			if err := fieldDesc.checkStringLen(field); err != nil {
+				return 0, false, err
+			}
+			innerSize, innerConstSize, err := c.size(field, fieldDesc.MaxSliceLen, fieldDesc.Nullable, typeStack)
 			if err != nil {
 				return 0, false, err
 			}
@@ -234,16 +393,23 @@ func (c *genericCodec) size(
 		return size, constSize, nil
 
 	case reflect.Map:
+		if numElts := value.Len(); uint32(numElts) > maxSliceLen {
+			return 0, false, fmt.Errorf("%w; map length, %d, exceeds maximum length, %d",
+				codec.ErrMaxSliceLenExceeded,
+				numElts,
+				maxSliceLen,
+			)
+		}
 		iter := value.MapRange()
 		if !iter.Next() {
 			return wrappers.IntLen, false, nil
 		}
 
-		keySize, keyConstSize, err := c.size(iter.Key(), false /*=nullable*/, typeStack)
+		keySize, keyConstSize, err := c.size(iter.Key(), c.maxSliceLen, false /*=nullable*/, typeStack)
 		if err != nil {
 			return 0, false, err
 		}
-		valueSize, valueConstSize, err := c.size(iter.Value(), nullable, typeStack)
+		valueSize, valueConstSize, err := c.size(iter.Value(), c.maxSliceLen, nullable, typeStack)
 		if err != nil {
 			return 0, false, err
 		}
@@ -258,7 +424,7 @@ func (c *genericCodec) size(
 				totalValueSize = valueSize
 			)
 			for iter.Next() {
-				valueSize, _, err := c.size(iter.Value(), nullable, typeStack)
+				valueSize, _, err := c.size(iter.Value(), c.maxSliceLen, nullable, typeStack)
 				if err != nil {
 					return 0, false, err
 				}
@@ -272,7 +438,7 @@ func (c *genericCodec) size(
 				totalKeySize = keySize
 			)
 			for iter.Next() {
-				keySize, _, err := c.size(iter.Key(), false /*=nullable*/, typeStack)
+				keySize, _, err := c.size(iter.Key(), c.maxSliceLen, false /*=nullable*/, typeStack)
 				if err != nil {
 					return 0, false, err
 				}
@@ -283,11 +449,11 @@ func (c *genericCodec) size(
 		default:
 			totalSize := wrappers.IntLen + keySize + valueSize
 			for iter.Next() {
-				keySize, _, err := c.size(iter.Key(), false /*=nullable*/, typeStack)
+				keySize, _, err := c.size(iter.Key(), c.maxSliceLen, false /*=nullable*/, typeStack)
 				if err != nil {
 					return 0, false, err
 				}
-				valueSize, _, err := c.size(iter.Value(), nullable, typeStack)
+				valueSize, _, err := c.size(iter.Value(), c.maxSliceLen, nullable, typeStack)
 				if err != nil {
 					return 0, false, err
 				}
@@ -320,6 +486,10 @@ func (c *genericCodec) marshal(
 	nullable bool,
 	typeStack set.Set[reflect.Type],
 ) error {
+	if c.isCustom(value.Type()) {
+		return marshalCustom(value, p)
+	}
+
 	switch valueKind := value.Kind(); valueKind {
 	case reflect.Uint8:
 		p.PackByte(uint8(value.Uint()))
@@ -362,6 +532,12 @@ func (c *genericCodec) marshal(
 			return errMarshalNil
 		}
 
+		if isProtoMessage(value.Type()) {
+			return marshalProto(value, p, c.maxSliceLen)
+		}
+		if c.isBigInt(value.Type()) {
+			return marshalBigInt(value, p, c.maxSliceLen)
+		}
 		return c.marshal(value.Elem(), p, c.maxSliceLen, false /*=nullable*/, typeStack)
 	case reflect.Interface:
 		isNil := value.IsNil()
@@ -440,12 +616,30 @@ func (c *genericCodec) marshal(
 		}
 		return nil
 	case reflect.Struct:
+		if c.isTime(value.Type()) {
+			return marshalTime(value, p)
+		}
+
 		serializedFields, err := c.fielder.GetSerializedFields(value.Type())
 		if err != nil {
 			return err
 		}
+		if c.sparse {
+			return c.marshalSparseStruct(value, p, serializedFields, typeStack)
+		}
 		for _, fieldDesc := range serializedFields { // Go through all fields of this struct that are serialized
-			if err := c.marshal(value.Field(fieldDesc.Index), p, fieldDesc.MaxSliceLen, fieldDesc.Nullable, typeStack); err != nil { // Serialize the field and write to byte array
+			field := value.Field(fieldDesc.Index)
+			isPresent, err := marshalOmitEmptyPrefix(fieldDesc, field, p)
+			if err != nil {
+				return err
+			}
+			if !isPresent {
+				continue
+			}
+			if err := fieldDesc.checkStringLen(field); err != nil {
+				return err
+			}
+			if err := c.marshal(field, p, fieldDesc.MaxSliceLen, fieldDesc.Nullable, typeStack); err != nil { // Serialize the field and write to byte array
 				return err
 			}
 		}
@@ -472,38 +666,45 @@ func (c *genericCodec) marshal(
 			endIndex   int
 		}
 
+		// Keys are marshalled into their own packer to be sorted, since [p]
+		// may flush the bytes packed into it before the map is fully packed.
+		keyPacker := wrappers.Packer{
+			MaxSize: p.MaxSize,
+		}
 		sortedKeys := make([]keyTuple, len(keys))
-		startOffset := p.Offset
-		endOffset := p.Offset
 		for i, key := range keys {
-			if err := c.marshal(key, p, c.maxSliceLen, false /*=nullable*/, typeStack); err != nil {
+			startIndex := keyPacker.Offset
+			if err := c.marshal(key, &keyPacker, c.maxSliceLen, false /*=nullable*/, typeStack); err != nil {
 				return err
 			}
-			if p.Err != nil {
-				return fmt.Errorf("couldn't marshal map key %+v: %w ", key, p.Err)
+			if keyPacker.Err != nil {
+				return fmt.Errorf("couldn't marshal map key %+v: %w ", key, keyPacker.Err)
 			}
 			sortedKeys[i] = keyTuple{
 				key:        key,
-				startIndex: endOffset,
-				endIndex:   p.Offset,
+				startIndex: startIndex,
+				endIndex:   keyPacker.Offset,
 			}
-			endOffset = p.Offset
 		}
 
 		slices.SortFunc(sortedKeys, func(a, b keyTuple) bool {
-			aBytes := p.Bytes[a.startIndex:a.endIndex]
-			bBytes := p.Bytes[b.startIndex:b.endIndex]
+			aBytes := keyPacker.Bytes[a.startIndex:a.endIndex]
+			bBytes := keyPacker.Bytes[b.startIndex:b.endIndex]
 			return bytes.Compare(aBytes, bBytes) < 0
 		})
+		// Distinct keys, such as distinct pointers to equal values, may have
+		// the same encoding. Their order can't be made canonical, and the
+		// encoding couldn't be unmarshalled.
+		for i := 1; i < len(sortedKeys); i++ {
+			prev, curr := sortedKeys[i-1], sortedKeys[i]
+			if bytes.Equal(keyPacker.Bytes[prev.startIndex:prev.endIndex], keyPacker.Bytes[curr.startIndex:curr.endIndex]) {
+				return fmt.Errorf("%w: %+v and %+v", codec.ErrDuplicateMapKey, prev.key, curr.key)
+			}
+		}
 
-		allKeyBytes := slices.Clone(p.Bytes[startOffset:p.Offset])
-		p.Offset = startOffset
 		for _, key := range sortedKeys {
 			// pack key
-			startIndex := key.startIndex - startOffset
-			endIndex := key.endIndex - startOffset
-			keyBytes := allKeyBytes[startIndex:endIndex]
-			p.PackFixedBytes(keyBytes)
+			p.PackFixedBytes(keyPacker.Bytes[key.startIndex:key.endIndex])
 			if p.Err != nil {
 				return p.Err
 			}
@@ -523,6 +724,13 @@ func (c *genericCodec) marshal(
 // Unmarshal unmarshals [bytes] into [dest], where [dest] must be a pointer or
 // interface
 func (c *genericCodec) Unmarshal(bytes []byte, dest interface{}) error {
+	return c.UnmarshalWithArena(bytes, dest, nil)
+}
+
+// UnmarshalWithArena unmarshals [bytes] into [dest], where [dest] must be a
+// pointer or interface. Pointers and slices created while unmarshalling are
+// allocated from [arena].
+func (c *genericCodec) UnmarshalWithArena(bytes []byte, dest interface{}, arena *codec.Arena) error {
 	if dest == nil {
 		return errUnmarshalNil
 	}
@@ -534,7 +742,7 @@ func (c *genericCodec) Unmarshal(bytes []byte, dest interface{}) error {
 	if destPtr.Kind() != reflect.Ptr {
 		return errNeedPointer
 	}
-	if err := c.unmarshal(&p, destPtr.Elem(), c.maxSliceLen, false /*=nullable*/, nil /*=typeStack*/); err != nil {
+	if err := c.unmarshal(&p, destPtr.Elem(), c.maxSliceLen, false /*=nullable*/, nil /*=typeStack*/, 0 /*=depth*/, c.newAllocationBudget(), arena); err != nil {
 		return err
 	}
 	if p.Offset != len(bytes) {
@@ -547,12 +755,34 @@ func (c *genericCodec) Unmarshal(bytes []byte, dest interface{}) error {
 	return nil
 }
 
+// UnmarshalFrom unmarshals the next value in [p] into [dest], where [dest]
+// must be a pointer or interface. Unlike Unmarshal, bytes may remain in [p]
+// afterwards.
+func (c *genericCodec) UnmarshalFrom(p *wrappers.Packer, dest interface{}) error {
+	if dest == nil {
+		return errUnmarshalNil
+	}
+
+	destPtr := reflect.ValueOf(dest)
+	if destPtr.Kind() != reflect.Ptr {
+		return errNeedPointer
+	}
+	return c.unmarshal(p, destPtr.Elem(), c.maxSliceLen, false /*=nullable*/, nil /*=typeStack*/, 0 /*=depth*/, c.newAllocationBudget(), nil /*=arena*/)
+}
+
 // Unmarshal from p.Bytes into [value]. [value] must be addressable.
 //
 // The [nullable] property affects how pointers and interfaces are unmarshalled,
 // as an extra byte would be used to unmarshal nil values for pointers and
 // interaces
 //
+// [depth] is the number of values that [value] is nested in.
+//
+// [budget] limits the bytes allocated for [value]. It's shared by every value
+// unmarshalled by the same call.
+//
+// If [arena] is non-nil, pointers and slices are allocated from it.
+//
 // c.lock should be held for the duration of this function
 func (c *genericCodec) unmarshal(
 	p *wrappers.Packer,
@@ -560,7 +790,17 @@ func (c *genericCodec) unmarshal(
 	maxSliceLen uint32,
 	nullable bool,
 	typeStack set.Set[reflect.Type],
+	depth uint32,
+	budget *allocationBudget,
+	arena *codec.Arena,
 ) error {
+	if depth > c.maxDepth {
+		return fmt.Errorf("%w: %d", codec.ErrMaxDepth, c.maxDepth)
+	}
+	if c.isCustom(value.Type()) {
+		return unmarshalCustom(p, value)
+	}
+
 	switch value.Kind() {
 	case reflect.Uint8:
 		value.SetUint(uint64(p.UnpackByte()))
@@ -646,12 +886,26 @@ func (c *genericCodec) unmarshal(
 			value.SetBytes(p.UnpackFixedBytes(numElts))
 			return p.Err
 		}
+		if err := budget.spend(uint64(numElts), innerType.Size()); err != nil {
+			return err
+		}
+		// Only allocate the full slice up front if its length is bounded by
+		// the number of remaining bytes.
+		if arena != nil && numElts <= len(p.Bytes)-p.Offset {
+			value.Set(arena.MakeSlice(sliceType, numElts))
+			for i := 0; i < numElts; i++ {
+				if err := c.unmarshal(p, value.Index(i), c.maxSliceLen, nullable, typeStack, depth+1, budget, arena); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
 		// Unmarshal each element and append it into the slice.
 		value.Set(reflect.MakeSlice(sliceType, 0, initialSliceLen))
 		zeroValue := reflect.Zero(innerType)
 		for i := 0; i < numElts; i++ {
 			value.Set(reflect.Append(value, zeroValue))
-			if err := c.unmarshal(p, value.Index(i), c.maxSliceLen, nullable, typeStack); err != nil {
+			if err := c.unmarshal(p, value.Index(i), c.maxSliceLen, nullable, typeStack, depth+1, budget, arena); err != nil {
 				return err
 			}
 		}
@@ -669,13 +923,28 @@ func (c *genericCodec) unmarshal(
 			return nil
 		}
 		for i := 0; i < numElts; i++ {
-			if err := c.unmarshal(p, value.Index(i), c.maxSliceLen, nullable, typeStack); err != nil {
+			if err := c.unmarshal(p, value.Index(i), c.maxSliceLen, nullable, typeStack, depth+1, budget, arena); err != nil {
 				return err
 			}
 		}
 		return nil
 	case reflect.String:
-		value.SetString(p.UnpackStr())
+		strLen := p.UnpackShort()
+		if p.Err != nil {
+			return fmt.Errorf("couldn't unmarshal string: %w", p.Err)
+		}
+		if c.unsafeStrings {
+			strBytes := p.UnpackFixedBytes(int(strLen))
+			if p.Err != nil {
+				return fmt.Errorf("couldn't unmarshal string: %w", p.Err)
+			}
+			value.SetString(unsafe.String(unsafe.SliceData(strBytes), len(strBytes)))
+			return nil
+		}
+		if err := budget.spend(uint64(strLen), 1); err != nil {
+			return err
+		}
+		value.SetString(string(p.UnpackFixedBytes(int(strLen))))
 		if p.Err != nil {
 			return fmt.Errorf("couldn't unmarshal string: %w", p.Err)
 		}
@@ -699,7 +968,7 @@ func (c *genericCodec) unmarshal(
 		typeStack.Add(intfImplementorType)
 
 		// Unmarshal into the struct
-		if err := c.unmarshal(p, intfImplementor, c.maxSliceLen, false /*=nullable*/, typeStack); err != nil {
+		if err := c.unmarshal(p, intfImplementor, c.maxSliceLen, false /*=nullable*/, typeStack, depth+1, budget, arena); err != nil {
 			return err
 		}
 
@@ -707,14 +976,35 @@ func (c *genericCodec) unmarshal(
 		value.Set(intfImplementor)
 		return nil
 	case reflect.Struct:
+		if c.isTime(value.Type()) {
+			return unmarshalTime(p, value)
+		}
+
 		// Get indices of fields that will be unmarshaled into
 		serializedFieldIndices, err := c.fielder.GetSerializedFields(value.Type())
 		if err != nil {
 			return fmt.Errorf("couldn't unmarshal struct: %w", err)
 		}
+		if c.sparse {
+			return c.unmarshalSparseStruct(p, value, serializedFieldIndices, typeStack, depth, budget, arena)
+		}
 		// Go through the fields and umarshal into them
 		for _, fieldDesc := range serializedFieldIndices {
-			if err := c.unmarshal(p, value.Field(fieldDesc.Index), fieldDesc.MaxSliceLen, fieldDesc.Nullable, typeStack); err != nil {
+			field := value.Field(fieldDesc.Index)
+			isPresent, err := unmarshalOmitEmptyPrefix(p, fieldDesc, field)
+			if err != nil {
+				return err
+			}
+			if !isPresent {
+				continue
+			}
+			if err := c.unmarshal(p, field, fieldDesc.MaxSliceLen, fieldDesc.Nullable, typeStack, depth+1, budget, arena); err != nil {
+				return err
+			}
+			if err := fieldDesc.checkStringLen(field); err != nil {
+				return err
+			}
+			if err := checkOmitEmptyCanonical(fieldDesc, field); err != nil {
 				return err
 			}
 		}
@@ -727,12 +1017,22 @@ func (c *genericCodec) unmarshal(
 			}
 		}
 
+		if isProtoMessage(value.Type()) {
+			return unmarshalProto(p, value, c.maxSliceLen)
+		}
+		if c.isBigInt(value.Type()) {
+			return unmarshalBigInt(p, value, c.maxSliceLen)
+		}
+
 		// Get the type this pointer points to
 		t := value.Type().Elem()
+		if err := budget.spend(1, t.Size()); err != nil {
+			return err
+		}
 		// Create a new pointer to a new value of the underlying type
-		v := reflect.New(t)
+		v := arena.New(t)
 		// Fill the value
-		if err := c.unmarshal(p, v.Elem(), c.maxSliceLen, false /*=nullable*/, typeStack); err != nil {
+		if err := c.unmarshal(p, v.Elem(), c.maxSliceLen, false /*=nullable*/, typeStack, depth+1, budget, arena); err != nil {
 			return err
 		}
 		// Assign to the top-level struct's member
@@ -759,6 +1059,10 @@ func (c *genericCodec) unmarshal(
 			prevKey      []byte
 		)
 
+		if err := budget.spend(uint64(numElts), mapKeyType.Size()+mapValueType.Size()); err != nil {
+			return err
+		}
+
 		// Set [value] to be a new map of the appropriate type.
 		value.Set(reflect.MakeMap(mapType))
 
@@ -767,7 +1071,7 @@ func (c *genericCodec) unmarshal(
 
 			keyStartOffset := p.Offset
 
-			if err := c.unmarshal(p, mapKey, c.maxSliceLen, false /*=nullable*/, typeStack); err != nil {
+			if err := c.unmarshal(p, mapKey, c.maxSliceLen, false /*=nullable*/, typeStack, depth+1, budget, arena); err != nil {
 				return err
 			}
 
@@ -779,13 +1083,13 @@ func (c *genericCodec) unmarshal(
 			// increasing key.
 			keyBytes := p.Bytes[keyStartOffset:p.Offset]
 			if i != 0 && bytes.Compare(keyBytes, prevKey) <= 0 {
-				return fmt.Errorf("keys aren't sorted: (%s, %s)", prevKey, mapKey)
+				return fmt.Errorf("%w: keys aren't sorted: (%s, %s)", codec.ErrNonCanonicalEncoding, prevKey, mapKey)
 			}
 			prevKey = keyBytes
 
 			// Get the value
 			mapValue := reflect.New(mapValueType).Elem()
-			if err := c.unmarshal(p, mapValue, c.maxSliceLen, nullable, typeStack); err != nil {
+			if err := c.unmarshal(p, mapValue, c.maxSliceLen, nullable, typeStack, depth+1, budget, arena); err != nil {
 				return err
 			}
 
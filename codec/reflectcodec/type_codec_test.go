@@ -4,10 +4,16 @@
 package reflectcodec
 
 import (
+	"encoding/binary"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
 func TestSizeWithNil(t *testing.T) {
@@ -15,16 +21,337 @@ func TestSizeWithNil(t *testing.T) {
 	var x *int32
 	y := int32(1)
 	c := genericCodec{}
-	_, _, err := c.size(reflect.ValueOf(x), false /*=nullable*/, nil /*=typeStack*/)
+	_, _, err := c.size(reflect.ValueOf(x), 0 /*=maxSliceLen*/, false /*=nullable*/, nil /*=typeStack*/)
 	require.ErrorIs(err, errMarshalNil)
-	len, _, err := c.size(reflect.ValueOf(x), true /*=nullable*/, nil /*=typeStack*/)
+	len, _, err := c.size(reflect.ValueOf(x), 0 /*=maxSliceLen*/, true /*=nullable*/, nil /*=typeStack*/)
 	require.Empty(err)
 	require.Equal(1, len)
 	x = &y
-	len, _, err = c.size(reflect.ValueOf(y), true /*=nullable*/, nil /*=typeStack*/)
+	len, _, err = c.size(reflect.ValueOf(y), 0 /*=maxSliceLen*/, true /*=nullable*/, nil /*=typeStack*/)
 	require.Empty(err)
 	require.Equal(4, len)
-	len, _, err = c.size(reflect.ValueOf(x), true /*=nullable*/, nil /*=typeStack*/)
+	len, _, err = c.size(reflect.ValueOf(x), 0 /*=maxSliceLen*/, true /*=nullable*/, nil /*=typeStack*/)
 	require.Empty(err)
 	require.Equal(5, len)
 }
+
+func TestDescribeType(t *testing.T) {
+	type inner struct {
+		Bytes []byte `serialize:"true" len:"8"`
+	}
+	type outer struct {
+		Ignored string
+		Inner   *inner           `serialize:"true,nullable"`
+		Map     map[string]int32 `serialize:"true"`
+		Self    []outer          `serialize:"true"`
+		Intf    interface{}      `serialize:"true"`
+	}
+	type renamed struct {
+		Other   *inner `serialize:"true,nullable"`
+		Ignored []int
+		Keys    map[string]int32 `serialize:"true"`
+		Values  []renamed        `serialize:"true"`
+		Any     interface{}      `serialize:"true"`
+	}
+
+	require := require.New(t)
+	c := New(nil, []string{DefaultTagName}, 1024).(TypeDescriber)
+
+	description, err := c.DescribeType(reflect.TypeOf(outer{}))
+	require.NoError(err)
+	require.Equal(
+		"struct{max=1024,nullable *struct{max=8 []uint8};max=1024 map[string]int32;max=1024 []recursive(0);max=1024 interface}",
+		description,
+	)
+
+	renamedDescription, err := c.DescribeType(reflect.TypeOf(renamed{}))
+	require.NoError(err)
+	require.Equal(description, renamedDescription)
+
+	_, err = c.DescribeType(reflect.TypeOf(float64(0)))
+	require.ErrorIs(err, codec.ErrUnsupportedType)
+}
+
+func TestDescribeSparseType(t *testing.T) {
+	type sparse struct {
+		Value uint64 `serialize:"true"`
+	}
+
+	require := require.New(t)
+	c := New(nil, []string{DefaultTagName}, 1024).(TypeDescriber)
+	sparseCodec := NewSparse(nil, []string{DefaultTagName}, 1024).(TypeDescriber)
+
+	description, err := c.DescribeType(reflect.TypeOf(sparse{}))
+	require.NoError(err)
+	require.Equal("struct{max=1024 uint64}", description)
+
+	// Sparse structs have a different serialized layout.
+	sparseDescription, err := sparseCodec.DescribeType(reflect.TypeOf(sparse{}))
+	require.NoError(err)
+	require.Equal("sparse struct{max=1024 uint64}", sparseDescription)
+}
+
+func TestDescribeStdlibTypes(t *testing.T) {
+	type stdlib struct {
+		Time time.Time  `serialize:"true"`
+		Int  *big.Int   `serialize:"true,nullable"`
+		Ints []*big.Int `serialize:"true"`
+	}
+
+	require := require.New(t)
+	c := New(nil, []string{DefaultTagName}, 1024).(TypeDescriber)
+
+	description, err := c.DescribeType(reflect.TypeOf(stdlib{}))
+	require.NoError(err)
+	require.Equal("struct{max=1024 time;max=1024,nullable bigint;max=1024 []bigint}", description)
+}
+
+// customUint64 marshals itself as a little endian uint64.
+type customUint64 uint64
+
+func (customUint64) CodecSize() (int, error) {
+	return wrappers.LongLen, nil
+}
+
+func (u customUint64) MarshalCodec(p *wrappers.Packer) error {
+	p.PackFixedBytes(binary.LittleEndian.AppendUint64(nil, uint64(u)))
+	return p.Err
+}
+
+func (u *customUint64) UnmarshalCodec(p *wrappers.Packer) error {
+	b := p.UnpackFixedBytes(wrappers.LongLen)
+	if p.Err != nil {
+		return p.Err
+	}
+	*u = customUint64(binary.LittleEndian.Uint64(b))
+	return nil
+}
+
+func TestDescribeCustomType(t *testing.T) {
+	type custom struct {
+		Value  customUint64   `serialize:"true"`
+		Values []customUint64 `serialize:"true"`
+	}
+
+	require := require.New(t)
+	c := New(nil, []string{DefaultTagName}, 1024).(TypeDescriber)
+
+	description, err := c.DescribeType(reflect.TypeOf(custom{}))
+	require.NoError(err)
+	require.Equal("struct{max=1024 custom(reflectcodec.customUint64);max=1024 []custom(reflectcodec.customUint64)}", description)
+}
+
+func TestDescribeTypeSchema(t *testing.T) {
+	type inner struct {
+		Bytes []byte `serialize:"true" len:"8"`
+	}
+	type outer struct {
+		Ignored string
+		Inner   *inner           `serialize:"true,nullable"`
+		Map     map[string]int32 `serialize:"true"`
+		Self    []outer          `serialize:"true"`
+		Intf    interface{}      `serialize:"true"`
+		Time    time.Time        `serialize:"true"`
+		Custom  customUint64     `serialize:"true"`
+	}
+
+	require := require.New(t)
+	c := NewSparse(nil, []string{DefaultTagName}, 1024).(SchemaDescriber)
+
+	schema, err := c.DescribeTypeSchema(reflect.TypeOf(outer{}))
+	require.NoError(err)
+	require.Equal(
+		&codec.TypeSchema{
+			Kind: codec.KindStruct,
+			Name: "reflectcodec.outer",
+			Fields: []codec.FieldSchema{
+				{
+					Name:        "Inner",
+					MaxSliceLen: 1024,
+					Nullable:    true,
+					Type: &codec.TypeSchema{
+						Kind: codec.KindPointer,
+						Elem: &codec.TypeSchema{
+							Kind: codec.KindStruct,
+							Name: "reflectcodec.inner",
+							Fields: []codec.FieldSchema{
+								{
+									Name:        "Bytes",
+									MaxSliceLen: 8,
+									Type: &codec.TypeSchema{
+										Kind: codec.KindSlice,
+										Elem: &codec.TypeSchema{Kind: "uint8"},
+									},
+								},
+							},
+							Sparse: true,
+						},
+					},
+				},
+				{
+					Name:        "Map",
+					MaxSliceLen: 1024,
+					Type: &codec.TypeSchema{
+						Kind: codec.KindMap,
+						Key:  &codec.TypeSchema{Kind: "string"},
+						Elem: &codec.TypeSchema{Kind: "int32"},
+					},
+				},
+				{
+					Name:        "Self",
+					MaxSliceLen: 1024,
+					Type: &codec.TypeSchema{
+						Kind: codec.KindSlice,
+						Elem: &codec.TypeSchema{
+							Kind: codec.KindRecursive,
+							Name: "reflectcodec.outer",
+						},
+					},
+				},
+				{
+					Name:        "Intf",
+					MaxSliceLen: 1024,
+					Type:        &codec.TypeSchema{Kind: codec.KindInterface},
+				},
+				{
+					Name:        "Time",
+					MaxSliceLen: 1024,
+					Type: &codec.TypeSchema{
+						Kind: codec.KindTime,
+						Name: "time.Time",
+					},
+				},
+				{
+					Name:        "Custom",
+					MaxSliceLen: 1024,
+					Type: &codec.TypeSchema{
+						Kind: codec.KindCustom,
+						Name: "reflectcodec.customUint64",
+					},
+				},
+			},
+			Sparse: true,
+		},
+		schema,
+	)
+
+	_, err = c.DescribeTypeSchema(reflect.TypeOf(float64(0)))
+	require.ErrorIs(err, codec.ErrUnsupportedType)
+}
+
+func TestParseSerializeTag(t *testing.T) {
+	tests := []struct {
+		value            string
+		expectedTag      SerializeTag
+		expectedCaptured bool
+		expectedErr      error
+	}{
+		{
+			value: "",
+		},
+		{
+			value: "false",
+		},
+		{
+			value:            TagValue,
+			expectedCaptured: true,
+		},
+		{
+			value:            TagWithNullableValue,
+			expectedTag:      SerializeTag{Nullable: true},
+			expectedCaptured: true,
+		},
+		{
+			value:            "true,maxLen=1024",
+			expectedTag:      SerializeTag{MaxLen: 1024},
+			expectedCaptured: true,
+		},
+		{
+			value:            "true,nullable,maxLen=8",
+			expectedTag:      SerializeTag{Nullable: true, MaxLen: 8},
+			expectedCaptured: true,
+		},
+		{
+			value:            "true,omitempty,maxLen=8",
+			expectedTag:      SerializeTag{MaxLen: 8, OmitEmpty: true},
+			expectedCaptured: true,
+		},
+		{
+			value: "true,unknown",
+		},
+		{
+			value:       "true,maxLen=0",
+			expectedErr: errInvalidMaxLen,
+		},
+		{
+			value:       "true,maxLen=abc",
+			expectedErr: errInvalidMaxLen,
+		},
+		{
+			value:       "true,maxLen=4294967295",
+			expectedErr: errInvalidMaxLen,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			require := require.New(t)
+
+			tag, captured, err := ParseSerializeTag(test.value)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedTag, tag)
+			require.Equal(test.expectedCaptured, captured)
+		})
+	}
+}
+
+func TestGetSerializedFieldsMaxLen(t *testing.T) {
+	type fields struct {
+		Tighter []byte `serialize:"true,maxLen=8"`
+		Looser  []byte `serialize:"true,maxLen=4096"`
+		Both    []byte `serialize:"true,maxLen=8" len:"4"`
+	}
+
+	require := require.New(t)
+	fielder := NewStructFielder([]string{DefaultTagName}, 1024)
+	serializedFields, err := fielder.GetSerializedFields(reflect.TypeOf(fields{}))
+	require.NoError(err)
+	require.Equal([]FieldDesc{
+		{Index: 0, MaxSliceLen: 8, MaxLen: 8},
+		{Index: 1, MaxSliceLen: 1024, MaxLen: 4096},
+		{Index: 2, MaxSliceLen: 4, MaxLen: 8},
+	}, serializedFields)
+
+	type invalid struct {
+		Value []byte `serialize:"true,maxLen=-1"`
+	}
+	_, err = fielder.GetSerializedFields(reflect.TypeOf(invalid{}))
+	require.ErrorIs(err, errInvalidMaxLen)
+}
+
+func TestGetSerializedFieldsOmitEmpty(t *testing.T) {
+	type fields struct {
+		Slice     []byte        `serialize:"true,omitempty"`
+		Map       map[int8]int8 `serialize:"true,omitempty"`
+		Pointer   *int32        `serialize:"true,omitempty"`
+		Interface interface{}   `serialize:"true,omitempty"`
+		Array     [2]byte       `serialize:"true"`
+	}
+
+	require := require.New(t)
+	fielder := NewStructFielder([]string{DefaultTagName}, 1024)
+	serializedFields, err := fielder.GetSerializedFields(reflect.TypeOf(fields{}))
+	require.NoError(err)
+	require.Equal([]FieldDesc{
+		{Index: 0, MaxSliceLen: 1024, OmitEmpty: true},
+		{Index: 1, MaxSliceLen: 1024, OmitEmpty: true},
+		{Index: 2, MaxSliceLen: 1024, OmitEmpty: true},
+		{Index: 3, MaxSliceLen: 1024, OmitEmpty: true},
+		{Index: 4, MaxSliceLen: 1024},
+	}, serializedFields)
+
+	type invalid struct {
+		Value [2]byte `serialize:"true,omitempty"`
+	}
+	_, err = fielder.GetSerializedFields(reflect.TypeOf(invalid{}))
+	require.ErrorIs(err, errInvalidOmitEmpty)
+}
@@ -5,7 +5,11 @@ package codec
 
 import "errors"
 
-var ErrDuplicateType = errors.New("duplicate type registration")
+var (
+	ErrDuplicateType   = errors.New("duplicate type registration")
+	ErrDuplicateTypeID = errors.New("duplicate type ID registration")
+	ErrRecursiveType   = errors.New("recursive type")
+)
 
 // Registry registers new types that can be marshaled into
 type Registry interface {
@@ -0,0 +1,120 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import "errors"
+
+// Kinds of the values described by a [TypeSchema], in addition to the names of
+// the primitive kinds: bool, string, uint8, int8, uint16, int16, uint32, int32,
+// uint64 and int64.
+const (
+	// An interface is serialized as the type ID of the registered type it
+	// holds, followed by the value it holds.
+	KindInterface = "interface"
+	KindPointer   = "pointer"
+	KindSlice     = "slice"
+	KindArray     = "array"
+	KindMap       = "map"
+	KindStruct    = "struct"
+	// A time is serialized as the number of nanoseconds since the unix epoch,
+	// as an int64.
+	KindTime = "time"
+	// A big integer is serialized as a bool that's true iff the integer is
+	// negative, followed by a length prefixed byte slice holding the big
+	// endian absolute value of the integer.
+	KindBigInt = "bigint"
+	// A protobuf message is serialized as a length prefixed byte slice holding
+	// the protobuf encoding of the message.
+	KindProto = "proto"
+	// A value of a type that marshals itself. Its layout isn't known.
+	KindCustom = "custom"
+	// A struct that is being described by an enclosing schema. Structs that
+	// reference themselves are described by a reference to the enclosing
+	// schema to guarantee termination.
+	KindRecursive = "recursive"
+)
+
+// Encodings of the type IDs that prefix interface values.
+const (
+	// The type ID is a 4 byte big endian integer.
+	TypeIDEncodingUint32 = "uint32"
+	// The type ID is encoded as a uvarint in as few bytes as possible.
+	TypeIDEncodingUvarint = "uvarint"
+	// The type ID is a 2 byte big endian group ID followed by a 2 byte big
+	// endian type ID within the group.
+	TypeIDEncodingGroupedUint16 = "uint16,uint16"
+)
+
+var ErrRegistrySchemaUnsupported = errors.New("codec doesn't support describing its registry")
+
+// RegistryDescriber is implemented by codecs that can describe their
+// registered types.
+type RegistryDescriber interface {
+	// DescribeRegistry returns the registered type IDs along with the
+	// serialized layout of the types they map to. The schema can be exported
+	// to generate compatible decoders in other languages.
+	DescribeRegistry() (*RegistrySchema, error)
+}
+
+// RegistrySchema is a machine-readable description of the types registered
+// with a codec.
+type RegistrySchema struct {
+	// How the type IDs of interface values are encoded.
+	TypeIDEncoding string `json:"typeIDEncoding"`
+	// The registered types, sorted by type ID.
+	Types []RegisteredTypeSchema `json:"types"`
+}
+
+type RegisteredTypeSchema struct {
+	// The group of the type ID, if type IDs are grouped.
+	GroupID uint16 `json:"groupID,omitempty"`
+	TypeID  uint32 `json:"typeID"`
+	// Whether the type ID is an alias. Values with the type ID are
+	// unmarshalled into the type, but the type is marshalled with the type ID
+	// it's registered under.
+	Alias bool `json:"alias,omitempty"`
+	// The Go name of the type.
+	Name   string      `json:"name"`
+	Schema *TypeSchema `json:"schema"`
+}
+
+// TypeSchema describes the serialized layout of a type.
+type TypeSchema struct {
+	Kind string `json:"kind"`
+	// The Go name of the type, if it's a named type.
+	Name string `json:"name,omitempty"`
+	// The length of an array.
+	Len int `json:"len,omitempty"`
+	// The key type of a map.
+	Key *TypeSchema `json:"key,omitempty"`
+	// The element type of a pointer, slice, array or map.
+	Elem *TypeSchema `json:"elem,omitempty"`
+	// The serialized fields of a struct, in the order they're serialized in.
+	Fields []FieldSchema `json:"fields,omitempty"`
+	// Whether the fields of a struct that are equal to their zero value are
+	// omitted.
+	Sparse bool `json:"sparse,omitempty"`
+	// The full name of a protobuf message.
+	ProtoMessage string `json:"protoMessage,omitempty"`
+	// The number of enclosing schemas to skip to reach the struct a recursive
+	// schema refers to, where 0 is the closest enclosing struct.
+	Depth int `json:"depth,omitempty"`
+}
+
+type FieldSchema struct {
+	// The Go name of the field.
+	Name string `json:"name"`
+	// The maximum length of the slices, maps and strings in the field.
+	MaxSliceLen uint32 `json:"maxSliceLen"`
+	// Whether the pointers and interfaces in the field are prefixed by a bool
+	// that's true iff they're nil. Otherwise they can't be nil.
+	Nullable bool `json:"nullable,omitempty"`
+	// The maximum length specified by the maxLen tag option of the field, or
+	// 0 if it doesn't have one.
+	MaxLen uint32 `json:"maxLen,omitempty"`
+	// Whether the field is prefixed by a bool that's true iff it's present.
+	// Fields that aren't present are nil or empty.
+	OmitEmpty bool        `json:"omitEmpty,omitempty"`
+	Type      *TypeSchema `json:"type"`
+}
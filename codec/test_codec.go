@@ -4,16 +4,35 @@
 package codec
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
 	"math"
+	"math/big"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
 var (
 	Tests = []func(c GeneralCodec, t testing.TB){
 		TestStruct,
 		TestRegisterStructTwice,
+		TestRegisterRecursiveType,
 		TestUInt32,
 		TestUIntPtr,
 		TestSlice,
@@ -44,11 +63,29 @@ var (
 		TestExtraSpace,
 		TestSliceLengthOverflow,
 		TestMap,
+		TestMapCanonicalEncoding,
+		TestUnmarshalWithArena,
+		TestHashOf,
+		TestStreaming,
+		TestProtoMessage,
+		TestProtoMessageNonCanonical,
+		TestTime,
+		TestBigInt,
+		TestCustomMarshaler,
+		TestFingerprint,
+		TestFreeze,
+		TestOmitEmpty,
+		TestJSON,
 	}
 
 	MultipleTagsTests = []func(c GeneralCodec, t testing.TB){
 		TestMultipleTags,
 	}
+
+	SparseTests = []func(c GeneralCodec, t testing.TB){
+		TestSparseStruct,
+		TestSparseNonCanonical,
+	}
 )
 
 // The below structs and interfaces exist
@@ -260,6 +297,46 @@ func TestRegisterStructTwice(codec GeneralCodec, t testing.TB) {
 	require.ErrorIs(err, ErrDuplicateType)
 }
 
+type selfReferential struct {
+	Next *selfReferential `serialize:"true"`
+}
+
+type mutuallyRecursiveA struct {
+	B []mutuallyRecursiveB `serialize:"true"`
+}
+
+type mutuallyRecursiveB struct {
+	A map[uint32]*mutuallyRecursiveA `serialize:"true"`
+}
+
+type notSerializedRecursion struct {
+	Next *notSerializedRecursion
+	Foo  Foo `serialize:"true"`
+}
+
+type sharedField struct {
+	Left  MyInnerStruct  `serialize:"true"`
+	Right *MyInnerStruct `serialize:"true"`
+}
+
+func TestRegisterRecursiveType(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	err := codec.RegisterType(&selfReferential{Next: &selfReferential{}})
+	require.ErrorIs(err, ErrRecursiveType)
+
+	err = codec.RegisterType(&mutuallyRecursiveA{B: []mutuallyRecursiveB{}})
+	require.ErrorIs(err, ErrRecursiveType)
+
+	err = codec.RegisterType(mutuallyRecursiveB{A: map[uint32]*mutuallyRecursiveA{}})
+	require.ErrorIs(err, ErrRecursiveType)
+
+	// Fields that aren't serialized and interfaces can't cause recursion at
+	// registration time, and a type may be reached through several fields.
+	require.NoError(codec.RegisterType(&notSerializedRecursion{Next: &notSerializedRecursion{}, Foo: &MyInnerStruct{}}))
+	require.NoError(codec.RegisterType(&sharedField{Left: MyInnerStruct{}, Right: &MyInnerStruct{}}))
+}
+
 func TestUInt32(codec GeneralCodec, t testing.TB) {
 	require := require.New(t)
 
@@ -844,9 +921,8 @@ func TestSliceWithEmptySerializationOutOfMemory(codec GeneralCodec, t testing.TB
 	_, err := manager.Marshal(0, val)
 	require.ErrorIs(err, ErrMaxSliceLenExceeded)
 
-	bytesLen, err := manager.Size(0, val)
-	require.NoError(err)
-	require.Equal(6, bytesLen) // 2 byte codec version + 4 byte length prefix
+	_, err = manager.Size(0, val)
+	require.ErrorIs(err, ErrMaxSliceLenExceeded)
 }
 
 func TestSliceTooLarge(codec GeneralCodec, t testing.TB) {
@@ -932,17 +1008,25 @@ func TestUnmarshalInvalidInterface(codec GeneralCodec, t testing.TB) {
 	require.NoError(codec.RegisterType(&innerNoInterface{}))
 	require.NoError(manager.RegisterCodec(0, codec))
 
+	// Has the same encoding as [outer], but accepts any registered type, so
+	// that the type IDs are encoded however [codec] encodes them.
+	type anyOuter struct {
+		Interface interface{} `serialize:"true"`
+	}
+
 	{
-		bytes := []byte{0, 0, 0, 0, 0, 0}
+		bytes, err := manager.Marshal(0, anyOuter{Interface: &innerInterface{}})
+		require.NoError(err)
 		s := outer{}
 		version, err := manager.Unmarshal(bytes, &s)
 		require.NoError(err)
 		require.Zero(version)
 	}
 	{
-		bytes := []byte{0, 0, 0, 0, 0, 1}
+		bytes, err := manager.Marshal(0, anyOuter{Interface: &innerNoInterface{}})
+		require.NoError(err)
 		s := outer{}
-		_, err := manager.Unmarshal(bytes, &s)
+		_, err = manager.Unmarshal(bytes, &s)
 		require.ErrorIs(err, ErrDoesNotImplementInterface)
 	}
 }
@@ -966,6 +1050,13 @@ func TestRestrictedSlice(codec GeneralCodec, t testing.TB) {
 	s.Bytes = []byte{0, 1, 2}
 	_, err = manager.Marshal(0, s)
 	require.ErrorIs(err, ErrMaxSliceLenExceeded)
+
+	// Size must not report a size for a value that can't be marshalled
+	_, err = manager.Size(0, s)
+	require.ErrorIs(err, ErrMaxSliceLenExceeded)
+
+	_, err = manager.Size(0, string(make([]byte, math.MaxUint16+1)))
+	require.ErrorIs(err, ErrMaxSliceLenExceeded)
 }
 
 // Test unmarshaling something with extra data
@@ -1115,6 +1206,858 @@ func TestMap(codec GeneralCodec, t testing.TB) {
 	require.Len(outerArrayBytes, outerArraySize)
 }
 
+// Test that map keys that can't be ordered canonically are rejected
+func TestMapCanonicalEncoding(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	// Distinct pointers to equal values have the same encoding.
+	key1 := int32(1)
+	key2 := int32(1)
+	_, err := manager.Marshal(0, map[*int32]int32{
+		&key1: 1,
+		&key2: 2,
+	})
+	require.ErrorIs(err, ErrDuplicateMapKey)
+
+	bytes, err := manager.Marshal(0, map[int32]int32{
+		1: 10,
+		2: 20,
+	})
+	require.NoError(err)
+	require.Equal([]byte{
+		0x00, 0x00, // codec version
+		0x00, 0x00, 0x00, 0x02, // number of key-value pairs
+		0x00, 0x00, 0x00, 0x01, // key
+		0x00, 0x00, 0x00, 0x0a, // value
+		0x00, 0x00, 0x00, 0x02, // key
+		0x00, 0x00, 0x00, 0x14, // value
+	}, bytes)
+
+	// Swap the key-value pairs.
+	unsortedBytes := append([]byte{}, bytes[:6]...)
+	unsortedBytes = append(unsortedBytes, bytes[14:]...)
+	unsortedBytes = append(unsortedBytes, bytes[6:14]...)
+
+	var unmarshalled map[int32]int32
+	_, err = manager.Unmarshal(unsortedBytes, &unmarshalled)
+	require.ErrorIs(err, ErrNonCanonicalEncoding)
+}
+
+func TestUnmarshalWithArena(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	number := int32(5)
+	input := []MyStructWithNullable{
+		{
+			Int32:      &number,
+			Int32Slice: []*int32{&number, nil, &number},
+			Int32Map:   map[int32]*int32{},
+		},
+		{
+			Int64:      new(int64),
+			Int32Slice: []*int32{},
+			Int32Map:   map[int32]*int32{1: &number},
+		},
+	}
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	bytes, err := manager.Marshal(0, input)
+	require.NoError(err)
+
+	arena := NewArena(2)
+	for i := 0; i < 3; i++ {
+		var output []MyStructWithNullable
+		version, err := manager.UnmarshalWithArena(bytes, &output, arena)
+		require.NoError(err)
+		require.Zero(version)
+		require.Equal(input, output)
+
+		// Appending to a slice allocated from the arena must not corrupt
+		// values allocated after it.
+		output[0].Int32Slice = append(output[0].Int32Slice, nil)
+		require.Equal(&number, output[0].Int32Slice[2])
+		require.Equal(input[1], output[1])
+	}
+
+	arena.Reset()
+
+	var output []MyStructWithNullable
+	_, err = manager.UnmarshalWithArena(bytes, &output, arena)
+	require.NoError(err)
+	require.Equal(input, output)
+}
+
+func TestHashOf(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	for _, value := range []interface{}{
+		"",
+		"hello",
+		[]uint32{1, 2, 3},
+		string(make([]byte, initialSliceCap+1)),
+	} {
+		bytes, err := manager.Marshal(0, value)
+		require.NoError(err)
+
+		// Hash the value repeatedly to reuse the pooled buffers.
+		for i := 0; i < 2; i++ {
+			id, err := manager.HashOf(0, value)
+			require.NoError(err)
+			require.Equal(ids.ID(hashing.ComputeHash256Array(bytes)), id)
+		}
+	}
+
+	_, err := manager.HashOf(1, "")
+	require.ErrorIs(err, ErrUnknownVersion)
+
+	_, err = manager.HashOf(0, nil)
+	require.ErrorIs(err, ErrMarshalNil)
+}
+
+func TestStreaming(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewManager(math.MaxInt32)
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	// Larger than the stream buffer, to be flushed and read in several parts
+	largeMap := make(map[uint32][]byte)
+	for i := uint32(0); i < 64; i++ {
+		largeMap[i] = make([]byte, 1024)
+		largeMap[i][0] = byte(i)
+	}
+
+	for _, value := range []interface{}{
+		"",
+		[]uint32{1, 2, 3},
+		largeMap,
+	} {
+		expected, err := manager.Marshal(0, value)
+		require.NoError(err)
+
+		w := &bytes.Buffer{}
+		require.NoError(manager.MarshalInto(0, value, w))
+		require.Equal(expected, w.Bytes())
+
+		unmarshalled := reflect.New(reflect.TypeOf(value))
+		version, err := manager.UnmarshalFrom(w, unmarshalled.Interface())
+		require.NoError(err)
+		require.Zero(version)
+		require.Equal(value, unmarshalled.Elem().Interface())
+	}
+
+	valueBytes, err := manager.Marshal(0, []uint32{1, 2, 3})
+	require.NoError(err)
+
+	var extraSpace []uint32
+	_, err = manager.UnmarshalFrom(bytes.NewReader(append(valueBytes, 0)), &extraSpace)
+	require.ErrorIs(err, ErrExtraSpace)
+
+	var truncated []uint32
+	_, err = manager.UnmarshalFrom(bytes.NewReader(valueBytes[:len(valueBytes)-1]), &truncated)
+	require.ErrorIs(err, wrappers.ErrInsufficientLength)
+
+	smallManager := NewManager(len(valueBytes) - 1)
+	require.NoError(smallManager.RegisterCodec(0, codec))
+
+	err = smallManager.MarshalInto(0, []uint32{1, 2, 3}, io.Discard)
+	require.ErrorIs(err, wrappers.ErrInsufficientLength)
+
+	var tooLarge []uint32
+	_, err = smallManager.UnmarshalFrom(bytes.NewReader(valueBytes), &tooLarge)
+	require.ErrorIs(err, wrappers.ErrInsufficientLength)
+}
+
+type MyStructWithProto struct {
+	Str       string                 `serialize:"true"`
+	Message   *wrapperspb.BytesValue `serialize:"true"`
+	Interface proto.Message          `serialize:"true"`
+	Nullable  *wrapperspb.BytesValue `serialize:"true,nullable"`
+}
+
+func TestProtoMessage(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	require.NoError(codec.RegisterType(&wrapperspb.StringValue{}))
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	input := MyStructWithProto{
+		Str:       "hello",
+		Message:   wrapperspb.Bytes([]byte{1, 2, 3}),
+		Interface: wrapperspb.String("world"),
+	}
+	bytes, err := manager.Marshal(0, input)
+	require.NoError(err)
+
+	size, err := manager.Size(0, input)
+	require.NoError(err)
+	require.Len(bytes, size)
+
+	var output MyStructWithProto
+	version, err := manager.Unmarshal(bytes, &output)
+	require.NoError(err)
+	require.Zero(version)
+	require.Equal(input.Str, output.Str)
+	require.True(proto.Equal(input.Message, output.Message))
+	require.True(proto.Equal(input.Interface, output.Interface))
+	require.Nil(output.Nullable)
+
+	// The protobuf encoding of a message is length prefixed.
+	messageBytes, err := proto.Marshal(input.Message)
+	require.NoError(err)
+	offset := wrappers.ShortLen + wrappers.StringLen(input.Str)
+	require.Equal(uint32(len(messageBytes)), binary.BigEndian.Uint32(bytes[offset:]))
+	offset += wrappers.IntLen
+	require.Equal(messageBytes, bytes[offset:offset+len(messageBytes)])
+}
+
+func TestProtoMessageNonCanonical(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	require.NoError(codec.RegisterType(&wrapperspb.StringValue{}))
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	input := MyStructWithProto{
+		Message:   wrapperspb.Bytes([]byte{1}),
+		Interface: wrapperspb.String(""),
+	}
+	canonicalBytes, err := manager.Marshal(0, input)
+	require.NoError(err)
+
+	// Offset of the length prefixed encoding of [input.Message].
+	offset := wrappers.ShortLen + wrappers.StringLen(input.Str)
+	messageLen := int(binary.BigEndian.Uint32(canonicalBytes[offset:]))
+	messageEnd := offset + wrappers.IntLen + messageLen
+	require.Equal([]byte{0x0a, 0x01, 0x01}, canonicalBytes[offset+wrappers.IntLen:messageEnd])
+
+	// Replaces the encoding of [input.Message] with [messageBytes].
+	withMessage := func(messageBytes []byte) []byte {
+		b := slices.Clone(canonicalBytes[:offset])
+		b = binary.BigEndian.AppendUint32(b, uint32(len(messageBytes)))
+		b = append(b, messageBytes...)
+		return append(b, canonicalBytes[messageEnd:]...)
+	}
+
+	tests := []struct {
+		name         string
+		messageBytes []byte
+		expectedErr  error
+	}{
+		{
+			name:         "canonical",
+			messageBytes: []byte{0x0a, 0x01, 0x01},
+		},
+		{
+			name:         "repeated field",
+			messageBytes: []byte{0x0a, 0x00, 0x0a, 0x01, 0x01},
+			expectedErr:  ErrNonCanonicalEncoding,
+		},
+		{
+			name:         "non-minimal length",
+			messageBytes: []byte{0x0a, 0x81, 0x00, 0x01},
+			expectedErr:  ErrNonCanonicalEncoding,
+		},
+		{
+			name:         "unknown field",
+			messageBytes: []byte{0x0a, 0x01, 0x01, 0x10, 0x01},
+		},
+		{
+			name:         "unknown field before known field",
+			messageBytes: []byte{0x10, 0x01, 0x0a, 0x01, 0x01},
+			expectedErr:  ErrNonCanonicalEncoding,
+		},
+	}
+	for _, test := range tests {
+		var output MyStructWithProto
+		_, err := manager.Unmarshal(withMessage(test.messageBytes), &output)
+		require.ErrorIs(err, test.expectedErr, test.name)
+		if test.expectedErr == nil {
+			require.Equal(input.Message.Value, output.Message.Value, test.name)
+		}
+	}
+}
+
+func TestTime(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	type timeStruct struct {
+		Time  time.Time   `serialize:"true"`
+		Times []time.Time `serialize:"true"`
+	}
+
+	input := timeStruct{
+		Time: time.Date(2023, time.October, 16, 1, 2, 3, 4, time.FixedZone("test", 3600)),
+		Times: []time.Time{
+			time.Unix(0, 0),
+			time.Unix(-1, 0),
+		},
+	}
+	bytes, err := manager.Marshal(0, input)
+	require.NoError(err)
+
+	size, err := manager.Size(0, input)
+	require.NoError(err)
+	require.Len(bytes, size)
+
+	// Times are marshalled as unix nanoseconds.
+	offset := wrappers.ShortLen
+	require.Equal(uint64(input.Time.UnixNano()), binary.BigEndian.Uint64(bytes[offset:]))
+
+	var output timeStruct
+	version, err := manager.Unmarshal(bytes, &output)
+	require.NoError(err)
+	require.Zero(version)
+	require.True(input.Time.Equal(output.Time))
+	require.Equal(time.UTC, output.Time.Location())
+	require.Len(output.Times, len(input.Times))
+	for i, inputTime := range input.Times {
+		require.True(inputTime.Equal(output.Times[i]))
+	}
+
+	// Times that can't be represented as unix nanoseconds can't be marshalled.
+	input.Times = append(input.Times, time.Time{})
+	_, err = manager.Marshal(0, input)
+	require.ErrorIs(err, ErrTimeOutOfRange)
+	_, err = manager.Size(0, input)
+	require.ErrorIs(err, ErrTimeOutOfRange)
+}
+
+func TestBigInt(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	type bigIntStruct struct {
+		Ints     []*big.Int `serialize:"true"`
+		Nullable *big.Int   `serialize:"true,nullable"`
+	}
+
+	large, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(ok)
+	input := bigIntStruct{
+		Ints: []*big.Int{
+			big.NewInt(0),
+			big.NewInt(1),
+			big.NewInt(-256),
+			large,
+			new(big.Int).Neg(large),
+		},
+	}
+	bytes, err := manager.Marshal(0, input)
+	require.NoError(err)
+
+	size, err := manager.Size(0, input)
+	require.NoError(err)
+	require.Len(bytes, size)
+
+	var output bigIntStruct
+	version, err := manager.Unmarshal(bytes, &output)
+	require.NoError(err)
+	require.Zero(version)
+	require.Len(output.Ints, len(input.Ints))
+	for i, inputInt := range input.Ints {
+		require.Zero(inputInt.Cmp(output.Ints[i]))
+	}
+	require.Nil(output.Nullable)
+
+	// -256 is marshalled as its sign followed by its absolute value.
+	bytes, err = manager.Marshal(0, big.NewInt(-256))
+	require.NoError(err)
+	require.Equal(
+		[]byte{
+			0x00, 0x00, // codec version
+			0x01,                   // negative
+			0x00, 0x00, 0x00, 0x02, // length of the absolute value
+			0x01, 0x00, // absolute value
+		},
+		bytes,
+	)
+
+	// The encoding of a big integer is canonical.
+	for _, nonCanonical := range [][]byte{
+		// leading zeros
+		{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x01},
+		// negative zero
+		{0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00},
+	} {
+		var i *big.Int
+		_, err := manager.Unmarshal(nonCanonical, &i)
+		require.ErrorIs(err, ErrNonCanonicalEncoding)
+	}
+}
+
+var errShortStringTooLong = errors.New("short string too long")
+
+// shortString marshals itself with a single byte length prefix.
+type shortString string
+
+func (s shortString) CodecSize() (int, error) {
+	if len(s) > math.MaxUint8 {
+		return 0, errShortStringTooLong
+	}
+	return wrappers.ByteLen + len(s), nil
+}
+
+func (s shortString) MarshalCodec(p *wrappers.Packer) error {
+	if len(s) > math.MaxUint8 {
+		return errShortStringTooLong
+	}
+	p.PackByte(uint8(len(s)))
+	p.PackFixedBytes([]byte(s))
+	return p.Err
+}
+
+func (s *shortString) UnmarshalCodec(p *wrappers.Packer) error {
+	strLen := p.UnpackByte()
+	*s = shortString(p.UnpackFixedBytes(int(strLen)))
+	return p.Err
+}
+
+type myStructWithCustom struct {
+	Str      shortString   `serialize:"true"`
+	Strs     []shortString `serialize:"true"`
+	Nullable *shortString  `serialize:"true,nullable"`
+}
+
+func TestCustomMarshaler(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	nullable := shortString("c")
+	input := myStructWithCustom{
+		Str:      "a",
+		Strs:     []shortString{"", "bb"},
+		Nullable: &nullable,
+	}
+	bytes, err := manager.Marshal(0, input)
+	require.NoError(err)
+	require.Equal(
+		[]byte{
+			0x00, 0x00, // codec version
+			0x01, 'a', // Str
+			0x00, 0x00, 0x00, 0x02, // length of Strs
+			0x00,           // Strs[0]
+			0x02, 'b', 'b', // Strs[1]
+			0x00,      // Nullable isn't nil
+			0x01, 'c', // *Nullable
+		},
+		bytes,
+	)
+
+	size, err := manager.Size(0, input)
+	require.NoError(err)
+	require.Len(bytes, size)
+
+	var output myStructWithCustom
+	version, err := manager.Unmarshal(bytes, &output)
+	require.NoError(err)
+	require.Zero(version)
+	require.Equal(input, output)
+
+	// Errors returned by the type are propagated.
+	input.Strs = append(input.Strs, shortString(make([]byte, math.MaxUint8+1)))
+	_, err = manager.Marshal(0, input)
+	require.ErrorIs(err, errShortStringTooLong)
+	_, err = manager.Size(0, input)
+	require.ErrorIs(err, errShortStringTooLong)
+}
+
+type myStructWithJSON struct {
+	Time      time.Time              `serialize:"true"`
+	Int       *big.Int               `serialize:"true"`
+	Nullable  *big.Int               `serialize:"true,nullable"`
+	Message   *wrapperspb.BytesValue `serialize:"true"`
+	Interface Foo                    `serialize:"true"`
+	Proto     proto.Message          `serialize:"true"`
+	Custom    shortString            `serialize:"true"`
+	Customs   []shortString          `serialize:"true"`
+}
+
+func TestJSON(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	require.NoError(codec.RegisterType(&MyInnerStruct2{}))
+	require.NoError(codec.RegisterType(&wrapperspb.StringValue{}))
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	large, ok := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	require.True(ok)
+	input := myStructWithJSON{
+		Time:      time.Date(2023, time.October, 16, 1, 2, 3, 4, time.FixedZone("test", 3600)),
+		Int:       large,
+		Message:   wrapperspb.Bytes([]byte{1, 2, 3}),
+		Interface: &MyInnerStruct2{Bool: true},
+		Proto:     wrapperspb.String("hello"),
+		Custom:    "a",
+		Customs:   []shortString{"", "bb"},
+	}
+	jsonBytes, err := manager.ToJSON(0, input)
+	require.NoError(err)
+
+	var fields map[string]json.RawMessage
+	require.NoError(json.Unmarshal(jsonBytes, &fields))
+	require.JSONEq(`"2023-10-16T00:02:03.000000004Z"`, string(fields["Time"]))
+	require.JSONEq(`"-123456789012345678901234567890"`, string(fields["Int"]))
+	require.JSONEq(`null`, string(fields["Nullable"]))
+	require.JSONEq(`"AQID"`, string(fields["Message"]))
+	require.JSONEq(`"0x0161"`, string(fields["Custom"]))
+	require.JSONEq(`["0x00", "0x026262"]`, string(fields["Customs"]))
+
+	var (
+		interfaceValue jsonInterfaceValue
+		protoValue     jsonInterfaceValue
+	)
+	require.NoError(json.Unmarshal(fields["Interface"], &interfaceValue))
+	require.Equal("*codec.MyInnerStruct2", interfaceValue.Type)
+	require.JSONEq(`{"Bool": true}`, string(interfaceValue.Value))
+	require.NoError(json.Unmarshal(fields["Proto"], &protoValue))
+	require.Equal("*wrapperspb.StringValue", protoValue.Type)
+	require.JSONEq(`"hello"`, string(protoValue.Value))
+
+	var output myStructWithJSON
+	require.NoError(manager.FromJSON(0, jsonBytes, &output))
+	require.True(input.Time.Equal(output.Time))
+	require.Equal(time.UTC, output.Time.Location())
+	require.Zero(input.Int.Cmp(output.Int))
+	require.Nil(output.Nullable)
+	require.True(proto.Equal(input.Message, output.Message))
+	require.Equal(input.Interface, output.Interface)
+	require.True(proto.Equal(input.Proto, output.Proto))
+	require.Equal(input.Custom, output.Custom)
+	require.Equal(input.Customs, output.Customs)
+
+	// The binary encoding is unchanged by the round trip.
+	inputBytes, err := manager.Marshal(0, input)
+	require.NoError(err)
+	outputBytes, err := manager.Marshal(0, output)
+	require.NoError(err)
+	require.Equal(inputBytes, outputBytes)
+
+	// Every serialized field must be present.
+	missingField := maps.Clone(fields)
+	delete(missingField, "Int")
+	missingFieldBytes, err := json.Marshal(missingField)
+	require.NoError(err)
+	err = manager.FromJSON(0, missingFieldBytes, &output)
+	require.ErrorIs(err, ErrMissingJSONField)
+
+	// The type of an interface value must be the type registered under its
+	// type ID.
+	interfaceValue.Type = protoValue.Type
+	mismatchedType := maps.Clone(fields)
+	mismatchedType["Interface"], err = json.Marshal(interfaceValue)
+	require.NoError(err)
+	mismatchedTypeBytes, err := json.Marshal(mismatchedType)
+	require.NoError(err)
+	err = manager.FromJSON(0, mismatchedTypeBytes, &output)
+	require.ErrorIs(err, ErrJSONTypeMismatch)
+}
+
+// jsonInterfaceValue is the JSON representation of a non-nil interface value.
+type jsonInterfaceValue struct {
+	TypeID string          `json:"typeID"`
+	Type   string          `json:"type"`
+	Value  json.RawMessage `json:"value"`
+}
+
+func TestFingerprint(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	require.NoError(codec.RegisterType(&MyInnerStruct{}))
+	fingerprint, err := manager.Fingerprint(0)
+	require.NoError(err)
+	require.NoError(manager.VerifyFingerprint(0, fingerprint))
+
+	// Fingerprints are deterministic.
+	sameFingerprint, err := manager.Fingerprint(0)
+	require.NoError(err)
+	require.Equal(fingerprint, sameFingerprint)
+
+	// Registering a type changes the fingerprint.
+	require.NoError(codec.RegisterType(&MyInnerStruct2{}))
+	newFingerprint, err := manager.Fingerprint(0)
+	require.NoError(err)
+	require.NotEqual(fingerprint, newFingerprint)
+
+	err = manager.VerifyFingerprint(0, fingerprint)
+	require.ErrorIs(err, ErrFingerprintMismatch)
+
+	_, err = manager.Fingerprint(1)
+	require.ErrorIs(err, ErrUnknownVersion)
+}
+
+func TestFreeze(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	freezer, ok := codec.(Freezer)
+	require.True(ok)
+
+	require.NoError(codec.RegisterType(&MyInnerStruct{}))
+	err := codec.RegisterType(&MyInnerStruct{})
+	require.ErrorIs(err, ErrDuplicateType)
+
+	freezer.Freeze()
+	err = codec.RegisterType(&MyInnerStruct2{})
+	require.ErrorIs(err, ErrRegistryFrozen)
+
+	// Types registered before the codec was frozen can still be marshalled.
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+	var myStruct Foo = &MyInnerStruct{Str: "hello"}
+	bytes, err := manager.Marshal(0, &myStruct)
+	require.NoError(err)
+	var unmarshalled Foo
+	_, err = manager.Unmarshal(bytes, &unmarshalled)
+	require.NoError(err)
+	require.Equal(myStruct, unmarshalled)
+
+	registrations := freezer.Registrations()
+	require.Len(registrations, 3)
+	require.Equal("*codec.MyInnerStruct", registrations[0].Type)
+	require.NotEmpty(registrations[0].ID)
+	require.Empty(registrations[0].Error)
+	require.Contains(registrations[0].Caller, "test_codec.go")
+	require.Empty(registrations[1].ID)
+	require.NotEmpty(registrations[1].Error)
+	require.Equal("*codec.MyInnerStruct2", registrations[2].Type)
+	require.Empty(registrations[2].ID)
+	require.NotEmpty(registrations[2].Error)
+}
+
+type myOmitEmptyStruct struct {
+	Amount uint64          `serialize:"true"`
+	Memo   []byte          `serialize:"true,omitempty"`
+	Inner  *MyInnerStruct  `serialize:"true,omitempty"`
+	Counts map[uint8]uint8 `serialize:"true,omitempty"`
+}
+
+func TestOmitEmpty(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	tests := []struct {
+		value         myOmitEmptyStruct
+		expected      myOmitEmptyStruct
+		expectedBytes []byte
+	}{
+		{
+			value: myOmitEmptyStruct{
+				Amount: 5,
+				// Empty slices and maps are omitted.
+				Memo:   []byte{},
+				Counts: map[uint8]uint8{},
+			},
+			expected: myOmitEmptyStruct{
+				Amount: 5,
+			},
+			expectedBytes: []byte{
+				// codec version
+				0x00, 0x00,
+				// amount
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05,
+				// memo isn't present
+				0x00,
+				// inner isn't present
+				0x00,
+				// counts aren't present
+				0x00,
+			},
+		},
+		{
+			value: myOmitEmptyStruct{
+				Amount: 5,
+				Memo:   []byte{0xab},
+				Inner:  &MyInnerStruct{Str: "a"},
+				Counts: map[uint8]uint8{1: 2},
+			},
+			expected: myOmitEmptyStruct{
+				Amount: 5,
+				Memo:   []byte{0xab},
+				Inner:  &MyInnerStruct{Str: "a"},
+				Counts: map[uint8]uint8{1: 2},
+			},
+			expectedBytes: []byte{
+				// codec version
+				0x00, 0x00,
+				// amount
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05,
+				// memo is present
+				0x01,
+				// memo
+				0x00, 0x00, 0x00, 0x01, 0xab,
+				// inner is present
+				0x01,
+				// inner.str
+				0x00, 0x01, 'a',
+				// inner.numberNotProvided is nil
+				0x01,
+				// counts are present
+				0x01,
+				// counts
+				0x00, 0x00, 0x00, 0x01, 0x01, 0x02,
+			},
+		},
+	}
+	for _, test := range tests {
+		bytes, err := manager.Marshal(0, test.value)
+		require.NoError(err)
+		require.Equal(test.expectedBytes, bytes)
+
+		size, err := manager.Size(0, test.value)
+		require.NoError(err)
+		require.Len(bytes, size)
+
+		var unmarshalled myOmitEmptyStruct
+		_, err = manager.Unmarshal(bytes, &unmarshalled)
+		require.NoError(err)
+		require.Equal(test.expected, unmarshalled)
+	}
+
+	// A present field must not be empty.
+	var unmarshalled myOmitEmptyStruct
+	_, err := manager.Unmarshal([]byte{
+		0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05,
+		0x01, 0x00, 0x00, 0x00, 0x00,
+		0x00,
+		0x00,
+	}, &unmarshalled)
+	require.ErrorIs(err, ErrNonCanonicalEncoding)
+}
+
+type mySparseStruct struct {
+	ID       ids.ID           `serialize:"true"`
+	Amount   uint64           `serialize:"true"`
+	Memo     []byte           `serialize:"true"`
+	Inner    MyInnerStruct    `serialize:"true"`
+	Optional *MyInnerStruct2  `serialize:"true,nullable"`
+	Outputs  []MyInnerStruct2 `serialize:"true"`
+	Ignored  string
+}
+
+func TestSparseStruct(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	tests := []struct {
+		value         mySparseStruct
+		expected      mySparseStruct
+		expectedBytes []byte
+	}{
+		{
+			value: mySparseStruct{},
+			expectedBytes: []byte{
+				// codec version
+				0x00, 0x00,
+				// presence bitmap
+				0x00,
+			},
+		},
+		{
+			value: mySparseStruct{
+				Amount: 5,
+				// Empty slices are omitted.
+				Memo: []byte{},
+				Outputs: []MyInnerStruct2{
+					{Bool: true},
+					{},
+				},
+				Ignored: "ignored",
+			},
+			expected: mySparseStruct{
+				Amount: 5,
+				Outputs: []MyInnerStruct2{
+					{Bool: true},
+					{},
+				},
+			},
+			expectedBytes: []byte{
+				// codec version
+				0x00, 0x00,
+				// presence bitmap
+				0x22,
+				// Amount
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05,
+				// length of Outputs
+				0x00, 0x00, 0x00, 0x02,
+				// Outputs[0] presence bitmap
+				0x01,
+				// Outputs[0].Bool
+				0x01,
+				// Outputs[1] presence bitmap
+				0x00,
+			},
+		},
+	}
+	for _, test := range tests {
+		bytes, err := manager.Marshal(0, test.value)
+		require.NoError(err)
+		require.Equal(test.expectedBytes, bytes)
+
+		bytesLen, err := manager.Size(0, test.value)
+		require.NoError(err)
+		require.Len(bytes, bytesLen)
+
+		var unmarshaled mySparseStruct
+		_, err = manager.Unmarshal(bytes, &unmarshaled)
+		require.NoError(err)
+		require.Equal(test.expected, unmarshaled)
+	}
+}
+
+func TestSparseNonCanonical(codec GeneralCodec, t testing.TB) {
+	require := require.New(t)
+
+	manager := NewDefaultManager()
+	require.NoError(manager.RegisterCodec(0, codec))
+
+	tests := map[string][]byte{
+		"unused bitmap bit set": {
+			// codec version
+			0x00, 0x00,
+			// presence bitmap
+			0x40,
+		},
+		"present zero field": {
+			// codec version
+			0x00, 0x00,
+			// presence bitmap
+			0x02,
+			// Amount
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		},
+	}
+	for _, bytes := range tests {
+		var unmarshaled mySparseStruct
+		_, err := manager.Unmarshal(bytes, &unmarshaled)
+		require.ErrorIs(err, ErrNonCanonicalEncoding)
+	}
+}
+
 func FuzzStructUnmarshal(codec GeneralCodec, f *testing.F) {
 	manager := NewDefaultManager()
 	// Register the types that may be unmarshaled into interfaces
@@ -52,6 +52,8 @@ import (
 	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
 	"github.com/ava-labs/avalanchego/vms/proposervm"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime/subprocess"
 )
 
 const (
@@ -1310,6 +1312,40 @@ func getPluginDir(v *viper.Viper) (string, error) {
 	return pluginDir, nil
 }
 
+func getPluginIntegrity(v *viper.Viper) (*subprocess.Integrity, error) {
+	var signingKey []byte
+	if v.IsSet(PluginSigningKeyPathKey) {
+		signingKeyPath := GetExpandedArg(v, PluginSigningKeyPathKey)
+		var err error
+		signingKey, err = os.ReadFile(filepath.Clean(signingKeyPath))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read %q: %w", PluginSigningKeyPathKey, err)
+		}
+	}
+
+	integrity, err := subprocess.NewIntegrity(v.GetStringMapString(PluginDigestsKey), signingKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin verification config: %w", err)
+	}
+	return integrity, nil
+}
+
+func getPluginGossipRelayConfig(v *viper.Viper) (rpcchainvm.GossipRelayConfig, error) {
+	policy, err := rpcchainvm.ParseGossipPolicy(v.GetString(PluginGossipPolicyKey))
+	if err != nil {
+		return rpcchainvm.GossipRelayConfig{}, fmt.Errorf("invalid %q: %w", PluginGossipPolicyKey, err)
+	}
+	config := rpcchainvm.GossipRelayConfig{
+		Policy:             policy,
+		MaxPendingMessages: v.GetInt(PluginGossipMaxPendingMessagesKey),
+		MaxPendingBytes:    v.GetInt(PluginGossipMaxPendingBytesKey),
+	}
+	if err := config.Verify(); err != nil {
+		return rpcchainvm.GossipRelayConfig{}, fmt.Errorf("invalid plugin gossip config: %w", err)
+	}
+	return config, nil
+}
+
 func GetNodeConfig(v *viper.Viper) (node.Config, error) {
 	var (
 		nodeConfig node.Config
@@ -1320,6 +1356,18 @@ func GetNodeConfig(v *viper.Viper) (node.Config, error) {
 	if err != nil {
 		return node.Config{}, err
 	}
+	nodeConfig.PluginSandboxMode, err = subprocess.ParseSandboxMode(v.GetString(PluginSandboxModeKey))
+	if err != nil {
+		return node.Config{}, fmt.Errorf("invalid %q: %w", PluginSandboxModeKey, err)
+	}
+	nodeConfig.PluginIntegrity, err = getPluginIntegrity(v)
+	if err != nil {
+		return node.Config{}, err
+	}
+	nodeConfig.PluginGossipRelay, err = getPluginGossipRelayConfig(v)
+	if err != nil {
+		return node.Config{}, err
+	}
 
 	nodeConfig.ConsensusShutdownTimeout = v.GetDuration(ConsensusShutdownTimeoutKey)
 	if nodeConfig.ConsensusShutdownTimeout < 0 {
@@ -23,6 +23,8 @@ import (
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/ulimit"
 	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime/subprocess"
 )
 
 const (
@@ -78,6 +80,12 @@ func addNodeFlags(fs *pflag.FlagSet) {
 
 	// Plugin directory
 	fs.String(PluginDirKey, defaultPluginDir, "Path to the plugin directory")
+	fs.String(PluginSandboxModeKey, "", fmt.Sprintf("Sandbox to run plugins in. One of {%q, %q}. If empty, plugins aren't sandboxed. Each plugin must declare the syscalls it makes in a manifest next to its binary with the extension %q", subprocess.SandboxSeccomp, subprocess.SandboxAppArmor, subprocess.ManifestExtension))
+	fs.StringToString(PluginDigestsKey, map[string]string{}, "Hex encoded SHA-256 digests of plugin binaries, by plugin file name. A plugin with a configured digest is only launched if its binary has that digest")
+	fs.String(PluginSigningKeyPathKey, "", fmt.Sprintf("Path to a PEM encoded ECDSA public key. If set, a plugin without a configured digest is only launched if the file next to its binary with the extension %q holds a signature of the binary by this key, as produced by cosign sign-blob. If neither this nor %s is set, plugins aren't verified", subprocess.SignatureExtension, PluginDigestsKey))
+	fs.String(PluginGossipPolicyKey, string(rpcchainvm.DefaultGossipRelayConfig.Policy), fmt.Sprintf("What happens to gossip that is received faster than a plugin handles it. One of {%q, %q, %q, %q}. Unless it's %q, gossip is sent to the plugin in the background and is shed once the maximum amount of gossip is waiting to be sent", rpcchainvm.GossipPolicyBlock, rpcchainvm.GossipPolicyDropNewest, rpcchainvm.GossipPolicyDropOldest, rpcchainvm.GossipPolicyCoalesce, rpcchainvm.GossipPolicyBlock))
+	fs.Int(PluginGossipMaxPendingMessagesKey, rpcchainvm.DefaultGossipRelayConfig.MaxPendingMessages, fmt.Sprintf("Maximum number of gossip messages waiting to be sent to a plugin. Ignored if %s is %q", PluginGossipPolicyKey, rpcchainvm.GossipPolicyBlock))
+	fs.Int(PluginGossipMaxPendingBytesKey, rpcchainvm.DefaultGossipRelayConfig.MaxPendingBytes, fmt.Sprintf("Maximum number of bytes of gossip waiting to be sent to a plugin. Ignored if %s is %q", PluginGossipPolicyKey, rpcchainvm.GossipPolicyBlock))
 
 	// Config File
 	fs.String(ConfigFileKey, "", fmt.Sprintf("Specifies a config file. Ignored if %s is specified", ConfigContentKey))
@@ -164,6 +164,12 @@ const (
 	HealthCheckFreqKey                                 = "health-check-frequency"
 	HealthCheckAveragerHalflifeKey                     = "health-check-averager-halflife"
 	PluginDirKey                                       = "plugin-dir"
+	PluginSandboxModeKey                               = "plugin-sandbox-mode"
+	PluginGossipPolicyKey                              = "plugin-gossip-policy"
+	PluginGossipMaxPendingMessagesKey                  = "plugin-gossip-max-pending-messages"
+	PluginGossipMaxPendingBytesKey                     = "plugin-gossip-max-pending-bytes"
+	PluginDigestsKey                                   = "plugin-digests"
+	PluginSigningKeyPathKey                            = "plugin-signing-key-file"
 	BootstrapBeaconConnectionTimeoutKey                = "bootstrap-beacon-connection-timeout"
 	BootstrapMaxTimeGetAncestorsKey                    = "bootstrap-max-time-get-ancestors"
 	BootstrapAncestorsMaxContainersSentKey             = "bootstrap-ancestors-max-containers-sent"
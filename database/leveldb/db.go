@@ -66,9 +66,11 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iter)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.OptionsReader = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.OptionsBatch  = (*batch)(nil)
+	_ database.Iterator      = (*iter)(nil)
 
 	ErrInvalidConfig = errors.New("invalid config")
 	ErrCouldNotOpen  = errors.New("could not open")
@@ -284,6 +286,15 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 	return value, updateError(err)
 }
 
+// GetWithOptions returns the value the key maps to in the database, reading it
+// according to [opts].
+func (db *Database) GetWithOptions(key []byte, opts database.ReadOptions) ([]byte, error) {
+	value, err := db.DB.Get(key, &opt.ReadOptions{
+		DontFillCache: !opts.FillCache,
+	})
+	return value, updateError(err)
+}
+
 // Put sets the value of the provided key to the provided value
 func (db *Database) Put(key []byte, value []byte) error {
 	return updateError(db.DB.Put(key, value, nil))
@@ -402,6 +413,13 @@ func (b *batch) Write() error {
 	return updateError(b.db.DB.Write(&b.Batch, nil))
 }
 
+// WriteWithOptions flushes any accumulated data to disk according to [opts].
+func (b *batch) WriteWithOptions(opts database.WriteOptions) error {
+	return updateError(b.db.DB.Write(&b.Batch, &opt.WriteOptions{
+		Sync: opts.Sync,
+	}))
+}
+
 // Reset resets the batch for reuse.
 func (b *batch) Reset() {
 	b.Batch.Reset()
@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+// ReadOptions configure how a value is read.
+type ReadOptions struct {
+	// FillCache specifies whether the data read should be added to the
+	// database's caches. Bulk scans should disable it so that they don't evict
+	// frequently read data.
+	FillCache bool
+}
+
+// DefaultReadOptions are the options used by Get.
+var DefaultReadOptions = ReadOptions{
+	FillCache: true,
+}
+
+// WriteOptions configure how a batch is written.
+type WriteOptions struct {
+	// Sync specifies whether the write must be flushed to stable storage
+	// before it's reported as complete. Critical writes should enable it so
+	// that they survive a crash of the machine.
+	Sync bool
+}
+
+// OptionsReader is implemented by databases that support ReadOptions.
+type OptionsReader interface {
+	// GetWithOptions is the same as Get, but reads the value according to
+	// [opts].
+	GetWithOptions(key []byte, opts ReadOptions) ([]byte, error)
+}
+
+// OptionsBatch is implemented by batches that support WriteOptions.
+type OptionsBatch interface {
+	// WriteWithOptions is the same as Write, but writes the batch according
+	// to [opts].
+	WriteWithOptions(opts WriteOptions) error
+}
+
+// GetWithOptions reads [key] from [db] according to [opts] if [db] supports
+// ReadOptions. Otherwise, [opts] are ignored.
+func GetWithOptions(db KeyValueReader, key []byte, opts ReadOptions) ([]byte, error) {
+	if db, ok := db.(OptionsReader); ok {
+		return db.GetWithOptions(key, opts)
+	}
+	return db.Get(key)
+}
+
+// WriteWithOptions writes [batch] according to [opts] if [batch] supports
+// WriteOptions. Otherwise, [opts] are ignored.
+func WriteWithOptions(batch Batch, opts WriteOptions) error {
+	if batch, ok := batch.(OptionsBatch); ok {
+		return batch.WriteWithOptions(opts)
+	}
+	return batch.Write()
+}
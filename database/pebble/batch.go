@@ -11,7 +11,10 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 )
 
-var _ database.Batch = (*batch)(nil)
+var (
+	_ database.Batch        = (*batch)(nil)
+	_ database.OptionsBatch = (*batch)(nil)
+)
 
 // Not safe for concurrent use.
 type batch struct {
@@ -47,6 +50,19 @@ func (b *batch) Size() int {
 
 // Assumes [b.db.lock] is not held.
 func (b *batch) Write() error {
+	return b.write(pebble.Sync)
+}
+
+// Assumes [b.db.lock] is not held.
+func (b *batch) WriteWithOptions(opts database.WriteOptions) error {
+	if opts.Sync {
+		return b.write(pebble.Sync)
+	}
+	return b.write(pebble.NoSync)
+}
+
+// Assumes [b.db.lock] is not held.
+func (b *batch) write(writeOpts *pebble.WriteOptions) error {
 	b.db.lock.RLock()
 	defer b.db.lock.RUnlock()
 
@@ -58,7 +74,7 @@ func (b *batch) Write() error {
 
 	if !b.written {
 		// This batch has not been written to the database yet.
-		if err := updateError(b.batch.Commit(pebble.Sync)); err != nil {
+		if err := updateError(b.batch.Commit(writeOpts)); err != nil {
 			return err
 		}
 		b.written = true
@@ -75,7 +91,7 @@ func (b *batch) Write() error {
 	}
 
 	// Commit the new batch.
-	return updateError(batchClone.Commit(pebble.Sync))
+	return updateError(batchClone.Commit(writeOpts))
 }
 
 func (b *batch) Reset() {
@@ -18,9 +18,11 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.OptionsReader = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.OptionsBatch  = (*batch)(nil)
+	_ database.Iterator      = (*iterator)(nil)
 )
 
 // Database partitions a database into a sub-database by prefixing all keys with
@@ -96,6 +98,22 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 	return val, err
 }
 
+// Assumes that it is OK for the argument to db.db.GetWithOptions
+// to be modified after db.db.GetWithOptions returns.
+// [key] may be modified after this method returns.
+func (db *Database) GetWithOptions(key []byte, opts database.ReadOptions) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	prefixedKey := db.prefix(key)
+	val, err := database.GetWithOptions(db.db, prefixedKey, opts)
+	db.bufferPool.Put(prefixedKey)
+	return val, err
+}
+
 // Assumes that it is OK for the argument to db.db.Put
 // to be modified after db.db.Put returns.
 // [key] can be modified after this method returns.
@@ -277,6 +295,18 @@ func (b *batch) Write() error {
 	return b.Batch.Write()
 }
 
+// WriteWithOptions flushes any accumulated data to the underlying database
+// according to [opts].
+func (b *batch) WriteWithOptions(opts database.WriteOptions) error {
+	b.db.lock.RLock()
+	defer b.db.lock.RUnlock()
+
+	if b.db.closed {
+		return database.ErrClosed
+	}
+	return database.WriteWithOptions(b.Batch, opts)
+}
+
 // Reset resets the batch for reuse.
 func (b *batch) Reset() {
 	// Return the byte buffers underneath each key back to the pool.
@@ -32,6 +32,7 @@ var Tests = []func(t *testing.T, db Database){
 	TestSimpleKeyValueClosed,
 	TestNewBatchClosed,
 	TestBatchPut,
+	TestBatchWriteWithOptions,
 	TestBatchDelete,
 	TestBatchReset,
 	TestBatchReuse,
@@ -253,6 +254,40 @@ func TestNewBatchClosed(t *testing.T, db Database) {
 	require.Equal(ErrClosed, batch.Write())
 }
 
+// TestBatchWriteWithOptions tests to make sure that batches written, and
+// values read, with options behave the same as without options.
+func TestBatchWriteWithOptions(t *testing.T, db Database) {
+	require := require.New(t)
+
+	key := []byte("hello")
+	value := []byte("world")
+
+	_, err := GetWithOptions(db, key, ReadOptions{})
+	require.Equal(ErrNotFound, err)
+
+	for _, opts := range []WriteOptions{{Sync: false}, {Sync: true}} {
+		batch := db.NewBatch()
+		require.NoError(batch.Put(key, value))
+		require.NoError(WriteWithOptions(batch, opts))
+
+		for _, readOpts := range []ReadOptions{{FillCache: false}, DefaultReadOptions} {
+			v, err := GetWithOptions(db, key, readOpts)
+			require.NoError(err)
+			require.Equal(value, v)
+		}
+
+		require.NoError(db.Delete(key))
+	}
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put(key, value))
+	require.NoError(db.Close())
+	require.Equal(ErrClosed, WriteWithOptions(batch, WriteOptions{Sync: true}))
+
+	_, err = GetWithOptions(db, key, DefaultReadOptions)
+	require.Equal(ErrClosed, err)
+}
+
 // TestBatchPut tests to make sure that batched writes work as expected.
 func TestBatchPut(t *testing.T, db Database) {
 	require := require.New(t)
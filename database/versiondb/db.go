@@ -16,10 +16,11 @@ import (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ Commitable        = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database      = (*Database)(nil)
+	_ database.OptionsReader = (*Database)(nil)
+	_ Commitable             = (*Database)(nil)
+	_ database.Batch         = (*batch)(nil)
+	_ database.Iterator      = (*iterator)(nil)
 )
 
 // Commitable defines the interface that specifies that something may be
@@ -82,6 +83,24 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 	return db.db.Get(key)
 }
 
+// GetWithOptions is the same as Get, but reads from the underlying database
+// according to [opts].
+func (db *Database) GetWithOptions(key []byte, opts database.ReadOptions) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.mem == nil {
+		return nil, database.ErrClosed
+	}
+	if val, has := db.mem[string(key)]; has {
+		if val.delete {
+			return nil, database.ErrNotFound
+		}
+		return slices.Clone(val.value), nil
+	}
+	return database.GetWithOptions(db.db, key, opts)
+}
+
 func (db *Database) Put(key, value []byte) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
@@ -186,6 +205,20 @@ func (db *Database) GetDatabase() database.Database {
 
 // Commit writes all the operations of this database to the underlying database
 func (db *Database) Commit() error {
+	return db.commit(database.Batch.Write)
+}
+
+// CommitWithOptions writes all the operations of this database to the
+// underlying database according to [opts]
+func (db *Database) CommitWithOptions(opts database.WriteOptions) error {
+	return db.commit(func(batch database.Batch) error {
+		return database.WriteWithOptions(batch, opts)
+	})
+}
+
+// commit writes all the operations of this database to the underlying
+// database with [write].
+func (db *Database) commit(write func(database.Batch) error) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
@@ -193,7 +226,7 @@ func (db *Database) Commit() error {
 	if err != nil {
 		return err
 	}
-	if err := batch.Write(); err != nil {
+	if err := write(batch); err != nil {
 		return err
 	}
 	batch.Reset()
@@ -157,6 +157,30 @@ func TestCommit(t *testing.T) {
 	require.Equal(value1, value)
 }
 
+func TestCommitWithOptions(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := New(baseDB)
+
+	key1 := []byte("hello1")
+	value1 := []byte("world1")
+
+	require.NoError(db.Put(key1, value1))
+
+	require.NoError(db.CommitWithOptions(database.WriteOptions{Sync: true}))
+
+	value, err := db.GetWithOptions(key1, database.ReadOptions{})
+	require.NoError(err)
+	require.Equal(value1, value)
+	value, err = baseDB.Get(key1)
+	require.NoError(err)
+	require.Equal(value1, value)
+
+	require.NoError(db.Close())
+	require.Equal(database.ErrClosed, db.CommitWithOptions(database.WriteOptions{}))
+}
+
 func TestCommitClosed(t *testing.T) {
 	require := require.New(t)
 
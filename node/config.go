@@ -25,6 +25,8 @@ import (
 	"github.com/ava-labs/avalanchego/utils/profiler"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/timer"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime/subprocess"
 )
 
 type IPCConfig struct {
@@ -169,7 +171,10 @@ type Config struct {
 
 	LoggingConfig logging.Config `json:"loggingConfig"`
 
-	PluginDir string `json:"pluginDir"`
+	PluginDir         string                       `json:"pluginDir"`
+	PluginSandboxMode subprocess.SandboxMode       `json:"pluginSandboxMode"`
+	PluginIntegrity   *subprocess.Integrity        `json:"pluginIntegrity"`
+	PluginGossipRelay rpcchainvm.GossipRelayConfig `json:"pluginGossipRelay"`
 
 	// File Descriptor Limit
 	FdLimit uint64 `json:"fdLimit"`
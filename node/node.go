@@ -60,6 +60,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/uptime"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/staking"
+	"github.com/ava-labs/avalanchego/subnets"
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
@@ -83,6 +84,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/propertyfx"
 	"github.com/ava-labs/avalanchego/vms/registry"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime/subprocess"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 
 	ipcsapi "github.com/ava-labs/avalanchego/api/ipcs"
@@ -1046,6 +1048,13 @@ func (n *Node) initVMs() error {
 		vdrs = validators.NewManager()
 	}
 
+	subnetChurnLimits := make(map[ids.ID]subnets.ChurnLimit)
+	for subnetID, subnetConfig := range n.Config.SubnetConfigs {
+		if subnetID != constants.PrimaryNetworkID && subnetConfig.ChurnLimit.Enabled() {
+			subnetChurnLimits[subnetID] = subnetConfig.ChurnLimit
+		}
+	}
+
 	vmRegisterer := registry.NewVMRegisterer(registry.VMRegistererConfig{
 		APIServer:    n.APIServer,
 		Log:          n.Log,
@@ -1063,6 +1072,7 @@ func (n *Node) initVMs() error {
 				SybilProtectionEnabled:        n.Config.SybilProtectionEnabled,
 				PartialSyncPrimaryNetwork:     n.Config.PartialSyncPrimaryNetwork,
 				TrackedSubnets:                n.Config.TrackedSubnets,
+				SubnetChurnLimits:             subnetChurnLimits,
 				TxFee:                         n.Config.TxFee,
 				CreateAssetTxFee:              n.Config.CreateAssetTxFee,
 				CreateSubnetTxFee:             n.Config.CreateSubnetTxFee,
@@ -1106,6 +1116,25 @@ func (n *Node) initVMs() error {
 	// initialize vm runtime manager
 	n.runtimeManager = runtime.NewManager()
 
+	var pluginSandbox *subprocess.Sandbox
+	if n.Config.PluginSandboxMode != subprocess.SandboxNone {
+		pluginSandbox = &subprocess.Sandbox{
+			Mode:    n.Config.PluginSandboxMode,
+			Monitor: subprocess.NewSandboxMonitor(),
+		}
+		if err := n.health.RegisterHealthCheck("pluginSandbox", pluginSandbox.Monitor, health.ApplicationTag); err != nil {
+			return fmt.Errorf("couldn't register plugin sandbox health check: %w", err)
+		}
+	}
+
+	pluginIntegrity := n.Config.PluginIntegrity
+	if pluginIntegrity != nil {
+		pluginIntegrity.Monitor = subprocess.NewIntegrityMonitor()
+		if err := n.health.RegisterHealthCheck("pluginIntegrity", pluginIntegrity.Monitor, health.ApplicationTag); err != nil {
+			return fmt.Errorf("couldn't register plugin integrity health check: %w", err)
+		}
+	}
+
 	// initialize the vm registry
 	n.VMRegistry = registry.NewVMRegistry(registry.VMRegistryConfig{
 		VMGetter: registry.NewVMGetter(registry.VMGetterConfig{
@@ -1114,6 +1143,9 @@ func (n *Node) initVMs() error {
 			PluginDirectory: n.Config.PluginDir,
 			CPUTracker:      n.resourceManager,
 			RuntimeTracker:  n.runtimeManager,
+			Sandbox:         pluginSandbox,
+			Integrity:       pluginIntegrity,
+			GossipRelay:     n.Config.PluginGossipRelay,
 		}),
 		VMRegisterer: vmRegisterer,
 	})
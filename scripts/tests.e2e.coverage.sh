@@ -0,0 +1,40 @@
+#!/usr/bin/env bash
+
+set -euo pipefail
+
+# Records the files of avalanchego exercised by each e2e spec so that
+# `./scripts/tests.e2e.sh --changed-files=...` can run only the specs
+# affected by a change.
+#
+# e.g.,
+# ./scripts/tests.e2e.coverage.sh
+# E2E_COVERAGE_MAP=/path/to/coverage.json ./scripts/tests.e2e.coverage.sh
+if ! [[ "$0" =~ scripts/tests.e2e.coverage.sh ]]; then
+  echo "must be run from repository root"
+  exit 255
+fi
+
+source ./scripts/constants.sh
+
+E2E_COVERAGE_MAP="${E2E_COVERAGE_MAP:-./tests/e2e/coverage.json}"
+COVER_AVALANCHEGO_PATH="./build/avalanchego-cover"
+
+#################################
+echo "building avalanchego with coverage instrumentation"
+go build -cover -covermode=atomic -coverpkg=./... \
+  -ldflags "-X github.com/ava-labs/avalanchego/version.GitCommit=$git_commit $static_ld_flags" \
+  -o "${COVER_AVALANCHEGO_PATH}" ./main/
+
+#################################
+echo "building e2e.test"
+go install -v github.com/onsi/ginkgo/v2/ginkgo@v2.1.4
+ACK_GINKGO_RC=true ginkgo build ./tests/e2e
+
+#################################
+echo "recording coverage of e2e specs to ${E2E_COVERAGE_MAP}"
+# Specs are run serially, each against its own network, so that the
+# coverage of a spec isn't attributed to any other.
+go run ./tests/fixture/e2e/cmd record \
+  --e2e-test-path="$(realpath ./tests/e2e/e2e.test)" \
+  --avalanchego-path="$(realpath "${COVER_AVALANCHEGO_PATH}")" \
+  --coverage-map="${E2E_COVERAGE_MAP}"
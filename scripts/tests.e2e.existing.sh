@@ -15,6 +15,7 @@ set -euo pipefail
 # ./scripts/tests.e2e.existing.sh --ginkgo.label-filter=x               # All arguments are supplied to ginkgo
 # E2E_SERIAL=1 ./scripts/tests.e2e.sh                                   # Run tests serially
 # AVALANCHEGO_PATH=./build/avalanchego ./scripts/tests.e2e.existing.sh  # Customization of avalanchego path
+# TMPNET_RUN_ID=shard-1 ./scripts/tests.e2e.existing.sh                # Isolation from concurrent runs
 if ! [[ "$0" =~ scripts/tests.e2e.existing.sh ]]; then
   echo "must be run from repository root"
   exit 255
@@ -44,8 +45,10 @@ trap cleanup EXIT
 print_separator
 ./build/tmpnetctl start-network
 
-# Determine the network configuration path from the latest symlink
-LATEST_SYMLINK_PATH="${HOME}/.tmpnet/networks/latest"
+# Determine the network configuration path from the latest symlink. If
+# TMPNET_RUN_ID is set, the network will have been started in a directory
+# named for the run to avoid clobbering the networks of concurrent runs.
+LATEST_SYMLINK_PATH="${HOME}/.tmpnet/networks${TMPNET_RUN_ID:+/${TMPNET_RUN_ID}}/latest"
 if [[ -h "${LATEST_SYMLINK_PATH}" ]]; then
   export TMPNET_NETWORK_DIR="$(realpath ${LATEST_SYMLINK_PATH})"
 else
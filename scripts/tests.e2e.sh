@@ -8,6 +8,7 @@ set -euo pipefail
 # E2E_SERIAL=1 ./scripts/tests.e2e.sh                                                  # Run tests serially
 # AVALANCHEGO_PATH=./build/avalanchego ./scripts/tests.e2e.sh                          # Customization of avalanchego path
 # E2E_USE_EXISTING_NETWORK=1 TMPNET_NETWORK_DIR=/path/to ./scripts/tests.e2e.sh        # Execute against an existing network
+# TMPNET_RUN_ID=shard-1 ./scripts/tests.e2e.sh                                        # Store networks of this run under a dir named for the run
 if ! [[ "$0" =~ scripts/tests.e2e.sh ]]; then
   echo "must be run from repository root"
   exit 255
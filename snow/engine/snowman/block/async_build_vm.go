@@ -0,0 +1,92 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package block
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+)
+
+// AsyncBuildBlockChainVM defines the interface a ChainVM can optionally
+// implement to build blocks without blocking the caller until the block is
+// built.
+type AsyncBuildBlockChainVM interface {
+	// StartBuildBlock starts building a new block on top of the currently
+	// preferred block and returns without waiting for the block to be built.
+	//
+	// If [blockCtx] is nil, the block is built as if by [BuildBlock].
+	// Otherwise, it's built as if by [BuildBlockWithContext].
+	//
+	// If the preferred block changes before the block is built, the build is
+	// cancelled, as the block would no longer be built on top of the
+	// preferred block.
+	StartBuildBlock(ctx context.Context, blockCtx *Context) BlockBuild
+}
+
+// BlockBuild is a block that is being built.
+type BlockBuild interface {
+	// Done returns a channel that is closed once the build has finished,
+	// either because the block was built, building failed, or the build was
+	// cancelled.
+	Done() <-chan struct{}
+
+	// Result waits for the build to finish and returns the built block.
+	//
+	// If the build was cancelled, the returned error wraps
+	// [context.Canceled].
+	//
+	// Result must be called under the same conditions as [BuildBlock].
+	Result(ctx context.Context) (snowman.Block, error)
+
+	// Cancel stops the build, allowing the VM to stop building the block.
+	// Cancelling a build that has finished has no effect.
+	Cancel()
+}
+
+// StartBuildBlock starts building a new block with [vm]. If [vm] doesn't
+// implement [AsyncBuildBlockChainVM], the block is built before
+// StartBuildBlock returns.
+func StartBuildBlock(ctx context.Context, vm ChainVM, blockCtx *Context) BlockBuild {
+	if vm, ok := vm.(AsyncBuildBlockChainVM); ok {
+		return vm.StartBuildBlock(ctx, blockCtx)
+	}
+	return BuildBlockNow(ctx, vm, blockCtx)
+}
+
+// BuildBlockNow builds a new block with [vm] and returns the finished build.
+//
+// If [blockCtx] is nil or [vm] doesn't implement
+// [BuildBlockWithContextChainVM], the block is built by [BuildBlock].
+// Otherwise, it's built by [BuildBlockWithContext].
+func BuildBlockNow(ctx context.Context, vm ChainVM, blockCtx *Context) BlockBuild {
+	build := &builtBlock{
+		done: make(chan struct{}),
+	}
+	defer close(build.done)
+
+	if buildVM, ok := vm.(BuildBlockWithContextChainVM); ok && blockCtx != nil {
+		build.blk, build.err = buildVM.BuildBlockWithContext(ctx, blockCtx)
+	} else {
+		build.blk, build.err = vm.BuildBlock(ctx)
+	}
+	return build
+}
+
+// builtBlock is a build that finished before it was returned.
+type builtBlock struct {
+	done chan struct{}
+	blk  snowman.Block
+	err  error
+}
+
+func (b *builtBlock) Done() <-chan struct{} {
+	return b.done
+}
+
+func (b *builtBlock) Result(context.Context) (snowman.Block, error) {
+	return b.blk, b.err
+}
+
+func (*builtBlock) Cancel() {}
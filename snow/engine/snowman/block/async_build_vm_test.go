@@ -0,0 +1,64 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package block
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+)
+
+type testAsyncBuildVM struct {
+	*TestVM
+
+	build BlockBuild
+}
+
+func (vm *testAsyncBuildVM) StartBuildBlock(context.Context, *Context) BlockBuild {
+	return vm.build
+}
+
+func TestStartBuildBlock(t *testing.T) {
+	require := require.New(t)
+
+	vm := &TestVM{}
+	blk := &snowman.TestBlock{}
+	vm.BuildBlockF = func(context.Context) (snowman.Block, error) {
+		return blk, nil
+	}
+
+	// VMs that don't build blocks asynchronously build the block before the
+	// build is returned.
+	build := StartBuildBlock(context.Background(), vm, &Context{})
+	select {
+	case <-build.Done():
+	default:
+		require.FailNow("build didn't finish")
+	}
+	builtBlk, err := build.Result(context.Background())
+	require.NoError(err)
+	require.Equal(blk, builtBlk)
+
+	// Otherwise, the VM starts the build.
+	asyncVM := &testAsyncBuildVM{
+		TestVM: &TestVM{},
+		build:  build,
+	}
+	require.Equal(build, StartBuildBlock(context.Background(), asyncVM, nil))
+}
+
+func TestBuildBlockNowReturnsError(t *testing.T) {
+	require := require.New(t)
+
+	vm := &TestVM{}
+	vm.BuildBlockF = func(context.Context) (snowman.Block, error) {
+		return nil, errTest
+	}
+
+	_, err := BuildBlockNow(context.Background(), vm, nil).Result(context.Background())
+	require.ErrorIs(err, errTest)
+}
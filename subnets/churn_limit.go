@@ -0,0 +1,60 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnets
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errNegativeChurnEpochDuration = errors.New("churn limit epochDuration must be >= 0")
+	errZeroChurnMaxChanges        = errors.New("churn limit maxChanges must be > 0 when epochDuration is set")
+)
+
+// ChurnLimit limits how quickly the validator set of a permissioned subnet
+// can change.
+//
+// Time is divided into epochs of [EpochDuration], aligned to the Unix epoch.
+// Adding a validator to the validator set at its start time and removing it at
+// its end time are each a change in the epoch they occur in.
+type ChurnLimit struct {
+	// EpochDuration is the length of each epoch.
+	//
+	// If 0 is specified, the validator set isn't limited.
+	EpochDuration time.Duration `json:"epochDuration" yaml:"epochDuration"`
+	// MaxChanges is the maximum number of changes to the validator set in a
+	// single epoch.
+	MaxChanges uint64 `json:"maxChanges" yaml:"maxChanges"`
+}
+
+// Enabled returns true iff the validator set is limited.
+func (c ChurnLimit) Enabled() bool {
+	return c.EpochDuration != 0
+}
+
+// Epoch returns the epoch that [t] is in.
+//
+// Assumes the validator set is limited.
+func (c ChurnLimit) Epoch(t time.Time) uint64 {
+	return uint64(t.UnixNano() / int64(c.EpochDuration))
+}
+
+// EpochStartTime returns the first time in [epoch].
+//
+// Assumes the validator set is limited.
+func (c ChurnLimit) EpochStartTime(epoch uint64) time.Time {
+	return time.Unix(0, int64(epoch)*int64(c.EpochDuration)).UTC()
+}
+
+func (c ChurnLimit) Valid() error {
+	switch {
+	case c.EpochDuration < 0:
+		return errNegativeChurnEpochDuration
+	case c.EpochDuration > 0 && c.MaxChanges == 0:
+		return errZeroChurnMaxChanges
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subnets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChurnLimitEpoch(t *testing.T) {
+	require := require.New(t)
+
+	limit := ChurnLimit{
+		EpochDuration: time.Hour,
+		MaxChanges:    1,
+	}
+	require.True(limit.Enabled())
+	require.False(ChurnLimit{}.Enabled())
+
+	epochStart := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	epoch := limit.Epoch(epochStart)
+	require.Equal(epochStart, limit.EpochStartTime(epoch))
+	require.Equal(epoch, limit.Epoch(epochStart.Add(time.Hour-1)))
+	require.Equal(epoch+1, limit.Epoch(epochStart.Add(time.Hour)))
+	require.Equal(epoch-1, limit.Epoch(epochStart.Add(-1)))
+}
@@ -63,6 +63,12 @@ type Config struct {
 	// TODO: Move this flag once the proposervm is configurable on a per-chain
 	// basis.
 	ProposerNumHistoricalBlocks uint64 `json:"proposerNumHistoricalBlocks" yaml:"proposerNumHistoricalBlocks"`
+
+	// ChurnLimit limits how quickly the validator set of this permissioned
+	// Subnet can change. It's a local policy: this node won't add a
+	// validator transaction that would exceed it to its mempool, but accepts
+	// blocks built by other nodes regardless.
+	ChurnLimit ChurnLimit `json:"churnLimit" yaml:"churnLimit"`
 }
 
 func (c *Config) Valid() error {
@@ -72,5 +78,5 @@ func (c *Config) Valid() error {
 	if !c.ValidatorOnly && c.AllowedNodes.Len() > 0 {
 		return errAllowedNodesWhenNotValidatorOnly
 	}
-	return nil
+	return c.ChurnLimit.Valid()
 }
@@ -5,6 +5,7 @@ package subnets
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -50,6 +51,27 @@ func TestValid(t *testing.T) {
 			},
 			expectedErr: errAllowedNodesWhenNotValidatorOnly,
 		},
+		{
+			name: "negative churn epoch duration",
+			s: Config{
+				ConsensusParameters: validParameters,
+				ChurnLimit: ChurnLimit{
+					EpochDuration: -time.Second,
+					MaxChanges:    1,
+				},
+			},
+			expectedErr: errNegativeChurnEpochDuration,
+		},
+		{
+			name: "zero churn max changes",
+			s: Config{
+				ConsensusParameters: validParameters,
+				ChurnLimit: ChurnLimit{
+					EpochDuration: time.Hour,
+				},
+			},
+			expectedErr: errZeroChurnMaxChanges,
+		},
 		{
 			name: "valid",
 			s: Config{
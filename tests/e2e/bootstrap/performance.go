@@ -0,0 +1,207 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package bootstrap
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+
+	"github.com/spf13/cast"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests"
+	"github.com/ava-labs/avalanchego/tests/fixture/e2e"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+const (
+	// Name of the file, in the network dir, that the results of the
+	// benchmark are written to. Since the network dir is uploaded as an
+	// artifact in CI, the results of every run are retained.
+	resultsFileName = "bootstrap_performance.json"
+
+	// Setting this env to a file path causes the results of the benchmark to
+	// be appended to that file as a single line of json. This supports
+	// tracking bootstrap performance across runs.
+	HistoryPathEnvName = "E2E_BOOTSTRAP_PERFORMANCE_HISTORY"
+
+	// Set by github actions to the commit being tested.
+	commitEnvName = "GITHUB_SHA"
+
+	// The maximum amount of time a fresh node is given to bootstrap at any
+	// one state size.
+	bootstrapTimeout = 10 * time.Minute
+
+	transferAmount = units.MilliAvax
+)
+
+// The number of txs issued on the X-Chain before each bootstrap measurement.
+// Txs are issued cumulatively, so each measurement is performed against a
+// larger state than the previous one.
+var txCounts = []int{0, 100, 1000}
+
+type bootstrapResult struct {
+	// Number of txs that had been issued when the measurement was taken
+	NumTxs int `json:"numTxs"`
+	// Size of the database of an existing node when the measurement was taken
+	DBSize int64 `json:"dbSize"`
+	// Time taken by a fresh node to report healthy
+	Duration time.Duration `json:"duration"`
+}
+
+type bootstrapResults struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Commit    string            `json:"commit,omitempty"`
+	Results   []bootstrapResult `json:"results"`
+}
+
+var _ = ginkgo.Describe("[Bootstrap Performance]", ginkgo.Label(e2e.BootstrapPerformanceLabel), ginkgo.Serial, func() {
+	require := require.New(ginkgo.GinkgoT())
+
+	ginkgo.It("should measure the time taken by a fresh node to bootstrap at increasing state sizes", func() {
+		// The benchmark is too slow to run by default and must be selected
+		// explicitly by its label.
+		suiteConfig, _ := ginkgo.GinkgoConfiguration()
+		if !strings.Contains(suiteConfig.LabelFilter, e2e.BootstrapPerformanceLabel) {
+			ginkgo.Skip("bootstrap performance is only measured when selected with --ginkgo.label-filter=" + e2e.BootstrapPerformanceLabel)
+		}
+
+		ginkgo.By("creating a new private network to isolate the measurements from other tests")
+		network := e2e.Env.NewPrivateNetwork()
+
+		existingNode := network.GetNodes()[0]
+		nodeURI := tmpnet.NodeURI{
+			NodeID: existingNode.GetID(),
+			URI:    existingNode.GetProcessContext().URI,
+		}
+		keychain := secp256k1fx.NewKeychain(network.GetConfig().FundedKeys[0])
+		xWallet := e2e.NewWallet(keychain, nodeURI).X()
+		avaxAssetID := xWallet.AVAXAssetID()
+		outputs := []*avax.TransferableOutput{{
+			Asset: avax.Asset{
+				ID: avaxAssetID,
+			},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: transferAmount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs: []ids.ShortID{
+						keychain.Keys[0].Address(),
+					},
+				},
+			},
+		}}
+
+		results := bootstrapResults{
+			Timestamp: time.Now().UTC(),
+			Commit:    os.Getenv(commitEnvName),
+		}
+		numTxs := 0
+		for _, txCount := range txCounts {
+			ginkgo.By("growing the state to " + strconv.Itoa(txCount) + " txs")
+			for ; numTxs < txCount; numTxs++ {
+				_, err := xWallet.IssueBaseTx(outputs, e2e.WithDefaultContext())
+				require.NoError(err)
+			}
+
+			dbSize, err := dirSize(filepath.Join(
+				cast.ToString(existingNode.GetConfig().Flags[config.DataDirKey]),
+				"db",
+			))
+			require.NoError(err)
+
+			ginkgo.By("measuring the time taken by a fresh node to bootstrap")
+			result := bootstrapResult{
+				NumTxs:   numTxs,
+				DBSize:   dbSize,
+				Duration: measureBootstrap(network),
+			}
+			tests.Outf("{{blue}}bootstrapped with %d txs and a %d byte db in %s{{/}}\n", result.NumTxs, result.DBSize, result.Duration)
+			ginkgo.AddReportEntry("bootstrap", result)
+			results.Results = append(results.Results, result)
+		}
+
+		ginkgo.By("writing the results")
+		resultsBytes, err := json.MarshalIndent(results, "", "  ")
+		require.NoError(err)
+		resultsPath := filepath.Join(e2e.Env.NetworkDir, resultsFileName)
+		require.NoError(os.WriteFile(resultsPath, resultsBytes, perms.ReadWrite))
+		tests.Outf("{{green}}wrote bootstrap performance results to %s{{/}}\n", resultsPath)
+
+		historyPath := os.Getenv(HistoryPathEnvName)
+		if len(historyPath) == 0 {
+			return
+		}
+		require.NoError(appendHistory(historyPath, results))
+		tests.Outf("{{green}}appended bootstrap performance results to %s{{/}}\n", historyPath)
+	})
+})
+
+// Returns the time taken by a new node to report healthy after being added to
+// [network]. The node is stopped before returning.
+func measureBootstrap(network tmpnet.Network) time.Duration {
+	require := require.New(ginkgo.GinkgoT())
+
+	start := time.Now()
+	node, err := network.AddEphemeralNode(ginkgo.GinkgoWriter, tmpnet.FlagsMap{})
+	require.NoError(err)
+	defer func() {
+		tests.Outf("Shutting down ephemeral node %s\n", node.GetID())
+		require.NoError(node.Stop())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), bootstrapTimeout)
+	defer cancel()
+	require.NoError(tmpnet.WaitForHealthy(ctx, node))
+	return time.Since(start)
+}
+
+// Appends [results] to the file at [path] as a single line of json.
+func appendHistory(path string, results bootstrapResults) error {
+	resultsBytes, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perms.ReadWrite)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(resultsBytes, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Returns the total size of the files under [dir].
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
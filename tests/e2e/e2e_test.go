@@ -6,6 +6,8 @@ package e2e_test
 import (
 	"testing"
 
+	_ "embed"
+
 	ginkgo "github.com/onsi/ginkgo/v2"
 
 	"github.com/onsi/gomega"
@@ -14,21 +16,40 @@ import (
 
 	// ensure test packages are scanned by ginkgo
 	_ "github.com/ava-labs/avalanchego/tests/e2e/banff"
+	_ "github.com/ava-labs/avalanchego/tests/e2e/bootstrap"
 	_ "github.com/ava-labs/avalanchego/tests/e2e/c"
 	_ "github.com/ava-labs/avalanchego/tests/e2e/faultinjection"
 	_ "github.com/ava-labs/avalanchego/tests/e2e/p"
+	_ "github.com/ava-labs/avalanchego/tests/e2e/repro"
 	_ "github.com/ava-labs/avalanchego/tests/e2e/static-handlers"
 	_ "github.com/ava-labs/avalanchego/tests/e2e/x"
 	_ "github.com/ava-labs/avalanchego/tests/e2e/x/transfer"
 )
 
 func TestE2E(t *testing.T) {
+	suiteConfig, reporterConfig := ginkgo.GinkgoConfiguration()
+	affected, err := flagVars.FocusAffectedSpecs(&suiteConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !affected {
+		t.Skip("no spec is affected by the changed files")
+	}
+
 	gomega.RegisterFailHandler(ginkgo.Fail)
-	ginkgo.RunSpecs(t, "e2e test suites")
+	ginkgo.RunSpecs(t, "e2e test suites", suiteConfig, reporterConfig)
 }
 
 var flagVars *e2e.FlagVars
 
+// Expectations of the metrics of the shared network that hold regardless of
+// which specs are run.
+//
+//go:embed metrics_baseline.json
+var metricsBaseline []byte
+
+var _ = e2e.DeclareMetricsBaseline("suite", metricsBaseline)
+
 func init() {
 	flagVars = e2e.RegisterFlags()
 }
@@ -42,3 +63,21 @@ var _ = ginkgo.SynchronizedBeforeSuite(func() []byte {
 	// Initialize the local test environment from the global state
 	e2e.InitSharedTestEnvironment(envBytes)
 })
+
+var _ = ginkgo.SynchronizedAfterSuite(func() {
+	// Run in every ginkgo process
+}, func() {
+	// Run only once in the first ginkgo process, after every process has
+	// finished running specs
+
+	// The environment isn't initialized if the suite failed to start.
+	if e2e.Env == nil {
+		return
+	}
+	e2e.Env.CheckMetricsBaselines()
+})
+
+var _ = ginkgo.AfterEach(func() {
+	e2e.Env.WriteTopologyIfFailed()
+	e2e.Env.WriteReproIfFailed()
+})
@@ -0,0 +1,287 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package p
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+
+	"github.com/spf13/cast"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/api/admin"
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests"
+	"github.com/ava-labs/avalanchego/tests/fixture/e2e"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// Scenarios with a validation period longer than this are only checked
+// against the reward calculator since waiting for them to end isn't
+// practical.
+const maxLiveRewardScenarioDuration = time.Minute
+
+// rewardScenario is a combination of staking parameters whose rewards are
+// checked by the reward matrix.
+type rewardScenario struct {
+	name             string
+	validationPeriod time.Duration
+	delegationPeriod time.Duration
+	delegationShare  uint32
+}
+
+// newRewardScenarios returns the combinations of edge case staking durations
+// and delegation shares supported by a network with the provided limits.
+func newRewardScenarios(minStakeDuration, maxStakeDuration time.Duration) []rewardScenario {
+	durations := []struct {
+		name             string
+		validationPeriod time.Duration
+		delegationPeriod time.Duration
+	}{
+		{
+			name:             "min duration",
+			validationPeriod: minStakeDuration,
+			delegationPeriod: minStakeDuration,
+		},
+		{
+			name:             "max duration",
+			validationPeriod: maxStakeDuration,
+			delegationPeriod: maxStakeDuration / 2,
+		},
+		{
+			name:             "delegation ending at validation end",
+			validationPeriod: validationPeriod,
+			delegationPeriod: validationPeriod,
+		},
+	}
+	shares := []struct {
+		name  string
+		share uint32
+	}{
+		{
+			name:  "zero fee",
+			share: 0,
+		},
+		{
+			name:  "max fee",
+			share: reward.PercentDenominator,
+		},
+	}
+
+	scenarios := make([]rewardScenario, 0, len(durations)*len(shares))
+	for _, duration := range durations {
+		for _, share := range shares {
+			scenarios = append(scenarios, rewardScenario{
+				name:             fmt.Sprintf("%s, %s", duration.name, share.name),
+				validationPeriod: duration.validationPeriod,
+				delegationPeriod: duration.delegationPeriod,
+				delegationShare:  share.share,
+			})
+		}
+	}
+	return scenarios
+}
+
+var _ = ginkgo.Describe("[Staking Rewards Matrix]", func() {
+	require := require.New(ginkgo.GinkgoT())
+
+	ginkgo.It("should split staking rewards as calculated for edge case staking parameters", func() {
+		network := e2e.Env.GetNetwork()
+
+		ginkgo.By("retrieving staking configuration for the network")
+		// TODO(marun) Enable GetConfig to return *node.Config
+		// directly. Currently, due to a circular dependency issue, a
+		// map-based equivalent is used for which manual unmarshaling
+		// is required.
+		adminClient := admin.NewClient(e2e.Env.GetRandomNodeURI().URI)
+		rawNodeConfigMap, err := adminClient.GetConfig(e2e.DefaultContext())
+		require.NoError(err)
+		nodeConfigMap, ok := rawNodeConfigMap.(map[string]interface{})
+		require.True(ok)
+		stakingConfigMap, ok := nodeConfigMap["stakingConfig"].(map[string]interface{})
+		require.True(ok)
+		rewardConfig := reward.Config{}
+		require.NoError(mapstructure.Decode(stakingConfigMap["rewardConfig"], &rewardConfig))
+		minStakeDuration := cast.ToDuration(stakingConfigMap["minStakeDuration"])
+		maxStakeDuration := cast.ToDuration(stakingConfigMap["maxStakeDuration"])
+		require.Positive(minStakeDuration)
+		require.GreaterOrEqual(maxStakeDuration, minStakeDuration)
+
+		const weight = 2_000 * units.Avax
+
+		pvmClient := platformvm.NewClient(e2e.Env.GetRandomNodeURI().URI)
+		currentSupply, _, err := pvmClient.GetCurrentSupply(e2e.DefaultContext(), constants.PrimaryNetworkID)
+		require.NoError(err)
+		calculator := reward.NewCalculator(rewardConfig)
+
+		for _, scenario := range newRewardScenarios(minStakeDuration, maxStakeDuration) {
+			ginkgo.By(fmt.Sprintf("checking the calculated reward split for %q", scenario.name), func() {
+				potentialDelegationReward := calculator.Calculate(scenario.delegationPeriod, weight, currentSupply)
+				delegationFee, delegatorReward := reward.Split(potentialDelegationReward, scenario.delegationShare)
+				require.Equal(potentialDelegationReward, delegationFee+delegatorReward)
+
+				switch scenario.delegationShare {
+				case 0:
+					require.Zero(delegationFee)
+				case reward.PercentDenominator:
+					require.Zero(delegatorReward)
+				}
+
+				if scenario.delegationPeriod == scenario.validationPeriod {
+					validationReward := calculator.Calculate(scenario.validationPeriod, weight, currentSupply)
+					require.Equal(validationReward, potentialDelegationReward)
+				}
+			})
+
+			if scenario.validationPeriod > maxLiveRewardScenarioDuration {
+				tests.Outf("{{yellow}}skipping live run of %q: validation period %s exceeds %s{{/}}\n",
+					scenario.name,
+					scenario.validationPeriod,
+					maxLiveRewardScenarioDuration,
+				)
+				continue
+			}
+
+			ginkgo.By(fmt.Sprintf("checking issued rewards for %q", scenario.name), func() {
+				runRewardScenario(require, network, calculator, scenario, weight)
+			})
+		}
+
+		e2e.CheckBootstrapIsPossible(network)
+	})
+})
+
+// runRewardScenario stakes on a new ephemeral node with the parameters of
+// [scenario] and checks that the issued rewards match [calculator].
+func runRewardScenario(
+	require *require.Assertions,
+	network tmpnet.Network,
+	calculator reward.Calculator,
+	scenario rewardScenario,
+	weight uint64,
+) {
+	node := e2e.AddEphemeralNode(network, tmpnet.FlagsMap{})
+	e2e.WaitForHealthy(node)
+
+	validationRewardKey, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+	delegationRewardKey, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+	delegatorRewardKey, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+	rewardKeys := []*secp256k1.PrivateKey{
+		validationRewardKey,
+		delegationRewardKey,
+		delegatorRewardKey,
+	}
+
+	keychain := secp256k1fx.NewKeychain(rewardKeys...)
+	keychain.Add(e2e.Env.AllocateFundedKey())
+	nodeURI := e2e.Env.GetRandomNodeURI()
+	pWallet := e2e.NewWallet(keychain, nodeURI).P()
+
+	infoClient := info.NewClient(node.GetProcessContext().URI)
+	nodeID, pop, err := infoClient.GetNodeID(e2e.DefaultContext())
+	require.NoError(err)
+
+	validatorStartTime := time.Now().Add(e2e.DefaultValidatorStartTimeDiff)
+	validatorEndTime := validatorStartTime.Add(scenario.validationPeriod)
+	delegatorEndTime := validatorEndTime
+	delegatorStartTime := delegatorEndTime.Add(-scenario.delegationPeriod)
+	tests.Outf("validation period starting at: %v\n", validatorStartTime)
+
+	_, err = pWallet.IssueAddPermissionlessValidatorTx(
+		&txs.SubnetValidator{
+			Validator: txs.Validator{
+				NodeID: nodeID,
+				Start:  uint64(validatorStartTime.Unix()),
+				End:    uint64(validatorEndTime.Unix()),
+				Wght:   weight,
+			},
+			Subnet: constants.PrimaryNetworkID,
+		},
+		pop,
+		pWallet.AVAXAssetID(),
+		&secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{validationRewardKey.Address()},
+		},
+		&secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{delegationRewardKey.Address()},
+		},
+		scenario.delegationShare,
+		e2e.WithDefaultContext(),
+	)
+	require.NoError(err)
+
+	_, err = pWallet.IssueAddPermissionlessDelegatorTx(
+		&txs.SubnetValidator{
+			Validator: txs.Validator{
+				NodeID: nodeID,
+				Start:  uint64(delegatorStartTime.Unix()),
+				End:    uint64(delegatorEndTime.Unix()),
+				Wght:   weight,
+			},
+			Subnet: constants.PrimaryNetworkID,
+		},
+		pWallet.AVAXAssetID(),
+		&secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{delegatorRewardKey.Address()},
+		},
+		e2e.WithDefaultContext(),
+	)
+	require.NoError(err)
+
+	time.Sleep(time.Until(validatorEndTime))
+
+	pvmClient := platformvm.NewClient(node.GetProcessContext().URI)
+	e2e.Eventually(func() bool {
+		validators, err := pvmClient.GetCurrentValidators(e2e.DefaultContext(), constants.PrimaryNetworkID, nil)
+		require.NoError(err)
+		for _, validator := range validators {
+			if validator.NodeID == nodeID {
+				return false
+			}
+		}
+		return true
+	}, e2e.DefaultTimeout, e2e.DefaultPollingInterval, "node failed to stop validating before timeout ")
+
+	rewardBalances := make(map[ids.ShortID]uint64, len(rewardKeys))
+	for _, rewardKey := range rewardKeys {
+		keychain := secp256k1fx.NewKeychain(rewardKey)
+		pWallet := e2e.NewWallet(keychain, nodeURI).P()
+		balances, err := pWallet.Builder().GetBalance()
+		require.NoError(err)
+		rewardBalances[rewardKey.Address()] = balances[pWallet.AVAXAssetID()]
+	}
+
+	currentSupply, _, err := pvmClient.GetCurrentSupply(e2e.DefaultContext(), constants.PrimaryNetworkID)
+	require.NoError(err)
+	expectedValidationReward := calculator.Calculate(scenario.validationPeriod, weight, currentSupply)
+	potentialDelegationReward := calculator.Calculate(scenario.delegationPeriod, weight, currentSupply)
+	expectedDelegationFee, expectedDelegatorReward := reward.Split(potentialDelegationReward, scenario.delegationShare)
+
+	require.Equal(map[ids.ShortID]uint64{
+		validationRewardKey.Address(): expectedValidationReward,
+		delegationRewardKey.Address(): expectedDelegationFee,
+		delegatorRewardKey.Address():  expectedDelegatorReward,
+	}, rewardBalances)
+
+	require.NoError(node.Stop())
+}
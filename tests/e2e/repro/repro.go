@@ -0,0 +1,84 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Implements tests of the reproduction of failed specs.
+package repro
+
+import (
+	"go/parser"
+	"go/token"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests/fixture/e2e"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var _ = e2e.DescribeXChain("[Repro]", func() {
+	require := require.New(ginkgo.GinkgoT())
+
+	const transferAmount = units.Avax
+
+	ginkgo.It("should replay the recorded operations of a spec against a fresh network", func() {
+		nodeURI := e2e.Env.GetRandomNodeURI()
+
+		ginkgo.By("creating a wallet with a funded key")
+		keychain := e2e.Env.NewKeychain(1)
+		xWallet := e2e.NewWallet(keychain, nodeURI).X()
+		avaxAssetID := xWallet.AVAXAssetID()
+
+		ginkgo.By("sending funds to a new address on the X-Chain")
+		recipientKey, err := secp256k1.NewPrivateKey()
+		require.NoError(err)
+		_, err = xWallet.IssueBaseTx(
+			[]*avax.TransferableOutput{{
+				Asset: avax.Asset{
+					ID: avaxAssetID,
+				},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: transferAmount,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{recipientKey.Address()},
+					},
+				},
+			}},
+			e2e.WithDefaultContext(),
+		)
+		require.NoError(err)
+
+		ginkgo.By("checking that the transfer was recorded")
+		repro := e2e.Env.CurrentRepro()
+		require.Len(repro.Steps, 1)
+		require.Equal(e2e.ReproOpIssueTx, repro.Steps[0].Op)
+		require.Equal(xWallet.BlockchainID(), repro.Steps[0].ChainID)
+
+		src, err := repro.GoSource()
+		require.NoError(err)
+		_, err = parser.ParseFile(token.NewFileSet(), "repro_test.go", src, parser.ParseComments)
+		require.NoError(err)
+
+		ginkgo.By("replaying the transfer against a fresh network")
+		r := e2e.NewReproReplayer(ginkgo.GinkgoT(), repro.NetworkDir)
+		for _, uri := range e2e.Env.URIs {
+			require.NotEqual(uri.URI, r.URI())
+		}
+		r.Replay(repro.Steps)
+
+		ginkgo.By("checking that the fresh network received the sent funds")
+		recipientWallet := e2e.NewWallet(
+			secp256k1fx.NewKeychain(recipientKey),
+			tmpnet.NodeURI{URI: r.URI()},
+		)
+		balances, err := recipientWallet.X().Builder().GetFTBalance()
+		require.NoError(err)
+		require.Equal(transferAmount, balances[avaxAssetID])
+	})
+})
@@ -0,0 +1,42 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package x
+
+import (
+	ginkgo "github.com/onsi/ginkgo/v2"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests/fixture/e2e"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+var _ = e2e.DescribeXChain("[Conflicting Txs]", func() {
+	ginkgo.It("should converge on one of a set of conflicting txs issued to different nodes", func() {
+		network := e2e.Env.GetNetwork()
+
+		ginkgo.By("adding nodes that don't gossip txs")
+		isolatedURIs := e2e.AddNoTxGossipNodes(network, 2)
+
+		ginkgo.By("building conflicting txs")
+		keychain := e2e.Env.NewKeychain(1)
+		xWallet := e2e.NewWallet(keychain, isolatedURIs[0]).X()
+		conflictingTxs := e2e.NewConflictingXChainTxs(xWallet, units.Avax, len(isolatedURIs))
+
+		ginkgo.By("issuing each conflicting tx to a different node")
+		e2e.IssueXChainTxs(conflictingTxs, isolatedURIs)
+
+		ginkgo.By("checking that every node accepts the same tx")
+		txIDs := make([]ids.ID, len(conflictingTxs))
+		for i, tx := range conflictingTxs {
+			txIDs[i] = tx.ID()
+		}
+		nodeURIs := make([]tmpnet.NodeURI, 0, len(e2e.Env.URIs)+len(isolatedURIs))
+		nodeURIs = append(nodeURIs, e2e.Env.URIs...)
+		nodeURIs = append(nodeURIs, isolatedURIs...)
+		e2e.WaitForXChainConvergence(nodeURIs, txIDs)
+
+		e2e.CheckBootstrapIsPossible(network)
+	})
+})
@@ -0,0 +1,175 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/avalanchego/tests/fixture/e2e"
+)
+
+var (
+	errTestBinaryRequired  = errors.New("--e2e-test-path is required")
+	errAvalancheGoRequired = errors.New("--avalanchego-path is required")
+	errCoverageMapRequired = errors.New("--coverage-map is required")
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "e2ecov",
+		Short: "e2ecov maps e2e specs to the files they exercise",
+	}
+
+	var (
+		testBinaryPath  string
+		execPath        string
+		coverageMapPath string
+	)
+	recordCmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record the files exercised by each e2e spec",
+		Long: `Runs each e2e spec against its own network of nodes built with
+'go build -cover' and records the files they executed to a coverage map.`,
+		RunE: func(*cobra.Command, []string) error {
+			switch {
+			case len(testBinaryPath) == 0:
+				return errTestBinaryRequired
+			case len(execPath) == 0:
+				return errAvalancheGoRequired
+			case len(coverageMapPath) == 0:
+				return errCoverageMapRequired
+			}
+			return record(testBinaryPath, execPath, coverageMapPath)
+		},
+	}
+	recordCmd.PersistentFlags().StringVar(&testBinaryPath, "e2e-test-path", "", "The path to an e2e test binary built with 'ginkgo build'")
+	recordCmd.PersistentFlags().StringVar(&execPath, "avalanchego-path", "", "The path to an avalanchego binary built with 'go build -cover'")
+	recordCmd.PersistentFlags().StringVar(&coverageMapPath, "coverage-map", "", "The path to write the coverage map to")
+	rootCmd.AddCommand(recordCmd)
+
+	var changedFilesPath string
+	affectedCmd := &cobra.Command{
+		Use:   "affected",
+		Short: "Print the e2e specs affected by a change",
+		RunE: func(*cobra.Command, []string) error {
+			if len(coverageMapPath) == 0 {
+				return errCoverageMapRequired
+			}
+			coverageMap, err := e2e.ReadCoverageMap(coverageMapPath)
+			if err != nil {
+				return err
+			}
+			changedFilesBytes, err := os.ReadFile(changedFilesPath)
+			if err != nil {
+				return fmt.Errorf("failed to read changed files: %w", err)
+			}
+			specs, ok := coverageMap.AffectedSpecs(strings.Fields(string(changedFilesBytes)))
+			if !ok {
+				fmt.Fprintln(os.Stdout, "all specs are affected")
+				return nil
+			}
+			for _, spec := range specs {
+				fmt.Fprintln(os.Stdout, spec)
+			}
+			return nil
+		},
+	}
+	affectedCmd.PersistentFlags().StringVar(&coverageMapPath, "coverage-map", "", "The path of the coverage map")
+	affectedCmd.PersistentFlags().StringVar(&changedFilesPath, "changed-files", "/dev/stdin", "A file listing the changed files, one per line")
+	rootCmd.AddCommand(affectedCmd)
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "e2ecov failed: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func record(testBinaryPath string, execPath string, coverageMapPath string) error {
+	tempDir, err := os.MkdirTemp("", "e2ecov")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	specs, err := listSpecs(testBinaryPath, tempDir)
+	if err != nil {
+		return err
+	}
+
+	coverageMap := e2e.NewCoverageMap()
+	for i, spec := range specs {
+		fmt.Fprintf(os.Stdout, "recording spec %d/%d: %s\n", i+1, len(specs), spec)
+
+		coverDir, err := os.MkdirTemp(tempDir, "cover")
+		if err != nil {
+			return err
+		}
+		cmd := exec.Command(
+			testBinaryPath,
+			"--avalanchego-path="+execPath,
+			"--ginkgo.focus="+e2e.SpecsFocus([]string{spec}),
+		)
+		// The nodes of the test network inherit the environment of the
+		// test binary and write their coverage counters to GOCOVERDIR
+		// when they are stopped.
+		cmd.Env = append(os.Environ(), "GOCOVERDIR="+coverDir)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run spec %q: %w", spec, err)
+		}
+
+		if err := coverageMap.RecordSpec(spec, coverDir); err != nil {
+			return err
+		}
+		// Write the map after every spec to preserve progress
+		if err := coverageMap.Write(coverageMapPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the full text of the specs of the test binary without running them.
+func listSpecs(testBinaryPath string, tempDir string) ([]string, error) {
+	reportPath := filepath.Join(tempDir, "specs.json")
+	cmd := exec.Command(
+		testBinaryPath,
+		"--ginkgo.dry-run",
+		"--ginkgo.json-report="+reportPath,
+	)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to list specs: %w", err)
+	}
+
+	reportBytes, err := os.ReadFile(reportPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec report: %w", err)
+	}
+	var reports []types.Report
+	if err := json.Unmarshal(reportBytes, &reports); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec report: %w", err)
+	}
+
+	var specs []string
+	for _, report := range reports {
+		for _, spec := range report.SpecReports {
+			if spec.LeafNodeType == types.NodeTypeIt {
+				specs = append(specs, spec.FullText())
+			}
+		}
+	}
+	return specs, nil
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package e2e
+
+import (
+	"fmt"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/tests"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/avm/txs"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/chain/x"
+)
+
+// Flags for a node that doesn't push gossip app messages, including
+// transactions, to its peers. A transaction issued to such a node is only seen
+// by the rest of the network once it's included in a block, which allows
+// conflicting transactions to be issued to different nodes before either of
+// them is known to the network.
+func NoTxGossipFlags() tmpnet.FlagsMap {
+	return tmpnet.FlagsMap{
+		config.AppGossipValidatorSizeKey:    0,
+		config.AppGossipNonValidatorSizeKey: 0,
+		config.AppGossipPeerSizeKey:         0,
+	}
+}
+
+// Add [count] ephemeral nodes configured with NoTxGossipFlags and wait for them
+// to report healthy. Issuing conflicting transactions to different nodes
+// returned by this function creates short-lived conflicting views of the
+// pending transactions that the network must resolve.
+func AddNoTxGossipNodes(network tmpnet.Network, count int) []tmpnet.NodeURI {
+	nodes := make([]tmpnet.Node, count)
+	for i := range nodes {
+		nodes[i] = AddEphemeralNode(network, NoTxGossipFlags())
+	}
+
+	nodeURIs := make([]tmpnet.NodeURI, count)
+	for i, node := range nodes {
+		WaitForHealthy(node)
+		nodeURIs[i] = tmpnet.NodeURI{
+			NodeID: node.GetID(),
+			URI:    node.GetProcessContext().URI,
+		}
+	}
+	return nodeURIs
+}
+
+// Build [count] signed X-Chain transactions that consume the same UTXOs, so
+// that at most one of them can be accepted. The transactions send [amount] of
+// AVAX to a new address and differ only by their memo. The transactions are
+// not issued.
+func NewConflictingXChainTxs(xWallet x.Wallet, amount uint64, count int) []*txs.Tx {
+	require := require.New(ginkgo.GinkgoT())
+
+	recipientKey, err := secp256k1.NewPrivateKey()
+	require.NoError(err)
+
+	utx, err := xWallet.Builder().NewBaseTx(
+		[]*avax.TransferableOutput{{
+			Asset: avax.Asset{
+				ID: xWallet.AVAXAssetID(),
+			},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs: []ids.ShortID{
+						recipientKey.Address(),
+					},
+				},
+			},
+		}},
+		WithDefaultContext(),
+	)
+	require.NoError(err)
+
+	conflictingTxs := make([]*txs.Tx, count)
+	for i := range conflictingTxs {
+		// Copying the unsigned tx ensures that every tx has the same inputs.
+		conflictingUTx := *utx
+		conflictingUTx.Memo = []byte(fmt.Sprintf("conflict %d", i))
+
+		tx, err := xWallet.Signer().SignUnsigned(DefaultContext(), &conflictingUTx)
+		require.NoError(err)
+		conflictingTxs[i] = tx
+	}
+	return conflictingTxs
+}
+
+// Issue issuedTxs[i] to the X-Chain of nodeURIs[i] without waiting for
+// acceptance.
+func IssueXChainTxs(issuedTxs []*txs.Tx, nodeURIs []tmpnet.NodeURI) {
+	require := require.New(ginkgo.GinkgoT())
+	require.Len(nodeURIs, len(issuedTxs))
+
+	for i, tx := range issuedTxs {
+		xClient := avm.NewClient(nodeURIs[i].URI, "X")
+		txID, err := xClient.IssueTx(DefaultContext(), tx.Bytes())
+		require.NoError(err)
+		tests.Outf(" issued transaction with ID %s to node %s\n", txID, nodeURIs[i].NodeID)
+	}
+}
+
+// Wait for every node in [nodeURIs] to accept the same one of the conflicting
+// X-Chain transactions identified by [txIDs], and return the ID of the
+// accepted transaction. Fails if a node accepts more than one of the
+// transactions or if nodes accept different transactions.
+func WaitForXChainConvergence(nodeURIs []tmpnet.NodeURI, txIDs []ids.ID) ids.ID {
+	require := require.New(ginkgo.GinkgoT())
+
+	var acceptedTxID ids.ID
+	Eventually(func() bool {
+		acceptedTxID = ids.Empty
+		for _, nodeURI := range nodeURIs {
+			xClient := avm.NewClient(nodeURI.URI, "X")
+
+			var nodeAcceptedTxIDs []ids.ID
+			for _, txID := range txIDs {
+				status, err := xClient.GetTxStatus(DefaultContext(), txID)
+				require.NoError(err)
+				if status == choices.Accepted {
+					nodeAcceptedTxIDs = append(nodeAcceptedTxIDs, txID)
+				}
+			}
+
+			require.LessOrEqual(len(nodeAcceptedTxIDs), 1, "node %s accepted conflicting transactions", nodeURI.NodeID)
+			if len(nodeAcceptedTxIDs) == 0 {
+				return false // The conflict hasn't been resolved by this node yet
+			}
+			if acceptedTxID == ids.Empty {
+				acceptedTxID = nodeAcceptedTxIDs[0]
+			}
+			require.Equal(acceptedTxID, nodeAcceptedTxIDs[0], "nodes accepted different conflicting transactions")
+		}
+		return true
+	}, DefaultTimeout, DefaultPollingInterval, "failed to see the network converge on a conflicting transaction before timeout")
+
+	tests.Outf("{{green}} network converged on transaction %s{{/}}\n", acceptedTxID)
+	return acceptedTxID
+}
@@ -0,0 +1,171 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package e2e
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/utils/perms"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+// The import path of the module whose files are mapped to specs.
+const avalancheGoModule = "github.com/ava-labs/avalanchego/"
+
+var errMalformedCoverage = errors.New("malformed coverage line")
+
+// CoverageMap maps each e2e spec to the files of avalanchego that the nodes
+// of the test network executed while the spec ran, so that a change can be
+// verified by running only the specs that exercise the files it changes.
+//
+// Paths are relative to the root of the repository.
+type CoverageMap struct {
+	// Files that were instrumented for coverage when the map was recorded.
+	Files []string `json:"files"`
+	// Full text of each spec -> files with at least one covered function.
+	Specs map[string][]string `json:"specs"`
+}
+
+func NewCoverageMap() *CoverageMap {
+	return &CoverageMap{
+		Specs: make(map[string][]string),
+	}
+}
+
+func ReadCoverageMap(path string) (*CoverageMap, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage map: %w", err)
+	}
+	m := NewCoverageMap()
+	if err := json.Unmarshal(bytes, m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal coverage map: %w", err)
+	}
+	return m, nil
+}
+
+func (m *CoverageMap) Write(path string) error {
+	bytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal coverage map: %w", err)
+	}
+	if err := os.WriteFile(path, bytes, perms.ReadWrite); err != nil {
+		return fmt.Errorf("failed to write coverage map: %w", err)
+	}
+	return nil
+}
+
+// RecordSpec records that [spec] exercised the files covered by the counters
+// that instrumented nodes wrote to [coverDir], which must have been the
+// GOCOVERDIR of the nodes.
+func (m *CoverageMap) RecordSpec(spec string, coverDir string) error {
+	output, err := exec.Command("go", "tool", "covdata", "func", "-i="+coverDir).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read coverage of spec %q: %w", spec, err)
+	}
+	instrumented, covered, err := parseCovdataFunc(bytes.NewReader(output))
+	if err != nil {
+		return fmt.Errorf("failed to parse coverage of spec %q: %w", spec, err)
+	}
+
+	files := set.Of(m.Files...)
+	files.Union(instrumented)
+	m.Files = files.List()
+	slices.Sort(m.Files)
+
+	m.Specs[spec] = covered.List()
+	slices.Sort(m.Specs[spec])
+	return nil
+}
+
+// AffectedSpecs returns the specs that exercise at least one of
+// [changedFiles], which are relative to the root of the repository. Returns
+// false if it can't be determined which specs are affected, in which case
+// every spec should run.
+//
+// Changes to unit tests and documentation don't affect any spec. Changes to
+// other files that weren't instrumented when the map was recorded, such as
+// new files, e2e tests and non-go files, may affect any spec.
+func (m *CoverageMap) AffectedSpecs(changedFiles []string) ([]string, bool) {
+	var (
+		instrumented = set.Of(m.Files...)
+		affected     set.Set[string]
+	)
+	for _, file := range changedFiles {
+		switch {
+		case strings.HasSuffix(file, "_test.go"), path.Ext(file) == ".md":
+			continue
+		case !instrumented.Contains(file):
+			return nil, false
+		}
+		for spec, files := range m.Specs {
+			if slices.Contains(files, file) {
+				affected.Add(spec)
+			}
+		}
+	}
+	specs := affected.List()
+	slices.Sort(specs)
+	return specs, true
+}
+
+// SpecsFocus returns a ginkgo focus regex that matches exactly the specs
+// whose full text is one of [specs].
+func SpecsFocus(specs []string) string {
+	quoted := make([]string, len(specs))
+	for i, spec := range specs {
+		quoted[i] = regexp.QuoteMeta(spec)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+// Parses the output of `go tool covdata func`, which has a line of the form
+//
+//	github.com/ava-labs/avalanchego/api/admin/service.go:87:	NewService	75.0%
+//
+// for each instrumented function, followed by a total. Returns the
+// instrumented files and the files with at least one covered function,
+// relative to the root of the repository. Files outside of avalanchego are
+// ignored.
+func parseCovdataFunc(r io.Reader) (set.Set[string], set.Set[string], error) {
+	var (
+		instrumented set.Set[string]
+		covered      set.Set[string]
+		scanner      = bufio.NewScanner(r)
+	)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || strings.TrimSuffix(fields[0], ":") == "total" {
+			continue
+		}
+
+		location, percent := fields[0], fields[len(fields)-1]
+		file, _, ok := strings.Cut(location, ":")
+		if !ok || !strings.HasSuffix(percent, "%") {
+			return nil, nil, fmt.Errorf("%w: %q", errMalformedCoverage, scanner.Text())
+		}
+		file, ok = strings.CutPrefix(file, avalancheGoModule)
+		if !ok {
+			continue
+		}
+
+		instrumented.Add(file)
+		if strings.TrimSuffix(percent, "%") != "0.0" {
+			covered.Add(file)
+		}
+	}
+	return instrumented, covered, scanner.Err()
+}
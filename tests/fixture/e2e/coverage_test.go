@@ -0,0 +1,104 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package e2e
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+func TestParseCovdataFunc(t *testing.T) {
+	require := require.New(t)
+
+	output := `github.com/ava-labs/avalanchego/api/admin/service.go:87:	NewService	75.0%
+github.com/ava-labs/avalanchego/api/admin/service.go:120:	StartCPUProfiler	0.0%
+github.com/ava-labs/avalanchego/api/info/service.go:64:	NewService	0.0%
+github.com/ava-labs/coreth/plugin/evm/vm.go:100:	Initialize	50.0%
+total	(statements)	12.5%
+`
+	instrumented, covered, err := parseCovdataFunc(strings.NewReader(output))
+	require.NoError(err)
+	require.Equal(set.Of("api/admin/service.go", "api/info/service.go"), instrumented)
+	require.Equal(set.Of("api/admin/service.go"), covered)
+
+	_, _, err = parseCovdataFunc(strings.NewReader("not coverage output\n"))
+	require.ErrorIs(err, errMalformedCoverage)
+}
+
+func TestAffectedSpecs(t *testing.T) {
+	m := &CoverageMap{
+		Files: []string{"a.go", "b.go", "c.go"},
+		Specs: map[string][]string{
+			"spec 1": {"a.go", "b.go"},
+			"spec 2": {"b.go"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		changedFiles  []string
+		expectedSpecs []string
+		expectedOK    bool
+	}{
+		{
+			name:         "no changes",
+			changedFiles: nil,
+			expectedOK:   true,
+		},
+		{
+			name:          "covered by one spec",
+			changedFiles:  []string{"a.go"},
+			expectedSpecs: []string{"spec 1"},
+			expectedOK:    true,
+		},
+		{
+			name:          "covered by every spec",
+			changedFiles:  []string{"b.go"},
+			expectedSpecs: []string{"spec 1", "spec 2"},
+			expectedOK:    true,
+		},
+		{
+			name:         "instrumented but not covered",
+			changedFiles: []string{"c.go"},
+			expectedOK:   true,
+		},
+		{
+			name:          "unit tests and docs are ignored",
+			changedFiles:  []string{"a.go", "d_test.go", "README.md"},
+			expectedSpecs: []string{"spec 1"},
+			expectedOK:    true,
+		},
+		{
+			name:         "not instrumented",
+			changedFiles: []string{"a.go", "scripts/build.sh"},
+			expectedOK:   false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			specs, ok := m.AffectedSpecs(test.changedFiles)
+			require.Equal(test.expectedOK, ok)
+			if ok {
+				require.ElementsMatch(test.expectedSpecs, specs)
+			}
+		})
+	}
+}
+
+func TestSpecsFocus(t *testing.T) {
+	require := require.New(t)
+
+	focus := regexp.MustCompile(SpecsFocus([]string{"[x] spec (1)", "spec 2"}))
+	require.True(focus.MatchString("[x] spec (1)"))
+	require.True(focus.MatchString("spec 2"))
+	require.False(focus.MatchString("spec 2 and more"))
+	require.False(focus.MatchString("x spec 1"))
+}
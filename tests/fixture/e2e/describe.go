@@ -14,6 +14,10 @@ const (
 	// but nonentheless uses the C-Chain. Intended to support
 	// execution of all C-Chain tests by the coreth repo in an e2e job.
 	UsesCChainLabel = "uses-c"
+
+	// Label for selecting the bootstrap performance benchmark. The benchmark
+	// is slow and is only run when explicitly selected with this label.
+	BootstrapPerformanceLabel = "bootstrap-performance"
 )
 
 // DescribeXChain annotates the tests for X-Chain.
@@ -4,10 +4,13 @@
 package e2e
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"regexp"
 	"time"
 
 	ginkgo "github.com/onsi/ginkgo/v2"
@@ -23,6 +26,9 @@ import (
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 )
 
+// Matches the characters of a spec name that aren't safe to use in a file name.
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
 // Env is used to access shared test fixture. Intended to be
 // initialized from SynchronizedBeforeSuite.
 var Env *TestEnvironment
@@ -67,7 +73,9 @@ func NewTestEnvironment(flagVars *FlagVars) *TestEnvironment {
 		require.NoError(err)
 		tests.Outf("{{yellow}}Using an existing network configured at %s{{/}}\n", network.Dir)
 	} else {
-		network = StartLocalNetwork(flagVars.AvalancheGoExecPath(), DefaultNetworkDir)
+		rootDir, err := local.GetRunRootDir(DefaultNetworkDir, flagVars.RunID())
+		require.NoError(err)
+		network = StartLocalNetwork(flagVars.AvalancheGoExecPath(), rootDir)
 	}
 
 	uris := network.GetURIs()
@@ -135,3 +143,104 @@ func (te *TestEnvironment) NewPrivateNetwork() tmpnet.Network {
 
 	return StartLocalNetwork(sharedNetwork.ExecPath, privateNetworksDir)
 }
+
+// CheckMetricsBaselines compares the metrics of the nodes of the shared
+// network to the baselines declared with DeclareMetricsBaseline, and writes
+// the comparison to the network dir. Fails if a metric drifted from its
+// baseline.
+func (te *TestEnvironment) CheckMetricsBaselines() {
+	baselines := DeclaredMetricsBaselines()
+	if len(baselines) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	report, err := CheckMetricsBaselines(ctx, te.URIs, baselines)
+	te.require.NoError(err)
+
+	reportBytes, err := tmpnet.DefaultJSONMarshal(report)
+	te.require.NoError(err)
+	reportPath := filepath.Join(te.NetworkDir, MetricsDriftReportFileName)
+	te.require.NoError(os.WriteFile(reportPath, reportBytes, perms.ReadWrite))
+	tests.Outf("{{blue}}wrote metrics drift report to %s{{/}}\n", reportPath)
+
+	drifted := report.Drifted()
+	for _, observation := range drifted {
+		tests.Outf("{{red}}metric drifted from baseline: %s{{/}}\n", observation)
+	}
+	te.require.Empty(drifted, "metrics drifted from their baselines")
+}
+
+// WriteTopologyIfFailed writes the topology of the shared network, as JSON
+// and as a graphviz graph, to the network dir if the current spec failed.
+// The topology is written under the network dir to ensure it will be included
+// in the artifact uploaded in CI.
+func (te *TestEnvironment) WriteTopologyIfFailed() {
+	report := ginkgo.CurrentSpecReport()
+	if !report.Failed() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tmpnet.DefaultTopologyTimeout)
+	defer cancel()
+	topology := tmpnet.GetTopology(ctx, te.GetNetwork())
+
+	topologyDir := filepath.Join(te.NetworkDir, TopologyDirName)
+	te.require.NoError(os.MkdirAll(topologyDir, perms.ReadWriteExecute))
+	basePath := filepath.Join(topologyDir, unsafeFileNameChars.ReplaceAllString(report.FullText(), "_"))
+
+	topologyBytes, err := tmpnet.DefaultJSONMarshal(topology)
+	te.require.NoError(err)
+	te.require.NoError(os.WriteFile(basePath+".json", topologyBytes, perms.ReadWrite))
+
+	dot := &bytes.Buffer{}
+	te.require.NoError(topology.WriteDOT(dot))
+	te.require.NoError(os.WriteFile(basePath+".dot", dot.Bytes(), perms.ReadWrite))
+
+	tests.Outf("{{yellow}}wrote network topology to %s.json{{/}}\n", basePath)
+}
+
+// WriteReproIfFailed writes the fixture operations performed by the current
+// spec to the network dir if the spec failed, as JSON and as a Go test that
+// replays them. Must be called after every spec, since it also starts the
+// recording of the next spec.
+func (te *TestEnvironment) WriteReproIfFailed() {
+	steps := recorder.reset()
+	report := ginkgo.CurrentSpecReport()
+	if !report.Failed() {
+		return
+	}
+
+	repro := te.newRepro(report, steps)
+
+	reproDir := filepath.Join(te.NetworkDir, ReproDirName)
+	te.require.NoError(os.MkdirAll(reproDir, perms.ReadWriteExecute))
+	basePath := filepath.Join(reproDir, unsafeFileNameChars.ReplaceAllString(report.FullText(), "_"))
+
+	reproBytes, err := tmpnet.DefaultJSONMarshal(repro)
+	te.require.NoError(err)
+	te.require.NoError(os.WriteFile(basePath+".json", reproBytes, perms.ReadWrite))
+
+	src, err := repro.GoSource()
+	te.require.NoError(err)
+	te.require.NoError(os.WriteFile(basePath+"_test.go", src, perms.ReadWrite))
+
+	tests.Outf("{{yellow}}wrote reproduction of the failed spec to %s_test.go{{/}}\n", basePath)
+}
+
+// CurrentRepro returns the fixture operations performed by the current spec
+// so far.
+func (te *TestEnvironment) CurrentRepro() *Repro {
+	return te.newRepro(ginkgo.CurrentSpecReport(), recorder.snapshot())
+}
+
+func (te *TestEnvironment) newRepro(report ginkgo.SpecReport, steps []ReproStep) *Repro {
+	return &Repro{
+		Spec:       report.FullText(),
+		Failure:    report.FailureMessage(),
+		Location:   report.FailureLocation().String(),
+		NetworkDir: te.NetworkDir,
+		Steps:      steps,
+	}
+}
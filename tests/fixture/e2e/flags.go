@@ -4,17 +4,27 @@
 package e2e
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/onsi/ginkgo/v2/types"
+
+	"github.com/ava-labs/avalanchego/tests"
 	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet/local"
 )
 
+var errCoverageMapRequired = errors.New("--coverage-map is required by --changed-files")
+
 type FlagVars struct {
 	avalancheGoExecPath string
 	networkDir          string
 	useExistingNetwork  bool
+	runID               string
+	coverageMapPath     string
+	changedFilesPath    string
 }
 
 func (v *FlagVars) NetworkDir() string {
@@ -35,6 +45,48 @@ func (v *FlagVars) UseExistingNetwork() bool {
 	return v.useExistingNetwork
 }
 
+func (v *FlagVars) RunID() string {
+	return v.runID
+}
+
+// FocusAffectedSpecs narrows [suiteConfig] to the specs that exercise the
+// files listed by --changed-files according to --coverage-map. Returns false
+// if no spec is affected, in which case no spec needs to run. [suiteConfig] is
+// left unchanged if --changed-files isn't set or if it can't be determined
+// which specs are affected.
+func (v *FlagVars) FocusAffectedSpecs(suiteConfig *types.SuiteConfig) (bool, error) {
+	if len(v.changedFilesPath) == 0 {
+		return true, nil
+	}
+	if len(v.coverageMapPath) == 0 {
+		return false, errCoverageMapRequired
+	}
+
+	coverageMap, err := ReadCoverageMap(v.coverageMapPath)
+	if err != nil {
+		return false, err
+	}
+	changedFilesBytes, err := os.ReadFile(v.changedFilesPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read changed files: %w", err)
+	}
+	changedFiles := strings.Fields(string(changedFilesBytes))
+
+	specs, ok := coverageMap.AffectedSpecs(changedFiles)
+	switch {
+	case !ok:
+		tests.Outf("{{yellow}}Running every spec since the specs affected by the changed files are unknown{{/}}\n")
+		return true, nil
+	case len(specs) == 0:
+		tests.Outf("{{green}}No spec is affected by the changed files{{/}}\n")
+		return false, nil
+	}
+
+	tests.Outf("{{green}}Running %d specs affected by the changed files{{/}}\n", len(specs))
+	suiteConfig.FocusStrings = append(suiteConfig.FocusStrings, SpecsFocus(specs))
+	return true, nil
+}
+
 func RegisterFlags() *FlagVars {
 	vars := FlagVars{}
 	flag.StringVar(
@@ -55,6 +107,25 @@ func RegisterFlags() *FlagVars {
 		false,
 		"[optional] whether to target the existing network identified by --network-dir.",
 	)
+	flag.StringVar(
+		&vars.runID,
+		"run-id",
+		os.Getenv(local.RunIDEnvName),
+		fmt.Sprintf("[optional] the ID of this test run. If provided, networks started by the run will be stored under a directory named for the ID. Also possible to configure via the %s env variable.", local.RunIDEnvName),
+	)
+
+	flag.StringVar(
+		&vars.coverageMapPath,
+		"coverage-map",
+		"",
+		"[optional] the coverage map recorded by the e2ecov tool. Required by --changed-files.",
+	)
+	flag.StringVar(
+		&vars.changedFilesPath,
+		"changed-files",
+		"",
+		"[optional] a file listing the files changed relative to the root of the repository, one per line (e.g. the output of git diff --name-only). If provided, only the specs that exercise the changed files according to --coverage-map are run.",
+	)
 
 	return &vars
 }
@@ -5,6 +5,7 @@ package e2e
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
@@ -59,6 +60,10 @@ const (
 	// Directory used to store private networks (specific to a single test)
 	// under the shared network dir.
 	PrivateNetworksDirName = "private_networks"
+
+	// Directory used to store the topology of the shared network when a
+	// test fails, under the shared network dir.
+	TopologyDirName = "topology"
 )
 
 // Create a new wallet for the provided keychain against the specified node URI.
@@ -72,6 +77,15 @@ func NewWallet(keychain *secp256k1fx.Keychain, nodeURI tmpnet.NodeURI) primary.W
 	require.NoError(ginkgo.GinkgoT(), err)
 	return primary.NewWalletWithOptions(
 		baseWallet,
+		common.WithPreIssuanceFunc(
+			func(chainID ids.ID, txBytes []byte) {
+				recorder.record(ReproStep{
+					Op:      ReproOpIssueTx,
+					ChainID: chainID,
+					TxBytes: hex.EncodeToString(txBytes),
+				})
+			},
+		),
 		common.WithPostIssuanceFunc(
 			func(id ids.ID) {
 				tests.Outf(" issued transaction with ID: %s\n", id)
@@ -111,6 +125,12 @@ func WithDefaultContext() common.Option {
 // version calls the condition function with a goroutine and ginkgo assertions don't work
 // properly in goroutines.
 func Eventually(condition func() bool, waitFor time.Duration, tick time.Duration, msg string) {
+	recorder.record(ReproStep{
+		Op:      ReproOpWait,
+		Timeout: waitFor,
+		Message: msg,
+	})
+
 	ticker := time.NewTicker(tick)
 	defer ticker.Stop()
 
@@ -133,6 +153,11 @@ func AddEphemeralNode(network tmpnet.Network, flags tmpnet.FlagsMap) tmpnet.Node
 
 	node, err := network.AddEphemeralNode(ginkgo.GinkgoWriter, flags)
 	require.NoError(err)
+	recorder.record(ReproStep{
+		Op:     ReproOpAddNode,
+		NodeID: node.GetID(),
+		Flags:  flags,
+	})
 
 	// Ensure node is stopped on teardown. It's configuration is not removed to enable
 	// collection in CI to aid in troubleshooting failures.
@@ -146,6 +171,11 @@ func AddEphemeralNode(network tmpnet.Network, flags tmpnet.FlagsMap) tmpnet.Node
 
 // Wait for the given node to report healthy.
 func WaitForHealthy(node tmpnet.Node) {
+	recorder.record(ReproStep{
+		Op:     ReproOpWaitForHealthy,
+		NodeID: node.GetID(),
+	})
+
 	// Need to use explicit context (vs DefaultContext()) to support use with DeferCleanup
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
@@ -160,6 +190,13 @@ func SendEthTransaction(ethClient ethclient.Client, signedTx *types.Transaction)
 	txID := signedTx.Hash()
 	tests.Outf(" sending eth transaction with ID: %s\n", txID)
 
+	txBytes, err := signedTx.MarshalBinary()
+	require.NoError(err)
+	recorder.record(ReproStep{
+		Op:      ReproOpSendEthTx,
+		TxBytes: hex.EncodeToString(txBytes),
+	})
+
 	require.NoError(ethClient.SendTransaction(DefaultContext(), signedTx))
 
 	// Wait for the receipt
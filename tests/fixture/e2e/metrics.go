@@ -0,0 +1,317 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/common/expfmt"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// The version of the format of metrics baseline files that is
+	// understood by this package. Baselines must declare the version they
+	// were written for so that the format can evolve without silently
+	// misinterpreting older files.
+	MetricsBaselineVersion = 1
+
+	// Name of the file, in the shared network dir, that the metrics drift
+	// report is written to. Since the network dir is uploaded as an artifact
+	// in CI, reports of successive runs can be diffed.
+	MetricsDriftReportFileName = "metrics_drift.json"
+
+	metricsPath = "/ext/metrics"
+)
+
+var (
+	errUnsupportedBaselineVersion = errors.New("unsupported metrics baseline version")
+	errMissingMetricName          = errors.New("metric expectation is missing a metric name")
+	errMissingBound               = errors.New("metric expectation has neither a min nor a max")
+	errInvalidBounds              = errors.New("metric expectation min exceeds its max")
+	errDuplicateBaseline          = errors.New("duplicate metrics baseline")
+	errUnexpectedStatus           = errors.New("unexpected status code")
+
+	// Baselines declared by specs, by name
+	declaredBaselines = map[string]*MetricsBaseline{}
+)
+
+// MetricExpectation bounds the value of a metric on every node of the shared
+// network at the end of the run.
+type MetricExpectation struct {
+	// Name of the metric, as exposed by the metrics API
+	Metric string `json:"metric"`
+	// If non-empty, only the series with these labels are included. The
+	// values of the included series are summed.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Inclusive bounds of the value. At least one must be provided.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// Why the expectation should hold, to help diagnose drift
+	Description string `json:"description,omitempty"`
+}
+
+// MetricsBaseline is the content of a metrics baseline file.
+type MetricsBaseline struct {
+	Version      int                 `json:"version"`
+	Expectations []MetricExpectation `json:"expectations"`
+}
+
+// ParseMetricsBaseline parses and validates the json content of a metrics
+// baseline file.
+func ParseMetricsBaseline(baselineBytes []byte) (*MetricsBaseline, error) {
+	baseline := &MetricsBaseline{}
+	if err := json.Unmarshal(baselineBytes, baseline); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metrics baseline: %w", err)
+	}
+	if baseline.Version != MetricsBaselineVersion {
+		return nil, fmt.Errorf("%w: %d (expected %d)", errUnsupportedBaselineVersion, baseline.Version, MetricsBaselineVersion)
+	}
+	for i, expectation := range baseline.Expectations {
+		switch {
+		case len(expectation.Metric) == 0:
+			return nil, fmt.Errorf("%w at index %d", errMissingMetricName, i)
+		case expectation.Min == nil && expectation.Max == nil:
+			return nil, fmt.Errorf("%w: %s", errMissingBound, expectation.Metric)
+		case expectation.Min != nil && expectation.Max != nil && *expectation.Min > *expectation.Max:
+			return nil, fmt.Errorf("%w: %s", errInvalidBounds, expectation.Metric)
+		}
+	}
+	return baseline, nil
+}
+
+// DeclareMetricsBaseline declares that the metrics of the nodes of the shared
+// network must match the baseline with the given json content at the end of
+// the run. It's intended to be called when the spec tree is constructed, for
+// example with a baseline file embedded by the package defining the specs:
+//
+//	//go:embed metrics_baseline.json
+//	var metricsBaseline []byte
+//
+//	var _ = e2e.DeclareMetricsBaseline("x/transfer", metricsBaseline)
+//
+// Panics if the baseline is invalid or if a baseline was already declared
+// with the same name, since either indicates a mistake in the test suite.
+func DeclareMetricsBaseline(name string, baselineBytes []byte) bool {
+	if _, ok := declaredBaselines[name]; ok {
+		panic(fmt.Errorf("%w: %s", errDuplicateBaseline, name))
+	}
+	baseline, err := ParseMetricsBaseline(baselineBytes)
+	if err != nil {
+		panic(fmt.Errorf("invalid metrics baseline %s: %w", name, err))
+	}
+	declaredBaselines[name] = baseline
+	return true
+}
+
+// MetricObservation is the value of a metric on a node, compared to the
+// expectation of a baseline.
+type MetricObservation struct {
+	Baseline    string            `json:"baseline"`
+	Metric      string            `json:"metric"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	NodeID      ids.NodeID        `json:"nodeID"`
+	Value       float64           `json:"value"`
+	Min         *float64          `json:"min,omitempty"`
+	Max         *float64          `json:"max,omitempty"`
+	Description string            `json:"description,omitempty"`
+	// False if the node doesn't report the metric at all
+	Reported bool `json:"reported"`
+	// True if the metric isn't reported or its value is out of bounds
+	Drifted bool `json:"drifted"`
+}
+
+func (o MetricObservation) String() string {
+	bounds := make([]string, 0, 2)
+	if o.Min != nil {
+		bounds = append(bounds, fmt.Sprintf("min %g", *o.Min))
+	}
+	if o.Max != nil {
+		bounds = append(bounds, fmt.Sprintf("max %g", *o.Max))
+	}
+	value := fmt.Sprintf("%g", o.Value)
+	if !o.Reported {
+		value = "not reported"
+	}
+	return fmt.Sprintf("%s: %s%s on node %s is %s (expected %s)",
+		o.Baseline,
+		o.Metric,
+		formatLabels(o.Labels),
+		o.NodeID,
+		value,
+		strings.Join(bounds, ", "),
+	)
+}
+
+// MetricsDriftReport compares the metrics of nodes to the expectations of
+// baselines.
+type MetricsDriftReport struct {
+	// Sorted by baseline, metric, labels and node ID so that the reports of
+	// successive runs can be diffed.
+	Observations []MetricObservation `json:"observations"`
+}
+
+// Drifted returns the observations that didn't match their baselines.
+func (r *MetricsDriftReport) Drifted() []MetricObservation {
+	var drifted []MetricObservation
+	for _, observation := range r.Observations {
+		if observation.Drifted {
+			drifted = append(drifted, observation)
+		}
+	}
+	return drifted
+}
+
+// CheckMetricsBaselines scrapes the metrics of the nodes at [uris] and
+// compares them to [baselines], keyed by the name of the baseline.
+func CheckMetricsBaselines(
+	ctx context.Context,
+	uris []tmpnet.NodeURI,
+	baselines map[string]*MetricsBaseline,
+) (*MetricsDriftReport, error) {
+	report := &MetricsDriftReport{}
+	for _, uri := range uris {
+		families, err := scrapeMetrics(ctx, uri.URI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape metrics of node %s: %w", uri.NodeID, err)
+		}
+		for name, baseline := range baselines {
+			for _, expectation := range baseline.Expectations {
+				report.Observations = append(report.Observations, observe(name, expectation, uri.NodeID, families))
+			}
+		}
+	}
+
+	slices.SortStableFunc(report.Observations, func(a, b MetricObservation) bool {
+		switch {
+		case a.Baseline != b.Baseline:
+			return a.Baseline < b.Baseline
+		case a.Metric != b.Metric:
+			return a.Metric < b.Metric
+		}
+		aLabels, bLabels := formatLabels(a.Labels), formatLabels(b.Labels)
+		if aLabels != bLabels {
+			return aLabels < bLabels
+		}
+		return a.NodeID.Less(b.NodeID)
+	})
+	return report, nil
+}
+
+// DeclaredMetricsBaselines returns the baselines declared with
+// DeclareMetricsBaseline.
+func DeclaredMetricsBaselines() map[string]*MetricsBaseline {
+	return maps.Clone(declaredBaselines)
+}
+
+func observe(
+	baselineName string,
+	expectation MetricExpectation,
+	nodeID ids.NodeID,
+	families map[string]*dto.MetricFamily,
+) MetricObservation {
+	observation := MetricObservation{
+		Baseline:    baselineName,
+		Metric:      expectation.Metric,
+		Labels:      expectation.Labels,
+		NodeID:      nodeID,
+		Min:         expectation.Min,
+		Max:         expectation.Max,
+		Description: expectation.Description,
+	}
+
+	family, ok := families[expectation.Metric]
+	if !ok {
+		// A metric that isn't reported is most likely misnamed, so it's
+		// treated as drift rather than as zero.
+		observation.Drifted = true
+		return observation
+	}
+	observation.Reported = true
+	for _, metric := range family.GetMetric() {
+		if hasLabels(metric, expectation.Labels) {
+			observation.Value += metricValue(metric)
+		}
+	}
+	observation.Drifted = (expectation.Min != nil && observation.Value < *expectation.Min) ||
+		(expectation.Max != nil && observation.Value > *expectation.Max)
+	return observation
+}
+
+func scrapeMetrics(ctx context.Context, uri string) (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri+metricsPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// hasLabels returns true if [metric] has every label in [labels].
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, label := range metric.GetLabel() {
+		if value, ok := labels[label.GetName()]; ok {
+			if value != label.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
+
+// metricValue returns the value of a counter, gauge or untyped metric, or the
+// number of observations of a histogram or summary.
+func metricValue(metric *dto.Metric) float64 {
+	switch {
+	case metric.Counter != nil:
+		return metric.Counter.GetValue()
+	case metric.Gauge != nil:
+		return metric.Gauge.GetValue()
+	case metric.Untyped != nil:
+		return metric.Untyped.GetValue()
+	case metric.Histogram != nil:
+		return float64(metric.Histogram.GetSampleCount())
+	case metric.Summary != nil:
+		return float64(metric.Summary.GetSampleCount())
+	default:
+		return 0
+	}
+}
+
+// formatLabels formats [labels] like prometheus does, in a deterministic order.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := maps.Keys(labels)
+	slices.Sort(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%q", name, labels[name])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
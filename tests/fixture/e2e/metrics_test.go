@@ -0,0 +1,137 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+)
+
+func TestParseMetricsBaseline(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseline    string
+		expectedErr error
+	}{
+		{
+			name:     "valid",
+			baseline: `{"version": 1, "expectations": [{"metric": "a", "min": 1}, {"metric": "b", "min": 0, "max": 0}]}`,
+		},
+		{
+			name:        "unsupported version",
+			baseline:    `{"version": 2, "expectations": []}`,
+			expectedErr: errUnsupportedBaselineVersion,
+		},
+		{
+			name:        "missing metric name",
+			baseline:    `{"version": 1, "expectations": [{"max": 1}]}`,
+			expectedErr: errMissingMetricName,
+		},
+		{
+			name:        "missing bound",
+			baseline:    `{"version": 1, "expectations": [{"metric": "a"}]}`,
+			expectedErr: errMissingBound,
+		},
+		{
+			name:        "min exceeds max",
+			baseline:    `{"version": 1, "expectations": [{"metric": "a", "min": 2, "max": 1}]}`,
+			expectedErr: errInvalidBounds,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseMetricsBaseline([]byte(test.baseline))
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestCheckMetricsBaselines(t *testing.T) {
+	require := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != metricsPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`# TYPE peers gauge
+peers 4
+# TYPE msgs counter
+msgs{op="get"} 3
+msgs{op="put"} 5
+# TYPE latency histogram
+latency_bucket{le="+Inf"} 7
+latency_sum 1.5
+latency_count 7
+`))
+	}))
+	defer server.Close()
+
+	baseline, err := ParseMetricsBaseline([]byte(`{
+  "version": 1,
+  "expectations": [
+    {"metric": "peers", "min": 1},
+    {"metric": "msgs", "labels": {"op": "get"}, "max": 3},
+    {"metric": "msgs", "max": 3},
+    {"metric": "latency", "min": 7, "max": 7},
+    {"metric": "unknown", "max": 0}
+  ]
+}`))
+	require.NoError(err)
+
+	nodeID := ids.GenerateTestNodeID()
+	report, err := CheckMetricsBaselines(
+		context.Background(),
+		[]tmpnet.NodeURI{{NodeID: nodeID, URI: server.URL}},
+		map[string]*MetricsBaseline{"test": baseline},
+	)
+	require.NoError(err)
+
+	values := map[string]float64{}
+	drifted := map[string]bool{}
+	for _, observation := range report.Observations {
+		require.Equal(nodeID, observation.NodeID)
+		key := observation.Metric + formatLabels(observation.Labels)
+		values[key] = observation.Value
+		drifted[key] = observation.Drifted
+	}
+	require.Equal(map[string]float64{
+		"latency":        7,
+		"msgs":           8,
+		`msgs{op="get"}`: 3,
+		"peers":          4,
+		"unknown":        0,
+	}, values)
+	require.Equal(map[string]bool{
+		"latency":        false,
+		"msgs":           true,
+		`msgs{op="get"}`: false,
+		"peers":          false,
+		"unknown":        true,
+	}, drifted)
+
+	// Observations are sorted by metric then labels
+	require.Equal("latency", report.Observations[0].Metric)
+	require.Empty(report.Observations[1].Labels)
+	require.Len(report.Drifted(), 2)
+}
+
+func TestCheckMetricsBaselinesUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := CheckMetricsBaselines(
+		context.Background(),
+		[]tmpnet.NodeURI{{NodeID: ids.GenerateTestNodeID(), URI: server.URL}},
+		map[string]*MetricsBaseline{},
+	)
+	require.ErrorIs(t, err, errUnexpectedStatus)
+}
@@ -0,0 +1,409 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package e2e
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"go/format"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/coreth/core/types"
+	"github.com/ava-labs/coreth/ethclient"
+	"github.com/ava-labs/coreth/interfaces"
+	"github.com/ava-labs/coreth/plugin/evm"
+
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet/local"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/avm"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+)
+
+// Directory used to store the reproductions of failed specs under the shared
+// network dir.
+const ReproDirName = "repro"
+
+// ReproOp is a fixture operation that can be replayed.
+type ReproOp string
+
+const (
+	// A node was added to the network with AddEphemeralNode.
+	ReproOpAddNode ReproOp = "addNode"
+	// A node was waited on to become healthy with WaitForHealthy.
+	ReproOpWaitForHealthy ReproOp = "waitForHealthy"
+	// A transaction was issued by a wallet created with NewWallet.
+	ReproOpIssueTx ReproOp = "issueTx"
+	// An eth transaction was sent with SendEthTransaction.
+	ReproOpSendEthTx ReproOp = "sendEthTx"
+	// A condition was waited on with Eventually. Conditions can't be
+	// replayed, so waits are only described.
+	ReproOpWait ReproOp = "wait"
+)
+
+// ReproStep is a fixture operation performed by a spec.
+type ReproStep struct {
+	Op ReproOp `json:"op"`
+	// The node that was added or waited on
+	NodeID ids.NodeID `json:"nodeID,omitempty"`
+	// The flags of the node that was added
+	Flags tmpnet.FlagsMap `json:"flags,omitempty"`
+	// The chain the transaction was issued to
+	ChainID ids.ID `json:"chainID,omitempty"`
+	// Hex encoded bytes of the transaction that was issued or sent
+	TxBytes string `json:"txBytes,omitempty"`
+	// The maximum duration of the wait
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// The message reported if the wait timed out
+	Message string `json:"message,omitempty"`
+}
+
+// Repro is the sequence of fixture operations performed by a failed spec.
+type Repro struct {
+	Spec       string      `json:"spec"`
+	Failure    string      `json:"failure"`
+	Location   string      `json:"location"`
+	NetworkDir string      `json:"networkDir"`
+	Steps      []ReproStep `json:"steps"`
+}
+
+// reproRecorder records the fixture operations of the current spec. Ginkgo
+// runs the specs of a process one at a time, so a single recorder per process
+// is sufficient.
+type reproRecorder struct {
+	lock  sync.Mutex
+	steps []ReproStep
+}
+
+var recorder = &reproRecorder{}
+
+func (r *reproRecorder) record(step ReproStep) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.steps = append(r.steps, step)
+}
+
+// snapshot returns a copy of the steps recorded so far.
+func (r *reproRecorder) snapshot() []ReproStep {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return slices.Clone(r.steps)
+}
+
+// reset returns the recorded steps and starts a new recording.
+func (r *reproRecorder) reset() []ReproStep {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	steps := r.steps
+	r.steps = nil
+	return steps
+}
+
+// GoSource returns the formatted source of a Go test that replays the steps of
+// [r] with a ReproReplayer. The test is a skeleton intended to be shrunk by
+// removing steps until the failure no longer reproduces.
+func (r *Repro) GoSource() ([]byte, error) {
+	var (
+		body     strings.Builder
+		addsNode bool
+	)
+	for _, step := range r.Steps {
+		switch step.Op {
+		case ReproOpAddNode:
+			addsNode = true
+			flags := step.Flags
+			if flags == nil {
+				flags = tmpnet.FlagsMap{}
+			}
+			fmt.Fprintf(&body, "r.AddNode(%q, %#v)\n", step.NodeID, flags)
+		case ReproOpWaitForHealthy:
+			fmt.Fprintf(&body, "r.WaitForHealthy(%q)\n", step.NodeID)
+		case ReproOpIssueTx:
+			fmt.Fprintf(&body, "r.IssueTx(%q, %q)\n", step.ChainID, step.TxBytes)
+		case ReproOpSendEthTx:
+			fmt.Fprintf(&body, "r.SendEthTx(%q)\n", step.TxBytes)
+		case ReproOpWait:
+			fmt.Fprintf(&body, "// The spec waited up to %s for: %s\n", step.Timeout, strings.ReplaceAll(step.Message, "\n", " "))
+		default:
+			fmt.Fprintf(&body, "// Unknown operation %q\n", step.Op)
+		}
+	}
+
+	var src strings.Builder
+	src.WriteString("// Code generated by the e2e fixture for a failed spec. Edit it to shrink\n")
+	src.WriteString("// the reproduction.\n\n")
+	src.WriteString("package repro\n\n")
+	src.WriteString("import (\n\"testing\"\n\n")
+	src.WriteString("\"github.com/ava-labs/avalanchego/tests/fixture/e2e\"\n")
+	if addsNode {
+		src.WriteString("\"github.com/ava-labs/avalanchego/tests/fixture/tmpnet\"\n")
+	}
+	src.WriteString(")\n\n")
+	src.WriteString("// TestRepro replays the fixture operations of the spec\n//\n")
+	fmt.Fprintf(&src, "//\t%s\n//\n", r.Spec)
+	src.WriteString("// against a fresh network started from the configuration of the network\n")
+	fmt.Fprintf(&src, "// it ran against. The spec failed at %s with:\n//\n", r.Location)
+	for _, line := range strings.Split(strings.TrimSpace(r.Failure), "\n") {
+		fmt.Fprintf(&src, "//\t%s\n", line)
+	}
+	src.WriteString("func TestRepro(t *testing.T) {\n")
+	fmt.Fprintf(&src, "r := e2e.NewReproReplayer(t, %s)\n", strconv.Quote(r.NetworkDir))
+	src.WriteString(body.String())
+	src.WriteString("}\n")
+	return format.Source([]byte(src.String()))
+}
+
+// ReproT is the subset of [testing.TB] used by a ReproReplayer, so that
+// repros can be replayed by go tests and ginkgo specs alike.
+type ReproT interface {
+	require.TestingT
+	Cleanup(func())
+}
+
+// ReproReplayer replays the steps of a Repro against a fresh network with the
+// genesis, funded keys and node keys of the network the spec ran against.
+// The transactions issued by the spec were accepted by that network, so they
+// can only be replayed against a network that hasn't seen them.
+type ReproReplayer struct {
+	t       ReproT
+	require *require.Assertions
+	network *local.LocalNetwork
+	// The nodes of the network, and the nodes added while replaying keyed by
+	// the ID of the node they replace
+	nodes map[ids.NodeID]tmpnet.Node
+}
+
+// NewReproReplayer starts a fresh network from the configuration recorded at
+// [networkDir] and returns a replayer targeting it. The network is stopped
+// when the test finishes. The avalanchego binary recorded at [networkDir] is
+// used unless AVALANCHEGO_PATH is set.
+func NewReproReplayer(t ReproT, networkDir string) *ReproReplayer {
+	require := require.New(t)
+
+	recorded, err := local.ReadNetwork(networkDir)
+	require.NoError(err)
+	require.NotEmpty(recorded.Nodes, "network contains no nodes")
+
+	ctx, cancel := context.WithTimeout(context.Background(), local.DefaultNetworkStartTimeout)
+	defer cancel()
+	network, err := local.StartNetwork(
+		ctx,
+		os.Stdout,
+		"", // Use the default root dir
+		newReplayNetwork(recorded),
+		0, // The nodes of [recorded] are used
+		0, // The funded keys of [recorded] are used
+	)
+	require.NoError(err)
+	t.Cleanup(func() {
+		require.NoError(network.Stop())
+	})
+
+	r := &ReproReplayer{
+		t:       t,
+		require: require,
+		network: network,
+		nodes:   make(map[ids.NodeID]tmpnet.Node, len(network.Nodes)),
+	}
+	for _, node := range network.Nodes {
+		r.nodes[node.NodeID] = node
+	}
+	return r
+}
+
+// newReplayNetwork returns the configuration of a network that accepts the
+// transactions issued to [recorded] without sharing any of its state. The
+// genesis and funded keys are reused so that the recorded transactions spend
+// the same UTXOs and balances, and the staking and signing keys of the nodes
+// are reused so that the node IDs referenced by the recorded steps and
+// transactions are the same.
+func newReplayNetwork(recorded *local.LocalNetwork) *local.LocalNetwork {
+	network := &local.LocalNetwork{
+		NetworkConfig: tmpnet.NetworkConfig{
+			Genesis:      recorded.Genesis,
+			CChainConfig: recorded.CChainConfig,
+			DefaultFlags: recorded.DefaultFlags,
+			FundedKeys:   recorded.FundedKeys,
+		},
+		LocalConfig: recorded.LocalConfig,
+		Nodes:       make([]*local.LocalNode, 0, len(recorded.Nodes)),
+	}
+	if execPath := os.Getenv(local.AvalancheGoPathEnvName); len(execPath) > 0 {
+		network.ExecPath = execPath
+	}
+	for _, recordedNode := range recorded.Nodes {
+		// The remaining flags of [recordedNode], such as its data dir and
+		// ports, refer to the recorded network.
+		node := local.NewLocalNode("")
+		for _, key := range []string{
+			config.StakingTLSKeyContentKey,
+			config.StakingCertContentKey,
+			config.StakingSignerKeyContentKey,
+		} {
+			node.Flags[key] = recordedNode.Flags[key]
+		}
+		network.Nodes = append(network.Nodes, node)
+	}
+	return network
+}
+
+// Replay replays [steps] in order. It's equivalent to the test returned by
+// [Repro.GoSource].
+func (r *ReproReplayer) Replay(steps []ReproStep) {
+	for _, step := range steps {
+		switch step.Op {
+		case ReproOpAddNode:
+			r.AddNode(step.NodeID.String(), step.Flags)
+		case ReproOpWaitForHealthy:
+			r.WaitForHealthy(step.NodeID.String())
+		case ReproOpIssueTx:
+			r.IssueTx(step.ChainID.String(), step.TxBytes)
+		case ReproOpSendEthTx:
+			r.SendEthTx(step.TxBytes)
+		}
+	}
+}
+
+// AddNode adds an ephemeral node with [flags] that replaces the node
+// [nodeID] in later steps. The node is stopped when the test finishes.
+func (r *ReproReplayer) AddNode(nodeID string, flags tmpnet.FlagsMap) {
+	replacedID, err := ids.NodeIDFromString(nodeID)
+	r.require.NoError(err)
+
+	node, err := r.network.AddEphemeralNode(os.Stdout, flags)
+	r.require.NoError(err)
+	r.t.Cleanup(func() {
+		r.require.NoError(node.Stop())
+	})
+	r.nodes[replacedID] = node
+}
+
+// WaitForHealthy waits for the node [nodeID] to report healthy.
+func (r *ReproReplayer) WaitForHealthy(nodeID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	r.require.NoError(tmpnet.WaitForHealthy(ctx, r.node(nodeID)))
+}
+
+// IssueTx issues the hex encoded transaction [txHex] to the chain [chainID],
+// which must be the P-Chain, X-Chain or C-Chain, and waits for it to be
+// accepted, as the wallet that issued it did.
+func (r *ReproReplayer) IssueTx(chainID string, txHex string) {
+	id, err := ids.FromString(chainID)
+	r.require.NoError(err)
+	txBytes, err := hex.DecodeString(txHex)
+	r.require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+
+	uri := r.URI()
+	if id == constants.PlatformChainID {
+		client := platformvm.NewClient(uri)
+		txID, err := client.IssueTx(ctx, txBytes)
+		r.require.NoError(err)
+		txStatus, err := client.AwaitTxDecided(ctx, txID, DefaultPollingInterval)
+		r.require.NoError(err)
+		r.require.Equal(status.Committed, txStatus.Status, "transaction %s wasn't committed", txID)
+		return
+	}
+
+	infoClient := info.NewClient(uri)
+	xChainID, err := infoClient.GetBlockchainID(ctx, "X")
+	r.require.NoError(err)
+	if id == xChainID {
+		client := avm.NewClient(uri, "X")
+		txID, err := client.IssueTx(ctx, txBytes)
+		r.require.NoError(err)
+		txStatus, err := client.ConfirmTx(ctx, txID, DefaultPollingInterval)
+		r.require.NoError(err)
+		r.require.Equal(choices.Accepted, txStatus, "transaction %s wasn't accepted", txID)
+		return
+	}
+
+	cChainID, err := infoClient.GetBlockchainID(ctx, "C")
+	r.require.NoError(err)
+	r.require.Equal(cChainID, id, "unsupported chain")
+	client := evm.NewCChainClient(uri)
+	txID, err := client.IssueTx(ctx, txBytes)
+	r.require.NoError(err)
+	for {
+		txStatus, err := client.GetAtomicTxStatus(ctx, txID)
+		r.require.NoError(err)
+		if txStatus == evm.Accepted {
+			return
+		}
+		r.require.Equal(evm.Processing, txStatus, "transaction %s wasn't accepted", txID)
+
+		select {
+		case <-time.After(DefaultPollingInterval):
+		case <-ctx.Done():
+			r.require.NoError(ctx.Err())
+		}
+	}
+}
+
+// SendEthTx sends the hex encoded eth transaction [txHex] to the C-Chain and
+// waits for its receipt, as SendEthTransaction did.
+func (r *ReproReplayer) SendEthTx(txHex string) {
+	txBytes, err := hex.DecodeString(txHex)
+	r.require.NoError(err)
+	tx := &types.Transaction{}
+	r.require.NoError(tx.UnmarshalBinary(txBytes))
+
+	nodeAddress := strings.TrimPrefix(strings.TrimPrefix(r.URI(), "http://"), "https://")
+	client, err := ethclient.Dial(fmt.Sprintf("ws://%s/ext/bc/C/ws", nodeAddress))
+	r.require.NoError(err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	r.require.NoError(client.SendTransaction(ctx, tx))
+
+	for {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			r.require.Equal(types.ReceiptStatusSuccessful, receipt.Status)
+			return
+		}
+		r.require.ErrorIs(err, interfaces.NotFound)
+
+		select {
+		case <-time.After(DefaultPollingInterval):
+		case <-ctx.Done():
+			r.require.NoError(ctx.Err())
+		}
+	}
+}
+
+// URI returns the URI of a node of the fresh network that wasn't added while
+// replaying.
+func (r *ReproReplayer) URI() string {
+	return r.network.Nodes[0].URI
+}
+
+func (r *ReproReplayer) node(nodeID string) tmpnet.Node {
+	id, err := ids.NodeIDFromString(nodeID)
+	r.require.NoError(err)
+	node, ok := r.nodes[id]
+	r.require.True(ok, "unknown node %s", nodeID)
+	return node
+}
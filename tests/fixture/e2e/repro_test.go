@@ -0,0 +1,121 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package e2e
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet"
+	"github.com/ava-labs/avalanchego/tests/fixture/tmpnet/local"
+)
+
+func TestReproGoSource(t *testing.T) {
+	require := require.New(t)
+
+	nodeID := ids.GenerateTestNodeID()
+	chainID := ids.GenerateTestID()
+	repro := &Repro{
+		Spec:       "[X-Chain] transfer",
+		Failure:    "Expected\n    <bool>: false\nto be true",
+		Location:   "/tests/e2e/x/transfer.go:42",
+		NetworkDir: "/tmp/network",
+		Steps: []ReproStep{
+			{
+				Op:     ReproOpAddNode,
+				NodeID: nodeID,
+				Flags:  tmpnet.FlagsMap{"log-level": "debug"},
+			},
+			{
+				Op:     ReproOpWaitForHealthy,
+				NodeID: nodeID,
+			},
+			{
+				Op:      ReproOpIssueTx,
+				ChainID: chainID,
+				TxBytes: "0001",
+			},
+			{
+				Op:      ReproOpWait,
+				Timeout: time.Minute,
+				Message: "failed to see\nthe transfer",
+			},
+		},
+	}
+
+	src, err := repro.GoSource()
+	require.NoError(err)
+	_, err = parser.ParseFile(token.NewFileSet(), "repro_test.go", src, parser.ParseComments)
+	require.NoError(err)
+
+	source := string(src)
+	require.Contains(source, `e2e.NewReproReplayer(t, "/tmp/network")`)
+	require.Contains(source, `r.AddNode("`+nodeID.String()+`", tmpnet.FlagsMap{"log-level": "debug"})`)
+	require.Contains(source, `r.WaitForHealthy("`+nodeID.String()+`")`)
+	require.Contains(source, `r.IssueTx("`+chainID.String()+`", "0001")`)
+	require.Contains(source, "// The spec waited up to 1m0s for: failed to see the transfer")
+
+	// The tmpnet import is only needed if a node is added.
+	repro.Steps = repro.Steps[2:]
+	src, err = repro.GoSource()
+	require.NoError(err)
+	require.NotContains(string(src), "tests/fixture/tmpnet")
+}
+
+func TestReproRecorderReset(t *testing.T) {
+	require := require.New(t)
+
+	r := &reproRecorder{}
+	step := ReproStep{
+		Op:      ReproOpSendEthTx,
+		TxBytes: "02",
+	}
+	r.record(step)
+	require.Equal([]ReproStep{step}, r.reset())
+	require.Empty(r.reset())
+}
+
+func TestNewReplayNetwork(t *testing.T) {
+	require := require.New(t)
+
+	recorded := &local.LocalNetwork{
+		LocalConfig: local.LocalConfig{
+			ExecPath: "/recorded/avalanchego",
+		},
+		Dir: t.TempDir(),
+	}
+	require.NoError(recorded.PopulateLocalNetworkConfig(1337, 2, 1))
+	require.NoError(recorded.WriteAll())
+	recorded, err := local.ReadNetwork(recorded.Dir)
+	require.NoError(err)
+
+	t.Setenv(local.AvalancheGoPathEnvName, "/replay/avalanchego")
+	network := newReplayNetwork(recorded)
+	network.Dir = t.TempDir()
+	require.NoError(network.PopulateLocalNetworkConfig(recorded.Genesis.NetworkID, 0, 0))
+
+	// The recorded transactions are valid on the replay network...
+	require.Equal(recorded.Genesis, network.Genesis)
+	require.Equal(recorded.FundedKeys, network.FundedKeys)
+	require.Equal("/replay/avalanchego", network.ExecPath)
+
+	// ...and its nodes have the recorded node IDs but none of their state.
+	require.Len(network.Nodes, len(recorded.Nodes))
+	recordedNodes := make(map[ids.NodeID]*local.LocalNode, len(recorded.Nodes))
+	for _, node := range recorded.Nodes {
+		recordedNodes[node.NodeID] = node
+	}
+	for _, node := range network.Nodes {
+		recordedNode, ok := recordedNodes[node.NodeID]
+		require.True(ok)
+		require.NotEqual(recordedNode.GetDataDir(), node.GetDataDir())
+		require.Equal(filepath.Join(network.Dir, node.NodeID.String()), node.GetDataDir())
+	}
+}
@@ -47,6 +47,7 @@ func main() {
 
 	var (
 		rootDir        string
+		runID          string
 		execPath       string
 		nodeCount      uint8
 		fundedKeyCount uint8
@@ -59,7 +60,11 @@ func main() {
 				return errAvalancheGoRequired
 			}
 
-			// Root dir will be defaulted on start if not provided
+			// Root dir will be defaulted if not provided
+			runRootDir, err := local.GetRunRootDir(rootDir, runID)
+			if err != nil {
+				return err
+			}
 
 			network := &local.LocalNetwork{
 				LocalConfig: local.LocalConfig{
@@ -68,7 +73,7 @@ func main() {
 			}
 			ctx, cancel := context.WithTimeout(context.Background(), local.DefaultNetworkStartTimeout)
 			defer cancel()
-			network, err := local.StartNetwork(ctx, os.Stdout, rootDir, network, int(nodeCount), int(fundedKeyCount))
+			network, err = local.StartNetwork(ctx, os.Stdout, runRootDir, network, int(nodeCount), int(fundedKeyCount))
 			if err != nil {
 				return err
 			}
@@ -93,6 +98,7 @@ func main() {
 		},
 	}
 	startNetworkCmd.PersistentFlags().StringVar(&rootDir, "root-dir", os.Getenv(local.RootDirEnvName), "The path to the root directory for local networks")
+	startNetworkCmd.PersistentFlags().StringVar(&runID, "run-id", os.Getenv(local.RunIDEnvName), "[optional] The ID of the run starting the network. If provided, the network will be stored under [root-dir]/[run-id]")
 	startNetworkCmd.PersistentFlags().StringVar(&execPath, "avalanchego-path", os.Getenv(local.AvalancheGoPathEnvName), "The path to an avalanchego binary")
 	startNetworkCmd.PersistentFlags().Uint8Var(&nodeCount, "node-count", tmpnet.DefaultNodeCount, "Number of nodes the network should initially consist of")
 	startNetworkCmd.PersistentFlags().Uint8Var(&fundedKeyCount, "funded-key-count", tmpnet.DefaultFundedKeyCount, "Number of funded keys the network should start with")
@@ -116,6 +122,47 @@ func main() {
 	stopNetworkCmd.PersistentFlags().StringVar(&networkDir, "network-dir", os.Getenv(local.NetworkDirEnvName), "The path to the configuration directory of a local network")
 	rootCmd.AddCommand(stopNetworkCmd)
 
+	var (
+		topologyNetworkDir string
+		dotPath            string
+	)
+	topologyCmd := &cobra.Command{
+		Use:   "topology",
+		Short: "Print the topology of a local network as JSON",
+		RunE: func(*cobra.Command, []string) error {
+			if len(topologyNetworkDir) == 0 {
+				return errNetworkDirRequired
+			}
+			network, err := local.ReadNetwork(topologyNetworkDir)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tmpnet.DefaultTopologyTimeout)
+			defer cancel()
+			topology := tmpnet.GetTopology(ctx, network)
+
+			topologyBytes, err := tmpnet.DefaultJSONMarshal(topology)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "%s\n", topologyBytes)
+
+			if len(dotPath) == 0 {
+				return nil
+			}
+			dotFile, err := os.Create(dotPath)
+			if err != nil {
+				return err
+			}
+			defer dotFile.Close()
+			return topology.WriteDOT(dotFile)
+		},
+	}
+	topologyCmd.PersistentFlags().StringVar(&topologyNetworkDir, "network-dir", os.Getenv(local.NetworkDirEnvName), "The path to the configuration directory of a local network")
+	topologyCmd.PersistentFlags().StringVar(&dotPath, "dot-path", "", "[optional] The path to write a graphviz rendering of the topology to")
+	rootCmd.AddCommand(topologyCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "tmpnetctl failed: %v\n", err)
 		os.Exit(1)
@@ -16,6 +16,7 @@ const (
 	AvalancheGoPathEnvName = "AVALANCHEGO_PATH"
 	NetworkDirEnvName      = "TMPNET_NETWORK_DIR"
 	RootDirEnvName         = "TMPNET_ROOT_DIR"
+	RunIDEnvName           = "TMPNET_RUN_ID"
 
 	DefaultNetworkStartTimeout = 2 * time.Minute
 	DefaultNodeInitTimeout     = 10 * time.Second
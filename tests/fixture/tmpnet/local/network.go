@@ -32,6 +32,9 @@ const (
 	networkHealthCheckInterval = 200 * time.Millisecond
 
 	defaultEphemeralDirName = "ephemeral"
+
+	// The first network ID considered for networks that don't specify one.
+	firstNetworkID uint32 = 1000
 )
 
 var (
@@ -40,6 +43,7 @@ var (
 	errLocalNetworkDirNotSet = errors.New("local network directory not set - has Create() been called?")
 	errInvalidNetworkDir     = errors.New("failed to write local network: invalid network directory")
 	errMissingBootstrapNodes = errors.New("failed to add node due to missing bootstrap nodes")
+	errInvalidRunID          = errors.New("invalid run ID")
 )
 
 // Default root dir for storing networks and their configuration.
@@ -51,23 +55,73 @@ func GetDefaultRootDir() (string, error) {
 	return filepath.Join(homeDir, ".tmpnet", "networks"), nil
 }
 
-// Find the next available network ID by attempting to create a
-// directory numbered from 1000 until creation succeeds. Returns the
-// network id and the full path of the created directory.
+// Dir in which network IDs are reserved. The dir is shared by all local
+// networks of the current user, regardless of the root dir they are
+// stored in, to ensure that concurrently running networks (e.g. those of
+// parallel test runs) never share a network ID.
+func GetNetworkIDsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".tmpnet", "network_ids"), nil
+}
+
+// Returns the dir in which the networks of the run identified by [runID]
+// are stored (i.e. [rootDir]/[runID]). Namespacing networks by run allows
+// the networks of concurrent runs (e.g. CI shards) sharing a root dir to be
+// located and cleaned up independently. If [rootDir] is empty, the default
+// root dir is used. If [runID] is empty, [rootDir] is returned unchanged.
+func GetRunRootDir(rootDir string, runID string) (string, error) {
+	if len(runID) == 0 {
+		return rootDir, nil
+	}
+	if runID == "." || runID == ".." || filepath.Base(runID) != runID {
+		return "", fmt.Errorf("%w %q: must be usable as a directory name", errInvalidRunID, runID)
+	}
+	if len(rootDir) == 0 {
+		var err error
+		rootDir, err = GetDefaultRootDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(rootDir, runID), nil
+}
+
+// Find the next available network ID by attempting to reserve IDs
+// numbered from 1000 until reservation succeeds and a directory
+// for the ID can be created under [rootDir]. Returns the network id
+// and the full path of the created directory.
 func FindNextNetworkID(rootDir string) (uint32, string, error) {
-	var (
-		networkID uint32 = 1000
-		dirPath   string
-	)
-	for {
-		_, reserved := constants.NetworkIDToNetworkName[networkID]
-		if reserved {
-			networkID++
+	networkIDsDir, err := GetNetworkIDsDir()
+	if err != nil {
+		return 0, "", err
+	}
+	return findNextNetworkID(networkIDsDir, rootDir)
+}
+
+func findNextNetworkID(networkIDsDir string, rootDir string) (uint32, string, error) {
+	if err := os.MkdirAll(networkIDsDir, perms.ReadWriteExecute); err != nil {
+		return 0, "", fmt.Errorf("failed to create network IDs dir: %w", err)
+	}
+
+	for networkID := firstNetworkID; ; networkID++ {
+		if _, ok := constants.NetworkIDToNetworkName[networkID]; ok {
 			continue
 		}
 
-		dirPath = filepath.Join(rootDir, strconv.FormatUint(uint64(networkID), 10))
-		err := os.Mkdir(dirPath, perms.ReadWriteExecute)
+		dirPath := filepath.Join(rootDir, strconv.FormatUint(uint64(networkID), 10))
+		reserved, err := reserveNetworkID(networkIDsDir, networkID, dirPath)
+		if err != nil {
+			return 0, "", err
+		}
+		if !reserved {
+			// ID is in use by another network, keep iterating
+			continue
+		}
+
+		err = os.Mkdir(dirPath, perms.ReadWriteExecute)
 		if err == nil {
 			return networkID, dirPath, nil
 		}
@@ -76,9 +130,56 @@ func FindNextNetworkID(rootDir string) (uint32, string, error) {
 			return 0, "", fmt.Errorf("failed to create network directory: %w", err)
 		}
 
-		// Directory already exists, keep iterating
-		networkID++
+		// Directory already exists (e.g. it was created before network IDs
+		// were reserved), keep iterating
+	}
+}
+
+// Attempts to reserve [networkID] for the network stored at [networkDir] by
+// exclusively creating a file named for the ID in [networkIDsDir]. Returns
+// false if the ID was already reserved. Reservations are released when their
+// networks are stopped.
+func reserveNetworkID(networkIDsDir string, networkID uint32, networkDir string) (bool, error) {
+	path := filepath.Join(networkIDsDir, strconv.FormatUint(uint64(networkID), 10))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perms.ReadWrite)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve network ID %d: %w", networkID, err)
+	}
+
+	// Record the network dir to simplify identifying the owner of an ID
+	_, err = file.WriteString(networkDir)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write reservation of network ID %d: %w", networkID, err)
+	}
+	return true, nil
+}
+
+// Releases the reservation of [networkID] in [networkIDsDir] if it's held by
+// the network stored at [networkDir], so that the ID can be reused by
+// subsequently started networks. Reservations held by other networks are
+// left in place.
+func releaseNetworkID(networkIDsDir string, networkID uint32, networkDir string) error {
+	path := filepath.Join(networkIDsDir, strconv.FormatUint(uint64(networkID), 10))
+	reservation, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read reservation of network ID %d: %w", networkID, err)
 	}
+	if string(reservation) != networkDir {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to release network ID %d: %w", networkID, err)
+	}
+	return nil
 }
 
 // Defines the configuration required for a local network (i.e. one composed of local processes).
@@ -437,7 +538,17 @@ func (ln *LocalNetwork) Stop() error {
 	if len(errs) > 0 {
 		return fmt.Errorf("failed to stop network:\n%w", errors.Join(errs...))
 	}
-	return nil
+	if ln.Genesis == nil {
+		return nil
+	}
+
+	// The network's processes are no longer running, so its ID can be
+	// reused by other networks.
+	networkIDsDir, err := GetNetworkIDsDir()
+	if err != nil {
+		return err
+	}
+	return releaseNetworkID(networkIDsDir, ln.Genesis.NetworkID, ln.Dir)
 }
 
 func (ln *LocalNetwork) GetGenesisPath() string {
@@ -4,9 +4,14 @@
 package local
 
 import (
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/utils/perms"
 )
 
 func TestNetworkSerialization(t *testing.T) {
@@ -27,3 +32,110 @@ func TestNetworkSerialization(t *testing.T) {
 	}
 	require.Equal(network, loadedNetwork)
 }
+
+func TestFindNextNetworkID(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		networkIDsDir = t.TempDir()
+		rootDir1      = t.TempDir()
+		rootDir2      = t.TempDir()
+	)
+
+	networkID, networkDir, err := findNextNetworkID(networkIDsDir, rootDir1)
+	require.NoError(err)
+	require.Equal(firstNetworkID, networkID)
+	require.Equal(filepath.Join(rootDir1, "1000"), networkDir)
+	require.DirExists(networkDir)
+
+	// Networks in different root dirs don't share network IDs
+	networkID, networkDir, err = findNextNetworkID(networkIDsDir, rootDir2)
+	require.NoError(err)
+	require.Equal(firstNetworkID+1, networkID)
+	require.Equal(filepath.Join(rootDir2, "1001"), networkDir)
+
+	// The reservation records the network dir
+	reservation, err := os.ReadFile(filepath.Join(networkIDsDir, "1001"))
+	require.NoError(err)
+	require.Equal(networkDir, string(reservation))
+
+	// Unreserved IDs are skipped if their network dir already exists
+	require.NoError(os.Mkdir(filepath.Join(rootDir1, "1002"), perms.ReadWriteExecute))
+	networkID, _, err = findNextNetworkID(networkIDsDir, rootDir1)
+	require.NoError(err)
+	require.Equal(firstNetworkID+3, networkID)
+}
+
+func TestReleaseNetworkID(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		networkIDsDir = t.TempDir()
+		rootDir1      = t.TempDir()
+		rootDir2      = t.TempDir()
+	)
+
+	networkID, networkDir, err := findNextNetworkID(networkIDsDir, rootDir1)
+	require.NoError(err)
+	require.Equal(firstNetworkID, networkID)
+
+	// A reservation held by another network isn't released
+	require.NoError(releaseNetworkID(networkIDsDir, networkID, filepath.Join(rootDir2, "1000")))
+	require.FileExists(filepath.Join(networkIDsDir, "1000"))
+
+	require.NoError(releaseNetworkID(networkIDsDir, networkID, networkDir))
+	require.NoFileExists(filepath.Join(networkIDsDir, "1000"))
+
+	// Releasing an unreserved ID is a no-op
+	require.NoError(releaseNetworkID(networkIDsDir, networkID, networkDir))
+
+	// The released ID is reused
+	networkID, _, err = findNextNetworkID(networkIDsDir, rootDir2)
+	require.NoError(err)
+	require.Equal(firstNetworkID, networkID)
+}
+
+func TestStopNetworkReleasesNetworkID(t *testing.T) {
+	require := require.New(t)
+
+	// Network IDs are reserved in the home dir
+	t.Setenv("HOME", t.TempDir())
+
+	networkID, networkDir, err := FindNextNetworkID(t.TempDir())
+	require.NoError(err)
+
+	network := &LocalNetwork{Dir: networkDir}
+	require.NoError(network.PopulateLocalNetworkConfig(networkID, 1, 1))
+	require.NoError(network.WriteAll())
+
+	networkIDsDir, err := GetNetworkIDsDir()
+	require.NoError(err)
+	reservationPath := filepath.Join(networkIDsDir, strconv.FormatUint(uint64(networkID), 10))
+	require.FileExists(reservationPath)
+
+	require.NoError(StopNetwork(networkDir))
+	require.NoFileExists(reservationPath)
+}
+
+func TestGetRunRootDir(t *testing.T) {
+	require := require.New(t)
+
+	rootDir, err := GetRunRootDir("/root", "")
+	require.NoError(err)
+	require.Equal("/root", rootDir)
+
+	rootDir, err = GetRunRootDir("/root", "shard-1")
+	require.NoError(err)
+	require.Equal(filepath.Join("/root", "shard-1"), rootDir)
+
+	defaultRootDir, err := GetDefaultRootDir()
+	require.NoError(err)
+	rootDir, err = GetRunRootDir("", "shard-1")
+	require.NoError(err)
+	require.Equal(filepath.Join(defaultRootDir, "shard-1"), rootDir)
+
+	for _, runID := range []string{".", "..", "a/b"} {
+		_, err = GetRunRootDir("/root", runID)
+		require.ErrorIs(err, errInvalidRunID)
+	}
+}
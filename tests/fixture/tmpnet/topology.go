@@ -0,0 +1,181 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tmpnet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api/info"
+	"github.com/ava-labs/avalanchego/config"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm"
+)
+
+// Maximum duration to wait for the nodes of a network to report their state.
+const DefaultTopologyTimeout = 30 * time.Second
+
+// Topology is a snapshot of the state of the nodes of a network, intended to
+// help diagnose test failures involving multiple nodes.
+type Topology struct {
+	NetworkID uint32         `json:"networkID"`
+	Nodes     []NodeTopology `json:"nodes"`
+}
+
+// NodeTopology is the state of a node as reported by its API.
+type NodeTopology struct {
+	NodeID         ids.NodeID    `json:"nodeID"`
+	URI            string        `json:"uri"`
+	Healthy        bool          `json:"healthy"`
+	Version        string        `json:"version,omitempty"`
+	Peers          []ids.NodeID  `json:"peers"`
+	TrackedSubnets []string      `json:"trackedSubnets"`
+	Chains         []ChainStatus `json:"chains"`
+	// Errors encountered querying the node. A node that isn't running can't
+	// be queried, so its state is incomplete.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ChainStatus is the status of a chain on a node.
+type ChainStatus struct {
+	ChainID      ids.ID `json:"chainID"`
+	Name         string `json:"name"`
+	SubnetID     ids.ID `json:"subnetID"`
+	Bootstrapped bool   `json:"bootstrapped"`
+}
+
+// GetTopology queries the nodes of [network] for their state. Errors
+// querying a node are recorded in the node's topology rather than returned,
+// so that the topology of a partially failed network can still be reported.
+func GetTopology(ctx context.Context, network Network) *Topology {
+	topology := &Topology{}
+	if genesis := network.GetConfig().Genesis; genesis != nil {
+		topology.NetworkID = genesis.NetworkID
+	}
+	for _, node := range network.GetNodes() {
+		topology.Nodes = append(topology.Nodes, getNodeTopology(ctx, node))
+	}
+	return topology
+}
+
+func getNodeTopology(ctx context.Context, node Node) NodeTopology {
+	nodeTopology := NodeTopology{
+		NodeID:         node.GetID(),
+		URI:            node.GetProcessContext().URI,
+		Peers:          []ids.NodeID{},
+		TrackedSubnets: []string{},
+		Chains:         []ChainStatus{},
+	}
+	addErr := func(err error) {
+		nodeTopology.Errors = append(nodeTopology.Errors, err.Error())
+	}
+
+	if trackedSubnets, err := node.GetConfig().Flags.GetStringVal(config.TrackSubnetsKey); err != nil {
+		addErr(err)
+	} else if len(trackedSubnets) > 0 {
+		nodeTopology.TrackedSubnets = strings.Split(trackedSubnets, ",")
+	}
+
+	healthy, err := node.IsHealthy(ctx)
+	if err != nil {
+		addErr(fmt.Errorf("failed to check health: %w", err))
+	}
+	nodeTopology.Healthy = healthy
+	if len(nodeTopology.URI) == 0 {
+		addErr(ErrNotRunning)
+		return nodeTopology
+	}
+
+	infoClient := info.NewClient(nodeTopology.URI)
+	if version, err := infoClient.GetNodeVersion(ctx); err != nil {
+		addErr(fmt.Errorf("failed to get version: %w", err))
+	} else {
+		nodeTopology.Version = version.Version
+	}
+
+	if peers, err := infoClient.Peers(ctx); err != nil {
+		addErr(fmt.Errorf("failed to get peers: %w", err))
+	} else {
+		for _, peer := range peers {
+			nodeTopology.Peers = append(nodeTopology.Peers, peer.ID)
+		}
+	}
+
+	chains := []ChainStatus{{
+		ChainID:  constants.PlatformChainID,
+		Name:     "P",
+		SubnetID: constants.PrimaryNetworkID,
+	}}
+	if blockchains, err := platformvm.NewClient(nodeTopology.URI).GetBlockchains(ctx); err != nil {
+		addErr(fmt.Errorf("failed to get blockchains: %w", err))
+	} else {
+		for _, blockchain := range blockchains {
+			chains = append(chains, ChainStatus{
+				ChainID:  blockchain.ID,
+				Name:     blockchain.Name,
+				SubnetID: blockchain.SubnetID,
+			})
+		}
+	}
+	for _, chain := range chains {
+		bootstrapped, err := infoClient.IsBootstrapped(ctx, chain.ChainID.String())
+		if err != nil {
+			// Chains of subnets the node doesn't track aren't running on
+			// the node.
+			continue
+		}
+		chain.Bootstrapped = bootstrapped
+		nodeTopology.Chains = append(nodeTopology.Chains, chain)
+	}
+	return nodeTopology
+}
+
+// WriteDOT writes [t] to [w] as a graphviz graph, with an edge between each
+// pair of connected nodes. Nodes that are unhealthy are drawn in red.
+func (t *Topology) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "graph \"network-%d\" {\n", t.NetworkID)
+	for _, node := range t.Nodes {
+		color := "black"
+		if !node.Healthy {
+			color = "red"
+		}
+		numBootstrapped := 0
+		for _, chain := range node.Chains {
+			if chain.Bootstrapped {
+				numBootstrapped++
+			}
+		}
+		fmt.Fprintf(&sb, "  %q [label=%q, color=%s];\n",
+			node.NodeID,
+			fmt.Sprintf("%s\n%s\n%d/%d chains bootstrapped", node.NodeID, node.Version, numBootstrapped, len(node.Chains)),
+			color,
+		)
+	}
+
+	// Connections are reported by both peers, so only draw each once.
+	drawn := set.Set[[2]ids.NodeID]{}
+	for _, node := range t.Nodes {
+		for _, peer := range node.Peers {
+			edge := [2]ids.NodeID{node.NodeID, peer}
+			if peer.Less(node.NodeID) {
+				edge = [2]ids.NodeID{peer, node.NodeID}
+			}
+			if drawn.Contains(edge) {
+				continue
+			}
+			drawn.Add(edge)
+			fmt.Fprintf(&sb, "  %q -- %q;\n", edge[0], edge[1])
+		}
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -3,7 +3,11 @@
 
 package bimap
 
-import "github.com/ava-labs/avalanchego/utils"
+import (
+	"golang.org/x/exp/maps"
+
+	"github.com/ava-labs/avalanchego/utils"
+)
 
 type Entry[K, V any] struct {
 	Key   K
@@ -96,6 +100,11 @@ func (m *BiMap[K, V]) DeleteValue(val V) (K, bool) {
 	return key, true
 }
 
+// Keys returns the keys in this map, in no particular order.
+func (m *BiMap[K, _]) Keys() []K {
+	return maps.Keys(m.keyToValue)
+}
+
 // Len return the number of entries in this map.
 func (m *BiMap[K, V]) Len() int {
 	return len(m.keyToValue)
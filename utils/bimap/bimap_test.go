@@ -308,6 +308,20 @@ func TestBiMapDeleteValue(t *testing.T) {
 	}
 }
 
+func TestBiMapKeys(t *testing.T) {
+	require := require.New(t)
+
+	m := New[int, int]()
+	require.Empty(m.Keys())
+
+	m.Put(1, 2)
+	m.Put(2, 3)
+	require.ElementsMatch([]int{1, 2}, m.Keys())
+
+	m.Put(1, 3)
+	require.Equal([]int{1}, m.Keys())
+}
+
 func TestBiMapLen(t *testing.T) {
 	require := require.New(t)
 
@@ -6,6 +6,7 @@ package wrappers
 import (
 	"encoding/binary"
 	"errors"
+	"io"
 	"math"
 )
 
@@ -24,6 +25,10 @@ const (
 	BoolLen = 1
 	// IPLen is the number of bytes per IP
 	IPLen = 16 + ShortLen
+
+	// minReadSize is the minimum number of bytes read from [Packer.Reader]
+	// at once, to avoid many small reads.
+	minReadSize = 4096
 )
 
 func StringLen(str string) int {
@@ -44,12 +49,25 @@ var (
 type Packer struct {
 	Errs
 
-	// The largest allowed size of expanding the byte array
+	// The largest allowed size of expanding the byte array. If [Writer] or
+	// [Reader] is set, this bounds the total number of bytes written or read.
 	MaxSize int
 	// The current byte array
 	Bytes []byte
 	// The offset that is being written to in the byte array
 	Offset int
+
+	// If non-nil, packed bytes are flushed to [Writer] rather than growing
+	// the byte array beyond its capacity. [Flush] must be called once
+	// packing is done to write the remaining bytes.
+	Writer io.Writer
+	// If non-nil, bytes are read from [Reader] into the byte array as they
+	// are needed to unpack values. Unpacked byte slices may alias the byte
+	// array, so bytes that were read are never overwritten.
+	Reader io.Reader
+
+	// The number of bytes flushed to [Writer]
+	flushed int
 }
 
 // PackByte append a byte to the byte array
@@ -251,11 +269,65 @@ func (p *Packer) checkSpace(bytes int) {
 		p.Add(errNegativeOffset)
 	case bytes < 0:
 		p.Add(errInvalidInput)
+	case len(p.Bytes)-p.Offset < bytes && p.Reader != nil:
+		p.fill(p.Offset + bytes)
 	case len(p.Bytes)-p.Offset < bytes:
 		p.Add(ErrInsufficientLength)
 	}
 }
 
+// fill reads from [Reader] until the byte array is at least [neededSize] bytes
+// long. If this is not possible due to the maximum size or the end of the
+// input, an error is added to the packer.
+func (p *Packer) fill(neededSize int) {
+	if neededSize > p.MaxSize {
+		p.Add(ErrInsufficientLength)
+		return
+	}
+
+	// Read ahead to amortize the cost of reading and growing the byte array
+	size := len(p.Bytes) + minReadSize
+	if doubled := 2 * len(p.Bytes); doubled > size {
+		size = doubled
+	}
+	if neededSize > size {
+		size = neededSize
+	}
+	if size > p.MaxSize {
+		size = p.MaxSize
+	}
+	if size > cap(p.Bytes) {
+		newBytes := make([]byte, len(p.Bytes), size)
+		copy(newBytes, p.Bytes)
+		p.Bytes = newBytes
+	}
+
+	n, err := io.ReadAtLeast(p.Reader, p.Bytes[len(p.Bytes):size], neededSize-len(p.Bytes))
+	p.Bytes = p.Bytes[:len(p.Bytes)+n]
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		p.Add(ErrInsufficientLength)
+	case err != nil:
+		p.Add(err)
+	}
+}
+
+// Flush writes the packed bytes to [Writer] and empties the byte array, so
+// that its capacity is reused for the bytes packed next. Does nothing if
+// [Writer] is nil.
+func (p *Packer) Flush() {
+	if p.Writer == nil || p.Errored() {
+		return
+	}
+	if _, err := p.Writer.Write(p.Bytes[:p.Offset]); err != nil {
+		p.Add(err)
+		return
+	}
+	p.flushed += p.Offset
+	p.Bytes = p.Bytes[:0]
+	p.Offset = 0
+}
+
 // expand ensures that there is [bytes] bytes left of space in the byte slice.
 // If this is not allowed due to the maximum size, an error is added to the packer
 // In order to understand this code, its important to understand the difference
@@ -265,12 +337,17 @@ func (p *Packer) expand(bytes int) {
 	switch {
 	case neededSize <= len(p.Bytes): // Byte slice has sufficient length already
 		return
-	case neededSize > p.MaxSize: // Lengthening the byte slice would cause it to grow too large
+	case p.flushed+neededSize > p.MaxSize: // Lengthening the byte slice would cause it to grow too large
 		p.Err = ErrInsufficientLength
 		return
 	case neededSize <= cap(p.Bytes): // Byte slice has sufficient capacity to lengthen it without mem alloc
 		p.Bytes = p.Bytes[:neededSize]
 		return
+	case p.Writer != nil && p.Offset > 0: // Make room by flushing the packed bytes
+		p.Flush()
+		if !p.Errored() {
+			p.expand(bytes)
+		}
 	default: // Add capacity/length to byte slice
 		p.Bytes = append(p.Bytes[:cap(p.Bytes)], make([]byte, neededSize-cap(p.Bytes))...)
 	}
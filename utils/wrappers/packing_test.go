@@ -4,6 +4,7 @@
 package wrappers
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -56,6 +57,65 @@ func TestPackerExpand(t *testing.T) {
 	require.Equal([]byte{0x01, 0x02, 0x03}, p.Bytes)
 }
 
+func TestPackerWriter(t *testing.T) {
+	require := require.New(t)
+
+	w := &bytes.Buffer{}
+	p := Packer{
+		MaxSize: 8,
+		Bytes:   make([]byte, 0, 3),
+		Writer:  w,
+	}
+	p.PackShort(0x0102)
+	require.False(p.Errored())
+	require.Empty(w.Bytes())
+
+	// Packing beyond the capacity flushes the packed bytes rather than
+	// growing the byte array
+	p.PackShort(0x0304)
+	require.False(p.Errored())
+	require.Equal([]byte{0x01, 0x02}, w.Bytes())
+	require.Equal(3, cap(p.Bytes))
+
+	p.PackInt(0x05060708)
+	require.False(p.Errored())
+
+	// MaxSize bounds the total number of packed bytes
+	p.PackByte(0x09)
+	require.ErrorIs(p.Err, ErrInsufficientLength)
+
+	p.Err = nil
+	p.Flush()
+	require.False(p.Errored())
+	require.Equal([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}, w.Bytes())
+	require.Zero(p.Offset)
+}
+
+func TestPackerReader(t *testing.T) {
+	require := require.New(t)
+
+	p := Packer{
+		MaxSize: 6,
+		Reader:  bytes.NewReader([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07}),
+	}
+	require.Equal(uint16(0x0102), p.UnpackShort())
+	unpacked := p.UnpackFixedBytes(2)
+	require.Equal(uint16(0x0506), p.UnpackShort())
+	require.False(p.Errored())
+	require.Equal([]byte{0x03, 0x04}, unpacked)
+
+	// MaxSize bounds the total number of read bytes
+	p.UnpackByte()
+	require.ErrorIs(p.Err, ErrInsufficientLength)
+
+	p = Packer{
+		MaxSize: 6,
+		Reader:  bytes.NewReader([]byte{0x01}),
+	}
+	p.UnpackShort()
+	require.ErrorIs(p.Err, ErrInsufficientLength)
+}
+
 func TestPackerPackByte(t *testing.T) {
 	require := require.New(t)
 
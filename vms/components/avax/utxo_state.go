@@ -70,6 +70,12 @@ type UTXOWriter interface {
 	DeleteUTXO(utxoID ids.ID) error
 }
 
+// NewUTXOIterator returns an iterator over the UTXOs that a UTXOState over [db]
+// holds, in order of increasing UTXO ID. The values are the serialized UTXOs.
+func NewUTXOIterator(db database.Database) database.Iterator {
+	return prefixdb.New(utxoPrefix, db).NewIterator()
+}
+
 type utxoState struct {
 	codec codec.Manager
 
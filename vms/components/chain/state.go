@@ -459,6 +459,12 @@ func (s *State) BuildBlock(ctx context.Context) (snowman.Block, error) {
 	return s.deduplicate(ctx, blk)
 }
 
+// AddBuiltBlock wraps [blk], which was built by the VM outside of BuildBlock
+// and BuildBlockWithContext, and adds it to the appropriate caching layer.
+func (s *State) AddBuiltBlock(ctx context.Context, blk snowman.Block) (snowman.Block, error) {
+	return s.deduplicate(ctx, blk)
+}
+
 func (s *State) deduplicate(ctx context.Context, blk snowman.Block) (snowman.Block, error) {
 	blkID := blk.ID()
 	// Defensive: buildBlock should not return a block that has already been verified.
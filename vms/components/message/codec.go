@@ -25,6 +25,7 @@ func init() {
 
 	err := utils.Err(
 		lc.RegisterType(&Tx{}),
+		lc.RegisterType(&DelegationOffer{}),
 		c.RegisterCodec(codecVersion, lc),
 	)
 	if err != nil {
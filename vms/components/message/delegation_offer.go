@@ -0,0 +1,20 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import "github.com/ava-labs/avalanchego/ids"
+
+var _ Message = (*DelegationOffer)(nil)
+
+// DelegationOffer gossips a validator's offer to accept delegations. The
+// offer is opaque to this package and is interpreted by the VM.
+type DelegationOffer struct {
+	message
+
+	Offer []byte `serialize:"true"`
+}
+
+func (msg *DelegationOffer) Handle(handler Handler, nodeID ids.NodeID, requestID uint32) error {
+	return handler.HandleDelegationOffer(nodeID, requestID, msg)
+}
@@ -0,0 +1,34 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+func TestDelegationOffer(t *testing.T) {
+	require := require.New(t)
+
+	offer := utils.RandomBytes(units.KiB)
+	builtMsg := DelegationOffer{
+		Offer: offer,
+	}
+	builtMsgBytes, err := Build(&builtMsg)
+	require.NoError(err)
+	require.Equal(builtMsgBytes, builtMsg.Bytes())
+
+	parsedMsgIntf, err := Parse(builtMsgBytes)
+	require.NoError(err)
+	require.Equal(builtMsgBytes, parsedMsgIntf.Bytes())
+
+	require.IsType(&DelegationOffer{}, parsedMsgIntf)
+	parsedMsg := parsedMsgIntf.(*DelegationOffer)
+
+	require.Equal(offer, parsedMsg.Offer)
+}
@@ -14,6 +14,7 @@ var _ Handler = NoopHandler{}
 
 type Handler interface {
 	HandleTx(nodeID ids.NodeID, requestID uint32, msg *Tx) error
+	HandleDelegationOffer(nodeID ids.NodeID, requestID uint32, msg *DelegationOffer) error
 }
 
 type NoopHandler struct {
@@ -27,3 +28,11 @@ func (h NoopHandler) HandleTx(nodeID ids.NodeID, requestID uint32, _ *Tx) error
 	)
 	return nil
 }
+
+func (h NoopHandler) HandleDelegationOffer(nodeID ids.NodeID, requestID uint32, _ *DelegationOffer) error {
+	h.Log.Debug("dropping unexpected DelegationOffer message",
+		zap.Stringer("nodeID", nodeID),
+		zap.Uint32("requestID", requestID),
+	)
+	return nil
+}
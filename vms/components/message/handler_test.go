@@ -13,7 +13,8 @@ import (
 )
 
 type CounterHandler struct {
-	Tx int
+	Tx              int
+	DelegationOffer int
 }
 
 func (h *CounterHandler) HandleTx(ids.NodeID, uint32, *Tx) error {
@@ -21,6 +22,11 @@ func (h *CounterHandler) HandleTx(ids.NodeID, uint32, *Tx) error {
 	return nil
 }
 
+func (h *CounterHandler) HandleDelegationOffer(ids.NodeID, uint32, *DelegationOffer) error {
+	h.DelegationOffer++
+	return nil
+}
+
 func TestHandleTx(t *testing.T) {
 	require := require.New(t)
 
@@ -37,4 +43,5 @@ func TestNoopHandler(t *testing.T) {
 	}
 
 	require.NoError(t, handler.HandleTx(ids.EmptyNodeID, 0, nil))
+	require.NoError(t, handler.HandleDelegationOffer(ids.EmptyNodeID, 0, nil))
 }
@@ -16,6 +16,7 @@ import (
 
 var (
 	_ Message = (*Tx)(nil)
+	_ Message = (*DelegationOffer)(nil)
 
 	ErrUnexpectedCodecVersion = errors.New("unexpected codec version")
 	errUnknownMessageType     = errors.New("unknown message type")
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
-	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/vms/example/xsvm/tx"
 )
 
@@ -26,8 +25,7 @@ func (b *Stateless) Time() time.Time {
 }
 
 func (b *Stateless) ID() (ids.ID, error) {
-	bytes, err := Codec.Marshal(Version, b)
-	return hashing.ComputeHash256Array(bytes), err
+	return Codec.HashOf(Version, b)
 }
 
 func Parse(bytes []byte) (*Stateless, error) {
@@ -7,7 +7,6 @@ import (
 	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
-	"github.com/ava-labs/avalanchego/utils/hashing"
 )
 
 var secpCache = secp256k1.RecoverCache{
@@ -46,8 +45,7 @@ func Sign(utx Unsigned, key *secp256k1.PrivateKey) (*Tx, error) {
 }
 
 func (tx *Tx) ID() (ids.ID, error) {
-	bytes, err := Codec.Marshal(Version, tx)
-	return hashing.ComputeHash256Array(bytes), err
+	return Codec.HashOf(Version, tx)
 }
 
 func (tx *Tx) SenderID() (ids.ShortID, error) {
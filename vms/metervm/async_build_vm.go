@@ -0,0 +1,72 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metervm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+)
+
+var _ block.BlockBuild = (*meterBlockBuild)(nil)
+
+func (vm *blockVM) StartBuildBlock(ctx context.Context, blockCtx *block.Context) block.BlockBuild {
+	if vm.asyncBuildVM == nil {
+		return block.BuildBlockNow(ctx, vm, blockCtx)
+	}
+
+	return &meterBlockBuild{
+		BlockBuild:  vm.asyncBuildVM.StartBuildBlock(ctx, blockCtx),
+		vm:          vm,
+		start:       vm.clock.Time(),
+		withContext: blockCtx != nil && vm.buildBlockVM != nil,
+	}
+}
+
+// meterBlockBuild measures a build from when it was started until its result
+// is first read.
+type meterBlockBuild struct {
+	block.BlockBuild
+
+	vm          *blockVM
+	start       time.Time
+	withContext bool
+
+	once sync.Once
+	blk  snowman.Block
+	err  error
+}
+
+func (b *meterBlockBuild) Result(ctx context.Context) (snowman.Block, error) {
+	b.once.Do(func() {
+		b.blk, b.err = b.result(ctx)
+	})
+	return b.blk, b.err
+}
+
+func (b *meterBlockBuild) result(ctx context.Context) (snowman.Block, error) {
+	blk, err := b.BlockBuild.Result(ctx)
+	end := b.vm.clock.Time()
+	duration := float64(end.Sub(b.start))
+	if err != nil {
+		if b.withContext {
+			b.vm.blockMetrics.buildBlockWithContextErr.Observe(duration)
+		} else {
+			b.vm.blockMetrics.buildBlockErr.Observe(duration)
+		}
+		return nil, err
+	}
+	if b.withContext {
+		b.vm.blockMetrics.buildBlockWithContext.Observe(duration)
+	} else {
+		b.vm.blockMetrics.buildBlock.Observe(duration)
+	}
+	return &meterBlock{
+		Block: blk,
+		vm:    b.vm,
+	}, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metervm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+)
+
+type testAsyncBuildVM struct {
+	*block.TestVM
+
+	started int
+}
+
+func (vm *testAsyncBuildVM) StartBuildBlock(ctx context.Context, blockCtx *block.Context) block.BlockBuild {
+	vm.started++
+	return block.BuildBlockNow(ctx, vm.TestVM, blockCtx)
+}
+
+func TestStartBuildBlock(t *testing.T) {
+	require := require.New(t)
+
+	blk := &snowman.TestBlock{}
+	innerVM := &testAsyncBuildVM{
+		TestVM: &block.TestVM{
+			BuildBlockF: func(context.Context) (snowman.Block, error) {
+				return blk, nil
+			},
+		},
+	}
+	vm := NewBlockVM(innerVM).(*blockVM)
+	require.NoError(vm.blockMetrics.Initialize(false, false, false, "", prometheus.NewRegistry()))
+
+	// The build is started by the wrapped VM and the built block is metered.
+	build := block.StartBuildBlock(context.Background(), vm, nil /*=blockCtx*/)
+	require.Equal(1, innerVM.started)
+
+	builtBlk, err := build.Result(context.Background())
+	require.NoError(err)
+	require.IsType(&meterBlock{}, builtBlk)
+	require.Equal(blk, builtBlk.(*meterBlock).Block)
+
+	// Reading the result again returns the same block.
+	sameBlk, err := build.Result(context.Background())
+	require.NoError(err)
+	require.Equal(builtBlk, sameBlk)
+}
+
+func TestStartBuildBlockWithoutAsyncVM(t *testing.T) {
+	require := require.New(t)
+
+	blk := &snowman.TestBlock{}
+	vm := NewBlockVM(&block.TestVM{
+		BuildBlockF: func(context.Context) (snowman.Block, error) {
+			return blk, nil
+		},
+	}).(*blockVM)
+	require.NoError(vm.blockMetrics.Initialize(false, false, false, "", prometheus.NewRegistry()))
+
+	// The block is built by BuildBlock before the build is returned.
+	build := block.StartBuildBlock(context.Background(), vm, nil /*=blockCtx*/)
+	<-build.Done()
+
+	builtBlk, err := build.Result(context.Background())
+	require.NoError(err)
+	require.IsType(&meterBlock{}, builtBlk)
+	require.Equal(blk, builtBlk.(*meterBlock).Block)
+}
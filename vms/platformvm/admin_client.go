@@ -0,0 +1,56 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"context"
+
+	"github.com/ava-labs/avalanchego/utils/rpc"
+)
+
+var _ AdminClient = (*adminClient)(nil)
+
+// AdminClient interface for interacting with the P Chain admin endpoint
+type AdminClient interface {
+	// ExportStakerSchedule returns the current and pending stakers in the
+	// node's state, signed with the node's BLS key
+	ExportStakerSchedule(ctx context.Context, options ...rpc.Option) (*SignedStakerSchedule, error)
+	// ExportCheckpoint exports the state at the node's last accepted block
+	// and returns a checkpoint of it signed with the node's BLS key
+	ExportCheckpoint(ctx context.Context, options ...rpc.Option) (*ExportCheckpointReply, error)
+	// SetDelegationOffer advertises that the node accepts delegations on the
+	// terms in [args], and returns the offer signed with the node's BLS key
+	SetDelegationOffer(ctx context.Context, args *SetDelegationOfferArgs, options ...rpc.Option) (*SignedDelegationOffer, error)
+}
+
+// adminClient implementation for interacting with the P Chain admin endpoint
+type adminClient struct {
+	requester rpc.EndpointRequester
+}
+
+// NewAdminClient returns an AdminClient for interacting with the P Chain
+// admin endpoint
+func NewAdminClient(uri string) AdminClient {
+	return &adminClient{requester: rpc.NewEndpointRequester(
+		uri + "/ext/P/admin",
+	)}
+}
+
+func (c *adminClient) ExportStakerSchedule(ctx context.Context, options ...rpc.Option) (*SignedStakerSchedule, error) {
+	res := &SignedStakerSchedule{}
+	err := c.requester.SendRequest(ctx, "admin.exportStakerSchedule", struct{}{}, res, options...)
+	return res, err
+}
+
+func (c *adminClient) ExportCheckpoint(ctx context.Context, options ...rpc.Option) (*ExportCheckpointReply, error) {
+	res := &ExportCheckpointReply{}
+	err := c.requester.SendRequest(ctx, "admin.exportCheckpoint", struct{}{}, res, options...)
+	return res, err
+}
+
+func (c *adminClient) SetDelegationOffer(ctx context.Context, args *SetDelegationOfferArgs, options ...rpc.Option) (*SignedDelegationOffer, error) {
+	res := &SignedDelegationOffer{}
+	err := c.requester.SendRequest(ctx, "admin.setDelegationOffer", args, res, options...)
+	return res, err
+}
@@ -0,0 +1,216 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+// AdminService defines the API calls that sign messages with this node's BLS
+// key. It's only served if the admin API is enabled in the execution config.
+type AdminService struct {
+	vm *VM
+}
+
+// ExportStakerSchedule returns the current and pending stakers in this node's
+// state, signed with this node's BLS key. The schedule can be validated
+// against the state of other nodes with ValidateStakerSchedule to detect state
+// divergence.
+func (s *AdminService) ExportStakerSchedule(r *http.Request, _ *struct{}, reply *SignedStakerSchedule) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "exportStakerSchedule"),
+	)
+
+	if err := s.checkSigner(); err != nil {
+		return err
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	schedule, err := s.vm.getStakerSchedule(r.Context())
+	if err != nil {
+		return err
+	}
+	msg, err := schedule.unsignedMessage(s.vm.ctx.ChainID)
+	if err != nil {
+		return err
+	}
+
+	reply.Schedule = *schedule
+	reply.NodeID = s.vm.ctx.NodeID
+	reply.PublicKey, reply.Signature, err = s.sign(msg)
+	if err != nil {
+		return fmt.Errorf("couldn't sign staker schedule: %w", err)
+	}
+	return nil
+}
+
+// ExportCheckpointReply is the response from calling ExportCheckpoint
+type ExportCheckpointReply struct {
+	Checkpoint SignedCheckpoint `json:"checkpoint"`
+	// Directory that the chain's state was exported to. A new node
+	// initializes its state from the checkpoint by setting it as its
+	// checkpoint state directory.
+	StateDir string `json:"stateDir"`
+}
+
+// ExportCheckpoint exports the chain's state at this node's last accepted
+// block to a merkledb in the chain data directory, and returns a checkpoint of
+// the block and of the exported state signed with this node's BLS key.
+// Checkpoints exported by several nodes can be combined with MergeCheckpoints.
+// The state is exported from a snapshot, so the chain keeps making progress
+// while the state is exported.
+func (s *AdminService) ExportCheckpoint(r *http.Request, _ *struct{}, reply *ExportCheckpointReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "exportCheckpoint"),
+	)
+
+	if err := s.checkSigner(); err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	height, checkpoint, snapshot, err := s.snapshotCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+	reply.StateDir, checkpoint.StateRoot, err = s.vm.exportCheckpointState(ctx, height, snapshot)
+	if err != nil {
+		return err
+	}
+	msg, err := checkpoint.unsignedMessage(s.vm.ctx.ChainID)
+	if err != nil {
+		return err
+	}
+
+	signature := CheckpointSignature{
+		NodeID: s.vm.ctx.NodeID,
+	}
+	signature.PublicKey, signature.Signature, err = s.sign(msg)
+	if err != nil {
+		return fmt.Errorf("couldn't sign checkpoint: %w", err)
+	}
+
+	reply.Checkpoint = SignedCheckpoint{
+		Checkpoint: *checkpoint,
+		Signatures: []CheckpointSignature{signature},
+	}
+	return nil
+}
+
+// snapshotCheckpoint returns the height of the last accepted block, its
+// checkpoint without a state root, and a snapshot of the chain's state after
+// the block was accepted. The snapshot must be released.
+func (s *AdminService) snapshotCheckpoint(ctx context.Context) (uint64, *Checkpoint, database.Iterator, error) {
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	height, err := s.vm.GetCurrentHeight(ctx)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	checkpoint, err := s.vm.getCheckpoint(ctx, height)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	// Accepted blocks are committed to [vm.db] atomically while [ctx.Lock] is
+	// held, so the iterator sees the state right after the block at [height]
+	// was accepted.
+	return height, checkpoint, s.vm.db.NewIterator(), nil
+}
+
+// SetDelegationOfferArgs are the arguments for calling SetDelegationOffer
+type SetDelegationOfferArgs struct {
+	// Amount of nAVAX this node is willing to accept in delegations
+	Capacity json.Uint64 `json:"capacity"`
+	// Percentage of delegation rewards kept by this node
+	DelegationFee json.Float32 `json:"delegationFee"`
+	// Minimum amount of nAVAX this node accepts in a single delegation
+	MinDelegation json.Uint64 `json:"minDelegation"`
+	// Unix time after which the offer is no longer advertised
+	Expiry json.Uint64 `json:"expiry"`
+}
+
+// SetDelegationOffer advertises that this node, which must be a current
+// primary network validator, accepts delegations. The offer is signed with
+// this node's BLS key, replaces any previous offer from this node, and is
+// gossiped to peers if delegation offer gossip is enabled. A new offer can
+// only be set [minDelegationOfferInterval] after the previous one.
+func (s *AdminService) SetDelegationOffer(r *http.Request, args *SetDelegationOfferArgs, reply *SignedDelegationOffer) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "setDelegationOffer"),
+	)
+
+	if err := s.checkSigner(); err != nil {
+		return err
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	offer := DelegationOffer{
+		NetworkID:     s.vm.ctx.NetworkID,
+		NodeID:        s.vm.ctx.NodeID,
+		Capacity:      args.Capacity,
+		DelegationFee: args.DelegationFee,
+		MinDelegation: args.MinDelegation,
+		IssuedAt:      json.Uint64(s.vm.clock.Unix()),
+		Expiry:        args.Expiry,
+	}
+	msg, err := offer.unsignedMessage(s.vm.ctx.ChainID)
+	if err != nil {
+		return err
+	}
+
+	reply.Offer = offer
+	reply.PublicKey, reply.Signature, err = s.sign(msg)
+	if err != nil {
+		return fmt.Errorf("couldn't sign delegation offer: %w", err)
+	}
+
+	if err := s.vm.addDelegationOffer(reply); err != nil {
+		return err
+	}
+	return s.vm.gossipDelegationOffer(r.Context(), reply)
+}
+
+// checkSigner returns an error if this node doesn't have a BLS key.
+func (s *AdminService) checkSigner() error {
+	if s.vm.ctx.WarpSigner == nil || s.vm.ctx.PublicKey == nil {
+		return errMissingBLSKey
+	}
+	return nil
+}
+
+// sign signs [msg] with this node's BLS key. Returns this node's hex encoded
+// public key and the hex encoded signature.
+func (s *AdminService) sign(msg *warp.UnsignedMessage) (string, string, error) {
+	sigBytes, err := s.vm.ctx.WarpSigner.Sign(msg)
+	if err != nil {
+		return "", "", err
+	}
+	publicKey, err := formatting.Encode(formatting.HexNC, bls.PublicKeyToBytes(s.vm.ctx.PublicKey))
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't encode public key: %w", err)
+	}
+	signature, err := formatting.Encode(formatting.HexNC, sigBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("couldn't encode signature: %w", err)
+	}
+	return publicKey, signature, nil
+}
@@ -0,0 +1,227 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+var (
+	errInvalidCheckpointSignature = errors.New("invalid checkpoint signature")
+	errDuplicateCheckpointSigner  = errors.New("duplicate checkpoint signer")
+	errUntrustedCheckpointSigner  = errors.New("checkpoint signer isn't in the trust set")
+	errWrongCheckpointSigner      = errors.New("checkpoint isn't signed with the trusted BLS key")
+	errTooFewCheckpointSigners    = errors.New("checkpoint isn't signed by enough trusted signers")
+	errNoCheckpointTrustSet       = errors.New("no checkpoint trust set is configured")
+	errCheckpointMismatch         = errors.New("checkpoints don't match")
+	errNoCheckpoints              = errors.New("no checkpoints to merge")
+	errInvalidCheckpointThreshold = errors.New("checkpoint signer threshold must be <= the size of the trust set")
+)
+
+// Checkpoint attests to the state of the chain at an accepted block.
+type Checkpoint struct {
+	NetworkID uint32 `json:"networkID"`
+	// Height of the checkpointed block
+	Height json.Uint64 `json:"height"`
+	// ID of the checkpointed block
+	BlockID ids.ID `json:"blockID"`
+	// Root of the merkledb that the canonical state of the chain was exported
+	// to after the checkpointed block was accepted. The canonical state
+	// doesn't include node-local data, such as uptimes, so every node that
+	// accepted the block exports the same root.
+	StateRoot ids.ID `json:"stateRoot"`
+	// Hash of the primary network validator set at [Height]
+	ValidatorSetHash ids.ID `json:"validatorSetHash"`
+}
+
+// Returns the message that's signed to attest to [c] on the chain [chainID].
+func (c *Checkpoint) unsignedMessage(chainID ids.ID) (*warp.UnsignedMessage, error) {
+	return newDomainMessage(c.NetworkID, chainID, checkpointDomain, c)
+}
+
+// CheckpointSignature is a signature over a [Checkpoint] with the BLS key of
+// the node that exported it.
+type CheckpointSignature struct {
+	// Node that signed the checkpoint
+	NodeID ids.NodeID `json:"nodeID"`
+	// Hex encoded BLS public key of [NodeID]
+	PublicKey string `json:"publicKey"`
+	// Hex encoded BLS signature of [NodeID] over the checkpoint
+	Signature string `json:"signature"`
+}
+
+// SignedCheckpoint is a [Checkpoint] signed by one or more nodes.
+type SignedCheckpoint struct {
+	Checkpoint Checkpoint            `json:"checkpoint"`
+	Signatures []CheckpointSignature `json:"signatures"`
+}
+
+// Verify checks that [c] is validly signed on the chain [chainID] by at least
+// [threshold] of the signers in [trustSet]. Signatures by signers that aren't
+// in [trustSet] are rejected.
+// Returns the signers of the checkpoint.
+func (c *SignedCheckpoint) Verify(
+	chainID ids.ID,
+	trustSet map[ids.NodeID]*bls.PublicKey,
+	threshold int,
+) (set.Set[ids.NodeID], error) {
+	msg, err := c.Checkpoint.unsignedMessage(chainID)
+	if err != nil {
+		return nil, err
+	}
+	msgBytes := msg.Bytes()
+
+	signers := set.NewSet[ids.NodeID](len(c.Signatures))
+	for _, signature := range c.Signatures {
+		if signers.Contains(signature.NodeID) {
+			return nil, fmt.Errorf("%w: %s", errDuplicateCheckpointSigner, signature.NodeID)
+		}
+		trustedPK, ok := trustSet[signature.NodeID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUntrustedCheckpointSigner, signature.NodeID)
+		}
+
+		pk, err := parsePublicKey(signature.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(bls.PublicKeyToBytes(trustedPK), bls.PublicKeyToBytes(pk)) {
+			return nil, fmt.Errorf("%w: %s", errWrongCheckpointSigner, signature.NodeID)
+		}
+
+		sigBytes, err := formatting.Decode(formatting.HexNC, signature.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decode signature: %w", err)
+		}
+		sig, err := bls.SignatureFromBytes(sigBytes)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse signature: %w", err)
+		}
+		if !bls.Verify(trustedPK, sig, msgBytes) {
+			return nil, fmt.Errorf("%w: %s", errInvalidCheckpointSignature, signature.NodeID)
+		}
+		signers.Add(signature.NodeID)
+	}
+
+	if signers.Len() < threshold || signers.Len() == 0 {
+		return nil, fmt.Errorf("%w: expected %d but got %d",
+			errTooFewCheckpointSigners,
+			threshold,
+			signers.Len(),
+		)
+	}
+	return signers, nil
+}
+
+// MergeCheckpoints combines the signatures of [checkpoints], which must all
+// attest to the same [Checkpoint], into a single [SignedCheckpoint].
+// Signatures aren't verified.
+func MergeCheckpoints(checkpoints ...*SignedCheckpoint) (*SignedCheckpoint, error) {
+	if len(checkpoints) == 0 {
+		return nil, errNoCheckpoints
+	}
+
+	merged := &SignedCheckpoint{
+		Checkpoint: checkpoints[0].Checkpoint,
+	}
+	signers := set.Set[ids.NodeID]{}
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Checkpoint != merged.Checkpoint {
+			return nil, fmt.Errorf("%w: height %d and height %d",
+				errCheckpointMismatch,
+				merged.Checkpoint.Height,
+				checkpoint.Checkpoint.Height,
+			)
+		}
+		for _, signature := range checkpoint.Signatures {
+			if signers.Contains(signature.NodeID) {
+				continue
+			}
+			signers.Add(signature.NodeID)
+			merged.Signatures = append(merged.Signatures, signature)
+		}
+	}
+	return merged, nil
+}
+
+// ParseCheckpointTrustSet parses the BLS public keys of [signers]. The
+// returned threshold defaults to the size of the trust set if [threshold] is
+// 0.
+func ParseCheckpointTrustSet(signers []config.CheckpointSigner, threshold int) (map[ids.NodeID]*bls.PublicKey, int, error) {
+	trustSet := make(map[ids.NodeID]*bls.PublicKey, len(signers))
+	for _, signer := range signers {
+		if _, ok := trustSet[signer.NodeID]; ok {
+			return nil, 0, fmt.Errorf("%w: %s", errDuplicateCheckpointSigner, signer.NodeID)
+		}
+		pk, err := parsePublicKey(signer.PublicKey)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid public key of %s: %w", signer.NodeID, err)
+		}
+		trustSet[signer.NodeID] = pk
+	}
+
+	if threshold == 0 {
+		threshold = len(trustSet)
+	}
+	if threshold > len(trustSet) {
+		return nil, 0, fmt.Errorf("%w: %d > %d",
+			errInvalidCheckpointThreshold,
+			threshold,
+			len(trustSet),
+		)
+	}
+	return trustSet, threshold, nil
+}
+
+// Returns the BLS public key encoded as hex in [pkStr].
+func parsePublicKey(pkStr string) (*bls.PublicKey, error) {
+	pkBytes, err := formatting.Decode(formatting.HexNC, pkStr)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode public key: %w", err)
+	}
+	pk, err := bls.PublicKeyFromBytes(pkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse public key: %w", err)
+	}
+	return pk, nil
+}
+
+// Returns the hash of [vdrs]. Each validator is hashed, in order of
+// increasing node ID, as its node ID, its weight as a big endian uint64, a
+// byte that's 1 if it has a BLS public key and 0 otherwise, and its compressed
+// BLS public key, if it has one.
+func hashValidatorSet(vdrs map[ids.NodeID]*validators.GetValidatorOutput) ids.ID {
+	nodeIDs := maps.Keys(vdrs)
+	utils.Sort(nodeIDs)
+
+	var vdrBytes []byte
+	for _, nodeID := range nodeIDs {
+		vdr := vdrs[nodeID]
+		vdrBytes = append(vdrBytes, nodeID[:]...)
+		vdrBytes = binary.BigEndian.AppendUint64(vdrBytes, vdr.Weight)
+		if vdr.PublicKey == nil {
+			vdrBytes = append(vdrBytes, 0)
+			continue
+		}
+		vdrBytes = append(vdrBytes, 1)
+		vdrBytes = append(vdrBytes, bls.PublicKeyToBytes(vdr.PublicKey)...)
+	}
+	return hashing.ComputeHash256Array(vdrBytes)
+}
@@ -0,0 +1,374 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	stdjson "encoding/json"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.uber.org/zap"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/leveldb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+const (
+	// Name of the directory in the chain data directory that checkpoint
+	// states are exported to.
+	checkpointsDir = "checkpoints"
+	// Number of key/value pairs committed to the exported merkledb at once.
+	checkpointStateBatchSize = 1024
+	// Number of bytes written to the database at once when importing a
+	// checkpoint state.
+	checkpointImportBatchSize = units.MiB
+)
+
+var (
+	errNoChainDataDir          = errors.New("no chain data directory to export the checkpoint state to")
+	errNoCheckpointState       = errors.New("no checkpoint state directory is configured")
+	errCheckpointStateRoot     = errors.New("checkpoint state doesn't match the checkpoint's state root")
+	errCheckpointBlockMismatch = errors.New("state imported from checkpoint doesn't match the checkpoint")
+)
+
+// Returns the config of the merkledb that checkpoint states are exported to.
+// The config must not change, since the root of the exported state is part
+// of the signed checkpoint.
+func checkpointStateConfig() merkledb.Config {
+	return merkledb.Config{
+		BranchFactor:              merkledb.BranchFactor16,
+		EvictionBatchSize:         units.MiB,
+		ValueNodeCacheSize:        units.MiB,
+		IntermediateNodeCacheSize: units.MiB,
+		Reg:                       prometheus.NewRegistry(),
+		Tracer:                    trace.Noop,
+	}
+}
+
+// exportCheckpointState writes the canonical state of the chain's state stored
+// in [src] to a merkledb over [dst], which must be empty, and returns the root
+// of the merkledb.
+func exportCheckpointState(ctx context.Context, src database.Database, dst database.Database) (ids.ID, error) {
+	exported, err := merkledb.New(ctx, dst, checkpointStateConfig())
+	if err != nil {
+		return ids.Empty, err
+	}
+	defer exported.Close()
+
+	writer := newMerkleDBWriter(ctx, exported)
+	if err := state.ExportCheckpoint(src, writer); err != nil {
+		return ids.Empty, err
+	}
+	if err := writer.flush(); err != nil {
+		return ids.Empty, err
+	}
+	return exported.GetMerkleRoot(ctx)
+}
+
+// importCheckpointState writes the chain's state from the canonical state in
+// the merkledb that was exported to [src] to [dst]. The merkledb is rebuilt in
+// [scratch], which must be empty, and the chain's state is only imported from
+// the rebuilt pairs once the rebuilt root matches [root], so that nothing is
+// written if [src] was tampered with.
+func importCheckpointState(
+	ctx context.Context,
+	src database.Database,
+	scratch database.Database,
+	dst database.Database,
+	root ids.ID,
+) error {
+	exported, err := merkledb.New(ctx, src, checkpointStateConfig())
+	if err != nil {
+		return fmt.Errorf("couldn't open checkpoint state: %w", err)
+	}
+	defer exported.Close()
+
+	verified, err := merkledb.New(ctx, scratch, checkpointStateConfig())
+	if err != nil {
+		return err
+	}
+	defer verified.Close()
+
+	if err := writeMerkleDB(ctx, exported, verified); err != nil {
+		return err
+	}
+	verifiedRoot, err := verified.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if verifiedRoot != root {
+		return fmt.Errorf("%w: expected %s but got %s",
+			errCheckpointStateRoot,
+			root,
+			verifiedRoot,
+		)
+	}
+	return state.ImportCheckpoint(verified, dst)
+}
+
+// Writes the key/value pairs of [src] to [dst].
+func writeMerkleDB(ctx context.Context, src database.Iteratee, dst merkledb.MerkleDB) error {
+	it := src.NewIterator()
+	defer it.Release()
+
+	writer := newMerkleDBWriter(ctx, dst)
+	for it.Next() {
+		if err := writer.Put(it.Key(), it.Value()); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return writer.flush()
+}
+
+// Writes the key/value pairs of [src] to [dst] in batches of
+// [checkpointImportBatchSize] bytes.
+func copyDatabase(src database.Iterator, dst database.Batcher) error {
+	batch := dst.NewBatch()
+	for src.Next() {
+		if err := batch.Put(src.Key(), src.Value()); err != nil {
+			return err
+		}
+		if batch.Size() < checkpointImportBatchSize {
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	if err := src.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+var _ database.KeyValueWriter = (*merkleDBWriter)(nil)
+
+// merkleDBWriter commits the pairs written to it to a merkledb in batches of
+// [checkpointStateBatchSize] pairs. [flush] must be called to commit the last
+// batch.
+type merkleDBWriter struct {
+	ctx context.Context
+	db  merkledb.MerkleDB
+	ops []database.BatchOp
+}
+
+func newMerkleDBWriter(ctx context.Context, db merkledb.MerkleDB) *merkleDBWriter {
+	return &merkleDBWriter{
+		ctx: ctx,
+		db:  db,
+		ops: make([]database.BatchOp, 0, checkpointStateBatchSize),
+	}
+}
+
+func (w *merkleDBWriter) Put(key, value []byte) error {
+	w.ops = append(w.ops, database.BatchOp{
+		Key:   slices.Clone(key),
+		Value: slices.Clone(value),
+	})
+	if len(w.ops) < checkpointStateBatchSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *merkleDBWriter) flush() error {
+	if len(w.ops) == 0 {
+		return nil
+	}
+	view, err := w.db.NewView(w.ctx, merkledb.ViewChanges{
+		BatchOps:     w.ops,
+		ConsumeBytes: true,
+	})
+	if err != nil {
+		return err
+	}
+	w.ops = make([]database.BatchOp, 0, checkpointStateBatchSize)
+	return view.CommitToDB(w.ctx)
+}
+
+// exportCheckpointState exports the chain's state in [snapshot] to a merkledb
+// in the checkpoints directory of the chain data directory, replacing any
+// previous export at [height]. Returns the directory of the export and its
+// root. Releases [snapshot].
+//
+// [snapshot] must be an iterator over [vm.db] that was created while the last
+// accepted block was at [height]. The snapshot is copied to a scratch
+// database before it's exported, so [vm.ctx.Lock] doesn't need to be held.
+func (vm *VM) exportCheckpointState(ctx context.Context, height uint64, snapshot database.Iterator) (string, ids.ID, error) {
+	defer snapshot.Release()
+
+	if vm.ctx.ChainDataDir == "" {
+		return "", ids.Empty, errNoChainDataDir
+	}
+
+	vm.checkpointExportLock.Lock()
+	defer vm.checkpointExportLock.Unlock()
+
+	scratchDir, err := os.MkdirTemp(vm.ctx.ChainDataDir, "checkpoint-export-")
+	if err != nil {
+		return "", ids.Empty, err
+	}
+	defer os.RemoveAll(scratchDir)
+	scratch, err := leveldb.New(scratchDir, nil, vm.ctx.Log, "", prometheus.NewRegistry())
+	if err != nil {
+		return "", ids.Empty, err
+	}
+	defer scratch.Close()
+
+	if err := copyDatabase(snapshot, scratch); err != nil {
+		return "", ids.Empty, fmt.Errorf("couldn't copy state snapshot: %w", err)
+	}
+
+	dir := filepath.Join(vm.ctx.ChainDataDir, checkpointsDir, strconv.FormatUint(height, 10))
+	if err := os.RemoveAll(dir); err != nil {
+		return "", ids.Empty, fmt.Errorf("couldn't remove previous checkpoint state: %w", err)
+	}
+	db, err := leveldb.New(dir, nil, vm.ctx.Log, "", prometheus.NewRegistry())
+	if err != nil {
+		return "", ids.Empty, fmt.Errorf("couldn't create checkpoint state database: %w", err)
+	}
+	defer db.Close()
+
+	root, err := exportCheckpointState(ctx, scratch, db)
+	if err != nil {
+		return "", ids.Empty, fmt.Errorf("couldn't export checkpoint state: %w", err)
+	}
+	return dir, root, nil
+}
+
+// initFromCheckpoint imports the state exported to [stateDir] with the
+// checkpoint in [checkpointFile], which must be trusted by the checkpoint
+// trust set, if the database is empty. Returns the imported checkpoint, or
+// nil if the database isn't empty.
+// Only the P-chain's state is imported. The blocks after the checkpoint are
+// fetched by bootstrapping as usual.
+func (vm *VM) initFromCheckpoint(ctx context.Context, checkpointFile, stateDir string) (*Checkpoint, error) {
+	isEmpty, err := database.IsEmpty(vm.db)
+	if err != nil {
+		return nil, err
+	}
+	if !isEmpty {
+		vm.ctx.Log.Info("ignoring checkpoint because the database isn't empty",
+			zap.String("checkpointFile", checkpointFile),
+		)
+		return nil, nil
+	}
+
+	if len(vm.checkpointTrustSet) == 0 {
+		return nil, errNoCheckpointTrustSet
+	}
+	if stateDir == "" {
+		return nil, errNoCheckpointState
+	}
+
+	checkpointBytes, err := os.ReadFile(checkpointFile)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read checkpoint: %w", err)
+	}
+	signed := &SignedCheckpoint{}
+	if err := stdjson.Unmarshal(checkpointBytes, signed); err != nil {
+		return nil, fmt.Errorf("couldn't parse checkpoint: %w", err)
+	}
+	if signed.Checkpoint.NetworkID != vm.ctx.NetworkID {
+		return nil, fmt.Errorf("%w: expected %d but got %d",
+			errWrongNetworkID,
+			vm.ctx.NetworkID,
+			signed.Checkpoint.NetworkID,
+		)
+	}
+	if _, err := signed.Verify(vm.ctx.ChainID, vm.checkpointTrustSet, vm.checkpointSignerThreshold); err != nil {
+		return nil, err
+	}
+
+	src, err := leveldb.New(stateDir, nil, vm.ctx.Log, "", prometheus.NewRegistry())
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open checkpoint state database: %w", err)
+	}
+	defer src.Close()
+
+	scratchDir, err := os.MkdirTemp(vm.ctx.ChainDataDir, "checkpoint-import-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(scratchDir)
+	scratch, err := leveldb.New(scratchDir, nil, vm.ctx.Log, "", prometheus.NewRegistry())
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Close()
+
+	vm.ctx.Log.Info("importing state from checkpoint",
+		zap.Uint64("height", uint64(signed.Checkpoint.Height)),
+		zap.Stringer("blkID", signed.Checkpoint.BlockID),
+		zap.Stringer("stateRoot", signed.Checkpoint.StateRoot),
+	)
+	if err := importCheckpointState(ctx, src, scratch, vm.db, signed.Checkpoint.StateRoot); err != nil {
+		return nil, err
+	}
+	return &signed.Checkpoint, nil
+}
+
+// verifyImportedCheckpoint checks that the last accepted block and the
+// validator set of the state imported from [checkpoint] match it.
+func (vm *VM) verifyImportedCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	lastAcceptedID := vm.state.GetLastAccepted()
+	if lastAcceptedID != checkpoint.BlockID {
+		return fmt.Errorf("%w: last accepted block is %s but the checkpoint is %s",
+			errCheckpointBlockMismatch,
+			lastAcceptedID,
+			checkpoint.BlockID,
+		)
+	}
+	imported, err := vm.getCheckpoint(ctx, uint64(checkpoint.Height))
+	if err != nil {
+		return err
+	}
+	if imported.BlockID != checkpoint.BlockID || imported.ValidatorSetHash != checkpoint.ValidatorSetHash {
+		return fmt.Errorf("%w: at height %d",
+			errCheckpointBlockMismatch,
+			checkpoint.Height,
+		)
+	}
+	return nil
+}
+
+// Returns the checkpoint of the accepted block at [height], without a state
+// root.
+// Assumes [vm.ctx.Lock] is held.
+func (vm *VM) getCheckpoint(ctx context.Context, height uint64) (*Checkpoint, error) {
+	blockID, err := vm.state.GetBlockIDAtHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get block at height %d: %w", height, err)
+	}
+	vdrs, err := vm.GetValidatorSet(ctx, height, constants.PrimaryNetworkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validator set: %w", err)
+	}
+	return &Checkpoint{
+		NetworkID:        vm.ctx.NetworkID,
+		Height:           json.Uint64(height),
+		BlockID:          blockID,
+		ValidatorSetHash: hashValidatorSet(vdrs),
+	}, nil
+}
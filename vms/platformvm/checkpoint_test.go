@@ -0,0 +1,157 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+func signCheckpoint(
+	t *testing.T,
+	checkpoint Checkpoint,
+	chainID ids.ID,
+	nodeID ids.NodeID,
+	sk *bls.SecretKey,
+) *SignedCheckpoint {
+	require := require.New(t)
+
+	msg, err := checkpoint.unsignedMessage(chainID)
+	require.NoError(err)
+	sigBytes, err := warp.NewSigner(sk, checkpoint.NetworkID, chainID).Sign(msg)
+	require.NoError(err)
+
+	signature := CheckpointSignature{
+		NodeID: nodeID,
+	}
+	signature.PublicKey, err = formatting.Encode(formatting.HexNC, bls.PublicKeyToBytes(bls.PublicFromSecretKey(sk)))
+	require.NoError(err)
+	signature.Signature, err = formatting.Encode(formatting.HexNC, sigBytes)
+	require.NoError(err)
+	return &SignedCheckpoint{
+		Checkpoint: checkpoint,
+		Signatures: []CheckpointSignature{signature},
+	}
+}
+
+func TestCheckpointVerify(t *testing.T) {
+	require := require.New(t)
+
+	chainID := ids.GenerateTestID()
+	checkpoint := Checkpoint{
+		NetworkID:        constants.UnitTestID,
+		Height:           10,
+		BlockID:          ids.GenerateTestID(),
+		StateRoot:        ids.GenerateTestID(),
+		ValidatorSetHash: ids.GenerateTestID(),
+	}
+
+	var (
+		nodeIDs  = make([]ids.NodeID, 3)
+		sks      = make([]*bls.SecretKey, 3)
+		trustSet = make(map[ids.NodeID]*bls.PublicKey, 3)
+		signed   = make([]*SignedCheckpoint, 3)
+		trusted  = make([]config.CheckpointSigner, 3)
+	)
+	for i := range nodeIDs {
+		sk, err := bls.NewSecretKey()
+		require.NoError(err)
+
+		nodeIDs[i] = ids.GenerateTestNodeID()
+		sks[i] = sk
+		trustSet[nodeIDs[i]] = bls.PublicFromSecretKey(sk)
+		signed[i] = signCheckpoint(t, checkpoint, chainID, nodeIDs[i], sk)
+		trusted[i] = config.CheckpointSigner{
+			NodeID:    nodeIDs[i],
+			PublicKey: signed[i].Signatures[0].PublicKey,
+		}
+	}
+
+	parsedTrustSet, threshold, err := ParseCheckpointTrustSet(trusted, 0)
+	require.NoError(err)
+	require.Len(parsedTrustSet, len(trustSet))
+	for nodeID, pk := range trustSet {
+		require.Contains(parsedTrustSet, nodeID)
+		require.Equal(bls.PublicKeyToBytes(pk), bls.PublicKeyToBytes(parsedTrustSet[nodeID]))
+	}
+	require.Equal(3, threshold)
+
+	_, _, err = ParseCheckpointTrustSet(trusted, 4)
+	require.ErrorIs(err, errInvalidCheckpointThreshold)
+
+	// A single signature doesn't meet a threshold of 2.
+	_, err = signed[0].Verify(chainID, trustSet, 2)
+	require.ErrorIs(err, errTooFewCheckpointSigners)
+
+	merged, err := MergeCheckpoints(signed[0], signed[1], signed[0])
+	require.NoError(err)
+	require.Len(merged.Signatures, 2)
+
+	signers, err := merged.Verify(chainID, trustSet, 2)
+	require.NoError(err)
+	require.Equal(set.Of(nodeIDs[0], nodeIDs[1]), signers)
+
+	// Signatures are bound to the chain.
+	_, err = merged.Verify(ids.GenerateTestID(), trustSet, 2)
+	require.ErrorIs(err, errInvalidCheckpointSignature)
+
+	// A signer can't claim to be another trusted signer.
+	impersonated := signCheckpoint(t, checkpoint, chainID, nodeIDs[2], sks[0])
+	_, err = impersonated.Verify(chainID, trustSet, 1)
+	require.ErrorIs(err, errWrongCheckpointSigner)
+
+	// Checkpoints that attest to different blocks can't be merged.
+	other := checkpoint
+	other.BlockID = ids.GenerateTestID()
+	_, err = MergeCheckpoints(signed[2], signCheckpoint(t, other, chainID, nodeIDs[0], sks[0]))
+	require.ErrorIs(err, errCheckpointMismatch)
+}
+
+func TestCheckpointStateExportImport(t *testing.T) {
+	require := require.New(t)
+	vm, _, _ := defaultVM(t)
+	vm.ctx.Lock.Lock()
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+		vm.ctx.Lock.Unlock()
+	}()
+
+	exported := memdb.New()
+	root, err := exportCheckpointState(context.Background(), vm.db, exported)
+	require.NoError(err)
+
+	// Uptimes are node-local, so they don't change the exported root.
+	require.NoError(vm.state.SetUptime(genesisNodeIDs[0], constants.PrimaryNetworkID, time.Hour, vm.state.GetTimestamp()))
+	require.NoError(vm.state.Commit())
+	rootWithUptime, err := exportCheckpointState(context.Background(), vm.db, memdb.New())
+	require.NoError(err)
+	require.Equal(root, rootWithUptime)
+
+	// Nothing is imported if the root doesn't match.
+	dst := memdb.New()
+	err = importCheckpointState(context.Background(), exported, memdb.New(), dst, ids.GenerateTestID())
+	require.ErrorIs(err, errCheckpointStateRoot)
+	isEmpty, err := database.IsEmpty(dst)
+	require.NoError(err)
+	require.True(isEmpty)
+
+	// The imported state is exported to the same root.
+	require.NoError(importCheckpointState(context.Background(), exported, memdb.New(), dst, root))
+	importedRoot, err := exportCheckpointState(context.Background(), dst, memdb.New())
+	require.NoError(err)
+	require.Equal(root, importedRoot)
+}
@@ -39,6 +39,9 @@ type Client interface {
 	//
 	// Deprecated: GetUTXOs should be used instead.
 	GetBalance(ctx context.Context, addrs []ids.ShortID, options ...rpc.Option) (*GetBalanceResponse, error)
+	// GetFeeRefunds returns the fees overpaid by [addrs] that can be claimed
+	// with a ClaimFeeRefundTx.
+	GetFeeRefunds(ctx context.Context, addrs []ids.ShortID, options ...rpc.Option) (*GetFeeRefundsReply, error)
 	// CreateAddress creates a new address for [user]
 	//
 	// Deprecated: Keys should no longer be stored on the node.
@@ -78,6 +81,27 @@ type Client interface {
 	GetCurrentValidators(ctx context.Context, subnetID ids.ID, nodeIDs []ids.NodeID, options ...rpc.Option) ([]ClientPermissionlessValidator, error)
 	// GetPendingValidators returns the list of pending validators for subnet with ID [subnetID]
 	GetPendingValidators(ctx context.Context, subnetID ids.ID, nodeIDs []ids.NodeID, options ...rpc.Option) ([]interface{}, []interface{}, error)
+	// GetSubnetValidatorQueue returns the validators of [subnetID] that are
+	// waiting to be added to its validator set and the changes scheduled in
+	// each epoch of its churn limit
+	GetSubnetValidatorQueue(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (*GetSubnetValidatorQueueReply, error)
+	// ProjectValidatorSet returns the validator set of [args.SubnetID]
+	// projected at [args.Timestamps], assuming the hypothetical changes in
+	// [args]
+	ProjectValidatorSet(ctx context.Context, args *ProjectValidatorSetArgs, options ...rpc.Option) (*ProjectValidatorSetReply, error)
+	// ValidateStakerSchedule verifies [schedule], which was exported by
+	// another node, and compares it to the stakers in the node's state
+	ValidateStakerSchedule(ctx context.Context, schedule *SignedStakerSchedule, options ...rpc.Option) (*ValidateStakerScheduleReply, error)
+	// VerifyCheckpoint verifies [checkpoint] against the node's checkpoint
+	// trust set and compares it to the node's accepted chain
+	VerifyCheckpoint(ctx context.Context, checkpoint *SignedCheckpoint, options ...rpc.Option) (*VerifyCheckpointReply, error)
+	// SubmitDelegationOffer adds [offer], which was set on another node, to
+	// the node's registry of delegation offers
+	SubmitDelegationOffer(ctx context.Context, offer *SignedDelegationOffer, options ...rpc.Option) error
+	// GetDelegationOffers returns the delegation offers known to the node of
+	// the validators in [nodeIDs] with at least [minCapacity] capacity. If
+	// [nodeIDs] is empty, the offers of all validators are returned.
+	GetDelegationOffers(ctx context.Context, nodeIDs []ids.NodeID, minCapacity uint64, options ...rpc.Option) ([]*SignedDelegationOffer, error)
 	// GetCurrentSupply returns an upper bound on the supply of AVAX in the system along with the P-chain height
 	GetCurrentSupply(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (uint64, uint64, error)
 	// SampleValidators returns the nodeIDs of a sample of [sampleSize] validators from the current validator set for subnet with ID [subnetID]
@@ -200,6 +224,12 @@ type Client interface {
 	//
 	// Deprecated: Blockchains should be fetched from a dedicated indexer.
 	GetBlockchains(ctx context.Context, options ...rpc.Option) ([]APIBlockchain, error)
+	// GetFilteredBlockchains returns the page of the blockchains on the
+	// platform described by [args], and the number of blockchains that match
+	// the filters in [args]
+	//
+	// Deprecated: Blockchains should be fetched from a dedicated indexer.
+	GetFilteredBlockchains(ctx context.Context, args *GetBlockchainsArgs, options ...rpc.Option) ([]APIBlockchain, uint64, error)
 	// IssueTx issues the transaction and returns its txID
 	IssueTx(ctx context.Context, tx []byte, options ...rpc.Option) (ids.ID, error)
 	// GetTx returns the byte representation of the transaction corresponding to [txID]
@@ -216,6 +246,9 @@ type Client interface {
 		freq time.Duration,
 		options ...rpc.Option,
 	) (*GetTxStatusResponse, error)
+	// GetAtomicStatus returns whether the outputs exported by the export tx
+	// [txID] were imported by their destination chain
+	GetAtomicStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (*GetAtomicStatusReply, error)
 	// GetStake returns the amount of nAVAX that [addrs] have cumulatively
 	// staked on the Primary Network.
 	//
@@ -227,6 +260,10 @@ type Client interface {
 		validatorsOnly bool,
 		options ...rpc.Option,
 	) (map[ids.ID]uint64, [][]byte, error)
+	// GetStakingOverview returns the balances, active and pending stakes, and
+	// pending rewards of [addrs] on the Primary Network, as of a single chain
+	// state.
+	GetStakingOverview(ctx context.Context, addrs []ids.ShortID, options ...rpc.Option) (*GetStakingOverviewReply, error)
 	// GetMinStake returns the minimum staking amount in nAVAX for validators
 	// and delegators respectively
 	GetMinStake(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (uint64, uint64, error)
@@ -249,6 +286,9 @@ type Client interface {
 	//
 	// Deprecated: GetRewardUTXOs should be fetched from a dedicated indexer.
 	GetRewardUTXOs(context.Context, *api.GetTxArgs, ...rpc.Option) ([][]byte, error)
+	// GetRewardsOwners returns the rewards owners of the staking tx [txID]
+	// along with the owners that the rewards would currently be paid to.
+	GetRewardsOwners(ctx context.Context, txID ids.ID, options ...rpc.Option) (*GetRewardsOwnersReply, error)
 	// GetTimestamp returns the current chain timestamp
 	GetTimestamp(ctx context.Context, options ...rpc.Option) (time.Time, error)
 	// GetValidatorsAt returns the weights of the validator set of a provided
@@ -259,6 +299,17 @@ type Client interface {
 		height uint64,
 		options ...rpc.Option,
 	) (map[ids.NodeID]*validators.GetValidatorOutput, error)
+	// GetValidatorWeightHistory returns the weight of [nodeID] on [subnetID]
+	// at [fromHeight] and the changes to its weight up to and including
+	// [toHeight].
+	GetValidatorWeightHistory(
+		ctx context.Context,
+		nodeID ids.NodeID,
+		subnetID ids.ID,
+		fromHeight uint64,
+		toHeight uint64,
+		options ...rpc.Option,
+	) (uint64, []ValidatorWeightChange, error)
 	// GetBlock returns the block with the given id.
 	GetBlock(ctx context.Context, blockID ids.ID, options ...rpc.Option) ([]byte, error)
 	// GetBlockByHeight returns the block at the given [height].
@@ -312,6 +363,14 @@ func (c *client) GetBalance(ctx context.Context, addrs []ids.ShortID, options ..
 	return res, err
 }
 
+func (c *client) GetFeeRefunds(ctx context.Context, addrs []ids.ShortID, options ...rpc.Option) (*GetFeeRefundsReply, error) {
+	res := &GetFeeRefundsReply{}
+	err := c.requester.SendRequest(ctx, "platform.getFeeRefunds", &GetFeeRefundsArgs{
+		Addresses: ids.ShortIDsToStrings(addrs),
+	}, res, options...)
+	return res, err
+}
+
 func (c *client) CreateAddress(ctx context.Context, user api.UserPass, options ...rpc.Option) (ids.ShortID, error) {
 	res := &api.JSONAddress{}
 	err := c.requester.SendRequest(ctx, "platform.createAddress", &user, res, options...)
@@ -455,6 +514,45 @@ func (c *client) GetPendingValidators(
 	return res.Validators, res.Delegators, err
 }
 
+func (c *client) GetSubnetValidatorQueue(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (*GetSubnetValidatorQueueReply, error) {
+	res := &GetSubnetValidatorQueueReply{}
+	err := c.requester.SendRequest(ctx, "platform.getSubnetValidatorQueue", &GetSubnetValidatorQueueArgs{
+		SubnetID: subnetID,
+	}, res, options...)
+	return res, err
+}
+
+func (c *client) ProjectValidatorSet(ctx context.Context, args *ProjectValidatorSetArgs, options ...rpc.Option) (*ProjectValidatorSetReply, error) {
+	res := &ProjectValidatorSetReply{}
+	err := c.requester.SendRequest(ctx, "platform.projectValidatorSet", args, res, options...)
+	return res, err
+}
+
+func (c *client) ValidateStakerSchedule(ctx context.Context, schedule *SignedStakerSchedule, options ...rpc.Option) (*ValidateStakerScheduleReply, error) {
+	res := &ValidateStakerScheduleReply{}
+	err := c.requester.SendRequest(ctx, "platform.validateStakerSchedule", schedule, res, options...)
+	return res, err
+}
+
+func (c *client) VerifyCheckpoint(ctx context.Context, checkpoint *SignedCheckpoint, options ...rpc.Option) (*VerifyCheckpointReply, error) {
+	res := &VerifyCheckpointReply{}
+	err := c.requester.SendRequest(ctx, "platform.verifyCheckpoint", checkpoint, res, options...)
+	return res, err
+}
+
+func (c *client) SubmitDelegationOffer(ctx context.Context, offer *SignedDelegationOffer, options ...rpc.Option) error {
+	return c.requester.SendRequest(ctx, "platform.submitDelegationOffer", offer, &api.EmptyReply{}, options...)
+}
+
+func (c *client) GetDelegationOffers(ctx context.Context, nodeIDs []ids.NodeID, minCapacity uint64, options ...rpc.Option) ([]*SignedDelegationOffer, error) {
+	res := &GetDelegationOffersReply{}
+	err := c.requester.SendRequest(ctx, "platform.getDelegationOffers", &GetDelegationOffersArgs{
+		NodeIDs:     nodeIDs,
+		MinCapacity: json.Uint64(minCapacity),
+	}, res, options...)
+	return res.Offers, err
+}
+
 func (c *client) GetCurrentSupply(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (uint64, uint64, error) {
 	res := &GetCurrentSupplyReply{}
 	err := c.requester.SendRequest(ctx, "platform.getCurrentSupply", &GetCurrentSupplyArgs{
@@ -700,10 +798,16 @@ func (c *client) Validates(ctx context.Context, subnetID ids.ID, options ...rpc.
 
 func (c *client) GetBlockchains(ctx context.Context, options ...rpc.Option) ([]APIBlockchain, error) {
 	res := &GetBlockchainsResponse{}
-	err := c.requester.SendRequest(ctx, "platform.getBlockchains", struct{}{}, res, options...)
+	err := c.requester.SendRequest(ctx, "platform.getBlockchains", &GetBlockchainsArgs{}, res, options...)
 	return res.Blockchains, err
 }
 
+func (c *client) GetFilteredBlockchains(ctx context.Context, args *GetBlockchainsArgs, options ...rpc.Option) ([]APIBlockchain, uint64, error) {
+	res := &GetBlockchainsResponse{}
+	err := c.requester.SendRequest(ctx, "platform.getBlockchains", args, res, options...)
+	return res.Blockchains, uint64(res.Total), err
+}
+
 func (c *client) IssueTx(ctx context.Context, txBytes []byte, options ...rpc.Option) (ids.ID, error) {
 	txStr, err := formatting.Encode(formatting.Hex, txBytes)
 	if err != nil {
@@ -744,6 +848,20 @@ func (c *client) GetTxStatus(ctx context.Context, txID ids.ID, options ...rpc.Op
 	return res, err
 }
 
+func (c *client) GetAtomicStatus(ctx context.Context, txID ids.ID, options ...rpc.Option) (*GetAtomicStatusReply, error) {
+	res := &GetAtomicStatusReply{}
+	err := c.requester.SendRequest(
+		ctx,
+		"platform.getAtomicStatus",
+		&GetAtomicStatusArgs{
+			TxID: txID,
+		},
+		res,
+		options...,
+	)
+	return res, err
+}
+
 func (c *client) AwaitTxDecided(ctx context.Context, txID ids.ID, freq time.Duration, options ...rpc.Option) (*GetTxStatusResponse, error) {
 	ticker := time.NewTicker(freq)
 	defer ticker.Stop()
@@ -799,6 +917,16 @@ func (c *client) GetStake(
 	return staked, outputs, err
 }
 
+func (c *client) GetStakingOverview(ctx context.Context, addrs []ids.ShortID, options ...rpc.Option) (*GetStakingOverviewReply, error) {
+	res := &GetStakingOverviewReply{}
+	err := c.requester.SendRequest(ctx, "platform.getStakingOverview", &GetStakingOverviewArgs{
+		JSONAddresses: api.JSONAddresses{
+			Addresses: ids.ShortIDsToStrings(addrs),
+		},
+	}, res, options...)
+	return res, err
+}
+
 func (c *client) GetMinStake(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (uint64, uint64, error) {
 	res := &GetMinStakeReply{}
 	err := c.requester.SendRequest(ctx, "platform.getMinStake", &GetMinStakeArgs{
@@ -849,6 +977,14 @@ func (c *client) GetRewardUTXOs(ctx context.Context, args *api.GetTxArgs, option
 	return utxos, err
 }
 
+func (c *client) GetRewardsOwners(ctx context.Context, txID ids.ID, options ...rpc.Option) (*GetRewardsOwnersReply, error) {
+	res := &GetRewardsOwnersReply{}
+	err := c.requester.SendRequest(ctx, "platform.getRewardsOwners", &GetRewardsOwnersArgs{
+		TxID: txID,
+	}, res, options...)
+	return res, err
+}
+
 func (c *client) GetTimestamp(ctx context.Context, options ...rpc.Option) (time.Time, error) {
 	res := &GetTimestampReply{}
 	err := c.requester.SendRequest(ctx, "platform.getTimestamp", struct{}{}, res, options...)
@@ -869,6 +1005,24 @@ func (c *client) GetValidatorsAt(
 	return res.Validators, err
 }
 
+func (c *client) GetValidatorWeightHistory(
+	ctx context.Context,
+	nodeID ids.NodeID,
+	subnetID ids.ID,
+	fromHeight uint64,
+	toHeight uint64,
+	options ...rpc.Option,
+) (uint64, []ValidatorWeightChange, error) {
+	res := &GetValidatorWeightHistoryReply{}
+	err := c.requester.SendRequest(ctx, "platform.getValidatorWeightHistory", &GetValidatorWeightHistoryArgs{
+		NodeID:     nodeID,
+		SubnetID:   subnetID,
+		FromHeight: json.Uint64(fromHeight),
+		ToHeight:   json.Uint64(toHeight),
+	}, res, options...)
+	return uint64(res.StartWeight), res.Changes, err
+}
+
 func (c *client) GetBlock(ctx context.Context, blockID ids.ID, options ...rpc.Option) ([]byte, error) {
 	res := &api.FormattedBlock{}
 	if err := c.requester.SendRequest(ctx, "platform.getBlock", &api.GetBlockArgs{
@@ -10,6 +10,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/uptime"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/subnets"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
@@ -41,6 +42,10 @@ type Config struct {
 	// Set of subnets that this node is validating
 	TrackedSubnets set.Set[ids.ID]
 
+	// Limits on how quickly the validator sets of permissioned subnets can
+	// change. Subnets without an entry aren't limited.
+	SubnetChurnLimits map[ids.ID]subnets.ChurnLimit
+
 	// Fee that is burned by every non-state creating transaction
 	TxFee uint64
 
@@ -117,6 +122,13 @@ type Config struct {
 	UseCurrentHeight bool
 }
 
+// GetSubnetChurnLimit returns the churn limit of [subnetID]. Returns false if
+// the validator set of [subnetID] isn't limited.
+func (c *Config) GetSubnetChurnLimit(subnetID ids.ID) (subnets.ChurnLimit, bool) {
+	limit, ok := c.SubnetChurnLimits[subnetID]
+	return limit, ok && limit.Enabled()
+}
+
 func (c *Config) IsApricotPhase3Activated(timestamp time.Time) bool {
 	return !timestamp.Before(c.ApricotPhase3Time)
 }
@@ -6,6 +6,7 @@ package config
 import (
 	"encoding/json"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/units"
 )
 
@@ -32,6 +33,32 @@ type ExecutionConfig struct {
 	BlockIDCacheSize             int  `json:"block-id-cache-size"`
 	FxOwnerCacheSize             int  `json:"fx-owner-cache-size"`
 	ChecksumsEnabled             bool `json:"checksums-enabled"`
+	// If true, the admin API, which signs messages with this node's BLS key,
+	// is served at the "/admin" extension of the chain's endpoint.
+	AdminAPIEnabled bool `json:"admin-api-enabled"`
+	// If true, delegation offers are gossiped to and accepted from peers.
+	// Otherwise, only offers set or submitted through the API are known.
+	DelegationOfferGossipEnabled bool `json:"delegation-offer-gossip-enabled"`
+	// Signers whose checkpoints are trusted by VerifyCheckpoint
+	CheckpointTrustSet []CheckpointSigner `json:"checkpoint-trust-set"`
+	// Number of signers in [CheckpointTrustSet] that must sign a checkpoint
+	// for it to be trusted. If 0 is specified, all of the signers in
+	// [CheckpointTrustSet] must sign.
+	CheckpointSignerThreshold int `json:"checkpoint-signer-threshold"`
+	// If non-empty and the database is empty, the state is imported from the
+	// checkpoint in this file, which must be signed by the checkpoint trust
+	// set, instead of being initialized from genesis.
+	CheckpointFile string `json:"checkpoint-file"`
+	// Directory that the state of the checkpoint in [CheckpointFile] was
+	// exported to
+	CheckpointStateDir string `json:"checkpoint-state-dir"`
+}
+
+// CheckpointSigner is a node trusted to sign checkpoints
+type CheckpointSigner struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	// Hex encoded BLS public key of [NodeID]
+	PublicKey string `json:"publicKey"`
 }
 
 // GetExecutionConfig returns an ExecutionConfig
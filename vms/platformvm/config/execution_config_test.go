@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
 )
 
 func TestExecutionConfigUnmarshal(t *testing.T) {
@@ -47,7 +49,18 @@ func TestExecutionConfigUnmarshal(t *testing.T) {
 			"chain-db-cache-size": 7,
 			"block-id-cache-size": 8,
 			"fx-owner-cache-size": 9,
-			"checksums-enabled": true
+			"checksums-enabled": true,
+			"admin-api-enabled": true,
+			"delegation-offer-gossip-enabled": true,
+			"checkpoint-trust-set": [
+				{
+					"nodeID": "NodeID-111111111111111111116DBWJs",
+					"publicKey": "0x00"
+				}
+			],
+			"checkpoint-signer-threshold": 1,
+			"checkpoint-file": "checkpoint.json",
+			"checkpoint-state-dir": "state"
 		}`)
 		ec, err := GetExecutionConfig(b)
 		require.NoError(err)
@@ -61,6 +74,17 @@ func TestExecutionConfigUnmarshal(t *testing.T) {
 			BlockIDCacheSize:             8,
 			FxOwnerCacheSize:             9,
 			ChecksumsEnabled:             true,
+			AdminAPIEnabled:              true,
+			DelegationOfferGossipEnabled: true,
+			CheckpointTrustSet: []CheckpointSigner{
+				{
+					NodeID:    ids.EmptyNodeID,
+					PublicKey: "0x00",
+				},
+			},
+			CheckpointSignerThreshold: 1,
+			CheckpointFile:            "checkpoint.json",
+			CheckpointStateDir:        "state",
 		}
 		require.Equal(expected, ec)
 	})
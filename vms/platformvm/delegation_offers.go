@@ -0,0 +1,302 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	stdjson "encoding/json"
+
+	"go.uber.org/zap"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/vms/components/message"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+const (
+	// Offers can't be advertised for longer than this.
+	maxDelegationOfferDuration = 7 * 24 * time.Hour
+	// An offer only replaces the known offer of the same validator if it was
+	// issued at least this much later, which limits how often each validator
+	// can set and gossip offers.
+	minDelegationOfferInterval = time.Minute
+)
+
+var (
+	errInvalidOfferSignature = errors.New("invalid delegation offer signature")
+	errWrongOfferSigner      = errors.New("delegation offer isn't signed with the validator's BLS key")
+	errOfferNotValidator     = errors.New("delegation offer isn't from a current primary network validator")
+	errOfferExpired          = errors.New("delegation offer is expired")
+	errOfferExpiryTooLate    = fmt.Errorf("delegation offer can't expire more than %s in the future", maxDelegationOfferDuration)
+	errOfferNoCapacity       = errors.New("delegation offer must have capacity > 0")
+	errOfferMinDelegation    = errors.New("delegation offer minimum delegation is below the minimum delegator stake")
+	errOfferOutdated         = errors.New("delegation offer isn't newer than the known offer")
+	errOfferRateLimited      = fmt.Errorf("delegation offer was issued less than %s after the known offer", minDelegationOfferInterval)
+	errOfferGossipDisabled   = errors.New("delegation offer gossip is disabled")
+)
+
+// DelegationOffer is a validator's advertisement that it accepts delegations.
+// Offers aren't part of consensus. The terms that apply to a delegation are
+// those of the validator's staking transaction, so wallets should verify the
+// fee with GetCurrentValidators before delegating.
+type DelegationOffer struct {
+	NetworkID uint32     `json:"networkID"`
+	NodeID    ids.NodeID `json:"nodeID"`
+	// Amount of nAVAX the validator is willing to accept in delegations
+	Capacity json.Uint64 `json:"capacity"`
+	// Percentage of delegation rewards kept by the validator
+	DelegationFee json.Float32 `json:"delegationFee"`
+	// Minimum amount of nAVAX the validator accepts in a single delegation
+	MinDelegation json.Uint64 `json:"minDelegation"`
+	// Unix time the offer was issued. A newer offer from the same validator
+	// replaces an older one.
+	IssuedAt json.Uint64 `json:"issuedAt"`
+	// Unix time after which the offer is no longer advertised
+	Expiry json.Uint64 `json:"expiry"`
+}
+
+// Returns the message that's signed to attest to [o] on the chain [chainID].
+func (o *DelegationOffer) unsignedMessage(chainID ids.ID) (*warp.UnsignedMessage, error) {
+	return newDomainMessage(o.NetworkID, chainID, delegationOfferDomain, o)
+}
+
+// SignedDelegationOffer is a [DelegationOffer] signed with the BLS key of the
+// validator that issued it.
+type SignedDelegationOffer struct {
+	Offer DelegationOffer `json:"offer"`
+	// Hex encoded BLS public key of [Offer.NodeID]
+	PublicKey string `json:"publicKey"`
+	// Hex encoded BLS signature of [Offer.NodeID] over [Offer]
+	Signature string `json:"signature"`
+}
+
+// Verify checks that [o.Signature] is a valid signature of [o.PublicKey] over
+// [o.Offer] on the chain [chainID].
+// Returns the public key that signed the offer.
+func (o *SignedDelegationOffer) Verify(chainID ids.ID) (*bls.PublicKey, error) {
+	pkBytes, err := formatting.Decode(formatting.HexNC, o.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode public key: %w", err)
+	}
+	pk, err := bls.PublicKeyFromBytes(pkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse public key: %w", err)
+	}
+
+	sigBytes, err := formatting.Decode(formatting.HexNC, o.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode signature: %w", err)
+	}
+	sig, err := bls.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse signature: %w", err)
+	}
+
+	msg, err := o.Offer.unsignedMessage(chainID)
+	if err != nil {
+		return nil, err
+	}
+	if !bls.Verify(pk, sig, msg.Bytes()) {
+		return nil, errInvalidOfferSignature
+	}
+	return pk, nil
+}
+
+// delegationOffers is a node-local registry of the latest offer of each
+// validator. It is safe for concurrent use.
+type delegationOffers struct {
+	lock   sync.Mutex
+	offers map[ids.NodeID]*SignedDelegationOffer
+}
+
+func newDelegationOffers() *delegationOffers {
+	return &delegationOffers{
+		offers: make(map[ids.NodeID]*SignedDelegationOffer),
+	}
+}
+
+// put records [offer] unless a newer or equally new offer from the same
+// validator is already known, or the known offer was issued less than
+// [minDelegationOfferInterval] before [offer].
+func (d *delegationOffers) put(offer *SignedDelegationOffer) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if known, ok := d.offers[offer.Offer.NodeID]; ok {
+		if known.Offer.IssuedAt >= offer.Offer.IssuedAt {
+			return errOfferOutdated
+		}
+		if offer.Offer.IssuedAt < known.Offer.IssuedAt+json.Uint64(minDelegationOfferInterval/time.Second) {
+			return errOfferRateLimited
+		}
+	}
+	d.offers[offer.Offer.NodeID] = offer
+	return nil
+}
+
+// list returns the offers for which [keep] returns true, sorted by increasing
+// fee, and removes the offers that expired before [now] or for which [keep]
+// returns false.
+func (d *delegationOffers) list(now time.Time, keep func(*SignedDelegationOffer) bool) []*SignedDelegationOffer {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for nodeID, offer := range d.offers {
+		if offer.Offer.Expiry < json.Uint64(now.Unix()) || !keep(offer) {
+			delete(d.offers, nodeID)
+		}
+	}
+
+	offers := maps.Values(d.offers)
+	slices.SortFunc(offers, func(a, b *SignedDelegationOffer) bool {
+		if a.Offer.DelegationFee != b.Offer.DelegationFee {
+			return a.Offer.DelegationFee < b.Offer.DelegationFee
+		}
+		return a.Offer.NodeID.Less(b.Offer.NodeID)
+	})
+	return offers
+}
+
+// verifyDelegationOffer checks that [offer] is well formed, unexpired and
+// signed by a current primary network validator.
+// Assumes [vm.ctx.Lock] is held.
+func (vm *VM) verifyDelegationOffer(offer *SignedDelegationOffer) error {
+	if offer.Offer.NetworkID != vm.ctx.NetworkID {
+		return fmt.Errorf("%w: expected %d but got %d",
+			errWrongNetworkID,
+			vm.ctx.NetworkID,
+			offer.Offer.NetworkID,
+		)
+	}
+
+	now := vm.clock.Time()
+	expiry := time.Unix(int64(offer.Offer.Expiry), 0)
+	switch {
+	case expiry.Before(now):
+		return errOfferExpired
+	case expiry.After(now.Add(maxDelegationOfferDuration)):
+		return errOfferExpiryTooLate
+	case offer.Offer.Capacity == 0:
+		return errOfferNoCapacity
+	case offer.Offer.DelegationFee < 0 || offer.Offer.DelegationFee > 100:
+		return errInvalidDelegationRate
+	case uint64(offer.Offer.MinDelegation) < vm.MinDelegatorStake:
+		return fmt.Errorf("%w: %d < %d", errOfferMinDelegation, offer.Offer.MinDelegation, vm.MinDelegatorStake)
+	}
+
+	// The signer is checked before the signature, so that offers that can't
+	// be accepted don't cost a BLS verification.
+	vdr, ok := vm.Validators.GetValidator(constants.PrimaryNetworkID, offer.Offer.NodeID)
+	if !ok {
+		return fmt.Errorf("%w: %s", errOfferNotValidator, offer.Offer.NodeID)
+	}
+	// If the validator registered a BLS key, its offer must be signed with
+	// it.
+	if vdr.PublicKey != nil {
+		pkBytes, err := formatting.Decode(formatting.HexNC, offer.PublicKey)
+		if err != nil || !bytes.Equal(bls.PublicKeyToBytes(vdr.PublicKey), pkBytes) {
+			return fmt.Errorf("%w: %s", errWrongOfferSigner, offer.Offer.NodeID)
+		}
+	}
+	_, err := offer.Verify(vm.ctx.ChainID)
+	return err
+}
+
+// addDelegationOffer verifies [offer] and records it in the registry.
+// Returns [errOfferOutdated] if a newer offer from the same validator is
+// already known, and [errOfferRateLimited] if the known offer was issued too
+// recently.
+// Assumes [vm.ctx.Lock] is held.
+func (vm *VM) addDelegationOffer(offer *SignedDelegationOffer) error {
+	if err := vm.verifyDelegationOffer(offer); err != nil {
+		return err
+	}
+	return vm.delegationOffers.put(offer)
+}
+
+// gossipDelegationOffer sends [offer] to peers if gossiping delegation offers
+// is enabled.
+func (vm *VM) gossipDelegationOffer(ctx context.Context, offer *SignedDelegationOffer) error {
+	if !vm.delegationOfferGossipEnabled {
+		return nil
+	}
+
+	offerBytes, err := stdjson.Marshal(offer)
+	if err != nil {
+		return err
+	}
+	msgBytes, err := message.Build(&message.DelegationOffer{
+		Offer: offerBytes,
+	})
+	if err != nil {
+		return err
+	}
+	return vm.appSender.SendAppGossip(ctx, msgBytes)
+}
+
+// AppGossip handles gossiped delegation offers, and passes all other messages
+// to the network.
+func (vm *VM) AppGossip(ctx context.Context, nodeID ids.NodeID, msgBytes []byte) error {
+	msgIntf, err := message.Parse(msgBytes)
+	if err != nil {
+		return vm.Network.AppGossip(ctx, nodeID, msgBytes)
+	}
+	msg, ok := msgIntf.(*message.DelegationOffer)
+	if !ok {
+		return vm.Network.AppGossip(ctx, nodeID, msgBytes)
+	}
+
+	if !vm.delegationOfferGossipEnabled {
+		vm.ctx.Log.Debug("dropping delegation offer",
+			zap.Stringer("nodeID", nodeID),
+			zap.Error(errOfferGossipDisabled),
+		)
+		return nil
+	}
+
+	offer := &SignedDelegationOffer{}
+	if err := stdjson.Unmarshal(msg.Offer, offer); err != nil {
+		vm.ctx.Log.Debug("dropping delegation offer",
+			zap.Stringer("nodeID", nodeID),
+			zap.String("reason", "failed to parse offer"),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	vm.ctx.Lock.Lock()
+	err = vm.addDelegationOffer(offer)
+	vm.ctx.Lock.Unlock()
+	if err != nil {
+		vm.ctx.Log.Debug("dropping delegation offer",
+			zap.Stringer("nodeID", nodeID),
+			zap.Stringer("offerNodeID", offer.Offer.NodeID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	// Only offers that weren't known are forwarded, so gossip stops once
+	// every peer knows the offer.
+	if err := vm.appSender.SendAppGossip(ctx, msgBytes); err != nil {
+		vm.ctx.Log.Debug("failed to gossip delegation offer",
+			zap.Stringer("offerNodeID", offer.Offer.NodeID),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
@@ -29,7 +29,9 @@ type txMetrics struct {
 	numAddPermissionlessValidatorTxs,
 	numAddPermissionlessDelegatorTxs,
 	numTransferSubnetOwnershipTxs,
-	numBaseTxs prometheus.Counter
+	numBaseTxs,
+	numRevokeAddressesTxs,
+	numClaimFeeRefundTxs prometheus.Counter
 }
 
 func newTxMetrics(
@@ -53,6 +55,8 @@ func newTxMetrics(
 		numAddPermissionlessDelegatorTxs: newTxMetric(namespace, "add_permissionless_delegator", registerer, &errs),
 		numTransferSubnetOwnershipTxs:    newTxMetric(namespace, "transfer_subnet_ownership", registerer, &errs),
 		numBaseTxs:                       newTxMetric(namespace, "base", registerer, &errs),
+		numRevokeAddressesTxs:            newTxMetric(namespace, "revoke_addresses", registerer, &errs),
+		numClaimFeeRefundTxs:             newTxMetric(namespace, "claim_fee_refund", registerer, &errs),
 	}
 	return m, errs.Err
 }
@@ -146,3 +150,13 @@ func (m *txMetrics) BaseTx(*txs.BaseTx) error {
 	m.numBaseTxs.Inc()
 	return nil
 }
+
+func (m *txMetrics) RevokeAddressesTx(*txs.RevokeAddressesTx) error {
+	m.numRevokeAddressesTxs.Inc()
+	return nil
+}
+
+func (m *txMetrics) ClaimFeeRefundTx(*txs.ClaimFeeRefundTx) error {
+	m.numClaimFeeRefundTxs.Inc()
+	return nil
+}
@@ -4,6 +4,7 @@
 package platformvm
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -16,12 +17,15 @@ import (
 	"go.uber.org/zap"
 
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/subnets"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
@@ -58,6 +62,10 @@ const (
 	// API
 	minAddStakerDelay = 2 * executor.SyncBound
 
+	// Max number of blocks whose weight changes can be requested in a single
+	// call to GetValidatorWeightHistory
+	maxValidatorWeightHistoryHeights = 100_000
+
 	// Note: Staker attributes cache should be large enough so that no evictions
 	// happen when the API loops through all stakers.
 	stakerAttributesCacheSize = 100_000
@@ -80,6 +88,14 @@ var (
 	errMissingPrivateKey        = errors.New("argument 'privateKey' not given")
 	errStartAfterEndTime        = errors.New("start time must be before end time")
 	errStartTimeInThePast       = errors.New("start time in the past")
+	errFromHeightAfterToHeight  = errors.New("argument 'fromHeight' must be <= 'toHeight'")
+	errHeightRangeTooLarge      = fmt.Errorf("height range must contain at most %d blocks", maxValidatorWeightHistoryHeights)
+	errMissingBLSKey            = errors.New("node doesn't have a BLS key")
+	errWrongNetworkID           = errors.New("wrong networkID")
+	errWrongScheduleSigner      = errors.New("staker schedule isn't signed with the validator's BLS key")
+	errUnknownTx                = errors.New("tx isn't known to this node")
+	errNotExportTx              = errors.New("tx isn't an export tx")
+	errNoOutboundReader         = errors.New("shared memory can't report the status of exported outputs")
 )
 
 // Service defines the API calls that can be made to the platform chain
@@ -237,16 +253,56 @@ func (s *Service) GetBalance(_ *http.Request, args *GetBalanceRequest, response
 	s.vm.ctx.Lock.Lock()
 	defer s.vm.ctx.Lock.Unlock()
 
-	utxos, err := avax.GetAllUTXOs(s.vm.state, addrs)
+	balances, err := s.getBalances(addrs)
 	if err != nil {
 		return fmt.Errorf("couldn't get UTXO set of %v: %w", args.Addresses, err)
 	}
 
+	response.Balances = newJSONBalanceMap(balances.total)
+	response.Unlockeds = newJSONBalanceMap(balances.unlocked)
+	response.LockedStakeables = newJSONBalanceMap(balances.lockedStakeable)
+	response.LockedNotStakeables = newJSONBalanceMap(balances.lockedNotStakeable)
+	response.UTXOIDs = balances.utxoIDs
+	response.Balance = response.Balances[s.vm.ctx.AVAXAssetID]
+	response.Unlocked = response.Unlockeds[s.vm.ctx.AVAXAssetID]
+	response.LockedStakeable = response.LockedStakeables[s.vm.ctx.AVAXAssetID]
+	response.LockedNotStakeable = response.LockedNotStakeables[s.vm.ctx.AVAXAssetID]
+	return nil
+}
+
+func newJSONBalanceMap(balanceMap map[ids.ID]uint64) map[ids.ID]json.Uint64 {
+	jsonBalanceMap := make(map[ids.ID]json.Uint64, len(balanceMap))
+	for assetID, amount := range balanceMap {
+		jsonBalanceMap[assetID] = json.Uint64(amount)
+	}
+	return jsonBalanceMap
+}
+
+// addressBalances are the balances of a set of addresses, keyed by asset ID
+type addressBalances struct {
+	total              map[ids.ID]uint64
+	unlocked           map[ids.ID]uint64
+	lockedStakeable    map[ids.ID]uint64
+	lockedNotStakeable map[ids.ID]uint64
+	utxoIDs            []*avax.UTXOID
+}
+
+// getBalances returns the balances of [addrs] at the current time.
+//
+// Assumes [s.vm.ctx.Lock] is held.
+func (s *Service) getBalances(addrs set.Set[ids.ShortID]) (*addressBalances, error) {
+	utxos, err := avax.GetAllUTXOs(s.vm.state, addrs)
+	if err != nil {
+		return nil, err
+	}
+
 	currentTime := s.vm.clock.Unix()
 
-	unlockeds := map[ids.ID]uint64{}
-	lockedStakeables := map[ids.ID]uint64{}
-	lockedNotStakeables := map[ids.ID]uint64{}
+	b := &addressBalances{
+		unlocked:           map[ids.ID]uint64{},
+		lockedStakeable:    map[ids.ID]uint64{},
+		lockedNotStakeable: map[ids.ID]uint64{},
+	}
 
 utxoFor:
 	for _, utxo := range utxos {
@@ -254,18 +310,18 @@ utxoFor:
 		switch out := utxo.Out.(type) {
 		case *secp256k1fx.TransferOutput:
 			if out.Locktime <= currentTime {
-				newBalance, err := safemath.Add64(unlockeds[assetID], out.Amount())
+				newBalance, err := safemath.Add64(b.unlocked[assetID], out.Amount())
 				if err != nil {
-					unlockeds[assetID] = math.MaxUint64
+					b.unlocked[assetID] = math.MaxUint64
 				} else {
-					unlockeds[assetID] = newBalance
+					b.unlocked[assetID] = newBalance
 				}
 			} else {
-				newBalance, err := safemath.Add64(lockedNotStakeables[assetID], out.Amount())
+				newBalance, err := safemath.Add64(b.lockedNotStakeable[assetID], out.Amount())
 				if err != nil {
-					lockedNotStakeables[assetID] = math.MaxUint64
+					b.lockedNotStakeable[assetID] = math.MaxUint64
 				} else {
-					lockedNotStakeables[assetID] = newBalance
+					b.lockedNotStakeable[assetID] = newBalance
 				}
 			}
 		case *stakeable.LockOut:
@@ -277,69 +333,53 @@ utxoFor:
 				)
 				continue utxoFor
 			case innerOut.Locktime > currentTime:
-				newBalance, err := safemath.Add64(lockedNotStakeables[assetID], out.Amount())
+				newBalance, err := safemath.Add64(b.lockedNotStakeable[assetID], out.Amount())
 				if err != nil {
-					lockedNotStakeables[assetID] = math.MaxUint64
+					b.lockedNotStakeable[assetID] = math.MaxUint64
 				} else {
-					lockedNotStakeables[assetID] = newBalance
+					b.lockedNotStakeable[assetID] = newBalance
 				}
 			case out.Locktime <= currentTime:
-				newBalance, err := safemath.Add64(unlockeds[assetID], out.Amount())
+				newBalance, err := safemath.Add64(b.unlocked[assetID], out.Amount())
 				if err != nil {
-					unlockeds[assetID] = math.MaxUint64
+					b.unlocked[assetID] = math.MaxUint64
 				} else {
-					unlockeds[assetID] = newBalance
+					b.unlocked[assetID] = newBalance
 				}
 			default:
-				newBalance, err := safemath.Add64(lockedStakeables[assetID], out.Amount())
+				newBalance, err := safemath.Add64(b.lockedStakeable[assetID], out.Amount())
 				if err != nil {
-					lockedStakeables[assetID] = math.MaxUint64
+					b.lockedStakeable[assetID] = math.MaxUint64
 				} else {
-					lockedStakeables[assetID] = newBalance
+					b.lockedStakeable[assetID] = newBalance
 				}
 			}
 		default:
 			continue utxoFor
 		}
 
-		response.UTXOIDs = append(response.UTXOIDs, &utxo.UTXOID)
+		b.utxoIDs = append(b.utxoIDs, &utxo.UTXOID)
 	}
 
-	balances := maps.Clone(lockedStakeables)
-	for assetID, amount := range lockedNotStakeables {
-		newBalance, err := safemath.Add64(balances[assetID], amount)
+	b.total = maps.Clone(b.lockedStakeable)
+	for assetID, amount := range b.lockedNotStakeable {
+		newBalance, err := safemath.Add64(b.total[assetID], amount)
 		if err != nil {
-			balances[assetID] = math.MaxUint64
+			b.total[assetID] = math.MaxUint64
 		} else {
-			balances[assetID] = newBalance
+			b.total[assetID] = newBalance
 		}
 	}
-	for assetID, amount := range unlockeds {
-		newBalance, err := safemath.Add64(balances[assetID], amount)
+	for assetID, amount := range b.unlocked {
+		newBalance, err := safemath.Add64(b.total[assetID], amount)
 		if err != nil {
-			balances[assetID] = math.MaxUint64
+			b.total[assetID] = math.MaxUint64
 		} else {
-			balances[assetID] = newBalance
+			b.total[assetID] = newBalance
 		}
 	}
 
-	response.Balances = newJSONBalanceMap(balances)
-	response.Unlockeds = newJSONBalanceMap(unlockeds)
-	response.LockedStakeables = newJSONBalanceMap(lockedStakeables)
-	response.LockedNotStakeables = newJSONBalanceMap(lockedNotStakeables)
-	response.Balance = response.Balances[s.vm.ctx.AVAXAssetID]
-	response.Unlocked = response.Unlockeds[s.vm.ctx.AVAXAssetID]
-	response.LockedStakeable = response.LockedStakeables[s.vm.ctx.AVAXAssetID]
-	response.LockedNotStakeable = response.LockedNotStakeables[s.vm.ctx.AVAXAssetID]
-	return nil
-}
-
-func newJSONBalanceMap(balanceMap map[ids.ID]uint64) map[ids.ID]json.Uint64 {
-	jsonBalanceMap := make(map[ids.ID]json.Uint64, len(balanceMap))
-	for assetID, amount := range balanceMap {
-		jsonBalanceMap[assetID] = json.Uint64(amount)
-	}
-	return jsonBalanceMap
+	return b, nil
 }
 
 // CreateAddress creates an address controlled by [args.Username]
@@ -869,14 +909,14 @@ func (s *Service) GetCurrentValidators(_ *http.Request, args *GetCurrentValidato
 				validationRewardOwner *platformapi.Owner
 				delegationRewardOwner *platformapi.Owner
 			)
-			validationOwner, ok := attr.validationRewardsOwner.(*secp256k1fx.OutputOwners)
+			validationOwner, ok := primaryRewardsOwner(attr.validationRewardsOwner)
 			if ok {
 				validationRewardOwner, err = s.getAPIOwner(validationOwner)
 				if err != nil {
 					return err
 				}
 			}
-			delegationOwner, ok := attr.delegationRewardsOwner.(*secp256k1fx.OutputOwners)
+			delegationOwner, ok := primaryRewardsOwner(attr.delegationRewardsOwner)
 			if ok {
 				delegationRewardOwner, err = s.getAPIOwner(delegationOwner)
 				if err != nil {
@@ -907,7 +947,7 @@ func (s *Service) GetCurrentValidators(_ *http.Request, args *GetCurrentValidato
 				if err != nil {
 					return err
 				}
-				owner, ok := attr.rewardsOwner.(*secp256k1fx.OutputOwners)
+				owner, ok := primaryRewardsOwner(attr.rewardsOwner)
 				if ok {
 					rewardOwner, err = s.getAPIOwner(owner)
 					if err != nil {
@@ -1093,6 +1133,441 @@ func (s *Service) GetPendingValidators(_ *http.Request, args *GetPendingValidato
 	return nil
 }
 
+// GetSubnetValidatorQueueArgs are the arguments for calling
+// GetSubnetValidatorQueue
+type GetSubnetValidatorQueueArgs struct {
+	SubnetID ids.ID `json:"subnetID"`
+}
+
+// APIChurnEpoch is an epoch of a subnet's churn limit in which changes to the
+// subnet's validator set are scheduled.
+type APIChurnEpoch struct {
+	StartTime json.Uint64 `json:"startTime"`
+	// Number of validators added to or removed from the validator set
+	Changes json.Uint64 `json:"changes"`
+	// Number of changes that can still be scheduled
+	RemainingChanges json.Uint64 `json:"remainingChanges"`
+}
+
+// GetSubnetValidatorQueueReply is the response from calling
+// GetSubnetValidatorQueue
+type GetSubnetValidatorQueueReply struct {
+	// Chain time that the queue was inspected at
+	Timestamp time.Time `json:"timestamp"`
+	// Churn limit of the subnet. Nil if the validator set isn't limited.
+	ChurnLimit *subnets.ChurnLimit `json:"churnLimit,omitempty"`
+	// Validators that haven't been added to the validator set yet, in order
+	// of increasing start time. Each validator is expected to be added at its
+	// start time.
+	Queue []platformapi.Staker `json:"queue"`
+	// Epochs, from the one containing [Timestamp] on, in which changes are
+	// scheduled, in order of increasing start time
+	Epochs []APIChurnEpoch `json:"epochs"`
+	// Earliest time a new validator can start without exceeding the churn
+	// limit. The epoch of the validator's end time must also have a remaining
+	// change.
+	NextAvailableStartTime time.Time `json:"nextAvailableStartTime"`
+}
+
+// GetSubnetValidatorQueue returns the permissioned validators of
+// [args.SubnetID] that are waiting to be added to its validator set, along
+// with the changes to the validator set scheduled in each epoch of the
+// subnet's churn limit.
+func (s *Service) GetSubnetValidatorQueue(_ *http.Request, args *GetSubnetValidatorQueueArgs, reply *GetSubnetValidatorQueueReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getSubnetValidatorQueue"),
+		zap.Stringer("subnetID", args.SubnetID),
+	)
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	reply.Timestamp = s.vm.state.GetTimestamp()
+	reply.NextAvailableStartTime = reply.Timestamp
+
+	pendingStakerIterator, err := s.vm.state.GetPendingStakerIterator()
+	if err != nil {
+		return err
+	}
+	defer pendingStakerIterator.Release()
+
+	reply.Queue = []platformapi.Staker{}
+	for pendingStakerIterator.Next() { // Iterates in order of increasing start time
+		staker := pendingStakerIterator.Value()
+		if staker.SubnetID != args.SubnetID || !staker.Priority.IsPermissionedValidator() {
+			continue
+		}
+		weight := json.Uint64(staker.Weight)
+		reply.Queue = append(reply.Queue, platformapi.Staker{
+			TxID:        staker.TxID,
+			NodeID:      staker.NodeID,
+			StartTime:   json.Uint64(staker.StartTime.Unix()),
+			EndTime:     json.Uint64(staker.EndTime.Unix()),
+			Weight:      weight,
+			StakeAmount: &weight,
+		})
+	}
+
+	reply.Epochs = []APIChurnEpoch{}
+	limit, ok := s.vm.GetSubnetChurnLimit(args.SubnetID)
+	if !ok {
+		return nil
+	}
+	reply.ChurnLimit = &limit
+
+	churn, err := executor.GetSubnetChurn(s.vm.state, args.SubnetID, limit)
+	if err != nil {
+		return err
+	}
+	epochs := maps.Keys(churn)
+	slices.Sort(epochs)
+	for _, epoch := range epochs {
+		changes := churn[epoch]
+		var remainingChanges uint64
+		if changes < limit.MaxChanges {
+			remainingChanges = limit.MaxChanges - changes
+		}
+		reply.Epochs = append(reply.Epochs, APIChurnEpoch{
+			StartTime:        json.Uint64(limit.EpochStartTime(epoch).Unix()),
+			Changes:          json.Uint64(changes),
+			RemainingChanges: json.Uint64(remainingChanges),
+		})
+	}
+
+	availableEpoch := limit.Epoch(reply.Timestamp)
+	for churn[availableEpoch] >= limit.MaxChanges {
+		availableEpoch++
+	}
+	if epochStartTime := limit.EpochStartTime(availableEpoch); epochStartTime.After(reply.Timestamp) {
+		reply.NextAvailableStartTime = epochStartTime
+	}
+	return nil
+}
+
+// ProjectValidatorSetArgs are the arguments for calling ProjectValidatorSet
+type ProjectValidatorSetArgs struct {
+	SubnetID ids.ID `json:"subnetID"`
+	// Times, in unix seconds, to project the validator set at. Must not be
+	// before the chain time.
+	Timestamps []json.Uint64 `json:"timestamps"`
+	// Stakers that are assumed to be added, in addition to the pending
+	// stakers. If the subnet has a churn limit, additions are applied in
+	// order and those that would exceed the limit are left out.
+	Additions []HypotheticalStaker `json:"additions"`
+	// Validators that are assumed to be removed before their end time
+	Removals []HypotheticalRemoval `json:"removals"`
+}
+
+// ProjectValidatorSetReply is the response from calling ProjectValidatorSet
+type ProjectValidatorSetReply struct {
+	// Chain time that the projections were made from
+	Timestamp time.Time `json:"timestamp"`
+	// Projections in the order of [ProjectValidatorSetArgs.Timestamps]
+	Projections []ValidatorSetProjection `json:"projections"`
+	// Additions that were left out of the projections because they would
+	// exceed the subnet's churn limit, in the order of
+	// [ProjectValidatorSetArgs.Additions]
+	ChurnLimitedAdditions []HypotheticalStaker `json:"churnLimitedAdditions"`
+}
+
+// ProjectValidatorSet projects the validator set and total weight of
+// [args.SubnetID] at future timestamps, from its current and pending stakers
+// and the hypothetical changes in [args]. Stakers are assumed to be added at
+// their start time and removed at their end time.
+func (s *Service) ProjectValidatorSet(_ *http.Request, args *ProjectValidatorSetArgs, reply *ProjectValidatorSetReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "projectValidatorSet"),
+		zap.Stringer("subnetID", args.SubnetID),
+		zap.Int("numTimestamps", len(args.Timestamps)),
+	)
+
+	switch {
+	case len(args.Timestamps) == 0:
+		return errNoProjectionTimestamps
+	case len(args.Timestamps) > maxProjectionTimestamps:
+		return errTooManyProjectionTimes
+	case len(args.Additions) > maxHypotheticalAdditions:
+		return errTooManyAdditions
+	case len(args.Removals) > maxHypotheticalRemovals:
+		return errTooManyRemovals
+	}
+
+	// The stakers are copied out of the state so that the projections are
+	// made without holding the lock.
+	projector := &validatorSetProjector{}
+	var err error
+	reply.Timestamp, err = s.loadValidatorSetProjector(args.SubnetID, projector)
+	if err != nil {
+		return err
+	}
+	for _, timestamp := range args.Timestamps {
+		if time.Unix(int64(timestamp), 0).Before(reply.Timestamp) {
+			return fmt.Errorf("%w: %d < %d", errProjectionInThePast, timestamp, reply.Timestamp.Unix())
+		}
+	}
+
+	reply.ChurnLimitedAdditions = []HypotheticalStaker{}
+	for _, staker := range args.Additions {
+		err := projector.addHypotheticalStaker(staker)
+		switch {
+		case errors.Is(err, errHypotheticalChurnLimited):
+			reply.ChurnLimitedAdditions = append(reply.ChurnLimitedAdditions, staker)
+		case err != nil:
+			return err
+		}
+	}
+	for _, removal := range args.Removals {
+		projector.addHypotheticalRemoval(removal)
+	}
+
+	reply.Projections = make([]ValidatorSetProjection, len(args.Timestamps))
+	for i, timestamp := range args.Timestamps {
+		reply.Projections[i], err = projector.project(time.Unix(int64(timestamp), 0))
+		if err != nil {
+			return fmt.Errorf("couldn't project validator set: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadValidatorSetProjector adds the current and pending stakers of
+// [subnetID] to [projector], and limits its hypothetical stakers by the
+// subnet's churn limit, if it has one. Returns the chain time.
+func (s *Service) loadValidatorSetProjector(subnetID ids.ID, projector *validatorSetProjector) (time.Time, error) {
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	for _, getIterator := range []func() (state.StakerIterator, error){
+		s.vm.state.GetCurrentStakerIterator,
+		s.vm.state.GetPendingStakerIterator,
+	} {
+		stakerIterator, err := getIterator()
+		if err != nil {
+			return time.Time{}, err
+		}
+		for stakerIterator.Next() {
+			if staker := stakerIterator.Value(); staker.SubnetID == subnetID {
+				projector.addStaker(staker)
+			}
+		}
+		stakerIterator.Release()
+	}
+
+	if limit, ok := s.vm.GetSubnetChurnLimit(subnetID); ok {
+		churn, err := executor.GetSubnetChurn(s.vm.state, subnetID, limit)
+		if err != nil {
+			return time.Time{}, err
+		}
+		projector.setChurn(limit, churn)
+	}
+	return s.vm.state.GetTimestamp(), nil
+}
+
+// ValidateStakerScheduleReply is the response from calling
+// ValidateStakerSchedule
+type ValidateStakerScheduleReply struct {
+	// Height of the last accepted block of this node
+	Height json.Uint64 `json:"height"`
+	// True if the schedule matches this node's state
+	Matches bool `json:"matches"`
+	// Current stakers in the schedule that aren't current stakers in this
+	// node's state
+	MissingCurrent []ScheduledStaker `json:"missingCurrent"`
+	// Current stakers in this node's state that aren't current stakers in the
+	// schedule
+	UnexpectedCurrent []ScheduledStaker `json:"unexpectedCurrent"`
+	// Pending stakers in the schedule that aren't pending stakers in this
+	// node's state
+	MissingPending []ScheduledStaker `json:"missingPending"`
+	// Pending stakers in this node's state that aren't pending stakers in the
+	// schedule
+	UnexpectedPending []ScheduledStaker `json:"unexpectedPending"`
+}
+
+// ValidateStakerSchedule verifies the signature of a schedule returned by
+// ExportStakerSchedule and compares it to the stakers in this node's state.
+//
+// If the schedule was exported at a different height than this node's last
+// accepted block, differences may be caused by the blocks accepted in between
+// rather than by divergent state.
+func (s *Service) ValidateStakerSchedule(r *http.Request, args *SignedStakerSchedule, reply *ValidateStakerScheduleReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "validateStakerSchedule"),
+		zap.Stringer("nodeID", args.NodeID),
+	)
+
+	if args.Schedule.NetworkID != s.vm.ctx.NetworkID {
+		return fmt.Errorf("%w: expected %d but got %d",
+			errWrongNetworkID,
+			s.vm.ctx.NetworkID,
+			args.Schedule.NetworkID,
+		)
+	}
+	pk, err := args.Verify(s.vm.ctx.ChainID)
+	if err != nil {
+		return err
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	// If the exporter is a validator, its schedule must be signed with its
+	// registered BLS key.
+	if vdr, ok := s.vm.Validators.GetValidator(constants.PrimaryNetworkID, args.NodeID); ok && vdr.PublicKey != nil {
+		if !bytes.Equal(bls.PublicKeyToBytes(vdr.PublicKey), bls.PublicKeyToBytes(pk)) {
+			return fmt.Errorf("%w: %s", errWrongScheduleSigner, args.NodeID)
+		}
+	}
+
+	schedule, err := s.vm.getStakerSchedule(r.Context())
+	if err != nil {
+		return err
+	}
+
+	reply.Height = schedule.Height
+	reply.MissingCurrent, reply.UnexpectedCurrent = diffStakers(args.Schedule.Current, schedule.Current)
+	reply.MissingPending, reply.UnexpectedPending = diffStakers(args.Schedule.Pending, schedule.Pending)
+	reply.Matches = len(reply.MissingCurrent) == 0 &&
+		len(reply.UnexpectedCurrent) == 0 &&
+		len(reply.MissingPending) == 0 &&
+		len(reply.UnexpectedPending) == 0
+	return nil
+}
+
+// VerifyCheckpointReply is the response from calling VerifyCheckpoint
+type VerifyCheckpointReply struct {
+	// Trusted nodes that signed the checkpoint
+	Signers []ids.NodeID `json:"signers"`
+	// Height of the last accepted block of this node
+	Height json.Uint64 `json:"height"`
+	// True if this node has accepted a block at the checkpoint's height
+	Reached bool `json:"reached"`
+	// True if this node has accepted the checkpointed block and its validator
+	// set at the checkpoint's height matches the checkpoint. The state root
+	// isn't compared, since it's checked when the exported state is imported.
+	Matches bool `json:"matches"`
+}
+
+// VerifyCheckpoint verifies that a checkpoint returned by ExportCheckpoint is
+// signed by enough of the signers in this node's checkpoint trust set, and
+// compares it to this node's accepted chain.
+func (s *Service) VerifyCheckpoint(r *http.Request, args *SignedCheckpoint, reply *VerifyCheckpointReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "verifyCheckpoint"),
+		zap.Uint64("height", uint64(args.Checkpoint.Height)),
+	)
+
+	if len(s.vm.checkpointTrustSet) == 0 {
+		return errNoCheckpointTrustSet
+	}
+	if args.Checkpoint.NetworkID != s.vm.ctx.NetworkID {
+		return fmt.Errorf("%w: expected %d but got %d",
+			errWrongNetworkID,
+			s.vm.ctx.NetworkID,
+			args.Checkpoint.NetworkID,
+		)
+	}
+	signers, err := args.Verify(s.vm.ctx.ChainID, s.vm.checkpointTrustSet, s.vm.checkpointSignerThreshold)
+	if err != nil {
+		return err
+	}
+	reply.Signers = signers.List()
+	utils.Sort(reply.Signers)
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	ctx := r.Context()
+	height, err := s.vm.GetCurrentHeight(ctx)
+	if err != nil {
+		return err
+	}
+	reply.Height = json.Uint64(height)
+
+	checkpointHeight := uint64(args.Checkpoint.Height)
+	reply.Reached = height >= checkpointHeight
+	if !reply.Reached {
+		return nil
+	}
+
+	checkpoint, err := s.vm.getCheckpoint(ctx, checkpointHeight)
+	if err != nil {
+		return err
+	}
+	reply.Matches = checkpoint.BlockID == args.Checkpoint.BlockID &&
+		checkpoint.ValidatorSetHash == args.Checkpoint.ValidatorSetHash
+	return nil
+}
+
+// SubmitDelegationOffer verifies an offer returned by SetDelegationOffer on
+// another node and adds it to this node's registry of delegation offers.
+func (s *Service) SubmitDelegationOffer(r *http.Request, args *SignedDelegationOffer, _ *api.EmptyReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "submitDelegationOffer"),
+		zap.Stringer("nodeID", args.Offer.NodeID),
+	)
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	if err := s.vm.addDelegationOffer(args); err != nil {
+		return err
+	}
+	return s.vm.gossipDelegationOffer(r.Context(), args)
+}
+
+// GetDelegationOffersArgs are the arguments for calling GetDelegationOffers
+type GetDelegationOffersArgs struct {
+	// If provided, only the offers of these nodes are returned
+	NodeIDs []ids.NodeID `json:"nodeIDs"`
+	// Only offers with at least this much capacity are returned
+	MinCapacity json.Uint64 `json:"minCapacity"`
+}
+
+// GetDelegationOffersReply is the response from calling GetDelegationOffers
+type GetDelegationOffersReply struct {
+	// Offers sorted by increasing delegation fee
+	Offers []*SignedDelegationOffer `json:"offers"`
+}
+
+// GetDelegationOffers returns the unexpired delegation offers known to this
+// node from current primary network validators. The offers aren't part of
+// consensus, so different nodes may know different offers.
+func (s *Service) GetDelegationOffers(_ *http.Request, args *GetDelegationOffersArgs, reply *GetDelegationOffersReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getDelegationOffers"),
+	)
+
+	nodeIDs := set.Of(args.NodeIDs...)
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	// Offers from nodes that stopped validating are removed.
+	offers := s.vm.delegationOffers.list(s.vm.clock.Time(), func(offer *SignedDelegationOffer) bool {
+		_, ok := s.vm.Validators.GetValidator(constants.PrimaryNetworkID, offer.Offer.NodeID)
+		return ok
+	})
+
+	reply.Offers = make([]*SignedDelegationOffer, 0, len(offers))
+	for _, offer := range offers {
+		if nodeIDs.Len() > 0 && !nodeIDs.Contains(offer.Offer.NodeID) {
+			continue
+		}
+		if offer.Offer.Capacity < args.MinCapacity {
+			continue
+		}
+		reply.Offers = append(reply.Offers, offer)
+	}
+	return nil
+}
+
 // GetCurrentSupplyArgs are the arguments for calling GetCurrentSupply
 type GetCurrentSupplyArgs struct {
 	SubnetID ids.ID `json:"subnetID"`
@@ -2084,16 +2559,56 @@ type APIBlockchain struct {
 
 	// Virtual Machine the blockchain runs
 	VMID ids.ID `json:"vmID"`
+
+	// The following fields are only set if [GetBlockchainsArgs.IncludeStatus]
+	// is true.
+
+	// Whether this node finished bootstrapping the blockchain
+	Bootstrapped *bool `json:"bootstrapped,omitempty"`
+
+	// Number of current validators of the subnet that validates the
+	// blockchain
+	ValidatorCount *json.Uint64 `json:"validatorCount,omitempty"`
+
+	// Height of the block that created the blockchain. Omitted if the height
+	// wasn't recorded when the blockchain was created.
+	CreationHeight *json.Uint64 `json:"creationHeight,omitempty"`
+}
+
+// GetBlockchainsArgs are the arguments for calling GetBlockchains
+type GetBlockchainsArgs struct {
+	// If non-empty, only the blockchains validated by these subnets are
+	// returned.
+	SubnetIDs []ids.ID `json:"subnetIDs"`
+	// If non-empty, only the blockchains that run these VMs are returned.
+	VMIDs []ids.ID `json:"vmIDs"`
+	// The number of matching blockchains to skip.
+	StartIndex json.Uint64 `json:"startIndex"`
+	// The maximum number of blockchains to return. If 0, every matching
+	// blockchain after [StartIndex] is returned.
+	Limit json.Uint64 `json:"limit"`
+	// If true, the bootstrapping status, validator count and creation height
+	// of each blockchain are returned.
+	IncludeStatus bool `json:"includeStatus"`
 }
 
 // GetBlockchainsResponse is the response from a call to GetBlockchains
 type GetBlockchainsResponse struct {
 	// blockchains that exist
 	Blockchains []APIBlockchain `json:"blockchains"`
+	// The number of blockchains that match the filters, including the ones
+	// that weren't returned because of [GetBlockchainsArgs.StartIndex] and
+	// [GetBlockchainsArgs.Limit].
+	Total json.Uint64 `json:"total"`
 }
 
-// GetBlockchains returns all of the blockchains that exist
-func (s *Service) GetBlockchains(_ *http.Request, _ *struct{}, response *GetBlockchainsResponse) error {
+// GetBlockchains returns the blockchains that exist.
+//
+// Blockchains are ordered by subnet ID, with the blockchains of the primary
+// network last, and by blockchain ID within each subnet. If subnets are given,
+// they're ordered as given instead. Pages are indexed against this order, so a
+// blockchain created while paging may shift the following pages by one.
+func (s *Service) GetBlockchains(_ *http.Request, args *GetBlockchainsArgs, response *GetBlockchainsResponse) error {
 	s.vm.ctx.Log.Debug("deprecated API called",
 		zap.String("service", "platform"),
 		zap.String("method", "getBlockchains"),
@@ -2102,14 +2617,34 @@ func (s *Service) GetBlockchains(_ *http.Request, _ *struct{}, response *GetBloc
 	s.vm.ctx.Lock.Lock()
 	defer s.vm.ctx.Lock.Unlock()
 
-	subnets, err := s.vm.state.GetSubnets()
-	if err != nil {
-		return fmt.Errorf("couldn't retrieve subnets: %w", err)
+	subnetIDs := args.SubnetIDs
+	if len(subnetIDs) == 0 {
+		subnets, err := s.vm.state.GetSubnets()
+		if err != nil {
+			return fmt.Errorf("couldn't retrieve subnets: %w", err)
+		}
+		subnetIDs = make([]ids.ID, 0, len(subnets)+1)
+		for _, subnet := range subnets {
+			subnetIDs = append(subnetIDs, subnet.ID())
+		}
+		utils.Sort(subnetIDs)
+		subnetIDs = append(subnetIDs, constants.PrimaryNetworkID)
 	}
+	vmIDs := set.Of(args.VMIDs...)
 
+	var (
+		startIndex = uint64(args.StartIndex)
+		limit      = uint64(args.Limit)
+		seen       set.Set[ids.ID]
+		matched    uint64
+	)
 	response.Blockchains = []APIBlockchain{}
-	for _, subnet := range subnets {
-		subnetID := subnet.ID()
+	for _, subnetID := range subnetIDs {
+		if seen.Contains(subnetID) {
+			continue
+		}
+		seen.Add(subnetID)
+
 		chains, err := s.vm.state.GetChains(subnetID)
 		if err != nil {
 			return fmt.Errorf(
@@ -2118,40 +2653,60 @@ func (s *Service) GetBlockchains(_ *http.Request, _ *struct{}, response *GetBloc
 				err,
 			)
 		}
+		// Don't sort [chains] in place because it may be cached by the state.
+		chains = slices.Clone(chains)
+		slices.SortFunc(chains, func(a, b *txs.Tx) bool {
+			return a.ID().Less(b.ID())
+		})
 
 		for _, chainTx := range chains {
-			chainID := chainTx.ID()
 			chain, ok := chainTx.Unsigned.(*txs.CreateChainTx)
 			if !ok {
 				return fmt.Errorf("expected tx type *txs.CreateChainTx but got %T", chainTx.Unsigned)
 			}
-			response.Blockchains = append(response.Blockchains, APIBlockchain{
-				ID:       chainID,
+			if vmIDs.Len() > 0 && !vmIDs.Contains(chain.VMID) {
+				continue
+			}
+
+			matched++
+			if matched <= startIndex || (limit > 0 && uint64(len(response.Blockchains)) >= limit) {
+				continue
+			}
+
+			blockchain := APIBlockchain{
+				ID:       chainTx.ID(),
 				Name:     chain.ChainName,
 				SubnetID: subnetID,
 				VMID:     chain.VMID,
-			})
+			}
+			if args.IncludeStatus {
+				if err := s.setBlockchainStatus(&blockchain); err != nil {
+					return err
+				}
+			}
+			response.Blockchains = append(response.Blockchains, blockchain)
 		}
 	}
+	response.Total = json.Uint64(matched)
+	return nil
+}
 
-	chains, err := s.vm.state.GetChains(constants.PrimaryNetworkID)
-	if err != nil {
-		return fmt.Errorf("couldn't retrieve subnets: %w", err)
-	}
-	for _, chainTx := range chains {
-		chainID := chainTx.ID()
-		chain, ok := chainTx.Unsigned.(*txs.CreateChainTx)
-		if !ok {
-			return fmt.Errorf("expected tx type *txs.CreateChainTx but got %T", chainTx.Unsigned)
-		}
-		response.Blockchains = append(response.Blockchains, APIBlockchain{
-			ID:       chainID,
-			Name:     chain.ChainName,
-			SubnetID: constants.PrimaryNetworkID,
-			VMID:     chain.VMID,
-		})
-	}
+// setBlockchainStatus sets the fields of [blockchain] that are only returned
+// if they're requested.
+func (s *Service) setBlockchainStatus(blockchain *APIBlockchain) error {
+	bootstrapped := s.vm.Chains.IsBootstrapped(blockchain.ID)
+	validatorCount := json.Uint64(s.vm.Validators.Count(blockchain.SubnetID))
+	blockchain.Bootstrapped = &bootstrapped
+	blockchain.ValidatorCount = &validatorCount
 
+	height, err := s.vm.state.GetChainCreationHeight(blockchain.SubnetID, blockchain.ID)
+	switch {
+	case err == nil:
+		creationHeight := json.Uint64(height)
+		blockchain.CreationHeight = &creationHeight
+	case !errors.Is(err, database.ErrNotFound):
+		return fmt.Errorf("couldn't retrieve creation height of chain %q: %w", blockchain.ID, err)
+	}
 	return nil
 }
 
@@ -2232,7 +2787,14 @@ func (s *Service) GetTxStatus(_ *http.Request, args *GetTxStatusArgs, response *
 	s.vm.ctx.Lock.Lock()
 	defer s.vm.ctx.Lock.Unlock()
 
-	_, txStatus, err := s.vm.state.GetTx(args.TxID)
+	return s.getTxStatus(args.TxID, response)
+}
+
+// getTxStatus reports the status of [txID] in [response].
+//
+// Assumes [s.vm.ctx.Lock] is held.
+func (s *Service) getTxStatus(txID ids.ID, response *GetTxStatusResponse) error {
+	_, txStatus, err := s.vm.state.GetTx(txID)
 	if err == nil { // Found the status. Report it.
 		response.Status = txStatus
 		return nil
@@ -2249,7 +2811,7 @@ func (s *Service) GetTxStatus(_ *http.Request, args *GetTxStatusArgs, response *
 		return fmt.Errorf("could not retrieve state for block %s", preferredID)
 	}
 
-	_, _, err = onAccept.GetTx(args.TxID)
+	_, _, err = onAccept.GetTx(txID)
 	if err == nil {
 		// Found the status in the preferred block's db. Report tx is processing.
 		response.Status = status.Processing
@@ -2259,7 +2821,7 @@ func (s *Service) GetTxStatus(_ *http.Request, args *GetTxStatusArgs, response *
 		return err
 	}
 
-	if s.vm.Builder.Has(args.TxID) {
+	if s.vm.Builder.Has(txID) {
 		// Found the tx in the mempool. Report tx is processing.
 		response.Status = status.Processing
 		return nil
@@ -2267,7 +2829,7 @@ func (s *Service) GetTxStatus(_ *http.Request, args *GetTxStatusArgs, response *
 
 	// Note: we check if tx is dropped only after having looked for it
 	// in the database and the mempool, because dropped txs may be re-issued.
-	reason := s.vm.Builder.GetDropReason(args.TxID)
+	reason := s.vm.Builder.GetDropReason(txID)
 	if reason == nil {
 		// The tx isn't being tracked by the node.
 		response.Status = status.Unknown
@@ -2280,6 +2842,94 @@ func (s *Service) GetTxStatus(_ *http.Request, args *GetTxStatusArgs, response *
 	return nil
 }
 
+type GetAtomicStatusArgs struct {
+	TxID ids.ID `json:"txID"`
+}
+
+type GetAtomicStatusReply struct {
+	Status status.AtomicStatus `json:"status"`
+	// The chain the outputs were exported to.
+	// Only set if the export tx was accepted.
+	DestinationChain ids.ID `json:"destinationChain"`
+	// The exported outputs that haven't been imported by the destination
+	// chain.
+	// Only non-empty if Status is pending.
+	PendingUTXOIDs []avax.UTXOID `json:"pendingUTXOIDs"`
+}
+
+// GetAtomicStatus reports whether the outputs exported by the export tx
+// [args.TxID] were imported by their destination chain, so that stuck
+// cross-chain transfers can be detected. Outputs are imported once the
+// destination chain removes them from shared memory.
+func (s *Service) GetAtomicStatus(_ *http.Request, args *GetAtomicStatusArgs, reply *GetAtomicStatusReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getAtomicStatus"),
+	)
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	tx, txStatus, err := s.vm.state.GetTx(args.TxID)
+	if err == database.ErrNotFound {
+		txStatusResponse := &GetTxStatusResponse{}
+		if err := s.getTxStatus(args.TxID, txStatusResponse); err != nil {
+			return err
+		}
+		switch txStatusResponse.Status {
+		case status.Processing:
+			reply.Status = status.AtomicProcessing
+		case status.Dropped:
+			reply.Status = status.AtomicOrphaned
+		default:
+			return fmt.Errorf("%w: %s", errUnknownTx, args.TxID)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't get tx: %w", err)
+	}
+
+	exportTx, ok := tx.Unsigned.(*txs.ExportTx)
+	if !ok {
+		return fmt.Errorf("%w: %s is a %T", errNotExportTx, args.TxID, tx.Unsigned)
+	}
+	if txStatus != status.Committed {
+		reply.Status = status.AtomicOrphaned
+		return nil
+	}
+
+	reader, ok := s.vm.ctx.SharedMemory.(atomic.OutboundReader)
+	if !ok {
+		return errNoOutboundReader
+	}
+	utxoIDs := make([]avax.UTXOID, len(exportTx.ExportedOutputs))
+	keys := make([][]byte, len(exportTx.ExportedOutputs))
+	for i := range exportTx.ExportedOutputs {
+		utxoIDs[i] = avax.UTXOID{
+			TxID:        args.TxID,
+			OutputIndex: uint32(len(exportTx.Outs) + i),
+		}
+		inputID := utxoIDs[i].InputID()
+		keys[i] = inputID[:]
+	}
+	pending, err := reader.HasOutbound(exportTx.DestinationChain, keys)
+	if err != nil {
+		return fmt.Errorf("couldn't read shared memory: %w", err)
+	}
+
+	reply.Status = status.AtomicConsumed
+	reply.DestinationChain = exportTx.DestinationChain
+	reply.PendingUTXOIDs = []avax.UTXOID{}
+	for i, isPending := range pending {
+		if isPending {
+			reply.Status = status.AtomicPending
+			reply.PendingUTXOIDs = append(reply.PendingUTXOIDs, utxoIDs[i])
+		}
+	}
+	return nil
+}
+
 type GetStakeArgs struct {
 	api.JSONAddresses
 	ValidatorsOnly bool                `json:"validatorsOnly"`
@@ -2387,6 +3037,196 @@ func (s *Service) GetStake(_ *http.Request, args *GetStakeArgs, response *GetSta
 	return nil
 }
 
+// GetStakingOverviewArgs are the arguments for calling GetStakingOverview
+type GetStakingOverviewArgs struct {
+	api.JSONAddresses
+}
+
+// APIStakeOverview describes a Primary Network staker that [addrs] staked
+// with.
+type APIStakeOverview struct {
+	TxID      ids.ID      `json:"txID"`
+	NodeID    ids.NodeID  `json:"nodeID"`
+	Validator bool        `json:"validator"`
+	StartTime json.Uint64 `json:"startTime"`
+	EndTime   json.Uint64 `json:"endTime"`
+	// Amount of nAVAX staked by [addrs]
+	Staked json.Uint64 `json:"staked"`
+	// Reward that will be paid to [addrs] if the staker is rewarded, before
+	// any delegation fee is deducted. Only populated for active stakers whose
+	// rewards are paid to [addrs].
+	PotentialReward *json.Uint64 `json:"potentialReward,omitempty"`
+}
+
+// GetStakingOverviewReply is the response from calling GetStakingOverview.
+type GetStakingOverviewReply struct {
+	// Chain time that the overview was taken at
+	Timestamp time.Time `json:"timestamp"`
+	// Balances, in nAVAX, of [addrs]
+	Unlocked           json.Uint64 `json:"unlocked"`
+	LockedStakeable    json.Uint64 `json:"lockedStakeable"`
+	LockedNotStakeable json.Uint64 `json:"lockedNotStakeable"`
+	// Total amount of nAVAX staked by [addrs] in active and pending stakes
+	Staked json.Uint64 `json:"staked"`
+	// Sum of the potential rewards of [ActiveStakes]
+	PendingRewards json.Uint64 `json:"pendingRewards"`
+	// Stakes that are currently active, in order of increasing end time
+	ActiveStakes []APIStakeOverview `json:"activeStakes"`
+	// Stakes that haven't started yet, in order of increasing start time
+	PendingStakes []APIStakeOverview `json:"pendingStakes"`
+}
+
+// GetStakingOverview returns the balances, stakes and pending rewards of
+// [args.Addresses] on the Primary Network. Everything is read from the same
+// chain state, so the results are consistent with each other.
+func (s *Service) GetStakingOverview(_ *http.Request, args *GetStakingOverviewArgs, reply *GetStakingOverviewReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getStakingOverview"),
+		logging.UserStrings("addresses", args.Addresses),
+	)
+
+	if len(args.Addresses) > maxGetStakeAddrs {
+		return fmt.Errorf("%d addresses provided but this method can take at most %d", len(args.Addresses), maxGetStakeAddrs)
+	}
+
+	addrs, err := avax.ParseServiceAddresses(s.addrManager, args.Addresses)
+	if err != nil {
+		return err
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	balances, err := s.getBalances(addrs)
+	if err != nil {
+		return fmt.Errorf("couldn't get UTXO set of %v: %w", args.Addresses, err)
+	}
+	avaxAssetID := s.vm.ctx.AVAXAssetID
+	reply.Timestamp = s.vm.state.GetTimestamp()
+	reply.Unlocked = json.Uint64(balances.unlocked[avaxAssetID])
+	reply.LockedStakeable = json.Uint64(balances.lockedStakeable[avaxAssetID])
+	reply.LockedNotStakeable = json.Uint64(balances.lockedNotStakeable[avaxAssetID])
+
+	currentStakerIterator, err := s.vm.state.GetCurrentStakerIterator()
+	if err != nil {
+		return err
+	}
+	defer currentStakerIterator.Release()
+
+	var (
+		staked         uint64
+		pendingRewards uint64
+	)
+	reply.ActiveStakes = []APIStakeOverview{}
+	for currentStakerIterator.Next() {
+		staker := currentStakerIterator.Value()
+		stake, ok, err := s.getStakeOverview(staker, addrs)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		staked, err = safemath.Add64(staked, uint64(stake.Staked))
+		if err != nil {
+			staked = math.MaxUint64
+		}
+		if stake.PotentialReward != nil {
+			pendingRewards, err = safemath.Add64(pendingRewards, uint64(*stake.PotentialReward))
+			if err != nil {
+				pendingRewards = math.MaxUint64
+			}
+		}
+		reply.ActiveStakes = append(reply.ActiveStakes, *stake)
+	}
+
+	pendingStakerIterator, err := s.vm.state.GetPendingStakerIterator()
+	if err != nil {
+		return err
+	}
+	defer pendingStakerIterator.Release()
+
+	reply.PendingStakes = []APIStakeOverview{}
+	for pendingStakerIterator.Next() {
+		staker := pendingStakerIterator.Value()
+		stake, ok, err := s.getStakeOverview(staker, addrs)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		// Pending stakers haven't been assigned a reward yet.
+		stake.PotentialReward = nil
+		staked, err = safemath.Add64(staked, uint64(stake.Staked))
+		if err != nil {
+			staked = math.MaxUint64
+		}
+		reply.PendingStakes = append(reply.PendingStakes, *stake)
+	}
+
+	reply.Staked = json.Uint64(staked)
+	reply.PendingRewards = json.Uint64(pendingRewards)
+	return nil
+}
+
+// getStakeOverview returns the overview of [staker] from the perspective of
+// [addrs]. Returns false if [staker] isn't a Primary Network staker or if
+// none of its stake is owned by [addrs].
+//
+// Assumes [s.vm.ctx.Lock] is held.
+func (s *Service) getStakeOverview(staker *state.Staker, addrs set.Set[ids.ShortID]) (*APIStakeOverview, bool, error) {
+	if staker.SubnetID != constants.PrimaryNetworkID {
+		return nil, false, nil
+	}
+
+	tx, _, err := s.vm.state.GetTx(staker.TxID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	amountStaked := make(map[ids.ID]uint64)
+	if len(getStakeHelper(tx, addrs, amountStaked)) == 0 {
+		return nil, false, nil
+	}
+
+	stake := &APIStakeOverview{
+		TxID:      staker.TxID,
+		NodeID:    staker.NodeID,
+		Validator: staker.Priority.IsValidator(),
+		StartTime: json.Uint64(staker.StartTime.Unix()),
+		EndTime:   json.Uint64(staker.EndTime.Unix()),
+		Staked:    json.Uint64(amountStaked[s.vm.ctx.AVAXAssetID]),
+	}
+
+	attr, err := s.loadStakerTxAttributes(staker.TxID)
+	if err != nil {
+		return nil, false, err
+	}
+	rewardsOwner := attr.rewardsOwner
+	if stake.Validator {
+		rewardsOwner = attr.validationRewardsOwner
+	}
+	if owner, ok := primaryRewardsOwner(rewardsOwner); ok && ownedByAny(owner, addrs) {
+		potentialReward := json.Uint64(staker.PotentialReward)
+		stake.PotentialReward = &potentialReward
+	}
+	return stake, true, nil
+}
+
+// ownedByAny returns true if any of the addresses of [owner] is in [addrs].
+func ownedByAny(owner *secp256k1fx.OutputOwners, addrs set.Set[ids.ShortID]) bool {
+	for _, addr := range owner.Addrs {
+		if addrs.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetMinStakeArgs are the arguments for calling GetMinStake.
 type GetMinStakeArgs struct {
 	SubnetID ids.ID `json:"subnetID"`
@@ -2570,6 +3410,131 @@ func (s *Service) GetRewardUTXOs(_ *http.Request, args *api.GetTxArgs, reply *Ge
 	return nil
 }
 
+// GetRewardsOwnersArgs are the arguments for calling GetRewardsOwners
+type GetRewardsOwnersArgs struct {
+	// ID of the staking tx
+	TxID ids.ID `json:"txID"`
+}
+
+// APIRewardsOwner describes who is paid the rewards issued to a rewards owner
+type APIRewardsOwner struct {
+	// Owner that is paid the rewards while it is spendable
+	Primary *platformapi.Owner `json:"primary"`
+	// Owner that is paid the rewards once [Primary] is provably unspendable.
+	// Nil if no backup owner was specified.
+	Backup *platformapi.Owner `json:"backup,omitempty"`
+	// Owner that would be paid if the rewards were issued now
+	Payee *platformapi.Owner `json:"payee"`
+}
+
+// GetRewardsOwnersReply is the response from GetRewardsOwners. Only the owners
+// relevant to the type of the staking tx are populated.
+type GetRewardsOwnersReply struct {
+	ValidationRewardsOwner *APIRewardsOwner `json:"validationRewardsOwner,omitempty"`
+	DelegationRewardsOwner *APIRewardsOwner `json:"delegationRewardsOwner,omitempty"`
+	RewardsOwner           *APIRewardsOwner `json:"rewardsOwner,omitempty"`
+}
+
+// GetRewardsOwners returns the rewards owners of the provided staking tx along
+// with the owners that the rewards would currently be paid to.
+func (s *Service) GetRewardsOwners(_ *http.Request, args *GetRewardsOwnersArgs, reply *GetRewardsOwnersReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getRewardsOwners"),
+		zap.Stringer("txID", args.TxID),
+	)
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	attr, err := s.loadStakerTxAttributes(args.TxID)
+	if err != nil {
+		return fmt.Errorf("couldn't get staker tx %s: %w", args.TxID, err)
+	}
+
+	if attr.validationRewardsOwner != nil {
+		reply.ValidationRewardsOwner, err = s.getAPIRewardsOwner(attr.validationRewardsOwner)
+		if err != nil {
+			return err
+		}
+	}
+	if attr.delegationRewardsOwner != nil {
+		reply.DelegationRewardsOwner, err = s.getAPIRewardsOwner(attr.delegationRewardsOwner)
+		if err != nil {
+			return err
+		}
+	}
+	if attr.rewardsOwner != nil {
+		reply.RewardsOwner, err = s.getAPIRewardsOwner(attr.rewardsOwner)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetFeeRefundsArgs are the arguments for calling GetFeeRefunds
+type GetFeeRefundsArgs struct {
+	Addresses []string `json:"addresses"`
+}
+
+// GetFeeRefundsReply is the response from GetFeeRefunds
+type GetFeeRefundsReply struct {
+	// Address --> Amount of AVAX the address overpaid in fees. Addresses
+	// without a refund are omitted.
+	Refunds map[string]json.Uint64 `json:"refunds"`
+	// Sum of [Refunds]
+	Total json.Uint64 `json:"total"`
+}
+
+// GetFeeRefunds returns the fees overpaid by the provided addresses. Refunds
+// can be claimed with a ClaimFeeRefundTx.
+//
+// A tx's overpayment is refunded if every AVAX input it spends from the
+// P-chain is unlocked and owned by the same single address. AVAX imported by
+// an ImportTx counts towards the overpayment, but an ImportTx that spends no
+// AVAX from the P-chain burns its overpayment.
+func (s *Service) GetFeeRefunds(_ *http.Request, args *GetFeeRefundsArgs, reply *GetFeeRefundsReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getFeeRefunds"),
+		logging.UserStrings("addresses", args.Addresses),
+	)
+
+	addrs, err := avax.ParseServiceAddresses(s.addrManager, args.Addresses)
+	if err != nil {
+		return err
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	reply.Refunds = make(map[string]json.Uint64)
+	var total uint64
+	for addr := range addrs {
+		refund, err := s.vm.state.GetFeeRefund(addr)
+		if err != nil {
+			return fmt.Errorf("couldn't get fee refund of %s: %w", addr, err)
+		}
+		if refund == 0 {
+			continue
+		}
+
+		addrStr, err := s.addrManager.FormatLocalAddress(addr)
+		if err != nil {
+			return fmt.Errorf("couldn't format address %s: %w", addr, err)
+		}
+		reply.Refunds[addrStr] = json.Uint64(refund)
+
+		total, err = safemath.Add64(total, refund)
+		if err != nil {
+			return err
+		}
+	}
+	reply.Total = json.Uint64(total)
+	return nil
+}
+
 // GetTimestampReply is the response from GetTimestamp
 type GetTimestampReply struct {
 	// Current timestamp
@@ -2683,6 +3648,105 @@ func (s *Service) GetValidatorsAt(r *http.Request, args *GetValidatorsAtArgs, re
 	return nil
 }
 
+// GetValidatorWeightHistoryArgs are the arguments for calling
+// GetValidatorWeightHistory
+type GetValidatorWeightHistoryArgs struct {
+	NodeID     ids.NodeID  `json:"nodeID"`
+	SubnetID   ids.ID      `json:"subnetID"`
+	FromHeight json.Uint64 `json:"fromHeight"`
+	ToHeight   json.Uint64 `json:"toHeight"`
+}
+
+// ValidatorWeightChange is the change in a validator's weight caused by
+// accepting the block at [Height].
+type ValidatorWeightChange struct {
+	Height json.Uint64 `json:"height"`
+	// Weight of the validator after the block at [Height] was accepted
+	Weight   json.Uint64 `json:"weight"`
+	Decrease bool        `json:"decrease"`
+	Amount   json.Uint64 `json:"amount"`
+}
+
+// GetValidatorWeightHistoryReply is the response from calling
+// GetValidatorWeightHistory
+type GetValidatorWeightHistoryReply struct {
+	// Weight of the validator at [FromHeight]
+	StartWeight json.Uint64 `json:"startWeight"`
+	// Changes to the validator's weight in (FromHeight, ToHeight], in
+	// increasing height order
+	Changes []ValidatorWeightChange `json:"changes"`
+}
+
+// GetValidatorWeightHistory returns the changes to the weight of a validator of
+// a provided subnet between the specified heights.
+func (s *Service) GetValidatorWeightHistory(r *http.Request, args *GetValidatorWeightHistoryArgs, reply *GetValidatorWeightHistoryReply) error {
+	fromHeight := uint64(args.FromHeight)
+	toHeight := uint64(args.ToHeight)
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getValidatorWeightHistory"),
+		zap.Stringer("nodeID", args.NodeID),
+		zap.Stringer("subnetID", args.SubnetID),
+		zap.Uint64("fromHeight", fromHeight),
+		zap.Uint64("toHeight", toHeight),
+	)
+
+	if fromHeight > toHeight {
+		return errFromHeightAfterToHeight
+	}
+	if toHeight-fromHeight > maxValidatorWeightHistoryHeights {
+		return errHeightRangeTooLarge
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	ctx := r.Context()
+	vdrs, err := s.vm.GetValidatorSet(ctx, toHeight, args.SubnetID)
+	if err != nil {
+		return fmt.Errorf("failed to get validator set: %w", err)
+	}
+
+	var weight uint64
+	if vdr, ok := vdrs[args.NodeID]; ok {
+		weight = vdr.Weight
+	}
+
+	reply.Changes = []ValidatorWeightChange{}
+	if fromHeight == toHeight {
+		reply.StartWeight = json.Uint64(weight)
+		return nil
+	}
+
+	diffs, err := s.vm.state.GetValidatorWeightDiffs(ctx, args.SubnetID, args.NodeID, toHeight, fromHeight+1)
+	if err != nil {
+		return fmt.Errorf("failed to get validator weight diffs: %w", err)
+	}
+
+	// [diffs] are ordered by decreasing height, so the weight is rewound from
+	// [toHeight] towards [fromHeight].
+	reply.Changes = make([]ValidatorWeightChange, len(diffs))
+	for i, diff := range diffs {
+		reply.Changes[len(diffs)-1-i] = ValidatorWeightChange{
+			Height:   json.Uint64(diff.Height),
+			Weight:   json.Uint64(weight),
+			Decrease: diff.Decrease,
+			Amount:   json.Uint64(diff.Amount),
+		}
+
+		if diff.Decrease {
+			weight, err = safemath.Add64(weight, diff.Amount)
+		} else {
+			weight, err = safemath.Sub(weight, diff.Amount)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply weight diff at height %d: %w", diff.Height, err)
+		}
+	}
+	reply.StartWeight = json.Uint64(weight)
+	return nil
+}
+
 func (s *Service) GetBlock(_ *http.Request, args *api.GetBlockArgs, response *api.GetBlockResponse) error {
 	s.vm.ctx.Log.Debug("API called",
 		zap.String("service", "platform"),
@@ -2789,6 +3853,46 @@ func (s *Service) getAPIOwner(owner *secp256k1fx.OutputOwners) (*platformapi.Own
 	return apiOwner, nil
 }
 
+func (s *Service) getAPIRewardsOwner(owner fx.Owner) (*APIRewardsOwner, error) {
+	payee, err := executor.ResolveRewardsOwner(s.vm.state, owner)
+	if err != nil {
+		return nil, err
+	}
+	payeeOwner, ok := payee.(*secp256k1fx.OutputOwners)
+	if !ok {
+		return nil, fmt.Errorf("expected *secp256k1fx.OutputOwners but got %T", payee)
+	}
+
+	apiOwner := &APIRewardsOwner{}
+	apiOwner.Payee, err = s.getAPIOwner(payeeOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	failoverOwner, ok := owner.(*txs.FailoverOwner)
+	if !ok {
+		apiOwner.Primary = apiOwner.Payee
+		return apiOwner, nil
+	}
+
+	apiOwner.Primary, err = s.getAPIOwner(failoverOwner.Primary)
+	if err != nil {
+		return nil, err
+	}
+	apiOwner.Backup, err = s.getAPIOwner(failoverOwner.Backup)
+	return apiOwner, err
+}
+
+// primaryRewardsOwner returns the owner that is paid rewards issued to [owner]
+// unless a failover to a backup owner occurs.
+func primaryRewardsOwner(owner fx.Owner) (*secp256k1fx.OutputOwners, bool) {
+	if failoverOwner, ok := owner.(*txs.FailoverOwner); ok {
+		return failoverOwner.Primary, true
+	}
+	outputOwners, ok := owner.(*secp256k1fx.OutputOwners)
+	return outputOwners, ok
+}
+
 // Takes in a staker and a set of addresses
 // Returns:
 // 1) The total amount staked by addresses in [addrs]
@@ -4,11 +4,15 @@
 package platformvm
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -28,6 +32,8 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/uptime"
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
@@ -35,11 +41,14 @@ import (
 	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/avalanchego/utils/json"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/perms"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
 	"github.com/ava-labs/avalanchego/vms/platformvm/state"
 	"github.com/ava-labs/avalanchego/vms/platformvm/status"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 
 	vmkeystore "github.com/ava-labs/avalanchego/vms/components/keystore"
@@ -261,6 +270,83 @@ func TestGetTxStatus(t *testing.T) {
 	require.Zero(resp.Reason)
 }
 
+func TestGetAtomicStatus(t *testing.T) {
+	require := require.New(t)
+	service, mutableSharedMemory := defaultService(t)
+	defaultAddress(t, service)
+	service.vm.ctx.Lock.Lock()
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	m := atomic.NewMemory(prefixdb.New([]byte{}, service.vm.db))
+	mutableSharedMemory.SharedMemory = m.NewSharedMemory(service.vm.ctx.ChainID)
+	peerSharedMemory := m.NewSharedMemory(xChainID)
+
+	tx, err := service.vm.txBuilder.NewExportTx(
+		100,
+		xChainID,
+		ids.GenerateTestShortID(),
+		[]*secp256k1.PrivateKey{keys[0]},
+		keys[0].PublicKey().Address(),
+	)
+	require.NoError(err)
+
+	service.vm.ctx.Lock.Unlock()
+
+	var (
+		arg   = &GetAtomicStatusArgs{TxID: tx.ID()}
+		reply GetAtomicStatusReply
+	)
+	err = service.GetAtomicStatus(nil, arg, &reply)
+	require.ErrorIs(err, errUnknownTx)
+
+	service.vm.ctx.Lock.Lock()
+	require.NoError(service.vm.Network.IssueTx(context.Background(), tx))
+	service.vm.ctx.Lock.Unlock()
+
+	reply = GetAtomicStatusReply{}
+	require.NoError(service.GetAtomicStatus(nil, arg, &reply))
+	require.Equal(status.AtomicProcessing, reply.Status)
+	require.Empty(reply.PendingUTXOIDs)
+
+	service.vm.ctx.Lock.Lock()
+	block, err := service.vm.BuildBlock(context.Background())
+	require.NoError(err)
+	blk := block.(*blockexecutor.Block)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(blk.Accept(context.Background()))
+	service.vm.ctx.Lock.Unlock()
+
+	exportTx := tx.Unsigned.(*txs.ExportTx)
+	utxoID := avax.UTXOID{
+		TxID:        tx.ID(),
+		OutputIndex: uint32(len(exportTx.Outs)),
+	}
+	// Caches the input ID so that [utxoID] equals the reported UTXO IDs.
+	inputID := utxoID.InputID()
+	reply = GetAtomicStatusReply{}
+	require.NoError(service.GetAtomicStatus(nil, arg, &reply))
+	require.Equal(status.AtomicPending, reply.Status)
+	require.Equal(xChainID, reply.DestinationChain)
+	require.Equal([]avax.UTXOID{utxoID}, reply.PendingUTXOIDs)
+
+	// Importing the output removes it from shared memory.
+	require.NoError(peerSharedMemory.Apply(map[ids.ID]*atomic.Requests{
+		service.vm.ctx.ChainID: {
+			RemoveRequests: [][]byte{inputID[:]},
+		},
+	}))
+
+	reply = GetAtomicStatusReply{}
+	require.NoError(service.GetAtomicStatus(nil, arg, &reply))
+	require.Equal(status.AtomicConsumed, reply.Status)
+	require.Equal(xChainID, reply.DestinationChain)
+	require.Empty(reply.PendingUTXOIDs)
+}
+
 // Test issuing and then retrieving a transaction
 func TestGetTx(t *testing.T) {
 	type test struct {
@@ -417,6 +503,121 @@ func TestGetBalance(t *testing.T) {
 	}
 }
 
+func TestGetBlockchains(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	vmIDs := []ids.ID{{'v', 'm', '1'}, {'v', 'm', '2'}}
+	chainIDs := make([]ids.ID, len(vmIDs))
+	creationHeights := make([]json.Uint64, len(vmIDs))
+	service.vm.ctx.Lock.Lock()
+	for i, vmID := range vmIDs {
+		tx, err := service.vm.txBuilder.NewCreateChainTx(
+			testSubnet1.ID(),
+			nil,
+			vmID,
+			nil,
+			fmt.Sprintf("chain%d", i),
+			[]*secp256k1.PrivateKey{testSubnet1ControlKeys[0], testSubnet1ControlKeys[1]},
+			ids.ShortEmpty, // change addr
+		)
+		require.NoError(err)
+		require.NoError(service.vm.Network.IssueTx(context.Background(), tx))
+
+		blk, err := service.vm.Builder.BuildBlock(context.Background())
+		require.NoError(err)
+		require.NoError(blk.Verify(context.Background()))
+		require.NoError(blk.Accept(context.Background()))
+		require.NoError(service.vm.SetPreference(context.Background(), service.vm.manager.LastAccepted()))
+
+		chainIDs[i] = tx.ID()
+		creationHeights[i] = json.Uint64(blk.Height())
+	}
+	validatorCount := json.Uint64(service.vm.Validators.Count(testSubnet1.ID()))
+	service.vm.ctx.Lock.Unlock()
+
+	// Chains are returned in order of their IDs.
+	if chainIDs[1].Less(chainIDs[0]) {
+		vmIDs[0], vmIDs[1] = vmIDs[1], vmIDs[0]
+		chainIDs[0], chainIDs[1] = chainIDs[1], chainIDs[0]
+		creationHeights[0], creationHeights[1] = creationHeights[1], creationHeights[0]
+	}
+
+	// Filter by subnet and include the status of each chain.
+	reply := GetBlockchainsResponse{}
+	require.NoError(service.GetBlockchains(nil, &GetBlockchainsArgs{
+		SubnetIDs:     []ids.ID{testSubnet1.ID()},
+		IncludeStatus: true,
+	}, &reply))
+	require.Equal(json.Uint64(2), reply.Total)
+	require.Len(reply.Blockchains, 2)
+	for i, blockchain := range reply.Blockchains {
+		require.Equal(chainIDs[i], blockchain.ID)
+		require.Equal(testSubnet1.ID(), blockchain.SubnetID)
+		require.Equal(vmIDs[i], blockchain.VMID)
+		require.NotNil(blockchain.Bootstrapped)
+		require.False(*blockchain.Bootstrapped)
+		require.Equal(&validatorCount, blockchain.ValidatorCount)
+		require.Equal(&creationHeights[i], blockchain.CreationHeight)
+	}
+
+	// Filter by VM.
+	reply = GetBlockchainsResponse{}
+	require.NoError(service.GetBlockchains(nil, &GetBlockchainsArgs{
+		VMIDs: []ids.ID{vmIDs[1]},
+	}, &reply))
+	require.Equal(json.Uint64(1), reply.Total)
+	require.Len(reply.Blockchains, 1)
+	require.Equal(chainIDs[1], reply.Blockchains[0].ID)
+	require.Nil(reply.Blockchains[0].Bootstrapped)
+
+	// Page through the chains of the subnet.
+	reply = GetBlockchainsResponse{}
+	require.NoError(service.GetBlockchains(nil, &GetBlockchainsArgs{
+		SubnetIDs:  []ids.ID{testSubnet1.ID()},
+		StartIndex: 1,
+		Limit:      1,
+	}, &reply))
+	require.Equal(json.Uint64(2), reply.Total)
+	require.Len(reply.Blockchains, 1)
+	require.Equal(chainIDs[1], reply.Blockchains[0].ID)
+}
+
+func TestGetFeeRefunds(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	refundedAddr := ids.GenerateTestShortID()
+	otherAddr := ids.GenerateTestShortID()
+	service.vm.ctx.Lock.Lock()
+	service.vm.state.SetFeeRefund(refundedAddr, 5)
+	service.vm.ctx.Lock.Unlock()
+
+	refundedAddrStr, err := service.addrManager.FormatLocalAddress(refundedAddr)
+	require.NoError(err)
+	otherAddrStr, err := service.addrManager.FormatLocalAddress(otherAddr)
+	require.NoError(err)
+
+	reply := GetFeeRefundsReply{}
+	require.NoError(service.GetFeeRefunds(nil, &GetFeeRefundsArgs{
+		Addresses: []string{refundedAddrStr, otherAddrStr},
+	}, &reply))
+	require.Equal(map[string]json.Uint64{
+		refundedAddrStr: 5,
+	}, reply.Refunds)
+	require.Equal(json.Uint64(5), reply.Total)
+}
+
 func TestGetStake(t *testing.T) {
 	require := require.New(t)
 	service, _ := defaultService(t)
@@ -590,6 +791,115 @@ func TestGetStake(t *testing.T) {
 	require.Equal(stakeAmount+oldStake, outputs[0].Out.Amount()+outputs[1].Out.Amount()+outputs[2].Out.Amount())
 }
 
+func TestGetStakingOverview(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defaultAddress(t, service)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	addr, err := service.addrManager.FormatLocalAddress(keys[0].PublicKey().Address())
+	require.NoError(err)
+	args := GetStakingOverviewArgs{
+		JSONAddresses: api.JSONAddresses{
+			Addresses: []string{addr},
+		},
+	}
+
+	// The genesis validator of [keys[0]] is rewarded to [keys[0]].
+	reply := GetStakingOverviewReply{}
+	require.NoError(service.GetStakingOverview(nil, &args, &reply))
+	require.False(reply.Timestamp.IsZero())
+	require.Equal(json.Uint64(defaultBalance), reply.Unlocked)
+	require.Zero(reply.LockedStakeable)
+	require.Zero(reply.LockedNotStakeable)
+	require.Equal(json.Uint64(defaultWeight), reply.Staked)
+	require.Len(reply.ActiveStakes, 1)
+	require.Empty(reply.PendingStakes)
+
+	validatorStake := reply.ActiveStakes[0]
+	require.Equal(genesisNodeIDs[0], validatorStake.NodeID)
+	require.True(validatorStake.Validator)
+	require.Equal(json.Uint64(defaultWeight), validatorStake.Staked)
+	require.NotNil(validatorStake.PotentialReward)
+	require.Equal(*validatorStake.PotentialReward, reply.PendingRewards)
+
+	service.vm.ctx.Lock.Lock()
+
+	// Add a delegator that is rewarded to another address
+	delegatorStakeAmount := service.vm.MinDelegatorStake + 12345
+	tx, err := service.vm.txBuilder.NewAddDelegatorTx(
+		delegatorStakeAmount,
+		uint64(defaultGenesisTime.Unix()),
+		uint64(defaultGenesisTime.Add(defaultMinStakingDuration).Unix()),
+		genesisNodeIDs[0],
+		ids.GenerateTestShortID(),
+		[]*secp256k1.PrivateKey{keys[0]},
+		keys[0].PublicKey().Address(), // change addr
+	)
+	require.NoError(err)
+
+	staker, err := state.NewCurrentStaker(
+		tx.ID(),
+		tx.Unsigned.(*txs.AddDelegatorTx),
+		12345,
+	)
+	require.NoError(err)
+
+	service.vm.state.PutCurrentDelegator(staker)
+	service.vm.state.AddTx(tx, status.Committed)
+
+	// Add a pending validator
+	validatorStakeAmount := service.vm.MinValidatorStake + 54321
+	tx, err = service.vm.txBuilder.NewAddValidatorTx(
+		validatorStakeAmount,
+		uint64(defaultGenesisTime.Unix()),
+		uint64(defaultGenesisTime.Add(defaultMinStakingDuration).Unix()),
+		ids.GenerateTestNodeID(),
+		keys[0].PublicKey().Address(),
+		0,
+		[]*secp256k1.PrivateKey{keys[0]},
+		keys[0].PublicKey().Address(), // change addr
+	)
+	require.NoError(err)
+
+	staker, err = state.NewPendingStaker(
+		tx.ID(),
+		tx.Unsigned.(*txs.AddValidatorTx),
+	)
+	require.NoError(err)
+
+	service.vm.state.PutPendingValidator(staker)
+	service.vm.state.AddTx(tx, status.Committed)
+	require.NoError(service.vm.state.Commit())
+
+	service.vm.ctx.Lock.Unlock()
+
+	reply = GetStakingOverviewReply{}
+	require.NoError(service.GetStakingOverview(nil, &args, &reply))
+	require.Equal(json.Uint64(defaultWeight+delegatorStakeAmount+validatorStakeAmount), reply.Staked)
+	require.Len(reply.ActiveStakes, 2)
+	require.Len(reply.PendingStakes, 1)
+
+	// The rewards of the delegator aren't paid to [keys[0]].
+	require.Equal(*validatorStake.PotentialReward, reply.PendingRewards)
+	for _, stake := range reply.ActiveStakes {
+		if stake.Validator {
+			continue
+		}
+		require.Equal(json.Uint64(delegatorStakeAmount), stake.Staked)
+		require.Nil(stake.PotentialReward)
+	}
+
+	pendingStake := reply.PendingStakes[0]
+	require.True(pendingStake.Validator)
+	require.Equal(json.Uint64(validatorStakeAmount), pendingStake.Staked)
+	require.Nil(pendingStake.PotentialReward)
+}
+
 func TestGetCurrentValidators(t *testing.T) {
 	require := require.New(t)
 	service, _ := defaultService(t)
@@ -744,6 +1054,342 @@ func TestGetTimestamp(t *testing.T) {
 	require.Equal(newTimestamp, reply.Timestamp)
 }
 
+func TestAdminAPIEnabled(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	handlers, err := service.vm.CreateHandlers(context.Background())
+	require.NoError(err)
+	require.NotContains(handlers, "/admin")
+
+	service.vm.adminAPIEnabled = true
+	handlers, err = service.vm.CreateHandlers(context.Background())
+	require.NoError(err)
+	require.Contains(handlers, "/admin")
+}
+
+func TestExportAndValidateStakerSchedule(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	signer := warp.NewSigner(sk, service.vm.ctx.NetworkID, service.vm.ctx.ChainID)
+	service.vm.ctx.WarpSigner = signer
+	service.vm.ctx.PublicKey = bls.PublicFromSecretKey(sk)
+
+	adminService := &AdminService{vm: service.vm}
+	exported := SignedStakerSchedule{}
+	require.NoError(adminService.ExportStakerSchedule(&http.Request{}, nil, &exported))
+	require.Len(exported.Schedule.Current, len(genesisNodeIDs))
+	require.Empty(exported.Schedule.Pending)
+
+	// The schedule matches the state it was exported from.
+	reply := ValidateStakerScheduleReply{}
+	require.NoError(service.ValidateStakerSchedule(&http.Request{}, &exported, &reply))
+	require.True(reply.Matches)
+	require.Equal(exported.Schedule.Height, reply.Height)
+
+	// Modifying the schedule invalidates its signature.
+	diverged := exported
+	diverged.Schedule.Current = exported.Schedule.Current[1:]
+	err = service.ValidateStakerSchedule(&http.Request{}, &diverged, &reply)
+	require.ErrorIs(err, errInvalidScheduleSignature)
+
+	// A validly signed schedule that diverges from the state is reported.
+	msg, err := diverged.Schedule.unsignedMessage(service.vm.ctx.ChainID)
+	require.NoError(err)
+	require.True(bytes.HasPrefix(msg.Payload, []byte(stakerScheduleDomain)))
+	sigBytes, err := signer.Sign(msg)
+	require.NoError(err)
+	diverged.Signature, err = formatting.Encode(formatting.HexNC, sigBytes)
+	require.NoError(err)
+
+	reply = ValidateStakerScheduleReply{}
+	require.NoError(service.ValidateStakerSchedule(&http.Request{}, &diverged, &reply))
+	require.False(reply.Matches)
+	require.Empty(reply.MissingCurrent)
+	require.Equal(exported.Schedule.Current[:1], reply.UnexpectedCurrent)
+	require.Empty(reply.MissingPending)
+	require.Empty(reply.UnexpectedPending)
+}
+
+func TestExportAndVerifyCheckpoint(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	service.vm.ctx.WarpSigner = warp.NewSigner(sk, service.vm.ctx.NetworkID, service.vm.ctx.ChainID)
+	service.vm.ctx.PublicKey = bls.PublicFromSecretKey(sk)
+
+	admin := &AdminService{vm: service.vm}
+
+	// The state can't be exported without a chain data directory.
+	exportReply := ExportCheckpointReply{}
+	err = admin.ExportCheckpoint(&http.Request{}, nil, &exportReply)
+	require.ErrorIs(err, errNoChainDataDir)
+
+	service.vm.ctx.ChainDataDir = t.TempDir()
+	require.NoError(admin.ExportCheckpoint(&http.Request{}, nil, &exportReply))
+	require.DirExists(exportReply.StateDir)
+	exported := exportReply.Checkpoint
+	require.Len(exported.Signatures, 1)
+	require.Equal(service.vm.ctx.NodeID, exported.Signatures[0].NodeID)
+
+	msg, err := exported.Checkpoint.unsignedMessage(service.vm.ctx.ChainID)
+	require.NoError(err)
+	require.True(bytes.HasPrefix(msg.Payload, []byte(checkpointDomain)))
+
+	// Checkpoints can't be verified without a trust set.
+	reply := VerifyCheckpointReply{}
+	err = service.VerifyCheckpoint(&http.Request{}, &exported, &reply)
+	require.ErrorIs(err, errNoCheckpointTrustSet)
+
+	// Checkpoints signed by signers outside of the trust set are rejected.
+	otherSK, err := bls.NewSecretKey()
+	require.NoError(err)
+	service.vm.checkpointTrustSet = map[ids.NodeID]*bls.PublicKey{
+		ids.GenerateTestNodeID(): bls.PublicFromSecretKey(otherSK),
+	}
+	service.vm.checkpointSignerThreshold = 1
+	err = service.VerifyCheckpoint(&http.Request{}, &exported, &reply)
+	require.ErrorIs(err, errUntrustedCheckpointSigner)
+
+	// The checkpoint matches the chain it was exported from.
+	service.vm.checkpointTrustSet = map[ids.NodeID]*bls.PublicKey{
+		service.vm.ctx.NodeID: service.vm.ctx.PublicKey,
+	}
+	require.NoError(service.VerifyCheckpoint(&http.Request{}, &exported, &reply))
+	require.Equal([]ids.NodeID{service.vm.ctx.NodeID}, reply.Signers)
+	require.True(reply.Reached)
+	require.True(reply.Matches)
+	require.Equal(exported.Checkpoint.Height, reply.Height)
+
+	// Modifying the checkpoint invalidates its signature.
+	modified := exported
+	modified.Checkpoint.BlockID = ids.GenerateTestID()
+	err = service.VerifyCheckpoint(&http.Request{}, &modified, &reply)
+	require.ErrorIs(err, errInvalidCheckpointSignature)
+
+	// A trusted checkpoint past the last accepted block hasn't been reached.
+	ahead := exported
+	ahead.Checkpoint.Height++
+	msg, err = ahead.Checkpoint.unsignedMessage(service.vm.ctx.ChainID)
+	require.NoError(err)
+	sigBytes, err := service.vm.ctx.WarpSigner.Sign(msg)
+	require.NoError(err)
+	ahead.Signatures = []CheckpointSignature{exported.Signatures[0]}
+	ahead.Signatures[0].Signature, err = formatting.Encode(formatting.HexNC, sigBytes)
+	require.NoError(err)
+
+	reply = VerifyCheckpointReply{}
+	require.NoError(service.VerifyCheckpoint(&http.Request{}, &ahead, &reply))
+	require.False(reply.Reached)
+	require.False(reply.Matches)
+}
+
+func TestInitFromCheckpoint(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	service.vm.ctx.WarpSigner = warp.NewSigner(sk, service.vm.ctx.NetworkID, service.vm.ctx.ChainID)
+	service.vm.ctx.PublicKey = bls.PublicFromSecretKey(sk)
+	service.vm.ctx.ChainDataDir = t.TempDir()
+
+	admin := &AdminService{vm: service.vm}
+	exportReply := ExportCheckpointReply{}
+	require.NoError(admin.ExportCheckpoint(&http.Request{}, nil, &exportReply))
+
+	checkpointBytes, err := stdjson.Marshal(exportReply.Checkpoint)
+	require.NoError(err)
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	require.NoError(os.WriteFile(checkpointFile, checkpointBytes, perms.ReadWrite))
+
+	publicKey, err := formatting.Encode(formatting.HexNC, bls.PublicKeyToBytes(service.vm.ctx.PublicKey))
+	require.NoError(err)
+	configBytes, err := stdjson.Marshal(map[string]interface{}{
+		"checkpoint-trust-set": []config.CheckpointSigner{
+			{
+				NodeID:    service.vm.ctx.NodeID,
+				PublicKey: publicKey,
+			},
+		},
+		"checkpoint-file":      checkpointFile,
+		"checkpoint-state-dir": exportReply.StateDir,
+	})
+	require.NoError(err)
+
+	vmConfig := service.vm.Config
+	vmConfig.UptimeLockedCalculator = uptime.NewLockedCalculator()
+	vmConfig.Validators = validators.NewManager()
+	vm := &VM{Config: vmConfig}
+	vm.clock.Set(service.vm.clock.Time())
+
+	ctx := defaultContext(t)
+	ctx.ChainDataDir = t.TempDir()
+	m := atomic.NewMemory(memdb.New())
+	ctx.SharedMemory = m.NewSharedMemory(ctx.ChainID)
+	_, genesisBytes := defaultGenesis(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+	require.NoError(vm.Initialize(
+		context.Background(),
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		nil,
+		configBytes,
+		make(chan common.Message, 1),
+		nil,
+		&common.SenderTest{},
+	))
+	defer func() {
+		require.NoError(vm.Shutdown(context.Background()))
+	}()
+
+	// The new VM starts from the checkpointed block rather than genesis.
+	lastAcceptedID, err := vm.LastAccepted(context.Background())
+	require.NoError(err)
+	require.Equal(exportReply.Checkpoint.Checkpoint.BlockID, lastAcceptedID)
+	height, err := vm.GetCurrentHeight(context.Background())
+	require.NoError(err)
+	require.Equal(uint64(exportReply.Checkpoint.Checkpoint.Height), height)
+}
+
+func TestProjectValidatorSetLimits(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	timestamp := json.Uint64(service.vm.state.GetTimestamp().Unix())
+	err := service.ProjectValidatorSet(&http.Request{}, &ProjectValidatorSetArgs{
+		Timestamps: []json.Uint64{timestamp},
+		Additions:  make([]HypotheticalStaker, maxHypotheticalAdditions+1),
+	}, &ProjectValidatorSetReply{})
+	require.ErrorIs(err, errTooManyAdditions)
+
+	err = service.ProjectValidatorSet(&http.Request{}, &ProjectValidatorSetArgs{
+		Timestamps: []json.Uint64{timestamp},
+		Removals:   make([]HypotheticalRemoval, maxHypotheticalRemovals+1),
+	}, &ProjectValidatorSetReply{})
+	require.ErrorIs(err, errTooManyRemovals)
+
+	reply := ProjectValidatorSetReply{}
+	require.NoError(service.ProjectValidatorSet(&http.Request{}, &ProjectValidatorSetArgs{
+		SubnetID:   constants.PrimaryNetworkID,
+		Timestamps: []json.Uint64{timestamp},
+	}, &reply))
+	require.Len(reply.Projections, 1)
+	require.Len(reply.Projections[0].Validators, len(genesisNodeIDs))
+	require.Empty(reply.ChurnLimitedAdditions)
+}
+
+func TestDelegationOffers(t *testing.T) {
+	require := require.New(t)
+	service, _ := defaultService(t)
+	defer func() {
+		service.vm.ctx.Lock.Lock()
+		require.NoError(service.vm.Shutdown(context.Background()))
+		service.vm.ctx.Lock.Unlock()
+	}()
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	service.vm.ctx.WarpSigner = warp.NewSigner(sk, service.vm.ctx.NetworkID, service.vm.ctx.ChainID)
+	service.vm.ctx.PublicKey = bls.PublicFromSecretKey(sk)
+
+	now := service.vm.clock.Time()
+	args := SetDelegationOfferArgs{
+		Capacity:      json.Uint64(service.vm.MaxValidatorStake),
+		DelegationFee: 2,
+		MinDelegation: json.Uint64(service.vm.MinDelegatorStake),
+		Expiry:        json.Uint64(now.Add(time.Hour).Unix()),
+	}
+
+	admin := &AdminService{vm: service.vm}
+
+	// Only validators can advertise delegation offers.
+	offer := SignedDelegationOffer{}
+	err = admin.SetDelegationOffer(&http.Request{}, &args, &offer)
+	require.ErrorIs(err, errOfferNotValidator)
+
+	service.vm.ctx.NodeID = genesisNodeIDs[0]
+	require.NoError(admin.SetDelegationOffer(&http.Request{}, &args, &offer))
+	require.Equal(genesisNodeIDs[0], offer.Offer.NodeID)
+
+	msg, err := offer.Offer.unsignedMessage(service.vm.ctx.ChainID)
+	require.NoError(err)
+	require.True(bytes.HasPrefix(msg.Payload, []byte(delegationOfferDomain)))
+
+	reply := GetDelegationOffersReply{}
+	require.NoError(service.GetDelegationOffers(&http.Request{}, &GetDelegationOffersArgs{}, &reply))
+	require.Equal([]*SignedDelegationOffer{&offer}, reply.Offers)
+
+	// Offers with too little capacity are filtered out.
+	reply = GetDelegationOffersReply{}
+	require.NoError(service.GetDelegationOffers(&http.Request{}, &GetDelegationOffersArgs{
+		MinCapacity: args.Capacity + 1,
+	}, &reply))
+	require.Empty(reply.Offers)
+
+	// A known offer can't be submitted again.
+	err = service.SubmitDelegationOffer(&http.Request{}, &offer, nil)
+	require.ErrorIs(err, errOfferOutdated)
+
+	// Modifying the offer invalidates its signature.
+	modified := offer
+	modified.Offer.IssuedAt++
+	modified.Offer.DelegationFee = 1
+	err = service.SubmitDelegationOffer(&http.Request{}, &modified, nil)
+	require.ErrorIs(err, errInvalidOfferSignature)
+
+	// A new offer can only be set once the previous one is old enough.
+	service.vm.clock.Set(now.Add(time.Second))
+	newOffer := SignedDelegationOffer{}
+	err = admin.SetDelegationOffer(&http.Request{}, &args, &newOffer)
+	require.ErrorIs(err, errOfferRateLimited)
+
+	service.vm.clock.Set(now.Add(minDelegationOfferInterval))
+	require.NoError(admin.SetDelegationOffer(&http.Request{}, &args, &newOffer))
+
+	// Expired offers are no longer returned.
+	service.vm.clock.Set(now.Add(2 * time.Hour))
+	reply = GetDelegationOffersReply{}
+	require.NoError(service.GetDelegationOffers(&http.Request{}, &GetDelegationOffersArgs{}, &reply))
+	require.Empty(reply.Offers)
+
+	err = service.SubmitDelegationOffer(&http.Request{}, &offer, nil)
+	require.ErrorIs(err, errOfferExpired)
+}
+
 func TestGetBlock(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,38 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	stdjson "encoding/json"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+// Domains of the messages that the admin API signs with this node's BLS key.
+//
+// The payload of each message starts with its domain. Warp payloads start with
+// a codec version of 0, so these messages can't be mistaken for Warp messages
+// from this chain, or for messages of another domain.
+const (
+	stakerScheduleDomain  = "avalanche:platformvm:stakerSchedule:v1"
+	checkpointDomain      = "avalanche:platformvm:checkpoint:v1"
+	delegationOfferDomain = "avalanche:platformvm:delegationOffer:v1"
+)
+
+// newDomainMessage returns the message that's signed to attest to the JSON
+// encoding of [v] in [domain] on the chain [chainID].
+func newDomainMessage(networkID uint32, chainID ids.ID, domain string, v interface{}) (*warp.UnsignedMessage, error) {
+	vBytes, err := stdjson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, 0, len(domain)+1+hashing.HashLen)
+	payload = append(payload, domain...)
+	payload = append(payload, 0) // separates the domain from the hash
+	payload = append(payload, hashing.ComputeHash256(vBytes)...)
+	return warp.NewUnsignedMessage(networkID, chainID, payload)
+}
@@ -0,0 +1,163 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/formatting"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+var errInvalidScheduleSignature = errors.New("invalid staker schedule signature")
+
+// ScheduledStaker is a current or pending staker in a [StakerSchedule].
+type ScheduledStaker struct {
+	TxID            ids.ID      `json:"txID"`
+	NodeID          ids.NodeID  `json:"nodeID"`
+	SubnetID        ids.ID      `json:"subnetID"`
+	Weight          json.Uint64 `json:"weight"`
+	StartTime       json.Uint64 `json:"startTime"`
+	EndTime         json.Uint64 `json:"endTime"`
+	PotentialReward json.Uint64 `json:"potentialReward"`
+	Priority        json.Uint8  `json:"priority"`
+}
+
+func newScheduledStaker(staker *state.Staker) ScheduledStaker {
+	return ScheduledStaker{
+		TxID:            staker.TxID,
+		NodeID:          staker.NodeID,
+		SubnetID:        staker.SubnetID,
+		Weight:          json.Uint64(staker.Weight),
+		StartTime:       json.Uint64(staker.StartTime.Unix()),
+		EndTime:         json.Uint64(staker.EndTime.Unix()),
+		PotentialReward: json.Uint64(staker.PotentialReward),
+		Priority:        json.Uint8(staker.Priority),
+	}
+}
+
+// StakerSchedule is the set of current and pending stakers in a node's state.
+type StakerSchedule struct {
+	NetworkID uint32 `json:"networkID"`
+	// Height of the last accepted block when the schedule was exported
+	Height json.Uint64 `json:"height"`
+	// Chain time when the schedule was exported
+	Timestamp json.Uint64 `json:"timestamp"`
+	// Stakers in the current staker set, in the order they're removed
+	Current []ScheduledStaker `json:"current"`
+	// Stakers in the pending staker set, in the order they're added
+	Pending []ScheduledStaker `json:"pending"`
+}
+
+// Returns the message that's signed to attest to [s] on the chain [chainID].
+func (s *StakerSchedule) unsignedMessage(chainID ids.ID) (*warp.UnsignedMessage, error) {
+	return newDomainMessage(s.NetworkID, chainID, stakerScheduleDomain, s)
+}
+
+// SignedStakerSchedule is a [StakerSchedule] signed with the BLS key of the
+// node that exported it.
+type SignedStakerSchedule struct {
+	Schedule StakerSchedule `json:"schedule"`
+	// Node that exported the schedule
+	NodeID ids.NodeID `json:"nodeID"`
+	// Hex encoded BLS public key of [NodeID]
+	PublicKey string `json:"publicKey"`
+	// Hex encoded BLS signature of [NodeID] over [Schedule]
+	Signature string `json:"signature"`
+}
+
+// Verify checks that [s.Signature] is a valid signature of [s.PublicKey]
+// over [s.Schedule] on the chain [chainID].
+// Returns the public key that signed the schedule.
+func (s *SignedStakerSchedule) Verify(chainID ids.ID) (*bls.PublicKey, error) {
+	pkBytes, err := formatting.Decode(formatting.HexNC, s.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode public key: %w", err)
+	}
+	pk, err := bls.PublicKeyFromBytes(pkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse public key: %w", err)
+	}
+
+	sigBytes, err := formatting.Decode(formatting.HexNC, s.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode signature: %w", err)
+	}
+	sig, err := bls.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse signature: %w", err)
+	}
+
+	msg, err := s.Schedule.unsignedMessage(chainID)
+	if err != nil {
+		return nil, err
+	}
+	if !bls.Verify(pk, sig, msg.Bytes()) {
+		return nil, errInvalidScheduleSignature
+	}
+	return pk, nil
+}
+
+// Returns the stakers in [expected] that aren't in [actual] and the stakers
+// in [actual] that aren't in [expected].
+func diffStakers(expected, actual []ScheduledStaker) ([]ScheduledStaker, []ScheduledStaker) {
+	expectedSet := set.Of(expected...)
+	actualSet := set.Of(actual...)
+
+	missing := []ScheduledStaker{}
+	for _, staker := range expected {
+		if !actualSet.Contains(staker) {
+			missing = append(missing, staker)
+		}
+	}
+	unexpected := []ScheduledStaker{}
+	for _, staker := range actual {
+		if !expectedSet.Contains(staker) {
+			unexpected = append(unexpected, staker)
+		}
+	}
+	return missing, unexpected
+}
+
+// Returns the current and pending stakers in this node's state.
+// Assumes [vm.ctx.Lock] is held.
+func (vm *VM) getStakerSchedule(ctx context.Context) (*StakerSchedule, error) {
+	height, err := vm.GetCurrentHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schedule := &StakerSchedule{
+		NetworkID: vm.ctx.NetworkID,
+		Height:    json.Uint64(height),
+		Timestamp: json.Uint64(vm.state.GetTimestamp().Unix()),
+	}
+
+	currentStakerIterator, err := vm.state.GetCurrentStakerIterator()
+	if err != nil {
+		return nil, err
+	}
+	schedule.Current = []ScheduledStaker{}
+	for currentStakerIterator.Next() {
+		schedule.Current = append(schedule.Current, newScheduledStaker(currentStakerIterator.Value()))
+	}
+	currentStakerIterator.Release()
+
+	pendingStakerIterator, err := vm.state.GetPendingStakerIterator()
+	if err != nil {
+		return nil, err
+	}
+	schedule.Pending = []ScheduledStaker{}
+	for pendingStakerIterator.Next() {
+		schedule.Pending = append(schedule.Pending, newScheduledStaker(pendingStakerIterator.Value()))
+	}
+	pendingStakerIterator.Release()
+	return schedule, nil
+}
@@ -0,0 +1,448 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/linkeddb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/versiondb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/block"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+// Prefixes of the key/value pairs of a checkpoint's canonical state. Pairs are
+// imported in order of their keys, so txs must be imported before the stakers
+// that reference them.
+const (
+	checkpointSingletonPrefix byte = iota
+	checkpointBlockPrefix
+	checkpointTxPrefix
+	checkpointUTXOPrefix
+	checkpointCurrentValidatorPrefix
+	checkpointCurrentDelegatorPrefix
+	checkpointCurrentSubnetValidatorPrefix
+	checkpointCurrentSubnetDelegatorPrefix
+	checkpointPendingValidatorPrefix
+	checkpointPendingDelegatorPrefix
+	checkpointPendingSubnetValidatorPrefix
+	checkpointPendingSubnetDelegatorPrefix
+	checkpointSubnetPrefix
+	checkpointSubnetOwnerPrefix
+	checkpointTransformedSubnetPrefix
+	checkpointSupplyPrefix
+	checkpointChainPrefix
+	checkpointRevokedAddressPrefix
+	checkpointFeeRefundPrefix
+)
+
+// Number of pairs of a checkpoint's canonical state that are imported before
+// they're committed to the database.
+const checkpointImportCommitInterval = 1024
+
+var (
+	errUnknownCheckpointKey  = errors.New("unknown checkpoint key")
+	errInvalidCheckpointKey  = errors.New("invalid checkpoint key")
+	errCheckpointNotAccepted = errors.New("checkpoint block isn't accepted")
+
+	// Singletons of the canonical state. The other singletons are either
+	// node-local or written by [ImportCheckpoint].
+	checkpointSingletonKeys = [][]byte{
+		timestampKey,
+		currentSupplyKey,
+		lastAcceptedKey,
+	}
+)
+
+// checkpointDBs are the databases of the layout that [New] loads the state
+// from, which hold the canonical state of a checkpoint.
+type checkpointDBs struct {
+	baseDB *versiondb.Database
+
+	singletonDB         database.Database
+	blockIDDB           database.Database
+	blockDB             database.Database
+	txDB                database.Database
+	utxoDB              database.Database
+	subnetBaseDB        database.Database
+	subnetOwnerDB       database.Database
+	transformedSubnetDB database.Database
+	supplyDB            database.Database
+	chainDB             database.Database
+	revokedAddressDB    database.Database
+	feeRefundDB         database.Database
+
+	// Staker lists by the prefix of their pairs in the canonical state
+	stakerDBs map[byte]database.Database
+}
+
+func newCheckpointDBs(db database.Database) *checkpointDBs {
+	// The databases are nested in the same way as in [newState], since
+	// nesting prefixdbs changes their prefixes.
+	baseDB := versiondb.New(db)
+	validatorsDB := prefixdb.New(validatorsPrefix, baseDB)
+	currentValidatorsDB := prefixdb.New(currentPrefix, validatorsDB)
+	pendingValidatorsDB := prefixdb.New(pendingPrefix, validatorsDB)
+	return &checkpointDBs{
+		baseDB:              baseDB,
+		singletonDB:         prefixdb.New(singletonPrefix, baseDB),
+		blockIDDB:           prefixdb.New(blockIDPrefix, baseDB),
+		blockDB:             prefixdb.New(blockPrefix, baseDB),
+		txDB:                prefixdb.New(txPrefix, baseDB),
+		utxoDB:              prefixdb.New(utxoPrefix, baseDB),
+		subnetBaseDB:        prefixdb.New(subnetPrefix, baseDB),
+		subnetOwnerDB:       prefixdb.New(subnetOwnerPrefix, baseDB),
+		transformedSubnetDB: prefixdb.New(transformedSubnetPrefix, baseDB),
+		supplyDB:            prefixdb.New(supplyPrefix, baseDB),
+		chainDB:             prefixdb.New(chainPrefix, baseDB),
+		revokedAddressDB:    prefixdb.New(revokedAddressPrefix, baseDB),
+		feeRefundDB:         prefixdb.New(feeRefundPrefix, baseDB),
+		stakerDBs: map[byte]database.Database{
+			checkpointCurrentValidatorPrefix:       prefixdb.New(validatorPrefix, currentValidatorsDB),
+			checkpointCurrentDelegatorPrefix:       prefixdb.New(delegatorPrefix, currentValidatorsDB),
+			checkpointCurrentSubnetValidatorPrefix: prefixdb.New(subnetValidatorPrefix, currentValidatorsDB),
+			checkpointCurrentSubnetDelegatorPrefix: prefixdb.New(subnetDelegatorPrefix, currentValidatorsDB),
+			checkpointPendingValidatorPrefix:       prefixdb.New(validatorPrefix, pendingValidatorsDB),
+			checkpointPendingDelegatorPrefix:       prefixdb.New(delegatorPrefix, pendingValidatorsDB),
+			checkpointPendingSubnetValidatorPrefix: prefixdb.New(subnetValidatorPrefix, pendingValidatorsDB),
+			checkpointPendingSubnetDelegatorPrefix: prefixdb.New(subnetDelegatorPrefix, pendingValidatorsDB),
+		},
+	}
+}
+
+// Returns the databases of the plain key/value pairs of the canonical state
+// by their prefix.
+func (c *checkpointDBs) plainDBs() map[byte]database.Database {
+	return map[byte]database.Database{
+		checkpointTxPrefix:                c.txDB,
+		checkpointSubnetOwnerPrefix:       c.subnetOwnerDB,
+		checkpointTransformedSubnetPrefix: c.transformedSubnetDB,
+		checkpointSupplyPrefix:            c.supplyDB,
+		checkpointRevokedAddressPrefix:    c.revokedAddressDB,
+		checkpointFeeRefundPrefix:         c.feeRefundDB,
+	}
+}
+
+// ExportCheckpoint writes the canonical state of the state stored in [db] to
+// [dst]. The canonical state only depends on the blocks that were accepted,
+// so the states of nodes that accepted the same blocks are exported to the
+// same key/value pairs.
+//
+// Node-local data, such as uptimes and indices, isn't exported, and neither
+// is historical data that isn't needed to verify the blocks after the last
+// accepted block: the blocks before it, reward UTXOs and validator set diffs.
+//
+// [db] must not be modified while the state is exported.
+func ExportCheckpoint(db database.Database, dst database.KeyValueWriter) error {
+	dbs := newCheckpointDBs(db)
+
+	for _, key := range checkpointSingletonKeys {
+		value, err := dbs.singletonDB.Get(key)
+		if err != nil {
+			return fmt.Errorf("failed to get singleton %q: %w", key, err)
+		}
+		if err := dst.Put(checkpointKey(checkpointSingletonPrefix, key), value); err != nil {
+			return err
+		}
+	}
+
+	lastAccepted, err := database.GetID(dbs.singletonDB, lastAcceptedKey)
+	if err != nil {
+		return err
+	}
+	blkBytes, err := dbs.blockDB.Get(lastAccepted[:])
+	if err != nil {
+		return fmt.Errorf("failed to get last accepted block %s: %w", lastAccepted, err)
+	}
+	// Blocks may be stored in the format used before pruning, so the block
+	// is exported as its canonical bytes.
+	blk, status, _, err := parseStoredBlock(blkBytes)
+	if err != nil {
+		return err
+	}
+	if status != choices.Accepted {
+		return fmt.Errorf("%w: %s", errCheckpointNotAccepted, lastAccepted)
+	}
+	if err := dst.Put(checkpointKey(checkpointBlockPrefix, lastAccepted[:]), blk.Bytes()); err != nil {
+		return err
+	}
+
+	for prefix, db := range dbs.plainDBs() {
+		if err := exportCheckpointPairs(db.NewIterator(), prefix, nil, dst); err != nil {
+			return err
+		}
+	}
+	if err := exportCheckpointPairs(avax.NewUTXOIterator(dbs.utxoDB), checkpointUTXOPrefix, nil, dst); err != nil {
+		return err
+	}
+
+	for prefix, db := range dbs.stakerDBs {
+		var canonicalValue func([]byte) ([]byte, error)
+		switch prefix {
+		case checkpointCurrentValidatorPrefix, checkpointCurrentSubnetValidatorPrefix:
+			canonicalValue = canonicalValidatorMetadata
+		case checkpointPendingValidatorPrefix,
+			checkpointPendingDelegatorPrefix,
+			checkpointPendingSubnetValidatorPrefix,
+			checkpointPendingSubnetDelegatorPrefix:
+			canonicalValue = func([]byte) ([]byte, error) {
+				return nil, nil
+			}
+		}
+		list := linkeddb.NewDefault(db)
+		if err := exportCheckpointPairs(list.NewIterator(), prefix, canonicalValue, dst); err != nil {
+			return err
+		}
+	}
+
+	subnetIDs := []ids.ID{constants.PrimaryNetworkID}
+	subnetIt := linkeddb.NewDefault(dbs.subnetBaseDB).NewIterator()
+	defer subnetIt.Release()
+	for subnetIt.Next() {
+		subnetID, err := ids.ToID(subnetIt.Key())
+		if err != nil {
+			return err
+		}
+		if err := dst.Put(checkpointKey(checkpointSubnetPrefix, subnetID[:]), nil); err != nil {
+			return err
+		}
+		subnetIDs = append(subnetIDs, subnetID)
+	}
+	if err := subnetIt.Error(); err != nil {
+		return err
+	}
+
+	for _, subnetID := range subnetIDs {
+		if err := exportCheckpointChains(dbs, subnetID, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the chains of [subnetID] to [dst], keyed by the subnet ID followed by
+// the chain ID.
+func exportCheckpointChains(dbs *checkpointDBs, subnetID ids.ID, dst database.KeyValueWriter) error {
+	chainIt := linkeddb.NewDefault(prefixdb.New(subnetID[:], dbs.chainDB)).NewIterator()
+	defer chainIt.Release()
+
+	for chainIt.Next() {
+		key := make([]byte, 0, ids.IDLen+len(chainIt.Key()))
+		key = append(key, subnetID[:]...)
+		key = append(key, chainIt.Key()...)
+		if err := dst.Put(checkpointKey(checkpointChainPrefix, key), chainIt.Value()); err != nil {
+			return err
+		}
+	}
+	return chainIt.Error()
+}
+
+// Writes the pairs of [it] to [dst] with their keys prefixed by [prefix]. If
+// [canonicalValue] is non-nil, the values are replaced by their canonical
+// value. Releases [it].
+func exportCheckpointPairs(
+	it database.Iterator,
+	prefix byte,
+	canonicalValue func([]byte) ([]byte, error),
+	dst database.KeyValueWriter,
+) error {
+	defer it.Release()
+
+	for it.Next() {
+		value := it.Value()
+		if canonicalValue != nil {
+			var err error
+			value, err = canonicalValue(value)
+			if err != nil {
+				return err
+			}
+		}
+		if err := dst.Put(checkpointKey(prefix, it.Key()), value); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// Returns the metadata of a current validator without its uptime, which is
+// node-local.
+func canonicalValidatorMetadata(metadataBytes []byte) ([]byte, error) {
+	metadata := &validatorMetadata{}
+	if err := parseValidatorMetadata(metadataBytes, metadata); err != nil {
+		return nil, err
+	}
+	return metadataCodec.Marshal(v0, &validatorMetadata{
+		PotentialReward:          metadata.PotentialReward,
+		PotentialDelegateeReward: metadata.PotentialDelegateeReward,
+	})
+}
+
+// ImportCheckpoint writes the canonical state in [src], which was written by
+// [ExportCheckpoint], to [db], which must be empty, so that [New] loads the
+// checkpoint's state from [db].
+//
+// The uptimes of the current validators aren't part of the canonical state.
+// They're initialized as if this node had accepted the blocks itself while it
+// wasn't tracking uptimes, so the validators are considered online since they
+// started validating.
+func ImportCheckpoint(src database.Iteratee, db database.Database) error {
+	var (
+		dbs       = newCheckpointDBs(db)
+		plainDBs  = dbs.plainDBs()
+		subnetDB  = linkeddb.NewDefault(dbs.subnetBaseDB)
+		stakerDBs = make(map[byte]linkeddb.LinkedDB, len(dbs.stakerDBs))
+	)
+	for prefix, db := range dbs.stakerDBs {
+		stakerDBs[prefix] = linkeddb.NewDefault(db)
+	}
+	utxoState, err := avax.NewUTXOState(dbs.utxoDB, txs.GenesisCodec, false)
+	if err != nil {
+		return err
+	}
+
+	it := src.NewIterator()
+	defer it.Release()
+
+	numImported := 0
+	for it.Next() {
+		prefixedKey := it.Key()
+		if len(prefixedKey) == 0 {
+			return errInvalidCheckpointKey
+		}
+		var (
+			prefix = prefixedKey[0]
+			key    = prefixedKey[1:]
+			value  = it.Value()
+		)
+		switch prefix {
+		case checkpointSingletonPrefix:
+			err = dbs.singletonDB.Put(key, value)
+		case checkpointBlockPrefix:
+			err = importCheckpointBlock(dbs, value)
+		case checkpointUTXOPrefix:
+			utxo := &avax.UTXO{}
+			if _, err := txs.GenesisCodec.Unmarshal(value, utxo); err != nil {
+				return fmt.Errorf("failed to parse UTXO: %w", err)
+			}
+			err = utxoState.PutUTXO(utxo)
+		case checkpointCurrentValidatorPrefix, checkpointCurrentSubnetValidatorPrefix:
+			err = importCheckpointValidator(dbs, stakerDBs[prefix], key, value)
+		case checkpointCurrentDelegatorPrefix,
+			checkpointCurrentSubnetDelegatorPrefix,
+			checkpointPendingValidatorPrefix,
+			checkpointPendingDelegatorPrefix,
+			checkpointPendingSubnetValidatorPrefix,
+			checkpointPendingSubnetDelegatorPrefix:
+			err = stakerDBs[prefix].Put(key, value)
+		case checkpointSubnetPrefix:
+			err = subnetDB.Put(key, nil)
+		case checkpointChainPrefix:
+			if len(key) != 2*ids.IDLen {
+				return fmt.Errorf("%w: %x", errInvalidCheckpointKey, prefixedKey)
+			}
+			chainDB := linkeddb.NewDefault(prefixdb.New(key[:ids.IDLen], dbs.chainDB))
+			err = chainDB.Put(key[ids.IDLen:], value)
+		default:
+			plainDB, ok := plainDBs[prefix]
+			if !ok {
+				return fmt.Errorf("%w: %x", errUnknownCheckpointKey, prefixedKey)
+			}
+			err = plainDB.Put(key, value)
+		}
+		if err != nil {
+			return err
+		}
+
+		numImported++
+		if numImported%checkpointImportCommitInterval != 0 {
+			continue
+		}
+		if err := dbs.baseDB.Commit(); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	// The blocks before the checkpoint aren't imported, so there is nothing to
+	// prune.
+	if err := dbs.singletonDB.Put(prunedKey, nil); err != nil {
+		return err
+	}
+	if err := dbs.singletonDB.Put(initializedKey, nil); err != nil {
+		return err
+	}
+	return dbs.baseDB.Commit()
+}
+
+// Writes the block [blkBytes] and indexes it by its height.
+func importCheckpointBlock(dbs *checkpointDBs, blkBytes []byte) error {
+	blk, err := block.Parse(block.GenesisCodec, blkBytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse block: %w", err)
+	}
+	blkID := blk.ID()
+	if err := dbs.blockDB.Put(blkID[:], blkBytes); err != nil {
+		return err
+	}
+	return database.PutID(dbs.blockIDDB, database.PackUInt64(blk.Height()), blkID)
+}
+
+// Writes the current validator added by the tx [txIDBytes] with the canonical
+// [metadataBytes] to [list], with the uptime of a validator that was just
+// started being tracked.
+func importCheckpointValidator(
+	dbs *checkpointDBs,
+	list linkeddb.LinkedDB,
+	txIDBytes []byte,
+	metadataBytes []byte,
+) error {
+	txID, err := ids.ToID(txIDBytes)
+	if err != nil {
+		return err
+	}
+	txBytes, err := dbs.txDB.Get(txIDBytes)
+	if err != nil {
+		return fmt.Errorf("failed to get tx %s: %w", txID, err)
+	}
+	stx := txBytesAndStatus{}
+	if _, err := txs.GenesisCodec.Unmarshal(txBytes, &stx); err != nil {
+		return err
+	}
+	tx, err := txs.Parse(txs.GenesisCodec, stx.Tx)
+	if err != nil {
+		return err
+	}
+	stakerTx, ok := tx.Unsigned.(txs.Staker)
+	if !ok {
+		return fmt.Errorf("expected tx type txs.Staker but got %T", tx.Unsigned)
+	}
+
+	metadata := &validatorMetadata{}
+	if err := parseValidatorMetadata(metadataBytes, metadata); err != nil {
+		return err
+	}
+	metadata.UpDuration = 0
+	metadata.LastUpdated = uint64(stakerTx.StartTime().Unix())
+	metadataBytes, err = metadataCodec.Marshal(v0, metadata)
+	if err != nil {
+		return err
+	}
+	return list.Put(txIDBytes, metadataBytes)
+}
+
+func checkpointKey(prefix byte, key []byte) []byte {
+	prefixedKey := make([]byte, 1+len(key))
+	prefixedKey[0] = prefix
+	copy(prefixedKey[1:], key)
+	return prefixedKey
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+func TestCheckpointExportIgnoresUptimes(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	require.NoError(s.Commit())
+
+	exported := memdb.New()
+	require.NoError(ExportCheckpoint(db, exported))
+
+	require.NoError(s.SetUptime(initialNodeID, constants.PrimaryNetworkID, time.Hour, initialTime.Add(time.Hour)))
+	require.NoError(s.Commit())
+
+	exportedWithUptime := memdb.New()
+	require.NoError(ExportCheckpoint(db, exportedWithUptime))
+	require.Equal(readAll(require, exported), readAll(require, exportedWithUptime))
+}
+
+func TestCheckpointImport(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+	require.NoError(s.SetUptime(initialNodeID, constants.PrimaryNetworkID, time.Hour, initialTime.Add(time.Hour)))
+	require.NoError(s.Commit())
+
+	exported := memdb.New()
+	require.NoError(ExportCheckpoint(db, exported))
+
+	importedDB := memdb.New()
+	require.NoError(ImportCheckpoint(exported, importedDB))
+	imported := newStateFromDB(require, importedDB)
+	shouldInit, err := imported.(*state).shouldInit()
+	require.NoError(err)
+	require.False(shouldInit)
+	require.NoError(imported.(*state).load())
+
+	require.Equal(s.GetLastAccepted(), imported.GetLastAccepted())
+	require.Equal(s.GetTimestamp(), imported.GetTimestamp())
+
+	expectedSupply, err := s.GetCurrentSupply(constants.PrimaryNetworkID)
+	require.NoError(err)
+	supply, err := imported.GetCurrentSupply(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(expectedSupply, supply)
+
+	expectedValidator, err := s.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+	validator, err := imported.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+	require.Equal(expectedValidator, validator)
+
+	// The uptime isn't imported
+	upDuration, lastUpdated, err := imported.GetUptime(initialNodeID, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Zero(upDuration)
+	require.Equal(initialTime, lastUpdated)
+
+	utxoID := avax.UTXOID{TxID: initialTxID}
+	expectedUTXO, err := s.GetUTXO(utxoID.InputID())
+	require.NoError(err)
+	utxo, err := imported.GetUTXO(utxoID.InputID())
+	require.NoError(err)
+	expectedUTXOBytes, err := txs.Codec.Marshal(txs.Version, expectedUTXO)
+	require.NoError(err)
+	utxoBytes, err := txs.Codec.Marshal(txs.Version, utxo)
+	require.NoError(err)
+	require.Equal(expectedUTXOBytes, utxoBytes)
+
+	expectedChains, err := s.GetChains(constants.PrimaryNetworkID)
+	require.NoError(err)
+	chains, err := imported.GetChains(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(expectedChains, chains)
+
+	shouldPrune, err := imported.ShouldPrune()
+	require.NoError(err)
+	require.False(shouldPrune)
+
+	// The imported state is exported to the same canonical state
+	reexported := memdb.New()
+	require.NoError(ExportCheckpoint(importedDB, reexported))
+	require.Equal(readAll(require, exported), readAll(require, reexported))
+}
+
+func TestCheckpointImportUnknownKey(t *testing.T) {
+	require := require.New(t)
+
+	exported := memdb.New()
+	require.NoError(exported.Put([]byte{checkpointFeeRefundPrefix + 1}, nil))
+
+	err := ImportCheckpoint(exported, memdb.New())
+	require.ErrorIs(err, errUnknownCheckpointKey)
+}
+
+func readAll(require *require.Assertions, db database.Iteratee) map[string][]byte {
+	it := db.NewIterator()
+	defer it.Release()
+
+	pairs := make(map[string][]byte)
+	for it.Next() {
+		pairs[string(it.Key())] = it.Value()
+	}
+	require.NoError(it.Error())
+	return pairs
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/platformvm/fx"
 	"github.com/ava-labs/avalanchego/vms/platformvm/status"
@@ -50,6 +51,11 @@ type diff struct {
 
 	addedChains map[ids.ID][]*txs.Tx
 
+	revokedAddresses set.Set[ids.ShortID]
+
+	// Address --> Refund of the fees overpaid by the address
+	feeRefunds map[ids.ShortID]uint64
+
 	addedRewardUTXOs map[ids.ID][]*avax.UTXO
 
 	addedTxs map[ids.ID]*txAndStatus
@@ -279,6 +285,43 @@ func (d *diff) SetSubnetOwner(subnetID ids.ID, owner fx.Owner) {
 	d.subnetOwners[subnetID] = owner
 }
 
+func (d *diff) IsAddressRevoked(addr ids.ShortID) (bool, error) {
+	if d.revokedAddresses.Contains(addr) {
+		return true, nil
+	}
+
+	// If the address was not revoked in this diff, ask the parent state.
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return false, ErrMissingParentState
+	}
+	return parentState.IsAddressRevoked(addr)
+}
+
+func (d *diff) RevokeAddress(addr ids.ShortID) {
+	d.revokedAddresses.Add(addr)
+}
+
+func (d *diff) GetFeeRefund(addr ids.ShortID) (uint64, error) {
+	if amount, ok := d.feeRefunds[addr]; ok {
+		return amount, nil
+	}
+
+	// If the refund was not modified in this diff, ask the parent state.
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return 0, ErrMissingParentState
+	}
+	return parentState.GetFeeRefund(addr)
+}
+
+func (d *diff) SetFeeRefund(addr ids.ShortID, amount uint64) {
+	if d.feeRefunds == nil {
+		d.feeRefunds = make(map[ids.ShortID]uint64)
+	}
+	d.feeRefunds[addr] = amount
+}
+
 func (d *diff) GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error) {
 	tx, exists := d.transformedSubnets[subnetID]
 	if exists {
@@ -465,5 +508,11 @@ func (d *diff) Apply(baseState Chain) error {
 	for subnetID, owner := range d.subnetOwners {
 		baseState.SetSubnetOwner(subnetID, owner)
 	}
+	for addr := range d.revokedAddresses {
+		baseState.RevokeAddress(addr)
+	}
+	for addr, amount := range d.feeRefunds {
+		baseState.SetFeeRefund(addr, amount)
+	}
 	return nil
 }
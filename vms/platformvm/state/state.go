@@ -33,6 +33,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/timer"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
@@ -79,6 +80,8 @@ var (
 	utxoPrefix                          = []byte("utxo")
 	subnetPrefix                        = []byte("subnet")
 	subnetOwnerPrefix                   = []byte("subnetOwner")
+	revokedAddressPrefix                = []byte("revokedAddress")
+	feeRefundPrefix                     = []byte("feeRefund")
 	transformedSubnetPrefix             = []byte("transformedSubnet")
 	supplyPrefix                        = []byte("supply")
 	chainPrefix                         = []byte("chain")
@@ -113,6 +116,16 @@ type Chain interface {
 	GetSubnetOwner(subnetID ids.ID) (fx.Owner, error)
 	SetSubnetOwner(subnetID ids.ID, owner fx.Owner)
 
+	// IsAddressRevoked returns true if [addr] was revoked by a
+	// RevokeAddressesTx.
+	IsAddressRevoked(addr ids.ShortID) (bool, error)
+	RevokeAddress(addr ids.ShortID)
+
+	// GetFeeRefund returns the amount of AVAX that [addr] overpaid in fees
+	// and can claim with a ClaimFeeRefundTx.
+	GetFeeRefund(addr ids.ShortID) (uint64, error)
+	SetFeeRefund(addr ids.ShortID, amount uint64)
+
 	GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error)
 	AddSubnetTransformation(transformSubnetTx *txs.Tx)
 
@@ -140,6 +153,11 @@ type State interface {
 	GetRewardUTXOs(txID ids.ID) ([]*avax.UTXO, error)
 	GetSubnets() ([]*txs.Tx, error)
 	GetChains(subnetID ids.ID) ([]*txs.Tx, error)
+	// GetChainCreationHeight returns the height of the block that created
+	// [chainID] in [subnetID]. Returns [database.ErrNotFound] if the height
+	// wasn't recorded, which is the case for chains created by nodes that
+	// didn't record creation heights yet.
+	GetChainCreationHeight(subnetID, chainID ids.ID) (uint64, error)
 
 	// ApplyValidatorWeightDiffs iterates from [startHeight] towards the genesis
 	// block until it has applied all of the diffs up to and including
@@ -160,6 +178,21 @@ type State interface {
 		subnetID ids.ID,
 	) error
 
+	// GetValidatorWeightDiffs returns the diffs of [nodeID]'s weight on
+	// [subnetID] at the heights from [startHeight] down to and including
+	// [endHeight]. Heights where [nodeID]'s weight didn't change are omitted.
+	// The diffs are ordered by decreasing height.
+	//
+	// Note: The diff at a height is the change in weight caused by accepting
+	// the block at that height.
+	GetValidatorWeightDiffs(
+		ctx context.Context,
+		subnetID ids.ID,
+		nodeID ids.NodeID,
+		startHeight uint64,
+		endHeight uint64,
+	) ([]*HeightWeightDiff, error)
+
 	// ApplyValidatorPublicKeyDiffs iterates from [startHeight] towards the
 	// genesis block until it has applied all of the diffs up to and including
 	// [endHeight]. Applying the diffs modifies [validators].
@@ -272,6 +305,10 @@ type stateBlk struct {
  * |   '-- txID -> nil
  * |-. subnetOwners
  * | '-. subnetID -> owner
+ * |-. revokedAddresses
+ * | '-- address -> nil
+ * |-. feeRefunds
+ * | '-- address -> amount
  * |-. chains
  * | '-. subnetID
  * |   '-. list
@@ -354,6 +391,12 @@ type state struct {
 	subnetOwnerCache cache.Cacher[ids.ID, fxOwnerAndSize] // cache of subnetID -> owner if the entry is nil, it is not in the database
 	subnetOwnerDB    database.Database
 
+	addedRevokedAddresses set.Set[ids.ShortID]
+	revokedAddressDB      database.Database
+
+	feeRefunds  map[ids.ShortID]uint64 // map of address -> modified refund
+	feeRefundDB database.Database
+
 	transformedSubnets     map[ids.ID]*txs.Tx            // map of subnetID -> transformSubnetTx
 	transformedSubnetCache cache.Cacher[ids.ID, *txs.Tx] // cache of subnetID -> transformSubnetTx if the entry is nil, it is not in the database
 	transformedSubnetDB    database.Database
@@ -407,6 +450,13 @@ func (v *ValidatorWeightDiff) Add(negative bool, amount uint64) error {
 	return nil
 }
 
+// HeightWeightDiff is the change in a validator's weight caused by accepting
+// the block at [Height].
+type HeightWeightDiff struct {
+	Height uint64
+	ValidatorWeightDiff
+}
+
 type heightWithSubnet struct {
 	Height   uint64 `serialize:"true"`
 	SubnetID ids.ID `serialize:"true"`
@@ -688,6 +738,11 @@ func newState(
 		subnetOwnerDB:    subnetOwnerDB,
 		subnetOwnerCache: subnetOwnerCache,
 
+		revokedAddressDB: prefixdb.New(revokedAddressPrefix, baseDB),
+
+		feeRefunds:  make(map[ids.ShortID]uint64),
+		feeRefundDB: prefixdb.New(feeRefundPrefix, baseDB),
+
 		transformedSubnets:     make(map[ids.ID]*txs.Tx),
 		transformedSubnetCache: transformedSubnetCache,
 		transformedSubnetDB:    prefixdb.New(transformedSubnetPrefix, baseDB),
@@ -886,6 +941,32 @@ func (s *state) SetSubnetOwner(subnetID ids.ID, owner fx.Owner) {
 	s.subnetOwners[subnetID] = owner
 }
 
+func (s *state) IsAddressRevoked(addr ids.ShortID) (bool, error) {
+	if s.addedRevokedAddresses.Contains(addr) {
+		return true, nil
+	}
+	return s.revokedAddressDB.Has(addr[:])
+}
+
+func (s *state) RevokeAddress(addr ids.ShortID) {
+	s.addedRevokedAddresses.Add(addr)
+}
+
+func (s *state) GetFeeRefund(addr ids.ShortID) (uint64, error) {
+	if amount, ok := s.feeRefunds[addr]; ok {
+		return amount, nil
+	}
+	amount, err := database.GetUInt64(s.feeRefundDB, addr[:])
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	return amount, err
+}
+
+func (s *state) SetFeeRefund(addr ids.ShortID, amount uint64) {
+	s.feeRefunds[addr] = amount
+}
+
 func (s *state) GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error) {
 	if tx, exists := s.transformedSubnets[subnetID]; exists {
 		return tx, nil
@@ -949,6 +1030,17 @@ func (s *state) GetChains(subnetID ids.ID) ([]*txs.Tx, error) {
 	return txs, nil
 }
 
+func (s *state) GetChainCreationHeight(subnetID, chainID ids.ID) (uint64, error) {
+	heightBytes, err := s.getChainDB(subnetID).Get(chainID[:])
+	if err != nil {
+		return 0, err
+	}
+	if len(heightBytes) == 0 {
+		return 0, database.ErrNotFound
+	}
+	return database.ParseUInt64(heightBytes)
+}
+
 func (s *state) AddChain(createChainTxIntf *txs.Tx) {
 	createChainTx := createChainTxIntf.Unsigned.(*txs.CreateChainTx)
 	subnetID := createChainTx.SubnetID
@@ -1256,6 +1348,98 @@ func applyWeightDiff(
 	return nil
 }
 
+func (s *state) GetValidatorWeightDiffs(
+	ctx context.Context,
+	subnetID ids.ID,
+	nodeID ids.NodeID,
+	startHeight uint64,
+	endHeight uint64,
+) ([]*HeightWeightDiff, error) {
+	var (
+		diffs      []*HeightWeightDiff
+		prevHeight = startHeight + 1
+	)
+	diffIter := s.flatValidatorWeightDiffsDB.NewIteratorWithStartAndPrefix(
+		marshalStartDiffKey(subnetID, startHeight),
+		subnetID[:],
+	)
+	defer diffIter.Release()
+
+	// TODO: Remove the index continuity checks once we are guaranteed nodes can
+	// not rollback to not support the new indexing mechanism.
+	for diffIter.Next() && s.indexedHeights != nil && s.indexedHeights.LowerBound <= endHeight {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		_, parsedHeight, parsedNodeID, err := unmarshalDiffKey(diffIter.Key())
+		if err != nil {
+			return nil, err
+		}
+		// If the parsedHeight is less than our target endHeight, then we have
+		// fully processed the diffs from startHeight through endHeight.
+		if parsedHeight < endHeight {
+			return diffs, diffIter.Error()
+		}
+
+		prevHeight = parsedHeight
+		if parsedNodeID != nodeID {
+			continue
+		}
+
+		weightDiff, err := unmarshalWeightDiff(diffIter.Value())
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, &HeightWeightDiff{
+			Height:              parsedHeight,
+			ValidatorWeightDiff: *weightDiff,
+		})
+	}
+	if err := diffIter.Error(); err != nil {
+		return nil, err
+	}
+
+	// TODO: Remove this once it is assumed that all subnet validators have
+	// adopted the new indexing.
+	//
+	// Note: [height] < [prevHeight] guards against underflowing past 0.
+	for height := prevHeight - 1; height >= endHeight && height < prevHeight; height-- {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		prefixStruct := heightWithSubnet{
+			Height:   height,
+			SubnetID: subnetID,
+		}
+		prefixBytes, err := block.GenesisCodec.Marshal(block.Version, prefixStruct)
+		if err != nil {
+			return nil, err
+		}
+
+		rawDiffDB := prefixdb.New(prefixBytes, s.nestedValidatorWeightDiffsDB)
+		diffDB := linkeddb.NewDefault(rawDiffDB)
+		weightDiffBytes, err := diffDB.Get(nodeID.Bytes())
+		if err == database.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		weightDiff := ValidatorWeightDiff{}
+		if _, err := block.GenesisCodec.Unmarshal(weightDiffBytes, &weightDiff); err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, &HeightWeightDiff{
+			Height:              height,
+			ValidatorWeightDiff: weightDiff,
+		})
+	}
+	return diffs, nil
+}
+
 func (s *state) ApplyValidatorPublicKeyDiffs(
 	ctx context.Context,
 	validators map[ids.NodeID]*validators.GetValidatorOutput,
@@ -1705,9 +1889,11 @@ func (s *state) write(updateValidators bool, height uint64) error {
 		s.writeUTXOs(),
 		s.writeSubnets(),
 		s.writeSubnetOwners(),
+		s.writeRevokedAddresses(),
+		s.writeFeeRefunds(),
 		s.writeTransformedSubnets(),
 		s.writeSubnetSupplies(),
-		s.writeChains(),
+		s.writeChains(height),
 		s.writeMetadata(),
 	)
 }
@@ -1729,6 +1915,8 @@ func (s *state) Close() error {
 		s.rewardUTXODB.Close(),
 		s.utxoDB.Close(),
 		s.subnetBaseDB.Close(),
+		s.revokedAddressDB.Close(),
+		s.feeRefundDB.Close(),
 		s.transformedSubnetDB.Close(),
 		s.supplyDB.Close(),
 		s.chainDB.Close(),
@@ -2315,6 +2503,35 @@ func (s *state) writeSubnetOwners() error {
 	return nil
 }
 
+func (s *state) writeRevokedAddresses() error {
+	for addr := range s.addedRevokedAddresses {
+		addr := addr
+		if err := s.revokedAddressDB.Put(addr[:], nil); err != nil {
+			return fmt.Errorf("failed to write revoked address: %w", err)
+		}
+	}
+	s.addedRevokedAddresses = nil
+	return nil
+}
+
+func (s *state) writeFeeRefunds() error {
+	for addr, amount := range s.feeRefunds {
+		addr := addr
+		delete(s.feeRefunds, addr)
+
+		var err error
+		if amount == 0 {
+			err = s.feeRefundDB.Delete(addr[:])
+		} else {
+			err = database.PutUInt64(s.feeRefundDB, addr[:], amount)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write fee refund: %w", err)
+		}
+	}
+	return nil
+}
+
 func (s *state) writeTransformedSubnets() error {
 	for subnetID, tx := range s.transformedSubnets {
 		txID := tx.ID()
@@ -2343,13 +2560,15 @@ func (s *state) writeSubnetSupplies() error {
 	return nil
 }
 
-func (s *state) writeChains() error {
+// writeChains records the chains added at [height].
+func (s *state) writeChains(height uint64) error {
+	heightBytes := database.PackUInt64(height)
 	for subnetID, chains := range s.addedChains {
 		for _, chain := range chains {
 			chainDB := s.getChainDB(subnetID)
 
 			chainID := chain.ID()
-			if err := chainDB.Put(chainID[:], nil); err != nil {
+			if err := chainDB.Put(chainID[:], heightBytes); err != nil {
 				return fmt.Errorf("failed to write chain: %w", err)
 			}
 		}
@@ -510,6 +510,88 @@ func TestStateAddRemoveValidator(t *testing.T) {
 	}
 }
 
+func TestStateGetValidatorWeightDiffs(t *testing.T) {
+	require := require.New(t)
+
+	state, _ := newInitializedState(require)
+
+	var (
+		subnetID  = ids.GenerateTestID()
+		startTime = time.Now()
+		endTime   = startTime.Add(24 * time.Hour)
+		staker    = Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  subnetID,
+			Weight:    5,
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+		otherStaker = Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  subnetID,
+			Weight:    7,
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	)
+
+	// Height 1: add [staker]
+	state.PutCurrentValidator(&staker)
+	state.SetHeight(1)
+	require.NoError(state.Commit())
+
+	// Height 2: add [otherStaker]
+	state.PutCurrentValidator(&otherStaker)
+	state.SetHeight(2)
+	require.NoError(state.Commit())
+
+	// Height 3: remove [staker]
+	state.DeleteCurrentValidator(&staker)
+	state.SetHeight(3)
+	require.NoError(state.Commit())
+
+	diffs, err := state.GetValidatorWeightDiffs(context.Background(), subnetID, staker.NodeID, 3, 1)
+	require.NoError(err)
+	require.Equal(
+		[]*HeightWeightDiff{
+			{
+				Height: 3,
+				ValidatorWeightDiff: ValidatorWeightDiff{
+					Decrease: true,
+					Amount:   staker.Weight,
+				},
+			},
+			{
+				Height: 1,
+				ValidatorWeightDiff: ValidatorWeightDiff{
+					Amount: staker.Weight,
+				},
+			},
+		},
+		diffs,
+	)
+
+	diffs, err = state.GetValidatorWeightDiffs(context.Background(), subnetID, staker.NodeID, 2, 2)
+	require.NoError(err)
+	require.Empty(diffs)
+
+	diffs, err = state.GetValidatorWeightDiffs(context.Background(), subnetID, otherStaker.NodeID, 3, 1)
+	require.NoError(err)
+	require.Equal(
+		[]*HeightWeightDiff{
+			{
+				Height: 2,
+				ValidatorWeightDiff: ValidatorWeightDiff{
+					Amount: otherStaker.Weight,
+				},
+			},
+		},
+		diffs,
+	)
+}
+
 func copyValidatorSet(
 	input map[ids.NodeID]*validators.GetValidatorOutput,
 ) map[ids.NodeID]*validators.GetValidatorOutput {
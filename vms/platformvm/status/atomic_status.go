@@ -0,0 +1,80 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+)
+
+// List of possible statuses of the import of an export tx's outputs:
+// - [AtomicProcessing] The export tx hasn't been accepted yet
+// - [AtomicPending] Some outputs haven't been imported by the destination chain
+// - [AtomicConsumed] All outputs were imported by the destination chain
+// - [AtomicOrphaned] The export tx was dropped, so nothing can be imported
+const (
+	AtomicProcessing AtomicStatus = iota
+	AtomicPending
+	AtomicConsumed
+	AtomicOrphaned
+)
+
+var (
+	errUnknownAtomicStatus = errors.New("unknown atomic status")
+
+	_ json.Marshaler    = AtomicStatus(0)
+	_ verify.Verifiable = AtomicStatus(0)
+	_ fmt.Stringer      = AtomicStatus(0)
+)
+
+type AtomicStatus uint32
+
+func (s AtomicStatus) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), s.Verify()
+}
+
+func (s *AtomicStatus) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case `"Processing"`:
+		*s = AtomicProcessing
+	case `"Pending"`:
+		*s = AtomicPending
+	case `"Consumed"`:
+		*s = AtomicConsumed
+	case `"Orphaned"`:
+		*s = AtomicOrphaned
+	case "null":
+	default:
+		return errUnknownAtomicStatus
+	}
+	return nil
+}
+
+// Verify that this is a valid status.
+func (s AtomicStatus) Verify() error {
+	switch s {
+	case AtomicProcessing, AtomicPending, AtomicConsumed, AtomicOrphaned:
+		return nil
+	default:
+		return errUnknownAtomicStatus
+	}
+}
+
+func (s AtomicStatus) String() string {
+	switch s {
+	case AtomicProcessing:
+		return "Processing"
+	case AtomicPending:
+		return "Pending"
+	case AtomicConsumed:
+		return "Consumed"
+	case AtomicOrphaned:
+		return "Orphaned"
+	default:
+		return "Invalid atomic status"
+	}
+}
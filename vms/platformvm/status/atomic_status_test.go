@@ -0,0 +1,61 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package status
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicStatusJSON(t *testing.T) {
+	require := require.New(t)
+
+	statuses := []AtomicStatus{
+		AtomicProcessing,
+		AtomicPending,
+		AtomicConsumed,
+		AtomicOrphaned,
+	}
+	for _, status := range statuses {
+		statusJSON, err := json.Marshal(status)
+		require.NoError(err)
+
+		var parsedStatus AtomicStatus
+		require.NoError(json.Unmarshal(statusJSON, &parsedStatus))
+		require.Equal(status, parsedStatus)
+	}
+
+	{
+		status := AtomicStatus(math.MaxInt32)
+		_, err := json.Marshal(status)
+		require.ErrorIs(err, errUnknownAtomicStatus)
+	}
+
+	{
+		status := AtomicConsumed
+		require.NoError(json.Unmarshal([]byte("null"), &status))
+		require.Equal(AtomicConsumed, status)
+	}
+
+	{
+		var status AtomicStatus
+		err := json.Unmarshal([]byte(`"not a status"`), &status)
+		require.ErrorIs(err, errUnknownAtomicStatus)
+	}
+}
+
+func TestAtomicStatusString(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("Processing", AtomicProcessing.String())
+	require.Equal("Pending", AtomicPending.String())
+	require.Equal("Consumed", AtomicConsumed.String())
+	require.Equal("Orphaned", AtomicOrphaned.String())
+
+	badStatus := AtomicStatus(math.MaxInt32)
+	require.Equal("Invalid atomic status", badStatus.String())
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	_ UnsignedTx = (*ClaimFeeRefundTx)(nil)
+
+	ErrNoFeeRefundClaimed  = errors.New("no fee refund claimed")
+	ErrNoFeeRefundClaimIns = errors.New("fee refund claim must consume at least one UTXO")
+)
+
+// ClaimFeeRefundTx claims the fees that an address overpaid. The claimed
+// amount is added to the AVAX consumed by the tx, so it can be spent by the
+// tx's outputs.
+//
+// The tx must consume at least one UTXO. Otherwise nothing would make the tx
+// unique, and it could be replayed to claim the same refund again.
+type ClaimFeeRefundTx struct {
+	// Metadata, inputs and outputs
+	BaseTx `serialize:"true"`
+	// Address whose refund is being claimed
+	Address ids.ShortID `serialize:"true" json:"address"`
+	// Amount of the refund being claimed
+	Amount uint64 `serialize:"true" json:"amount"`
+	// Proves that [Address] authorizes the claim
+	AddressAuth verify.Verifiable `serialize:"true" json:"addressAuthorization"`
+}
+
+// Owner returns the owner that must authorize this claim.
+func (tx *ClaimFeeRefundTx) Owner() *secp256k1fx.OutputOwners {
+	return &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{tx.Address},
+	}
+}
+
+func (tx *ClaimFeeRefundTx) SyntacticVerify(ctx *snow.Context) error {
+	switch {
+	case tx == nil:
+		return ErrNilTx
+	case tx.SyntacticallyVerified:
+		// already passed syntactic verification
+		return nil
+	case tx.Amount == 0:
+		return ErrNoFeeRefundClaimed
+	case len(tx.Ins) == 0:
+		return ErrNoFeeRefundClaimIns
+	}
+
+	if err := tx.BaseTx.SyntacticVerify(ctx); err != nil {
+		return err
+	}
+	if err := tx.AddressAuth.Verify(); err != nil {
+		return err
+	}
+
+	tx.SyntacticallyVerified = true
+	return nil
+}
+
+func (tx *ClaimFeeRefundTx) Visit(visitor Visitor) error {
+	return visitor.ClaimFeeRefundTx(tx)
+}
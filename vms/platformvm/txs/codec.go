@@ -107,5 +107,8 @@ func RegisterDUnsignedTxsTypes(targetCodec linearcodec.Codec) error {
 	return utils.Err(
 		targetCodec.RegisterType(&TransferSubnetOwnershipTx{}),
 		targetCodec.RegisterType(&BaseTx{}),
+		targetCodec.RegisterType(&RevokeAddressesTx{}),
+		targetCodec.RegisterType(&FailoverOwner{}),
+		targetCodec.RegisterType(&ClaimFeeRefundTx{}),
 	)
 }
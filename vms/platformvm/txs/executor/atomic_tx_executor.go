@@ -80,6 +80,14 @@ func (*AtomicTxExecutor) BaseTx(*txs.BaseTx) error {
 	return ErrWrongTxType
 }
 
+func (*AtomicTxExecutor) RevokeAddressesTx(*txs.RevokeAddressesTx) error {
+	return ErrWrongTxType
+}
+
+func (*AtomicTxExecutor) ClaimFeeRefundTx(*txs.ClaimFeeRefundTx) error {
+	return ErrWrongTxType
+}
+
 func (e *AtomicTxExecutor) ImportTx(tx *txs.ImportTx) error {
 	return e.atomicTx(tx)
 }
@@ -0,0 +1,93 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/subnets"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+)
+
+var ErrChurnLimitExceeded = errors.New("subnet validator set churn limit exceeded")
+
+// GetSubnetChurn returns the number of changes to the validator set of
+// [subnetID] in each epoch of [limit], starting from the epoch that contains
+// the current chain time. Epochs without any changes are omitted.
+//
+// Only changes that are recorded in [chainState] are counted. These are the
+// start times of current and pending permissioned validators, and their end
+// times.
+func GetSubnetChurn(chainState state.Chain, subnetID ids.ID, limit subnets.ChurnLimit) (map[uint64]uint64, error) {
+	currentEpoch := limit.Epoch(chainState.GetTimestamp())
+	churn := make(map[uint64]uint64)
+	addChange := func(changeTime time.Time) {
+		if epoch := limit.Epoch(changeTime); epoch >= currentEpoch {
+			churn[epoch]++
+		}
+	}
+
+	for _, getIterator := range []func() (state.StakerIterator, error){
+		chainState.GetCurrentStakerIterator,
+		chainState.GetPendingStakerIterator,
+	} {
+		stakerIterator, err := getIterator()
+		if err != nil {
+			return nil, err
+		}
+		for stakerIterator.Next() {
+			staker := stakerIterator.Value()
+			if staker.SubnetID != subnetID || !staker.Priority.IsPermissionedValidator() {
+				continue
+			}
+			addChange(staker.StartTime)
+			addChange(staker.EndTime)
+		}
+		stakerIterator.Release()
+	}
+	return churn, nil
+}
+
+// VerifySubnetChurn returns [ErrChurnLimitExceeded] if adding a validator to
+// [subnetID] from [startTime] until [endTime] would exceed the churn limit of
+// [subnetID] in either the epoch of [startTime] or the epoch of [endTime].
+//
+// Removing a validator before its end time isn't limited, so that misbehaving
+// validators can always be removed promptly.
+func VerifySubnetChurn(
+	backend *Backend,
+	chainState state.Chain,
+	subnetID ids.ID,
+	startTime time.Time,
+	endTime time.Time,
+) error {
+	limit, ok := backend.Config.GetSubnetChurnLimit(subnetID)
+	if !ok {
+		return nil
+	}
+
+	churn, err := GetSubnetChurn(chainState, subnetID, limit)
+	if err != nil {
+		return err
+	}
+
+	churn[limit.Epoch(startTime)]++
+	churn[limit.Epoch(endTime)]++
+	for _, epoch := range []uint64{limit.Epoch(startTime), limit.Epoch(endTime)} {
+		if churn[epoch] > limit.MaxChanges {
+			return fmt.Errorf(
+				"%w: subnet %s would have %d changes in the epoch starting at %s but at most %d are allowed",
+				ErrChurnLimitExceeded,
+				subnetID,
+				churn[epoch],
+				limit.EpochStartTime(epoch),
+				limit.MaxChanges,
+			)
+		}
+	}
+	return nil
+}
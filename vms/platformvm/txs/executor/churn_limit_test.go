@@ -0,0 +1,74 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/subnets"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+func TestVerifySubnetChurn(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, true /*=postBanff*/, true /*=postCortina*/)
+
+	subnetID := testSubnet1.ID()
+	limit := subnets.ChurnLimit{
+		EpochDuration: time.Hour,
+		MaxChanges:    2,
+	}
+	env.config.SubnetChurnLimits = map[ids.ID]subnets.ChurnLimit{
+		subnetID: limit,
+	}
+
+	chainTime := env.state.GetTimestamp()
+	epoch := limit.Epoch(chainTime)
+	epochStartTime := limit.EpochStartTime(epoch)
+	putPendingValidator := func(startTime, endTime time.Time) {
+		env.state.PutPendingValidator(&state.Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  subnetID,
+			Weight:    1,
+			StartTime: startTime,
+			EndTime:   endTime,
+			NextTime:  startTime,
+			Priority:  txs.SubnetPermissionedValidatorPendingPriority,
+		})
+	}
+
+	putPendingValidator(chainTime.Add(time.Second), epochStartTime.Add(5*time.Hour))
+	putPendingValidator(chainTime.Add(time.Second), epochStartTime.Add(3*time.Hour))
+
+	churn, err := GetSubnetChurn(env.state, subnetID, limit)
+	require.NoError(err)
+	require.Equal(
+		map[uint64]uint64{
+			epoch:     2,
+			epoch + 3: 1,
+			epoch + 5: 1,
+		},
+		churn,
+	)
+
+	// The epoch of the start time is full.
+	err = VerifySubnetChurn(&env.backend, env.state, subnetID, chainTime.Add(time.Second), epochStartTime.Add(4*time.Hour))
+	require.ErrorIs(err, ErrChurnLimitExceeded)
+
+	// The epochs of the start time and the end time have remaining changes.
+	require.NoError(VerifySubnetChurn(&env.backend, env.state, subnetID, epochStartTime.Add(time.Hour), epochStartTime.Add(3*time.Hour)))
+
+	// Starting and ending in the same epoch counts as two changes.
+	err = VerifySubnetChurn(&env.backend, env.state, subnetID, epochStartTime.Add(3*time.Hour), epochStartTime.Add(3*time.Hour+time.Minute))
+	require.ErrorIs(err, ErrChurnLimitExceeded)
+
+	// Other subnets aren't limited.
+	require.NoError(VerifySubnetChurn(&env.backend, env.state, ids.GenerateTestID(), chainTime.Add(time.Second), epochStartTime.Add(4*time.Hour)))
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package executor
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// accrueFeeRefund credits the AVAX that [ins] consume beyond what [outs]
+// produce and [fee] burns to the refund of the address that owns [ins], so
+// that it can later be claimed with a [*txs.ClaimFeeRefundTx].
+//
+// Overpayments are only credited once Durango is activated, and only if every
+// AVAX input spends an unlocked UTXO owned by the same single address.
+// Otherwise, the overpayment is burned.
+//
+// Must be called after the flow check passes and before [ins] are consumed.
+func accrueFeeRefund(
+	backend *Backend,
+	chainState state.Chain,
+	ins []*avax.TransferableInput,
+	outs []*avax.TransferableOutput,
+	fee uint64,
+) error {
+	return accrueImportFeeRefund(backend, chainState, ins, nil, outs, fee)
+}
+
+// accrueStakerFeeRefund is [accrueFeeRefund] for a staker tx, which
+// additionally produces [stakeOuts].
+func accrueStakerFeeRefund(
+	backend *Backend,
+	chainState state.Chain,
+	ins []*avax.TransferableInput,
+	outs []*avax.TransferableOutput,
+	stakeOuts []*avax.TransferableOutput,
+	fee uint64,
+) error {
+	allOuts := make([]*avax.TransferableOutput, len(outs)+len(stakeOuts))
+	copy(allOuts, outs)
+	copy(allOuts[len(outs):], stakeOuts)
+	return accrueFeeRefund(backend, chainState, ins, allOuts, fee)
+}
+
+// accrueImportFeeRefund is [accrueFeeRefund] for a tx that additionally
+// consumes [importedIns] from shared memory.
+//
+// The imported UTXOs aren't available while bootstrapping, so their owners
+// can't be verified deterministically. The imported AVAX counts towards the
+// overpayment, which is credited to the owner of [ins]. If [ins] don't spend
+// any AVAX, the overpayment is burned.
+func accrueImportFeeRefund(
+	backend *Backend,
+	chainState state.Chain,
+	ins []*avax.TransferableInput,
+	importedIns []*avax.TransferableInput,
+	outs []*avax.TransferableOutput,
+	fee uint64,
+) error {
+	if !backend.Config.IsDurangoActivated(chainState.GetTimestamp()) {
+		return nil
+	}
+
+	var (
+		avaxAssetID = backend.Ctx.AVAXAssetID
+		owner       ids.ShortID
+		hasOwner    bool
+		consumed    uint64
+		produced    uint64
+		err         error
+	)
+	for _, in := range ins {
+		if in.AssetID() != avaxAssetID {
+			continue
+		}
+
+		utxo, err := chainState.GetUTXO(in.InputID())
+		if err != nil {
+			return fmt.Errorf("failed to read consumed UTXO %s: %w", &in.UTXOID, err)
+		}
+		// Locked UTXOs are not [*secp256k1fx.TransferOutput]s, so refunds
+		// can't be used to unlock funds early.
+		out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok || out.Threshold != 1 || len(out.Addrs) != 1 {
+			return nil
+		}
+		if hasOwner && out.Addrs[0] != owner {
+			return nil
+		}
+		owner = out.Addrs[0]
+		hasOwner = true
+
+		consumed, err = math.Add64(consumed, in.In.Amount())
+		if err != nil {
+			return err
+		}
+	}
+	if !hasOwner {
+		return nil
+	}
+	for _, in := range importedIns {
+		if in.AssetID() != avaxAssetID {
+			continue
+		}
+		consumed, err = math.Add64(consumed, in.In.Amount())
+		if err != nil {
+			return err
+		}
+	}
+	for _, out := range outs {
+		if out.AssetID() != avaxAssetID {
+			continue
+		}
+		produced, err = math.Add64(produced, out.Out.Amount())
+		if err != nil {
+			return err
+		}
+	}
+
+	burned, err := math.Sub(consumed, produced)
+	if err != nil || burned <= fee {
+		return nil
+	}
+
+	refund, err := chainState.GetFeeRefund(owner)
+	if err != nil {
+		return fmt.Errorf("failed to get fee refund of %s: %w", owner, err)
+	}
+	refund, err = math.Add64(refund, burned-fee)
+	if err != nil {
+		return err
+	}
+	chainState.SetFeeRefund(owner, refund)
+	return nil
+}
@@ -0,0 +1,367 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/stakeable"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestAccrueFeeRefund(t *testing.T) {
+	var (
+		avaxAssetID = ids.GenerateTestID()
+		addr0       = ids.ShortID{0}
+		addr1       = ids.ShortID{1}
+		fee         = uint64(10)
+	)
+
+	newUTXO := func(amount uint64, threshold uint32, addrs ...ids.ShortID) *avax.UTXO {
+		return &avax.UTXO{
+			UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+			Asset:  avax.Asset{ID: avaxAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: threshold,
+					Addrs:     addrs,
+				},
+			},
+		}
+	}
+	newOutput := func(amount uint64) *avax.TransferableOutput {
+		return &avax.TransferableOutput{
+			Asset: avax.Asset{ID: avaxAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		durangoTime    time.Time
+		utxos          []*avax.UTXO
+		importedIns    []uint64
+		outs           []*avax.TransferableOutput
+		stakeOuts      []*avax.TransferableOutput
+		expectedRefund map[ids.ShortID]uint64
+	}{
+		{
+			name:        "before durango",
+			durangoTime: mockable.MaxTime,
+			utxos:       []*avax.UTXO{newUTXO(100, 1, addr0)},
+			outs:        []*avax.TransferableOutput{newOutput(50)},
+		},
+		{
+			name:  "exact fee",
+			utxos: []*avax.UTXO{newUTXO(100, 1, addr0)},
+			outs:  []*avax.TransferableOutput{newOutput(90)},
+		},
+		{
+			name:  "overpaid",
+			utxos: []*avax.UTXO{newUTXO(60, 1, addr0), newUTXO(40, 1, addr0)},
+			outs:  []*avax.TransferableOutput{newOutput(50)},
+			expectedRefund: map[ids.ShortID]uint64{
+				addr0: 40,
+			},
+		},
+		{
+			name:  "multiple owners",
+			utxos: []*avax.UTXO{newUTXO(60, 1, addr0), newUTXO(40, 1, addr1)},
+			outs:  []*avax.TransferableOutput{newOutput(50)},
+		},
+		{
+			name:  "multisig owner",
+			utxos: []*avax.UTXO{newUTXO(100, 1, addr0, addr1)},
+			outs:  []*avax.TransferableOutput{newOutput(50)},
+		},
+		{
+			name: "locked input",
+			utxos: []*avax.UTXO{{
+				UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+				Asset:  avax.Asset{ID: avaxAssetID},
+				Out: &stakeable.LockOut{
+					Locktime:        1,
+					TransferableOut: newUTXO(100, 1, addr0).Out.(*secp256k1fx.TransferOutput),
+				},
+			}},
+			outs: []*avax.TransferableOutput{newOutput(50)},
+		},
+		{
+			name:      "staked overpaid",
+			utxos:     []*avax.UTXO{newUTXO(100, 1, addr0)},
+			outs:      []*avax.TransferableOutput{newOutput(20)},
+			stakeOuts: []*avax.TransferableOutput{newOutput(30)},
+			expectedRefund: map[ids.ShortID]uint64{
+				addr0: 40,
+			},
+		},
+		{
+			name:      "staked exact fee",
+			utxos:     []*avax.UTXO{newUTXO(100, 1, addr0)},
+			outs:      []*avax.TransferableOutput{newOutput(20)},
+			stakeOuts: []*avax.TransferableOutput{newOutput(70)},
+		},
+		{
+			name:        "imported overpaid",
+			utxos:       []*avax.UTXO{newUTXO(20, 1, addr0)},
+			importedIns: []uint64{80},
+			outs:        []*avax.TransferableOutput{newOutput(50)},
+			expectedRefund: map[ids.ShortID]uint64{
+				addr0: 40,
+			},
+		},
+		{
+			name:        "imported exact fee",
+			utxos:       []*avax.UTXO{newUTXO(20, 1, addr0)},
+			importedIns: []uint64{80},
+			outs:        []*avax.TransferableOutput{newOutput(90)},
+		},
+		{
+			name:        "only imported",
+			importedIns: []uint64{100},
+			outs:        []*avax.TransferableOutput{newOutput(50)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			ins := make([]*avax.TransferableInput, len(test.utxos))
+			utxos := make(map[ids.ID]*avax.UTXO, len(test.utxos))
+			for i, utxo := range test.utxos {
+				ins[i] = &avax.TransferableInput{
+					UTXOID: utxo.UTXOID,
+					Asset:  utxo.Asset,
+					In: &secp256k1fx.TransferInput{
+						Amt: utxo.Out.(avax.Amounter).Amount(),
+					},
+				}
+				utxos[utxo.InputID()] = utxo
+			}
+			importedIns := make([]*avax.TransferableInput, len(test.importedIns))
+			for i, amount := range test.importedIns {
+				importedIns[i] = &avax.TransferableInput{
+					UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+					Asset:  avax.Asset{ID: avaxAssetID},
+					In: &secp256k1fx.TransferInput{
+						Amt: amount,
+					},
+				}
+			}
+
+			refunds := make(map[ids.ShortID]uint64)
+			chainState := state.NewMockChain(ctrl)
+			chainState.EXPECT().GetTimestamp().Return(time.Time{}.Add(time.Second)).AnyTimes()
+			chainState.EXPECT().GetUTXO(gomock.Any()).DoAndReturn(
+				func(utxoID ids.ID) (*avax.UTXO, error) {
+					return utxos[utxoID], nil
+				},
+			).AnyTimes()
+			chainState.EXPECT().GetFeeRefund(gomock.Any()).DoAndReturn(
+				func(addr ids.ShortID) (uint64, error) {
+					return refunds[addr], nil
+				},
+			).AnyTimes()
+			chainState.EXPECT().SetFeeRefund(gomock.Any(), gomock.Any()).Do(
+				func(addr ids.ShortID, amount uint64) {
+					refunds[addr] = amount
+				},
+			).AnyTimes()
+
+			backend := &Backend{
+				Config: &config.Config{
+					DurangoTime: test.durangoTime,
+				},
+				Ctx: &snow.Context{
+					AVAXAssetID: avaxAssetID,
+				},
+			}
+			if len(test.stakeOuts) > 0 {
+				require.NoError(accrueStakerFeeRefund(backend, chainState, ins, test.outs, test.stakeOuts, fee))
+			} else {
+				require.NoError(accrueImportFeeRefund(backend, chainState, ins, importedIns, test.outs, fee))
+			}
+
+			expectedRefund := test.expectedRefund
+			if expectedRefund == nil {
+				expectedRefund = map[ids.ShortID]uint64{}
+			}
+			require.Equal(expectedRefund, refunds)
+		})
+	}
+}
+
+func TestVerifyClaimFeeRefundTxInsufficientRefund(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	addr := ids.GenerateTestShortID()
+	unsignedTx := &txs.ClaimFeeRefundTx{
+		BaseTx: txs.BaseTx{
+			BaseTx: avax.BaseTx{
+				NetworkID:    1,
+				BlockchainID: ids.GenerateTestID(),
+				Ins: []*avax.TransferableInput{{
+					UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+					Asset:  avax.Asset{ID: ids.GenerateTestID()},
+					In: &secp256k1fx.TransferInput{
+						Amt: 1,
+					},
+				}},
+			},
+		},
+		Address:     addr,
+		Amount:      2,
+		AddressAuth: &secp256k1fx.Input{},
+	}
+	tx := &txs.Tx{Unsigned: unsignedTx}
+	require.NoError(tx.Initialize(txs.Codec))
+
+	chainState := state.NewMockChain(ctrl)
+	chainState.EXPECT().GetTimestamp().Return(time.Time{}.Add(time.Second))
+	chainState.EXPECT().GetFeeRefund(addr).Return(uint64(1), nil)
+
+	backend := &Backend{
+		Config:       &config.Config{},
+		Bootstrapped: &utils.Atomic[bool]{},
+		Ctx: &snow.Context{
+			NetworkID: 1,
+			ChainID:   unsignedTx.BlockchainID,
+		},
+	}
+	_, err := verifyClaimFeeRefundTx(backend, chainState, tx, unsignedTx)
+	require.ErrorIs(err, ErrInsufficientFeeRefund)
+}
+
+func TestVerifyClaimFeeRefundTxNoInputs(t *testing.T) {
+	require := require.New(t)
+
+	unsignedTx := &txs.ClaimFeeRefundTx{
+		BaseTx: txs.BaseTx{
+			BaseTx: avax.BaseTx{
+				NetworkID:    1,
+				BlockchainID: ids.GenerateTestID(),
+			},
+		},
+		Address:     ids.GenerateTestShortID(),
+		Amount:      2,
+		AddressAuth: &secp256k1fx.Input{},
+	}
+	ctx := &snow.Context{
+		NetworkID: 1,
+		ChainID:   unsignedTx.BlockchainID,
+	}
+	err := unsignedTx.SyntacticVerify(ctx)
+	require.ErrorIs(err, txs.ErrNoFeeRefundClaimIns)
+}
+
+func TestStandardTxExecutorClaimFeeRefundTx(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, true /*=postBanff*/, true /*=postCortina*/)
+	env.ctx.Lock.Lock()
+	defer func() {
+		require.NoError(shutdownEnvironment(env))
+	}()
+
+	var (
+		key    = preFundedKeys[0]
+		addr   = key.PublicKey().Address()
+		refund = 10 * defaultTxFee
+		claim  = 5 * defaultTxFee
+	)
+	env.state.SetFeeRefund(addr, refund)
+
+	// Spend a single UTXO of [addr] alongside the claim.
+	utxoIDs, err := env.state.UTXOIDs(addr.Bytes(), ids.Empty, 1)
+	require.NoError(err)
+	require.Len(utxoIDs, 1)
+	utxo, err := env.state.GetUTXO(utxoIDs[0])
+	require.NoError(err)
+	out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+	require.True(ok)
+
+	owner := secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{addr},
+	}
+	unsignedTx := &txs.ClaimFeeRefundTx{
+		BaseTx: txs.BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    env.ctx.NetworkID,
+			BlockchainID: env.ctx.ChainID,
+			Ins: []*avax.TransferableInput{{
+				UTXOID: utxo.UTXOID,
+				Asset:  utxo.Asset,
+				In: &secp256k1fx.TransferInput{
+					Amt: out.Amt,
+					Input: secp256k1fx.Input{
+						SigIndices: []uint32{0},
+					},
+				},
+			}},
+			Outs: []*avax.TransferableOutput{{
+				Asset: avax.Asset{ID: avaxAssetID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt:          out.Amt + claim - defaultTxFee,
+					OutputOwners: owner,
+				},
+			}},
+		}},
+		Address: addr,
+		Amount:  claim,
+		AddressAuth: &secp256k1fx.Input{
+			SigIndices: []uint32{0},
+		},
+	}
+	tx := &txs.Tx{Unsigned: unsignedTx}
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{key}, {key}}))
+
+	onAcceptState, err := state.NewDiff(lastAcceptedID, env)
+	require.NoError(err)
+	require.NoError(tx.Unsigned.Visit(&StandardTxExecutor{
+		Backend: &env.backend,
+		State:   onAcceptState,
+		Tx:      tx,
+	}))
+
+	remaining, err := onAcceptState.GetFeeRefund(addr)
+	require.NoError(err)
+	require.Equal(refund-claim, remaining)
+
+	onAcceptState.AddTx(tx, status.Committed)
+	require.NoError(onAcceptState.Apply(env.state))
+
+	// Replaying the claim must fail, as its input was already consumed.
+	onAcceptState, err = state.NewDiff(lastAcceptedID, env)
+	require.NoError(err)
+	err = tx.Unsigned.Visit(&StandardTxExecutor{
+		Backend: &env.backend,
+		State:   onAcceptState,
+		Tx:      tx,
+	})
+	require.ErrorIs(err, database.ErrNotFound)
+
+	remaining, err = env.state.GetFeeRefund(addr)
+	require.NoError(err)
+	require.Equal(refund-claim, remaining)
+}
@@ -105,6 +105,14 @@ func (*ProposalTxExecutor) BaseTx(*txs.BaseTx) error {
 	return ErrWrongTxType
 }
 
+func (*ProposalTxExecutor) RevokeAddressesTx(*txs.RevokeAddressesTx) error {
+	return ErrWrongTxType
+}
+
+func (*ProposalTxExecutor) ClaimFeeRefundTx(*txs.ClaimFeeRefundTx) error {
+	return ErrWrongTxType
+}
+
 func (e *ProposalTxExecutor) AddValidatorTx(tx *txs.AddValidatorTx) error {
 	// AddValidatorTx is a proposal transaction until the Banff fork
 	// activation. Following the activation, AddValidatorTxs must be issued into
@@ -440,7 +448,10 @@ func (e *ProposalTxExecutor) rewardValidatorTx(uValidatorTx txs.ValidatorTx, val
 	// Provide the reward here
 	reward := validator.PotentialReward
 	if reward > 0 {
-		validationRewardsOwner := uValidatorTx.ValidationRewardsOwner()
+		validationRewardsOwner, err := ResolveRewardsOwner(e.OnCommitState, uValidatorTx.ValidationRewardsOwner())
+		if err != nil {
+			return fmt.Errorf("failed to resolve rewards owner: %w", err)
+		}
 		outIntf, err := e.Fx.CreateOutput(reward, validationRewardsOwner)
 		if err != nil {
 			return fmt.Errorf("failed to create output: %w", err)
@@ -477,7 +488,10 @@ func (e *ProposalTxExecutor) rewardValidatorTx(uValidatorTx txs.ValidatorTx, val
 		return nil
 	}
 
-	delegationRewardsOwner := uValidatorTx.DelegationRewardsOwner()
+	delegationRewardsOwner, err := ResolveRewardsOwner(e.OnCommitState, uValidatorTx.DelegationRewardsOwner())
+	if err != nil {
+		return fmt.Errorf("failed to resolve rewards owner: %w", err)
+	}
 	outIntf, err := e.Fx.CreateOutput(delegateeReward, delegationRewardsOwner)
 	if err != nil {
 		return fmt.Errorf("failed to create output: %w", err)
@@ -566,7 +580,10 @@ func (e *ProposalTxExecutor) rewardDelegatorTx(uDelegatorTx txs.DelegatorTx, del
 	// Reward the delegator here
 	reward := delegatorReward
 	if reward > 0 {
-		rewardsOwner := uDelegatorTx.RewardsOwner()
+		rewardsOwner, err := ResolveRewardsOwner(e.OnCommitState, uDelegatorTx.RewardsOwner())
+		if err != nil {
+			return fmt.Errorf("failed to resolve rewards owner: %w", err)
+		}
 		outIntf, err := e.Fx.CreateOutput(reward, rewardsOwner)
 		if err != nil {
 			return fmt.Errorf("failed to create output: %w", err)
@@ -622,7 +639,10 @@ func (e *ProposalTxExecutor) rewardDelegatorTx(uDelegatorTx txs.DelegatorTx, del
 	} else {
 		// For any validators who started prior to [CortinaTime], we issue the
 		// [delegateeReward] immediately.
-		delegationRewardsOwner := vdrTx.DelegationRewardsOwner()
+		delegationRewardsOwner, err := ResolveRewardsOwner(e.OnCommitState, vdrTx.DelegationRewardsOwner())
+		if err != nil {
+			return fmt.Errorf("failed to resolve rewards owner: %w", err)
+		}
 		outIntf, err := e.Fx.CreateOutput(delegateeReward, delegationRewardsOwner)
 		if err != nil {
 			return fmt.Errorf("failed to create output: %w", err)
@@ -0,0 +1,76 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package executor
+
+import (
+	"github.com/ava-labs/avalanchego/vms/platformvm/fx"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+// verifyRewardsOwners returns an error if any of the provided rewards [owners]
+// uses a feature that isn't activated yet.
+func verifyRewardsOwners(
+	backend *Backend,
+	chainState state.Chain,
+	owners ...fx.Owner,
+) error {
+	for _, owner := range owners {
+		if _, ok := owner.(*txs.FailoverOwner); !ok {
+			continue
+		}
+		if !backend.Config.IsDurangoActivated(chainState.GetTimestamp()) {
+			return ErrDurangoUpgradeNotActive
+		}
+	}
+	return nil
+}
+
+// ResolveRewardsOwner returns the owner that rewards issued to [owner] should
+// be paid to based on [chainState].
+//
+// If [owner] is a [*txs.FailoverOwner], the arbitration rules are:
+//   - If the primary owner is spendable, the primary owner is paid.
+//   - If the primary owner is provably unspendable and the backup owner is
+//     spendable, the backup owner is paid.
+//   - If both owners are provably unspendable, the primary owner is paid.
+//
+// Any other owner is returned unmodified.
+func ResolveRewardsOwner(chainState state.Chain, owner fx.Owner) (fx.Owner, error) {
+	failoverOwner, ok := owner.(*txs.FailoverOwner)
+	if !ok {
+		return owner, nil
+	}
+
+	primaryUnspendable, err := IsProvablyUnspendable(chainState, failoverOwner.Primary)
+	if err != nil || !primaryUnspendable {
+		return failoverOwner.Primary, err
+	}
+
+	backupUnspendable, err := IsProvablyUnspendable(chainState, failoverOwner.Backup)
+	if err != nil {
+		return nil, err
+	}
+	if backupUnspendable {
+		return failoverOwner.Primary, nil
+	}
+	return failoverOwner.Backup, nil
+}
+
+// IsProvablyUnspendable returns true if so many of the addresses of [owner]
+// have been revoked that its threshold can no longer be met.
+func IsProvablyUnspendable(chainState state.Chain, owner *secp256k1fx.OutputOwners) (bool, error) {
+	var numSpendableAddrs uint32
+	for _, addr := range owner.Addrs {
+		revoked, err := chainState.IsAddressRevoked(addr)
+		if err != nil {
+			return false, err
+		}
+		if !revoked {
+			numSpendableAddrs++
+		}
+	}
+	return numSpendableAddrs < owner.Threshold, nil
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/fx"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestResolveRewardsOwner(t *testing.T) {
+	var (
+		addr0 = ids.ShortID{0}
+		addr1 = ids.ShortID{1}
+		addr2 = ids.ShortID{2}
+
+		primary = &secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{addr0, addr1},
+		}
+		backup = &secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{addr2},
+		}
+		failoverOwner = &txs.FailoverOwner{
+			Primary: primary,
+			Backup:  backup,
+		}
+	)
+
+	tests := []struct {
+		name          string
+		owner         fx.Owner
+		revoked       set.Set[ids.ShortID]
+		expectedOwner fx.Owner
+	}{
+		{
+			name:          "not a failover owner",
+			owner:         primary,
+			revoked:       set.Of(addr0, addr1),
+			expectedOwner: primary,
+		},
+		{
+			name:          "primary spendable",
+			owner:         failoverOwner,
+			revoked:       nil,
+			expectedOwner: primary,
+		},
+		{
+			name:          "primary threshold still reachable",
+			owner:         failoverOwner,
+			revoked:       set.Of(addr0),
+			expectedOwner: primary,
+		},
+		{
+			name:          "primary unspendable",
+			owner:         failoverOwner,
+			revoked:       set.Of(addr0, addr1),
+			expectedOwner: backup,
+		},
+		{
+			name:          "primary and backup unspendable",
+			owner:         failoverOwner,
+			revoked:       set.Of(addr0, addr1, addr2),
+			expectedOwner: primary,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			chainState := state.NewMockChain(ctrl)
+			chainState.EXPECT().IsAddressRevoked(gomock.Any()).DoAndReturn(
+				func(addr ids.ShortID) (bool, error) {
+					return test.revoked.Contains(addr), nil
+				},
+			).AnyTimes()
+
+			owner, err := ResolveRewardsOwner(chainState, test.owner)
+			require.NoError(err)
+			require.Equal(test.expectedOwner, owner)
+		})
+	}
+}
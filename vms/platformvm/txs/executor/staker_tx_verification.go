@@ -12,8 +12,10 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
 	"github.com/ava-labs/avalanchego/vms/platformvm/state"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 
 	safemath "github.com/ava-labs/avalanchego/utils/math"
 )
@@ -38,6 +40,9 @@ var (
 	ErrDelegateToPermissionedValidator = errors.New("delegation to permissioned validator")
 	ErrWrongStakedAssetID              = errors.New("incorrect staked assetID")
 	ErrDurangoUpgradeNotActive         = errors.New("attempting to use a Durango-upgrade feature prior to activation")
+	ErrUnauthorizedRevocation          = errors.New("unauthorized address revocation")
+	ErrInsufficientFeeRefund           = errors.New("claim exceeds the fee refund")
+	ErrUnauthorizedFeeRefundClaim      = errors.New("unauthorized fee refund claim")
 )
 
 // verifySubnetValidatorPrimaryNetworkRequirements verifies the primary
@@ -91,6 +96,10 @@ func verifyAddValidatorTx(
 		return nil, err
 	}
 
+	if err := verifyRewardsOwners(backend, chainState, tx.RewardsOwner); err != nil {
+		return nil, err
+	}
+
 	duration := tx.Validator.Duration()
 
 	switch {
@@ -349,6 +358,10 @@ func verifyAddDelegatorTx(
 		return nil, err
 	}
 
+	if err := verifyRewardsOwners(backend, chainState, tx.DelegationRewardsOwner); err != nil {
+		return nil, err
+	}
+
 	duration := tx.Validator.Duration()
 	switch {
 	case duration < backend.Config.MinStakeDuration:
@@ -465,6 +478,10 @@ func verifyAddPermissionlessValidatorTx(
 		return nil
 	}
 
+	if err := verifyRewardsOwners(backend, chainState, tx.ValidatorRewardsOwner, tx.DelegatorRewardsOwner); err != nil {
+		return err
+	}
+
 	currentTimestamp := chainState.GetTimestamp()
 	// Ensure the proposed validator starts after the current time
 	startTime := tx.StartTime()
@@ -533,15 +550,10 @@ func verifyAddPermissionlessValidatorTx(
 		)
 	}
 
-	var txFee uint64
 	if tx.Subnet != constants.PrimaryNetworkID {
 		if err := verifySubnetValidatorPrimaryNetworkRequirements(chainState, tx.Validator); err != nil {
 			return err
 		}
-
-		txFee = backend.Config.AddSubnetValidatorFee
-	} else {
-		txFee = backend.Config.AddPrimaryNetworkValidatorFee
 	}
 
 	outs := make([]*avax.TransferableOutput, len(tx.Outs)+len(tx.StakeOuts))
@@ -556,7 +568,7 @@ func verifyAddPermissionlessValidatorTx(
 		outs,
 		sTx.Creds,
 		map[ids.ID]uint64{
-			backend.Ctx.AVAXAssetID: txFee,
+			backend.Ctx.AVAXAssetID: addPermissionlessValidatorFee(backend.Config, tx.Subnet),
 		},
 	); err != nil {
 		return fmt.Errorf("%w: %w", ErrFlowCheckFailed, err)
@@ -572,6 +584,24 @@ func verifyAddPermissionlessValidatorTx(
 	return nil
 }
 
+// addPermissionlessValidatorFee returns the fee burned by an
+// AddPermissionlessValidatorTx that adds a validator to [subnetID].
+func addPermissionlessValidatorFee(cfg *config.Config, subnetID ids.ID) uint64 {
+	if subnetID != constants.PrimaryNetworkID {
+		return cfg.AddSubnetValidatorFee
+	}
+	return cfg.AddPrimaryNetworkValidatorFee
+}
+
+// addPermissionlessDelegatorFee returns the fee burned by an
+// AddPermissionlessDelegatorTx that adds a delegator to [subnetID].
+func addPermissionlessDelegatorFee(cfg *config.Config, subnetID ids.ID) uint64 {
+	if subnetID != constants.PrimaryNetworkID {
+		return cfg.AddSubnetDelegatorFee
+	}
+	return cfg.AddPrimaryNetworkDelegatorFee
+}
+
 // verifyAddPermissionlessDelegatorTx carries out the validation for an
 // AddPermissionlessDelegatorTx.
 func verifyAddPermissionlessDelegatorTx(
@@ -589,6 +619,10 @@ func verifyAddPermissionlessDelegatorTx(
 		return nil
 	}
 
+	if err := verifyRewardsOwners(backend, chainState, tx.DelegationRewardsOwner); err != nil {
+		return err
+	}
+
 	currentTimestamp := chainState.GetTimestamp()
 	// Ensure the proposed validator starts after the current timestamp
 	startTime := tx.StartTime()
@@ -675,7 +709,6 @@ func verifyAddPermissionlessDelegatorTx(
 	copy(outs, tx.Outs)
 	copy(outs[len(tx.Outs):], tx.StakeOuts)
 
-	var txFee uint64
 	if tx.Subnet != constants.PrimaryNetworkID {
 		// Invariant: Delegators must only be able to reference validator
 		//            transactions that implement [txs.ValidatorTx]. All
@@ -686,10 +719,6 @@ func verifyAddPermissionlessDelegatorTx(
 		if validator.Priority.IsPermissionedValidator() {
 			return ErrDelegateToPermissionedValidator
 		}
-
-		txFee = backend.Config.AddSubnetDelegatorFee
-	} else {
-		txFee = backend.Config.AddPrimaryNetworkDelegatorFee
 	}
 
 	// Verify the flowcheck
@@ -700,7 +729,7 @@ func verifyAddPermissionlessDelegatorTx(
 		outs,
 		sTx.Creds,
 		map[ids.ID]uint64{
-			backend.Ctx.AVAXAssetID: txFee,
+			backend.Ctx.AVAXAssetID: addPermissionlessDelegatorFee(backend.Config, tx.Subnet),
 		},
 	); err != nil {
 		return fmt.Errorf("%w: %w", ErrFlowCheckFailed, err)
@@ -762,3 +791,160 @@ func verifyTransferSubnetOwnershipTx(
 
 	return nil
 }
+
+// Returns an error if the given tx is invalid.
+// The transaction is valid if:
+// * [sTx]'s creds authorize it to spend the stated inputs.
+// * [sTx]'s creds authorize it to revoke every address in [tx.Addrs].
+// * The flow checker passes.
+func verifyRevokeAddressesTx(
+	backend *Backend,
+	chainState state.Chain,
+	sTx *txs.Tx,
+	tx *txs.RevokeAddressesTx,
+) error {
+	if !backend.Config.IsDurangoActivated(chainState.GetTimestamp()) {
+		return ErrDurangoUpgradeNotActive
+	}
+
+	// Verify the tx is well-formed
+	if err := sTx.SyntacticVerify(backend.Ctx); err != nil {
+		return err
+	}
+
+	if !backend.Bootstrapped.Get() {
+		// Not bootstrapped yet -- don't need to do full verification.
+		return nil
+	}
+
+	if len(sTx.Creds) == 0 {
+		// Ensure there is at least one credential for the revocation
+		return errWrongNumberOfCredentials
+	}
+
+	baseTxCredsLen := len(sTx.Creds) - 1
+	addrsCred := sTx.Creds[baseTxCredsLen]
+	if err := backend.Fx.VerifyPermission(tx, tx.AddrsAuth, addrsCred, tx.Owner()); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnauthorizedRevocation, err)
+	}
+
+	// Verify the flowcheck
+	if err := backend.FlowChecker.VerifySpend(
+		tx,
+		chainState,
+		tx.Ins,
+		tx.Outs,
+		sTx.Creds[:baseTxCredsLen],
+		map[ids.ID]uint64{
+			backend.Ctx.AVAXAssetID: backend.Config.TxFee,
+		},
+	); err != nil {
+		return fmt.Errorf("%w: %w", ErrFlowCheckFailed, err)
+	}
+
+	return nil
+}
+
+// Returns the fee refund of [tx.Address] if the given tx is valid, and an
+// error otherwise.
+// The transaction is valid if:
+// * [tx.Address] has at least [tx.Amount] of fees to be refunded.
+// * [sTx]'s creds authorize it to spend the stated inputs.
+// * [sTx]'s creds authorize it to claim the refund of [tx.Address].
+// * The flow checker passes, counting [tx.Amount] as consumed AVAX.
+func verifyClaimFeeRefundTx(
+	backend *Backend,
+	chainState state.Chain,
+	sTx *txs.Tx,
+	tx *txs.ClaimFeeRefundTx,
+) (uint64, error) {
+	if !backend.Config.IsDurangoActivated(chainState.GetTimestamp()) {
+		return 0, ErrDurangoUpgradeNotActive
+	}
+
+	// Verify the tx is well-formed
+	if err := sTx.SyntacticVerify(backend.Ctx); err != nil {
+		return 0, err
+	}
+
+	refund, err := chainState.GetFeeRefund(tx.Address)
+	if err != nil {
+		return 0, err
+	}
+	if tx.Amount > refund {
+		return 0, fmt.Errorf(
+			"%w: %s claimed %d but is owed %d",
+			ErrInsufficientFeeRefund,
+			tx.Address,
+			tx.Amount,
+			refund,
+		)
+	}
+
+	if !backend.Bootstrapped.Get() {
+		// Not bootstrapped yet -- don't need to do full verification.
+		return refund, nil
+	}
+
+	if len(sTx.Creds) == 0 {
+		// Ensure there is at least one credential for the claim
+		return 0, errWrongNumberOfCredentials
+	}
+	addressInput, ok := tx.AddressAuth.(*secp256k1fx.Input)
+	if !ok {
+		return 0, fmt.Errorf("%w: unexpected auth type %T", ErrUnauthorizedFeeRefundClaim, tx.AddressAuth)
+	}
+
+	// The refund is spent as if it were a UTXO owned by [tx.Address]. This
+	// UTXO only exists for the flow check, and is never written to state.
+	utxos := make([]*avax.UTXO, len(tx.Ins), len(tx.Ins)+1)
+	for i, in := range tx.Ins {
+		utxo, err := chainState.GetUTXO(in.InputID())
+		if err != nil {
+			return 0, fmt.Errorf(
+				"failed to read consumed UTXO %s due to: %w",
+				&in.UTXOID,
+				err,
+			)
+		}
+		utxos[i] = utxo
+	}
+	refundUTXO := &avax.UTXO{
+		UTXOID: avax.UTXOID{
+			TxID: sTx.ID(),
+		},
+		Asset: avax.Asset{ID: backend.Ctx.AVAXAssetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt:          tx.Amount,
+			OutputOwners: *tx.Owner(),
+		},
+	}
+	utxos = append(utxos, refundUTXO)
+
+	ins := make([]*avax.TransferableInput, len(tx.Ins), len(tx.Ins)+1)
+	copy(ins, tx.Ins)
+	ins = append(ins, &avax.TransferableInput{
+		UTXOID: refundUTXO.UTXOID,
+		Asset:  refundUTXO.Asset,
+		In: &secp256k1fx.TransferInput{
+			Amt:   tx.Amount,
+			Input: *addressInput,
+		},
+	})
+
+	// Verify the flowcheck
+	if err := backend.FlowChecker.VerifySpendUTXOs(
+		tx,
+		utxos,
+		ins,
+		tx.Outs,
+		sTx.Creds,
+		map[ids.ID]uint64{
+			backend.Ctx.AVAXAssetID: backend.Config.TxFee,
+		},
+	); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFlowCheckFailed, err)
+	}
+
+	return refund, nil
+}
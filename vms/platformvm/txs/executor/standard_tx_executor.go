@@ -74,6 +74,10 @@ func (e *StandardTxExecutor) CreateChainTx(tx *txs.CreateChainTx) error {
 		return err
 	}
 
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, createBlockchainTxFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 
 	// Consume the UTXOS
@@ -113,6 +117,10 @@ func (e *StandardTxExecutor) CreateSubnetTx(tx *txs.CreateSubnetTx) error {
 		return err
 	}
 
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, createSubnetTxFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 
 	// Consume the UTXOS
@@ -185,6 +193,12 @@ func (e *StandardTxExecutor) ImportTx(tx *txs.ImportTx) error {
 		}
 	}
 
+	// The refund must not depend on shared memory, so it's accrued even if the
+	// shared memory inputs weren't verified.
+	if err := accrueImportFeeRefund(e.Backend, e.State, tx.Ins, tx.ImportedInputs, tx.Outs, e.Config.TxFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 
 	// Consume the UTXOS
@@ -232,6 +246,10 @@ func (e *StandardTxExecutor) ExportTx(tx *txs.ExportTx) error {
 		return fmt.Errorf("failed verifySpend: %w", err)
 	}
 
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, outs, e.Config.TxFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 
 	// Consume the UTXOS
@@ -290,6 +308,10 @@ func (e *StandardTxExecutor) AddValidatorTx(tx *txs.AddValidatorTx) error {
 		return err
 	}
 
+	if err := accrueStakerFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, tx.StakeOuts, e.Config.AddPrimaryNetworkValidatorFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 	newStaker, err := state.NewPendingStaker(txID, tx)
 	if err != nil {
@@ -321,6 +343,10 @@ func (e *StandardTxExecutor) AddSubnetValidatorTx(tx *txs.AddSubnetValidatorTx)
 		return err
 	}
 
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, e.Config.AddSubnetValidatorFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 	newStaker, err := state.NewPendingStaker(txID, tx)
 	if err != nil {
@@ -344,6 +370,10 @@ func (e *StandardTxExecutor) AddDelegatorTx(tx *txs.AddDelegatorTx) error {
 		return err
 	}
 
+	if err := accrueStakerFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, tx.StakeOuts, e.Config.AddPrimaryNetworkDelegatorFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 	newStaker, err := state.NewPendingStaker(txID, tx)
 	if err != nil {
@@ -381,6 +411,10 @@ func (e *StandardTxExecutor) RemoveSubnetValidatorTx(tx *txs.RemoveSubnetValidat
 
 	// Invariant: There are no permissioned subnet delegators to remove.
 
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, e.Config.TxFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 	avax.Consume(e.State, tx.Ins)
 	avax.Produce(e.State, txID, tx.Outs)
@@ -422,6 +456,10 @@ func (e *StandardTxExecutor) TransformSubnetTx(tx *txs.TransformSubnetTx) error
 		return err
 	}
 
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, e.Config.TransformSubnetTxFee); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 
 	// Consume the UTXOS
@@ -444,6 +482,10 @@ func (e *StandardTxExecutor) AddPermissionlessValidatorTx(tx *txs.AddPermissionl
 		return err
 	}
 
+	if err := accrueStakerFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, tx.StakeOuts, addPermissionlessValidatorFee(e.Config, tx.Subnet)); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 	newStaker, err := state.NewPendingStaker(txID, tx)
 	if err != nil {
@@ -478,6 +520,10 @@ func (e *StandardTxExecutor) AddPermissionlessDelegatorTx(tx *txs.AddPermissionl
 		return err
 	}
 
+	if err := accrueStakerFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, tx.StakeOuts, addPermissionlessDelegatorFee(e.Config, tx.Subnet)); err != nil {
+		return err
+	}
+
 	txID := e.Tx.ID()
 	newStaker, err := state.NewPendingStaker(txID, tx)
 	if err != nil {
@@ -506,6 +552,10 @@ func (e *StandardTxExecutor) TransferSubnetOwnershipTx(tx *txs.TransferSubnetOwn
 		return err
 	}
 
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, e.Config.TxFee); err != nil {
+		return err
+	}
+
 	e.State.SetSubnetOwner(tx.Subnet, tx.Owner)
 
 	txID := e.Tx.ID()
@@ -515,6 +565,59 @@ func (e *StandardTxExecutor) TransferSubnetOwnershipTx(tx *txs.TransferSubnetOwn
 	return nil
 }
 
+// Verifies a [*txs.RevokeAddressesTx] and, if it passes, executes it on
+// [e.State]. For verification rules, see [verifyRevokeAddressesTx].
+// This transaction will result in every address in [tx.Addrs] being revoked.
+func (e *StandardTxExecutor) RevokeAddressesTx(tx *txs.RevokeAddressesTx) error {
+	err := verifyRevokeAddressesTx(
+		e.Backend,
+		e.State,
+		e.Tx,
+		tx,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, e.Config.TxFee); err != nil {
+		return err
+	}
+
+	for _, addr := range tx.Addrs {
+		e.State.RevokeAddress(addr)
+	}
+
+	txID := e.Tx.ID()
+	avax.Consume(e.State, tx.Ins)
+	avax.Produce(e.State, txID, tx.Outs)
+
+	return nil
+}
+
+// Verifies a [*txs.ClaimFeeRefundTx] and, if it passes, executes it on
+// [e.State]. For verification rules, see [verifyClaimFeeRefundTx].
+// This transaction will result in [tx.Amount] being deducted from the fee
+// refund of [tx.Address].
+func (e *StandardTxExecutor) ClaimFeeRefundTx(tx *txs.ClaimFeeRefundTx) error {
+	refund, err := verifyClaimFeeRefundTx(
+		e.Backend,
+		e.State,
+		e.Tx,
+		tx,
+	)
+	if err != nil {
+		return err
+	}
+
+	e.State.SetFeeRefund(tx.Address, refund-tx.Amount)
+
+	txID := e.Tx.ID()
+	avax.Consume(e.State, tx.Ins)
+	avax.Produce(e.State, txID, tx.Outs)
+
+	return nil
+}
+
 func (e *StandardTxExecutor) BaseTx(tx *txs.BaseTx) error {
 	if !e.Backend.Config.IsDurangoActivated(e.State.GetTimestamp()) {
 		return ErrDurangoUpgradeNotActive
@@ -539,6 +642,10 @@ func (e *StandardTxExecutor) BaseTx(tx *txs.BaseTx) error {
 		return err
 	}
 
+	if err := accrueFeeRefund(e.Backend, e.State, tx.Ins, tx.Outs, e.Config.TxFee); err != nil {
+		return err
+	}
+
 	// Consume the UTXOS
 	avax.Consume(e.State, tx.Ins)
 	// Produce the UTXOS
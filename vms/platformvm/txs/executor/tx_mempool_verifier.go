@@ -35,7 +35,18 @@ func (v *MempoolTxVerifier) AddValidatorTx(tx *txs.AddValidatorTx) error {
 }
 
 func (v *MempoolTxVerifier) AddSubnetValidatorTx(tx *txs.AddSubnetValidatorTx) error {
-	return v.standardTx(tx)
+	baseState, err := v.standardBaseState()
+	if err != nil {
+		return err
+	}
+
+	// The churn limit is a local policy, so it's only enforced when a
+	// transaction is added to the mempool rather than when a block is
+	// verified.
+	if err := VerifySubnetChurn(v.Backend, baseState, tx.SubnetValidator.Subnet, tx.StartTime(), tx.EndTime()); err != nil {
+		return err
+	}
+	return v.verifyStandardTx(baseState, tx)
 }
 
 func (v *MempoolTxVerifier) AddDelegatorTx(tx *txs.AddDelegatorTx) error {
@@ -82,18 +93,29 @@ func (v *MempoolTxVerifier) BaseTx(tx *txs.BaseTx) error {
 	return v.standardTx(tx)
 }
 
+func (v *MempoolTxVerifier) RevokeAddressesTx(tx *txs.RevokeAddressesTx) error {
+	return v.standardTx(tx)
+}
+
+func (v *MempoolTxVerifier) ClaimFeeRefundTx(tx *txs.ClaimFeeRefundTx) error {
+	return v.standardTx(tx)
+}
+
 func (v *MempoolTxVerifier) standardTx(tx txs.UnsignedTx) error {
 	baseState, err := v.standardBaseState()
 	if err != nil {
 		return err
 	}
+	return v.verifyStandardTx(baseState, tx)
+}
 
+func (v *MempoolTxVerifier) verifyStandardTx(baseState state.Diff, tx txs.UnsignedTx) error {
 	executor := StandardTxExecutor{
 		Backend: v.Backend,
 		State:   baseState,
 		Tx:      v.Tx,
 	}
-	err = tx.Visit(&executor)
+	err := tx.Visit(&executor)
 	// We ignore [errFutureStakeTime] here because the time will be advanced
 	// when this transaction is issued.
 	if errors.Is(err, ErrFutureStakeTime) {
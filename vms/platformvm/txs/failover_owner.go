@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/fx"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	_ fx.Owner = (*FailoverOwner)(nil)
+
+	ErrNilFailoverOwner     = errors.New("nil failover owner")
+	ErrRedundantBackupOwner = errors.New("backup owner is equal to the primary owner")
+)
+
+// FailoverOwner is a rewards owner that specifies a backup owner set. Rewards
+// are paid to [Primary] unless [Primary] is provably unspendable at the time of
+// the payout, in which case they are paid to [Backup].
+//
+// A FailoverOwner can be used anywhere a staking tx specifies a rewards owner.
+type FailoverOwner struct {
+	verify.IsNotState `json:"-"`
+
+	// Owner that is paid the rewards while it is spendable
+	Primary *secp256k1fx.OutputOwners `serialize:"true" json:"primary"`
+	// Owner that is paid the rewards once [Primary] is provably unspendable
+	Backup *secp256k1fx.OutputOwners `serialize:"true" json:"backup"`
+}
+
+func (o *FailoverOwner) InitCtx(ctx *snow.Context) {
+	o.Primary.InitCtx(ctx)
+	o.Backup.InitCtx(ctx)
+}
+
+func (o *FailoverOwner) Verify() error {
+	if o == nil {
+		return ErrNilFailoverOwner
+	}
+	if err := verify.All(o.Primary, o.Backup); err != nil {
+		return err
+	}
+	if o.Primary.Equals(o.Backup) {
+		return ErrRedundantBackupOwner
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+func TestFailoverOwnerVerify(t *testing.T) {
+	primary := &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{{1}},
+	}
+	backup := &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{{2}},
+	}
+
+	tests := []struct {
+		name        string
+		owner       *FailoverOwner
+		expectedErr error
+	}{
+		{
+			name:        "nil owner",
+			owner:       nil,
+			expectedErr: ErrNilFailoverOwner,
+		},
+		{
+			name: "nil primary",
+			owner: &FailoverOwner{
+				Backup: backup,
+			},
+			expectedErr: secp256k1fx.ErrNilOutput,
+		},
+		{
+			name: "nil backup",
+			owner: &FailoverOwner{
+				Primary: primary,
+			},
+			expectedErr: secp256k1fx.ErrNilOutput,
+		},
+		{
+			name: "invalid backup",
+			owner: &FailoverOwner{
+				Primary: primary,
+				Backup: &secp256k1fx.OutputOwners{
+					Threshold: 2,
+					Addrs:     []ids.ShortID{{2}},
+				},
+			},
+			expectedErr: secp256k1fx.ErrOutputUnspendable,
+		},
+		{
+			name: "backup equal to primary",
+			owner: &FailoverOwner{
+				Primary: primary,
+				Backup: &secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{{1}},
+				},
+			},
+			expectedErr: ErrRedundantBackupOwner,
+		},
+		{
+			name: "valid",
+			owner: &FailoverOwner{
+				Primary: primary,
+				Backup:  backup,
+			},
+			expectedErr: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.owner.Verify()
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
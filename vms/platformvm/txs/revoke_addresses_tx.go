@@ -0,0 +1,70 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"errors"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	_ UnsignedTx = (*RevokeAddressesTx)(nil)
+
+	ErrNoRevokedAddresses              = errors.New("no addresses to revoke")
+	ErrRevokedAddressesNotSortedUnique = errors.New("revoked addresses not sorted and unique")
+)
+
+// RevokeAddressesTx permanently marks a set of addresses as revoked. The keys
+// of revoked addresses are considered burned when arbitrating the payout of
+// rewards to a [FailoverOwner].
+type RevokeAddressesTx struct {
+	// Metadata, inputs and outputs
+	BaseTx `serialize:"true"`
+	// Addresses that are being revoked
+	Addrs []ids.ShortID `serialize:"true" json:"addresses"`
+	// Proves that every address being revoked assents to its revocation.
+	AddrsAuth verify.Verifiable `serialize:"true" json:"addressesAuthorization"`
+}
+
+// Owner returns the owner that must authorize this revocation. Every revoked
+// address must sign the tx.
+func (tx *RevokeAddressesTx) Owner() *secp256k1fx.OutputOwners {
+	return &secp256k1fx.OutputOwners{
+		Threshold: uint32(len(tx.Addrs)),
+		Addrs:     tx.Addrs,
+	}
+}
+
+func (tx *RevokeAddressesTx) SyntacticVerify(ctx *snow.Context) error {
+	switch {
+	case tx == nil:
+		return ErrNilTx
+	case tx.SyntacticallyVerified:
+		// already passed syntactic verification
+		return nil
+	case len(tx.Addrs) == 0:
+		return ErrNoRevokedAddresses
+	case !utils.IsSortedAndUnique(tx.Addrs):
+		return ErrRevokedAddressesNotSortedUnique
+	}
+
+	if err := tx.BaseTx.SyntacticVerify(ctx); err != nil {
+		return err
+	}
+	if err := tx.AddrsAuth.Verify(); err != nil {
+		return err
+	}
+
+	tx.SyntacticallyVerified = true
+	return nil
+}
+
+func (tx *RevokeAddressesTx) Visit(visitor Visitor) error {
+	return visitor.RevokeAddressesTx(tx)
+}
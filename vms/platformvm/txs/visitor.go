@@ -20,4 +20,6 @@ type Visitor interface {
 	AddPermissionlessDelegatorTx(*AddPermissionlessDelegatorTx) error
 	TransferSubnetOwnershipTx(*TransferSubnetOwnershipTx) error
 	BaseTx(*BaseTx) error
+	RevokeAddressesTx(*RevokeAddressesTx) error
+	ClaimFeeRefundTx(*ClaimFeeRefundTx) error
 }
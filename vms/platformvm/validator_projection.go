@@ -0,0 +1,213 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/subnets"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+const (
+	// Max number of timestamps that the validator set can be projected at in
+	// a single call to ProjectValidatorSet
+	maxProjectionTimestamps = 256
+	// Max number of hypothetical additions in a single call to
+	// ProjectValidatorSet
+	maxHypotheticalAdditions = 256
+	// Max number of hypothetical removals in a single call to
+	// ProjectValidatorSet
+	maxHypotheticalRemovals = 256
+)
+
+var (
+	errNoProjectionTimestamps   = errors.New("argument 'timestamps' not provided")
+	errTooManyProjectionTimes   = fmt.Errorf("at most %d timestamps can be projected", maxProjectionTimestamps)
+	errProjectionInThePast      = errors.New("projection timestamp is before the chain time")
+	errTooManyAdditions         = fmt.Errorf("at most %d hypothetical additions can be projected", maxHypotheticalAdditions)
+	errTooManyRemovals          = fmt.Errorf("at most %d hypothetical removals can be projected", maxHypotheticalRemovals)
+	errHypotheticalChurnLimited = errors.New("hypothetical staker would exceed the subnet's churn limit")
+	errHypotheticalZeroWeight   = errors.New("hypothetical staker must have a positive weight")
+	errHypotheticalStakerPeriod = errors.New("hypothetical staker must start before it ends")
+)
+
+// HypotheticalStaker is a staker that is assumed to be added to the validator
+// set when projecting it.
+type HypotheticalStaker struct {
+	NodeID    ids.NodeID  `json:"nodeID"`
+	Weight    json.Uint64 `json:"weight"`
+	StartTime json.Uint64 `json:"startTime"`
+	EndTime   json.Uint64 `json:"endTime"`
+}
+
+// HypotheticalRemoval is a validator that is assumed to be removed from the
+// validator set, along with its delegators, at [Time] when projecting it.
+type HypotheticalRemoval struct {
+	NodeID ids.NodeID  `json:"nodeID"`
+	Time   json.Uint64 `json:"time"`
+}
+
+// ProjectedValidator is a validator in a projected validator set.
+type ProjectedValidator struct {
+	NodeID ids.NodeID `json:"nodeID"`
+	// Weight of the validator, including its delegators
+	Weight json.Uint64 `json:"weight"`
+}
+
+// ValidatorSetProjection is the validator set projected at [Timestamp].
+type ValidatorSetProjection struct {
+	Timestamp json.Uint64 `json:"timestamp"`
+	// Validators in order of increasing node ID
+	Validators  []ProjectedValidator `json:"validators"`
+	TotalWeight json.Uint64          `json:"totalWeight"`
+}
+
+// projectedStaker is the period in which a staker contributes its weight to
+// the validator set.
+type projectedStaker struct {
+	nodeID    ids.NodeID
+	weight    uint64
+	startTime time.Time
+	endTime   time.Time
+}
+
+func (s *projectedStaker) isActive(timestamp time.Time) bool {
+	// Stakers are added to the validator set when the chain time reaches
+	// their start time, and removed when it reaches their end time.
+	return !s.startTime.After(timestamp) && timestamp.Before(s.endTime)
+}
+
+// validatorSetProjector projects the validator set of a subnet from its
+// current and pending stakers, together with hypothetical changes.
+type validatorSetProjector struct {
+	stakers  []projectedStaker
+	removals map[ids.NodeID]time.Time
+
+	// If [churn] is non-nil, hypothetical stakers are limited by
+	// [churnLimit], and [churn] is the number of changes in each of its
+	// epochs.
+	churnLimit subnets.ChurnLimit
+	churn      map[uint64]uint64
+}
+
+// setChurn limits the hypothetical stakers added after this call by [limit],
+// given the number of changes [churn] that are already scheduled in each of
+// its epochs.
+func (p *validatorSetProjector) setChurn(limit subnets.ChurnLimit, churn map[uint64]uint64) {
+	p.churnLimit = limit
+	p.churn = churn
+}
+
+// addStaker adds [staker], which must be a current or pending staker of the
+// projected subnet.
+func (p *validatorSetProjector) addStaker(staker *state.Staker) {
+	p.stakers = append(p.stakers, projectedStaker{
+		nodeID:    staker.NodeID,
+		weight:    staker.Weight,
+		startTime: staker.StartTime,
+		endTime:   staker.EndTime,
+	})
+}
+
+// addHypotheticalStaker adds [staker]. If the subnet's churn limit is set,
+// returns [errHypotheticalChurnLimited] without adding [staker] if it would
+// exceed the limit.
+func (p *validatorSetProjector) addHypotheticalStaker(staker HypotheticalStaker) error {
+	if staker.Weight == 0 {
+		return fmt.Errorf("%w: %s", errHypotheticalZeroWeight, staker.NodeID)
+	}
+	if staker.StartTime >= staker.EndTime {
+		return fmt.Errorf("%w: %s", errHypotheticalStakerPeriod, staker.NodeID)
+	}
+
+	startTime := time.Unix(int64(staker.StartTime), 0)
+	endTime := time.Unix(int64(staker.EndTime), 0)
+	if p.churn != nil {
+		// Matches the check that the chain applies to new permissioned
+		// validators. See [executor.VerifySubnetChurn].
+		startEpoch := p.churnLimit.Epoch(startTime)
+		endEpoch := p.churnLimit.Epoch(endTime)
+		p.churn[startEpoch]++
+		p.churn[endEpoch]++
+		if p.churn[startEpoch] > p.churnLimit.MaxChanges || p.churn[endEpoch] > p.churnLimit.MaxChanges {
+			p.churn[startEpoch]--
+			p.churn[endEpoch]--
+			return fmt.Errorf("%w: %s", errHypotheticalChurnLimited, staker.NodeID)
+		}
+	}
+
+	p.stakers = append(p.stakers, projectedStaker{
+		nodeID:    staker.NodeID,
+		weight:    uint64(staker.Weight),
+		startTime: startTime,
+		endTime:   endTime,
+	})
+	return nil
+}
+
+// addHypotheticalRemoval removes the stakers of [removal.NodeID] from the
+// projections at and after [removal.Time]. If the same node is removed more
+// than once, the earliest removal applies.
+func (p *validatorSetProjector) addHypotheticalRemoval(removal HypotheticalRemoval) {
+	if p.removals == nil {
+		p.removals = make(map[ids.NodeID]time.Time)
+	}
+	removalTime := time.Unix(int64(removal.Time), 0)
+	if prevTime, ok := p.removals[removal.NodeID]; ok && prevTime.Before(removalTime) {
+		return
+	}
+	p.removals[removal.NodeID] = removalTime
+}
+
+// project returns the validator set at [timestamp].
+func (p *validatorSetProjector) project(timestamp time.Time) (ValidatorSetProjection, error) {
+	weights := make(map[ids.NodeID]uint64)
+	for _, staker := range p.stakers {
+		if !staker.isActive(timestamp) {
+			continue
+		}
+		if removalTime, ok := p.removals[staker.nodeID]; ok && !timestamp.Before(removalTime) {
+			continue
+		}
+		weight, err := safemath.Add64(weights[staker.nodeID], staker.weight)
+		if err != nil {
+			return ValidatorSetProjection{}, err
+		}
+		weights[staker.nodeID] = weight
+	}
+
+	nodeIDs := maps.Keys(weights)
+	utils.Sort(nodeIDs)
+
+	projection := ValidatorSetProjection{
+		Timestamp:  json.Uint64(timestamp.Unix()),
+		Validators: make([]ProjectedValidator, len(nodeIDs)),
+	}
+	var totalWeight uint64
+	for i, nodeID := range nodeIDs {
+		weight := weights[nodeID]
+		projection.Validators[i] = ProjectedValidator{
+			NodeID: nodeID,
+			Weight: json.Uint64(weight),
+		}
+
+		var err error
+		totalWeight, err = safemath.Add64(totalWeight, weight)
+		if err != nil {
+			return ValidatorSetProjection{}, err
+		}
+	}
+	projection.TotalWeight = json.Uint64(totalWeight)
+	return projection, nil
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/subnets"
+	"github.com/ava-labs/avalanchego/utils/json"
+	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+func TestValidatorSetProjector(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		nodeID0 = ids.BuildTestNodeID([]byte{0})
+		nodeID1 = ids.BuildTestNodeID([]byte{1})
+		nodeID2 = ids.BuildTestNodeID([]byte{2})
+	)
+	projector := &validatorSetProjector{}
+	// Current validator with a current delegator
+	projector.addStaker(&state.Staker{
+		NodeID:    nodeID0,
+		Weight:    10,
+		StartTime: time.Unix(0, 0),
+		EndTime:   time.Unix(100, 0),
+	})
+	projector.addStaker(&state.Staker{
+		NodeID:    nodeID0,
+		Weight:    5,
+		StartTime: time.Unix(10, 0),
+		EndTime:   time.Unix(50, 0),
+	})
+	// Pending validator
+	projector.addStaker(&state.Staker{
+		NodeID:    nodeID1,
+		Weight:    20,
+		StartTime: time.Unix(30, 0),
+		EndTime:   time.Unix(200, 0),
+	})
+	require.NoError(projector.addHypotheticalStaker(HypotheticalStaker{
+		NodeID:    nodeID2,
+		Weight:    7,
+		StartTime: 60,
+		EndTime:   300,
+	}))
+	projector.addHypotheticalRemoval(HypotheticalRemoval{
+		NodeID: nodeID1,
+		Time:   150,
+	})
+
+	tests := []struct {
+		timestamp          int64
+		expectedValidators []ProjectedValidator
+		expectedWeight     uint64
+	}{
+		{
+			timestamp: 20,
+			expectedValidators: []ProjectedValidator{
+				{NodeID: nodeID0, Weight: 15},
+			},
+			expectedWeight: 15,
+		},
+		{
+			// Stakers are added at their start time
+			timestamp: 30,
+			expectedValidators: []ProjectedValidator{
+				{NodeID: nodeID0, Weight: 15},
+				{NodeID: nodeID1, Weight: 20},
+			},
+			expectedWeight: 35,
+		},
+		{
+			// Stakers are removed at their end time
+			timestamp: 60,
+			expectedValidators: []ProjectedValidator{
+				{NodeID: nodeID0, Weight: 10},
+				{NodeID: nodeID1, Weight: 20},
+				{NodeID: nodeID2, Weight: 7},
+			},
+			expectedWeight: 37,
+		},
+		{
+			// Removed validators are excluded at and after their removal
+			timestamp: 150,
+			expectedValidators: []ProjectedValidator{
+				{NodeID: nodeID2, Weight: 7},
+			},
+			expectedWeight: 7,
+		},
+		{
+			timestamp:          300,
+			expectedValidators: []ProjectedValidator{},
+			expectedWeight:     0,
+		},
+	}
+	for _, test := range tests {
+		projection, err := projector.project(time.Unix(test.timestamp, 0))
+		require.NoError(err)
+		require.Equal(ValidatorSetProjection{
+			Timestamp:   json.Uint64(test.timestamp),
+			Validators:  test.expectedValidators,
+			TotalWeight: json.Uint64(test.expectedWeight),
+		}, projection)
+	}
+}
+
+func TestValidatorSetProjectorInvalidStaker(t *testing.T) {
+	tests := []struct {
+		name        string
+		staker      HypotheticalStaker
+		expectedErr error
+	}{
+		{
+			name: "zero weight",
+			staker: HypotheticalStaker{
+				StartTime: 1,
+				EndTime:   2,
+			},
+			expectedErr: errHypotheticalZeroWeight,
+		},
+		{
+			name: "ends at start",
+			staker: HypotheticalStaker{
+				Weight:    1,
+				StartTime: 2,
+				EndTime:   2,
+			},
+			expectedErr: errHypotheticalStakerPeriod,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			projector := &validatorSetProjector{}
+			err := projector.addHypotheticalStaker(test.staker)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestValidatorSetProjectorChurnLimit(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		nodeID0 = ids.BuildTestNodeID([]byte{0})
+		nodeID1 = ids.BuildTestNodeID([]byte{1})
+		nodeID2 = ids.BuildTestNodeID([]byte{2})
+	)
+	limit := subnets.ChurnLimit{
+		EpochDuration: 10 * time.Second,
+		MaxChanges:    2,
+	}
+	projector := &validatorSetProjector{}
+	// One change is already scheduled in the epoch starting at 10.
+	projector.setChurn(limit, map[uint64]uint64{1: 1})
+
+	// Starts in the epoch starting at 10 and ends in the one starting at 20.
+	require.NoError(projector.addHypotheticalStaker(HypotheticalStaker{
+		NodeID:    nodeID0,
+		Weight:    1,
+		StartTime: 10,
+		EndTime:   20,
+	}))
+	// The epoch starting at 10 is full.
+	err := projector.addHypotheticalStaker(HypotheticalStaker{
+		NodeID:    nodeID1,
+		Weight:    1,
+		StartTime: 15,
+		EndTime:   40,
+	})
+	require.ErrorIs(err, errHypotheticalChurnLimited)
+	// The epoch starting at 20 has one change left.
+	err = projector.addHypotheticalStaker(HypotheticalStaker{
+		NodeID:    nodeID1,
+		Weight:    1,
+		StartTime: 20,
+		EndTime:   25,
+	})
+	require.ErrorIs(err, errHypotheticalChurnLimited)
+	require.NoError(projector.addHypotheticalStaker(HypotheticalStaker{
+		NodeID:    nodeID2,
+		Weight:    1,
+		StartTime: 20,
+		EndTime:   30,
+	}))
+
+	projection, err := projector.project(time.Unix(20, 0))
+	require.NoError(err)
+	require.Equal([]ProjectedValidator{
+		{
+			NodeID: nodeID2,
+			Weight: 1,
+		},
+	}, projection.Validators)
+}
+
+func TestValidatorSetProjectorOverflow(t *testing.T) {
+	require := require.New(t)
+
+	projector := &validatorSetProjector{}
+	for _, nodeID := range []ids.NodeID{ids.GenerateTestNodeID(), ids.GenerateTestNodeID()} {
+		require.NoError(projector.addHypotheticalStaker(HypotheticalStaker{
+			NodeID:    nodeID,
+			Weight:    math.MaxUint64,
+			StartTime: 0,
+			EndTime:   10,
+		}))
+	}
+	_, err := projector.project(time.Unix(5, 0))
+	require.ErrorIs(err, safemath.ErrOverflow)
+}
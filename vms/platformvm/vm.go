@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/gorilla/rpc/v2"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
 	"github.com/ava-labs/avalanchego/utils/json"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
@@ -90,6 +92,21 @@ type VM struct {
 
 	// TODO: Remove after v1.11.x is activated
 	pruned utils.Atomic[bool]
+
+	appSender common.AppSender
+	// If true, [AdminService] is served
+	adminAPIEnabled bool
+	// Node-local registry of the delegation offers of validators
+	delegationOffers             *delegationOffers
+	delegationOfferGossipEnabled bool
+
+	// Signers whose checkpoints are trusted and the number of them that must
+	// sign a checkpoint
+	checkpointTrustSet        map[ids.NodeID]*bls.PublicKey
+	checkpointSignerThreshold int
+	// Serializes checkpoint state exports, which are done without holding
+	// [ctx.Lock]
+	checkpointExportLock sync.Mutex
 }
 
 // Initialize this blockchain.
@@ -126,6 +143,25 @@ func (vm *VM) Initialize(
 
 	vm.ctx = chainCtx
 	vm.db = db
+	vm.appSender = appSender
+	vm.adminAPIEnabled = execConfig.AdminAPIEnabled
+	vm.delegationOffers = newDelegationOffers()
+	vm.delegationOfferGossipEnabled = execConfig.DelegationOfferGossipEnabled
+	vm.checkpointTrustSet, vm.checkpointSignerThreshold, err = ParseCheckpointTrustSet(
+		execConfig.CheckpointTrustSet,
+		execConfig.CheckpointSignerThreshold,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to parse checkpoint trust set: %w", err)
+	}
+
+	var checkpoint *Checkpoint
+	if execConfig.CheckpointFile != "" {
+		checkpoint, err = vm.initFromCheckpoint(ctx, execConfig.CheckpointFile, execConfig.CheckpointStateDir)
+		if err != nil {
+			return fmt.Errorf("failed to initialize from checkpoint: %w", err)
+		}
+	}
 
 	vm.codecRegistry = linearcodec.NewDefault()
 	vm.fx = &secp256k1fx.Fx{}
@@ -151,6 +187,11 @@ func (vm *VM) Initialize(
 
 	validatorManager := pvalidators.NewManager(chainCtx.Log, vm.Config, vm.state, vm.metrics, &vm.clock)
 	vm.State = validatorManager
+	if checkpoint != nil {
+		if err := vm.verifyImportedCheckpoint(ctx, checkpoint); err != nil {
+			return err
+		}
+	}
 	vm.atomicUtxosManager = avax.NewAtomicUTXOManager(chainCtx.SharedMemory, txs.Codec)
 	utxoHandler := utxo.NewHandler(vm.ctx, &vm.clock, vm.fx)
 	vm.uptimeManager = uptime.NewManager(vm.state, &vm.clock)
@@ -424,10 +465,23 @@ func (vm *VM) CreateHandlers(context.Context) (map[string]http.Handler, error) {
 			Size: stakerAttributesCacheSize,
 		},
 	}
-	err := server.RegisterService(service, "platform")
-	return map[string]http.Handler{
+	if err := server.RegisterService(service, "platform"); err != nil {
+		return nil, err
+	}
+	handlers := map[string]http.Handler{
 		"": server,
-	}, err
+	}
+	if !vm.adminAPIEnabled {
+		return handlers, nil
+	}
+
+	adminServer := rpc.NewServer()
+	adminServer.RegisterCodec(json.NewCodec(), "application/json")
+	adminServer.RegisterCodec(json.NewCodec(), "application/json;charset=UTF-8")
+	adminServer.RegisterInterceptFunc(vm.metrics.InterceptRequest)
+	adminServer.RegisterAfterFunc(vm.metrics.AfterRequest)
+	handlers["/admin"] = adminServer
+	return handlers, adminServer.RegisterService(&AdminService{vm: vm}, "admin")
 }
 
 // CreateStaticHandlers returns a map where:
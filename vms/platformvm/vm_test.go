@@ -137,6 +137,10 @@ type mutableSharedMemory struct {
 	atomic.SharedMemory
 }
 
+func (m *mutableSharedMemory) HasOutbound(peerChainID ids.ID, keys [][]byte) ([]bool, error) {
+	return m.SharedMemory.(atomic.OutboundReader).HasOutbound(peerChainID, keys)
+}
+
 func defaultContext(t *testing.T) *snow.Context {
 	require := require.New(t)
 
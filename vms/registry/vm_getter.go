@@ -6,7 +6,10 @@ package registry
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/filesystem"
@@ -14,12 +17,20 @@ import (
 	"github.com/ava-labs/avalanchego/vms"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime/subprocess"
 )
 
 var (
 	_ VMGetter = (*vmGetter)(nil)
 
 	errInvalidVMID = errors.New("invalid vmID")
+
+	// Extensions of files that are kept next to a plugin binary, rather than
+	// being plugins themselves.
+	sidecarExtensions = []string{
+		subprocess.ManifestExtension,
+		subprocess.SignatureExtension,
+	}
 )
 
 // VMGetter defines functionality to get the plugins on the node.
@@ -40,6 +51,12 @@ type VMGetterConfig struct {
 	PluginDirectory string
 	CPUTracker      resource.ProcessTracker
 	RuntimeTracker  runtime.Tracker
+	// If non-nil, plugins are run in a sandbox.
+	Sandbox *subprocess.Sandbox
+	// If non-nil, plugins are verified before they are launched.
+	Integrity *subprocess.Integrity
+	// Configures how gossip is relayed to plugins.
+	GossipRelay rpcchainvm.GossipRelayConfig
 }
 
 type vmGetter struct {
@@ -62,7 +79,14 @@ func (getter *vmGetter) Get() (map[ids.ID]vms.Factory, map[ids.ID]vms.Factory, e
 	registeredVMs := make(map[ids.ID]vms.Factory)
 	unregisteredVMs := make(map[ids.ID]vms.Factory)
 	for _, file := range files {
-		if file.IsDir() {
+		if file.IsDir() || isSidecar(file.Name()) {
+			continue
+		}
+		isRegular, err := getter.isRegular(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !isRegular {
 			continue
 		}
 
@@ -103,7 +127,35 @@ func (getter *vmGetter) Get() (map[ids.ID]vms.Factory, map[ids.ID]vms.Factory, e
 			filepath.Join(getter.config.PluginDirectory, file.Name()),
 			getter.config.CPUTracker,
 			getter.config.RuntimeTracker,
+			getter.config.Sandbox,
+			getter.config.Integrity,
+			getter.config.GossipRelay,
 		)
 	}
 	return registeredVMs, unregisteredVMs, nil
 }
+
+// isSidecar returns true if [name] is the name of a file that is kept next to
+// a plugin binary.
+func isSidecar(name string) bool {
+	for _, ext := range sidecarExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRegular returns true if [file] is a regular file, or a symlink to one.
+func (getter *vmGetter) isRegular(file fs.DirEntry) (bool, error) {
+	mode := file.Type()
+	if mode&fs.ModeSymlink == 0 {
+		return mode.IsRegular(), nil
+	}
+
+	info, err := os.Stat(filepath.Join(getter.config.PluginDirectory, file.Name()))
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve plugin %q: %w", file.Name(), err)
+	}
+	return info.Mode().IsRegular(), nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/resource"
 	"github.com/ava-labs/avalanchego/vms"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime/subprocess"
 )
 
 var (
@@ -46,6 +47,16 @@ var (
 	invalidVM = filesystem.MockFile{
 		MockName: "invalid-vm.file",
 	}
+	unregisteredVMManifest = filesystem.MockFile{
+		MockName: unregisteredVMName + subprocess.ManifestExtension,
+	}
+	unregisteredVMSignature = filesystem.MockFile{
+		MockName: unregisteredVMName + subprocess.SignatureExtension,
+	}
+	socket = filesystem.MockFile{
+		MockName: "plugin.sock",
+		MockType: fs.ModeSocket,
+	}
 
 	// read dir results
 	oneValidVM = []fs.DirEntry{
@@ -57,6 +68,14 @@ var (
 		registeredVM,
 		unregisteredVM,
 	}
+	vmWithSidecars = []fs.DirEntry{
+		directory,
+		unregisteredVMManifest,
+		socket,
+		unregisteredVM,
+		// Would replace the factory of [unregisteredVM] if it wasn't skipped.
+		unregisteredVMSignature,
+	}
 	invalidVMs = []fs.DirEntry{
 		directory,
 		invalidVM,
@@ -130,6 +149,25 @@ func TestGet_Success(t *testing.T) {
 	require.NoError(err)
 }
 
+// Get should skip the files next to a plugin that aren't plugins themselves.
+func TestGet_SkipsSidecars(t *testing.T) {
+	require := require.New(t)
+
+	resources := initVMGetterTest(t)
+
+	unregisteredVMId := ids.GenerateTestID()
+
+	resources.mockReader.EXPECT().ReadDir(pluginDir).Times(1).Return(vmWithSidecars, nil)
+	resources.mockManager.EXPECT().Lookup(unregisteredVMName).Times(1).Return(unregisteredVMId, nil)
+	resources.mockManager.EXPECT().GetFactory(unregisteredVMId).Times(1).Return(nil, vms.ErrNotFound)
+
+	registeredVMs, unregisteredVMs, err := resources.getter.Get()
+	require.NoError(err)
+	require.Empty(registeredVMs)
+	require.Len(unregisteredVMs, 1)
+	require.NotNil(unregisteredVMs[unregisteredVMId])
+}
+
 type vmGetterTestResources struct {
 	ctrl        *gomock.Controller
 	mockReader  *filesystem.MockReader
@@ -0,0 +1,156 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils/set"
+
+	vmpb "github.com/ava-labs/avalanchego/proto/pb/vm"
+)
+
+var (
+	_ block.AsyncBuildBlockChainVM = (*VMClient)(nil)
+	_ block.BlockBuild             = (*blockBuild)(nil)
+)
+
+// builds tracks the blocks being built by the remote VM.
+type builds struct {
+	lock sync.Mutex
+	// Block that builds are expected to be built on top of
+	preference ids.ID
+	// Builds that haven't finished yet
+	pending set.Set[*blockBuild]
+
+	// Holds a value while a BuildBlock request is being handled by the remote
+	// VM, so that the remote VM builds one block at a time.
+	building chan struct{}
+}
+
+// sendBuildBlock sends [req] to the remote VM once no other BuildBlock request is
+// being handled by it.
+func (vm *VMClient) sendBuildBlock(ctx context.Context, req *vmpb.BuildBlockRequest) (*vmpb.BuildBlockResponse, error) {
+	select {
+	case vm.builds.building <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() {
+		<-vm.builds.building
+	}()
+
+	return vm.client.BuildBlock(ctx, req)
+}
+
+// setPreference cancels the pending builds that were started on top of a
+// block other than [preference].
+func (b *builds) setPreference(preference ids.ID) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.preference = preference
+	for build := range b.pending {
+		if build.preference != preference {
+			build.Cancel()
+		}
+	}
+}
+
+// blockBuild is a block being built by the remote VM with a BuildBlock
+// request.
+//
+// Cancelling the build cancels the context of the request, which gRPC
+// propagates to the context the remote VM builds the block with.
+type blockBuild struct {
+	vm         *VMClient
+	preference ids.ID
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// Set before [done] is closed
+	resp      *vmpb.BuildBlockResponse
+	err       error
+	cancelled bool
+
+	// Set by the first call to Result
+	resultOnce sync.Once
+	blk        snowman.Block
+	resultErr  error
+}
+
+// StartBuildBlock sends a BuildBlock request to the remote VM without waiting
+// for the response. The request is cancelled if SetPreference is called with
+// a different block before the response is received.
+//
+// The request isn't sent until the remote VM has finished building any
+// previously requested block.
+func (vm *VMClient) StartBuildBlock(ctx context.Context, blockCtx *block.Context) block.BlockBuild {
+	req := &vmpb.BuildBlockRequest{}
+	if blockCtx != nil {
+		req.PChainHeight = &blockCtx.PChainHeight
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	build := &blockBuild{
+		vm:     vm,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	vm.builds.lock.Lock()
+	build.preference = vm.builds.preference
+	vm.builds.pending.Add(build)
+	vm.builds.lock.Unlock()
+
+	go func() {
+		build.resp, build.err = vm.sendBuildBlock(ctx, req)
+		build.cancelled = ctx.Err() != nil
+		cancel()
+
+		vm.builds.lock.Lock()
+		vm.builds.pending.Remove(build)
+		vm.builds.lock.Unlock()
+
+		close(build.done)
+	}()
+	return build
+}
+
+func (b *blockBuild) Done() <-chan struct{} {
+	return b.done
+}
+
+func (b *blockBuild) Result(ctx context.Context) (snowman.Block, error) {
+	<-b.done
+	b.resultOnce.Do(func() {
+		b.blk, b.resultErr = b.result(ctx)
+	})
+	return b.blk, b.resultErr
+}
+
+func (b *blockBuild) result(ctx context.Context) (snowman.Block, error) {
+	switch {
+	case b.err != nil && b.cancelled:
+		return nil, fmt.Errorf("block build cancelled: %w", context.Canceled)
+	case b.err != nil:
+		return nil, b.err
+	}
+
+	blk, err := b.vm.newBlockFromBuildBlock(b.resp)
+	if err != nil {
+		return nil, err
+	}
+	return b.vm.State.AddBuiltBlock(ctx, blk)
+}
+
+func (b *blockBuild) Cancel() {
+	b.cancel()
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block/mocks"
+)
+
+func asyncBuildBlockTestPlugin(t *testing.T, loadExpectations bool) block.ChainVM {
+	// test key is "asyncBuildBlockTestKey"
+
+	// create mock
+	ctrl := gomock.NewController(t)
+	ctxVM := ContextEnabledVMMock{
+		MockChainVM:                      mocks.NewMockChainVM(ctrl),
+		MockBuildBlockWithContextChainVM: mocks.NewMockBuildBlockWithContextChainVM(ctrl),
+	}
+
+	if loadExpectations {
+		blk := snowman.NewMockBlock(ctrl)
+		gomock.InOrder(
+			// Initialize
+			ctxVM.MockChainVM.EXPECT().Initialize(
+				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+				gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+				gomock.Any(),
+			).Return(nil).Times(1),
+			ctxVM.MockChainVM.EXPECT().LastAccepted(gomock.Any()).Return(preSummaryBlk.ID(), nil).Times(1),
+			ctxVM.MockChainVM.EXPECT().GetBlock(gomock.Any(), gomock.Any()).Return(preSummaryBlk, nil).Times(1),
+
+			// Builds without a block context succeed
+			ctxVM.MockChainVM.EXPECT().BuildBlock(gomock.Any()).Return(blk, nil).Times(1),
+			blk.EXPECT().ID().Return(blkID).Times(1),
+			blk.EXPECT().Parent().Return(preSummaryBlk.ID()).Times(1),
+			blk.EXPECT().Bytes().Return(blkBytes).Times(1),
+			blk.EXPECT().Height().Return(preSummaryBlk.Height()+1).Times(1),
+			blk.EXPECT().Timestamp().Return(time.Now()).Times(1),
+		)
+		// Builds with a block context only finish once they're cancelled. A
+		// build may be cancelled before it reaches the VM.
+		ctxVM.MockBuildBlockWithContextChainVM.EXPECT().BuildBlockWithContext(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ *block.Context) (snowman.Block, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		).MaxTimes(2)
+		ctxVM.MockChainVM.EXPECT().SetPreference(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	}
+
+	return ctxVM
+}
+
+func TestAsyncBuildBlock(t *testing.T) {
+	require := require.New(t)
+	testKey := asyncBuildBlockTestKey
+
+	// Create and start the plugin
+	vm, stopper := buildClientHelper(require, testKey)
+	defer stopper.Stop(context.Background())
+
+	ctx := snow.DefaultContextTest()
+
+	require.NoError(vm.Initialize(context.Background(), ctx, memdb.New(), nil, nil, nil, nil, nil, nil))
+
+	blockCtx := &block.Context{
+		PChainHeight: 1,
+	}
+
+	// Builds are sent to the VM one at a time, so the second build waits for
+	// the first to finish.
+	blockingBuild := vm.StartBuildBlock(context.Background(), blockCtx)
+	require.Eventually(func() bool {
+		return len(vm.builds.building) == 1
+	}, time.Second, time.Millisecond)
+	build := vm.StartBuildBlock(context.Background(), nil /*=blockCtx*/)
+	select {
+	case <-build.Done():
+		require.FailNow("build finished while another build was pending")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Cancelling the first build lets the second one be sent.
+	blockingBuild.Cancel()
+	_, err := blockingBuild.Result(context.Background())
+	require.ErrorIs(err, context.Canceled)
+
+	// Builds that aren't cancelled return the built block.
+	blk, err := build.Result(context.Background())
+	require.NoError(err)
+	require.Equal(blkID, blk.ID())
+	require.Equal(preSummaryBlk.ID(), blk.Parent())
+
+	// Cancelling a finished build has no effect.
+	build.Cancel()
+	sameBlk, err := build.Result(context.Background())
+	require.NoError(err)
+	require.Equal(blk, sameBlk)
+
+	// Changing the preference cancels the build on top of the previous
+	// preference.
+	build = vm.StartBuildBlock(context.Background(), blockCtx)
+	require.NoError(vm.SetPreference(context.Background(), ids.GenerateTestID()))
+	<-build.Done()
+	_, err = build.Result(context.Background())
+	require.ErrorIs(err, context.Canceled)
+}
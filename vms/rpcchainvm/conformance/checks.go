@@ -0,0 +1,285 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+)
+
+var (
+	errUnexpectedStatus       = errors.New("unexpected status")
+	errUnexpectedBlock        = errors.New("unexpected block")
+	errUnexpectedLastAccepted = errors.New("unexpected last accepted block")
+	errUnexpectedSummary      = errors.New("unexpected state summary")
+	errUnknownBlockReturned   = errors.New("unknown block was returned")
+
+	checks = []check{
+		{
+			name: "initialize",
+			run:  checkInitialize,
+		},
+		{
+			name: "parse block",
+			run:  checkParseBlock,
+		},
+		{
+			name: "get unknown block",
+			run:  checkGetUnknownBlock,
+		},
+		{
+			name: "build, verify and accept block",
+			run:  checkBuildVerifyAccept,
+		},
+		{
+			name: "build, verify and reject block",
+			run:  checkBuildVerifyReject,
+		},
+		{
+			name: "state sync",
+			run:  checkStateSync,
+		},
+		{
+			name: "shutdown",
+			run:  checkShutdown,
+		},
+	}
+)
+
+// checkInitialize verifies that the VM can be initialized and bootstrapped and
+// that it reports an accepted last accepted block.
+func checkInitialize(ctx context.Context, h *harness) error {
+	if err := h.initializeAndStart(ctx); err != nil {
+		return err
+	}
+
+	lastAccepted, err := h.lastAccepted(ctx)
+	if err != nil {
+		return err
+	}
+	return requireStatus(lastAccepted, choices.Accepted)
+}
+
+// checkParseBlock verifies that parsing the bytes of a block results in the
+// same block.
+func checkParseBlock(ctx context.Context, h *harness) error {
+	if err := h.initializeAndStart(ctx); err != nil {
+		return err
+	}
+
+	lastAccepted, err := h.lastAccepted(ctx)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := h.vm.ParseBlock(ctx, lastAccepted.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to parse block %s: %w", lastAccepted.ID(), err)
+	}
+	return requireSameBlock(lastAccepted, parsed)
+}
+
+// checkGetUnknownBlock verifies that the VM doesn't return blocks it doesn't
+// know about.
+func checkGetUnknownBlock(ctx context.Context, h *harness) error {
+	if err := h.initializeAndStart(ctx); err != nil {
+		return err
+	}
+
+	blkID := ids.GenerateTestID()
+	if _, err := h.vm.GetBlock(ctx, blkID); err == nil {
+		return fmt.Errorf("%w: %s", errUnknownBlockReturned, blkID)
+	}
+	return nil
+}
+
+// checkBuildVerifyAccept verifies that a block built on the last accepted
+// block can be verified and accepted, and that accepting it updates the last
+// accepted block.
+func checkBuildVerifyAccept(ctx context.Context, h *harness) error {
+	blk, err := buildAndVerify(ctx, h)
+	if err != nil {
+		return err
+	}
+
+	if err := h.vm.SetPreference(ctx, blk.ID()); err != nil {
+		return fmt.Errorf("failed to set preference to %s: %w", blk.ID(), err)
+	}
+	if err := blk.Accept(ctx); err != nil {
+		return fmt.Errorf("failed to accept block %s: %w", blk.ID(), err)
+	}
+	if err := requireStatus(blk, choices.Accepted); err != nil {
+		return err
+	}
+
+	lastAccepted, err := h.lastAccepted(ctx)
+	if err != nil {
+		return err
+	}
+	if lastAccepted.ID() != blk.ID() {
+		return fmt.Errorf("%w: expected %s but got %s", errUnexpectedLastAccepted, blk.ID(), lastAccepted.ID())
+	}
+	return requireStatus(lastAccepted, choices.Accepted)
+}
+
+// checkBuildVerifyReject verifies that a block built on the last accepted
+// block can be verified and rejected, and that rejecting it doesn't change the
+// last accepted block.
+func checkBuildVerifyReject(ctx context.Context, h *harness) error {
+	blk, err := buildAndVerify(ctx, h)
+	if err != nil {
+		return err
+	}
+
+	if err := blk.Reject(ctx); err != nil {
+		return fmt.Errorf("failed to reject block %s: %w", blk.ID(), err)
+	}
+	if err := requireStatus(blk, choices.Rejected); err != nil {
+		return err
+	}
+
+	lastAccepted, err := h.lastAccepted(ctx)
+	if err != nil {
+		return err
+	}
+	if lastAccepted.ID() != blk.Parent() {
+		return fmt.Errorf("%w: expected %s but got %s", errUnexpectedLastAccepted, blk.Parent(), lastAccepted.ID())
+	}
+	return nil
+}
+
+// checkStateSync verifies that the VM's state summaries can be parsed and
+// looked up by height.
+func checkStateSync(ctx context.Context, h *harness) error {
+	if err := h.initialize(ctx); err != nil {
+		return err
+	}
+
+	enabled, err := h.vm.StateSyncEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if state sync is enabled: %w", err)
+	}
+	if !enabled {
+		return fmt.Errorf("%w: state sync isn't enabled", errSkipped)
+	}
+
+	summary, err := h.vm.GetLastStateSummary(ctx)
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("%w: VM doesn't have a state summary", errSkipped)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get last state summary: %w", err)
+	}
+
+	parsed, err := h.vm.ParseStateSummary(ctx, summary.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to parse state summary %s: %w", summary.ID(), err)
+	}
+	if err := requireSameSummary(summary, parsed); err != nil {
+		return err
+	}
+
+	atHeight, err := h.vm.GetStateSummary(ctx, summary.Height())
+	if err != nil {
+		return fmt.Errorf("failed to get state summary at height %d: %w", summary.Height(), err)
+	}
+	return requireSameSummary(summary, atHeight)
+}
+
+// checkShutdown verifies that an initialized VM can be shut down.
+func checkShutdown(ctx context.Context, h *harness) error {
+	if err := h.initializeAndStart(ctx); err != nil {
+		return err
+	}
+
+	h.shutdown = true
+	if err := h.vm.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+// buildAndVerify builds a block on the last accepted block and verifies it.
+func buildAndVerify(ctx context.Context, h *harness) (snowman.Block, error) {
+	if err := h.initializeAndStart(ctx); err != nil {
+		return nil, err
+	}
+
+	lastAccepted, err := h.lastAccepted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.vm.SetPreference(ctx, lastAccepted.ID()); err != nil {
+		return nil, fmt.Errorf("failed to set preference to %s: %w", lastAccepted.ID(), err)
+	}
+
+	blk, err := h.buildBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if blk.Parent() != lastAccepted.ID() {
+		return nil, fmt.Errorf("%w: expected parent %s but got %s", errUnexpectedBlock, lastAccepted.ID(), blk.Parent())
+	}
+	if expectedHeight := lastAccepted.Height() + 1; blk.Height() != expectedHeight {
+		return nil, fmt.Errorf("%w: expected height %d but got %d", errUnexpectedBlock, expectedHeight, blk.Height())
+	}
+	if err := requireStatus(blk, choices.Processing); err != nil {
+		return nil, err
+	}
+
+	// The engine may receive the block from a peer rather than building it,
+	// so the parsed block must be identical.
+	parsed, err := h.vm.ParseBlock(ctx, blk.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse block %s: %w", blk.ID(), err)
+	}
+	if err := requireSameBlock(blk, parsed); err != nil {
+		return nil, err
+	}
+
+	if err := blk.Verify(ctx); err != nil {
+		return nil, fmt.Errorf("failed to verify block %s: %w", blk.ID(), err)
+	}
+	return blk, nil
+}
+
+func requireStatus(blk snowman.Block, expected choices.Status) error {
+	if status := blk.Status(); status != expected {
+		return fmt.Errorf("%w: expected block %s to be %s but was %s", errUnexpectedStatus, blk.ID(), expected, status)
+	}
+	return nil
+}
+
+func requireSameBlock(expected, actual snowman.Block) error {
+	switch {
+	case expected.ID() != actual.ID():
+		return fmt.Errorf("%w: expected ID %s but got %s", errUnexpectedBlock, expected.ID(), actual.ID())
+	case expected.Parent() != actual.Parent():
+		return fmt.Errorf("%w: expected parent %s but got %s", errUnexpectedBlock, expected.Parent(), actual.Parent())
+	case expected.Height() != actual.Height():
+		return fmt.Errorf("%w: expected height %d but got %d", errUnexpectedBlock, expected.Height(), actual.Height())
+	case !expected.Timestamp().Equal(actual.Timestamp()):
+		return fmt.Errorf("%w: expected timestamp %s but got %s", errUnexpectedBlock, expected.Timestamp(), actual.Timestamp())
+	default:
+		return nil
+	}
+}
+
+func requireSameSummary(expected, actual block.StateSummary) error {
+	switch {
+	case expected.ID() != actual.ID():
+		return fmt.Errorf("%w: expected ID %s but got %s", errUnexpectedSummary, expected.ID(), actual.ID())
+	case expected.Height() != actual.Height():
+		return fmt.Errorf("%w: expected height %d but got %d", errUnexpectedSummary, expected.Height(), actual.Height())
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/conformance"
+)
+
+var errChecksFailed = errors.New("conformance checks failed")
+
+func main() {
+	var (
+		pluginPath   string
+		networkID    uint32
+		genesisPath  string
+		upgradePath  string
+		configPath   string
+		buildTimeout time.Duration
+	)
+	rootCmd := &cobra.Command{
+		Use:   "vmconformance",
+		Short: "Check a VM plugin binary for compliance with the rpcchainvm protocol",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			config := conformance.Config{
+				PluginPath:   pluginPath,
+				NetworkID:    networkID,
+				BuildTimeout: buildTimeout,
+			}
+			var err error
+			if config.Genesis, err = readOptionalFile(genesisPath); err != nil {
+				return err
+			}
+			if config.Upgrade, err = readOptionalFile(upgradePath); err != nil {
+				return err
+			}
+			if config.Config, err = readOptionalFile(configPath); err != nil {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			results, err := conformance.Run(ctx, config)
+			if err != nil {
+				return err
+			}
+
+			failed := false
+			for _, result := range results {
+				if result.Err != nil {
+					fmt.Fprintf(os.Stdout, "%s %s: %s\n", result.Status, result.Name, result.Err)
+				} else {
+					fmt.Fprintf(os.Stdout, "%s %s\n", result.Status, result.Name)
+				}
+				failed = failed || result.Status == conformance.Failed
+			}
+			if failed {
+				// The failures have already been reported.
+				cmd.SilenceUsage = true
+				return errChecksFailed
+			}
+			return nil
+		},
+	}
+	rootCmd.Flags().StringVar(&pluginPath, "plugin-path", "", "Path to the VM plugin binary")
+	rootCmd.Flags().Uint32Var(&networkID, "network-id", 0, "[optional] Network ID to initialize the VM with")
+	rootCmd.Flags().StringVar(&genesisPath, "genesis-file", "", "[optional] Path to the genesis to initialize the VM with")
+	rootCmd.Flags().StringVar(&upgradePath, "upgrade-file", "", "[optional] Path to the upgrade bytes to initialize the VM with")
+	rootCmd.Flags().StringVar(&configPath, "config-file", "", "[optional] Path to the config to initialize the VM with")
+	rootCmd.Flags().DurationVar(&buildTimeout, "build-timeout", conformance.DefaultBuildTimeout, "How long to wait for the VM to request a block to be built")
+
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "vmconformance failed: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// readOptionalFile returns the contents of the file at [path], or nil if
+// [path] is empty.
+func readOptionalFile(path string) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,138 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package conformance drives an arbitrary VM plugin binary through the
+// lifecycle that avalanchego puts VMs through and reports whether the VM
+// complies with the contract expected by the consensus engine.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+const DefaultBuildTimeout = 5 * time.Second
+
+var (
+	ErrMissingPluginPath = errors.New("missing plugin path")
+
+	// errSkipped is wrapped by the errors of checks that couldn't be run
+	// against the VM, such as state sync checks against a VM that doesn't
+	// support state sync.
+	errSkipped = errors.New("skipped")
+)
+
+type Status int
+
+const (
+	Passed Status = iota
+	Failed
+	Skipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Passed:
+		return "PASS"
+	case Failed:
+		return "FAIL"
+	case Skipped:
+		return "SKIP"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name   string
+	Status Status
+	// Err is the reason the check failed or was skipped.
+	Err error
+}
+
+type Config struct {
+	// PluginPath is the path to the VM plugin binary.
+	PluginPath string
+	// NetworkID is the network ID that the VM is initialized with.
+	NetworkID uint32
+	// Genesis, Upgrade and Config are passed to the VM on initialization.
+	Genesis []byte
+	Upgrade []byte
+	Config  []byte
+	// BuildTimeout is how long to wait for the VM to notify the engine of
+	// pending transactions before attempting to build a block.
+	BuildTimeout time.Duration
+	// IssueTxs, if non-nil, is called before a block is built so that the VM
+	// has transactions to include in the block. VMs can't be made to build
+	// blocks generically, so block building checks are skipped if the VM
+	// can't build a block and IssueTxs is nil.
+	IssueTxs func(ctx context.Context, vm block.ChainVM) error
+	// Log is used for the VM's logs. Defaults to [logging.NoLog].
+	Log logging.Logger
+}
+
+type check struct {
+	name string
+	run  func(context.Context, *harness) error
+}
+
+// Run runs every check against a new instance of the plugin and returns the
+// results in the order the checks were run.
+func Run(ctx context.Context, config Config) ([]Result, error) {
+	if len(config.PluginPath) == 0 {
+		return nil, ErrMissingPluginPath
+	}
+	if config.BuildTimeout == 0 {
+		config.BuildTimeout = DefaultBuildTimeout
+	}
+	if config.Log == nil {
+		config.Log = logging.NoLog{}
+	}
+
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, runCheck(ctx, config, c))
+	}
+	return results, nil
+}
+
+// runCheck runs [c] against a new instance of the plugin so that checks can't
+// affect each other.
+func runCheck(ctx context.Context, config Config, c check) Result {
+	result := Result{
+		Name: c.name,
+	}
+
+	h, err := newHarness(config)
+	if err != nil {
+		result.Status = Failed
+		result.Err = fmt.Errorf("failed to start plugin: %w", err)
+		return result
+	}
+
+	err = c.run(ctx, h)
+	closeErr := h.close(ctx)
+	switch {
+	case errors.Is(err, errSkipped):
+		result.Status = Skipped
+		result.Err = err
+	case err != nil:
+		result.Status = Failed
+		result.Err = err
+	case closeErr != nil:
+		result.Status = Failed
+		result.Err = fmt.Errorf("failed to shut down: %w", closeErr)
+	default:
+		result.Status = Passed
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package conformance
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMissingPluginPath(t *testing.T) {
+	_, err := Run(context.Background(), Config{})
+	require.ErrorIs(t, err, ErrMissingPluginPath)
+}
+
+func TestRunMissingPlugin(t *testing.T) {
+	require := require.New(t)
+
+	results, err := Run(context.Background(), Config{
+		PluginPath: filepath.Join(t.TempDir(), "missing"),
+	})
+	require.NoError(err)
+	require.Len(results, len(checks))
+	for i, result := range results {
+		require.Equal(checks[i].name, result.Name)
+		require.Equal(Failed, result.Status)
+	}
+}
+
+func TestRunConformingVM(t *testing.T) {
+	require := require.New(t)
+
+	results, err := Run(context.Background(), Config{
+		PluginPath: newTestPlugin(t, conformingVMName),
+	})
+	require.NoError(err)
+	require.Len(results, len(checks))
+	for i, result := range results {
+		require.Equal(checks[i].name, result.Name)
+		if result.Name == "state sync" {
+			// [conformingVM] doesn't support state sync.
+			require.Equal(Skipped, result.Status)
+			require.ErrorIs(result.Err, errSkipped)
+			continue
+		}
+		require.Equal(Passed, result.Status, result.Err)
+	}
+}
+
+func TestRunStateSyncableVM(t *testing.T) {
+	require := require.New(t)
+
+	results, err := Run(context.Background(), Config{
+		PluginPath: newTestPlugin(t, stateSyncableVMName),
+	})
+	require.NoError(err)
+	require.Len(results, len(checks))
+	for i, result := range results {
+		require.Equal(checks[i].name, result.Name)
+		require.Equal(Passed, result.Status, result.Err)
+	}
+}
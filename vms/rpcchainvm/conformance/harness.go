@@ -0,0 +1,249 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ava-labs/avalanchego/api/keystore"
+	"github.com/ava-labs/avalanchego/api/metrics"
+	"github.com/ava-labs/avalanchego/chains/atomic"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/resource"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime"
+)
+
+const toEngineBufferSize = 1024
+
+var (
+	_ resource.ProcessTracker = noProcessTracker{}
+	_ validators.State        = (*noValidatorsState)(nil)
+	_ common.AppSender        = noAppSender{}
+)
+
+// harness is a single instance of the plugin under test.
+type harness struct {
+	config Config
+
+	vm             *rpcchainvm.VMClient
+	runtimeManager runtime.Manager
+	toEngine       chan common.Message
+	chainDataDir   string
+
+	initialized bool
+	shutdown    bool
+}
+
+func newHarness(config Config) (*harness, error) {
+	runtimeManager := runtime.NewManager()
+	factory := rpcchainvm.NewFactory(config.PluginPath, noProcessTracker{}, runtimeManager, nil, nil, rpcchainvm.DefaultGossipRelayConfig)
+	vmIntf, err := factory.New(config.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	chainDataDir, err := os.MkdirTemp("", "vm-conformance")
+	if err != nil {
+		runtimeManager.Stop(context.Background())
+		return nil, err
+	}
+
+	return &harness{
+		config:         config,
+		vm:             vmIntf.(*rpcchainvm.VMClient),
+		runtimeManager: runtimeManager,
+		toEngine:       make(chan common.Message, toEngineBufferSize),
+		chainDataDir:   chainDataDir,
+	}, nil
+}
+
+// initialize initializes the VM as a new chain.
+func (h *harness) initialize(ctx context.Context) error {
+	sk, err := bls.NewSecretKey()
+	if err != nil {
+		return err
+	}
+
+	var (
+		chainID  = ids.GenerateTestID()
+		subnetID = ids.GenerateTestID()
+	)
+	chainCtx := &snow.Context{
+		NetworkID:    h.config.NetworkID,
+		SubnetID:     subnetID,
+		ChainID:      chainID,
+		NodeID:       ids.GenerateTestNodeID(),
+		PublicKey:    bls.PublicFromSecretKey(sk),
+		XChainID:     ids.GenerateTestID(),
+		CChainID:     ids.GenerateTestID(),
+		AVAXAssetID:  ids.GenerateTestID(),
+		Log:          h.config.Log,
+		Keystore:     keystore.New(h.config.Log, memdb.New()).NewBlockchainKeyStore(chainID),
+		SharedMemory: atomic.NewMemory(memdb.New()).NewSharedMemory(chainID),
+		BCLookup:     ids.NewAliaser(),
+		Metrics:      metrics.NewOptionalGatherer(),
+		WarpSigner:   warp.NewSigner(sk, h.config.NetworkID, chainID),
+		ValidatorState: &noValidatorsState{
+			subnetID: subnetID,
+		},
+		ChainDataDir: h.chainDataDir,
+	}
+
+	if err := h.vm.Initialize(
+		ctx,
+		chainCtx,
+		memdb.New(),
+		h.config.Genesis,
+		h.config.Upgrade,
+		h.config.Config,
+		h.toEngine,
+		nil,
+		noAppSender{},
+	); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	h.initialized = true
+	return nil
+}
+
+// initializeAndStart initializes the VM and transitions it into normal
+// operation, as if it had just finished bootstrapping.
+func (h *harness) initializeAndStart(ctx context.Context) error {
+	if err := h.initialize(ctx); err != nil {
+		return err
+	}
+	if err := h.vm.SetState(ctx, snow.Bootstrapping); err != nil {
+		return fmt.Errorf("failed to transition to %s: %w", snow.Bootstrapping, err)
+	}
+	if err := h.vm.SetState(ctx, snow.NormalOp); err != nil {
+		return fmt.Errorf("failed to transition to %s: %w", snow.NormalOp, err)
+	}
+	return nil
+}
+
+// lastAccepted returns the VM's last accepted block.
+func (h *harness) lastAccepted(ctx context.Context) (snowman.Block, error) {
+	blkID, err := h.vm.LastAccepted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last accepted block ID: %w", err)
+	}
+	blk, err := h.vm.GetBlock(ctx, blkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last accepted block %s: %w", blkID, err)
+	}
+	return blk, nil
+}
+
+// buildBlock builds a block on top of the VM's preferred block.
+//
+// If the VM can't build a block and [Config.IssueTxs] is nil, the returned
+// error wraps [errSkipped].
+func (h *harness) buildBlock(ctx context.Context) (snowman.Block, error) {
+	if h.config.IssueTxs != nil {
+		if err := h.config.IssueTxs(ctx, h.vm); err != nil {
+			return nil, fmt.Errorf("failed to issue txs: %w", err)
+		}
+	}
+
+	// Give the VM a chance to signal that it's ready to build a block. VMs
+	// aren't required to do so, so a block is attempted regardless.
+	timer := time.NewTimer(h.config.BuildTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-h.toEngine:
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	blk, err := h.vm.BuildBlock(ctx)
+	switch {
+	case err == nil:
+		return blk, nil
+	case h.config.IssueTxs == nil:
+		return nil, fmt.Errorf("%w: couldn't build a block without issued txs: %w", errSkipped, err)
+	default:
+		return nil, fmt.Errorf("failed to build block: %w", err)
+	}
+}
+
+// close shuts down the VM, if it was initialized and hasn't already been shut
+// down, and stops the plugin.
+func (h *harness) close(ctx context.Context) error {
+	errs := wrappers.Errs{}
+	if h.initialized && !h.shutdown {
+		errs.Add(h.vm.Shutdown(ctx))
+	}
+	h.runtimeManager.Stop(ctx)
+	errs.Add(os.RemoveAll(h.chainDataDir))
+	return errs.Err
+}
+
+type noProcessTracker struct{}
+
+func (noProcessTracker) TrackProcess(int) {}
+
+func (noProcessTracker) UntrackProcess(int) {}
+
+// noValidatorsState reports an empty validator set for the VM's subnet.
+type noValidatorsState struct {
+	subnetID ids.ID
+}
+
+func (*noValidatorsState) GetMinimumHeight(context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func (*noValidatorsState) GetCurrentHeight(context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func (s *noValidatorsState) GetSubnetID(context.Context, ids.ID) (ids.ID, error) {
+	return s.subnetID, nil
+}
+
+func (*noValidatorsState) GetValidatorSet(context.Context, uint64, ids.ID) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	return map[ids.NodeID]*validators.GetValidatorOutput{}, nil
+}
+
+// noAppSender drops every message, as the VM under test has no peers.
+type noAppSender struct{}
+
+func (noAppSender) SendAppRequest(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
+	return nil
+}
+
+func (noAppSender) SendAppResponse(context.Context, ids.NodeID, uint32, []byte) error {
+	return nil
+}
+
+func (noAppSender) SendAppGossip(context.Context, []byte) error {
+	return nil
+}
+
+func (noAppSender) SendAppGossipSpecific(context.Context, set.Set[ids.NodeID], []byte) error {
+	return nil
+}
+
+func (noAppSender) SendCrossChainAppRequest(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
+
+func (noAppSender) SendCrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return nil
+}
@@ -0,0 +1,252 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package conformance
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/snow/choices"
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime"
+)
+
+const (
+	conformingVMName    = "conforming"
+	stateSyncableVMName = "state-syncable"
+	testBlockLen        = ids.IDLen + 2*8
+	testStateSummaryLen = 8
+)
+
+var (
+	_ block.ChainVM         = (*conformingVM)(nil)
+	_ block.StateSyncableVM = (*stateSyncableVM)(nil)
+
+	errInvalidBlockLength   = errors.New("invalid block length")
+	errInvalidSummaryLength = errors.New("invalid state summary length")
+
+	// testVMs are the VMs that the test binary serves when it's run as a
+	// plugin, by the name of the plugin.
+	testVMs = map[string]func() block.ChainVM{
+		conformingVMName: func() block.ChainVM {
+			return &conformingVM{}
+		},
+		stateSyncableVMName: func() block.ChainVM {
+			return &stateSyncableVM{}
+		},
+	}
+)
+
+// TestMain serves one of [testVMs] if the test binary was run as a plugin,
+// rather than running the tests.
+func TestMain(m *testing.M) {
+	if len(os.Getenv(runtime.EngineAddressKey)) == 0 {
+		os.Exit(m.Run())
+	}
+
+	newVM, ok := testVMs[filepath.Base(os.Args[0])]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown test VM %q\n", os.Args[0])
+		os.Exit(1)
+	}
+	if err := rpcchainvm.Serve(context.Background(), newVM()); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to serve test VM: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// newTestPlugin returns the path of a plugin that serves the test VM with the
+// given [name].
+func newTestPlugin(t *testing.T, name string) string {
+	require := require.New(t)
+
+	testBinary, err := os.Executable()
+	require.NoError(err)
+	pluginPath := filepath.Join(t.TempDir(), name)
+	require.NoError(os.Symlink(testBinary, pluginPath))
+	return pluginPath
+}
+
+// conformingVM is an in-memory VM that complies with the contract verified by
+// the checks. A block is the encoding of its parent, height and timestamp, and
+// a block can be built whenever the engine asks for one.
+type conformingVM struct {
+	block.TestVM
+
+	lock      sync.Mutex
+	toEngine  chan<- common.Message
+	blocks    map[ids.ID]*snowman.TestBlock
+	preferred ids.ID
+}
+
+func (vm *conformingVM) Initialize(
+	_ context.Context,
+	_ *snow.Context,
+	_ database.Database,
+	_ []byte,
+	_ []byte,
+	_ []byte,
+	toEngine chan<- common.Message,
+	_ []*common.Fx,
+	_ common.AppSender,
+) error {
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+
+	genesis := newTestBlock(ids.Empty, 0, time.Unix(0, 0))
+	genesis.StatusV = choices.Accepted
+
+	vm.toEngine = toEngine
+	vm.blocks = map[ids.ID]*snowman.TestBlock{
+		genesis.ID(): genesis,
+	}
+	vm.preferred = genesis.ID()
+	return nil
+}
+
+func (*conformingVM) HealthCheck(context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+func (vm *conformingVM) BuildBlock(context.Context) (snowman.Block, error) {
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+
+	parent := vm.blocks[vm.preferred]
+	blk := newTestBlock(parent.ID(), parent.Height()+1, time.Unix(time.Now().Unix(), 0))
+	vm.blocks[blk.ID()] = blk
+	return blk, nil
+}
+
+func (vm *conformingVM) ParseBlock(_ context.Context, blkBytes []byte) (snowman.Block, error) {
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+
+	if len(blkBytes) != testBlockLen {
+		return nil, fmt.Errorf("%w: %d", errInvalidBlockLength, len(blkBytes))
+	}
+	var (
+		parentID  = ids.ID(blkBytes[:ids.IDLen])
+		height    = binary.BigEndian.Uint64(blkBytes[ids.IDLen:])
+		timestamp = time.Unix(int64(binary.BigEndian.Uint64(blkBytes[ids.IDLen+8:])), 0)
+		blk       = newTestBlock(parentID, height, timestamp)
+	)
+	if existing, ok := vm.blocks[blk.ID()]; ok {
+		return existing, nil
+	}
+	vm.blocks[blk.ID()] = blk
+	return blk, nil
+}
+
+func (vm *conformingVM) GetBlock(_ context.Context, blkID ids.ID) (snowman.Block, error) {
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+
+	blk, ok := vm.blocks[blkID]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return blk, nil
+}
+
+// SetPreference signals the engine that a block can be built on [blkID].
+func (vm *conformingVM) SetPreference(_ context.Context, blkID ids.ID) error {
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+
+	vm.preferred = blkID
+	select {
+	case vm.toEngine <- common.PendingTxs:
+	default:
+	}
+	return nil
+}
+
+func (vm *conformingVM) LastAccepted(context.Context) (ids.ID, error) {
+	vm.lock.Lock()
+	defer vm.lock.Unlock()
+
+	var lastAccepted *snowman.TestBlock
+	for _, blk := range vm.blocks {
+		if blk.Status() == choices.Accepted && (lastAccepted == nil || blk.Height() > lastAccepted.Height()) {
+			lastAccepted = blk
+		}
+	}
+	return lastAccepted.ID(), nil
+}
+
+// stateSyncableVM is a [conformingVM] that has a state summary for its
+// genesis.
+type stateSyncableVM struct {
+	conformingVM
+}
+
+func (*stateSyncableVM) StateSyncEnabled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (*stateSyncableVM) GetOngoingSyncStateSummary(context.Context) (block.StateSummary, error) {
+	return nil, database.ErrNotFound
+}
+
+func (*stateSyncableVM) GetLastStateSummary(context.Context) (block.StateSummary, error) {
+	return newTestStateSummary(0), nil
+}
+
+func (*stateSyncableVM) ParseStateSummary(_ context.Context, summaryBytes []byte) (block.StateSummary, error) {
+	if len(summaryBytes) != testStateSummaryLen {
+		return nil, fmt.Errorf("%w: %d", errInvalidSummaryLength, len(summaryBytes))
+	}
+	return newTestStateSummary(binary.BigEndian.Uint64(summaryBytes)), nil
+}
+
+func (*stateSyncableVM) GetStateSummary(_ context.Context, height uint64) (block.StateSummary, error) {
+	if height != 0 {
+		return nil, database.ErrNotFound
+	}
+	return newTestStateSummary(height), nil
+}
+
+func newTestBlock(parentID ids.ID, height uint64, timestamp time.Time) *snowman.TestBlock {
+	blkBytes := make([]byte, testBlockLen)
+	copy(blkBytes, parentID[:])
+	binary.BigEndian.PutUint64(blkBytes[ids.IDLen:], height)
+	binary.BigEndian.PutUint64(blkBytes[ids.IDLen+8:], uint64(timestamp.Unix()))
+	return &snowman.TestBlock{
+		TestDecidable: choices.TestDecidable{
+			IDV:     hashing.ComputeHash256Array(blkBytes),
+			StatusV: choices.Processing,
+		},
+		ParentV:    parentID,
+		HeightV:    height,
+		TimestampV: timestamp,
+		BytesV:     blkBytes,
+	}
+}
+
+func newTestStateSummary(height uint64) *block.TestStateSummary {
+	summaryBytes := binary.BigEndian.AppendUint64(nil, height)
+	return &block.TestStateSummary{
+		IDV:     hashing.ComputeHash256Array(summaryBytes),
+		HeightV: height,
+		BytesV:  summaryBytes,
+	}
+}
@@ -5,7 +5,13 @@ package rpcchainvm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+
+	"go.uber.org/zap"
 
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/resource"
@@ -21,17 +27,77 @@ type factory struct {
 	path           string
 	processTracker resource.ProcessTracker
 	runtimeTracker runtime.Tracker
+	sandbox        *subprocess.Sandbox
+	integrity      *subprocess.Integrity
+	gossipRelay    GossipRelayConfig
 }
 
-func NewFactory(path string, processTracker resource.ProcessTracker, runtimeTracker runtime.Tracker) vms.Factory {
+// NewFactory returns a factory that runs the plugin at [path]. If the plugin
+// has a manifest, it's verified before every launch. If [sandbox] is
+// non-nil, the plugin is run in a sandbox built from the plugin's manifest. If
+// [integrity] is non-nil, the plugin binary is verified before every launch.
+//
+// Gossip is relayed to the VMs it creates as configured by [gossipRelay].
+func NewFactory(
+	path string,
+	processTracker resource.ProcessTracker,
+	runtimeTracker runtime.Tracker,
+	sandbox *subprocess.Sandbox,
+	integrity *subprocess.Integrity,
+	gossipRelay GossipRelayConfig,
+) vms.Factory {
 	return &factory{
 		path:           path,
 		processTracker: processTracker,
 		runtimeTracker: runtimeTracker,
+		sandbox:        sandbox,
+		integrity:      integrity,
+		gossipRelay:    gossipRelay,
 	}
 }
 
 func (f *factory) New(log logging.Logger) (interface{}, error) {
+	status, stopper, err := f.launch(log)
+	if err != nil {
+		return nil, err
+	}
+
+	callMetrics := grpcutils.NewCallMetrics(log, grpcutils.DefaultCallMetricsConfig)
+	clientConn, err := grpcutils.Dial(
+		status.Addr,
+		grpcutils.WithChainUnaryInterceptor(callMetrics.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	vm := NewClient(clientConn)
+	vm.callMetrics = callMetrics
+	vm.gossipRelayConfig = f.gossipRelay
+	vm.SetProcess(stopper, status.Pid, f.processTracker)
+	return vm, nil
+}
+
+// Launches a plugin process and waits for its handshake to complete.
+// The stdout and stderr of the process are written to [log].
+func (f *factory) launch(log logging.Logger) (*subprocess.Status, runtime.Stopper, error) {
+	if f.integrity != nil {
+		if err := f.integrity.Verify(f.path); err != nil {
+			return nil, nil, fmt.Errorf("failed to verify plugin %q: %w", f.path, err)
+		}
+	}
+
+	manifest, err := f.readManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest != nil {
+		log.Debug("loaded plugin manifest",
+			zap.String("path", f.path),
+			zap.Uints("protocolVersions", manifest.ProtocolVersions),
+		)
+	}
+
 	config := &subprocess.Config{
 		Stderr:           log,
 		Stdout:           log,
@@ -39,30 +105,75 @@ func (f *factory) New(log logging.Logger) (interface{}, error) {
 		Log:              log,
 	}
 
+	// AppArmor denies operations with EACCES rather than killing the plugin,
+	// so a plugin that is denied access typically fails its handshake without
+	// saying why.
+	var denials *subprocess.DenialWriter
+	if f.sandboxed() && f.sandbox.Mode == subprocess.SandboxAppArmor {
+		denials = &subprocess.DenialWriter{
+			Writer: log,
+		}
+		config.Stderr = denials
+		config.Stdout = denials
+	}
+
+	cmd, err := f.newCmd(config, manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	listener, err := grpcutils.NewListener()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create listener: %w", err)
+		return nil, nil, fmt.Errorf("failed to create listener: %w", err)
 	}
 
 	status, stopper, err := subprocess.Bootstrap(
 		context.TODO(),
 		listener,
-		subprocess.NewCmd(f.path),
+		cmd,
 		config,
 	)
 	if err != nil {
-		return nil, err
+		if denials != nil {
+			if denialErr := denials.Err(); denialErr != nil {
+				return nil, nil, fmt.Errorf("failed to launch plugin %q: %w: %w", f.path, denialErr, err)
+			}
+		}
+		return nil, nil, err
 	}
 
-	clientConn, err := grpcutils.Dial(status.Addr)
-	if err != nil {
-		return nil, err
+	f.runtimeTracker.TrackRuntime(stopper)
+	return status, stopper, nil
+}
+
+// Returns the manifest of the plugin, so that a plugin that doesn't match this
+// node fails before it's launched. The manifest is only required if the plugin
+// is sandboxed, otherwise nil is returned if the plugin doesn't have one.
+func (f *factory) readManifest() (*subprocess.Manifest, error) {
+	manifest, err := subprocess.ReadManifest(f.path)
+	if errors.Is(err, fs.ErrNotExist) && !f.sandboxed() {
+		return nil, nil
 	}
+	return manifest, err
+}
 
-	vm := NewClient(clientConn)
-	vm.SetProcess(stopper, status.Pid, f.processTracker)
+func (f *factory) sandboxed() bool {
+	return f.sandbox != nil && f.sandbox.Mode != subprocess.SandboxNone
+}
 
-	f.runtimeTracker.TrackRuntime(stopper)
+func (f *factory) newCmd(config *subprocess.Config, manifest *subprocess.Manifest) (*exec.Cmd, error) {
+	if !f.sandboxed() {
+		return subprocess.NewCmd(f.path), nil
+	}
 
-	return vm, nil
+	cmd, err := subprocess.NewSandboxedCmd(f.sandbox.Mode, manifest, f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sandbox plugin: %w", err)
+	}
+	if f.sandbox.Monitor != nil {
+		config.OnExit = func(state *os.ProcessState) {
+			f.sandbox.Monitor.HandleExit(f.path, state)
+		}
+	}
+	return cmd, nil
 }
@@ -0,0 +1,309 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/buffer"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	// GossipPolicyBlock doesn't relay gossip. The VM client sends gossip to
+	// the plugin while it's received, so receiving gossip waits until the
+	// plugin has handled it. This is the default.
+	GossipPolicyBlock GossipPolicy = "block"
+	// GossipPolicyDropNewest drops gossip that is received while the maximum
+	// amount of gossip is pending.
+	GossipPolicyDropNewest GossipPolicy = "drop-newest"
+	// GossipPolicyDropOldest drops the gossip that has been pending the
+	// longest to make room for gossip that is received while the maximum
+	// amount of gossip is pending.
+	GossipPolicyDropOldest GossipPolicy = "drop-oldest"
+	// GossipPolicyCoalesce drops gossip that is identical to pending gossip,
+	// and otherwise behaves like [GossipPolicyDropOldest].
+	GossipPolicyCoalesce GossipPolicy = "coalesce"
+)
+
+var (
+	DefaultGossipRelayConfig = GossipRelayConfig{
+		Policy:             GossipPolicyBlock,
+		MaxPendingMessages: 4096,
+		MaxPendingBytes:    64 * units.MiB,
+	}
+
+	errUnknownGossipPolicy    = errors.New("unknown gossip policy")
+	errInvalidMaxPendingLimit = errors.New("max pending gossip must be positive")
+)
+
+// GossipPolicy determines what happens to gossip that is received faster than
+// the plugin handles it.
+//
+// Every policy other than [GossipPolicyBlock] is opt-in and changes when the
+// plugin sees gossip:
+//   - AppGossip returns before the plugin has handled the gossip, so the
+//     plugin may handle gossip concurrently with, and after, other calls that
+//     were made after AppGossip returned, such as AppRequest or BuildBlock.
+//   - Gossip is sent to the plugin from a single goroutine, one message at a
+//     time, in the order it was received. Shed gossip is never sent.
+//   - An error returned by the plugin for a message is returned by a later
+//     AppGossip rather than by the AppGossip that received the message.
+//   - Gossip that is pending when the VM is shut down is dropped.
+type GossipPolicy string
+
+func ParseGossipPolicy(s string) (GossipPolicy, error) {
+	switch policy := GossipPolicy(s); policy {
+	case GossipPolicyBlock, GossipPolicyDropNewest, GossipPolicyDropOldest, GossipPolicyCoalesce:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %q", errUnknownGossipPolicy, s)
+	}
+}
+
+// GossipRelayConfig configures how gossip is relayed to a plugin.
+type GossipRelayConfig struct {
+	Policy GossipPolicy `json:"policy"`
+	// The maximum number of messages that may be waiting to be sent to the
+	// plugin. Ignored if [Policy] is [GossipPolicyBlock].
+	MaxPendingMessages int `json:"maxPendingMessages"`
+	// The maximum total size of the messages that may be waiting to be sent
+	// to the plugin. Ignored if [Policy] is [GossipPolicyBlock].
+	MaxPendingBytes int `json:"maxPendingBytes"`
+}
+
+func (c GossipRelayConfig) Verify() error {
+	if _, err := ParseGossipPolicy(string(c.Policy)); err != nil {
+		return err
+	}
+	if c.Policy == GossipPolicyBlock {
+		return nil
+	}
+	if c.MaxPendingMessages <= 0 || c.MaxPendingBytes <= 0 {
+		return fmt.Errorf("%w: %d messages, %d bytes",
+			errInvalidMaxPendingLimit,
+			c.MaxPendingMessages,
+			c.MaxPendingBytes,
+		)
+	}
+	return nil
+}
+
+type pendingGossip struct {
+	nodeID ids.NodeID
+	msg    []byte
+	// Only set if the policy is [GossipPolicyCoalesce]
+	hash ids.ID
+}
+
+// gossipRelay sends gossip to the plugin from a single goroutine, so that
+// receiving gossip doesn't wait for the plugin to handle it. Gossip that is
+// received while the maximum amount of gossip is pending is shed according to
+// the configured policy, rather than queued.
+//
+// The relay isn't used if the policy is [GossipPolicyBlock], and treats it
+// like [GossipPolicyDropOldest] if it's configured with it.
+type gossipRelay struct {
+	log    logging.Logger
+	config GossipRelayConfig
+	send   func(ctx context.Context, nodeID ids.NodeID, msg []byte) error
+
+	// Cancelled when the relay is closed to abort the message being sent.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Closed once the goroutine sending gossip has exited.
+	done chan struct{}
+
+	lock sync.Mutex
+	// Signalled when gossip is pending or the relay is closed.
+	cond         *sync.Cond
+	pending      buffer.Deque[*pendingGossip]
+	pendingBytes int
+	// The number of pending messages with each hash.
+	pendingHashes map[ids.ID]int
+	closed        bool
+	// The first error returned by the plugin. Returned by the next call to
+	// Relay, as the error would have been returned by AppGossip if it
+	// weren't relayed.
+	err error
+
+	numPendingMessages prometheus.Gauge
+	numPendingBytes    prometheus.Gauge
+	numDropped         prometheus.Counter
+	numCoalesced       prometheus.Counter
+}
+
+// newGossipRelay starts relaying gossip with [send]. The relay must be closed
+// once it's no longer used.
+func newGossipRelay(
+	log logging.Logger,
+	config GossipRelayConfig,
+	registerer prometheus.Registerer,
+	send func(ctx context.Context, nodeID ids.NodeID, msg []byte) error,
+) (*gossipRelay, error) {
+	r := &gossipRelay{
+		log:           log,
+		config:        config,
+		send:          send,
+		done:          make(chan struct{}),
+		pending:       buffer.NewUnboundedDeque[*pendingGossip](0),
+		pendingHashes: make(map[ids.ID]int),
+		numPendingMessages: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gossip_relay_pending_messages",
+			Help: "number of gossip messages waiting to be sent to the plugin",
+		}),
+		numPendingBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gossip_relay_pending_bytes",
+			Help: "size of the gossip messages waiting to be sent to the plugin in bytes",
+		}),
+		numDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gossip_relay_dropped",
+			Help: "number of gossip messages dropped because the plugin fell behind",
+		}),
+		numCoalesced: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gossip_relay_coalesced",
+			Help: "number of gossip messages dropped because an identical message was pending",
+		}),
+	}
+	r.cond = sync.NewCond(&r.lock)
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(r.numPendingMessages),
+		registerer.Register(r.numPendingBytes),
+		registerer.Register(r.numDropped),
+		registerer.Register(r.numCoalesced),
+	)
+	if errs.Errored() {
+		return nil, errs.Err
+	}
+
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	go r.run()
+	return r, nil
+}
+
+// Relay queues [msg] to be sent to the plugin, unless it's shed.
+// Returns the error returned by the plugin when a previous message was sent,
+// if any.
+func (r *gossipRelay) Relay(nodeID ids.NodeID, msg []byte) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err != nil {
+		return fmt.Errorf("failed to relay gossip: %w", r.err)
+	}
+	if r.closed {
+		return nil
+	}
+
+	gossip := &pendingGossip{
+		nodeID: nodeID,
+		msg:    msg,
+	}
+	if r.config.Policy == GossipPolicyCoalesce {
+		gossip.hash = hashing.ComputeHash256Array(msg)
+		if r.pendingHashes[gossip.hash] > 0 {
+			r.numCoalesced.Inc()
+			return nil
+		}
+	}
+	if len(msg) > r.config.MaxPendingBytes {
+		// The message can never be pending.
+		r.drop(nodeID, msg)
+		return nil
+	}
+
+	for r.pending.Len() >= r.config.MaxPendingMessages || r.pendingBytes+len(msg) > r.config.MaxPendingBytes {
+		if r.config.Policy == GossipPolicyDropNewest {
+			r.drop(nodeID, msg)
+			return nil
+		}
+		oldest := r.pop()
+		r.drop(oldest.nodeID, oldest.msg)
+	}
+
+	r.pending.PushRight(gossip)
+	r.pendingBytes += len(msg)
+	if r.config.Policy == GossipPolicyCoalesce {
+		r.pendingHashes[gossip.hash]++
+	}
+	r.numPendingMessages.Set(float64(r.pending.Len()))
+	r.numPendingBytes.Set(float64(r.pendingBytes))
+	r.cond.Signal()
+	return nil
+}
+
+// Close stops relaying gossip. Pending gossip is dropped.
+func (r *gossipRelay) Close() {
+	r.lock.Lock()
+	r.closed = true
+	r.cond.Broadcast()
+	r.lock.Unlock()
+
+	r.cancel()
+	<-r.done
+}
+
+func (r *gossipRelay) run() {
+	defer close(r.done)
+
+	for {
+		r.lock.Lock()
+		for r.pending.Len() == 0 && !r.closed {
+			r.cond.Wait()
+		}
+		if r.closed {
+			r.lock.Unlock()
+			return
+		}
+		gossip := r.pop()
+		r.lock.Unlock()
+
+		if err := r.send(r.ctx, gossip.nodeID, gossip.msg); err != nil && r.ctx.Err() == nil {
+			r.lock.Lock()
+			if r.err == nil {
+				r.err = err
+			}
+			r.lock.Unlock()
+		}
+	}
+}
+
+// Removes and returns the gossip that has been pending the longest.
+//
+// Assumes [r.lock] is held and that gossip is pending.
+func (r *gossipRelay) pop() *pendingGossip {
+	gossip, _ := r.pending.PopLeft()
+	r.pendingBytes -= len(gossip.msg)
+	if r.config.Policy == GossipPolicyCoalesce {
+		r.pendingHashes[gossip.hash]--
+		if r.pendingHashes[gossip.hash] == 0 {
+			delete(r.pendingHashes, gossip.hash)
+		}
+	}
+	r.numPendingMessages.Set(float64(r.pending.Len()))
+	r.numPendingBytes.Set(float64(r.pendingBytes))
+	return gossip
+}
+
+func (r *gossipRelay) drop(nodeID ids.NodeID, msg []byte) {
+	r.numDropped.Inc()
+	r.log.Debug("dropping gossip because the plugin fell behind",
+		zap.Stringer("nodeID", nodeID),
+		zap.Int("messageLen", len(msg)),
+		zap.String("policy", string(r.config.Policy)),
+	)
+}
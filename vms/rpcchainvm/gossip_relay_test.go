@@ -0,0 +1,219 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+)
+
+var errTestGossip = errors.New("non-nil error")
+
+// blockingSender sends gossip to [sent] once [release] is closed. [started]
+// receives each message as it starts being sent.
+type blockingSender struct {
+	started chan []byte
+	release chan struct{}
+	sent    chan []byte
+	err     error
+}
+
+func newBlockingSender() *blockingSender {
+	return &blockingSender{
+		started: make(chan []byte, 16),
+		release: make(chan struct{}),
+		sent:    make(chan []byte, 16),
+	}
+}
+
+func (s *blockingSender) send(ctx context.Context, _ ids.NodeID, msg []byte) error {
+	s.started <- msg
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.sent <- msg
+	return s.err
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	metric := &dto.Metric{}
+	require.NoError(t, counter.Write(metric))
+	return metric.GetCounter().GetValue()
+}
+
+func TestGossipRelayPolicies(t *testing.T) {
+	tests := []struct {
+		policy            GossipPolicy
+		expectedSent      []string
+		expectedCoalesced float64
+	}{
+		{
+			policy:       GossipPolicyDropNewest,
+			expectedSent: []string{"0", "1", "2"},
+		},
+		{
+			policy:       GossipPolicyDropOldest,
+			expectedSent: []string{"0", "2", "3"},
+		},
+		{
+			policy:            GossipPolicyCoalesce,
+			expectedSent:      []string{"0", "2", "3"},
+			expectedCoalesced: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(string(test.policy), func(t *testing.T) {
+			require := require.New(t)
+
+			sender := newBlockingSender()
+			relay, err := newGossipRelay(
+				logging.NoLog{},
+				GossipRelayConfig{
+					Policy:             test.policy,
+					MaxPendingMessages: 2,
+					MaxPendingBytes:    1024,
+				},
+				prometheus.NewRegistry(),
+				sender.send,
+			)
+			require.NoError(err)
+			defer relay.Close()
+
+			// Wait for the first message to be in flight, so that the others
+			// are pending.
+			require.NoError(relay.Relay(ids.EmptyNodeID, []byte("0")))
+			require.Equal([]byte("0"), <-sender.started)
+
+			require.NoError(relay.Relay(ids.EmptyNodeID, []byte("1")))
+			require.NoError(relay.Relay(ids.EmptyNodeID, []byte("2")))
+			if test.policy == GossipPolicyCoalesce {
+				require.NoError(relay.Relay(ids.EmptyNodeID, []byte("2")))
+			}
+			require.NoError(relay.Relay(ids.EmptyNodeID, []byte("3")))
+
+			require.Equal(float64(1), counterValue(t, relay.numDropped))
+			require.Equal(test.expectedCoalesced, counterValue(t, relay.numCoalesced))
+
+			close(sender.release)
+			for _, expected := range test.expectedSent {
+				require.Equal([]byte(expected), <-sender.sent)
+			}
+		})
+	}
+}
+
+func TestGossipRelayMaxPendingBytes(t *testing.T) {
+	require := require.New(t)
+
+	sender := newBlockingSender()
+	relay, err := newGossipRelay(
+		logging.NoLog{},
+		GossipRelayConfig{
+			Policy:             GossipPolicyDropOldest,
+			MaxPendingMessages: 16,
+			MaxPendingBytes:    4,
+		},
+		prometheus.NewRegistry(),
+		sender.send,
+	)
+	require.NoError(err)
+	defer relay.Close()
+
+	require.NoError(relay.Relay(ids.EmptyNodeID, []byte("0")))
+	require.Equal([]byte("0"), <-sender.started)
+
+	// A message that can never be pending is dropped.
+	require.NoError(relay.Relay(ids.EmptyNodeID, []byte("12345")))
+	require.Equal(float64(1), counterValue(t, relay.numDropped))
+
+	// Pending messages are dropped to make room for the new message.
+	require.NoError(relay.Relay(ids.EmptyNodeID, []byte("12")))
+	require.NoError(relay.Relay(ids.EmptyNodeID, []byte("345")))
+	require.Equal(float64(2), counterValue(t, relay.numDropped))
+
+	close(sender.release)
+	require.Equal([]byte("0"), <-sender.sent)
+	require.Equal([]byte("345"), <-sender.sent)
+}
+
+func TestGossipRelayReturnsSendError(t *testing.T) {
+	require := require.New(t)
+
+	sender := newBlockingSender()
+	sender.err = errTestGossip
+	close(sender.release)
+	config := DefaultGossipRelayConfig
+	config.Policy = GossipPolicyDropOldest
+	relay, err := newGossipRelay(
+		logging.NoLog{},
+		config,
+		prometheus.NewRegistry(),
+		sender.send,
+	)
+	require.NoError(err)
+	defer relay.Close()
+
+	require.NoError(relay.Relay(ids.EmptyNodeID, []byte("0")))
+	<-sender.sent
+
+	// The error is recorded after the message is sent.
+	require.Eventually(func() bool {
+		return errors.Is(relay.Relay(ids.EmptyNodeID, []byte("1")), errTestGossip)
+	}, time.Second, time.Millisecond)
+}
+
+func TestGossipRelayConfigVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      GossipRelayConfig
+		expectedErr error
+	}{
+		{
+			name:   "default",
+			config: DefaultGossipRelayConfig,
+		},
+		{
+			name: "block ignores limits",
+			config: GossipRelayConfig{
+				Policy: GossipPolicyBlock,
+			},
+		},
+		{
+			name: "unknown policy",
+			config: GossipRelayConfig{
+				Policy:             "drop-everything",
+				MaxPendingMessages: 1,
+				MaxPendingBytes:    1,
+			},
+			expectedErr: errUnknownGossipPolicy,
+		},
+		{
+			name: "no pending messages",
+			config: GossipRelayConfig{
+				Policy:          GossipPolicyDropNewest,
+				MaxPendingBytes: 1,
+			},
+			expectedErr: errInvalidMaxPendingLimit,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Verify()
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
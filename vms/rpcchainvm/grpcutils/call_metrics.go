@@ -0,0 +1,221 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package grpcutils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.uber.org/zap"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+const (
+	// CorrelationIDKey is the gRPC metadata key used to propagate the
+	// correlation ID of a call between the node and a plugin.
+	CorrelationIDKey = "x-avalanche-correlation-id"
+
+	correlationIDLen = 8
+	methodLabel      = "method"
+)
+
+var (
+	_ prometheus.Collector = (*CallMetrics)(nil)
+
+	DefaultCallMetricsConfig = CallMetricsConfig{
+		SlowCallThreshold:     time.Second,
+		LargeMessageThreshold: units.MiB,
+	}
+)
+
+type correlationIDContextKey struct{}
+
+type CallMetricsConfig struct {
+	// Unary calls that take at least this long are logged.
+	SlowCallThreshold time.Duration
+	// Unary calls whose request or response is at least this many bytes are
+	// logged.
+	LargeMessageThreshold int
+}
+
+// CallMetrics records the size of the payloads of unary calls per method and
+// logs calls that exceed the configured thresholds.
+//
+// Every call is tagged with a correlation ID that is propagated through the
+// gRPC metadata, so the log lines emitted by the node and by the plugin for the
+// same call can be matched.
+type CallMetrics struct {
+	log    logging.Logger
+	config CallMetricsConfig
+
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+// NewCallMetrics returns a CallMetrics that is not registered. The caller is
+// expected to register it with a prometheus.Registerer.
+func NewCallMetrics(log logging.Logger, config CallMetricsConfig) *CallMetrics {
+	return &CallMetrics{
+		log:    log,
+		config: config,
+		requestSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_request_size_bytes",
+				Help:    "size of the unary gRPC requests in bytes",
+				Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+			},
+			[]string{methodLabel},
+		),
+		responseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_response_size_bytes",
+				Help:    "size of the unary gRPC responses in bytes",
+				Buckets: prometheus.ExponentialBuckets(64, 4, 10),
+			},
+			[]string{methodLabel},
+		),
+	}
+}
+
+func (m *CallMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requestSize.Describe(ch)
+	m.responseSize.Describe(ch)
+}
+
+func (m *CallMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requestSize.Collect(ch)
+	m.responseSize.Collect(ch)
+}
+
+// UnaryClientInterceptor returns an interceptor that attaches a correlation ID
+// to the outgoing call and observes it.
+//
+// If [ctx] is already associated with a correlation ID, either because it was
+// passed into a server handler or because it was set with [WithCorrelationID],
+// that ID is reused.
+func (m *CallMetrics) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		correlationID, ok := CorrelationID(ctx)
+		if !ok {
+			correlationID = newCorrelationID()
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, CorrelationIDKey, correlationID)
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe("client", method, correlationID, time.Since(start), req, reply, err)
+		return err
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor that observes incoming calls.
+// The correlation ID provided by the caller is made available to the handler
+// through [CorrelationID].
+func (m *CallMetrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		correlationID, ok := CorrelationID(ctx)
+		if !ok {
+			correlationID = newCorrelationID()
+		}
+		ctx = WithCorrelationID(ctx, correlationID)
+
+		start := time.Now()
+		reply, err := handler(ctx, req)
+		m.observe("server", info.FullMethod, correlationID, time.Since(start), req, reply, err)
+		return reply, err
+	}
+}
+
+func (m *CallMetrics) observe(
+	side string,
+	method string,
+	correlationID string,
+	duration time.Duration,
+	req interface{},
+	reply interface{},
+	err error,
+) {
+	requestSize := messageSize(req)
+	m.requestSize.WithLabelValues(method).Observe(float64(requestSize))
+
+	var responseSize int
+	if err == nil {
+		responseSize = messageSize(reply)
+		m.responseSize.WithLabelValues(method).Observe(float64(responseSize))
+	}
+
+	if duration < m.config.SlowCallThreshold &&
+		requestSize < m.config.LargeMessageThreshold &&
+		responseSize < m.config.LargeMessageThreshold {
+		return
+	}
+
+	m.log.Warn("expensive gRPC call",
+		zap.String("side", side),
+		zap.String("method", method),
+		zap.String("correlationID", correlationID),
+		zap.Duration("duration", duration),
+		zap.Int("requestSize", requestSize),
+		zap.Int("responseSize", responseSize),
+		zap.Error(err),
+	)
+}
+
+// WithCorrelationID returns a context that causes calls made with it through a
+// [CallMetrics] interceptor to be tagged with [correlationID].
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, correlationID)
+}
+
+// CorrelationID returns the correlation ID associated with [ctx], if any.
+func CorrelationID(ctx context.Context) (string, bool) {
+	if correlationID, ok := ctx.Value(correlationIDContextKey{}).(string); ok {
+		return correlationID, true
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(CorrelationIDKey)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+func newCorrelationID() string {
+	b := make([]byte, correlationIDLen)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func messageSize(msg interface{}) int {
+	protoMsg, ok := msg.(proto.Message)
+	if !ok {
+		return 0
+	}
+	return proto.Size(protoMsg)
+}
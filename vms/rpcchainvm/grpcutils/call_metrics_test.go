@@ -0,0 +1,84 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package grpcutils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"google.golang.org/grpc"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/rpcdb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+
+	pb "github.com/ava-labs/avalanchego/proto/pb/rpcdb"
+)
+
+func TestCallMetricsCorrelationID(t *testing.T) {
+	require := require.New(t)
+
+	serverMetrics := NewCallMetrics(logging.NoLog{}, DefaultCallMetricsConfig)
+	clientMetrics := NewCallMetrics(logging.NoLog{}, DefaultCallMetricsConfig)
+
+	registry := prometheus.NewRegistry()
+	require.NoError(registry.Register(serverMetrics))
+
+	var seenCorrelationID string
+	server := NewServer(
+		WithUnaryInterceptor(serverMetrics.UnaryServerInterceptor()),
+		WithUnaryInterceptor(func(
+			ctx context.Context,
+			req interface{},
+			_ *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			seenCorrelationID, _ = CorrelationID(ctx)
+			return handler(ctx, req)
+		}),
+	)
+	defer server.Stop()
+	pb.RegisterDatabaseServer(server, rpcdb.NewServer(memdb.New()))
+
+	listener, err := NewListener()
+	require.NoError(err)
+	go Serve(listener, server)
+
+	conn, err := Dial(
+		listener.Addr().String(),
+		WithChainUnaryInterceptor(clientMetrics.UnaryClientInterceptor()),
+	)
+	require.NoError(err)
+	defer conn.Close()
+
+	client := pb.NewDatabaseClient(conn)
+
+	// A correlation ID is generated if one isn't provided.
+	_, err = client.Put(context.Background(), &pb.PutRequest{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	})
+	require.NoError(err)
+	require.NotEmpty(seenCorrelationID)
+
+	// A provided correlation ID is propagated to the server.
+	ctx := WithCorrelationID(context.Background(), "correlation")
+	_, err = client.Get(ctx, &pb.GetRequest{
+		Key: []byte("foo"),
+	})
+	require.NoError(err)
+	require.Equal("correlation", seenCorrelationID)
+
+	metricFamilies, err := registry.Gather()
+	require.NoError(err)
+	require.Len(metricFamilies, 2)
+	for _, metricFamily := range metricFamilies {
+		// One series per method
+		require.Len(metricFamily.Metric, 2)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package grpcutils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+// InFlightTracker tracks the unary calls, per method, that are being handled
+// by a server so that they can be drained before the server is stopped.
+type InFlightTracker struct {
+	lock     sync.Mutex
+	inFlight map[string]int
+	// Closed and replaced whenever a call completes.
+	completed chan struct{}
+}
+
+func NewInFlightTracker() *InFlightTracker {
+	return &InFlightTracker{
+		inFlight:  make(map[string]int),
+		completed: make(chan struct{}),
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor that tracks incoming calls
+// while they are being handled.
+func (t *InFlightTracker) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		t.start(info.FullMethod)
+		defer t.finish(info.FullMethod)
+
+		return handler(ctx, req)
+	}
+}
+
+// InFlight returns the number of calls, per method, that are currently being
+// handled. Methods without calls in flight are omitted.
+func (t *InFlightTracker) InFlight() map[string]int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.inFlightExcept(nil)
+}
+
+// Drain waits, for at most [timeout], until no calls are in flight other
+// than calls to [ignoredMethods]. Calls to [ignoredMethods] are typically the
+// calls that initiated the drain.
+//
+// Returns the number of calls, per method, that were still in flight when
+// [timeout] elapsed. If every call completed, the returned map is empty.
+func (t *InFlightTracker) Drain(timeout time.Duration, ignoredMethods ...string) map[string]int {
+	ignored := set.Of(ignoredMethods...)
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		t.lock.Lock()
+		inFlight := t.inFlightExcept(ignored)
+		completed := t.completed
+		t.lock.Unlock()
+
+		if len(inFlight) == 0 {
+			return inFlight
+		}
+
+		select {
+		case <-completed:
+		case <-deadline.C:
+			return inFlight
+		}
+	}
+}
+
+func (t *InFlightTracker) start(method string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.inFlight[method]++
+}
+
+func (t *InFlightTracker) finish(method string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.inFlight[method]--
+	if t.inFlight[method] == 0 {
+		delete(t.inFlight, method)
+	}
+	close(t.completed)
+	t.completed = make(chan struct{})
+}
+
+// Assumes [t.lock] is held.
+func (t *InFlightTracker) inFlightExcept(ignored set.Set[string]) map[string]int {
+	inFlight := make(map[string]int, len(t.inFlight))
+	for method, count := range t.inFlight {
+		if !ignored.Contains(method) {
+			inFlight[method] = count
+		}
+	}
+	return inFlight
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package grpcutils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlightTrackerDrain(t *testing.T) {
+	require := require.New(t)
+
+	const method = "/test.Service/Method"
+	var (
+		tracker     = NewInFlightTracker()
+		interceptor = tracker.UnaryServerInterceptor()
+		release     = make(chan struct{})
+		done        = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		_, _ = interceptor(
+			context.Background(),
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: method},
+			func(context.Context, interface{}) (interface{}, error) {
+				<-release
+				return nil, nil
+			},
+		)
+	}()
+
+	require.Eventually(func() bool {
+		return tracker.InFlight()[method] == 1
+	}, time.Second, time.Millisecond)
+
+	// The call doesn't complete within the drain period.
+	require.Equal(map[string]int{method: 1}, tracker.Drain(time.Millisecond))
+
+	// Ignored methods aren't waited on.
+	require.Empty(tracker.Drain(time.Hour, method))
+
+	close(release)
+	require.Empty(tracker.Drain(time.Hour))
+	<-done
+	require.Empty(tracker.InFlight())
+}
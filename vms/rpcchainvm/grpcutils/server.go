@@ -71,10 +71,11 @@ func (s *ServerOptions) applyOpts(opts []ServerOption) {
 type ServerOption func(*ServerOptions)
 
 // WithUnaryInterceptor adds a single unary interceptor to the gRPC server
-// options.
+// options. If provided multiple times, the interceptors are chained in the
+// order they were provided.
 func WithUnaryInterceptor(unaryInterceptor grpc.UnaryServerInterceptor) ServerOption {
 	return func(s *ServerOptions) {
-		s.opts = append(s.opts, grpc.UnaryInterceptor(unaryInterceptor))
+		s.opts = append(s.opts, grpc.ChainUnaryInterceptor(unaryInterceptor))
 	}
 }
 
@@ -13,11 +13,27 @@ const (
 	// Address of the runtime engine server.
 	EngineAddressKey = "AVALANCHE_VM_RUNTIME_ENGINE_ADDR"
 
+	// RPCChainVM protocol version implemented by the runtime engine server.
+	EngineProtocolVersionKey = "AVALANCHE_VM_RUNTIME_ENGINE_PROTOCOL_VERSION"
+
+	// Maximum duration, parsable by time.ParseDuration, that a VM waits for
+	// in-flight requests to complete when shutting down.
+	DrainPeriodKey = "AVALANCHE_VM_DRAIN_PERIOD"
+
 	// Duration before handshake timeout during bootstrap.
 	DefaultHandshakeTimeout = 5 * time.Second
 
 	// Duration of time to wait for graceful termination to complete.
 	DefaultGracefulTimeout = 5 * time.Second
+
+	// Duration of [DefaultGracefulTimeout] reserved for the VM to stop its
+	// server and exit once in-flight requests have been drained.
+	DefaultGracefulStopMargin = time.Second
+
+	// Duration of time a VM waits for in-flight requests to complete when
+	// shutting down. This is also the longest drain period a VM accepts, so
+	// that the VM stops before it is killed.
+	DefaultDrainPeriod = DefaultGracefulTimeout - DefaultGracefulStopMargin
 )
 
 var (
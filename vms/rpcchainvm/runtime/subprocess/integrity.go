@@ -0,0 +1,189 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subprocess
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// SignatureExtension is appended to the path of a plugin to get the path of
+// its signature.
+const SignatureExtension = ".sig"
+
+var (
+	errInvalidDigest         = errors.New("invalid plugin digest")
+	errInvalidPublicKey      = errors.New("invalid plugin signing key")
+	errDigestMismatch        = errors.New("plugin digest mismatch")
+	errInvalidSignature      = errors.New("invalid plugin signature")
+	errUnverifiedPlugin      = errors.New("plugin has neither a configured digest nor a signing key to verify it with")
+	errIntegrityFailures     = errors.New("plugins failed integrity verification")
+	errUnsupportedPublicKey  = errors.New("plugin signing key must be an ECDSA key")
+	errMissingPublicKeyBlock = errors.New("plugin signing key isn't PEM encoded")
+)
+
+// Integrity describes how plugin binaries are verified before they are
+// launched.
+//
+// A plugin whose file name has a configured digest may only be launched if
+// its binary has that digest. Otherwise, if a signing key is configured, the
+// plugin may only be launched if the file next to its binary with the
+// extension [SignatureExtension] holds a base64 encoded ASN.1 ECDSA signature
+// of the SHA-256 digest of its binary, as produced by `cosign sign-blob`.
+type Integrity struct {
+	// Hex encoded SHA-256 digests of plugin binaries, by file name.
+	Digests map[string]string `json:"digests"`
+	// May be nil.
+	PublicKey *ecdsa.PublicKey `json:"-"`
+	// Records the plugins that failed verification. May be nil.
+	Monitor *IntegrityMonitor `json:"-"`
+}
+
+// NewIntegrity returns the plugin verification described by [digests], which
+// maps plugin file names to hex encoded SHA-256 digests, and the PEM encoded
+// [publicKey]. Returns nil if neither is provided.
+func NewIntegrity(digests map[string]string, publicKey []byte) (*Integrity, error) {
+	if len(digests) == 0 && len(publicKey) == 0 {
+		return nil, nil
+	}
+
+	i := &Integrity{
+		Digests: make(map[string]string, len(digests)),
+	}
+	for name, digest := range digests {
+		digest = strings.ToLower(strings.TrimPrefix(digest, "sha256:"))
+		digestBytes, err := hex.DecodeString(digest)
+		if err != nil {
+			return nil, fmt.Errorf("%w for %q: %w", errInvalidDigest, name, err)
+		}
+		if len(digestBytes) != sha256.Size {
+			return nil, fmt.Errorf("%w for %q: expected %d bytes but got %d", errInvalidDigest, name, sha256.Size, len(digestBytes))
+		}
+		i.Digests[name] = digest
+	}
+
+	if len(publicKey) != 0 {
+		block, _ := pem.Decode(publicKey)
+		if block == nil {
+			return nil, errMissingPublicKeyBlock
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidPublicKey, err)
+		}
+		ecdsaKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: got %T", errUnsupportedPublicKey, key)
+		}
+		i.PublicKey = ecdsaKey
+	}
+	return i, nil
+}
+
+// Verify returns nil iff the plugin at [path] may be launched. The outcome is
+// recorded by [i.Monitor], if any.
+func (i *Integrity) Verify(path string) error {
+	err := i.verify(path)
+	if i.Monitor != nil {
+		i.Monitor.record(path, err)
+	}
+	return err
+}
+
+func (i *Integrity) verify(path string) error {
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read plugin: %w", err)
+	}
+	digest := sha256.Sum256(binary)
+
+	if expected, ok := i.Digests[filepath.Base(path)]; ok {
+		if actual := hex.EncodeToString(digest[:]); actual != expected {
+			return fmt.Errorf("%w: expected %s but got %s", errDigestMismatch, expected, actual)
+		}
+		return nil
+	}
+
+	if i.PublicKey == nil {
+		return errUnverifiedPlugin
+	}
+
+	encodedSignature, err := os.ReadFile(path + SignatureExtension)
+	if err != nil {
+		return fmt.Errorf("failed to read plugin signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encodedSignature)))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalidSignature, err)
+	}
+	if !ecdsa.VerifyASN1(i.PublicKey, digest[:], signature) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// IntegrityFailure is a plugin that most recently failed verification.
+type IntegrityFailure struct {
+	Path      string    `json:"path"`
+	Error     string    `json:"error"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// IntegrityMonitor reports the plugins whose most recent verification failed
+// through the health API.
+type IntegrityMonitor struct {
+	lock sync.Mutex
+	// Plugin path -> most recent verification failure
+	failures map[string]IntegrityFailure
+}
+
+func NewIntegrityMonitor() *IntegrityMonitor {
+	return &IntegrityMonitor{
+		failures: make(map[string]IntegrityFailure),
+	}
+}
+
+func (m *IntegrityMonitor) record(path string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err == nil {
+		delete(m.failures, path)
+		return
+	}
+	m.failures[path] = IntegrityFailure{
+		Path:      path,
+		Error:     err.Error(),
+		Timestamp: time.Now(),
+	}
+}
+
+// HealthCheck is unhealthy while the most recent verification of any plugin
+// failed.
+func (m *IntegrityMonitor) HealthCheck(context.Context) (interface{}, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	details := map[string]interface{}{
+		"failures": maps.Values(m.failures),
+	}
+	if len(m.failures) > 0 {
+		return details, fmt.Errorf("%w: %d plugins", errIntegrityFailures, len(m.failures))
+	}
+	return details, nil
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subprocess
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIntegrity(t *testing.T) {
+	require := require.New(t)
+
+	integrity, err := NewIntegrity(nil, nil)
+	require.NoError(err)
+	require.Nil(integrity)
+
+	_, err = NewIntegrity(map[string]string{"plugin": "not hex"}, nil)
+	require.ErrorIs(err, errInvalidDigest)
+
+	_, err = NewIntegrity(map[string]string{"plugin": "abcd"}, nil)
+	require.ErrorIs(err, errInvalidDigest)
+
+	_, err = NewIntegrity(nil, []byte("not pem"))
+	require.ErrorIs(err, errMissingPublicKeyBlock)
+}
+
+func TestIntegrityVerify(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "plugin")
+	binary := []byte("plugin binary")
+	require.NoError(os.WriteFile(path, binary, 0o600))
+	digest := sha256.Sum256(binary)
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(err)
+	publicKey := pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: publicKeyBytes,
+	})
+
+	// Nothing verifies the plugin
+	integrity, err := NewIntegrity(map[string]string{"other": hex.EncodeToString(digest[:])}, nil)
+	require.NoError(err)
+	integrity.Monitor = NewIntegrityMonitor()
+	require.ErrorIs(integrity.Verify(path), errUnverifiedPlugin)
+	_, err = integrity.Monitor.HealthCheck(context.Background())
+	require.ErrorIs(err, errIntegrityFailures)
+
+	// The configured digest matches
+	integrity.Digests["plugin"] = hex.EncodeToString(digest[:])
+	require.NoError(integrity.Verify(path))
+	_, err = integrity.Monitor.HealthCheck(context.Background())
+	require.NoError(err)
+
+	// The configured digest doesn't match, even though the signature is valid
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest[:])
+	require.NoError(err)
+	require.NoError(os.WriteFile(path+SignatureExtension, []byte(base64.StdEncoding.EncodeToString(signature)), 0o600))
+	integrity, err = NewIntegrity(map[string]string{"plugin": hex.EncodeToString(make([]byte, sha256.Size))}, publicKey)
+	require.NoError(err)
+	require.ErrorIs(integrity.Verify(path), errDigestMismatch)
+
+	// The signature is valid
+	integrity, err = NewIntegrity(nil, publicKey)
+	require.NoError(err)
+	require.NoError(integrity.Verify(path))
+
+	// The signature isn't of the plugin
+	require.NoError(os.WriteFile(path, []byte("tampered plugin binary"), 0o600))
+	require.ErrorIs(integrity.Verify(path), errInvalidSignature)
+}
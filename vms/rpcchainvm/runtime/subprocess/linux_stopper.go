@@ -11,6 +11,7 @@ package subprocess
 
 import (
 	"context"
+	"os"
 	"os/exec"
 	"syscall"
 
@@ -27,16 +28,25 @@ func NewCmd(path string, args ...string) *exec.Cmd {
 	return cmd
 }
 
-func stop(ctx context.Context, log logging.Logger, cmd *exec.Cmd) {
-	waitChan := make(chan error)
+// stop returns the state of the subprocess if it was waited for, or nil if it
+// had to be killed.
+func stop(ctx context.Context, log logging.Logger, cmd *exec.Cmd) *os.ProcessState {
+	type result struct {
+		state *os.ProcessState
+		err   error
+	}
+	waitChan := make(chan result, 1)
 	go func() {
 		// attempt graceful shutdown
 		errs := wrappers.Errs{}
 		err := cmd.Process.Signal(syscall.SIGTERM)
 		errs.Add(err)
-		_, err = cmd.Process.Wait()
+		state, err := cmd.Process.Wait()
 		errs.Add(err)
-		waitChan <- errs.Err
+		waitChan <- result{
+			state: state,
+			err:   errs.Err,
+		}
 		close(waitChan)
 	}()
 
@@ -44,19 +54,21 @@ func stop(ctx context.Context, log logging.Logger, cmd *exec.Cmd) {
 	defer cancel()
 
 	select {
-	case err := <-waitChan:
-		if err == nil {
+	case res := <-waitChan:
+		if res.err == nil {
 			log.Debug("subprocess gracefully shutdown")
 		} else {
 			log.Error("subprocess graceful shutdown failed",
-				zap.Error(err),
+				zap.Error(res.err),
 			)
 		}
+		return res.state
 	case <-ctx.Done():
 		// force kill
 		err := cmd.Process.Kill()
 		log.Error("subprocess was killed",
 			zap.Error(err),
 		)
+		return nil
 	}
 }
@@ -0,0 +1,153 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subprocess
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime"
+)
+
+// ManifestExtension is appended to the path of a plugin to get the path of its
+// manifest.
+const ManifestExtension = ".manifest.json"
+
+var (
+	errNoSyscalls       = errors.New("manifest doesn't declare any syscalls")
+	errUnknownSyscall   = errors.New("unknown syscall")
+	errDuplicateSyscall = errors.New("duplicate syscall")
+)
+
+// Manifest declares the requirements of a plugin. It's read from the file
+// next to the plugin binary named after the binary with [ManifestExtension].
+type Manifest struct {
+	// RPCChainVM protocol versions the plugin can serve. If empty, the
+	// protocol version is only checked during the handshake.
+	ProtocolVersions []uint `json:"protocolVersions,omitempty"`
+	// Syscalls the plugin is allowed to make, by name. Only required when the
+	// plugin is sandboxed.
+	Syscalls []string `json:"syscalls,omitempty"`
+}
+
+// ManifestError is returned when the manifest of a plugin can't be read, or
+// doesn't match what this node supports.
+type ManifestError struct {
+	// Path of the manifest
+	Path string
+	// JSON key of the manifest field that is invalid, or empty if the
+	// manifest as a whole is invalid
+	Field string
+	Err   error
+}
+
+func (e *ManifestError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("plugin manifest %q: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("plugin manifest %q: field %q: %v", e.Path, e.Field, e.Err)
+}
+
+func (e *ManifestError) Unwrap() error {
+	return e.Err
+}
+
+// ReadManifest reads the manifest of the plugin at [pluginPath] and verifies
+// it. Returned errors are of type [*ManifestError]. If the manifest doesn't
+// exist, the error wraps [os.ErrNotExist].
+func ReadManifest(pluginPath string) (*Manifest, error) {
+	path := pluginPath + ManifestExtension
+	manifestBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ManifestError{
+			Path: path,
+			Err:  fmt.Errorf("failed to read plugin manifest: %w", err),
+		}
+	}
+
+	// Unknown fields are rejected, so that a manifest isn't mistaken to
+	// enforce requirements that this node doesn't support.
+	decoder := json.NewDecoder(bytes.NewReader(manifestBytes))
+	decoder.DisallowUnknownFields()
+	manifest := &Manifest{}
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, &ManifestError{
+			Path: path,
+			Err:  fmt.Errorf("failed to parse plugin manifest: %w", err),
+		}
+	}
+	if err := manifest.Verify(); err != nil {
+		return nil, setManifestPath(err, path)
+	}
+	return manifest, nil
+}
+
+// setManifestPath returns [err] with its path set to [path] if it's a
+// [*ManifestError].
+func setManifestPath(err error, path string) error {
+	var manifestErr *ManifestError
+	if errors.As(err, &manifestErr) {
+		manifestErr.Path = path
+	}
+	return err
+}
+
+// Verify returns nil iff [m] is compatible with this node. Returned errors are
+// of type [*ManifestError].
+//
+// Syscalls are verified separately when the plugin is sandboxed.
+func (m *Manifest) Verify() error {
+	if len(m.ProtocolVersions) > 0 && !slices.Contains(m.ProtocolVersions, version.RPCChainVMProtocol) {
+		return &ManifestError{
+			Field: "protocolVersions",
+			Err: fmt.Errorf("%w: AvalancheGo implements RPCChainVM protocol version %d, the plugin declares %v",
+				runtime.ErrProtocolVersionMismatch,
+				version.RPCChainVMProtocol,
+				m.ProtocolVersions,
+			),
+		}
+	}
+	return nil
+}
+
+// verifySyscalls returns nil iff [m] declares at least one syscall and every
+// declared syscall is known on this platform.
+func (m *Manifest) verifySyscalls() error {
+	if len(m.Syscalls) == 0 {
+		return &ManifestError{
+			Field: "syscalls",
+			Err:   errNoSyscalls,
+		}
+	}
+
+	syscalls := make(map[string]struct{}, len(m.Syscalls))
+	for _, name := range m.Syscalls {
+		if _, ok := syscalls[name]; ok {
+			return &ManifestError{
+				Field: "syscalls",
+				Err:   fmt.Errorf("%w: %q", errDuplicateSyscall, name),
+			}
+		}
+		syscalls[name] = struct{}{}
+
+		if _, ok := syscallNumbers[name]; !ok {
+			return &ManifestError{
+				Field: "syscalls",
+				Err:   fmt.Errorf("%w: %q", errUnknownSyscall, name),
+			}
+		}
+	}
+	return nil
+}
+
+// allows returns true if [m] allows the plugin to make the syscall [name].
+func (m *Manifest) allows(name string) bool {
+	return slices.Contains(m.Syscalls, name)
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subprocess
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime"
+)
+
+func TestManifestVerify(t *testing.T) {
+	tests := []struct {
+		name          string
+		manifest      Manifest
+		expectedField string
+		expectedErr   error
+	}{
+		{
+			name:     "empty",
+			manifest: Manifest{},
+		},
+		{
+			name: "valid",
+			manifest: Manifest{
+				ProtocolVersions: []uint{version.RPCChainVMProtocol - 1, version.RPCChainVMProtocol},
+			},
+		},
+		{
+			name: "protocol version mismatch",
+			manifest: Manifest{
+				ProtocolVersions: []uint{version.RPCChainVMProtocol + 1},
+			},
+			expectedField: "protocolVersions",
+			expectedErr:   runtime.ErrProtocolVersionMismatch,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			err := test.manifest.Verify()
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr == nil {
+				return
+			}
+
+			var manifestErr *ManifestError
+			require.ErrorAs(err, &manifestErr)
+			require.Equal(test.expectedField, manifestErr.Field)
+		})
+	}
+}
+
+func TestManifestVerifySyscalls(t *testing.T) {
+	tests := []struct {
+		name        string
+		syscalls    []string
+		expectedErr error
+	}{
+		{
+			name:        "no syscalls",
+			expectedErr: errNoSyscalls,
+		},
+		{
+			name:        "unknown syscall",
+			syscalls:    []string{"not_a_syscall"},
+			expectedErr: errUnknownSyscall,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Manifest{
+				Syscalls: tt.syscalls,
+			}
+			require.ErrorIs(t, m.verifySyscalls(), tt.expectedErr)
+		})
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	require := require.New(t)
+
+	pluginPath := filepath.Join(t.TempDir(), "plugin")
+	manifestPath := pluginPath + ManifestExtension
+
+	_, err := ReadManifest(pluginPath)
+	require.ErrorIs(err, os.ErrNotExist)
+
+	require.NoError(os.WriteFile(manifestPath, []byte(`{"syscalls": ["read"]}`), 0o600))
+	manifest, err := ReadManifest(pluginPath)
+	require.NoError(err)
+	require.Equal(&Manifest{
+		Syscalls: []string{"read"},
+	}, manifest)
+
+	// Errors report the path of the manifest and the invalid field.
+	require.NoError(os.WriteFile(manifestPath, []byte(`{"protocolVersions": [0]}`), 0o600))
+	_, err = ReadManifest(pluginPath)
+	require.ErrorIs(err, runtime.ErrProtocolVersionMismatch)
+	var manifestErr *ManifestError
+	require.ErrorAs(err, &manifestErr)
+	require.Equal(manifestPath, manifestErr.Path)
+	require.Equal("protocolVersions", manifestErr.Field)
+
+	// Fields this node doesn't support are rejected.
+	require.NoError(os.WriteFile(manifestPath, []byte(`{"capabilities": ["stateSync"]}`), 0o600))
+	_, err = ReadManifest(pluginPath)
+	require.ErrorAs(err, &manifestErr)
+	require.Equal(manifestPath, manifestErr.Path)
+	require.Empty(manifestErr.Field)
+}
@@ -8,6 +8,7 @@ package subprocess
 
 import (
 	"context"
+	"os"
 	"os/exec"
 
 	"go.uber.org/zap"
@@ -19,7 +20,9 @@ func NewCmd(path string, args ...string) *exec.Cmd {
 	return exec.Command(path, args...)
 }
 
-func stop(_ context.Context, log logging.Logger, cmd *exec.Cmd) {
+// stop always kills the subprocess, so the state of the subprocess is never
+// returned.
+func stop(_ context.Context, log logging.Logger, cmd *exec.Cmd) *os.ProcessState {
 	err := cmd.Process.Kill()
 	if err == nil {
 		log.Debug("subprocess was killed")
@@ -28,4 +31,5 @@ func stop(_ context.Context, log logging.Logger, cmd *exec.Cmd) {
 			zap.Error(err),
 		)
 	}
+	return nil
 }
@@ -16,6 +16,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm/grpcutils"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm/gruntime"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm/runtime"
@@ -31,6 +32,9 @@ type Config struct {
 	// Duration engine server will wait for handshake success.
 	HandshakeTimeout time.Duration
 	Log              logging.Logger
+	// If non-nil, called with the state of the VM process once it has been
+	// stopped gracefully or exited on its own.
+	OnExit func(*os.ProcessState)
 }
 
 type Status struct {
@@ -73,7 +77,10 @@ func Bootstrap(
 	go grpcutils.Serve(listener, server)
 
 	serverAddr := listener.Addr()
-	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", runtime.EngineAddressKey, serverAddr.String()))
+	cmd.Env = append(cmd.Env,
+		fmt.Sprintf("%s=%s", runtime.EngineAddressKey, serverAddr.String()),
+		fmt.Sprintf("%s=%d", runtime.EngineProtocolVersionKey, version.RPCChainVMProtocol),
+	)
 	// pass golang debug env to subprocess
 	for _, env := range os.Environ() {
 		if strings.HasPrefix(env, "GRPC_") || strings.HasPrefix(env, "GODEBUG") {
@@ -94,9 +101,14 @@ func Bootstrap(
 	if err := cmd.Start(); err != nil {
 		return nil, nil, fmt.Errorf("failed to start process: %w", err)
 	}
+	// Extra files were inherited by the subprocess and aren't used by this
+	// process.
+	for _, file := range cmd.ExtraFiles {
+		_ = file.Close()
+	}
 
 	log := config.Log
-	stopper := NewStopper(log, cmd)
+	stopper := newStopper(log, cmd, config.OnExit)
 
 	// start stdout collector
 	go func() {
@@ -0,0 +1,158 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subprocess
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// SandboxMode describes how a plugin process is sandboxed.
+type SandboxMode string
+
+const (
+	// SandboxNone runs plugins without a sandbox.
+	SandboxNone SandboxMode = ""
+	// SandboxSeccomp runs plugins under a seccomp filter that kills the
+	// plugin if it makes a syscall that isn't in its manifest. Requires
+	// bubblewrap (bwrap) to be installed.
+	SandboxSeccomp SandboxMode = "seccomp"
+	// SandboxAppArmor runs plugins under an AppArmor profile that denies
+	// the capabilities whose syscalls aren't in its manifest. Requires
+	// apparmor_parser and aa-exec to be installed, and the node to be
+	// allowed to load AppArmor profiles.
+	SandboxAppArmor SandboxMode = "apparmor"
+)
+
+var (
+	errUnknownSandboxMode = errors.New("unknown sandbox mode")
+	errSandboxUnsupported = errors.New("sandbox not supported on this platform")
+	errSandboxViolations  = errors.New("plugins violated their sandbox")
+
+	// ErrSandboxDenied is returned when a sandboxed plugin fails to launch
+	// because its sandbox denied it access to a resource.
+	ErrSandboxDenied = errors.New("plugin was denied access by its sandbox")
+
+	// How EACCES is reported by the C library and by Go. AppArmor denies
+	// operations with EACCES rather than killing the process.
+	permissionDenied = []byte("permission denied")
+
+	// Syscalls that are always allowed because they are required to start
+	// and stop the plugin.
+	requiredSyscalls = []string{
+		"execve",
+		"exit",
+		"exit_group",
+	}
+)
+
+func ParseSandboxMode(s string) (SandboxMode, error) {
+	switch mode := SandboxMode(s); mode {
+	case SandboxNone, SandboxSeccomp, SandboxAppArmor:
+		return mode, nil
+	default:
+		return SandboxNone, fmt.Errorf("%w: %q", errUnknownSandboxMode, s)
+	}
+}
+
+// Sandbox describes how plugin processes should be sandboxed.
+type Sandbox struct {
+	Mode SandboxMode
+	// Records the plugins that violated their sandbox.
+	Monitor *SandboxMonitor
+}
+
+// SandboxViolation is a plugin process that was stopped because it violated
+// its sandbox.
+type SandboxViolation struct {
+	Path      string    `json:"path"`
+	Pid       int       `json:"pid"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SandboxMonitor reports the plugin processes that violated their sandbox
+// through the health API.
+type SandboxMonitor struct {
+	lock       sync.Mutex
+	violations []SandboxViolation
+}
+
+func NewSandboxMonitor() *SandboxMonitor {
+	return &SandboxMonitor{}
+}
+
+// HandleExit records a violation if the plugin at [path] exited with [state]
+// because it violated its sandbox.
+func (m *SandboxMonitor) HandleExit(path string, state *os.ProcessState) {
+	if !isSandboxViolation(state) {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.violations = append(m.violations, SandboxViolation{
+		Path:      path,
+		Pid:       state.Pid(),
+		Timestamp: time.Now(),
+	})
+}
+
+// HealthCheck is unhealthy once any plugin has violated its sandbox.
+func (m *SandboxMonitor) HealthCheck(context.Context) (interface{}, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	details := map[string]interface{}{
+		"violations": slices.Clone(m.violations),
+	}
+	if len(m.violations) > 0 {
+		return details, fmt.Errorf("%w: %d violations", errSandboxViolations, len(m.violations))
+	}
+	return details, nil
+}
+
+// DenialWriter forwards the output of a sandboxed plugin to [Writer], and
+// records the first line of output that reports an EACCES denial.
+type DenialWriter struct {
+	Writer io.Writer
+
+	lock   sync.Mutex
+	denial string
+}
+
+func (w *DenialWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	if w.denial == "" {
+		for _, line := range bytes.Split(p, []byte{'\n'}) {
+			if bytes.Contains(bytes.ToLower(line), permissionDenied) {
+				w.denial = string(bytes.TrimSpace(line))
+				break
+			}
+		}
+	}
+	w.lock.Unlock()
+
+	return w.Writer.Write(p)
+}
+
+// Err returns an error wrapping [ErrSandboxDenied] if the plugin reported an
+// EACCES denial. Otherwise, nil is returned.
+func (w *DenialWriter) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.denial == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrSandboxDenied, w.denial)
+}
@@ -0,0 +1,212 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build linux
+// +build linux
+
+package subprocess
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"golang.org/x/exp/slices"
+
+	"golang.org/x/net/bpf"
+)
+
+const (
+	seccompRetKillProcess = 0x80000000
+	seccompRetAllow       = 0x7fff0000
+
+	// Offsets of the fields of struct seccomp_data.
+	seccompDataNrOffset   = 0
+	seccompDataArchOffset = 4
+
+	// File descriptor of the seccomp filter in the bubblewrap process.
+	// ExtraFiles start after stdin, stdout and stderr.
+	seccompFilterFD = 3
+
+	appArmorProfilePrefix = "avalanchego-plugin-"
+)
+
+var invalidProfileNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// NewSandboxedCmd returns a command that runs the plugin at [path] in a
+// sandbox of type [mode] that only allows the plugin to make the syscalls
+// declared in [manifest].
+func NewSandboxedCmd(mode SandboxMode, manifest *Manifest, path string, args ...string) (*exec.Cmd, error) {
+	switch mode {
+	case SandboxNone:
+		return NewCmd(path, args...), nil
+	case SandboxSeccomp:
+		if err := manifest.verifySyscalls(); err != nil {
+			return nil, setManifestPath(err, path+ManifestExtension)
+		}
+		return newSeccompCmd(manifest, path, args...)
+	case SandboxAppArmor:
+		if err := manifest.verifySyscalls(); err != nil {
+			return nil, setManifestPath(err, path+ManifestExtension)
+		}
+		return newAppArmorCmd(manifest, path, args...)
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownSandboxMode, mode)
+	}
+}
+
+// newSeccompCmd runs the plugin with bubblewrap, which installs the seccomp
+// filter read from [seccompFilterFD] before executing the plugin.
+func newSeccompCmd(manifest *Manifest, path string, args ...string) (*exec.Cmd, error) {
+	filter, err := seccompFilter(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	bwrapPath, err := exec.LookPath("bwrap")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bubblewrap: %w", err)
+	}
+
+	filterReader, filterWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seccomp filter pipe: %w", err)
+	}
+	// The filter is much smaller than the pipe buffer, so this doesn't block.
+	_, err = filterWriter.Write(filter)
+	if closeErr := filterWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = filterReader.Close()
+		return nil, fmt.Errorf("failed to write seccomp filter: %w", err)
+	}
+
+	bwrapArgs := append([]string{
+		"--dev-bind", "/", "/",
+		"--die-with-parent",
+		"--seccomp", fmt.Sprint(seccompFilterFD),
+		"--",
+		path,
+	}, args...)
+	cmd := NewCmd(bwrapPath, bwrapArgs...)
+	cmd.ExtraFiles = []*os.File{filterReader}
+	return cmd, nil
+}
+
+// seccompFilter returns a classic BPF program that allows the syscalls
+// declared in [manifest] and kills the process on any other syscall.
+func seccompFilter(manifest *Manifest) ([]byte, error) {
+	if auditArch == 0 {
+		return nil, errSandboxUnsupported
+	}
+
+	syscalls := append(slices.Clone(manifest.Syscalls), requiredSyscalls...)
+	slices.Sort(syscalls)
+	syscalls = slices.Compact(syscalls)
+
+	program := []bpf.Instruction{
+		// Kill the process if the syscall is made for another architecture,
+		// because the syscall numbers are different.
+		bpf.LoadAbsolute{Off: seccompDataArchOffset, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: auditArch, SkipTrue: 1},
+		bpf.RetConstant{Val: seccompRetKillProcess},
+		bpf.LoadAbsolute{Off: seccompDataNrOffset, Size: 4},
+	}
+	for _, name := range syscalls {
+		nr, ok := syscallNumbers[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", errUnknownSyscall, name)
+		}
+		program = append(program,
+			bpf.JumpIf{Cond: bpf.JumpEqual, Val: nr, SkipFalse: 1},
+			bpf.RetConstant{Val: seccompRetAllow},
+		)
+	}
+	program = append(program, bpf.RetConstant{Val: seccompRetKillProcess})
+
+	rawProgram, err := bpf.Assemble(program)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble seccomp filter: %w", err)
+	}
+
+	// The filter is an array of struct sock_filter in native byte order.
+	// Seccomp filters are only supported on little endian architectures.
+	filter := make([]byte, 0, 8*len(rawProgram))
+	for _, inst := range rawProgram {
+		filter = binary.LittleEndian.AppendUint16(filter, inst.Op)
+		filter = append(filter, inst.Jt, inst.Jf)
+		filter = binary.LittleEndian.AppendUint32(filter, inst.K)
+	}
+	return filter, nil
+}
+
+// newAppArmorCmd loads an AppArmor profile for the plugin and runs the plugin
+// under it with aa-exec.
+func newAppArmorCmd(manifest *Manifest, path string, args ...string) (*exec.Cmd, error) {
+	aaExecPath, err := exec.LookPath("aa-exec")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find aa-exec: %w", err)
+	}
+
+	name := appArmorProfilePrefix + invalidProfileNameChars.ReplaceAllString(filepath.Base(path), "_")
+	loadCmd := exec.Command("apparmor_parser", "--replace")
+	loadCmd.Stdin = strings.NewReader(appArmorProfile(name, manifest))
+	if output, err := loadCmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to load AppArmor profile %q: %w: %s", name, err, output)
+	}
+
+	aaExecArgs := append([]string{
+		"--profile", name,
+		"--",
+		path,
+	}, args...)
+	return NewCmd(aaExecPath, aaExecArgs...), nil
+}
+
+// appArmorProfile returns an AppArmor profile named [name]. AppArmor doesn't
+// filter individual syscalls, so the profile only grants the capabilities
+// whose syscalls are declared in [manifest]. Everything else is denied.
+func appArmorProfile(name string, manifest *Manifest) string {
+	var sb strings.Builder
+	sb.WriteString("#include <tunables/global>\n\n")
+	fmt.Fprintf(&sb, "profile %s flags=(attach_disconnected) {\n", name)
+	sb.WriteString("  #include <abstractions/base>\n\n")
+	sb.WriteString("  file,\n")
+	sb.WriteString("  signal,\n")
+	for _, rule := range []struct {
+		syscall string
+		rule    string
+	}{
+		{syscall: "socket", rule: "network"},
+		{syscall: "ptrace", rule: "ptrace"},
+		{syscall: "mount", rule: "mount"},
+		{syscall: "umount2", rule: "umount"},
+		{syscall: "pivot_root", rule: "pivot_root"},
+	} {
+		if manifest.allows(rule.syscall) {
+			fmt.Fprintf(&sb, "  %s,\n", rule.rule)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// isSandboxViolation returns true if [state] is the state of a process that
+// was killed by its seccomp filter. Bubblewrap reports that its child was
+// killed by a signal by exiting with 128 + the signal number.
+func isSandboxViolation(state *os.ProcessState) bool {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok {
+		return false
+	}
+	if status.Signaled() {
+		return status.Signal() == syscall.SIGSYS
+	}
+	return status.Exited() && status.ExitStatus() == 128+int(syscall.SIGSYS)
+}
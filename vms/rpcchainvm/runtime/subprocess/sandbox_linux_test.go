@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build linux && (amd64 || arm64)
+// +build linux
+// +build amd64 arm64
+
+package subprocess
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestVerifyDuplicateSyscall(t *testing.T) {
+	m := &Manifest{
+		Syscalls: []string{"read", "read"},
+	}
+	require.ErrorIs(t, m.verifySyscalls(), errDuplicateSyscall)
+}
+
+func TestSeccompFilter(t *testing.T) {
+	require := require.New(t)
+
+	manifest := &Manifest{
+		Syscalls: []string{"read", "write", "exit_group"},
+	}
+	require.NoError(manifest.verifySyscalls())
+
+	filter, err := seccompFilter(manifest)
+	require.NoError(err)
+
+	// 4 instructions to load the syscall number, 2 instructions for each of
+	// the unique allowed syscalls and 1 instruction to kill the process.
+	numSyscalls := len(manifest.Syscalls) + len(requiredSyscalls) - 1
+	require.Len(filter, 8*(4+2*numSyscalls+1))
+}
+
+func TestAppArmorProfile(t *testing.T) {
+	require := require.New(t)
+
+	profile := appArmorProfile("avalanchego-plugin-test", &Manifest{
+		Syscalls: []string{"read", "socket"},
+	})
+	require.Contains(profile, "profile avalanchego-plugin-test ")
+	require.Contains(profile, "  network,\n")
+	require.NotContains(profile, "ptrace")
+	require.NotContains(profile, "mount")
+}
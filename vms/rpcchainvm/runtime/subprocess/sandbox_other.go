@@ -0,0 +1,29 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !linux
+// +build !linux
+
+package subprocess
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Syscall allowlists are only supported on linux.
+var syscallNumbers map[string]uint32
+
+// NewSandboxedCmd returns a command that runs the plugin at [path]. Plugins
+// can only be sandboxed on linux.
+func NewSandboxedCmd(mode SandboxMode, _ *Manifest, path string, args ...string) (*exec.Cmd, error) {
+	if mode != SandboxNone {
+		return nil, fmt.Errorf("%w: %q", errSandboxUnsupported, mode)
+	}
+	return NewCmd(path, args...), nil
+}
+
+func isSandboxViolation(*os.ProcessState) bool {
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package subprocess
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSandboxMode(t *testing.T) {
+	require := require.New(t)
+
+	for _, mode := range []SandboxMode{SandboxNone, SandboxSeccomp, SandboxAppArmor} {
+		parsedMode, err := ParseSandboxMode(string(mode))
+		require.NoError(err)
+		require.Equal(mode, parsedMode)
+	}
+
+	_, err := ParseSandboxMode("chroot")
+	require.ErrorIs(err, errUnknownSandboxMode)
+}
+
+func TestSandboxMonitorHealthCheck(t *testing.T) {
+	require := require.New(t)
+
+	m := NewSandboxMonitor()
+	_, err := m.HealthCheck(context.Background())
+	require.NoError(err)
+
+	m.violations = append(m.violations, SandboxViolation{
+		Path: "plugin",
+	})
+	_, err = m.HealthCheck(context.Background())
+	require.ErrorIs(err, errSandboxViolations)
+}
+
+func TestDenialWriter(t *testing.T) {
+	require := require.New(t)
+
+	output := &bytes.Buffer{}
+	w := &DenialWriter{
+		Writer: output,
+	}
+	_, err := w.Write([]byte("starting plugin\n"))
+	require.NoError(err)
+	require.NoError(w.Err())
+
+	_, err = w.Write([]byte("aa-exec: ERROR: Failed to execute \"plugin\": Permission denied\nexiting\n"))
+	require.NoError(err)
+	err = w.Err()
+	require.ErrorIs(err, ErrSandboxDenied)
+	require.ErrorContains(err, "Failed to execute")
+
+	require.Equal("starting plugin\naa-exec: ERROR: Failed to execute \"plugin\": Permission denied\nexiting\n", output.String())
+}
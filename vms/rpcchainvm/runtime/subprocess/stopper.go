@@ -5,6 +5,7 @@ package subprocess
 
 import (
 	"context"
+	"os"
 	"os/exec"
 	"sync"
 
@@ -13,9 +14,14 @@ import (
 )
 
 func NewStopper(logger logging.Logger, cmd *exec.Cmd) runtime.Stopper {
+	return newStopper(logger, cmd, nil)
+}
+
+func newStopper(logger logging.Logger, cmd *exec.Cmd, onExit func(*os.ProcessState)) *stopper {
 	return &stopper{
 		cmd:    cmd,
 		logger: logger,
+		onExit: onExit,
 	}
 }
 
@@ -23,10 +29,14 @@ type stopper struct {
 	once   sync.Once
 	cmd    *exec.Cmd
 	logger logging.Logger
+	onExit func(*os.ProcessState)
 }
 
 func (s *stopper) Stop(ctx context.Context) {
 	s.once.Do(func() {
-		stop(ctx, s.logger, s.cmd)
+		state := stop(ctx, s.logger, s.cmd)
+		if state != nil && s.onExit != nil {
+			s.onExit(state)
+		}
 	})
 }
@@ -5,9 +5,12 @@ package rpcchainvm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
@@ -18,6 +21,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm/grpcutils"
 	"github.com/ava-labs/avalanchego/vms/rpcchainvm/gruntime"
@@ -29,19 +33,99 @@ import (
 
 const defaultRuntimeDialTimeout = 5 * time.Second
 
+var (
+	errNoProtocolVersions          = errors.New("no protocol versions registered")
+	errUnsupportedProtocolVersion  = errors.New("unsupported protocol version")
+	errNonAdjacentProtocolVersions = errors.New("registered protocol versions are not adjacent")
+	errInvalidDrainPeriod          = errors.New("invalid drain period")
+)
+
 // The address of the Runtime server is expected to be passed via ENV `runtime.EngineAddressKey`.
 // This address is used by the Runtime client to send Initialize RPC to server.
 //
 // Serve starts the RPC Chain VM server and performs a handshake with the VM runtime service.
 func Serve(ctx context.Context, vm block.ChainVM, opts ...grpcutils.ServerOption) error {
+	return serve(ctx, vm, version.RPCChainVMProtocol, opts...)
+}
+
+// ServeVersions starts the RPC Chain VM server using the VM registered for the
+// protocol version implemented by the runtime engine. This allows a single
+// plugin binary to serve nodes on both sides of a protocol version bump.
+//
+// The keys of [vms] must be adjacent protocol versions. The protocol version of
+// the runtime engine is expected to be passed via ENV
+// `runtime.EngineProtocolVersionKey`. If it isn't provided, the engine predates
+// version negotiation and the highest registered version is used.
+func ServeVersions(ctx context.Context, vms map[uint]block.ChainVM, opts ...grpcutils.ServerOption) error {
+	protocolVersion, err := selectProtocolVersion(vms, os.Getenv(runtime.EngineProtocolVersionKey))
+	if err != nil {
+		return fmt.Errorf("failed to select protocol version: %w", err)
+	}
+	return serve(ctx, vms[protocolVersion], protocolVersion, opts...)
+}
+
+// selectProtocolVersion returns the protocol version in [vms] that should be
+// used to serve a runtime engine advertising [engineVersion].
+func selectProtocolVersion(vms map[uint]block.ChainVM, engineVersion string) (uint, error) {
+	if len(vms) == 0 {
+		return 0, errNoProtocolVersions
+	}
+
+	var (
+		minVersion = ^uint(0)
+		maxVersion uint
+	)
+	for protocolVersion := range vms {
+		minVersion = math.Min(minVersion, protocolVersion)
+		maxVersion = math.Max(maxVersion, protocolVersion)
+	}
+	if maxVersion-minVersion >= uint(len(vms)) {
+		return 0, fmt.Errorf("%w: min %d max %d", errNonAdjacentProtocolVersions, minVersion, maxVersion)
+	}
+
+	if engineVersion == "" {
+		return maxVersion, nil
+	}
+
+	protocolVersion, err := strconv.ParseUint(engineVersion, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q: %w", runtime.EngineProtocolVersionKey, err)
+	}
+	if _, ok := vms[uint(protocolVersion)]; !ok {
+		return 0, fmt.Errorf("%w: engine implements %d but plugin supports %d-%d",
+			errUnsupportedProtocolVersion,
+			protocolVersion,
+			minVersion,
+			maxVersion,
+		)
+	}
+	return uint(protocolVersion), nil
+}
+
+func serve(ctx context.Context, vm block.ChainVM, protocolVersion uint, opts ...grpcutils.ServerOption) error {
+	drainPeriod, err := parseDrainPeriod(os.Getenv(runtime.DrainPeriodKey))
+	if err != nil {
+		return err
+	}
+
 	signals := make(chan os.Signal, 2)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 	defer signal.Stop(signals)
 
 	var allowShutdown utils.Atomic[bool]
-	server := newVMServer(vm, &allowShutdown, opts...)
+	inFlight := grpcutils.NewInFlightTracker()
+	drain := &drainBudget{period: drainPeriod}
+	server := newVMServer(vm, &allowShutdown, inFlight, drain, opts...)
 	go func(ctx context.Context) {
 		defer func() {
+			// GracefulStop waits for every in-flight call to complete, so
+			// calls are only given what remains of [drainPeriod] after the
+			// Shutdown call's drain to complete before they're aborted.
+			if aborted := inFlight.Drain(drain.remaining()); len(aborted) > 0 {
+				fmt.Printf("vm server: aborting in-flight calls after %s: %v\n", drainPeriod, aborted)
+				server.Stop()
+				return
+			}
 			server.GracefulStop()
 			fmt.Println("vm server: graceful termination success")
 		}()
@@ -91,7 +175,7 @@ func Serve(ctx context.Context, vm block.ChainVM, opts ...grpcutils.ServerOption
 
 	ctx, cancel := context.WithTimeout(ctx, defaultRuntimeDialTimeout)
 	defer cancel()
-	err = client.Initialize(ctx, version.RPCChainVMProtocol, listener.Addr().String())
+	err = client.Initialize(ctx, protocolVersion, listener.Addr().String())
 	if err != nil {
 		_ = listener.Close()
 		return fmt.Errorf("failed to initialize vm runtime: %w", err)
@@ -103,10 +187,66 @@ func Serve(ctx context.Context, vm block.ChainVM, opts ...grpcutils.ServerOption
 	return nil
 }
 
+// parseDrainPeriod returns the drain period specified by [drainPeriod], or
+// [runtime.DefaultDrainPeriod] if it isn't specified. The drain period must
+// leave [runtime.DefaultGracefulStopMargin] of [runtime.DefaultGracefulTimeout]
+// for the server to stop.
+func parseDrainPeriod(drainPeriod string) (time.Duration, error) {
+	if drainPeriod == "" {
+		return runtime.DefaultDrainPeriod, nil
+	}
+	period, err := time.ParseDuration(drainPeriod)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q: %w", runtime.DrainPeriodKey, err)
+	}
+	if period < 0 || period > runtime.DefaultDrainPeriod {
+		return 0, fmt.Errorf("%w: %q is %s but must be in [0, %s]",
+			errInvalidDrainPeriod,
+			runtime.DrainPeriodKey,
+			period,
+			runtime.DefaultDrainPeriod,
+		)
+	}
+	return period, nil
+}
+
+// drainBudget is the drain period shared by every drain of a shutdown. The VM
+// drains when the Shutdown call is received and again when the server is
+// stopped, and together they must not wait longer than [period].
+type drainBudget struct {
+	period time.Duration
+
+	once     sync.Once
+	deadline time.Time
+}
+
+// remaining starts the drain period, if it hasn't been started yet, and
+// returns how much of it is left.
+func (b *drainBudget) remaining() time.Duration {
+	b.once.Do(func() {
+		b.deadline = time.Now().Add(b.period)
+	})
+	return time.Until(b.deadline)
+}
+
 // Returns an RPC Chain VM server serving health and VM services.
-func newVMServer(vm block.ChainVM, allowShutdown *utils.Atomic[bool], opts ...grpcutils.ServerOption) *grpc.Server {
+//
+// Calls to the server are tracked by [inFlight]. When the VM is shut down, it
+// waits up to [drain]'s period for in-flight calls to complete.
+func newVMServer(
+	vm block.ChainVM,
+	allowShutdown *utils.Atomic[bool],
+	inFlight *grpcutils.InFlightTracker,
+	drain *drainBudget,
+	opts ...grpcutils.ServerOption,
+) *grpc.Server {
+	opts = append(opts, grpcutils.WithUnaryInterceptor(inFlight.UnaryServerInterceptor()))
 	server := grpcutils.NewServer(opts...)
-	vmpb.RegisterVMServer(server, NewServer(vm, allowShutdown))
+
+	vmServer := NewServer(vm, allowShutdown)
+	vmServer.inFlight = inFlight
+	vmServer.drain = drain
+	vmpb.RegisterVMServer(server, vmServer)
 
 	health := health.NewServer()
 	health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
@@ -107,6 +107,16 @@ type VMClient struct {
 	conns        []*grpc.ClientConn
 
 	grpcServerMetrics *grpc_prometheus.ServerMetrics
+	// If nil, calls made by the VM aren't observed.
+	callMetrics *grpcutils.CallMetrics
+
+	// If the zero value, gossip is sent to the VM as it's received.
+	gossipRelayConfig GossipRelayConfig
+	// If nil, gossip is sent to the VM as it's received.
+	gossipRelay *gossipRelay
+
+	// Blocks being built by the remote VM
+	builds builds
 }
 
 // NewClient returns a VM connected to a remote VM
@@ -114,6 +124,9 @@ func NewClient(clientConn *grpc.ClientConn) *VMClient {
 	return &VMClient{
 		client: vmpb.NewVMClient(clientConn),
 		conns:  []*grpc.ClientConn{clientConn},
+		builds: builds{
+			building: make(chan struct{}, 1),
+		},
 	}
 }
 
@@ -147,6 +160,12 @@ func (vm *VMClient) Initialize(
 	if err := registerer.Register(vm.grpcServerMetrics); err != nil {
 		return err
 	}
+	if vm.callMetrics == nil {
+		vm.callMetrics = grpcutils.NewCallMetrics(chainCtx.Log, grpcutils.DefaultCallMetricsConfig)
+	}
+	if err := registerer.Register(vm.callMetrics); err != nil {
+		return err
+	}
 	if err := multiGatherer.Register("rpcchainvm", registerer); err != nil {
 		return err
 	}
@@ -250,13 +269,21 @@ func (vm *VMClient) Initialize(
 		return err
 	}
 	vm.State = chainState
+	vm.builds.setPreference(id)
 
+	if policy := vm.gossipRelayConfig.Policy; policy != "" && policy != GossipPolicyBlock {
+		vm.gossipRelay, err = newGossipRelay(chainCtx.Log, vm.gossipRelayConfig, registerer, vm.appGossip)
+		if err != nil {
+			return err
+		}
+	}
 	return chainCtx.Metrics.Register(multiGatherer)
 }
 
 func (vm *VMClient) newDBServer(db database.Database) *grpc.Server {
 	server := grpcutils.NewServer(
 		grpcutils.WithUnaryInterceptor(vm.grpcServerMetrics.UnaryServerInterceptor()),
+		grpcutils.WithUnaryInterceptor(vm.callMetrics.UnaryServerInterceptor()),
 		grpcutils.WithStreamInterceptor(vm.grpcServerMetrics.StreamServerInterceptor()),
 	)
 
@@ -279,6 +306,7 @@ func (vm *VMClient) newDBServer(db database.Database) *grpc.Server {
 func (vm *VMClient) newInitServer() *grpc.Server {
 	server := grpcutils.NewServer(
 		grpcutils.WithUnaryInterceptor(vm.grpcServerMetrics.UnaryServerInterceptor()),
+		grpcutils.WithUnaryInterceptor(vm.callMetrics.UnaryServerInterceptor()),
 		grpcutils.WithStreamInterceptor(vm.grpcServerMetrics.StreamServerInterceptor()),
 	)
 
@@ -341,6 +369,10 @@ func (vm *VMClient) SetState(ctx context.Context, state snow.State) error {
 }
 
 func (vm *VMClient) Shutdown(ctx context.Context) error {
+	if vm.gossipRelay != nil {
+		vm.gossipRelay.Close()
+	}
+
 	errs := wrappers.Errs{}
 	_, err := vm.client.Shutdown(ctx, &emptypb.Empty{})
 	errs.Add(err)
@@ -412,7 +444,7 @@ func (vm *VMClient) Disconnected(ctx context.Context, nodeID ids.NodeID) error {
 // If the underlying VM doesn't actually implement this method, its [BuildBlock]
 // method will be called instead.
 func (vm *VMClient) buildBlockWithContext(ctx context.Context, blockCtx *block.Context) (snowman.Block, error) {
-	resp, err := vm.client.BuildBlock(ctx, &vmpb.BuildBlockRequest{
+	resp, err := vm.sendBuildBlock(ctx, &vmpb.BuildBlockRequest{
 		PChainHeight: &blockCtx.PChainHeight,
 	})
 	if err != nil {
@@ -422,7 +454,7 @@ func (vm *VMClient) buildBlockWithContext(ctx context.Context, blockCtx *block.C
 }
 
 func (vm *VMClient) buildBlock(ctx context.Context) (snowman.Block, error) {
-	resp, err := vm.client.BuildBlock(ctx, &vmpb.BuildBlockRequest{})
+	resp, err := vm.sendBuildBlock(ctx, &vmpb.BuildBlockRequest{})
 	if err != nil {
 		return nil, err
 	}
@@ -503,6 +535,10 @@ func (vm *VMClient) getBlock(ctx context.Context, blkID ids.ID) (snowman.Block,
 }
 
 func (vm *VMClient) SetPreference(ctx context.Context, blkID ids.ID) error {
+	// Blocks being built on top of the previously preferred block are no
+	// longer useful.
+	vm.builds.setPreference(blkID)
+
 	_, err := vm.client.SetPreference(ctx, &vmpb.SetPreferenceRequest{
 		Id: blkID[:],
 	})
@@ -600,7 +636,17 @@ func (vm *VMClient) AppRequestFailed(ctx context.Context, nodeID ids.NodeID, req
 	return err
 }
 
+// AppGossip sends [msg] to the VM. If gossip is relayed, [msg] is queued to
+// be sent without waiting for the VM to handle it, and may be shed if the VM
+// falls behind.
 func (vm *VMClient) AppGossip(ctx context.Context, nodeID ids.NodeID, msg []byte) error {
+	if vm.gossipRelay != nil {
+		return vm.gossipRelay.Relay(nodeID, msg)
+	}
+	return vm.appGossip(ctx, nodeID, msg)
+}
+
+func (vm *VMClient) appGossip(ctx context.Context, nodeID ids.NodeID, msg []byte) error {
 	_, err := vm.client.AppGossip(
 		ctx,
 		&vmpb.AppGossipMsg{
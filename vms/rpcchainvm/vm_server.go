@@ -16,6 +16,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 
+	"go.uber.org/zap"
+
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/ava-labs/avalanchego/api/keystore/gkeystore"
@@ -74,6 +76,11 @@ type VMServer struct {
 
 	allowShutdown *utils.Atomic[bool]
 
+	// If non-nil, tracks the calls being handled by this server so that they
+	// can complete, within [drain], before the VM is shut down.
+	inFlight *grpcutils.InFlightTracker
+	drain    *drainBudget
+
 	processMetrics prometheus.Gatherer
 	db             database.Database
 	log            logging.Logger
@@ -147,13 +154,32 @@ func (vm *VMServer) Initialize(ctx context.Context, req *vmpb.InitializeRequest)
 		return nil, err
 	}
 
+	// TODO: Allow the logger to be configured by the client
+	vm.log = logging.NewLogger(
+		fmt.Sprintf("<%s Chain>", chainID),
+		logging.NewWrappedCore(
+			logging.Info,
+			originalStderr,
+			logging.Colors.ConsoleEncoder(),
+		),
+	)
+
+	// gRPC payload size metrics and expensive call logging
+	grpcCallMetrics := grpcutils.NewCallMetrics(vm.log, grpcutils.DefaultCallMetricsConfig)
+	if err := registerer.Register(grpcCallMetrics); err != nil {
+		return nil, err
+	}
+
 	// Register metrics for each Go plugin processes
 	vm.processMetrics = registerer
 
 	// Dial the database
 	dbClientConn, err := grpcutils.Dial(
 		req.DbServerAddr,
-		grpcutils.WithChainUnaryInterceptor(grpcClientMetrics.UnaryClientInterceptor()),
+		grpcutils.WithChainUnaryInterceptor(
+			grpcClientMetrics.UnaryClientInterceptor(),
+			grpcCallMetrics.UnaryClientInterceptor(),
+		),
 		grpcutils.WithChainStreamInterceptor(grpcClientMetrics.StreamClientInterceptor()),
 	)
 	if err != nil {
@@ -164,19 +190,12 @@ func (vm *VMServer) Initialize(ctx context.Context, req *vmpb.InitializeRequest)
 		rpcdb.NewClient(rpcdbpb.NewDatabaseClient(dbClientConn)),
 	)
 
-	// TODO: Allow the logger to be configured by the client
-	vm.log = logging.NewLogger(
-		fmt.Sprintf("<%s Chain>", chainID),
-		logging.NewWrappedCore(
-			logging.Info,
-			originalStderr,
-			logging.Colors.ConsoleEncoder(),
-		),
-	)
-
 	clientConn, err := grpcutils.Dial(
 		req.ServerAddr,
-		grpcutils.WithChainUnaryInterceptor(grpcClientMetrics.UnaryClientInterceptor()),
+		grpcutils.WithChainUnaryInterceptor(
+			grpcClientMetrics.UnaryClientInterceptor(),
+			grpcCallMetrics.UnaryClientInterceptor(),
+		),
 		grpcutils.WithChainStreamInterceptor(grpcClientMetrics.StreamClientInterceptor()),
 	)
 	if err != nil {
@@ -303,6 +322,15 @@ func (vm *VMServer) Shutdown(ctx context.Context, _ *emptypb.Empty) (*emptypb.Em
 	if vm.closed == nil {
 		return &emptypb.Empty{}, nil
 	}
+	if vm.inFlight != nil {
+		aborted := vm.inFlight.Drain(vm.drain.remaining(), vmpb.VM_Shutdown_FullMethodName)
+		if len(aborted) > 0 {
+			vm.log.Warn("shutting down with calls in flight",
+				zap.Duration("drainPeriod", vm.drain.period),
+				zap.Any("inFlight", aborted),
+			)
+		}
+	}
 	errs := wrappers.Errs{}
 	errs.Add(vm.vm.Shutdown(ctx))
 	close(vm.closed)
@@ -387,6 +415,10 @@ func (vm *VMServer) Disconnected(ctx context.Context, req *vmpb.DisconnectedRequ
 
 // If the underlying VM doesn't actually implement this method, its [BuildBlock]
 // method will be called instead.
+//
+// [ctx] is cancelled if the client abandons the request, such as when a build
+// started with StartBuildBlock is cancelled, so that the VM can stop building
+// the block.
 func (vm *VMServer) BuildBlock(ctx context.Context, req *vmpb.BuildBlockRequest) (*vmpb.BuildBlockResponse, error) {
 	var (
 		blk snowman.Block
@@ -39,6 +39,7 @@ const (
 	lastAcceptedBlockPostStateSummaryAcceptTestKey = "lastAcceptedBlockPostStateSummaryAcceptTest"
 	contextTestKey                                 = "contextTest"
 	batchedParseBlockCachingTestKey                = "batchedParseBlockCachingTest"
+	asyncBuildBlockTestKey                         = "asyncBuildBlockTest"
 )
 
 var TestServerPluginMap = map[string]func(*testing.T, bool) block.ChainVM{
@@ -51,6 +52,7 @@ var TestServerPluginMap = map[string]func(*testing.T, bool) block.ChainVM{
 	lastAcceptedBlockPostStateSummaryAcceptTestKey: lastAcceptedBlockPostStateSummaryAcceptTestPlugin,
 	contextTestKey:                                 contextEnabledTestPlugin,
 	batchedParseBlockCachingTestKey:                batchedParseBlockCachingTestPlugin,
+	asyncBuildBlockTestKey:                         asyncBuildBlockTestPlugin,
 }
 
 // helperProcess helps with creating the subnet binary for testing.
@@ -202,3 +204,99 @@ func TestRuntimeSubprocessBootstrap(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		versions        []uint
+		engineVersion   string
+		expectedVersion uint
+		expectedErr     error
+	}{
+		{
+			name:          "no versions",
+			versions:      nil,
+			engineVersion: "30",
+			expectedErr:   errNoProtocolVersions,
+		},
+		{
+			name:          "non-adjacent versions",
+			versions:      []uint{28, 30},
+			engineVersion: "30",
+			expectedErr:   errNonAdjacentProtocolVersions,
+		},
+		{
+			name:          "unsupported version",
+			versions:      []uint{29, 30},
+			engineVersion: "31",
+			expectedErr:   errUnsupportedProtocolVersion,
+		},
+		{
+			name:            "older engine version",
+			versions:        []uint{29, 30},
+			engineVersion:   "29",
+			expectedVersion: 29,
+		},
+		{
+			name:            "newer engine version",
+			versions:        []uint{29, 30},
+			engineVersion:   "30",
+			expectedVersion: 30,
+		},
+		{
+			name:            "engine version not provided",
+			versions:        []uint{29, 30},
+			engineVersion:   "",
+			expectedVersion: 30,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			vms := make(map[uint]block.ChainVM, len(test.versions))
+			for _, version := range test.versions {
+				vms[version] = nil
+			}
+
+			protocolVersion, err := selectProtocolVersion(vms, test.engineVersion)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedVersion, protocolVersion)
+		})
+	}
+}
+
+func TestParseDrainPeriod(t *testing.T) {
+	require := require.New(t)
+
+	period, err := parseDrainPeriod("")
+	require.NoError(err)
+	require.Equal(runtime.DefaultDrainPeriod, period)
+
+	period, err = parseDrainPeriod("1500ms")
+	require.NoError(err)
+	require.Equal(1500*time.Millisecond, period)
+
+	_, err = parseDrainPeriod("soon")
+	require.Error(err) //nolint:forbidigo // error is not exported
+
+	// The drain period must leave time for the server to stop before it's
+	// killed.
+	_, err = parseDrainPeriod(runtime.DefaultGracefulTimeout.String())
+	require.ErrorIs(err, errInvalidDrainPeriod)
+
+	_, err = parseDrainPeriod("-1s")
+	require.ErrorIs(err, errInvalidDrainPeriod)
+}
+
+func TestDrainBudgetShared(t *testing.T) {
+	require := require.New(t)
+
+	drain := &drainBudget{period: time.Hour}
+	first := drain.remaining()
+	require.LessOrEqual(first, time.Hour)
+
+	// Later drains only get what's left of the period.
+	time.Sleep(time.Millisecond)
+	require.Less(drain.remaining(), first)
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracedvm
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+)
+
+var _ block.BlockBuild = (*tracedBlockBuild)(nil)
+
+// StartBuildBlock traces the build until it finishes, whether or not its
+// result is read.
+func (vm *blockVM) StartBuildBlock(ctx context.Context, blockCtx *block.Context) block.BlockBuild {
+	if vm.asyncBuildVM == nil {
+		return block.BuildBlockNow(ctx, vm, blockCtx)
+	}
+
+	var opts []oteltrace.SpanStartOption
+	if blockCtx != nil {
+		opts = append(opts, oteltrace.WithAttributes(
+			attribute.Int64("pChainHeight", int64(blockCtx.PChainHeight)),
+		))
+	}
+	ctx, span := vm.tracer.Start(ctx, vm.startBuildBlockTag, opts...)
+
+	build := vm.asyncBuildVM.StartBuildBlock(ctx, blockCtx)
+	go func() {
+		<-build.Done()
+		span.End()
+	}()
+	return &tracedBlockBuild{
+		BlockBuild: build,
+		vm:         vm,
+	}
+}
+
+type tracedBlockBuild struct {
+	block.BlockBuild
+
+	vm *blockVM
+
+	once sync.Once
+	blk  snowman.Block
+	err  error
+}
+
+func (b *tracedBlockBuild) Result(ctx context.Context) (snowman.Block, error) {
+	b.once.Do(func() {
+		b.blk, b.err = b.BlockBuild.Result(ctx)
+		if b.err == nil {
+			b.blk = &tracedBlock{
+				Block: b.blk,
+				vm:    b.vm,
+			}
+		}
+	})
+	return b.blk, b.err
+}
@@ -25,6 +25,7 @@ var (
 	_ block.BuildBlockWithContextChainVM = (*blockVM)(nil)
 	_ block.BatchedChainVM               = (*blockVM)(nil)
 	_ block.StateSyncableVM              = (*blockVM)(nil)
+	_ block.AsyncBuildBlockChainVM       = (*blockVM)(nil)
 )
 
 type blockVM struct {
@@ -32,6 +33,7 @@ type blockVM struct {
 	buildBlockVM block.BuildBlockWithContextChainVM
 	batchedVM    block.BatchedChainVM
 	ssVM         block.StateSyncableVM
+	asyncBuildVM block.AsyncBuildBlockChainVM
 	// ChainVM tags
 	initializeTag              string
 	buildBlockTag              string
@@ -47,6 +49,8 @@ type blockVM struct {
 	verifyWithContextTag       string
 	// BuildBlockWithContextChainVM tags
 	buildBlockWithContextTag string
+	// AsyncBuildBlockChainVM tags
+	startBuildBlockTag string
 	// BatchedChainVM tags
 	getAncestorsTag      string
 	batchedParseBlockTag string
@@ -66,11 +70,13 @@ func NewBlockVM(vm block.ChainVM, name string, tracer trace.Tracer) block.ChainV
 	buildBlockVM, _ := vm.(block.BuildBlockWithContextChainVM)
 	batchedVM, _ := vm.(block.BatchedChainVM)
 	ssVM, _ := vm.(block.StateSyncableVM)
+	asyncBuildVM, _ := vm.(block.AsyncBuildBlockChainVM)
 	return &blockVM{
 		ChainVM:                       vm,
 		buildBlockVM:                  buildBlockVM,
 		batchedVM:                     batchedVM,
 		ssVM:                          ssVM,
+		asyncBuildVM:                  asyncBuildVM,
 		initializeTag:                 fmt.Sprintf("%s.initialize", name),
 		buildBlockTag:                 fmt.Sprintf("%s.buildBlock", name),
 		parseBlockTag:                 fmt.Sprintf("%s.parseBlock", name),
@@ -84,6 +90,7 @@ func NewBlockVM(vm block.ChainVM, name string, tracer trace.Tracer) block.ChainV
 		shouldVerifyWithContextTag:    fmt.Sprintf("%s.shouldVerifyWithContext", name),
 		verifyWithContextTag:          fmt.Sprintf("%s.verifyWithContext", name),
 		buildBlockWithContextTag:      fmt.Sprintf("%s.buildBlockWithContext", name),
+		startBuildBlockTag:            fmt.Sprintf("%s.startBuildBlock", name),
 		getAncestorsTag:               fmt.Sprintf("%s.getAncestors", name),
 		batchedParseBlockTag:          fmt.Sprintf("%s.batchedParseBlock", name),
 		verifyHeightIndexTag:          fmt.Sprintf("%s.verifyHeightIndex", name),
@@ -154,6 +154,10 @@ func (w *wallet) IssueAtomicTx(
 ) error {
 	ops := common.NewOptions(options)
 	ctx := ops.Context()
+	if f := ops.PreIssuanceFunc(); f != nil {
+		f(w.Backend.BlockchainID(), tx.SignedBytes())
+	}
+
 	txID, err := w.avaxClient.IssueTx(ctx, tx.SignedBytes())
 	if err != nil {
 		return err
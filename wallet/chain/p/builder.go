@@ -24,7 +24,6 @@ import (
 )
 
 var (
-	errNoChangeAddress           = errors.New("no possible change address")
 	errWrongTxType               = errors.New("wrong tx type")
 	errUnknownOwnerType          = errors.New("unknown owner type")
 	errInsufficientAuthorization = errors.New("insufficient authorization")
@@ -243,6 +242,19 @@ type Builder interface {
 		rewardsOwner *secp256k1fx.OutputOwners,
 		options ...common.Option,
 	) (*txs.AddPermissionlessDelegatorTx, error)
+
+	// NewClaimFeeRefundTx claims fees that [addr] overpaid.
+	//
+	// - [addr] specifies the address whose refund is being claimed.
+	// - [amount] specifies the amount of the refund to claim.
+	// - [to] specifies where to send the claimed funds, after the tx fee is
+	//   deducted.
+	NewClaimFeeRefundTx(
+		addr ids.ShortID,
+		amount uint64,
+		to *secp256k1fx.OutputOwners,
+		options ...common.Option,
+	) (*txs.ClaimFeeRefundTx, error)
 }
 
 // BuilderBackend specifies the required information needed to build unsigned
@@ -811,6 +823,76 @@ func (b *builder) NewAddPermissionlessDelegatorTx(
 	}, nil
 }
 
+func (b *builder) NewClaimFeeRefundTx(
+	addr ids.ShortID,
+	amount uint64,
+	to *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.ClaimFeeRefundTx, error) {
+	ops := common.NewOptions(options)
+	owner := &secp256k1fx.OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{addr},
+	}
+	addrs := ops.Addresses(b.addrs)
+	minIssuanceTime := ops.MinIssuanceTime()
+	inputSigIndices, ok := common.MatchOwners(owner, addrs, minIssuanceTime)
+	if !ok {
+		// We can't authorize the claim
+		return nil, errInsufficientAuthorization
+	}
+
+	var (
+		avaxAssetID = b.backend.AVAXAssetID()
+		txFee       = b.backend.BaseTxFee()
+
+		// The claim must consume at least one UTXO so that it can't be
+		// replayed. If the claimed amount covers the tx fee, a single unit of
+		// AVAX is burned from the UTXOs and returned in the claim output.
+		toBurn = uint64(1)
+	)
+	if amount < txFee {
+		// claimed amount goes toward paying tx fee
+		toBurn = txFee - amount
+	}
+	inputs, outputs, _, err := b.spend(
+		map[ids.ID]uint64{
+			avaxAssetID: toBurn,
+		},
+		map[ids.ID]uint64{},
+		ops,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't generate tx inputs/outputs: %w", err)
+	}
+	if amount >= txFee {
+		utils.Sort(to.Addrs)
+		outputs = append(outputs, &avax.TransferableOutput{
+			Asset: avax.Asset{ID: avaxAssetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt:          amount - txFee + toBurn,
+				OutputOwners: *to,
+			},
+		})
+	}
+
+	avax.SortTransferableOutputs(outputs, txs.Codec) // sort the outputs
+	return &txs.ClaimFeeRefundTx{
+		BaseTx: txs.BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    b.backend.NetworkID(),
+			BlockchainID: constants.PlatformChainID,
+			Ins:          inputs,
+			Outs:         outputs,
+			Memo:         ops.Memo(),
+		}},
+		Address: addr,
+		Amount:  amount,
+		AddressAuth: &secp256k1fx.Input{
+			SigIndices: inputSigIndices,
+		},
+	}, nil
+}
+
 func (b *builder) getBalance(
 	chainID ids.ID,
 	options *common.Options,
@@ -888,14 +970,10 @@ func (b *builder) spend(
 	addrs := options.Addresses(b.addrs)
 	minIssuanceTime := options.MinIssuanceTime()
 
-	addr, ok := addrs.Peek()
-	if !ok {
-		return nil, nil, nil, errNoChangeAddress
+	changeOwners, err := common.NewChangeOwners(options, addrs)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	changeOwner := options.ChangeOwner(&secp256k1fx.OutputOwners{
-		Threshold: 1,
-		Addrs:     []ids.ShortID{addr},
-	})
 
 	// Iterate over the locked UTXOs
 	for _, utxo := range utxos {
@@ -1044,7 +1122,7 @@ func (b *builder) spend(
 				Asset: utxo.Asset,
 				Out: &secp256k1fx.TransferOutput{
 					Amt:          amountToStake,
-					OutputOwners: *changeOwner,
+					OutputOwners: *changeOwners.Next(),
 				},
 			})
 		}
@@ -1054,7 +1132,7 @@ func (b *builder) spend(
 				Asset: utxo.Asset,
 				Out: &secp256k1fx.TransferOutput{
 					Amt:          remainingAmount,
-					OutputOwners: *changeOwner,
+					OutputOwners: *changeOwners.Next(),
 				},
 			})
 		}
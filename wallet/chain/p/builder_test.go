@@ -0,0 +1,241 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package p
+
+import (
+	stdcontext "context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/vms/components/avax"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+	"github.com/ava-labs/avalanchego/wallet/subnet/primary/common"
+)
+
+var _ BuilderBackend = (*testBuilderBackend)(nil)
+
+type testBuilderBackend struct {
+	Context
+	utxos []*avax.UTXO
+}
+
+func (b *testBuilderBackend) UTXOs(stdcontext.Context, ids.ID) ([]*avax.UTXO, error) {
+	return b.utxos, nil
+}
+
+func (*testBuilderBackend) GetTx(stdcontext.Context, ids.ID) (*txs.Tx, error) {
+	return nil, database.ErrNotFound
+}
+
+func TestNewBaseTxChangeOwners(t *testing.T) {
+	addrs := []ids.ShortID{
+		ids.GenerateTestShortID(),
+		ids.GenerateTestShortID(),
+		ids.GenerateTestShortID(),
+	}
+	utils.Sort(addrs)
+
+	var (
+		avaxAssetID  = ids.GenerateTestID()
+		otherAssetID = ids.GenerateTestID()
+		customOwner  = &secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+		}
+		backend = &testBuilderBackend{
+			Context: NewContext(
+				constants.UnitTestID,
+				avaxAssetID,
+				units.MilliAvax, // baseTxFee
+				units.MilliAvax, // createSubnetTxFee
+				units.MilliAvax, // transformSubnetTxFee
+				units.MilliAvax, // createBlockchainTxFee
+				units.MilliAvax, // addPrimaryNetworkValidatorFee
+				units.MilliAvax, // addPrimaryNetworkDelegatorFee
+				units.MilliAvax, // addSubnetValidatorFee
+				units.MilliAvax, // addSubnetDelegatorFee
+			),
+			// The AVAX UTXO is spent before the other UTXO, so the change
+			// outputs are assigned owners in that order.
+			utxos: []*avax.UTXO{
+				newTestUTXO(avaxAssetID, units.Avax, addrs[2]),
+				newTestUTXO(otherAssetID, units.Avax, addrs[2]),
+			},
+		}
+		outputs = []*avax.TransferableOutput{
+			newTestOutput(avaxAssetID, units.MilliAvax),
+			newTestOutput(otherAssetID, units.MilliAvax),
+		}
+	)
+
+	tests := []struct {
+		name               string
+		options            []common.Option
+		expectedErr        error
+		expectedAVAXOwner  *secp256k1fx.OutputOwners
+		expectedAssetOwner *secp256k1fx.OutputOwners
+	}{
+		{
+			name: "consolidate by default",
+			expectedAVAXOwner: &secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     addrs[:1],
+			},
+			expectedAssetOwner: &secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     addrs[:1],
+			},
+		},
+		{
+			name: "rotate",
+			options: []common.Option{
+				common.WithChangePolicy(common.RotateChange),
+			},
+			expectedAVAXOwner: &secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     addrs[:1],
+			},
+			expectedAssetOwner: &secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     addrs[1:2],
+			},
+		},
+		{
+			name: "threshold",
+			options: []common.Option{
+				common.WithChangePolicy(common.ThresholdChange),
+				common.WithChangeThreshold(2),
+			},
+			expectedAVAXOwner: &secp256k1fx.OutputOwners{
+				Threshold: 2,
+				Addrs:     addrs,
+			},
+			expectedAssetOwner: &secp256k1fx.OutputOwners{
+				Threshold: 2,
+				Addrs:     addrs,
+			},
+		},
+		{
+			name: "threshold defaults to all addresses",
+			options: []common.Option{
+				common.WithChangePolicy(common.ThresholdChange),
+			},
+			expectedAVAXOwner: &secp256k1fx.OutputOwners{
+				Threshold: 3,
+				Addrs:     addrs,
+			},
+			expectedAssetOwner: &secp256k1fx.OutputOwners{
+				Threshold: 3,
+				Addrs:     addrs,
+			},
+		},
+		{
+			name: "custom owner takes precedence over the policy",
+			options: []common.Option{
+				common.WithChangeOwner(customOwner),
+				common.WithChangePolicy(common.RotateChange),
+			},
+			expectedAVAXOwner:  customOwner,
+			expectedAssetOwner: customOwner,
+		},
+		{
+			name: "threshold larger than the number of addresses",
+			options: []common.Option{
+				common.WithChangePolicy(common.ThresholdChange),
+				common.WithChangeThreshold(4),
+			},
+			expectedErr: common.ErrInvalidChangeThreshold,
+		},
+		{
+			name: "zero threshold",
+			options: []common.Option{
+				common.WithChangePolicy(common.ThresholdChange),
+				common.WithChangeThreshold(0),
+			},
+			expectedErr: common.ErrInvalidChangeThreshold,
+		},
+		{
+			name: "unknown policy",
+			options: []common.Option{
+				common.WithChangePolicy(common.ThresholdChange + 1),
+			},
+			expectedErr: common.ErrUnknownChangePolicy,
+		},
+		{
+			name: "no addresses",
+			options: []common.Option{
+				common.WithCustomAddresses(set.Set[ids.ShortID]{}),
+			},
+			expectedErr: common.ErrNoChangeAddress,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			builder := NewBuilder(set.Of(addrs...), backend)
+			tx, err := builder.NewBaseTx(outputs, test.options...)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				return
+			}
+
+			require.Len(tx.Ins, 2)
+			changeOwners := make(map[ids.ID]*secp256k1fx.OutputOwners)
+			for _, out := range tx.Outs {
+				if out.Out.Amount() == units.MilliAvax {
+					// [out] is one of [outputs]
+					continue
+				}
+				transferOut, ok := out.Out.(*secp256k1fx.TransferOutput)
+				require.True(ok)
+				changeOwners[out.AssetID()] = &transferOut.OutputOwners
+			}
+			require.Equal(
+				map[ids.ID]*secp256k1fx.OutputOwners{
+					avaxAssetID:  test.expectedAVAXOwner,
+					otherAssetID: test.expectedAssetOwner,
+				},
+				changeOwners,
+			)
+		})
+	}
+}
+
+func newTestUTXO(assetID ids.ID, amount uint64, addr ids.ShortID) *avax.UTXO {
+	return &avax.UTXO{
+		UTXOID: avax.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: amount,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	}
+}
+
+func newTestOutput(assetID ids.ID, amount uint64) *avax.TransferableOutput {
+	return &avax.TransferableOutput{
+		Asset: avax.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: amount,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+			},
+		},
+	}
+}
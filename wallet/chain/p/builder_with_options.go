@@ -222,3 +222,17 @@ func (b *builderWithOptions) NewAddPermissionlessDelegatorTx(
 		common.UnionOptions(b.options, options)...,
 	)
 }
+
+func (b *builderWithOptions) NewClaimFeeRefundTx(
+	addr ids.ShortID,
+	amount uint64,
+	to *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.ClaimFeeRefundTx, error) {
+	return b.Builder.NewClaimFeeRefundTx(
+		addr,
+		amount,
+		to,
+		common.UnionOptions(b.options, options)...,
+	)
+}
@@ -30,6 +30,7 @@ var (
 	errUnknownCredentialType = errors.New("unknown credential type")
 	errUnknownOutputType     = errors.New("unknown output type")
 	errUnknownSubnetAuthType = errors.New("unknown subnet auth type")
+	errUnknownAddrsAuthType  = errors.New("unknown addresses auth type")
 	errInvalidUTXOSigIndex   = errors.New("invalid UTXO signature index")
 
 	emptySig [secp256k1.SignatureLen]byte
@@ -156,6 +157,40 @@ func (s *signerVisitor) TransferSubnetOwnershipTx(tx *txs.TransferSubnetOwnershi
 	return sign(s.tx, true, txSigners)
 }
 
+func (s *signerVisitor) RevokeAddressesTx(tx *txs.RevokeAddressesTx) error {
+	txSigners, err := s.getSigners(constants.PlatformChainID, tx.Ins)
+	if err != nil {
+		return err
+	}
+	addrsInput, ok := tx.AddrsAuth.(*secp256k1fx.Input)
+	if !ok {
+		return errUnknownAddrsAuthType
+	}
+	addrsAuthSigners, err := s.getOwnerSigners(tx.Owner(), addrsInput)
+	if err != nil {
+		return err
+	}
+	txSigners = append(txSigners, addrsAuthSigners)
+	return sign(s.tx, true, txSigners)
+}
+
+func (s *signerVisitor) ClaimFeeRefundTx(tx *txs.ClaimFeeRefundTx) error {
+	txSigners, err := s.getSigners(constants.PlatformChainID, tx.Ins)
+	if err != nil {
+		return err
+	}
+	addrInput, ok := tx.AddressAuth.(*secp256k1fx.Input)
+	if !ok {
+		return errUnknownAddrsAuthType
+	}
+	addrAuthSigners, err := s.getOwnerSigners(tx.Owner(), addrInput)
+	if err != nil {
+		return err
+	}
+	txSigners = append(txSigners, addrAuthSigners)
+	return sign(s.tx, true, txSigners)
+}
+
 func (s *signerVisitor) TransformSubnetTx(tx *txs.TransformSubnetTx) error {
 	txSigners, err := s.getSigners(constants.PlatformChainID, tx.Ins)
 	if err != nil {
@@ -263,9 +298,12 @@ func (s *signerVisitor) getSubnetSigners(subnetID ids.ID, subnetAuth verify.Veri
 	if !ok {
 		return nil, errUnknownOwnerType
 	}
+	return s.getOwnerSigners(owner, subnetInput)
+}
 
-	authSigners := make([]keychain.Signer, len(subnetInput.SigIndices))
-	for sigIndex, addrIndex := range subnetInput.SigIndices {
+func (s *signerVisitor) getOwnerSigners(owner *secp256k1fx.OutputOwners, input *secp256k1fx.Input) ([]keychain.Signer, error) {
+	authSigners := make([]keychain.Signer, len(input.SigIndices))
+	for sigIndex, addrIndex := range input.SigIndices {
 		if addrIndex >= uint32(len(owner.Addrs)) {
 			return nil, errInvalidUTXOSigIndex
 		}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/platformvm"
 	"github.com/ava-labs/avalanchego/vms/platformvm/signer"
@@ -232,6 +233,20 @@ type Wallet interface {
 		options ...common.Option,
 	) (*txs.Tx, error)
 
+	// IssueClaimFeeRefundTx creates, signs, and issues a claim of the fees
+	// that [addr] overpaid.
+	//
+	// - [addr] specifies the address whose refund is being claimed.
+	// - [amount] specifies the amount of the refund to claim.
+	// - [to] specifies where to send the claimed funds, after the tx fee is
+	//   deducted.
+	IssueClaimFeeRefundTx(
+		addr ids.ShortID,
+		amount uint64,
+		to *secp256k1fx.OutputOwners,
+		options ...common.Option,
+	) (*txs.Tx, error)
+
 	// IssueUnsignedTx signs and issues the unsigned tx.
 	IssueUnsignedTx(
 		utx txs.UnsignedTx,
@@ -465,6 +480,19 @@ func (w *wallet) IssueAddPermissionlessDelegatorTx(
 	return w.IssueUnsignedTx(utx, options...)
 }
 
+func (w *wallet) IssueClaimFeeRefundTx(
+	addr ids.ShortID,
+	amount uint64,
+	to *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	utx, err := w.builder.NewClaimFeeRefundTx(addr, amount, to, options...)
+	if err != nil {
+		return nil, err
+	}
+	return w.IssueUnsignedTx(utx, options...)
+}
+
 func (w *wallet) IssueUnsignedTx(
 	utx txs.UnsignedTx,
 	options ...common.Option,
@@ -485,6 +513,10 @@ func (w *wallet) IssueTx(
 ) error {
 	ops := common.NewOptions(options)
 	ctx := ops.Context()
+	if f := ops.PreIssuanceFunc(); f != nil {
+		f(constants.PlatformChainID, tx.Bytes())
+	}
+
 	txID, err := w.client.IssueTx(ctx, tx.Bytes())
 	if err != nil {
 		return err
@@ -218,6 +218,20 @@ func (w *walletWithOptions) IssueAddPermissionlessDelegatorTx(
 	)
 }
 
+func (w *walletWithOptions) IssueClaimFeeRefundTx(
+	addr ids.ShortID,
+	amount uint64,
+	to *secp256k1fx.OutputOwners,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	return w.Wallet.IssueClaimFeeRefundTx(
+		addr,
+		amount,
+		to,
+		common.UnionOptions(w.options, options)...,
+	)
+}
+
 func (w *walletWithOptions) IssueUnsignedTx(
 	utx txs.UnsignedTx,
 	options ...common.Option,
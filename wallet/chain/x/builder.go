@@ -23,7 +23,6 @@ import (
 )
 
 var (
-	errNoChangeAddress   = errors.New("no possible change address")
 	errInsufficientFunds = errors.New("insufficient funds")
 
 	_ Builder = (*builder)(nil)
@@ -542,14 +541,10 @@ func (b *builder) spend(
 	addrs := options.Addresses(b.addrs)
 	minIssuanceTime := options.MinIssuanceTime()
 
-	addr, ok := addrs.Peek()
-	if !ok {
-		return nil, nil, errNoChangeAddress
+	changeOwners, err := common.NewChangeOwners(options, addrs)
+	if err != nil {
+		return nil, nil, err
 	}
-	changeOwner := options.ChangeOwner(&secp256k1fx.OutputOwners{
-		Threshold: 1,
-		Addrs:     []ids.ShortID{addr},
-	})
 
 	// Iterate over the UTXOs
 	for _, utxo := range utxos {
@@ -598,7 +593,7 @@ func (b *builder) spend(
 				Asset: utxo.Asset,
 				Out: &secp256k1fx.TransferOutput{
 					Amt:          remainingAmount,
-					OutputOwners: *changeOwner,
+					OutputOwners: *changeOwners.Next(),
 				},
 			})
 		}
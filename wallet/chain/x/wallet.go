@@ -300,6 +300,10 @@ func (w *wallet) IssueTx(
 ) error {
 	ops := common.NewOptions(options)
 	ctx := ops.Context()
+	if f := ops.PreIssuanceFunc(); f != nil {
+		f(w.Backend.BlockchainID(), tx.Bytes())
+	}
+
 	txID, err := w.client.IssueTx(ctx, tx.Bytes())
 	if err != nil {
 		return err
@@ -0,0 +1,123 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
+)
+
+var (
+	ErrNoChangeAddress        = errors.New("no possible change address")
+	ErrInvalidChangeThreshold = errors.New("invalid change threshold")
+	ErrUnknownChangePolicy    = errors.New("unknown change policy")
+)
+
+// ChangePolicy determines the owners of the change outputs of a transaction.
+//
+// A custom owner provided with [WithChangeOwner] takes precedence over the
+// change policy.
+type ChangePolicy uint8
+
+const (
+	// ConsolidateChange sends every change output to the same address, the
+	// lowest of the addresses the wallet controls. This is the default.
+	ConsolidateChange ChangePolicy = iota
+	// RotateChange sends each change output of a transaction to the next of
+	// the addresses the wallet controls, in sorted order, so that no address
+	// receives more than one change output unless there are more outputs than
+	// addresses.
+	RotateChange
+	// ThresholdChange sends every change output to an owner made up of all
+	// the addresses the wallet controls, requiring the threshold provided
+	// with [WithChangeThreshold] to spend. If no threshold is provided, all
+	// the addresses must sign.
+	ThresholdChange
+)
+
+func (p ChangePolicy) String() string {
+	switch p {
+	case ConsolidateChange:
+		return "consolidate"
+	case RotateChange:
+		return "rotate"
+	case ThresholdChange:
+		return "threshold"
+	default:
+		return fmt.Sprintf("unknown(%d)", p)
+	}
+}
+
+// ChangeOwners returns the owners of the change outputs of a transaction,
+// according to the change policy.
+type ChangeOwners struct {
+	owners []*secp256k1fx.OutputOwners
+	next   int
+}
+
+// NewChangeOwners returns the owners of the change outputs of a transaction
+// built with [options] by a wallet that controls [addrs].
+func NewChangeOwners(options *Options, addrs set.Set[ids.ShortID]) (*ChangeOwners, error) {
+	if addrs.Len() == 0 {
+		return nil, ErrNoChangeAddress
+	}
+	if changeOwner := options.ChangeOwner(nil); changeOwner != nil {
+		return &ChangeOwners{
+			owners: []*secp256k1fx.OutputOwners{changeOwner},
+		}, nil
+	}
+
+	sortedAddrs := addrs.List()
+	utils.Sort(sortedAddrs)
+
+	switch policy := options.ChangePolicy(); policy {
+	case ConsolidateChange:
+		return &ChangeOwners{
+			owners: []*secp256k1fx.OutputOwners{{
+				Threshold: 1,
+				Addrs:     sortedAddrs[:1],
+			}},
+		}, nil
+	case RotateChange:
+		owners := make([]*secp256k1fx.OutputOwners, len(sortedAddrs))
+		for i, addr := range sortedAddrs {
+			owners[i] = &secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			}
+		}
+		return &ChangeOwners{
+			owners: owners,
+		}, nil
+	case ThresholdChange:
+		threshold := options.ChangeThreshold(uint32(len(sortedAddrs)))
+		if threshold == 0 || int(threshold) > len(sortedAddrs) {
+			return nil, fmt.Errorf("%w: %d of %d addresses",
+				ErrInvalidChangeThreshold,
+				threshold,
+				len(sortedAddrs),
+			)
+		}
+		return &ChangeOwners{
+			owners: []*secp256k1fx.OutputOwners{{
+				Threshold: threshold,
+				Addrs:     sortedAddrs,
+			}},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownChangePolicy, policy)
+	}
+}
+
+// Next returns the owner of the next change output.
+func (c *ChangeOwners) Next() *secp256k1fx.OutputOwners {
+	owner := c.owners[c.next]
+	c.next = (c.next + 1) % len(c.owners)
+	return owner
+}
@@ -17,6 +17,11 @@ import (
 
 const defaultPollFrequency = 100 * time.Millisecond
 
+// Signature of the function that will be called before a transaction is
+// issued with the ID of the chain it's issued to and the bytes of the
+// transaction.
+type PreIssuanceFunc func(chainID ids.ID, txBytes []byte)
+
 // Signature of the function that will be called after a transaction
 // has been issued with the ID of the issued transaction.
 type PostIssuanceFunc func(ids.ID)
@@ -41,6 +46,10 @@ type Options struct {
 
 	changeOwner *secp256k1fx.OutputOwners
 
+	changePolicy       ChangePolicy
+	changeThresholdSet bool
+	changeThreshold    uint32
+
 	memo []byte
 
 	assumeDecided bool
@@ -48,6 +57,7 @@ type Options struct {
 	pollFrequencySet bool
 	pollFrequency    time.Duration
 
+	preIssuanceFunc  PreIssuanceFunc
 	postIssuanceFunc PostIssuanceFunc
 }
 
@@ -117,6 +127,17 @@ func (o *Options) ChangeOwner(defaultOwner *secp256k1fx.OutputOwners) *secp256k1
 	return defaultOwner
 }
 
+func (o *Options) ChangePolicy() ChangePolicy {
+	return o.changePolicy
+}
+
+func (o *Options) ChangeThreshold(defaultThreshold uint32) uint32 {
+	if o.changeThresholdSet {
+		return o.changeThreshold
+	}
+	return defaultThreshold
+}
+
 func (o *Options) Memo() []byte {
 	return o.memo
 }
@@ -132,6 +153,10 @@ func (o *Options) PollFrequency() time.Duration {
 	return defaultPollFrequency
 }
 
+func (o *Options) PreIssuanceFunc() PreIssuanceFunc {
+	return o.preIssuanceFunc
+}
+
 func (o *Options) PostIssuanceFunc() PostIssuanceFunc {
 	return o.postIssuanceFunc
 }
@@ -181,6 +206,21 @@ func WithChangeOwner(changeOwner *secp256k1fx.OutputOwners) Option {
 	}
 }
 
+func WithChangePolicy(policy ChangePolicy) Option {
+	return func(o *Options) {
+		o.changePolicy = policy
+	}
+}
+
+// WithChangeThreshold sets the number of addresses that must sign to spend
+// change outputs created with the [ThresholdChange] policy.
+func WithChangeThreshold(threshold uint32) Option {
+	return func(o *Options) {
+		o.changeThresholdSet = true
+		o.changeThreshold = threshold
+	}
+}
+
 func WithMemo(memo []byte) Option {
 	return func(o *Options) {
 		o.memo = memo
@@ -200,6 +240,12 @@ func WithPollFrequency(pollFrequency time.Duration) Option {
 	}
 }
 
+func WithPreIssuanceFunc(f PreIssuanceFunc) Option {
+	return func(o *Options) {
+		o.preIssuanceFunc = f
+	}
+}
+
 func WithPostIssuanceFunc(f PostIssuanceFunc) Option {
 	return func(o *Options) {
 		o.postIssuanceFunc = f
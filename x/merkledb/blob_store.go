@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/perms"
+)
+
+var (
+	_ database.KeyValueReaderWriter = (*fileBlobStore)(nil)
+
+	errMissingBlob = errors.New("missing blob")
+)
+
+// fileBlobStore stores each blob in its own file, named after the hex encoding
+// of the blob's key.
+type fileBlobStore struct {
+	dir string
+}
+
+// NewFileBlobStore returns a blob store, suitable for [Config.BlobStore], that
+// stores blobs as files in [dir]. [dir] is created if it doesn't exist.
+func NewFileBlobStore(dir string) (database.KeyValueReaderWriter, error) {
+	if err := os.MkdirAll(dir, perms.ReadWriteExecute); err != nil {
+		return nil, err
+	}
+	return &fileBlobStore{
+		dir: dir,
+	}, nil
+}
+
+func (s *fileBlobStore) Has(key []byte) (bool, error) {
+	_, err := os.Stat(s.path(key))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (s *fileBlobStore) Get(key []byte) ([]byte, error) {
+	blob, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, database.ErrNotFound
+	}
+	return blob, err
+}
+
+// Put atomically writes [value] to the file of [key].
+func (s *fileBlobStore) Put(key []byte, value []byte) error {
+	return perms.WriteFile(s.path(key), value, perms.ReadWrite)
+}
+
+func (s *fileBlobStore) path(key []byte) string {
+	return filepath.Join(s.dir, hex.EncodeToString(key))
+}
+
+// Returns true iff the value of [n] should be stored in [blobStore] rather
+// than in the node itself.
+func isBlobValue(n *node, blobStore database.KeyValueReaderWriter, blobValueThreshold int) bool {
+	return blobStore != nil && n != nil && n.hasValue() && len(n.value.Value()) > blobValueThreshold
+}
+
+// Writes the value of [n] to [blobStore], keyed by its hash, and returns the
+// bytes of [n] with its value replaced by that hash.
+// Since blobs are keyed by their hash, a blob that is already in [blobStore]
+// isn't rewritten.
+func putBlobValue(n *node, blobStore database.KeyValueReaderWriter) ([]byte, error) {
+	value := n.value.Value()
+	blobID := hashing.ComputeHash256Array(value)
+	hasBlob, err := blobStore.Has(blobID[:])
+	if err != nil {
+		return nil, err
+	}
+	if !hasBlob {
+		if err := blobStore.Put(blobID[:], value); err != nil {
+			return nil, err
+		}
+	}
+	return codec.encodeBlobValueDBNode(&n.dbNode, blobID), nil
+}
+
+// Replaces the value of [n], which is the ID of a blob, with the blob from
+// [blobStore].
+// Returns [ErrCorrupted] if the blob doesn't match its ID.
+func resolveBlobValue(n *node, blobStore database.KeyValueReaderWriter) error {
+	if blobStore == nil {
+		return fmt.Errorf("%w: node %x references a blob but no blob store is configured", errMissingBlob, n.key.Bytes())
+	}
+	blobID, err := ids.ToID(n.value.Value())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrCorrupted, err)
+	}
+	blob, err := blobStore.Get(blobID[:])
+	if err != nil {
+		return fmt.Errorf("%w %s: %w", errMissingBlob, blobID, err)
+	}
+	if hashing.ComputeHash256Array(blob) != blobID {
+		return fmt.Errorf("%w: blob doesn't match its ID %s", ErrCorrupted, blobID)
+	}
+	n.setValue(maybe.Some(blob))
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+func TestFileBlobStore(t *testing.T) {
+	require := require.New(t)
+
+	store, err := NewFileBlobStore(t.TempDir())
+	require.NoError(err)
+
+	key := []byte{1, 2, 3}
+	has, err := store.Has(key)
+	require.NoError(err)
+	require.False(has)
+
+	_, err = store.Get(key)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(store.Put(key, []byte{4}))
+
+	has, err = store.Has(key)
+	require.NoError(err)
+	require.True(has)
+
+	value, err := store.Get(key)
+	require.NoError(err)
+	require.Equal([]byte{4}, value)
+}
+
+func TestBlobValues(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB     = memdb.New()
+		blobStore  = memdb.New()
+		smallKey   = []byte{1}
+		smallValue = []byte{2}
+		largeKey   = []byte{3}
+		largeValue = make([]byte, units.MiB)
+	)
+	for i := range largeValue {
+		largeValue[i] = byte(i)
+	}
+
+	config := newDefaultConfig()
+	config.BlobStore = blobStore
+	config.BlobValueThreshold = 64
+	db, err := newDatabase(
+		context.Background(),
+		baseDB,
+		config,
+		&mockMetrics{},
+	)
+	require.NoError(err)
+
+	require.NoError(db.Put(smallKey, smallValue))
+	require.NoError(db.Put(largeKey, largeValue))
+
+	// Storing values as blobs doesn't change the root.
+	expectedDB, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(expectedDB.Put(smallKey, smallValue))
+	require.NoError(expectedDB.Put(largeKey, largeValue))
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	expectedRoot, err := expectedDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+
+	// Only the large value is stored as a blob.
+	blobID := hashing.ComputeHash256Array(largeValue)
+	blob, err := blobStore.Get(blobID[:])
+	require.NoError(err)
+	require.Equal(largeValue, blob)
+
+	smallBlobID := hashing.ComputeHash256Array(smallValue)
+	has, err := blobStore.Has(smallBlobID[:])
+	require.NoError(err)
+	require.False(has)
+
+	largeNodeBytes, err := baseDB.Get(append(slices.Clone(valueNodePrefix), largeKey...))
+	require.NoError(err)
+	require.Less(len(largeNodeBytes), int(config.BlobValueThreshold))
+
+	// Blob values aren't cached.
+	_, ok := db.valueNodeDB.nodeCache.Get(ToKey(largeKey))
+	require.False(ok)
+
+	// Blob values are resolved when read.
+	db.valueNodeDB.nodeCache.Flush()
+	values, errs := db.GetValues(context.Background(), [][]byte{smallKey, largeKey})
+	require.Equal([]error{nil, nil}, errs)
+	require.Equal([][]byte{smallValue, largeValue}, values)
+
+	it := db.NewIterator()
+	require.True(it.Next())
+	require.Equal(smallValue, it.Value())
+	require.True(it.Next())
+	require.Equal(largeValue, it.Value())
+	require.False(it.Next())
+	require.NoError(it.Error())
+	it.Release()
+
+	// A blob that doesn't match its ID is reported as corrupted.
+	require.NoError(blobStore.Put(blobID[:], smallValue))
+	_, err = db.Get(largeKey)
+	require.ErrorIs(err, ErrCorrupted)
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+const checksumLen = crc32.Size
+
+var (
+	// ErrCorrupted is returned when a node read from disk doesn't match its
+	// checksum. It's distinct from [database.ErrNotFound], which is returned
+	// when a node isn't on disk at all.
+	ErrCorrupted = errors.New("node is corrupted")
+
+	checksumTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// Returns a copy of [nodeBytes] followed by its CRC32C checksum.
+func appendChecksum(nodeBytes []byte) []byte {
+	b := make([]byte, len(nodeBytes)+checksumLen)
+	copy(b, nodeBytes)
+	binary.BigEndian.PutUint32(b[len(nodeBytes):], crc32.Checksum(nodeBytes, checksumTable))
+	return b
+}
+
+// Returns [b] without the checksum appended by [appendChecksum].
+// Returns [ErrCorrupted] if the checksum doesn't match.
+func verifyChecksum(b []byte) ([]byte, error) {
+	if len(b) < checksumLen {
+		return nil, fmt.Errorf("%w: %d bytes is shorter than the checksum", ErrCorrupted, len(b))
+	}
+	var (
+		nodeBytes = b[:len(b)-checksumLen]
+		expected  = binary.BigEndian.Uint32(b[len(nodeBytes):])
+		actual    = crc32.Checksum(nodeBytes, checksumTable)
+	)
+	if expected != actual {
+		return nil, fmt.Errorf("%w: expected checksum %x but got %x", ErrCorrupted, expected, actual)
+	}
+	return nodeBytes, nil
+}
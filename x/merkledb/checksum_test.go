@@ -0,0 +1,123 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+func TestChecksum(t *testing.T) {
+	require := require.New(t)
+
+	nodeBytes := []byte{1, 2, 3}
+	b := appendChecksum(nodeBytes)
+	require.Len(b, len(nodeBytes)+checksumLen)
+
+	parsedBytes, err := verifyChecksum(b)
+	require.NoError(err)
+	require.Equal(nodeBytes, parsedBytes)
+
+	for i := range b {
+		corrupted := appendChecksum(nodeBytes)
+		corrupted[i] ^= 1
+		_, err := verifyChecksum(corrupted)
+		require.ErrorIs(err, ErrCorrupted)
+	}
+
+	_, err = verifyChecksum(b[:checksumLen-1])
+	require.ErrorIs(err, ErrCorrupted)
+}
+
+func TestNodeChecksumsDetectCorruption(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	config.NodeChecksums = true
+	db, err := newDatabase(
+		context.Background(),
+		baseDB,
+		config,
+		&mockMetrics{},
+	)
+	require.NoError(err)
+
+	key := []byte{1}
+	require.NoError(db.Put(key, []byte{2}))
+
+	// Force the node to be read from disk.
+	db.valueNodeDB.nodeCache.Flush()
+
+	value, err := db.Get(key)
+	require.NoError(err)
+	require.Equal([]byte{2}, value)
+
+	db.valueNodeDB.nodeCache.Flush()
+
+	// Flip a bit of the node on disk.
+	dbKey := append(slices.Clone(valueNodePrefix), key...)
+	nodeBytes, err := baseDB.Get(dbKey)
+	require.NoError(err)
+	nodeBytes[0] ^= 1
+	require.NoError(baseDB.Put(dbKey, nodeBytes))
+
+	_, err = db.Get(key)
+	require.ErrorIs(err, ErrCorrupted)
+
+	// Missing nodes are still reported as missing.
+	_, err = db.Get([]byte{3})
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestIntermediateNodeChecksumsDetectCorruption(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := newIntermediateNodeDB(
+		baseDB,
+		&sync.Pool{
+			New: func() interface{} { return make([]byte, 0) },
+		},
+		&mockMetrics{},
+		1000,
+		1000,
+		1000,
+		4,
+		true,
+		nil,
+	)
+
+	key := ToKey([]byte{1})
+	n := newNode(key)
+	n.setValue(maybe.Some([]byte{2}))
+	require.NoError(db.Put(key, n))
+	require.NoError(db.Flush())
+
+	dbKey := db.constructDBKey(key)
+	nodeBytes, err := baseDB.Get(dbKey)
+	require.NoError(err)
+	require.Len(nodeBytes, len(n.bytes())+checksumLen)
+
+	readNode, err := db.Get(key)
+	require.NoError(err)
+	require.Equal(n.value, readNode.value)
+
+	nodeBytes[len(nodeBytes)-1] ^= 1
+	require.NoError(baseDB.Put(dbKey, nodeBytes))
+
+	// Read the node from the database, not the cache.
+	db.nodeCache.Flush()
+	_, err = db.Get(key)
+	require.ErrorIs(err, ErrCorrupted)
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"sync"
@@ -15,9 +16,27 @@ import (
 	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 )
 
+// Versions of the serialized format of a dbNode.
+//
+// dbNodes serialized before the format was versioned aren't prefixed with a
+// version. Since those dbNodes begin with a bool, version prefixes must never
+// be [falseByte] or [trueByte].
+const (
+	// Reported for dbNodes that aren't prefixed with a version.
+	unversionedDBNode byte = 0
+	dbNodeVersion1    byte = 2
+	// Same layout as [dbNodeVersion1], except that the value is the ID of a
+	// blob that holds the actual value.
+	blobValueDBNodeVersion byte = 3
+
+	// Version that dbNodes are serialized with.
+	currentDBNodeVersion = dbNodeVersion1
+)
+
 const (
 	boolLen              = 1
 	trueByte             = 1
@@ -28,6 +47,7 @@ const (
 	minByteSliceLen      = minVarIntLen
 	minDBNodeLen         = minMaybeByteSliceLen + minVarIntLen
 	minChildLen          = minVarIntLen + minKeyLen + ids.IDLen + boolLen
+	minWitnessNodeLen    = minKeyLen + minByteSliceLen
 
 	estimatedKeyLen           = 64
 	estimatedValueLen         = 64
@@ -44,12 +64,14 @@ var (
 	trueBytes  = []byte{trueByte}
 	falseBytes = []byte{falseByte}
 
-	errChildIndexTooLarge = errors.New("invalid child index. Must be less than branching factor")
-	errLeadingZeroes      = errors.New("varint has leading zeroes")
-	errInvalidBool        = errors.New("decoded bool is neither true nor false")
-	errNonZeroKeyPadding  = errors.New("key partial byte should be padded with 0s")
-	errExtraSpace         = errors.New("trailing buffer space")
-	errIntOverflow        = errors.New("value overflows int")
+	errChildIndexTooLarge   = errors.New("invalid child index. Must be less than branching factor")
+	errLeadingZeroes        = errors.New("varint has leading zeroes")
+	errInvalidBool          = errors.New("decoded bool is neither true nor false")
+	errNonZeroKeyPadding    = errors.New("key partial byte should be padded with 0s")
+	errExtraSpace           = errors.New("trailing buffer space")
+	errIntOverflow          = errors.New("value overflows int")
+	errWitnessKeysUnsorted  = errors.New("witness keys not sorted and unique")
+	errUnknownDBNodeVersion = errors.New("unknown db node version")
 )
 
 // encoderDecoder defines the interface needed by merkleDB to marshal
@@ -63,14 +85,29 @@ type encoder interface {
 	// Assumes [n] is non-nil.
 	encodeDBNode(n *dbNode) []byte
 
+	// Encodes [n] with its value replaced by [blobID], the ID of the blob
+	// that holds its value.
+	// Assumes [n] is non-nil.
+	encodeBlobValueDBNode(n *dbNode, blobID ids.ID) []byte
+
 	// Returns the bytes that will be hashed to generate [n]'s ID.
 	// Assumes [n] is non-nil.
 	encodeHashValues(n *node) []byte
+
+	// Returns [n]'s ID, which is the hash of [encodeHashValues].
+	// Assumes [n] is non-nil.
+	hashValues(n *node) ids.ID
+
+	// Assumes [w] is non-nil.
+	encodeWitness(w *Witness) []byte
 }
 
 type decoder interface {
 	// Assumes [n] is non-nil.
 	decodeDBNode(bytes []byte, n *dbNode) error
+
+	// Assumes [w] is non-nil.
+	decodeWitness(bytes []byte, w *Witness) error
 }
 
 func newCodec() encoderDecoder {
@@ -80,6 +117,11 @@ func newCodec() encoderDecoder {
 				return make([]byte, binary.MaxVarintLen64)
 			},
 		},
+		hashBufferPool: sync.Pool{
+			New: func() interface{} {
+				return &bytes.Buffer{}
+			},
+		},
 	}
 }
 
@@ -89,16 +131,31 @@ type codecImpl struct {
 	// Invariant: Every byte slice returned by [varIntPool] has
 	// length [binary.MaxVarintLen64].
 	varIntPool sync.Pool
+	// Buffers used by [hashValues].
+	// Invariant: Every buffer returned by [hashBufferPool] is empty.
+	hashBufferPool sync.Pool
 }
 
 func (c *codecImpl) encodeDBNode(n *dbNode) []byte {
+	return c.encodeVersionedDBNode(currentDBNodeVersion, n)
+}
+
+func (c *codecImpl) encodeBlobValueDBNode(n *dbNode, blobID ids.ID) []byte {
+	return c.encodeVersionedDBNode(blobValueDBNodeVersion, &dbNode{
+		value:    maybe.Some(blobID[:]),
+		children: n.children,
+	})
+}
+
+func (c *codecImpl) encodeVersionedDBNode(version byte, n *dbNode) []byte {
 	var (
 		numChildren = len(n.children)
 		// Estimate size of [n] to prevent memory allocations
-		estimatedLen = estimatedValueLen + minVarIntLen + estimatedNodeChildLen*numChildren
+		estimatedLen = 1 + estimatedValueLen + minVarIntLen + estimatedNodeChildLen*numChildren
 		buf          = bytes.NewBuffer(make([]byte, 0, estimatedLen))
 	)
 
+	_ = buf.WriteByte(version)
 	c.encodeMaybeByteSlice(buf, n.value)
 	c.encodeUint(buf, uint64(numChildren))
 	// Note we insert children in order of increasing index
@@ -123,6 +180,21 @@ func (c *codecImpl) encodeHashValues(n *node) []byte {
 		buf          = bytes.NewBuffer(make([]byte, 0, estimatedLen))
 	)
 
+	c.encodeHashValuesInto(buf, n)
+	return buf.Bytes()
+}
+
+func (c *codecImpl) hashValues(n *node) ids.ID {
+	buf := c.hashBufferPool.Get().(*bytes.Buffer)
+	c.encodeHashValuesInto(buf, n)
+	id := hashing.ComputeHash256Array(buf.Bytes())
+	buf.Reset()
+	c.hashBufferPool.Put(buf)
+	return id
+}
+
+func (c *codecImpl) encodeHashValuesInto(buf *bytes.Buffer, n *node) {
+	numChildren := len(n.children)
 	c.encodeUint(buf, uint64(numChildren))
 
 	// ensure that the order of entries is consistent
@@ -135,11 +207,17 @@ func (c *codecImpl) encodeHashValues(n *node) []byte {
 	}
 	c.encodeMaybeByteSlice(buf, n.valueDigest)
 	c.encodeKey(buf, n.key)
-
-	return buf.Bytes()
 }
 
 func (c *codecImpl) decodeDBNode(b []byte, n *dbNode) error {
+	switch version := dbNodeVersion(b); version {
+	case unversionedDBNode:
+	case dbNodeVersion1, blobValueDBNodeVersion:
+		b = b[1:]
+	default:
+		return fmt.Errorf("%w: %d", errUnknownDBNodeVersion, version)
+	}
+
 	if minDBNodeLen > len(b) {
 		return io.ErrUnexpectedEOF
 	}
@@ -196,6 +274,80 @@ func (c *codecImpl) decodeDBNode(b []byte, n *dbNode) error {
 	return nil
 }
 
+// Returns the version of the serialized dbNode [b], or [unversionedDBNode] if
+// [b] isn't prefixed with a version.
+func dbNodeVersion(b []byte) byte {
+	if len(b) == 0 || b[0] == falseByte || b[0] == trueByte {
+		return unversionedDBNode
+	}
+	return b[0]
+}
+
+func (c *codecImpl) encodeWitness(w *Witness) []byte {
+	var (
+		numNodes = len(w.nodes)
+		// Estimate size of [w] to prevent memory allocations
+		estimatedLen = minVarIntLen + numNodes*(estimatedKeyLen+estimatedValueLen)
+		buf          = bytes.NewBuffer(make([]byte, 0, estimatedLen))
+	)
+
+	c.encodeUint(buf, uint64(numNodes))
+	// Note we insert nodes in order of increasing key
+	// for determinism.
+	keys := maps.Keys(w.nodes)
+	slices.SortFunc(keys, func(a, b Key) bool {
+		return a.Less(b)
+	})
+	for _, key := range keys {
+		c.encodeKey(buf, key)
+		c.encodeByteSlice(buf, w.nodes[key].bytes())
+	}
+	return buf.Bytes()
+}
+
+func (c *codecImpl) decodeWitness(b []byte, w *Witness) error {
+	if minVarIntLen > len(b) {
+		return io.ErrUnexpectedEOF
+	}
+
+	src := bytes.NewReader(b)
+
+	numNodes, err := c.decodeUint(src)
+	switch {
+	case err != nil:
+		return err
+	case numNodes > uint64(src.Len()/minWitnessNodeLen):
+		return io.ErrUnexpectedEOF
+	}
+
+	w.nodes = make(map[Key]*node, numNodes)
+	var previousKey Key
+	for i := uint64(0); i < numNodes; i++ {
+		key, err := c.decodeKey(src)
+		if err != nil {
+			return err
+		}
+		if i != 0 && !previousKey.Less(key) {
+			return errWitnessKeysUnsorted
+		}
+		previousKey = key
+
+		nodeBytes, err := c.decodeByteSlice(src)
+		if err != nil {
+			return err
+		}
+		n, err := parseNode(key, nodeBytes)
+		if err != nil {
+			return err
+		}
+		w.nodes[key] = n
+	}
+	if src.Len() != 0 {
+		return errExtraSpace
+	}
+	return nil
+}
+
 func (*codecImpl) encodeBool(dst *bytes.Buffer, value bool) {
 	bytesValue := falseBytes
 	if value {
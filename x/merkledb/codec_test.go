@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 )
 
@@ -113,7 +114,11 @@ func FuzzCodecDBNodeCanonical(f *testing.F) {
 				t.SkipNow()
 			}
 
-			// Encoding [node] should be the same as [b].
+			// Encoding [node] should be the same as [b], migrated to the
+			// current version if [b] isn't versioned.
+			if dbNodeVersion(b) == unversionedDBNode {
+				b = append([]byte{currentDBNodeVersion}, b...)
+			}
 			buf := codec.encodeDBNode(node)
 			require.Equal(b, buf)
 		},
@@ -188,6 +193,17 @@ func TestCodecDecodeDBNode_TooShort(t *testing.T) {
 	require.ErrorIs(err, io.ErrUnexpectedEOF)
 }
 
+func TestCodecDecodeDBNode_UnknownVersion(t *testing.T) {
+	require := require.New(t)
+
+	nodeBytes := codec.encodeDBNode(&dbNode{})
+	nodeBytes[0] = blobValueDBNodeVersion + 1
+
+	var parsedDBNode dbNode
+	err := codec.decodeDBNode(nodeBytes, &parsedDBNode)
+	require.ErrorIs(err, errUnknownDBNodeVersion)
+}
+
 // Ensure that encodeHashValues is deterministic
 func FuzzEncodeHashValues(f *testing.F) {
 	codec1 := newCodec()
@@ -241,6 +257,9 @@ func FuzzEncodeHashValues(f *testing.F) {
 
 				// Make sure they're the same
 				require.Equal(hvBytes1, hvBytes2)
+
+				// Make sure the ID is the hash of the serialized bytes
+				require.Equal(ids.ID(hashing.ComputeHash256Array(hvBytes1)), codec1.hashValues(hv))
 			}
 		},
 	)
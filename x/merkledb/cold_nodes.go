@@ -0,0 +1,491 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	defaultColdNodeMigrationBatchSize = 1024
+	defaultColdNodeRequestTimeout     = 30 * time.Second
+)
+
+var (
+	// Holds the hash of each intermediate node that was migrated to the
+	// object store, keyed by the node's key in [intermediateNodePrefix].
+	coldNodeIndexPrefix = []byte{4}
+	// Holds the commit in which each intermediate node was last written to
+	// disk, keyed by the node's key in [intermediateNodePrefix].
+	nodeWriteCommitPrefix = []byte{5}
+	// Holds an empty value for each write of an intermediate node, keyed by
+	// the commit it was written in followed by the node's key in
+	// [intermediateNodePrefix], so that the nodes that were written the
+	// longest ago are iterated first.
+	coldNodeQueuePrefix = []byte{6}
+
+	coldNodeCommitKey = []byte(string(metadataPrefix) + "coldNodeCommit")
+
+	errInvalidColdNodeAge = errors.New("cold node age must be positive")
+	errMissingColdNode    = errors.New("missing cold node")
+)
+
+// ObjectStore is the subset of an S3-compatible object store used to hold
+// cold nodes. See [Config.ColdNodeStore].
+type ObjectStore interface {
+	// GetObject returns the object stored under [key].
+	// Returns [database.ErrNotFound] if there is no such object.
+	GetObject(ctx context.Context, key string) ([]byte, error)
+
+	// PutObject stores [value] under [key], replacing any existing object.
+	PutObject(ctx context.Context, key string, value []byte) error
+
+	// DeleteObject deletes the object stored under [key], if any.
+	DeleteObject(ctx context.Context, key string) error
+}
+
+// coldNodeStore migrates intermediate nodes that weren't written to disk in
+// the last [age] commits to [objectStore].
+//
+// Migrated nodes are removed from [intermediateNodePrefix] and recorded in
+// [coldNodeIndexPrefix], along with their hash, so that only nodes that are
+// known to be cold are requested from [objectStore] and nodes that don't
+// match their hash are rejected.
+//
+// Nodes are migrated in the background, so that commits aren't delayed by
+// requests to [objectStore] and a failed migration doesn't fail a commit.
+type coldNodeStore struct {
+	baseDB      database.Database
+	objectStore ObjectStore
+
+	// The number of commits after which a node that wasn't written is
+	// migrated.
+	age uint64
+	// The maximum number of writes processed by a single migration, so that
+	// nodes are removed from [baseDB] in bounded batches.
+	migrationBatchSize int
+	// The maximum duration of each request to [objectStore].
+	requestTimeout time.Duration
+
+	// Held while intermediate nodes are written to [baseDB] and while
+	// migrated nodes are removed from it, so that a node that's written
+	// after it was uploaded isn't removed.
+	lock sync.Mutex
+	// The number of commits so far. Persisted under [coldNodeCommitKey].
+	commit uint64
+
+	// Held by [migrate], so that a node is only migrated once.
+	migrateLock sync.Mutex
+	// Signals [migrateLoop] that a commit finished.
+	migrateSignal chan struct{}
+	// Canceled when the store is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// Closed when [migrateLoop] returns.
+	done chan struct{}
+
+	// Holds nodes read from [objectStore]. It's separate from the cache of
+	// intermediate nodes so that reading historical nodes doesn't evict the
+	// nodes that are read the most.
+	nodeCache cache.Cacher[Key, *node]
+}
+
+func newColdNodeStore(
+	db database.Database,
+	objectStore ObjectStore,
+	age uint64,
+	migrationBatchSize int,
+	requestTimeout time.Duration,
+	cacheSize int,
+) (*coldNodeStore, error) {
+	if age == 0 {
+		return nil, errInvalidColdNodeAge
+	}
+	if migrationBatchSize == 0 {
+		migrationBatchSize = defaultColdNodeMigrationBatchSize
+	}
+	if requestTimeout == 0 {
+		requestTimeout = defaultColdNodeRequestTimeout
+	}
+	commit, err := database.GetUInt64(db, coldNodeCommitKey)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &coldNodeStore{
+		baseDB:             db,
+		objectStore:        objectStore,
+		age:                age,
+		migrationBatchSize: migrationBatchSize,
+		requestTimeout:     requestTimeout,
+		commit:             commit,
+		migrateSignal:      make(chan struct{}, 1),
+		ctx:                ctx,
+		cancel:             cancel,
+		done:               make(chan struct{}),
+		nodeCache:          cache.NewSizedLRU(cacheSize, cacheEntrySize),
+	}, nil
+}
+
+// start starts migrating nodes in the background. [close] must be called to
+// stop.
+func (s *coldNodeStore) start() {
+	go s.migrateLoop()
+}
+
+// close stops migrating nodes and waits for the current migration to return.
+func (s *coldNodeStore) close() {
+	s.cancel()
+	<-s.done
+}
+
+// startCommit starts a new commit. Nodes written after this call are
+// recorded as written in the new commit.
+// It must be called before any changes of the commit are written, so that
+// the commit's changes aren't written if it fails.
+func (s *coldNodeStore) startCommit() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := database.PutUInt64(s.baseDB, coldNodeCommitKey, s.commit+1); err != nil {
+		return err
+	}
+	s.commit++
+	return nil
+}
+
+// finishCommit signals that a commit was written, so nodes that became cold
+// can be migrated.
+func (s *coldNodeStore) finishCommit() {
+	select {
+	case s.migrateSignal <- struct{}{}:
+	default:
+	}
+}
+
+// recordWrite adds to [b] the operations recording that the node with the
+// given [key] and [dbKey] was written to disk, or deleted if [deleted].
+// Returns true if the node was cold, in which case its object must be deleted
+// with [deleteObject] once [b] is written.
+// Assumes [s.lock] is held until [b] is written.
+func (s *coldNodeStore) recordWrite(b database.Batch, key Key, dbKey []byte, deleted bool) (bool, error) {
+	s.nodeCache.Evict(key)
+
+	indexKey := prefixedKey(coldNodeIndexPrefix, dbKey)
+	wasCold, err := s.baseDB.Has(indexKey)
+	if err != nil {
+		return false, err
+	}
+	if wasCold {
+		if err := b.Delete(indexKey); err != nil {
+			return false, err
+		}
+	}
+
+	writeCommitKey := prefixedKey(nodeWriteCommitPrefix, dbKey)
+	if deleted {
+		return wasCold, b.Delete(writeCommitKey)
+	}
+	if err := database.PutUInt64(b, writeCommitKey, s.commit); err != nil {
+		return false, err
+	}
+	return wasCold, b.Put(coldNodeQueueKey(s.commit, dbKey), nil)
+}
+
+// deleteObject deletes the object of the node with the given [dbKey]. It's
+// only called after the node was removed from [coldNodeIndexPrefix], so an
+// object that fails to be deleted is never read.
+func (s *coldNodeStore) deleteObject(dbKey []byte) error {
+	// Not canceled by [close], so that the objects of nodes that are flushed
+	// when the database is closed are still deleted.
+	ctx, cancel := context.WithTimeout(context.Background(), s.requestTimeout)
+	defer cancel()
+
+	return s.objectStore.DeleteObject(ctx, coldNodeObjectKey(dbKey))
+}
+
+// Get returns the bytes of the node with the given [dbKey] from
+// [s.objectStore], or [database.ErrNotFound] if the node isn't cold.
+// Returns [ErrCorrupted] if the bytes don't match the hash recorded when the
+// node was migrated.
+func (s *coldNodeStore) Get(ctx context.Context, dbKey []byte) ([]byte, error) {
+	expectedHash, err := s.baseDB.Get(prefixedKey(coldNodeIndexPrefix, dbKey))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+
+	nodeBytes, err := s.objectStore.GetObject(ctx, coldNodeObjectKey(dbKey))
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %x", errMissingColdNode, dbKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hashing.ComputeHash256(nodeBytes), expectedHash) {
+		return nil, fmt.Errorf("%w: cold node %x doesn't match its hash", ErrCorrupted, dbKey)
+	}
+	return nodeBytes, nil
+}
+
+// migrateLoop migrates nodes after each commit until [s.ctx] is canceled.
+// A migration that fails leaves its nodes queued, so they're migrated after
+// a later commit.
+func (s *coldNodeStore) migrateLoop() {
+	defer close(s.done)
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.migrateSignal:
+		}
+
+		// Keep migrating while there's a backlog of cold nodes.
+		for {
+			processed, err := s.migrate(s.ctx)
+			if err != nil || processed < s.migrationBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// coldNodeCandidate is a write of a node that may be cold.
+type coldNodeCandidate struct {
+	queueKey    []byte
+	dbKey       []byte
+	writeCommit uint64
+	// The bytes that were uploaded. Nil if the node was written or deleted
+	// after [writeCommit].
+	nodeBytes []byte
+}
+
+// migrate migrates up to [s.migrationBatchSize] of the nodes that weren't
+// written in the last [s.age] commits to [s.objectStore]. Returns the number
+// of queued writes that were processed.
+//
+// Nodes are uploaded before they're removed from [s.baseDB], so a node is
+// always in at least one of them. Nodes that were written while they were
+// uploaded aren't removed.
+func (s *coldNodeStore) migrate(ctx context.Context) (int, error) {
+	s.migrateLock.Lock()
+	defer s.migrateLock.Unlock()
+
+	s.lock.Lock()
+	commit := s.commit
+	s.lock.Unlock()
+	if commit <= s.age {
+		return 0, nil
+	}
+	coldCommit := commit - s.age
+
+	candidates, err := s.getColdNodeCandidates(coldCommit)
+	if err != nil {
+		return 0, err
+	}
+	for i := range candidates {
+		if err := s.upload(ctx, &candidates[i]); err != nil {
+			s.deleteUploaded(candidates[:i])
+			return 0, err
+		}
+	}
+
+	// The uploaded nodes are only removed if they weren't written since they
+	// were uploaded. Otherwise, their objects are stale.
+	s.lock.Lock()
+	batch := s.baseDB.NewBatch()
+	stale := make([]coldNodeCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		isCurrent, err := s.remove(batch, candidate)
+		if err != nil {
+			s.lock.Unlock()
+			s.deleteUploaded(candidates)
+			return 0, err
+		}
+		if !isCurrent {
+			stale = append(stale, candidate)
+		}
+	}
+	err = batch.Write()
+	s.lock.Unlock()
+	if err != nil {
+		s.deleteUploaded(candidates)
+		return 0, err
+	}
+
+	s.deleteUploaded(stale)
+	return len(candidates), nil
+}
+
+// Returns up to [s.migrationBatchSize] of the queued writes that happened in
+// or before [coldCommit].
+func (s *coldNodeStore) getColdNodeCandidates(coldCommit uint64) ([]coldNodeCandidate, error) {
+	it := s.baseDB.NewIteratorWithPrefix(coldNodeQueuePrefix)
+	defer it.Release()
+
+	var candidates []coldNodeCandidate
+	for len(candidates) < s.migrationBatchSize && it.Next() {
+		queueKey := slices.Clone(it.Key())
+		writeCommit, dbKey, err := parseColdNodeQueueKey(queueKey)
+		if err != nil {
+			return nil, err
+		}
+		if writeCommit > coldCommit {
+			break
+		}
+		candidates = append(candidates, coldNodeCandidate{
+			queueKey:    queueKey,
+			dbKey:       dbKey,
+			writeCommit: writeCommit,
+		})
+	}
+	return candidates, it.Error()
+}
+
+// upload uploads the node of [candidate] if it was last written in
+// [candidate.writeCommit], and sets [candidate.nodeBytes] to its bytes.
+func (s *coldNodeStore) upload(ctx context.Context, candidate *coldNodeCandidate) error {
+	nodeBytes, isCurrent, err := s.getCurrentNode(*candidate)
+	if err != nil || !isCurrent {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+
+	if err := s.objectStore.PutObject(ctx, coldNodeObjectKey(candidate.dbKey), nodeBytes); err != nil {
+		return err
+	}
+	candidate.nodeBytes = nodeBytes
+	return nil
+}
+
+// remove adds to [b] the operations that dequeue [candidate] and, if its
+// uploaded node is still current, remove it from [s.baseDB] and record it as
+// cold. Returns false if [candidate] was uploaded but is no longer current.
+// Assumes [s.lock] is held until [b] is written.
+func (s *coldNodeStore) remove(b database.Batch, candidate coldNodeCandidate) (bool, error) {
+	if err := b.Delete(candidate.queueKey); err != nil {
+		return false, err
+	}
+	if candidate.nodeBytes == nil {
+		return true, nil
+	}
+
+	nodeBytes, isCurrent, err := s.getCurrentNode(candidate)
+	if err != nil {
+		return false, err
+	}
+	if !isCurrent || !bytes.Equal(nodeBytes, candidate.nodeBytes) {
+		return false, nil
+	}
+
+	if err := b.Delete(candidate.dbKey); err != nil {
+		return false, err
+	}
+	if err := b.Delete(prefixedKey(nodeWriteCommitPrefix, candidate.dbKey)); err != nil {
+		return false, err
+	}
+	return true, b.Put(
+		prefixedKey(coldNodeIndexPrefix, candidate.dbKey),
+		hashing.ComputeHash256(nodeBytes),
+	)
+}
+
+// Returns the bytes of the node of [candidate] and true if it was last
+// written in [candidate.writeCommit]. Returns false if it was written again
+// or deleted since.
+func (s *coldNodeStore) getCurrentNode(candidate coldNodeCandidate) ([]byte, bool, error) {
+	lastWriteCommit, err := database.GetUInt64(s.baseDB, prefixedKey(nodeWriteCommitPrefix, candidate.dbKey))
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	case lastWriteCommit != candidate.writeCommit:
+		return nil, false, nil
+	}
+
+	nodeBytes, err := s.baseDB.Get(candidate.dbKey)
+	if err != nil {
+		return nil, false, err
+	}
+	return nodeBytes, true, nil
+}
+
+// deleteUploaded deletes the objects uploaded for [candidates] that weren't
+// recorded as cold. They're never read, so failures are ignored.
+func (s *coldNodeStore) deleteUploaded(candidates []coldNodeCandidate) {
+	for _, candidate := range candidates {
+		if candidate.nodeBytes != nil {
+			_ = s.deleteObject(candidate.dbKey)
+		}
+	}
+}
+
+// Clear removes the local records of cold nodes. Objects aren't deleted from
+// [s.objectStore], since the nodes they hold can no longer be read.
+func (s *coldNodeStore) Clear() error {
+	s.migrateLock.Lock()
+	defer s.migrateLock.Unlock()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.nodeCache.Flush()
+	for _, prefix := range [][]byte{coldNodeIndexPrefix, nodeWriteCommitPrefix, coldNodeQueuePrefix} {
+		if err := database.AtomicClearPrefix(s.baseDB, s.baseDB, prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the key of the object holding the node with the given [dbKey].
+func coldNodeObjectKey(dbKey []byte) string {
+	return hex.EncodeToString(dbKey)
+}
+
+// Returns [key] prefixed by [prefix] in a newly allocated slice.
+func prefixedKey(prefix []byte, key []byte) []byte {
+	b := make([]byte, len(prefix)+len(key))
+	copy(b, prefix)
+	copy(b[len(prefix):], key)
+	return b
+}
+
+func coldNodeQueueKey(commit uint64, dbKey []byte) []byte {
+	prefixLen := len(coldNodeQueuePrefix)
+	b := make([]byte, prefixLen+wrappers.LongLen+len(dbKey))
+	copy(b, coldNodeQueuePrefix)
+	binary.BigEndian.PutUint64(b[prefixLen:], commit)
+	copy(b[prefixLen+wrappers.LongLen:], dbKey)
+	return b
+}
+
+func parseColdNodeQueueKey(queueKey []byte) (uint64, []byte, error) {
+	prefixLen := len(coldNodeQueuePrefix)
+	if len(queueKey) < prefixLen+wrappers.LongLen {
+		return 0, nil, fmt.Errorf("%w: cold node queue key %x is too short", ErrCorrupted, queueKey)
+	}
+	commit := binary.BigEndian.Uint64(queueKey[prefixLen:])
+	dbKey := queueKey[prefixLen+wrappers.LongLen:]
+	return commit, dbKey, nil
+}
@@ -0,0 +1,353 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+var _ ObjectStore = (*testObjectStore)(nil)
+
+type testObjectStore struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+	// If non-nil, returned by PutObject
+	putErr error
+	// If non-nil, called by PutObject before the object is stored
+	onPut func()
+}
+
+func newTestObjectStore() *testObjectStore {
+	return &testObjectStore{
+		objects: make(map[string][]byte),
+	}
+}
+
+func (s *testObjectStore) GetObject(_ context.Context, key string) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, ok := s.objects[key]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *testObjectStore) PutObject(_ context.Context, key string, value []byte) error {
+	s.lock.Lock()
+	onPut := s.onPut
+	s.lock.Unlock()
+	if onPut != nil {
+		onPut()
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.putErr != nil {
+		return s.putErr
+	}
+	s.objects[key] = value
+	return nil
+}
+
+func (s *testObjectStore) DeleteObject(_ context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *testObjectStore) len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.objects)
+}
+
+func (s *testObjectStore) has(key string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.objects[key]
+	return ok
+}
+
+// Migrates the nodes that are cold, rather than waiting for them to be
+// migrated in the background.
+func migrateColdNodes(t *testing.T, db *merkleDB) {
+	_, err := db.intermediateNodeDB.coldNodes.migrate(context.Background())
+	require.NoError(t, err)
+}
+
+func newColdNodeConfig(store ObjectStore) Config {
+	config := newDefaultConfig()
+	// Write every intermediate node to disk as soon as it's changed.
+	config.IntermediateWriteBufferSize = 1
+	config.EvictionBatchSize = 1
+	config.ColdNodeStore = store
+	config.ColdNodeAge = 2
+	config.ColdNodeCacheSize = 1000
+	return config
+}
+
+func TestColdNodes(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB = memdb.New()
+		store  = newTestObjectStore()
+		config = newColdNodeConfig(store)
+		// Intermediate nodes of keys {0x10, 0x11} and {0x20, 0x21}
+		coldKey1 = ToKey([]byte{0x10}).Take(4)
+		coldKey2 = ToKey([]byte{0x20}).Take(4)
+	)
+	db, err := newDatabase(context.Background(), baseDB, config, &mockMetrics{})
+	require.NoError(err)
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte{0x10}, []byte{1}))
+	require.NoError(batch.Put([]byte{0x11}, []byte{1}))
+	require.NoError(batch.Put([]byte{0x20}, []byte{1}))
+	require.NoError(batch.Put([]byte{0x21}, []byte{1}))
+	require.NoError(batch.Write())
+	require.Zero(store.len())
+
+	expectedNode1, err := db.intermediateNodeDB.Get(coldKey1)
+	require.NoError(err)
+	expectedNode2, err := db.intermediateNodeDB.Get(coldKey2)
+	require.NoError(err)
+
+	// The intermediate nodes weren't written in the last 2 commits, but the
+	// sentinel node was.
+	require.NoError(db.Put([]byte{0x30}, []byte{1}))
+	require.NoError(db.Put([]byte{0x40}, []byte{1}))
+	migrateColdNodes(t, db)
+	require.Equal(2, store.len())
+	for _, key := range []Key{coldKey1, coldKey2} {
+		dbKey := db.intermediateNodeDB.constructDBKey(key)
+		has, err := baseDB.Has(dbKey)
+		require.NoError(err)
+		require.False(has)
+
+		has, err = baseDB.Has(prefixedKey(coldNodeIndexPrefix, dbKey))
+		require.NoError(err)
+		require.True(has)
+	}
+	has, err := baseDB.Has(db.intermediateNodeDB.constructDBKey(Key{}))
+	require.NoError(err)
+	require.True(has)
+
+	// Cold nodes are read from the object store.
+	db.intermediateNodeDB.nodeCache.Flush()
+	node1, err := db.intermediateNodeDB.Get(coldKey1)
+	require.NoError(err)
+	require.Equal(expectedNode1.bytes(), node1.bytes())
+	_, ok := db.intermediateNodeDB.coldNodes.nodeCache.Get(coldKey1)
+	require.True(ok)
+
+	// Cold nodes are read after the database is reopened.
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	db, err = newDatabase(context.Background(), baseDB, config, &mockMetrics{})
+	require.NoError(err)
+	reopenedRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, reopenedRoot)
+	node2, err := db.intermediateNodeDB.Get(coldKey2)
+	require.NoError(err)
+	require.Equal(expectedNode2.bytes(), node2.bytes())
+
+	// Writing a cold node deletes its object.
+	require.NoError(db.Put([]byte{0x12}, []byte{1}))
+	require.Equal(1, store.len())
+	has, err = baseDB.Has(prefixedKey(coldNodeIndexPrefix, db.intermediateNodeDB.constructDBKey(coldKey1)))
+	require.NoError(err)
+	require.False(has)
+
+	value, err := db.Get([]byte{0x11})
+	require.NoError(err)
+	require.Equal([]byte{1}, value)
+
+	// The root matches a database without cold nodes.
+	expectedDB, err := getBasicDB()
+	require.NoError(err)
+	for _, key := range [][]byte{{0x10}, {0x11}, {0x12}, {0x20}, {0x21}, {0x30}, {0x40}} {
+		require.NoError(expectedDB.Put(key, []byte{1}))
+	}
+	expectedRoot, err := expectedDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	root, err = db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+}
+
+func TestColdNodesMissingObject(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		store   = newTestObjectStore()
+		coldKey = ToKey([]byte{0x10}).Take(4)
+	)
+	db, err := newDatabase(context.Background(), memdb.New(), newColdNodeConfig(store), &mockMetrics{})
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte{0x10}, []byte{1}))
+	require.NoError(db.Put([]byte{0x11}, []byte{1}))
+	require.NoError(db.Put([]byte{0x20}, []byte{1}))
+	require.NoError(db.Put([]byte{0x30}, []byte{1}))
+	migrateColdNodes(t, db)
+	require.Equal(1, store.len())
+
+	store.lock.Lock()
+	store.objects = make(map[string][]byte)
+	store.lock.Unlock()
+	db.intermediateNodeDB.nodeCache.Flush()
+	_, err = db.intermediateNodeDB.Get(coldKey)
+	require.ErrorIs(err, errMissingColdNode)
+}
+
+func TestColdNodesInvalidAge(t *testing.T) {
+	config := newColdNodeConfig(newTestObjectStore())
+	config.ColdNodeAge = 0
+	_, err := newDatabase(context.Background(), memdb.New(), config, &mockMetrics{})
+	require.ErrorIs(t, err, errInvalidColdNodeAge)
+}
+
+func TestColdNodesCorruptedObject(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		store   = newTestObjectStore()
+		coldKey = ToKey([]byte{0x10}).Take(4)
+	)
+	db, err := newDatabase(context.Background(), memdb.New(), newColdNodeConfig(store), &mockMetrics{})
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte{0x10}, []byte{1}))
+	require.NoError(db.Put([]byte{0x11}, []byte{1}))
+	require.NoError(db.Put([]byte{0x20}, []byte{1}))
+	require.NoError(db.Put([]byte{0x30}, []byte{1}))
+	migrateColdNodes(t, db)
+
+	objectKey := coldNodeObjectKey(db.intermediateNodeDB.constructDBKey(coldKey))
+	require.True(store.has(objectKey))
+	store.lock.Lock()
+	store.objects[objectKey] = append(store.objects[objectKey], 0)
+	store.lock.Unlock()
+
+	db.intermediateNodeDB.nodeCache.Flush()
+	db.intermediateNodeDB.coldNodes.nodeCache.Flush()
+	_, err = db.intermediateNodeDB.Get(coldKey)
+	require.ErrorIs(err, ErrCorrupted)
+}
+
+func TestColdNodesUploadFailure(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB  = memdb.New()
+		store   = newTestObjectStore()
+		coldKey = ToKey([]byte{0x10}).Take(4)
+		errPut  = errors.New("put failed")
+	)
+	store.putErr = errPut
+	db, err := newDatabase(context.Background(), baseDB, newColdNodeConfig(store), &mockMetrics{})
+	require.NoError(err)
+
+	// Failing to migrate nodes doesn't fail commits.
+	require.NoError(db.Put([]byte{0x10}, []byte{1}))
+	require.NoError(db.Put([]byte{0x11}, []byte{1}))
+	require.NoError(db.Put([]byte{0x20}, []byte{1}))
+	require.NoError(db.Put([]byte{0x30}, []byte{1}))
+	_, err = db.intermediateNodeDB.coldNodes.migrate(context.Background())
+	require.ErrorIs(err, errPut)
+
+	dbKey := db.intermediateNodeDB.constructDBKey(coldKey)
+	has, err := baseDB.Has(dbKey)
+	require.NoError(err)
+	require.True(has)
+	value, err := db.Get([]byte{0x11})
+	require.NoError(err)
+	require.Equal([]byte{1}, value)
+
+	// The nodes stay queued and are migrated once the store recovers.
+	store.lock.Lock()
+	store.putErr = nil
+	store.lock.Unlock()
+	migrateColdNodes(t, db)
+	has, err = baseDB.Has(dbKey)
+	require.NoError(err)
+	require.False(has)
+	require.True(store.has(coldNodeObjectKey(dbKey)))
+}
+
+func TestColdNodesWrittenDuringUpload(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		baseDB    = memdb.New()
+		store     = newTestObjectStore()
+		coldKey1  = ToKey([]byte{0x10}).Take(4)
+		coldKey2  = ToKey([]byte{0x20}).Take(4)
+		uploading = make(chan struct{})
+		release   = make(chan struct{})
+		once      sync.Once
+	)
+	db, err := newDatabase(context.Background(), baseDB, newColdNodeConfig(store), &mockMetrics{})
+	require.NoError(err)
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte{0x10}, []byte{1}))
+	require.NoError(batch.Put([]byte{0x11}, []byte{1}))
+	require.NoError(batch.Put([]byte{0x20}, []byte{1}))
+	require.NoError(batch.Put([]byte{0x21}, []byte{1}))
+	require.NoError(batch.Write())
+
+	// Block the background migration while it uploads.
+	store.lock.Lock()
+	store.onPut = func() {
+		once.Do(func() {
+			close(uploading)
+		})
+		<-release
+	}
+	store.lock.Unlock()
+	require.NoError(db.Put([]byte{0x30}, []byte{1}))
+	require.NoError(db.Put([]byte{0x40}, []byte{1}))
+	<-uploading
+
+	// Write the first node again while it's uploaded.
+	require.NoError(db.Put([]byte{0x12}, []byte{1}))
+	close(release)
+	migrateColdNodes(t, db)
+
+	dbKey1 := db.intermediateNodeDB.constructDBKey(coldKey1)
+	has, err := baseDB.Has(dbKey1)
+	require.NoError(err)
+	require.True(has)
+	require.False(store.has(coldNodeObjectKey(dbKey1)))
+
+	dbKey2 := db.intermediateNodeDB.constructDBKey(coldKey2)
+	has, err = baseDB.Has(dbKey2)
+	require.NoError(err)
+	require.False(has)
+	require.True(store.has(coldNodeObjectKey(dbKey2)))
+
+	value, err := db.Get([]byte{0x12})
+	require.NoError(err)
+	require.Equal([]byte{1}, value)
+}
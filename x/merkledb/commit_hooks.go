@@ -0,0 +1,65 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+// ChangeSummary describes the key-value changes made by a commit.
+type ChangeSummary struct {
+	// The keys whose values were changed, in order of increasing key, along
+	// with their new values. A value of Nothing means the key was deleted.
+	KeyChanges []KeyChange
+}
+
+type CommitHookRegisterer interface {
+	// RegisterOnCommit registers [hook] to be called after every commit that
+	// changes at least one key, once the new root has been written to disk.
+	//
+	// Hooks are called synchronously, in the order they were registered,
+	// before the new root is visible to readers of the database and before
+	// any other commit can occur. Therefore, a hook never observes a root
+	// that it hasn't been called with.
+	// Hooks must not call methods of the database.
+	RegisterOnCommit(hook func(root ids.ID, summary ChangeSummary))
+}
+
+func (db *merkleDB) RegisterOnCommit(hook func(root ids.ID, summary ChangeSummary)) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.onCommitHooks = append(db.onCommitHooks, hook)
+}
+
+// Calls the registered commit hooks with [changes].
+// Assumes [db.lock] is held.
+func (db *merkleDB) callOnCommitHooks(changes *changeSummary) {
+	if len(db.onCommitHooks) == 0 || len(changes.values) == 0 {
+		return
+	}
+
+	changedKeys := maps.Keys(changes.values)
+	utils.Sort(changedKeys)
+
+	summary := ChangeSummary{
+		KeyChanges: make([]KeyChange, len(changedKeys)),
+	}
+	for i, key := range changedKeys {
+		summary.KeyChanges[i] = KeyChange{
+			Key: key.Bytes(),
+			// create a copy so edits of the []byte don't affect the db
+			Value: maybe.Bind(changes.values[key].after, slices.Clone[[]byte]),
+		}
+	}
+
+	for _, hook := range db.onCommitHooks {
+		hook(changes.rootID, summary)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+func TestRegisterOnCommit(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte{1}, []byte{1}))
+
+	type commit struct {
+		root    ids.ID
+		summary ChangeSummary
+	}
+	var (
+		firstCommits  []commit
+		secondCommits []commit
+	)
+	db.RegisterOnCommit(func(root ids.ID, summary ChangeSummary) {
+		firstCommits = append(firstCommits, commit{
+			root:    root,
+			summary: summary,
+		})
+	})
+	db.RegisterOnCommit(func(root ids.ID, summary ChangeSummary) {
+		// Hooks are called in the order they were registered.
+		require.Len(firstCommits, len(secondCommits)+1)
+		secondCommits = append(secondCommits, commit{
+			root:    root,
+			summary: summary,
+		})
+	})
+
+	view, err := db.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{
+			{Key: []byte{3}, Value: []byte{3}},
+			{Key: []byte{1}, Delete: true},
+			{Key: []byte{2}, Value: []byte{2}},
+		},
+	})
+	require.NoError(err)
+	require.NoError(view.CommitToDB(context.Background()))
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	expectedCommits := []commit{
+		{
+			root: root,
+			summary: ChangeSummary{
+				KeyChanges: []KeyChange{
+					{Key: []byte{1}, Value: maybe.Nothing[[]byte]()},
+					{Key: []byte{2}, Value: maybe.Some([]byte{2})},
+					{Key: []byte{3}, Value: maybe.Some([]byte{3})},
+				},
+			},
+		},
+	}
+	require.Equal(expectedCommits, firstCommits)
+	require.Equal(expectedCommits, secondCommits)
+
+	// Commits that don't change any keys don't call the hooks.
+	view, err = db.NewView(context.Background(), ViewChanges{})
+	require.NoError(err)
+	require.NoError(view.CommitToDB(context.Background()))
+	require.Len(firstCommits, 1)
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// The number of commit events buffered for each subscriber if no buffer size
+// is specified, so that a subscriber that reads events as they arrive doesn't
+// miss any under the default [DropCommitEvents] policy.
+const defaultCommitEventBufferSize = 128
+
+const (
+	// DropCommitEvents drops events sent to a subscriber whose buffer is
+	// full.
+	DropCommitEvents CommitEventPolicy = iota
+	// BlockOnCommitEvents blocks commits until every subscriber has buffer
+	// space for the event.
+	BlockOnCommitEvents
+)
+
+// CommitEventPolicy determines what happens when a subscriber isn't keeping
+// up with commits.
+type CommitEventPolicy uint8
+
+// CommitEvent describes a successful commit to the database.
+type CommitEvent struct {
+	// RootID is the merkle root of the database after the commit.
+	RootID ids.ID
+	// NumKeysChanged is the number of keys whose values were changed.
+	NumKeysChanged int
+	// Height is the number of commits made since the database was opened,
+	// including this one.
+	Height uint64
+}
+
+type CommitSubscriber interface {
+	// Subscribe returns a channel that receives an event after every
+	// successful commit. The channel is closed when the database is closed.
+	Subscribe() <-chan CommitEvent
+}
+
+type commitNotifier struct {
+	lock        sync.Mutex
+	policy      CommitEventPolicy
+	bufferSize  int
+	height      uint64
+	subscribers []chan CommitEvent
+	closed      bool
+}
+
+func newCommitNotifier(policy CommitEventPolicy, bufferSize int) *commitNotifier {
+	if bufferSize == 0 {
+		bufferSize = defaultCommitEventBufferSize
+	}
+	return &commitNotifier{
+		policy:     policy,
+		bufferSize: bufferSize,
+	}
+}
+
+func (n *commitNotifier) subscribe() <-chan CommitEvent {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	subscriber := make(chan CommitEvent, n.bufferSize)
+	if n.closed {
+		close(subscriber)
+		return subscriber
+	}
+	n.subscribers = append(n.subscribers, subscriber)
+	return subscriber
+}
+
+// notify sends an event for a commit resulting in [rootID] to all
+// subscribers.
+// If the policy is [BlockOnCommitEvents], blocks until every subscriber has
+// received the event.
+func (n *commitNotifier) notify(rootID ids.ID, numKeysChanged int) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.closed {
+		return
+	}
+
+	n.height++
+	event := CommitEvent{
+		RootID:         rootID,
+		NumKeysChanged: numKeysChanged,
+		Height:         n.height,
+	}
+	for _, subscriber := range n.subscribers {
+		if n.policy == BlockOnCommitEvents {
+			subscriber <- event
+			continue
+		}
+
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+func (n *commitNotifier) close() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.closed {
+		return
+	}
+	n.closed = true
+
+	for _, subscriber := range n.subscribers {
+		close(subscriber)
+	}
+	n.subscribers = nil
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+func TestSubscribeCommitEvents(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.CommitEventBufferSize = 2
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	events := db.Subscribe()
+
+	view, err := db.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: []database.BatchOp{
+				{Key: []byte{1}, Value: []byte{1}},
+				{Key: []byte{2}, Value: []byte{2}},
+			},
+		},
+	)
+	require.NoError(err)
+	require.NoError(view.CommitToDB(context.Background()))
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(CommitEvent{
+		RootID:         root,
+		NumKeysChanged: 2,
+		Height:         1,
+	}, <-events)
+
+	require.NoError(db.Put([]byte{3}, []byte{3}))
+
+	root, err = db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(CommitEvent{
+		RootID:         root,
+		NumKeysChanged: 1,
+		Height:         2,
+	}, <-events)
+
+	require.NoError(db.Close())
+
+	_, ok := <-events
+	require.False(ok)
+
+	_, ok = <-db.Subscribe()
+	require.False(ok)
+}
+
+func TestSubscribeCommitEventsDefaultBuffer(t *testing.T) {
+	require := require.New(t)
+
+	db, err := newDB(context.Background(), memdb.New(), newDefaultConfig())
+	require.NoError(err)
+
+	events := db.Subscribe()
+
+	// Events are buffered by default, so none are dropped if the subscriber
+	// reads them after the commits.
+	require.NoError(db.Put([]byte{1}, []byte{1}))
+	require.NoError(db.Put([]byte{2}, []byte{2}))
+
+	require.Equal(uint64(1), (<-events).Height)
+	require.Equal(uint64(2), (<-events).Height)
+}
+
+func TestSubscribeCommitEventsDropsWhenFull(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.CommitEventBufferSize = 1
+	config.CommitEventPolicy = DropCommitEvents
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	events := db.Subscribe()
+
+	require.NoError(db.Put([]byte{1}, []byte{1}))
+	require.NoError(db.Put([]byte{2}, []byte{2}))
+
+	event := <-events
+	require.Equal(uint64(1), event.Height)
+	require.Empty(events)
+}
@@ -10,12 +10,13 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
-	"golang.org/x/sync/semaphore"
 
 	"go.opentelemetry.io/otel/attribute"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/resource"
 	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
 )
@@ -39,6 +41,10 @@ const (
 	clearBatchSize                       = units.MiB
 	rebuildIntermediateDeletionWriteSize = units.MiB
 	valueNodePrefixLen                   = 1
+
+	// The maximum number of keys, or nodes, loaded into the cache by a single
+	// acquisition of [commitLock] while warming.
+	warmBatchSize = 256
 )
 
 var (
@@ -54,10 +60,31 @@ var (
 	hadCleanShutdown        = []byte{1}
 	didNotHaveCleanShutdown = []byte{0}
 
+	ErrChangeProofConflict = errors.New("change proof conflicts with local changes")
+	ErrNotViewStack        = errors.New("views don't form a parent to child chain")
+	ErrSquashedViewInStack = errors.New("squashed views can't be committed as part of a view stack")
+
 	errSameRoot      = errors.New("start and end root are the same")
 	errNoNewSentinel = errors.New("there was no updated sentinel node in change list")
 )
 
+const (
+	// FailOnConflict causes the commit to fail if any key in the change proof
+	// was modified locally.
+	FailOnConflict ConflictPolicy = iota
+	// OverwriteOnConflict commits every key in the change proof, replacing
+	// any local modifications.
+	OverwriteOnConflict
+	// SkipOnConflict commits every key in the change proof except those that
+	// were modified locally.
+	SkipOnConflict
+)
+
+// ConflictPolicy determines how keys in a change proof that were modified
+// locally since the proof's start root are handled when the proof is
+// committed.
+type ConflictPolicy uint8
+
 type ChangeProofer interface {
 	// GetChangeProof returns a proof for a subset of the key/value changes in key range
 	// [start, end] that occurred between [startRootID] and [endRootID].
@@ -95,17 +122,33 @@ type ChangeProofer interface {
 	CommitChangeProof(ctx context.Context, proof *ChangeProof) error
 }
 
+type ConflictingChangeProofCommitter interface {
+	// CommitChangeProofWithPolicy commits the key/value pairs within the
+	// [proof] to the db. Keys in [proof] whose values were modified locally
+	// since [startRootID] are handled according to [policy].
+	// Returns [ErrInsufficientHistory] if this node has insufficient history
+	// to determine which keys were modified locally.
+	CommitChangeProofWithPolicy(
+		ctx context.Context,
+		startRootID ids.ID,
+		proof *ChangeProof,
+		policy ConflictPolicy,
+	) error
+}
+
 type RangeProofer interface {
 	// GetRangeProofAtRoot returns a proof for the key/value pairs in this trie within the range
 	// [start, end] when the root of the trie was [rootID].
 	// If [start] is Nothing, there's no lower bound on the range.
 	// If [end] is Nothing, there's no upper bound on the range.
+	// See [Trie.GetRangeProof] for the meaning of [maxLength] and [maxBytes].
 	GetRangeProofAtRoot(
 		ctx context.Context,
 		rootID ids.ID,
 		start maybe.Maybe[[]byte],
 		end maybe.Maybe[[]byte],
 		maxLength int,
+		maxBytes int,
 	) (*RangeProof, error)
 
 	// CommitRangeProof commits the key/value pairs within the [proof] to the db.
@@ -131,6 +174,35 @@ type Prefetcher interface {
 	// Using PrefetchPaths can be more efficient than PrefetchPath because
 	// the underlying view used to compute each path can be reused.
 	PrefetchPaths(keys [][]byte) error
+
+	// WarmKeys loads all trie nodes on the paths of [keys] into the cache in
+	// the background and returns immediately. Warming stops early if [ctx] is
+	// cancelled or the database is closed. Commits aren't blocked for the
+	// whole duration of warming.
+	//
+	// The returned channel receives the result of warming once it finishes.
+	WarmKeys(ctx context.Context, keys [][]byte) <-chan error
+
+	// WarmPrefix is the same as WarmKeys, but loads all trie nodes on the
+	// paths of keys that start with [prefix].
+	WarmPrefix(ctx context.Context, prefix []byte) <-chan error
+}
+
+type ViewStackCommitter interface {
+	// CommitViewStack commits [views] to the database as a single batch with
+	// a single root update.
+	// The parent of [views][0] must be the database and the parent of every
+	// other view must be the view before it.
+	CommitViewStack(ctx context.Context, views []TrieView) error
+}
+
+type CommitEpochGetter interface {
+	// CommitEpoch returns the number of commits made to the database since
+	// it was opened. Views record the commit epoch in which they were
+	// created, and the error returned by an invalid view records the commit
+	// epoch in which it was invalidated, so that they can be compared to the
+	// current commit epoch when debugging stale views.
+	CommitEpoch() uint64
 }
 
 type MerkleDB interface {
@@ -140,8 +212,17 @@ type MerkleDB interface {
 	MerkleRootGetter
 	ProofGetter
 	ChangeProofer
+	ConflictingChangeProofCommitter
 	RangeProofer
 	Prefetcher
+	CommitSubscriber
+	RangeLocker
+	ViewStackCommitter
+	KeyEpochTracker
+	QuotaTracker
+	CommitEpochGetter
+	CommitHookRegisterer
+	HeightIndexer
 }
 
 type Config struct {
@@ -153,6 +234,25 @@ type Config struct {
 	//
 	// If 0 is specified, [runtime.NumCPU] will be used.
 	RootGenConcurrency uint
+	// MinRootGenConcurrency is the minimum number of goroutines to use when
+	// generating a new state root. If it's less than [RootGenConcurrency],
+	// the number of goroutines adapts between the two based on recent root
+	// generation latency and CPU availability.
+	//
+	// If 0 is specified, [RootGenConcurrency] will be used, which disables
+	// adaptation.
+	MinRootGenConcurrency uint
+	// If non-nil, the CPU usage reported by [CPUUser] is used to avoid
+	// generating roots with more goroutines than there are idle CPUs.
+	// Only used if [MinRootGenConcurrency] < [RootGenConcurrency].
+	CPUUser resource.CPUUser
+	// ViewRootGenConcurrency is the default maximum number of goroutines a
+	// single view uses when generating its state root. Limiting it prevents a
+	// large view from using all of [RootGenConcurrency] and starving other
+	// views. It can be overridden per view by [ViewChanges.RootGenConcurrency].
+	//
+	// If 0 is specified, [RootGenConcurrency] will be used.
+	ViewRootGenConcurrency uint
 	// The number of bytes to write to disk when intermediate nodes are evicted
 	// from their cache and written to disk.
 	EvictionBatchSize uint
@@ -161,14 +261,90 @@ type Config struct {
 	HistoryLength uint
 	// The number of bytes to cache nodes with values.
 	ValueNodeCacheSize uint
-	// The number of bytes to cache nodes without values.
+	// The number of bytes to cache nodes without values that were read from
+	// disk.
 	IntermediateNodeCacheSize uint
+	// The number of bytes of changed nodes without values to hold in memory
+	// before they're written to disk. Independent of
+	// [IntermediateNodeCacheSize], so that committing many changes doesn't
+	// evict the nodes that are read the most.
+	//
+	// If 0 is specified, [IntermediateNodeCacheSize] will be used.
+	IntermediateWriteBufferSize uint
 	// If [Reg] is nil, metrics are collected locally but not exported through
 	// Prometheus.
 	// This may be useful for testing.
 	Reg        prometheus.Registerer
 	TraceLevel TraceLevel
 	Tracer     trace.Tracer
+	// The number of commit events buffered for each subscriber.
+	// If 0 is specified, 128 will be used.
+	CommitEventBufferSize uint
+	// Determines whether commit events are dropped or commits are blocked
+	// when a subscriber's buffer is full.
+	CommitEventPolicy CommitEventPolicy
+	// If true, a CRC32C checksum is appended to each node written to disk and
+	// verified when the node is read. Reading a node that doesn't match its
+	// checksum returns [ErrCorrupted].
+	// Must not be changed once the database has been written to.
+	NodeChecksums bool
+	// If non-nil, values longer than [BlobValueThreshold] bytes are stored in
+	// [BlobStore], keyed by their hash, and the trie only stores the hash.
+	// Values are resolved transparently when they're read, and the root is
+	// the same as if the values were stored in the trie.
+	// Blobs are never removed from [BlobStore], since they may be shared by
+	// multiple keys. See [NewFileBlobStore] for a file-backed store.
+	BlobStore database.KeyValueReaderWriter
+	// Ignored if [BlobStore] is nil.
+	BlobValueThreshold uint
+	// If non-nil, intermediate nodes that weren't written to disk in the last
+	// [ColdNodeAge] commits are migrated to [ColdNodeStore] and removed from
+	// the database. An index of the migrated nodes is kept in the database so
+	// that only migrated nodes are read from [ColdNodeStore], and nodes read
+	// from it are cached. This allows archival databases to keep nodes that
+	// are rarely read off of local disk.
+	// Nodes with values are never migrated, since they're iterated over.
+	// Must not be changed from non-nil to nil once nodes have been migrated.
+	ColdNodeStore ObjectStore
+	// Must be positive if [ColdNodeStore] is non-nil.
+	ColdNodeAge uint
+	// The maximum number of node writes checked for migration by each commit.
+	// Nodes that become cold faster than this are migrated by later commits.
+	//
+	// If 0 is specified, 1024 will be used.
+	ColdNodeMigrationBatchSize uint
+	// The number of bytes to cache nodes read from [ColdNodeStore].
+	// Ignored if [ColdNodeStore] is nil.
+	ColdNodeCacheSize uint
+	// The maximum duration of each request to [ColdNodeStore].
+	//
+	// If 0 is specified, 30 seconds will be used.
+	ColdNodeRequestTimeout time.Duration
+	// If true, the epoch in which each key was last written is recorded.
+	// See [KeyEpochTracker].
+	TrackKeyEpochs bool
+	// If empty, key epochs are stored alongside the values of the trie and
+	// don't affect its root.
+	// Otherwise, the epoch of each key is recorded in the trie under the key
+	// prefixed with [HashedKeyEpochPrefix], and is therefore included in the
+	// root. Keys with this prefix are reserved for epoch records.
+	// Ignored if [TrackKeyEpochs] is false.
+	HashedKeyEpochPrefix []byte
+	// If true, roots are generated on a single goroutine and the changed
+	// keys and nodes of each view are processed in sorted order, so that the
+	// same operations always happen in the same order. This is much slower
+	// and is only intended for tests and for reproducing order-dependent
+	// bugs.
+	DeterministicHashing bool
+	// Bounds the number of keys and bytes stored under each prefix, so that
+	// the state of each tenant of a database shared by many applications can
+	// be bounded. Prefixes must be non-empty and must not be prefixes of one
+	// another.
+	// A change that would increase the usage of a quota past it fails to be
+	// recorded in a view with a [*QuotaExceededError]. The usage of each quota
+	// is calculated when the database is opened, which takes time
+	// proportional to the number of keys under the quota prefixes.
+	PrefixQuotas []PrefixQuota
 }
 
 // merkleDB can only be edited by committing changes from a trieView.
@@ -210,11 +386,44 @@ type merkleDB struct {
 	// Valid children of this trie.
 	childViews []*trieView
 
-	// calculateNodeIDsSema controls the number of goroutines inside
+	// calculateNodeIDsLimiter controls the number of goroutines inside
 	// [calculateNodeIDsHelper] at any given time.
-	calculateNodeIDsSema *semaphore.Weighted
+	calculateNodeIDsLimiter *hashConcurrencyLimiter
+	// The default maximum number of goroutines inside
+	// [calculateNodeIDsHelper] for a single view.
+	viewRootGenConcurrency uint
 
 	tokenSize int
+
+	// Notifies subscribers of commits.
+	commitNotifier *commitNotifier
+
+	// Advisory locks on key ranges held by cooperating writers.
+	rangeLocker *rangeLocker
+
+	// If true, the epoch in which each key was last written is recorded.
+	trackKeyEpochs bool
+	// If non-empty, key epochs are recorded in the trie under this prefix.
+	hashedKeyEpochPrefix []byte
+	// If true, roots are generated on a single goroutine in a deterministic
+	// order.
+	deterministicHashing bool
+	// The epoch recorded for keys changed by views created now.
+	epoch utils.Atomic[uint64]
+
+	// The prefix quotas of the database, and their usage by the committed
+	// values indexed like [quotas].
+	// [lock] must be held when accessing [quotaUsage].
+	quotas     quotas
+	quotaUsage []PrefixUsage
+
+	// Incremented by every commit, before the views it invalidates are
+	// marked as invalid. See [CommitEpochGetter].
+	commitEpoch atomic.Uint64
+
+	// Called after every commit that changes at least one key.
+	// [lock] must be held when accessing this field.
+	onCommitHooks []func(ids.ID, ChangeSummary)
 }
 
 // New returns a new merkle database.
@@ -235,11 +444,42 @@ func newDatabase(
 	if err := config.BranchFactor.Valid(); err != nil {
 		return nil, err
 	}
+	prefixQuotas, err := newQuotas(config.PrefixQuotas)
+	if err != nil {
+		return nil, err
+	}
 
 	rootGenConcurrency := uint(runtime.NumCPU())
 	if config.RootGenConcurrency != 0 {
 		rootGenConcurrency = config.RootGenConcurrency
 	}
+	minRootGenConcurrency := rootGenConcurrency
+	if config.MinRootGenConcurrency != 0 && config.MinRootGenConcurrency < rootGenConcurrency {
+		minRootGenConcurrency = config.MinRootGenConcurrency
+	}
+	viewRootGenConcurrency := rootGenConcurrency
+	if config.ViewRootGenConcurrency != 0 {
+		viewRootGenConcurrency = config.ViewRootGenConcurrency
+	}
+	writeBufferSize := config.IntermediateNodeCacheSize
+	if config.IntermediateWriteBufferSize != 0 {
+		writeBufferSize = config.IntermediateWriteBufferSize
+	}
+
+	var coldNodes *coldNodeStore
+	if config.ColdNodeStore != nil {
+		coldNodes, err = newColdNodeStore(
+			db,
+			config.ColdNodeStore,
+			uint64(config.ColdNodeAge),
+			int(config.ColdNodeMigrationBatchSize),
+			config.ColdNodeRequestTimeout,
+			int(config.ColdNodeCacheSize),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Share a sync.Pool of []byte between the intermediateNodeDB and valueNodeDB
 	// to reduce memory allocations.
@@ -249,21 +489,34 @@ func newDatabase(
 		},
 	}
 	trieDB := &merkleDB{
-		metrics:              metrics,
-		baseDB:               db,
-		valueNodeDB:          newValueNodeDB(db, bufferPool, metrics, int(config.ValueNodeCacheSize)),
-		intermediateNodeDB:   newIntermediateNodeDB(db, bufferPool, metrics, int(config.IntermediateNodeCacheSize), int(config.EvictionBatchSize), BranchFactorToTokenSize[config.BranchFactor]),
-		history:              newTrieHistory(int(config.HistoryLength)),
-		debugTracer:          getTracerIfEnabled(config.TraceLevel, DebugTrace, config.Tracer),
-		infoTracer:           getTracerIfEnabled(config.TraceLevel, InfoTrace, config.Tracer),
-		childViews:           make([]*trieView, 0, defaultPreallocationSize),
-		calculateNodeIDsSema: semaphore.NewWeighted(int64(rootGenConcurrency)),
-		tokenSize:            BranchFactorToTokenSize[config.BranchFactor],
+		metrics:                 metrics,
+		baseDB:                  db,
+		valueNodeDB:             newValueNodeDB(db, bufferPool, metrics, int(config.ValueNodeCacheSize), config.NodeChecksums, config.BlobStore, int(config.BlobValueThreshold)),
+		intermediateNodeDB:      newIntermediateNodeDB(db, bufferPool, metrics, int(config.IntermediateNodeCacheSize), int(writeBufferSize), int(config.EvictionBatchSize), BranchFactorToTokenSize[config.BranchFactor], config.NodeChecksums, coldNodes),
+		history:                 newTrieHistory(int(config.HistoryLength)),
+		debugTracer:             getTracerIfEnabled(config.TraceLevel, DebugTrace, config.Tracer),
+		infoTracer:              getTracerIfEnabled(config.TraceLevel, InfoTrace, config.Tracer),
+		childViews:              make([]*trieView, 0, defaultPreallocationSize),
+		calculateNodeIDsLimiter: newHashConcurrencyLimiter(minRootGenConcurrency, rootGenConcurrency, config.CPUUser),
+		viewRootGenConcurrency:  viewRootGenConcurrency,
+		tokenSize:               BranchFactorToTokenSize[config.BranchFactor],
+		commitNotifier:          newCommitNotifier(config.CommitEventPolicy, int(config.CommitEventBufferSize)),
+		rangeLocker:             newRangeLocker(),
+		trackKeyEpochs:          config.TrackKeyEpochs,
+		deterministicHashing:    config.DeterministicHashing,
+		hashedKeyEpochPrefix:    slices.Clone(config.HashedKeyEpochPrefix),
+		quotas:                  prefixQuotas,
 	}
 
 	if err := trieDB.initializeRoot(); err != nil {
 		return nil, err
 	}
+	// Calculated before the trie is rebuilt, since rebuilding it records
+	// every value in views.
+	trieDB.quotaUsage, err = trieDB.calculateQuotaUsage()
+	if err != nil {
+		return nil, err
+	}
 
 	// add current root to history (has no changes)
 	trieDB.history.record(&changeSummary{
@@ -288,8 +541,13 @@ func newDatabase(
 	}
 
 	// mark that the db has not yet been cleanly closed
-	err = trieDB.baseDB.Put(cleanShutdownKey, didNotHaveCleanShutdown)
-	return trieDB, err
+	if err := trieDB.baseDB.Put(cleanShutdownKey, didNotHaveCleanShutdown); err != nil {
+		return nil, err
+	}
+	if coldNodes != nil {
+		coldNodes.start()
+	}
+	return trieDB, nil
 }
 
 // Deletes every intermediate node and rebuilds them by re-adding every key/value.
@@ -301,6 +559,11 @@ func (db *merkleDB) rebuild(ctx context.Context, cacheSize int) error {
 	if err := database.ClearPrefix(db.baseDB, intermediateNodePrefix, rebuildIntermediateDeletionWriteSize); err != nil {
 		return err
 	}
+	if coldNodes := db.intermediateNodeDB.coldNodes; coldNodes != nil {
+		if err := coldNodes.Clear(); err != nil {
+			return err
+		}
+	}
 
 	// Add all key-value pairs back into the database.
 	opsSizeLimit := math.Max(
@@ -368,6 +631,59 @@ func (db *merkleDB) CommitChangeProof(ctx context.Context, proof *ChangeProof) e
 	return view.commitToDB(ctx)
 }
 
+func (db *merkleDB) CommitChangeProofWithPolicy(
+	ctx context.Context,
+	startRootID ids.ID,
+	proof *ChangeProof,
+	policy ConflictPolicy,
+) error {
+	db.commitLock.Lock()
+	defer db.commitLock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	var conflictingKeys set.Set[Key]
+	if policy != OverwriteOnConflict {
+		proofKeys := set.NewSet[Key](len(proof.KeyChanges))
+		for _, kv := range proof.KeyChanges {
+			proofKeys.Add(ToKey(kv.Key))
+		}
+
+		var err error
+		conflictingKeys, err = db.history.getChangedKeys(startRootID, db.getMerkleRoot(), proofKeys)
+		if err != nil {
+			return err
+		}
+		if policy == FailOnConflict && conflictingKeys.Len() > 0 {
+			return fmt.Errorf("%w: %d keys modified since root %s",
+				ErrChangeProofConflict,
+				conflictingKeys.Len(),
+				startRootID,
+			)
+		}
+	}
+
+	ops := make([]database.BatchOp, 0, len(proof.KeyChanges))
+	for _, kv := range proof.KeyChanges {
+		if conflictingKeys.Contains(ToKey(kv.Key)) {
+			continue
+		}
+		ops = append(ops, database.BatchOp{
+			Key:    kv.Key,
+			Value:  kv.Value.Value(),
+			Delete: kv.Value.IsNothing(),
+		})
+	}
+
+	view, err := newTrieView(db, db, ViewChanges{BatchOps: ops})
+	if err != nil {
+		return err
+	}
+	return view.commitToDB(ctx)
+}
+
 func (db *merkleDB) CommitRangeProof(ctx context.Context, start, end maybe.Maybe[[]byte], proof *RangeProof) error {
 	db.commitLock.Lock()
 	defer db.commitLock.Unlock()
@@ -429,6 +745,10 @@ func (db *merkleDB) Close() error {
 	}
 
 	db.closed = true
+	db.commitNotifier.close()
+	if coldNodes := db.intermediateNodeDB.coldNodes; coldNodes != nil {
+		coldNodes.close()
+	}
 	db.valueNodeDB.Close()
 	// Flush intermediary nodes to disk.
 	if err := db.intermediateNodeDB.Flush(); err != nil {
@@ -477,14 +797,151 @@ func (db *merkleDB) PrefetchPath(key []byte) error {
 }
 
 func (db *merkleDB) prefetchPath(view *trieView, keyBytes []byte) error {
-	return view.visitPathToKey(ToKey(keyBytes), func(n *node) error {
-		if !n.hasValue() {
-			return db.intermediateNodeDB.nodeCache.Put(n.key, n)
-		}
+	return view.visitPathToKey(ToKey(keyBytes), db.cacheNode)
+}
 
-		db.valueNodeDB.nodeCache.Put(n.key, n)
+// cacheNode adds [n] to the cache of the node db it belongs to.
+func (db *merkleDB) cacheNode(n *node) error {
+	if !n.hasValue() {
+		db.intermediateNodeDB.cache(n)
 		return nil
-	})
+	}
+
+	db.valueNodeDB.nodeCache.Put(n.key, n)
+	return nil
+}
+
+func (db *merkleDB) WarmKeys(ctx context.Context, keys [][]byte) <-chan error {
+	// Convert the keys before returning so that the caller may modify [keys].
+	pathKeys := make([]Key, len(keys))
+	for i, key := range keys {
+		pathKeys[i] = ToKey(key)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- db.warmKeys(ctx, pathKeys)
+		close(result)
+	}()
+	return result
+}
+
+func (db *merkleDB) warmKeys(ctx context.Context, keys []Key) error {
+	for len(keys) > 0 {
+		batchSize := math.Min(len(keys), warmBatchSize)
+		if err := db.warmKeysBatch(ctx, keys[:batchSize]); err != nil {
+			return err
+		}
+		keys = keys[batchSize:]
+	}
+	return nil
+}
+
+// warmKeysBatch loads the nodes on the paths of [keys] into the cache while
+// holding [db.commitLock], which guarantees that no stale node is cached.
+func (db *merkleDB) warmKeysBatch(ctx context.Context, keys []Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	// reuse the view so that it can keep repeated nodes in memory
+	tempView, err := newTrieView(db, db, ViewChanges{})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := tempView.visitPathToKey(key, db.cacheNode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *merkleDB) WarmPrefix(ctx context.Context, prefix []byte) <-chan error {
+	prefixKey := ToKey(prefix)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- db.warmPrefix(ctx, prefixKey)
+		close(result)
+	}()
+	return result
+}
+
+// warmNode is a node that will be loaded into the cache.
+type warmNode struct {
+	key      Key
+	hasValue bool
+}
+
+// warmPrefix walks the nodes that are either ancestors of [prefix] or have
+// [prefix] as a prefix, and loads them into the cache.
+func (db *merkleDB) warmPrefix(ctx context.Context, prefix Key) error {
+	// The sentinel node is the ancestor of all nodes.
+	remaining := []warmNode{{}}
+	for len(remaining) > 0 {
+		var err error
+		remaining, err = db.warmPrefixBatch(ctx, prefix, remaining)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warmPrefixBatch loads up to [warmBatchSize] nodes of [remaining] into the
+// cache while holding [db.commitLock] and returns the nodes that still need to
+// be loaded.
+//
+// Nodes in [remaining] may have been found before a commit. A node that has
+// since been removed is skipped, and the children of a node that is found are
+// read after any such commit, so no stale node is cached.
+func (db *merkleDB) warmPrefixBatch(ctx context.Context, prefix Key, remaining []warmNode) ([]warmNode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	for i := 0; i < warmBatchSize && len(remaining) > 0; i++ {
+		next := remaining[len(remaining)-1]
+		remaining = remaining[:len(remaining)-1]
+
+		n, err := db.getEditableNode(next.key, next.hasValue)
+		if err == database.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := db.cacheNode(n); err != nil {
+			return nil, err
+		}
+
+		for index, entry := range n.children {
+			childKey := n.key.Extend(ToToken(index, db.tokenSize), entry.compressedKey)
+			if !childKey.HasPrefix(prefix) && !prefix.HasPrefix(childKey) {
+				continue
+			}
+			remaining = append(remaining, warmNode{
+				key:      childKey,
+				hasValue: entry.hasValue,
+			})
+		}
+	}
+	return remaining, nil
 }
 
 func (db *merkleDB) Get(key []byte) ([]byte, error) {
@@ -562,6 +1019,10 @@ func (db *merkleDB) getValueWithoutLock(key Key) ([]byte, error) {
 	return n.value.Value(), nil
 }
 
+func (db *merkleDB) CommitEpoch() uint64 {
+	return db.commitEpoch.Load()
+}
+
 func (db *merkleDB) GetMerkleRoot(ctx context.Context) (ids.ID, error) {
 	_, span := db.infoTracer.Start(ctx, "MerkleDB.GetMerkleRoot")
 	defer span.End()
@@ -602,7 +1063,7 @@ func (db *merkleDB) getProof(ctx context.Context, key []byte) (*Proof, error) {
 		return nil, database.ErrClosed
 	}
 
-	view, err := newTrieView(db, db, ViewChanges{})
+	view, err := newTrieView(db, db, ViewChanges{Priority: HighPriority})
 	if err != nil {
 		return nil, err
 	}
@@ -615,11 +1076,12 @@ func (db *merkleDB) GetRangeProof(
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
+	maxBytes int,
 ) (*RangeProof, error) {
 	db.commitLock.RLock()
 	defer db.commitLock.RUnlock()
 
-	return db.getRangeProofAtRoot(ctx, db.getMerkleRoot(), start, end, maxLength)
+	return db.getRangeProofAtRoot(ctx, db.getMerkleRoot(), start, end, maxLength, maxBytes)
 }
 
 func (db *merkleDB) GetRangeProofAtRoot(
@@ -628,11 +1090,12 @@ func (db *merkleDB) GetRangeProofAtRoot(
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
+	maxBytes int,
 ) (*RangeProof, error) {
 	db.commitLock.RLock()
 	defer db.commitLock.RUnlock()
 
-	return db.getRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+	return db.getRangeProofAtRoot(ctx, rootID, start, end, maxLength, maxBytes)
 }
 
 // Assumes [db.commitLock] is read locked.
@@ -643,6 +1106,7 @@ func (db *merkleDB) getRangeProofAtRoot(
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
+	maxBytes int,
 ) (*RangeProof, error) {
 	if db.closed {
 		return nil, database.ErrClosed
@@ -650,12 +1114,15 @@ func (db *merkleDB) getRangeProofAtRoot(
 	if maxLength <= 0 {
 		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxLength)
 	}
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxBytes, maxBytes)
+	}
 
 	historicalView, err := db.getHistoricalViewForRange(rootID, start, end)
 	if err != nil {
 		return nil, err
 	}
-	return historicalView.GetRangeProof(ctx, start, end, maxLength)
+	return historicalView.GetRangeProof(ctx, start, end, maxLength, maxBytes)
 }
 
 func (db *merkleDB) GetChangeProof(
@@ -785,6 +1252,14 @@ func (db *merkleDB) NewView(
 	return newView, nil
 }
 
+func (db *merkleDB) LockRange(ctx context.Context, start, end maybe.Maybe[[]byte]) (*RangeLock, error) {
+	return db.rangeLocker.lockRange(ctx, start, end)
+}
+
+func (db *merkleDB) Subscribe() <-chan CommitEvent {
+	return db.commitNotifier.subscribe()
+}
+
 func (db *merkleDB) Has(k []byte) (bool, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -832,6 +1307,10 @@ func (db *merkleDB) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	return db.valueNodeDB.newIteratorWithStartAndPrefix(start, prefix)
 }
 
+func (db *merkleDB) NewProofIteratorWithStartAndPrefix(start, prefix []byte) ProofIterator {
+	return newProofIterator(db.NewIteratorWithStartAndPrefix(start, prefix), db)
+}
+
 func (db *merkleDB) Put(k, v []byte) error {
 	return db.PutContext(context.Background(), k, v)
 }
@@ -898,34 +1377,56 @@ func (db *merkleDB) commitBatch(ops []database.BatchOp) error {
 // commitChanges commits the changes in [trieToCommit] to [db].
 // Assumes [trieToCommit]'s node IDs have been calculated.
 func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) error {
+	if trieToCommit == nil {
+		return db.commitViewStackChanges(ctx, nil, nil)
+	}
+	return db.commitViewStackChanges(ctx, []*trieView{trieToCommit}, trieToCommit.changes)
+}
+
+// commitViewStackChanges commits [changes], which are the combined changes of
+// [views], to [db].
+// The parent of [views][0] must be [db] and the parent of every other view
+// must be the view before it.
+// Assumes the node IDs of [views] have been calculated.
+func (db *merkleDB) commitViewStackChanges(ctx context.Context, views []*trieView, changes *changeSummary) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
 	switch {
 	case db.closed:
 		return database.ErrClosed
-	case trieToCommit == nil:
+	case len(views) == 0:
 		return nil
-	case trieToCommit.isInvalid():
-		return ErrInvalid
-	case trieToCommit.committed:
-		return ErrCommitted
-	case trieToCommit.db != trieToCommit.getParentTrie():
+	}
+	for _, view := range views {
+		if err := view.checkValid(); err != nil {
+			return err
+		}
+		if view.committed {
+			return ErrCommitted
+		}
+	}
+	if views[0].db != views[0].getParentTrie() {
 		return ErrParentNotDatabase
 	}
 
-	changes := trieToCommit.changes
 	_, span := db.infoTracer.Start(ctx, "MerkleDB.commitChanges", oteltrace.WithAttributes(
+		attribute.Int("viewsCommitted", len(views)),
 		attribute.Int("nodesChanged", len(changes.nodes)),
 		attribute.Int("valuesChanged", len(changes.values)),
 	))
 	defer span.End()
 
-	// invalidate all child views except for the view being committed
-	db.invalidateChildrenExcept(trieToCommit)
+	// Start a new commit epoch and invalidate all child views except for the
+	// views being committed, before any of the committed changes are visible.
+	epoch := db.commitEpoch.Add(1)
+	db.invalidateChildrenExcept(views[0], epoch)
+	for i, view := range views[:len(views)-1] {
+		view.invalidateChildrenExcept(views[i+1], epoch)
+	}
 
-	// move any child views of the committed trie onto the db
-	db.moveChildViewsToDB(trieToCommit)
+	// move any child views of the last committed trie onto the db
+	db.moveChildViewsToDB(views[len(views)-1])
 
 	if len(changes.nodes) == 0 {
 		return nil
@@ -936,10 +1437,17 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 		return errNoNewSentinel
 	}
 
+	coldNodes := db.intermediateNodeDB.coldNodes
+	if coldNodes != nil {
+		if err := coldNodes.startCommit(); err != nil {
+			return err
+		}
+	}
+
 	currentValueNodeBatch := db.valueNodeDB.NewBatch()
 
 	_, nodesSpan := db.infoTracer.Start(ctx, "MerkleDB.commitChanges.writeNodes")
-	for key, nodeChange := range changes.nodes {
+	writeNode := func(key Key, nodeChange *change[*node]) error {
 		shouldAddIntermediate := nodeChange.after != nil && !nodeChange.after.hasValue()
 		shouldDeleteIntermediate := !shouldAddIntermediate && nodeChange.before != nil && !nodeChange.before.hasValue()
 
@@ -948,12 +1456,10 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 
 		if shouldAddIntermediate {
 			if err := db.intermediateNodeDB.Put(key, nodeChange.after); err != nil {
-				nodesSpan.End()
 				return err
 			}
 		} else if shouldDeleteIntermediate {
 			if err := db.intermediateNodeDB.Delete(key); err != nil {
-				nodesSpan.End()
 				return err
 			}
 		}
@@ -963,11 +1469,27 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 		} else if shouldDeleteValue {
 			currentValueNodeBatch.Delete(key)
 		}
+		return nil
+	}
+	if db.deterministicHashing {
+		for _, key := range sortedKeys(changes.nodes) {
+			if err := writeNode(key, changes.nodes[key]); err != nil {
+				nodesSpan.End()
+				return err
+			}
+		}
+	} else {
+		for key, nodeChange := range changes.nodes {
+			if err := writeNode(key, nodeChange); err != nil {
+				nodesSpan.End()
+				return err
+			}
+		}
 	}
 	nodesSpan.End()
 
 	_, commitSpan := db.infoTracer.Start(ctx, "MerkleDB.commitChanges.valueNodeDBCommit")
-	err := currentValueNodeBatch.Write()
+	err := db.writeValueNodes(currentValueNodeBatch, views)
 	commitSpan.End()
 	if err != nil {
 		return err
@@ -977,10 +1499,125 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 	// so that we don't need to clean up on error.
 	db.sentinelNode = sentinelChange.after
 	db.rootID = changes.rootID
+	if quotaUsage := views[len(views)-1].quotaUsage; quotaUsage != nil {
+		db.quotaUsage = quotaUsage
+	}
 	db.history.record(changes)
+	db.callOnCommitHooks(changes)
+	if coldNodes != nil {
+		// Nodes that became cold are migrated in the background.
+		coldNodes.finishCommit()
+	}
 	return nil
 }
 
+// Writes [valueNodeBatch] to the base db. If key epochs are tracked outside
+// of the trie, the epochs of the keys changed by [views] are written in the
+// same batch so that they can't diverge from the values.
+func (db *merkleDB) writeValueNodes(valueNodeBatch *valueNodeBatch, views []*trieView) error {
+	dbBatch := db.baseDB.NewBatch()
+	if err := valueNodeBatch.writeTo(dbBatch); err != nil {
+		return err
+	}
+	if db.trackKeyEpochs && !db.hashKeyEpochs() {
+		if err := writeUnhashedKeyEpochs(dbBatch, views); err != nil {
+			return err
+		}
+	}
+	return dbBatch.Write()
+}
+
+// CommitViewStack commits [views] to the database in a single batch,
+// resulting in a single root update.
+// The parent of [views][0] must be the database and the parent of every other
+// view must be the view before it.
+//
+// Assumes [db.commitLock] and [db.lock] aren't held.
+func (db *merkleDB) CommitViewStack(ctx context.Context, views []TrieView) error {
+	ctx, span := db.infoTracer.Start(ctx, "MerkleDB.CommitViewStack", oteltrace.WithAttributes(
+		attribute.Int("viewCount", len(views)),
+	))
+	defer span.End()
+
+	db.commitLock.Lock()
+	defer db.commitLock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	if len(views) == 0 {
+		return nil
+	}
+
+	trieViews := make([]*trieView, len(views))
+	for i, view := range views {
+		trieView, ok := view.(*trieView)
+		if !ok {
+			return ErrUnsupportedView
+		}
+		if trieView.squashedFrom != nil {
+			return ErrSquashedViewInStack
+		}
+		if i > 0 && trieView.getParentTrie() != views[i-1] {
+			return ErrNotViewStack
+		}
+		trieViews[i] = trieView
+	}
+
+	changes := make([]*changeSummary, len(trieViews))
+	for i, trieView := range trieViews {
+		trieView.commitLock.Lock()
+		defer trieView.commitLock.Unlock()
+
+		if err := trieView.calculateNodeIDs(ctx); err != nil {
+			return err
+		}
+		changes[i] = trieView.changes
+	}
+
+	mergedChanges := mergeChangeSummaries(changes)
+	if err := db.commitViewStackChanges(ctx, trieViews, mergedChanges); err != nil {
+		return err
+	}
+
+	for _, trieView := range trieViews {
+		trieView.committed = true
+	}
+	db.commitNotifier.notify(mergedChanges.rootID, len(mergedChanges.values))
+	return nil
+}
+
+// mergeChangeSummaries returns the changes resulting from applying each of
+// [changes] in order.
+// Assumes [changes] is non-empty.
+func mergeChangeSummaries(changes []*changeSummary) *changeSummary {
+	merged := newChangeSummary(len(changes[len(changes)-1].values))
+	for _, summary := range changes {
+		for key, nodeChange := range summary.nodes {
+			if existing, ok := merged.nodes[key]; ok {
+				existing.after = nodeChange.after
+				continue
+			}
+			merged.nodes[key] = &change[*node]{
+				before: nodeChange.before,
+				after:  nodeChange.after,
+			}
+		}
+		for key, valueChange := range summary.values {
+			if existing, ok := merged.values[key]; ok {
+				existing.after = valueChange.after
+				continue
+			}
+			merged.values[key] = &change[maybe.Maybe[[]byte]]{
+				before: valueChange.before,
+				after:  valueChange.after,
+			}
+		}
+	}
+	merged.rootID = changes[len(changes)-1].rootID
+	return merged
+}
+
 // moveChildViewsToDB removes any child views from the trieToCommit and moves them to the db
 // assumes [db.lock] is held
 func (db *merkleDB) moveChildViewsToDB(trieToCommit *trieView) {
@@ -1000,6 +1637,12 @@ func (*merkleDB) CommitToDB(context.Context) error {
 	return nil
 }
 
+// Squash returns the db since it has no ancestors.
+// This exists to satisfy the TrieView interface.
+func (db *merkleDB) Squash(context.Context) (TrieView, error) {
+	return db, nil
+}
+
 // This is defined on merkleDB instead of ChangeProof
 // because it accesses database internals.
 // Assumes [db.lock] isn't held.
@@ -1138,14 +1781,15 @@ func (db *merkleDB) VerifyChangeProof(
 	return nil
 }
 
-// Invalidates and removes any child views that aren't [exception].
+// Invalidates and removes any child views that aren't [exception] because of
+// the commit that started commit epoch [epoch].
 // Assumes [db.lock] is held.
-func (db *merkleDB) invalidateChildrenExcept(exception *trieView) {
+func (db *merkleDB) invalidateChildrenExcept(exception *trieView, epoch uint64) {
 	isTrackedView := false
 
 	for _, childView := range db.childViews {
 		if childView != exception {
-			childView.invalidate()
+			childView.invalidate(epoch)
 		} else {
 			isTrackedView = true
 		}
@@ -1285,13 +1929,19 @@ func (db *merkleDB) Clear() error {
 	if err := db.intermediateNodeDB.Clear(); err != nil {
 		return err
 	}
+	if err := database.AtomicClearPrefix(db.baseDB, db.baseDB, keyEpochPrefix); err != nil {
+		return err
+	}
 
 	// Clear root
 	db.sentinelNode = newNode(Key{})
 	db.rootID = db.sentinelNode.calculateID(db.metrics)
+	db.quotaUsage = make([]PrefixUsage, len(db.quotas))
 
-	// Clear history
+	// Clear history, but keep the height since it must never decrease.
+	height := db.history.height
 	db.history = newTrieHistory(db.history.maxHistoryLen)
+	db.history.height = height
 	db.history.record(&changeSummary{
 		rootID: db.getMerkleRoot(),
 		values: map[Key]*change[maybe.Maybe[[]byte]]{},
@@ -328,6 +328,7 @@ func Test_MerkleDB_CommitRangeProof_DeletesValuesInRange(t *testing.T) {
 		maybe.Nothing[[]byte](),
 		maybe.Some([]byte("key3")),
 		10,
+		0,
 	)
 	require.NoError(err)
 
@@ -368,6 +369,7 @@ func Test_MerkleDB_CommitRangeProof_EmptyTrie(t *testing.T) {
 		maybe.Some([]byte("key1")),
 		maybe.Some([]byte("key3")),
 		10,
+		0,
 	)
 	require.NoError(err)
 
@@ -405,6 +407,7 @@ func Test_MerkleDB_CommitRangeProof_TrieWithInitialValues(t *testing.T) {
 		maybe.Some([]byte("key1")),
 		maybe.Some([]byte("key3")),
 		10,
+		0,
 	)
 	require.NoError(err)
 
@@ -563,7 +566,7 @@ func TestDatabaseCommitChanges(t *testing.T) {
 	// Committing an invalid view should fail.
 	invalidView, err := db.NewView(context.Background(), ViewChanges{})
 	require.NoError(err)
-	invalidView.(*trieView).invalidate()
+	invalidView.(*trieView).invalidate(1)
 	err = invalidView.CommitToDB(context.Background())
 	require.ErrorIs(err, ErrInvalid)
 
@@ -624,9 +627,9 @@ func TestDatabaseCommitChanges(t *testing.T) {
 	require.Equal(view1Root, db.getMerkleRoot())
 
 	// Make sure view2 is invalid and view1 and view3 is valid.
-	require.False(view1.invalidated)
-	require.True(view2.invalidated)
-	require.False(view3.invalidated)
+	require.False(view1.isInvalid())
+	require.True(view2.isInvalid())
+	require.False(view3.isInvalid())
 
 	// Make sure view2 isn't tracked by the database.
 	require.NotContains(db.childViews, view2)
@@ -661,28 +664,72 @@ func TestDatabaseInvalidateChildrenExcept(t *testing.T) {
 	require.IsType(&trieView{}, view3Intf)
 	view3 := view3Intf.(*trieView)
 
-	db.invalidateChildrenExcept(view1)
+	db.invalidateChildrenExcept(view1, 1)
 
 	// Make sure view1 is valid and view2 and view3 are invalid.
-	require.False(view1.invalidated)
-	require.True(view2.invalidated)
-	require.True(view3.invalidated)
+	require.False(view1.isInvalid())
+	require.True(view2.isInvalid())
+	require.True(view3.isInvalid())
 	require.Contains(db.childViews, view1)
 	require.Len(db.childViews, 1)
 
-	db.invalidateChildrenExcept(nil)
+	db.invalidateChildrenExcept(nil, 2)
 
 	// Make sure all views are invalid.
-	require.True(view1.invalidated)
-	require.True(view2.invalidated)
-	require.True(view3.invalidated)
+	require.True(view1.isInvalid())
+	require.True(view2.isInvalid())
+	require.True(view3.isInvalid())
 	require.Empty(db.childViews)
 
 	// Calling with an untracked view doesn't add the untracked view
-	db.invalidateChildrenExcept(view1)
+	db.invalidateChildrenExcept(view1, 1)
 	require.Empty(db.childViews)
 }
 
+func TestCommitEpoch(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.Zero(db.CommitEpoch())
+
+	require.NoError(db.Put([]byte{0}, []byte{0}))
+	require.Equal(uint64(1), db.CommitEpoch())
+
+	viewToCommitIntf, err := db.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: []database.BatchOp{
+				{Key: []byte{1}, Value: []byte{1}},
+			},
+		},
+	)
+	require.NoError(err)
+	viewToCommit := viewToCommitIntf.(*trieView)
+	require.Equal(uint64(1), viewToCommit.createdEpoch)
+
+	siblingIntf, err := db.NewView(context.Background(), ViewChanges{})
+	require.NoError(err)
+	sibling := siblingIntf.(*trieView)
+
+	childIntf, err := viewToCommit.NewView(context.Background(), ViewChanges{})
+	require.NoError(err)
+	child := childIntf.(*trieView)
+
+	require.NoError(viewToCommit.CommitToDB(context.Background()))
+	require.Equal(uint64(2), db.CommitEpoch())
+
+	// The sibling was invalidated by the commit that started epoch 2.
+	_, err = sibling.GetValue(context.Background(), []byte{0})
+	require.ErrorIs(err, ErrInvalid)
+	require.ErrorContains(err, "created in commit epoch 1 was invalidated in commit epoch 2")
+
+	// The child of the committed view is still valid.
+	value, err := child.GetValue(context.Background(), []byte{1})
+	require.NoError(err)
+	require.Equal([]byte{1}, value)
+}
+
 func Test_MerkleDB_Random_Insert_Ordering(t *testing.T) {
 	require := require.New(t)
 
@@ -809,7 +856,8 @@ func TestMerkleDBClear(t *testing.T) {
 
 	// Assert caches are empty.
 	require.Zero(db.valueNodeDB.nodeCache.Len())
-	require.Zero(db.intermediateNodeDB.nodeCache.currentSize)
+	require.Zero(db.intermediateNodeDB.nodeCache.Len())
+	require.Zero(db.intermediateNodeDB.writeBuffer.currentSize)
 
 	// Assert history has only the clearing change.
 	require.Len(db.history.lastChanges, 1)
@@ -819,6 +867,137 @@ func TestMerkleDBClear(t *testing.T) {
 	require.Empty(change.values)
 }
 
+func TestCommitChangeProofWithPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         ConflictPolicy
+		expectedErr    error
+		expectedValues map[string][]byte
+	}{
+		{
+			name:        "fail",
+			policy:      FailOnConflict,
+			expectedErr: ErrChangeProofConflict,
+			expectedValues: map[string][]byte{
+				"key1": []byte("value1"),
+				"key2": []byte("local"),
+			},
+		},
+		{
+			name:   "overwrite",
+			policy: OverwriteOnConflict,
+			expectedValues: map[string][]byte{
+				"key1": []byte("value2"),
+				"key2": []byte("value2"),
+			},
+		},
+		{
+			name:   "skip",
+			policy: SkipOnConflict,
+			expectedValues: map[string][]byte{
+				"key1": []byte("value2"),
+				"key2": []byte("local"),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			source, err := getBasicDB()
+			require.NoError(err)
+			target, err := getBasicDB()
+			require.NoError(err)
+
+			for _, db := range []*merkleDB{source, target} {
+				require.NoError(db.Put([]byte("key1"), []byte("value1")))
+			}
+			startRoot := source.getMerkleRoot()
+			require.Equal(startRoot, target.getMerkleRoot())
+
+			require.NoError(source.Put([]byte("key1"), []byte("value2")))
+			require.NoError(source.Put([]byte("key2"), []byte("value2")))
+			endRoot := source.getMerkleRoot()
+
+			proof, err := source.GetChangeProof(
+				context.Background(),
+				startRoot,
+				endRoot,
+				maybe.Nothing[[]byte](),
+				maybe.Nothing[[]byte](),
+				10,
+			)
+			require.NoError(err)
+
+			// Modify a key in the proof locally.
+			require.NoError(target.Put([]byte("key2"), []byte("local")))
+
+			err = target.CommitChangeProofWithPolicy(context.Background(), startRoot, proof, test.policy)
+			require.ErrorIs(err, test.expectedErr)
+
+			for key, expectedValue := range test.expectedValues {
+				value, err := target.Get([]byte(key))
+				require.NoError(err)
+				require.Equal(expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestCommitViewStack(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	view1, err := db.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{
+			{Key: []byte{1}, Value: []byte{1}},
+			{Key: []byte{2}, Value: []byte{1}},
+		},
+	})
+	require.NoError(err)
+	view2, err := view1.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{
+			{Key: []byte{2}, Value: []byte{2}},
+			{Key: []byte{3}, Value: []byte{2}},
+		},
+	})
+	require.NoError(err)
+	view3, err := view2.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{
+			{Key: []byte{1}, Delete: true},
+		},
+	})
+	require.NoError(err)
+
+	view1Root, err := view1.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	view3Root, err := view3.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	require.ErrorIs(db.CommitViewStack(context.Background(), []TrieView{view1, view3}), ErrNotViewStack)
+	require.ErrorIs(db.CommitViewStack(context.Background(), []TrieView{view2, view3}), ErrParentNotDatabase)
+
+	require.NoError(db.CommitViewStack(context.Background(), []TrieView{view1, view2, view3}))
+	require.Equal(view3Root, db.getMerkleRoot())
+
+	_, err = db.Get([]byte{1})
+	require.ErrorIs(err, database.ErrNotFound)
+	value, err := db.Get([]byte{2})
+	require.NoError(err)
+	require.Equal([]byte{2}, value)
+	value, err = db.Get([]byte{3})
+	require.NoError(err)
+	require.Equal([]byte{2}, value)
+
+	// Only the root of the last view should have been recorded.
+	require.Contains(db.history.lastChanges, view3Root)
+	require.NotContains(db.history.lastChanges, view1Root)
+
+	require.ErrorIs(view3.CommitToDB(context.Background()), ErrCommitted)
+}
+
 func FuzzMerkleDBEmptyRandomizedActions(f *testing.F) {
 	f.Fuzz(
 		func(
@@ -947,7 +1126,7 @@ func runRandDBTest(require *require.Assertions, r *rand.Rand, rt randTest, token
 				end = maybe.Some(step.value)
 			}
 
-			rangeProof, err := db.GetRangeProofAtRoot(context.Background(), root, start, end, maxProofLen)
+			rangeProof, err := db.GetRangeProofAtRoot(context.Background(), root, start, end, maxProofLen, 0)
 			require.NoError(err)
 			require.LessOrEqual(len(rangeProof.KeyValues), maxProofLen)
 
@@ -1242,3 +1421,56 @@ func insertRandomKeyValues(
 		}
 	}
 }
+
+func TestWarmKeys(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	keys := [][]byte{{0}, {1}, {1, 0}, {2}}
+	for _, key := range keys {
+		require.NoError(db.Put(key, key))
+	}
+	db.valueNodeDB.nodeCache.Flush()
+
+	require.NoError(<-db.WarmKeys(context.Background(), [][]byte{{1, 0}, {3}}))
+	for _, key := range keys {
+		_, ok := db.valueNodeDB.nodeCache.Get(ToKey(key))
+		// Only the nodes on the path of {1, 0} are warmed.
+		require.Equal(bytes.HasPrefix([]byte{1, 0}, key), ok)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(<-db.WarmKeys(ctx, keys), context.Canceled)
+
+	require.NoError(db.Close())
+	require.ErrorIs(<-db.WarmKeys(context.Background(), keys), database.ErrClosed)
+}
+
+func TestWarmPrefix(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	keys := [][]byte{{0}, {1}, {1, 0}, {1, 1}, {2}}
+	for _, key := range keys {
+		require.NoError(db.Put(key, key))
+	}
+	db.valueNodeDB.nodeCache.Flush()
+
+	require.NoError(<-db.WarmPrefix(context.Background(), []byte{1}))
+	for _, key := range keys {
+		_, ok := db.valueNodeDB.nodeCache.Get(ToKey(key))
+		require.Equal(bytes.HasPrefix(key, []byte{1}), ok)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(<-db.WarmPrefix(ctx, nil), context.Canceled)
+
+	require.NoError(db.Close())
+	require.ErrorIs(<-db.WarmPrefix(context.Background(), nil), database.ErrClosed)
+}
@@ -0,0 +1,166 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/resource"
+)
+
+// The number of root generations observed before the concurrency limit is
+// adjusted.
+const hashConcurrencyWindow = 8
+
+// hashConcurrencyLimiter limits the number of goroutines inside
+// [calculateNodeIDsHelper] at any given time.
+//
+// If [minLimit] < [maxLimit], the limit adapts to the observed root generation
+// latency. After every [hashConcurrencyWindow] root generations, the average
+// time spent hashing a node is compared to that of the previous window. If it
+// improved, the limit keeps moving in the same direction. Otherwise, the
+// direction is reversed. The limit never exceeds the number of CPUs that are
+// available to the process.
+type hashConcurrencyLimiter struct {
+	// Reports the CPU usage of the process. May be nil.
+	cpuUser resource.CPUUser
+	// Returns the number of CPUs the process may use.
+	numCPUs func() int
+
+	lock sync.Mutex
+	// Signalled when a goroutine is released or [limit] increases.
+	cond *sync.Cond
+
+	minLimit int
+	maxLimit int
+	limit    int
+	inUse    int
+
+	// Either 1 or -1.
+	direction int
+	// The number of root generations observed in the current window.
+	windowCount int
+	// The number of nodes hashed in the current window.
+	windowNodes int
+	// The time spent generating roots in the current window.
+	windowDuration time.Duration
+	// The average time spent hashing a node in the previous window.
+	// 0 if there was no previous window.
+	lastNodeDuration time.Duration
+}
+
+func newHashConcurrencyLimiter(minLimit, maxLimit uint, cpuUser resource.CPUUser) *hashConcurrencyLimiter {
+	l := &hashConcurrencyLimiter{
+		cpuUser:   cpuUser,
+		numCPUs:   func() int { return runtime.GOMAXPROCS(0) },
+		minLimit:  int(minLimit),
+		maxLimit:  int(maxLimit),
+		limit:     int(maxLimit),
+		direction: -1,
+	}
+	l.cond = sync.NewCond(&l.lock)
+	return l
+}
+
+// Acquire blocks until a goroutine is available.
+func (l *hashConcurrencyLimiter) Acquire() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for l.inUse >= l.limit {
+		l.cond.Wait()
+	}
+	l.inUse++
+}
+
+// TryAcquire returns true iff a goroutine was available.
+func (l *hashConcurrencyLimiter) TryAcquire() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.inUse >= l.limit {
+		return false
+	}
+	l.inUse++
+	return true
+}
+
+// Release returns a goroutine acquired by Acquire or TryAcquire.
+func (l *hashConcurrencyLimiter) Release() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.inUse--
+	l.cond.Signal()
+}
+
+// Limit returns the current maximum number of goroutines.
+func (l *hashConcurrencyLimiter) Limit() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.limit
+}
+
+// Observe records that generating a root hashed [numNodes] nodes in
+// [duration].
+func (l *hashConcurrencyLimiter) Observe(numNodes int, duration time.Duration) {
+	if l.minLimit >= l.maxLimit || numNodes == 0 {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.windowCount++
+	l.windowNodes += numNodes
+	l.windowDuration += duration
+	if l.windowCount < hashConcurrencyWindow {
+		return
+	}
+
+	nodeDuration := l.windowDuration / time.Duration(l.windowNodes)
+	if l.lastNodeDuration != 0 && nodeDuration > l.lastNodeDuration {
+		l.direction = -l.direction
+	}
+	l.lastNodeDuration = nodeDuration
+	l.windowCount = 0
+	l.windowNodes = 0
+	l.windowDuration = 0
+
+	newLimit := l.limit + l.direction
+	if available := l.availableCPUs(); newLimit > available {
+		// Hashing can't go faster than the CPUs that are free to do it.
+		newLimit = available
+		l.direction = -1
+	}
+	switch {
+	case newLimit < l.minLimit:
+		newLimit = l.minLimit
+		l.direction = 1
+	case newLimit > l.maxLimit:
+		newLimit = l.maxLimit
+		l.direction = -1
+	}
+	if newLimit > l.limit {
+		l.cond.Broadcast()
+	}
+	l.limit = newLimit
+}
+
+// availableCPUs returns the number of CPUs that may be used for hashing. CPUs
+// already used by hashing are considered available.
+func (l *hashConcurrencyLimiter) availableCPUs() int {
+	numCPUs := l.numCPUs()
+	if l.cpuUser == nil {
+		return numCPUs
+	}
+	otherUsage := l.cpuUser.CPUUsage() - float64(l.inUse)
+	if otherUsage < 0 {
+		otherUsage = 0
+	}
+	return numCPUs - int(otherUsage)
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/utils/resource"
+)
+
+func TestHashConcurrencyLimiterAcquire(t *testing.T) {
+	require := require.New(t)
+
+	l := newHashConcurrencyLimiter(2, 2, nil)
+	require.True(l.TryAcquire())
+	require.True(l.TryAcquire())
+	require.False(l.TryAcquire())
+
+	acquired := make(chan struct{})
+	go func() {
+		l.Acquire()
+		close(acquired)
+	}()
+
+	l.Release()
+	<-acquired
+	require.False(l.TryAcquire())
+}
+
+func TestHashConcurrencyLimiterFixed(t *testing.T) {
+	require := require.New(t)
+
+	l := newHashConcurrencyLimiter(4, 4, nil)
+	for i := 0; i < 10*hashConcurrencyWindow; i++ {
+		l.Observe(1, time.Duration(i+1)*time.Second)
+	}
+	require.Equal(4, l.Limit())
+}
+
+func TestHashConcurrencyLimiterAdapts(t *testing.T) {
+	require := require.New(t)
+
+	l := newHashConcurrencyLimiter(1, 4, nil)
+	l.numCPUs = func() int { return 8 }
+	require.Equal(4, l.Limit())
+
+	observeWindow := func(nodeDuration time.Duration) {
+		for i := 0; i < hashConcurrencyWindow; i++ {
+			l.Observe(10, 10*nodeDuration)
+		}
+	}
+
+	// The first window always reduces the limit.
+	observeWindow(time.Millisecond)
+	require.Equal(3, l.Limit())
+
+	// Hashing got faster, so the limit keeps decreasing.
+	observeWindow(time.Millisecond / 2)
+	require.Equal(2, l.Limit())
+
+	// Hashing got slower, so the limit increases.
+	observeWindow(time.Millisecond)
+	require.Equal(3, l.Limit())
+
+	// Hashing got faster, so the limit keeps increasing up to the maximum.
+	observeWindow(time.Millisecond / 2)
+	require.Equal(4, l.Limit())
+	observeWindow(time.Millisecond / 4)
+	require.Equal(4, l.Limit())
+
+	// Hashing didn't get slower, so the limit keeps moving away from the
+	// maximum down to the minimum.
+	for i := 0; i < 3; i++ {
+		observeWindow(time.Millisecond / 4)
+	}
+	require.Equal(1, l.Limit())
+}
+
+func TestHashConcurrencyLimiterCPUAvailability(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	cpuUser := resource.NewMockUser(ctrl)
+	l := newHashConcurrencyLimiter(1, 8, cpuUser)
+	l.numCPUs = func() int { return 8 }
+
+	// Other work is using 6 of the 8 CPUs, so hashing is limited to 2
+	// goroutines.
+	cpuUser.EXPECT().CPUUsage().Return(6.0).AnyTimes()
+	for i := 0; i < hashConcurrencyWindow; i++ {
+		l.Observe(1, time.Millisecond)
+	}
+	require.Equal(2, l.Limit())
+}
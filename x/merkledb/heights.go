@@ -0,0 +1,110 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+var (
+	ErrHeightDecreased = errors.New("height decreased")
+	ErrFutureHeight    = errors.New("height is in the future")
+
+	errStartHeightAfterEndHeight = errors.New("start height > end height")
+)
+
+// HeightIndexer tags commits with a user provided height, such as the height
+// of the block that caused the commit, so that historical roots can be looked
+// up by height.
+// Heights are only tracked for the changes kept in the history, and aren't
+// persisted across restarts.
+type HeightIndexer interface {
+	// SetHeight sets the height that commits are tagged with after this call
+	// returns. Multiple commits may be tagged with the same height.
+	// Returns [ErrHeightDecreased] if [height] is less than the current
+	// height.
+	SetHeight(height uint64) error
+
+	// RootAtHeight returns the root after the last commit tagged with a
+	// height <= [height].
+	// Returns [ErrFutureHeight] if [height] is greater than the current
+	// height.
+	// Returns [ErrInsufficientHistory] if the history doesn't go back to
+	// [height].
+	RootAtHeight(ctx context.Context, height uint64) (ids.ID, error)
+
+	// GetChangeProofByHeights returns a proof for a subset of the key/value
+	// changes in key range [start, end] that occurred between the roots at
+	// [startHeight] and [endHeight]. See [ChangeProofer.GetChangeProof].
+	GetChangeProofByHeights(
+		ctx context.Context,
+		startHeight uint64,
+		endHeight uint64,
+		start maybe.Maybe[[]byte],
+		end maybe.Maybe[[]byte],
+		maxLength int,
+	) (*ChangeProof, error)
+}
+
+func (db *merkleDB) SetHeight(height uint64) error {
+	db.commitLock.Lock()
+	defer db.commitLock.Unlock()
+
+	if height < db.history.height {
+		return fmt.Errorf("%w: from %d to %d", ErrHeightDecreased, db.history.height, height)
+	}
+	db.history.height = height
+	return nil
+}
+
+func (db *merkleDB) RootAtHeight(_ context.Context, height uint64) (ids.ID, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return ids.Empty, database.ErrClosed
+	}
+	return db.history.getRootAtHeight(height)
+}
+
+func (db *merkleDB) GetChangeProofByHeights(
+	ctx context.Context,
+	startHeight uint64,
+	endHeight uint64,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) (*ChangeProof, error) {
+	if startHeight > endHeight {
+		return nil, fmt.Errorf("%w: %d > %d", errStartHeightAfterEndHeight, startHeight, endHeight)
+	}
+
+	startRootID, endRootID, err := db.getRootsAtHeights(startHeight, endHeight)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetChangeProof(ctx, startRootID, endRootID, start, end, maxLength)
+}
+
+func (db *merkleDB) getRootsAtHeights(startHeight uint64, endHeight uint64) (ids.ID, ids.ID, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return ids.Empty, ids.Empty, database.ErrClosed
+	}
+
+	startRootID, err := db.history.getRootAtHeight(startHeight)
+	if err != nil {
+		return ids.Empty, ids.Empty, err
+	}
+	endRootID, err := db.history.getRootAtHeight(endHeight)
+	return startRootID, endRootID, err
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+func TestRootAtHeight(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	emptyRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	require.NoError(db.SetHeight(1))
+	require.NoError(db.Put([]byte{1}, []byte{1}))
+	root1, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Height 2 has no commits.
+	require.NoError(db.SetHeight(3))
+	require.NoError(db.Put([]byte{3}, []byte{3}))
+	require.NoError(db.Put([]byte{3}, []byte{4}))
+	root3, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	root, err := db.RootAtHeight(context.Background(), 0)
+	require.NoError(err)
+	require.Equal(emptyRoot, root)
+
+	root, err = db.RootAtHeight(context.Background(), 1)
+	require.NoError(err)
+	require.Equal(root1, root)
+
+	root, err = db.RootAtHeight(context.Background(), 2)
+	require.NoError(err)
+	require.Equal(root1, root)
+
+	// The last commit at a height determines its root.
+	root, err = db.RootAtHeight(context.Background(), 3)
+	require.NoError(err)
+	require.Equal(root3, root)
+
+	_, err = db.RootAtHeight(context.Background(), 4)
+	require.ErrorIs(err, ErrFutureHeight)
+
+	err = db.SetHeight(2)
+	require.ErrorIs(err, ErrHeightDecreased)
+
+	// Clearing the database doesn't reset the height.
+	require.NoError(db.Clear())
+	root, err = db.RootAtHeight(context.Background(), 3)
+	require.NoError(err)
+	require.Equal(emptyRoot, root)
+
+	_, err = db.RootAtHeight(context.Background(), 2)
+	require.ErrorIs(err, ErrInsufficientHistory)
+}
+
+func TestRootAtHeightInsufficientHistory(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.HistoryLength = 2
+	db, err := newDatabase(
+		context.Background(),
+		memdb.New(),
+		config,
+		&mockMetrics{},
+	)
+	require.NoError(err)
+
+	for height := uint64(1); height <= 3; height++ {
+		require.NoError(db.SetHeight(height))
+		require.NoError(db.Put([]byte{byte(height)}, []byte{byte(height)}))
+	}
+
+	_, err = db.RootAtHeight(context.Background(), 1)
+	require.ErrorIs(err, ErrInsufficientHistory)
+
+	root, err := db.RootAtHeight(context.Background(), 3)
+	require.NoError(err)
+	expectedRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+}
+
+func TestGetChangeProofByHeights(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	for height := uint64(1); height <= 3; height++ {
+		require.NoError(db.SetHeight(height))
+		require.NoError(db.Put([]byte{byte(height)}, []byte{byte(height)}))
+	}
+
+	proof, err := db.GetChangeProofByHeights(context.Background(), 1, 3, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+
+	startRoot, err := db.RootAtHeight(context.Background(), 1)
+	require.NoError(err)
+	endRoot, err := db.RootAtHeight(context.Background(), 3)
+	require.NoError(err)
+	expectedProof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	require.Equal(expectedProof, proof)
+	require.Len(proof.KeyChanges, 2)
+
+	_, err = db.GetChangeProofByHeights(context.Background(), 3, 1, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.ErrorIs(err, errStartHeightAfterEndHeight)
+
+	_, err = db.GetChangeProofByHeights(context.Background(), 1, 4, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.ErrorIs(err, ErrFutureHeight)
+}
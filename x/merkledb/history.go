@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils"
@@ -32,6 +33,10 @@ type trieHistory struct {
 
 	// Each change is tagged with this monotonic increasing number.
 	nextInsertNumber uint64
+
+	// Each change is tagged with this height, which is set by the user and
+	// never decreases.
+	height uint64
 }
 
 // Tracks the beginning and ending state of a value.
@@ -47,6 +52,8 @@ type changeSummaryAndInsertNumber struct {
 	// Another changeSummaryAndInsertNumber with a greater
 	// [insertNumber] means that change was after this one.
 	insertNumber uint64
+	// The height of the database when this change was recorded.
+	height uint64
 }
 
 // Tracks all the node and value changes that resulted in the rootID.
@@ -71,6 +78,30 @@ func newTrieHistory(maxHistoryLookback int) *trieHistory {
 	}
 }
 
+// Returns the root after the last change tagged with a height <= [height].
+// Returns [ErrFutureHeight] if [height] is greater than the current height,
+// since changes with heights <= [height] may still be recorded.
+// Returns [ErrInsufficientHistory] if the history doesn't contain a change
+// tagged with a height <= [height].
+func (th *trieHistory) getRootAtHeight(height uint64) (ids.ID, error) {
+	if height > th.height {
+		return ids.Empty, fmt.Errorf("%w: requested %d but current height is %d", ErrFutureHeight, height, th.height)
+	}
+
+	// Heights are non-decreasing in [th.history], so find the first change
+	// tagged with a height > [height].
+	numChanges := th.history.Len()
+	index := sort.Search(numChanges, func(i int) bool {
+		changes, _ := th.history.Index(i)
+		return changes.height > height
+	})
+	if index == 0 {
+		return ids.Empty, fmt.Errorf("%w: no root found at height %d", ErrInsufficientHistory, height)
+	}
+	changes, _ := th.history.Index(index - 1)
+	return changes.rootID, nil
+}
+
 // Returns up to [maxLength] key-value pair changes with keys in
 // [start, end] that occurred between [startRoot] and [endRoot].
 // If [start] is Nothing, there's no lower bound on the range.
@@ -92,6 +123,81 @@ func (th *trieHistory) getValueChanges(
 		return newChangeSummary(maxLength), nil
 	}
 
+	startRootIndex, endRootIndex, err := th.getRootIndices(startRoot, endRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		// Keep track of changed keys so the largest can be removed
+		// in order to stay within the [maxLength] limit if necessary.
+		changedKeys = set.Set[Key]{}
+
+		startKey = maybe.Bind(start, ToKey)
+		endKey   = maybe.Bind(end, ToKey)
+
+		// For each element in the history in the range between [startRoot]'s
+		// last appearance (exclusive) and [endRoot]'s last appearance (inclusive),
+		// add the changes to keys in [start, end] to [combinedChanges].
+		// Only the key-value pairs with the greatest [maxLength] keys will be kept.
+		combinedChanges = newChangeSummary(maxLength)
+	)
+
+	// For each change after [startRootChanges] up to and including
+	// [endRootChanges], record the change in [combinedChanges].
+	for i := startRootIndex + 1; i <= endRootIndex; i++ {
+		changes, _ := th.history.Index(i)
+
+		// Add the changes from this commit to [combinedChanges].
+		for key, valueChange := range changes.values {
+			// The key is outside the range [start, end].
+			if (startKey.HasValue() && key.Less(startKey.Value())) ||
+				(end.HasValue() && key.Greater(endKey.Value())) {
+				continue
+			}
+
+			// A change to this key already exists in [combinedChanges]
+			// so update its before value with the earlier before value
+			if existing, ok := combinedChanges.values[key]; ok {
+				existing.after = valueChange.after
+				if existing.before.HasValue() == existing.after.HasValue() &&
+					bytes.Equal(existing.before.Value(), existing.after.Value()) {
+					// The change to this key is a no-op, so remove it from [combinedChanges].
+					delete(combinedChanges.values, key)
+					changedKeys.Remove(key)
+				}
+			} else {
+				combinedChanges.values[key] = &change[maybe.Maybe[[]byte]]{
+					before: valueChange.before,
+					after:  valueChange.after,
+				}
+				changedKeys.Add(key)
+			}
+		}
+	}
+
+	// If we have <= [maxLength] elements, we're done.
+	if changedKeys.Len() <= maxLength {
+		return combinedChanges, nil
+	}
+
+	// Keep only the smallest [maxLength] items in [combinedChanges.values].
+	sortedChangedKeys := changedKeys.List()
+	utils.Sort(sortedChangedKeys)
+	for len(sortedChangedKeys) > maxLength {
+		greatestKey := sortedChangedKeys[len(sortedChangedKeys)-1]
+		sortedChangedKeys = sortedChangedKeys[:len(sortedChangedKeys)-1]
+		delete(combinedChanges.values, greatestKey)
+	}
+
+	return combinedChanges, nil
+}
+
+// Returns the indices in [th.history] of the last change resulting in
+// [startRoot] before the last change resulting in [endRoot], and of the last
+// change resulting in [endRoot].
+// Returns [ErrInsufficientHistory] if either change isn't in the history.
+func (th *trieHistory) getRootIndices(startRoot ids.ID, endRoot ids.ID) (int, int, error) {
 	// [endRootChanges] is the last change in the history resulting in [endRoot].
 	// TODO when we update to minimum go version 1.20.X, make this return another
 	// wrapped error ErrNoEndRoot. In NetworkServer.HandleChangeProofRequest, if we return
@@ -99,7 +205,7 @@ func (th *trieHistory) getValueChanges(
 	// lack the necessary history.
 	endRootChanges, ok := th.lastChanges[endRoot]
 	if !ok {
-		return nil, fmt.Errorf("%w: end root %s not found", ErrInsufficientHistory, endRoot)
+		return 0, 0, fmt.Errorf("%w: end root %s not found", ErrInsufficientHistory, endRoot)
 	}
 
 	// Confirm there's a change resulting in [startRoot] before
@@ -107,7 +213,7 @@ func (th *trieHistory) getValueChanges(
 	// [startRootChanges] is the last appearance of [startRoot].
 	startRootChanges, ok := th.lastChanges[startRoot]
 	if !ok {
-		return nil, fmt.Errorf("%w: start root %s not found", ErrInsufficientHistory, startRoot)
+		return 0, 0, fmt.Errorf("%w: start root %s not found", ErrInsufficientHistory, startRoot)
 	}
 
 	var (
@@ -142,7 +248,7 @@ func (th *trieHistory) getValueChanges(
 			}
 
 			if i == 0 {
-				return nil, fmt.Errorf(
+				return 0, 0, fmt.Errorf(
 					"%w: start root %s not found before end root %s",
 					ErrInsufficientHistory, startRoot, endRoot,
 				)
@@ -151,19 +257,6 @@ func (th *trieHistory) getValueChanges(
 	}
 
 	var (
-		// Keep track of changed keys so the largest can be removed
-		// in order to stay within the [maxLength] limit if necessary.
-		changedKeys = set.Set[Key]{}
-
-		startKey = maybe.Bind(start, ToKey)
-		endKey   = maybe.Bind(end, ToKey)
-
-		// For each element in the history in the range between [startRoot]'s
-		// last appearance (exclusive) and [endRoot]'s last appearance (inclusive),
-		// add the changes to keys in [start, end] to [combinedChanges].
-		// Only the key-value pairs with the greatest [maxLength] keys will be kept.
-		combinedChanges = newChangeSummary(maxLength)
-
 		// The difference between the index of [startRootChanges] and [endRootChanges] in [th.history].
 		startToEndOffset = int(endRootChanges.insertNumber - startRootChanges.insertNumber)
 
@@ -171,55 +264,45 @@ func (th *trieHistory) getValueChanges(
 		// which occurs before [endRootChanges].
 		startRootIndex = endRootIndex - startToEndOffset
 	)
+	return startRootIndex, endRootIndex, nil
+}
 
-	// For each change after [startRootChanges] up to and including
-	// [endRootChanges], record the change in [combinedChanges].
+// Returns the subset of [keys] whose values differ between [startRoot] and
+// [endRoot].
+// Returns [ErrInsufficientHistory] if the history is insufficient to
+// determine the changes.
+func (th *trieHistory) getChangedKeys(startRoot ids.ID, endRoot ids.ID, keys set.Set[Key]) (set.Set[Key], error) {
+	changedKeys := set.Set[Key]{}
+	if startRoot == endRoot || keys.Len() == 0 {
+		return changedKeys, nil
+	}
+
+	startRootIndex, endRootIndex, err := th.getRootIndices(startRoot, endRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	// key --> the key's value at [startRoot]
+	initialValues := make(map[Key]maybe.Maybe[[]byte])
 	for i := startRootIndex + 1; i <= endRootIndex; i++ {
 		changes, _ := th.history.Index(i)
-
-		// Add the changes from this commit to [combinedChanges].
 		for key, valueChange := range changes.values {
-			// The key is outside the range [start, end].
-			if (startKey.HasValue() && key.Less(startKey.Value())) ||
-				(end.HasValue() && key.Greater(endKey.Value())) {
+			if !keys.Contains(key) {
 				continue
 			}
-
-			// A change to this key already exists in [combinedChanges]
-			// so update its before value with the earlier before value
-			if existing, ok := combinedChanges.values[key]; ok {
-				existing.after = valueChange.after
-				if existing.before.HasValue() == existing.after.HasValue() &&
-					bytes.Equal(existing.before.Value(), existing.after.Value()) {
-					// The change to this key is a no-op, so remove it from [combinedChanges].
-					delete(combinedChanges.values, key)
-					changedKeys.Remove(key)
-				}
+			initialValue, ok := initialValues[key]
+			if !ok {
+				initialValue = valueChange.before
+				initialValues[key] = initialValue
+			}
+			if maybe.Equal(initialValue, valueChange.after, bytes.Equal) {
+				changedKeys.Remove(key)
 			} else {
-				combinedChanges.values[key] = &change[maybe.Maybe[[]byte]]{
-					before: valueChange.before,
-					after:  valueChange.after,
-				}
 				changedKeys.Add(key)
 			}
 		}
 	}
-
-	// If we have <= [maxLength] elements, we're done.
-	if changedKeys.Len() <= maxLength {
-		return combinedChanges, nil
-	}
-
-	// Keep only the smallest [maxLength] items in [combinedChanges.values].
-	sortedChangedKeys := changedKeys.List()
-	utils.Sort(sortedChangedKeys)
-	for len(sortedChangedKeys) > maxLength {
-		greatestKey := sortedChangedKeys[len(sortedChangedKeys)-1]
-		sortedChangedKeys = sortedChangedKeys[:len(sortedChangedKeys)-1]
-		delete(combinedChanges.values, greatestKey)
-	}
-
-	return combinedChanges, nil
+	return changedKeys, nil
 }
 
 // Returns the changes to go from the current trie state back to the requested [rootID]
@@ -295,6 +378,7 @@ func (th *trieHistory) record(changes *changeSummary) {
 	changesAndIndex := &changeSummaryAndInsertNumber{
 		changeSummary: changes,
 		insertNumber:  th.nextInsertNumber,
+		height:        th.height,
 	}
 	th.nextInsertNumber++
 
@@ -4,8 +4,13 @@
 package merkledb
 
 import (
+	"context"
+	"errors"
 	"sync"
 
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/database"
 )
 
@@ -13,7 +18,7 @@ const defaultBufferLength = 256
 
 // Holds intermediate nodes. That is, those without values.
 // Changes to this database aren't written to [baseDB] until
-// they're evicted from the [nodeCache] or Flush is called.
+// they're evicted from the [writeBuffer] or Flush is called.
 type intermediateNodeDB struct {
 	// Holds unused []byte
 	bufferPool *sync.Pool
@@ -22,35 +27,53 @@ type intermediateNodeDB struct {
 	// Keys written to [baseDB] are prefixed with [intermediateNodePrefix].
 	baseDB database.Database
 
+	// Holds the nodes that were changed but haven't been written to [baseDB].
 	// If a value is nil, the corresponding key isn't in the trie.
 	// Note that a call to Put may cause a node to be evicted
-	// from the cache, which will call [OnEviction].
+	// from the buffer, which will call [OnEviction].
 	// A non-nil error returned from Put is considered fatal.
+	// Keys in [writeBuffer] aren't prefixed with [intermediateNodePrefix].
+	writeBuffer onEvictCache[Key, *node]
+	// Holds nodes read from [baseDB]. It is sized independently of
+	// [writeBuffer] so that a large commit doesn't evict the nodes that are
+	// read the most. A key is never in both [writeBuffer] and [nodeCache].
 	// Keys in [nodeCache] aren't prefixed with [intermediateNodePrefix].
-	nodeCache onEvictCache[Key, *node]
+	nodeCache cache.Cacher[Key, *node]
 	// the number of bytes to evict during an eviction batch
 	evictionBatchSize int
 	metrics           merkleMetrics
 	tokenSize         int
+	// If true, nodes written to [baseDB] are followed by their checksum,
+	// which is verified when they're read.
+	checksums bool
+	// If non-nil, nodes that weren't written recently are migrated to an
+	// object store, and are read from it once they're no longer in [baseDB].
+	coldNodes *coldNodeStore
 }
 
 func newIntermediateNodeDB(
 	db database.Database,
 	bufferPool *sync.Pool,
 	metrics merkleMetrics,
-	size int,
+	cacheSize int,
+	writeBufferSize int,
 	evictionBatchSize int,
 	tokenSize int,
+	checksums bool,
+	coldNodes *coldNodeStore,
 ) *intermediateNodeDB {
 	result := &intermediateNodeDB{
 		metrics:           metrics,
 		baseDB:            db,
 		bufferPool:        bufferPool,
+		nodeCache:         cache.NewSizedLRU(cacheSize, cacheEntrySize),
 		evictionBatchSize: evictionBatchSize,
 		tokenSize:         tokenSize,
+		checksums:         checksums,
+		coldNodes:         coldNodes,
 	}
-	result.nodeCache = newOnEvictCache(
-		size,
+	result.writeBuffer = newOnEvictCache(
+		writeBufferSize,
 		cacheEntrySize,
 		result.onEviction,
 	)
@@ -59,69 +82,176 @@ func newIntermediateNodeDB(
 
 // A non-nil error is considered fatal and closes [db.baseDB].
 func (db *intermediateNodeDB) onEviction(key Key, n *node) error {
+	staleObjectKeys, err := db.writeEvicted(key, n)
+	if err != nil {
+		_ = db.baseDB.Close()
+		return err
+	}
+
+	// The replaced cold nodes are no longer in the index, so their objects
+	// are never read again even if they fail to be deleted.
+	for _, dbKey := range staleObjectKeys {
+		_ = db.coldNodes.deleteObject(dbKey)
+	}
+	return nil
+}
+
+// writeEvicted writes [n] to disk, along with the oldest nodes in the buffer.
+// Returns the keys of the cold nodes that were replaced, whose objects must be
+// deleted.
+func (db *intermediateNodeDB) writeEvicted(key Key, n *node) ([][]byte, error) {
+	if db.coldNodes != nil {
+		// Prevents the nodes from being migrated while they're written.
+		db.coldNodes.lock.Lock()
+		defer db.coldNodes.lock.Unlock()
+	}
+
 	writeBatch := db.baseDB.NewBatch()
 
 	totalSize := cacheEntrySize(key, n)
-	if err := db.addToBatch(writeBatch, key, n); err != nil {
-		_ = db.baseDB.Close()
-		return err
+	staleObjectKey, err := db.addToBatch(writeBatch, key, n)
+	if err != nil {
+		return nil, err
+	}
+	var staleObjectKeys [][]byte
+	if staleObjectKey != nil {
+		staleObjectKeys = append(staleObjectKeys, staleObjectKey)
 	}
 
-	// Evict the oldest [evictionBatchSize] nodes from the cache
+	// Evict the oldest [evictionBatchSize] nodes from the buffer
 	// and write them to disk. We write a batch of them, rather than
 	// just [n], so that we don't immediately evict and write another
 	// node, because each time this method is called we do a disk write.
 	// Evicts a total number of bytes, rather than a number of nodes
 	for totalSize < db.evictionBatchSize {
-		key, n, exists := db.nodeCache.removeOldest()
+		key, n, exists := db.writeBuffer.removeOldest()
 		if !exists {
-			// The cache is empty.
+			// The buffer is empty.
 			break
 		}
 		totalSize += cacheEntrySize(key, n)
-		if err := db.addToBatch(writeBatch, key, n); err != nil {
-			_ = db.baseDB.Close()
-			return err
+		staleObjectKey, err := db.addToBatch(writeBatch, key, n)
+		if err != nil {
+			return nil, err
+		}
+		if staleObjectKey != nil {
+			staleObjectKeys = append(staleObjectKeys, staleObjectKey)
 		}
 	}
-	if err := writeBatch.Write(); err != nil {
-		_ = db.baseDB.Close()
-		return err
-	}
-	return nil
+	return staleObjectKeys, writeBatch.Write()
 }
 
-func (db *intermediateNodeDB) addToBatch(b database.Batch, key Key, n *node) error {
+// addToBatch adds the write of [n] to [b].
+// If [n] replaces a cold node, the key of the cold node in [db.baseDB] is
+// returned so that its object can be deleted once [b] is written.
+func (db *intermediateNodeDB) addToBatch(b database.Batch, key Key, n *node) ([]byte, error) {
 	dbKey := db.constructDBKey(key)
 	defer db.bufferPool.Put(dbKey)
 	db.metrics.DatabaseNodeWrite()
+
+	var staleObjectKey []byte
+	if db.coldNodes != nil {
+		wasCold, err := db.coldNodes.recordWrite(b, key, dbKey, n == nil)
+		if err != nil {
+			return nil, err
+		}
+		if wasCold {
+			staleObjectKey = slices.Clone(dbKey)
+		}
+	}
 	if n == nil {
-		return b.Delete(dbKey)
+		return staleObjectKey, b.Delete(dbKey)
+	}
+	nodeBytes := n.bytes()
+	if db.checksums {
+		nodeBytes = appendChecksum(nodeBytes)
 	}
-	return b.Put(dbKey, n.bytes())
+	return staleObjectKey, b.Put(dbKey, nodeBytes)
 }
 
+// Get returns the node with the given [key].
+// [key] isn't retained, so it may reference a temporary buffer.
 func (db *intermediateNodeDB) Get(key Key) (*node, error) {
-	if cachedValue, isCached := db.nodeCache.Get(key); isCached {
-		db.metrics.IntermediateNodeCacheHit()
-		if cachedValue == nil {
+	if bufferedValue, isBuffered := db.writeBuffer.Get(key); isBuffered {
+		db.metrics.IntermediateNodeWriteBufferHit()
+		if bufferedValue == nil {
 			return nil, database.ErrNotFound
 		}
+		return bufferedValue, nil
+	}
+	if cachedValue, isCached := db.nodeCache.Get(key); isCached {
+		db.metrics.IntermediateNodeCacheHit()
 		return cachedValue, nil
 	}
+	if db.coldNodes != nil {
+		if cachedValue, isCached := db.coldNodes.nodeCache.Get(key); isCached {
+			db.metrics.IntermediateNodeCacheHit()
+			return cachedValue, nil
+		}
+	}
 	db.metrics.IntermediateNodeCacheMiss()
 
+	// [key] may reference a temporary buffer, but it's retained by the parsed
+	// node and the cache.
+	key = key.clone()
 	dbKey := db.constructDBKey(key)
 	db.metrics.DatabaseNodeRead()
 	nodeBytes, err := db.baseDB.Get(dbKey)
+	if errors.Is(err, database.ErrNotFound) && db.coldNodes != nil {
+		n, err := db.getColdNode(key, dbKey)
+		db.bufferPool.Put(dbKey)
+		return n, err
+	}
 	if err != nil {
 		return nil, err
 	}
 	db.bufferPool.Put(dbKey)
 
+	n, err := db.parseNode(key, nodeBytes)
+	if err != nil {
+		return nil, err
+	}
+	db.nodeCache.Put(key, n)
+	return n, nil
+}
+
+// getColdNode reads the node with the given [key] and [dbKey] from the object
+// store and caches it.
+// Returns [database.ErrNotFound] if the node isn't cold.
+func (db *intermediateNodeDB) getColdNode(key Key, dbKey []byte) (*node, error) {
+	// The request is bounded by the store's request timeout.
+	nodeBytes, err := db.coldNodes.Get(context.Background(), dbKey)
+	if err != nil {
+		return nil, err
+	}
+	n, err := db.parseNode(key, nodeBytes)
+	if err != nil {
+		return nil, err
+	}
+	db.coldNodes.nodeCache.Put(key, n)
+	return n, nil
+}
+
+// Parses [nodeBytes], as written by [db.addToBatch], to a node.
+func (db *intermediateNodeDB) parseNode(key Key, nodeBytes []byte) (*node, error) {
+	if db.checksums {
+		var err error
+		nodeBytes, err = verifyChecksum(nodeBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return parseNode(key, nodeBytes)
 }
 
+// cache adds [n], which must be up to date, to [db.nodeCache] unless a change
+// to it is buffered.
+func (db *intermediateNodeDB) cache(n *node) {
+	if _, isBuffered := db.writeBuffer.Get(n.key); !isBuffered {
+		db.nodeCache.Put(n.key, n)
+	}
+}
+
 // constructDBKey returns a key that can be used in [db.baseDB].
 // We need to be able to differentiate between two keys of equal
 // byte length but different bit length, so we add padding to differentiate.
@@ -136,24 +266,32 @@ func (db *intermediateNodeDB) constructDBKey(key Key) []byte {
 }
 
 func (db *intermediateNodeDB) Put(key Key, n *node) error {
-	return db.nodeCache.Put(key, n)
+	db.nodeCache.Evict(key)
+	return db.writeBuffer.Put(key, n)
 }
 
 func (db *intermediateNodeDB) Flush() error {
-	return db.nodeCache.Flush()
+	return db.writeBuffer.Flush()
 }
 
 func (db *intermediateNodeDB) Delete(key Key) error {
-	return db.nodeCache.Put(key, nil)
+	db.nodeCache.Evict(key)
+	return db.writeBuffer.Put(key, nil)
 }
 
 func (db *intermediateNodeDB) Clear() error {
-	// Reset the cache. Note we don't flush because that would cause us to
-	// persist intermediate nodes we're about to delete.
-	db.nodeCache = newOnEvictCache(
-		db.nodeCache.maxSize,
-		db.nodeCache.size,
-		db.nodeCache.onEviction,
+	// Reset the caches. Note we don't flush the write buffer because that
+	// would cause us to persist intermediate nodes we're about to delete.
+	db.nodeCache.Flush()
+	db.writeBuffer = newOnEvictCache(
+		db.writeBuffer.maxSize,
+		db.writeBuffer.size,
+		db.writeBuffer.onEviction,
 	)
+	if db.coldNodes != nil {
+		if err := db.coldNodes.Clear(); err != nil {
+			return err
+		}
+	}
 	return database.AtomicClearPrefix(db.baseDB, db.baseDB, intermediateNodePrefix)
 }
@@ -27,7 +27,7 @@ func Test_IntermediateNodeDB(t *testing.T) {
 	n.setValue(maybe.Some([]byte{byte(0x02)}))
 	nodeSize := cacheEntrySize(n.key, n)
 
-	// use exact multiple of node size so require.Equal(1, db.nodeCache.fifo.Len()) is correct later
+	// use exact multiple of node size so require.Equal(1, db.writeBuffer.fifo.Len()) is correct later
 	cacheSize := nodeSize * 20
 	evictionBatchSize := cacheSize
 	baseDB := memdb.New()
@@ -38,8 +38,11 @@ func Test_IntermediateNodeDB(t *testing.T) {
 		},
 		&mockMetrics{},
 		cacheSize,
+		cacheSize,
 		evictionBatchSize,
 		4,
+		false,
+		nil,
 	)
 
 	// Put a key-node pair
@@ -89,7 +92,7 @@ func Test_IntermediateNodeDB(t *testing.T) {
 	}
 
 	// Assert cache has expected number of elements
-	require.Equal(added, db.nodeCache.fifo.Len())
+	require.Equal(added, db.writeBuffer.fifo.Len())
 
 	// Put one more element in the cache, which should trigger an eviction
 	// of all but 2 elements. 2 elements remain rather than 1 element because of
@@ -100,24 +103,28 @@ func Test_IntermediateNodeDB(t *testing.T) {
 	require.NoError(db.Put(key, node))
 
 	// Assert cache has expected number of elements
-	require.Equal(1, db.nodeCache.fifo.Len())
-	gotKey, _, ok := db.nodeCache.fifo.Oldest()
+	require.Equal(1, db.writeBuffer.fifo.Len())
+	gotKey, _, ok := db.writeBuffer.fifo.Oldest()
 	require.True(ok)
 	require.Equal(ToKey([]byte{byte(added)}), gotKey)
 
 	// Get a node from the base database
 	// Use an early key that has been evicted from the cache
-	_, inCache := db.nodeCache.Get(node1Key)
-	require.False(inCache)
+	_, inBuffer := db.writeBuffer.Get(node1Key)
+	require.False(inBuffer)
 	nodeRead, err := db.Get(node1Key)
 	require.NoError(err)
 	require.Equal(maybe.Some([]byte{0x01}), nodeRead.value)
 
-	// Flush the cache.
+	// Nodes read from the base database are cached
+	_, inCache := db.nodeCache.Get(node1Key)
+	require.True(inCache)
+
+	// Flush the write buffer.
 	require.NoError(db.Flush())
 
-	// Assert the cache is empty
-	require.Zero(db.nodeCache.fifo.Len())
+	// Assert the write buffer is empty
+	require.Zero(db.writeBuffer.fifo.Len())
 
 	// Assert the evicted cache elements were written to disk with prefix.
 	it := baseDB.NewIteratorWithPrefix(intermediateNodePrefix)
@@ -150,8 +157,11 @@ func FuzzIntermediateNodeDBConstructDBKey(f *testing.F) {
 				},
 				&mockMetrics{},
 				cacheSize,
+				cacheSize,
 				evictionBatchSize,
 				tokenSize,
+				false,
+				nil,
 			)
 
 			p := ToKey(key)
@@ -191,8 +201,11 @@ func Test_IntermediateNodeDB_ConstructDBKey_DirtyBuffer(t *testing.T) {
 		},
 		&mockMetrics{},
 		cacheSize,
+		cacheSize,
 		evictionBatchSize,
 		4,
+		false,
+		nil,
 	)
 
 	db.bufferPool.Put([]byte{0xFF, 0xFF, 0xFF})
@@ -226,8 +239,11 @@ func TestIntermediateNodeDBClear(t *testing.T) {
 		},
 		&mockMetrics{},
 		cacheSize,
+		cacheSize,
 		evictionBatchSize,
 		4,
+		false,
+		nil,
 	)
 
 	for _, b := range [][]byte{{1}, {2}, {3}} {
@@ -240,5 +256,52 @@ func TestIntermediateNodeDBClear(t *testing.T) {
 	defer iter.Release()
 	require.False(iter.Next())
 
-	require.Zero(db.nodeCache.currentSize)
+	require.Zero(db.writeBuffer.currentSize)
+	require.Zero(db.nodeCache.Len())
+}
+
+func TestIntermediateNodeDBWritesDontEvictReads(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	bufferPool := &sync.Pool{
+		New: func() interface{} { return make([]byte, 0) },
+	}
+	writeDB := newIntermediateNodeDB(baseDB, bufferPool, &mockMetrics{}, 1000, 1000, 1000, 4, false, nil)
+	readKey := ToKey([]byte{0})
+	require.NoError(writeDB.Put(readKey, newNode(readKey)))
+	require.NoError(writeDB.Flush())
+
+	metrics := &mockMetrics{}
+	db := newIntermediateNodeDB(baseDB, bufferPool, metrics, 1000, 200, 50, 4, false, nil)
+
+	// Read the node to cache it
+	_, err := db.Get(readKey)
+	require.NoError(err)
+	require.Equal(int64(1), metrics.intermediateNodeCacheMiss)
+
+	// Write more than the write buffer can hold
+	for i := 1; i < 100; i++ {
+		key := ToKey([]byte{byte(i)})
+		require.NoError(db.Put(key, newNode(key)))
+	}
+	_, err = db.Get(ToKey([]byte{99}))
+	require.NoError(err)
+	require.Equal(int64(1), metrics.intermediateNodeBufferHit)
+
+	// The node that was read is still cached
+	_, err = db.Get(readKey)
+	require.NoError(err)
+	require.Equal(int64(1), metrics.intermediateNodeCacheHit)
+	require.Equal(int64(1), metrics.intermediateNodeCacheMiss)
+
+	// Writing a cached node replaces it in the cache
+	updated := newNode(readKey)
+	updated.setValue(maybe.Some([]byte{1}))
+	require.NoError(db.Put(readKey, updated))
+	_, inCache := db.nodeCache.Get(readKey)
+	require.False(inCache)
+	n, err := db.Get(readKey)
+	require.NoError(err)
+	require.Equal(updated, n)
 }
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"unsafe"
 
 	"golang.org/x/exp/maps"
@@ -32,6 +33,16 @@ var (
 
 	validTokenSizes = maps.Keys(tokenSizeToBranchFactor)
 
+	// keyBufferPool holds the buffers of keys that are only needed
+	// temporarily, such as the keys nodes are looked up by.
+	// See [Key.takeInto] and [Key.extendInto].
+	keyBufferPool = sync.Pool{
+		New: func() interface{} {
+			buffer := make([]byte, 0, defaultBufferLength)
+			return &buffer
+		},
+	}
+
 	validBranchFactors = []BranchFactor{
 		BranchFactor2,
 		BranchFactor4,
@@ -172,6 +183,13 @@ func (k Key) Less(other Key) bool {
 	return k.value < other.value || (k.value == other.value && k.length < other.length)
 }
 
+// sortedKeys returns the keys of [m] in ascending order.
+func sortedKeys[V any](m map[Key]V) []Key {
+	keys := maps.Keys(m)
+	slices.SortFunc(keys, Key.Less)
+	return keys
+}
+
 // Extend returns a new Key that is the in-order aggregation of Key [k] with [keys]
 func (k Key) Extend(keys ...Key) Key {
 	totalBitLength := k.length
@@ -292,6 +310,62 @@ func (k Key) Take(bitsToTake int) Key {
 	return result
 }
 
+// takeInto is like [Take], but if the result can't reference [k], it's
+// written to [buffer] rather than to a new allocation.
+// The returned Key must not be used once [buffer] is modified or returned to
+// [keyBufferPool], so it must be cloned by anything that retains it.
+func (k Key) takeInto(buffer *[]byte, bitsToTake int) Key {
+	if k.length <= bitsToTake {
+		return k
+	}
+
+	remainderBits := bitsToTake % 8
+	if remainderBits == 0 {
+		return Key{
+			value:  k.value[:bitsToTake/8],
+			length: bitsToTake,
+		}
+	}
+
+	*buffer = append((*buffer)[:0], k.value[:bytesNeeded(bitsToTake)]...)
+	(*buffer)[len(*buffer)-1] &= byte(0xFF << dualBitIndex(remainderBits))
+	return Key{
+		value:  byteSliceToString(*buffer),
+		length: bitsToTake,
+	}
+}
+
+// extendInto is like [Extend], but the result is written to [buffer] rather
+// than to a new allocation.
+// The returned Key must not be used once [buffer] is modified or returned to
+// [keyBufferPool], so it must be cloned by anything that retains it.
+func (k Key) extendInto(buffer *[]byte, keys ...Key) Key {
+	totalBitLength := k.length
+	for _, key := range keys {
+		totalBitLength += key.length
+	}
+	*buffer = append((*buffer)[:0], make([]byte, bytesNeeded(totalBitLength))...)
+	copy(*buffer, k.value)
+	currentTotal := k.length
+	for _, key := range keys {
+		extendIntoBuffer(*buffer, key, currentTotal)
+		currentTotal += key.length
+	}
+
+	return Key{
+		value:  byteSliceToString(*buffer),
+		length: totalBitLength,
+	}
+}
+
+// clone returns a copy of [k] that doesn't reference the memory of [k].
+func (k Key) clone() Key {
+	return Key{
+		value:  strings.Clone(k.value),
+		length: k.length,
+	}
+}
+
 // Bytes returns the raw bytes of the Key
 // Invariant: The returned value must not be modified.
 func (k Key) Bytes() []byte {
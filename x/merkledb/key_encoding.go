@@ -0,0 +1,89 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const (
+	uint64KeyBits      = wrappers.LongLen * 8
+	addressSlotKeyBits = (ids.ShortIDLen + ids.IDLen) * 8
+)
+
+var ErrMissingKeyPrefix = errors.New("key doesn't have the expected prefix")
+
+// The helpers below build keys from structured components. The components
+// are encoded such that the order of the keys, and therefore the order in
+// which they're iterated, matches the order of the components.
+
+// Uint64Key returns the 8 byte, big endian encoding of [v] as a key.
+// Keys built from smaller integers are less than keys built from larger ones.
+func Uint64Key(v uint64) Key {
+	keyBytes := make([]byte, wrappers.LongLen)
+	binary.BigEndian.PutUint64(keyBytes, v)
+	return toKey(keyBytes)
+}
+
+// ParseUint64Key returns the integer [k] was built from by [Uint64Key].
+func ParseUint64Key(k Key) (uint64, error) {
+	if k.length != uint64KeyBits {
+		return 0, fmt.Errorf("%w: expected %d bits but got %d", ErrInvalidKeyLength, uint64KeyBits, k.length)
+	}
+	return binary.BigEndian.Uint64(k.Bytes()), nil
+}
+
+// AddressSlotKey returns the concatenation of [address] and [slot] as a key.
+// Keys are ordered by address, and keys with the same address by slot.
+func AddressSlotKey(address ids.ShortID, slot ids.ID) Key {
+	keyBytes := make([]byte, ids.ShortIDLen+ids.IDLen)
+	copy(keyBytes, address[:])
+	copy(keyBytes[ids.ShortIDLen:], slot[:])
+	return toKey(keyBytes)
+}
+
+// ParseAddressSlotKey returns the address and slot [k] was built from by
+// [AddressSlotKey].
+func ParseAddressSlotKey(k Key) (ids.ShortID, ids.ID, error) {
+	if k.length != addressSlotKeyBits {
+		return ids.ShortEmpty, ids.Empty, fmt.Errorf("%w: expected %d bits but got %d", ErrInvalidKeyLength, addressSlotKeyBits, k.length)
+	}
+	var (
+		keyBytes = k.Bytes()
+		address  ids.ShortID
+		slot     ids.ID
+	)
+	copy(address[:], keyBytes)
+	copy(slot[:], keyBytes[ids.ShortIDLen:])
+	return address, slot, nil
+}
+
+// PrefixedKey returns [suffix] appended to [prefix].
+// [prefix] may have a partial byte (e.g. a single token), in which case the
+// bits of [suffix] are shifted to immediately follow it. Note that only keys
+// with a whole number of bytes can hold values.
+func PrefixedKey(prefix Key, suffix []byte) Key {
+	// [Extend] copies [suffix], so it doesn't need to be cloned.
+	return prefix.Extend(toKey(suffix))
+}
+
+// ParsePrefixedKey returns the suffix [k] was built from by [PrefixedKey]
+// with [prefix].
+func ParsePrefixedKey(k Key, prefix Key) ([]byte, error) {
+	if !k.HasPrefix(prefix) {
+		return nil, ErrMissingKeyPrefix
+	}
+	suffix := k.Skip(prefix.length)
+	if suffix.hasPartialByte() {
+		return nil, fmt.Errorf("%w: suffix has %d bits", ErrInvalidKeyLength, suffix.length)
+	}
+	return slices.Clone(suffix.Bytes()), nil
+}
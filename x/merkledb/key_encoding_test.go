@@ -0,0 +1,88 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestUint64Key(t *testing.T) {
+	require := require.New(t)
+
+	for _, v := range []uint64{0, 1, 255, 256, math.MaxUint64} {
+		key := Uint64Key(v)
+		require.Equal(uint64KeyBits, key.length)
+
+		parsed, err := ParseUint64Key(key)
+		require.NoError(err)
+		require.Equal(v, parsed)
+	}
+
+	require.True(Uint64Key(1).Less(Uint64Key(256)))
+	require.True(Uint64Key(255).Less(Uint64Key(256)))
+
+	_, err := ParseUint64Key(ToKey([]byte{1}))
+	require.ErrorIs(err, ErrInvalidKeyLength)
+}
+
+func TestAddressSlotKey(t *testing.T) {
+	require := require.New(t)
+
+	address := ids.ShortID{1, 2, 3}
+	slot := ids.ID{4, 5, 6}
+	key := AddressSlotKey(address, slot)
+	require.Equal(addressSlotKeyBits, key.length)
+
+	parsedAddress, parsedSlot, err := ParseAddressSlotKey(key)
+	require.NoError(err)
+	require.Equal(address, parsedAddress)
+	require.Equal(slot, parsedSlot)
+
+	require.True(key.Less(AddressSlotKey(address, ids.ID{4, 5, 7})))
+	require.True(AddressSlotKey(address, ids.ID{0xff}).Less(AddressSlotKey(ids.ShortID{1, 2, 4}, ids.Empty)))
+
+	_, _, err = ParseAddressSlotKey(Uint64Key(0))
+	require.ErrorIs(err, ErrInvalidKeyLength)
+}
+
+func TestPrefixedKey(t *testing.T) {
+	require := require.New(t)
+
+	prefix := ToKey([]byte{0xff})
+	suffix := []byte{1, 2, 3}
+	key := PrefixedKey(prefix, suffix)
+	require.Equal(ToKey([]byte{0xff, 1, 2, 3}), key)
+
+	parsed, err := ParsePrefixedKey(key, prefix)
+	require.NoError(err)
+	require.Equal(suffix, parsed)
+
+	_, err = ParsePrefixedKey(key, ToKey([]byte{0xfe}))
+	require.ErrorIs(err, ErrMissingKeyPrefix)
+}
+
+func TestPrefixedKeyPartialByte(t *testing.T) {
+	require := require.New(t)
+
+	// A 4 bit prefix shifts the suffix by half a byte.
+	prefix := ToToken(1, 4)
+	suffix := []byte{0x23}
+	key := PrefixedKey(prefix, suffix)
+	require.Equal(12, key.length)
+	require.True(key.hasPartialByte())
+	require.Equal(ToKey([]byte{0x12, 0x30}).Take(12), key)
+
+	parsed, err := ParsePrefixedKey(key, prefix)
+	require.NoError(err)
+	require.Equal(suffix, parsed)
+
+	// The remaining 8 bits don't form a whole suffix.
+	_, err = ParsePrefixedKey(ToKey([]byte{0x12, 0x30}), prefix)
+	require.ErrorIs(err, ErrInvalidKeyLength)
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	_ database.Iterator = (*keyEpochIterator)(nil)
+
+	keyEpochPrefix = []byte{3}
+
+	ErrKeyEpochsNotTracked = errors.New("key epochs are not tracked")
+	errInvalidKeyEpoch     = errors.New("invalid key epoch")
+)
+
+type KeyEpochTracker interface {
+	// SetEpoch sets the epoch that is recorded as the last-write epoch of the
+	// keys changed by views created after this call returns.
+	// Does nothing if key epochs aren't tracked.
+	SetEpoch(epoch uint64)
+
+	// GetKeyEpoch returns the epoch in which [key] was last written.
+	// Returns [database.ErrNotFound] if no epoch is recorded for [key].
+	// Returns [ErrKeyEpochsNotTracked] if key epochs aren't tracked.
+	GetKeyEpoch(key []byte) (uint64, error)
+
+	// NewKeyEpochIteratorBefore returns an iterator, in order of increasing
+	// key, over the keys that were last written in an epoch < [epoch].
+	// The value of each entry is the epoch of its key, as a big endian
+	// uint64.
+	NewKeyEpochIteratorBefore(epoch uint64) database.Iterator
+}
+
+func (db *merkleDB) SetEpoch(epoch uint64) {
+	db.epoch.Set(epoch)
+}
+
+func (db *merkleDB) GetKeyEpoch(key []byte) (uint64, error) {
+	var (
+		epochBytes []byte
+		err        error
+	)
+	switch {
+	case !db.trackKeyEpochs:
+		return 0, ErrKeyEpochsNotTracked
+	case db.hashKeyEpochs():
+		epochKey, ok := db.hashedKeyEpochKey(key)
+		if !ok {
+			return 0, database.ErrNotFound
+		}
+		epochBytes, err = db.Get(epochKey)
+	default:
+		epochBytes, err = db.baseDB.Get(unhashedKeyEpochKey(key))
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseKeyEpoch(epochBytes)
+}
+
+func (db *merkleDB) NewKeyEpochIteratorBefore(epoch uint64) database.Iterator {
+	switch {
+	case !db.trackKeyEpochs:
+		return &database.IteratorError{
+			Err: ErrKeyEpochsNotTracked,
+		}
+	case db.hashKeyEpochs():
+		return &keyEpochIterator{
+			Iterator:  db.NewIteratorWithPrefix(db.hashedKeyEpochPrefix),
+			prefixLen: len(db.hashedKeyEpochPrefix),
+			before:    epoch,
+		}
+	default:
+		return &keyEpochIterator{
+			Iterator:  db.baseDB.NewIteratorWithPrefix(keyEpochPrefix),
+			prefixLen: len(keyEpochPrefix),
+			before:    epoch,
+		}
+	}
+}
+
+// Returns true iff key epochs are recorded in the trie, and are therefore
+// included in its root.
+func (db *merkleDB) hashKeyEpochs() bool {
+	return db.trackKeyEpochs && len(db.hashedKeyEpochPrefix) > 0
+}
+
+// Returns true iff [key] is an epoch record in the trie.
+func (db *merkleDB) isHashedKeyEpochRecord(key []byte) bool {
+	return db.hashKeyEpochs() && bytes.HasPrefix(key, db.hashedKeyEpochPrefix)
+}
+
+// Returns the key that the epoch of [key] is recorded under in the trie.
+// Returns false if [key] is itself an epoch record, since epochs aren't
+// recorded for epoch records.
+// Assumes [db.hashKeyEpochs] returns true.
+func (db *merkleDB) hashedKeyEpochKey(key []byte) ([]byte, bool) {
+	if db.isHashedKeyEpochRecord(key) {
+		return nil, false
+	}
+	return append(slices.Clone(db.hashedKeyEpochPrefix), key...), true
+}
+
+// Returns the key that the epoch of [key] is recorded under in the base db.
+func unhashedKeyEpochKey(key []byte) []byte {
+	return append(slices.Clone(keyEpochPrefix), key...)
+}
+
+// Records, in [batch], the epochs of the keys changed by [views].
+// Assumes the epochs aren't hashed.
+func writeUnhashedKeyEpochs(batch database.Batch, views []*trieView) error {
+	for _, view := range views {
+		epochBytes := keyEpochBytes(view.epoch)
+		for key, change := range view.changes.values {
+			epochKey := unhashedKeyEpochKey(key.Bytes())
+			if change.after.IsNothing() {
+				if err := batch.Delete(epochKey); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := batch.Put(epochKey, epochBytes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func keyEpochBytes(epoch uint64) []byte {
+	epochBytes := make([]byte, wrappers.LongLen)
+	binary.BigEndian.PutUint64(epochBytes, epoch)
+	return epochBytes
+}
+
+func parseKeyEpoch(epochBytes []byte) (uint64, error) {
+	if len(epochBytes) != wrappers.LongLen {
+		return 0, errInvalidKeyEpoch
+	}
+	return binary.BigEndian.Uint64(epochBytes), nil
+}
+
+// keyEpochIterator iterates over the epoch records of keys last written
+// before [before].
+type keyEpochIterator struct {
+	database.Iterator
+	prefixLen int
+	before    uint64
+	err       error
+}
+
+func (it *keyEpochIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.Iterator.Next() {
+		epoch, err := parseKeyEpoch(it.Iterator.Value())
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if epoch < it.before {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *keyEpochIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Error()
+}
+
+func (it *keyEpochIterator) Key() []byte {
+	return it.Iterator.Key()[it.prefixLen:]
+}
@@ -0,0 +1,211 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+func newKeyEpochTestDB(t *testing.T, hashedPrefix []byte) *merkleDB {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.TrackKeyEpochs = true
+	config.HashedKeyEpochPrefix = hashedPrefix
+	db, err := newDatabase(
+		context.Background(),
+		memdb.New(),
+		config,
+		&mockMetrics{},
+	)
+	require.NoError(err)
+	return db
+}
+
+// Writes []byte{0} and []byte{1} in epoch 1, []byte{2} in epoch 2, then
+// deletes []byte{1} and rewrites []byte{0} in epoch 3.
+func writeKeyEpochs(t *testing.T, db *merkleDB) {
+	require := require.New(t)
+
+	db.SetEpoch(1)
+	require.NoError(db.Put([]byte{0}, []byte{0}))
+	require.NoError(db.Put([]byte{1}, []byte{1}))
+
+	db.SetEpoch(2)
+	require.NoError(db.Put([]byte{2}, []byte{2}))
+
+	db.SetEpoch(3)
+	view, err := db.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{
+			{Key: []byte{0}, Value: []byte{3}},
+			{Key: []byte{1}, Delete: true},
+		},
+	})
+	require.NoError(err)
+	require.NoError(view.CommitToDB(context.Background()))
+}
+
+func TestKeyEpochs(t *testing.T) {
+	tests := []struct {
+		name         string
+		hashedPrefix []byte
+	}{
+		{
+			name: "unhashed",
+		},
+		{
+			name:         "hashed",
+			hashedPrefix: []byte{0xff},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db := newKeyEpochTestDB(t, tt.hashedPrefix)
+			writeKeyEpochs(t, db)
+
+			epoch, err := db.GetKeyEpoch([]byte{0})
+			require.NoError(err)
+			require.Equal(uint64(3), epoch)
+
+			epoch, err = db.GetKeyEpoch([]byte{2})
+			require.NoError(err)
+			require.Equal(uint64(2), epoch)
+
+			// Deleting a key removes its epoch.
+			_, err = db.GetKeyEpoch([]byte{1})
+			require.ErrorIs(err, database.ErrNotFound)
+
+			it := db.NewKeyEpochIteratorBefore(3)
+			require.True(it.Next())
+			require.Equal([]byte{2}, it.Key())
+			require.Equal(keyEpochBytes(2), it.Value())
+			require.False(it.Next())
+			require.NoError(it.Error())
+			it.Release()
+
+			it = db.NewKeyEpochIteratorBefore(2)
+			require.False(it.Next())
+			require.NoError(it.Error())
+			it.Release()
+		})
+	}
+}
+
+// batchRecordingDB records the keys written by each batch written to it.
+type batchRecordingDB struct {
+	database.Database
+	batches [][][]byte
+}
+
+func (db *batchRecordingDB) NewBatch() database.Batch {
+	return &recordingBatch{
+		Batch: db.Database.NewBatch(),
+		db:    db,
+	}
+}
+
+type recordingBatch struct {
+	database.Batch
+	db   *batchRecordingDB
+	keys [][]byte
+}
+
+func (b *recordingBatch) Put(key, value []byte) error {
+	b.keys = append(b.keys, slices.Clone(key))
+	return b.Batch.Put(key, value)
+}
+
+func (b *recordingBatch) Write() error {
+	b.db.batches = append(b.db.batches, b.keys)
+	return b.Batch.Write()
+}
+
+func TestKeyEpochsWrittenWithValues(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := &batchRecordingDB{Database: memdb.New()}
+	config := newDefaultConfig()
+	config.TrackKeyEpochs = true
+	db, err := newDatabase(
+		context.Background(),
+		baseDB,
+		config,
+		&mockMetrics{},
+	)
+	require.NoError(err)
+
+	db.SetEpoch(1)
+	key := []byte{1}
+	require.NoError(db.Put(key, []byte{1}))
+
+	valueKey := append(slices.Clone(valueNodePrefix), key...)
+	epochKey := unhashedKeyEpochKey(key)
+	containsKey := func(keys [][]byte, key []byte) bool {
+		return slices.ContainsFunc(keys, func(k []byte) bool {
+			return bytes.Equal(k, key)
+		})
+	}
+	for _, keys := range baseDB.batches {
+		if containsKey(keys, valueKey) {
+			// The epoch must be written in the same batch as the value.
+			require.True(containsKey(keys, epochKey))
+			return
+		}
+	}
+	require.FailNow("value wasn't written")
+}
+
+func TestKeyEpochsRoot(t *testing.T) {
+	require := require.New(t)
+
+	untrackedDB, err := getBasicDB()
+	require.NoError(err)
+	writeKeyEpochs(t, untrackedDB)
+	untrackedRoot, err := untrackedDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Unhashed epochs don't affect the root.
+	unhashedDB := newKeyEpochTestDB(t, nil)
+	writeKeyEpochs(t, unhashedDB)
+	unhashedRoot, err := unhashedDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(untrackedRoot, unhashedRoot)
+
+	// Hashed epochs are included in the root.
+	hashedDB := newKeyEpochTestDB(t, []byte{0xff})
+	writeKeyEpochs(t, hashedDB)
+	hashedRoot, err := hashedDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.NotEqual(untrackedRoot, hashedRoot)
+
+	value, err := hashedDB.Get([]byte{0xff, 0})
+	require.NoError(err)
+	require.Equal(keyEpochBytes(3), value)
+}
+
+func TestKeyEpochsNotTracked(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	_, err = db.GetKeyEpoch([]byte{0})
+	require.ErrorIs(err, ErrKeyEpochsNotTracked)
+
+	it := db.NewKeyEpochIteratorBefore(1)
+	require.False(it.Next())
+	require.ErrorIs(it.Error(), ErrKeyEpochsNotTracked)
+	it.Release()
+}
@@ -522,6 +522,63 @@ func FuzzKeyTake(f *testing.F) {
 	})
 }
 
+func FuzzKeyTakeInto(f *testing.F) {
+	f.Fuzz(func(
+		t *testing.T,
+		first []byte,
+		uTokensToTake uint,
+	) {
+		require := require.New(t)
+		// The buffer is reused, so leftover bytes must not leak into the key.
+		buffer := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+		for _, ts := range validTokenSizes {
+			key1 := ToKey(first)
+			uBitsToTake := uTokensToTake * uint(ts)
+			if uBitsToTake >= uint(key1.length) {
+				t.SkipNow()
+			}
+			bitsToTake := int(uBitsToTake)
+			require.Equal(key1.Take(bitsToTake), key1.takeInto(&buffer, bitsToTake))
+		}
+	})
+}
+
+func FuzzKeyExtendInto(f *testing.F) {
+	f.Fuzz(func(
+		t *testing.T,
+		first []byte,
+		second []byte,
+		tokenByte byte,
+		forceFirstOdd bool,
+	) {
+		require := require.New(t)
+		// The buffer is reused, so leftover bytes must not leak into the key.
+		buffer := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+		for _, ts := range validTokenSizes {
+			key1 := ToKey(first)
+			if forceFirstOdd && key1.length > ts {
+				key1 = key1.Take(key1.length - ts)
+			}
+			key2 := ToKey(second)
+			token := ToToken(byte(int(tokenByte)%int(tokenSizeToBranchFactor[ts])), ts)
+			require.Equal(key1.Extend(token, key2), key1.extendInto(&buffer, token, key2))
+		}
+	})
+}
+
+func Test_Key_Clone(t *testing.T) {
+	require := require.New(t)
+
+	buffer := []byte{0b0101_0101, 0b1010_1010}
+	key := ToKey([]byte{0b0101_0101, 0b1010_1010}).takeInto(&buffer, 12)
+	clone := key.clone()
+	require.Equal(key, clone)
+
+	buffer[0] = 0
+	require.NotEqual(key, clone)
+	require.Equal(ToKey([]byte{0b0101_0101, 0b1010_0000}).Take(12), clone)
+}
+
 func TestShiftCopy(t *testing.T) {
 	type test struct {
 		dst      []byte
@@ -24,6 +24,7 @@ type merkleMetrics interface {
 	ValueNodeCacheMiss()
 	IntermediateNodeCacheHit()
 	IntermediateNodeCacheMiss()
+	IntermediateNodeWriteBufferHit()
 	ViewNodeCacheHit()
 	ViewNodeCacheMiss()
 	ViewValueCacheHit()
@@ -39,6 +40,7 @@ type mockMetrics struct {
 	valueNodeCacheMiss        int64
 	intermediateNodeCacheHit  int64
 	intermediateNodeCacheMiss int64
+	intermediateNodeBufferHit int64
 	viewNodeCacheHit          int64
 	viewNodeCacheMiss         int64
 	viewValueCacheHit         int64
@@ -122,12 +124,20 @@ func (m *mockMetrics) IntermediateNodeCacheMiss() {
 	m.intermediateNodeCacheMiss++
 }
 
+func (m *mockMetrics) IntermediateNodeWriteBufferHit() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.intermediateNodeBufferHit++
+}
+
 type metrics struct {
 	ioKeyWrite                prometheus.Counter
 	ioKeyRead                 prometheus.Counter
 	hashCount                 prometheus.Counter
 	intermediateNodeCacheHit  prometheus.Counter
 	intermediateNodeCacheMiss prometheus.Counter
+	intermediateNodeBufferHit prometheus.Counter
 	valueNodeCacheHit         prometheus.Counter
 	valueNodeCacheMiss        prometheus.Counter
 	viewNodeCacheHit          prometheus.Counter
@@ -175,7 +185,12 @@ func newMetrics(namespace string, reg prometheus.Registerer) (merkleMetrics, err
 		intermediateNodeCacheMiss: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "intermediate_node_cache_miss",
-			Help:      "cumulative amount of misses on the intermediate node db cache",
+			Help:      "cumulative amount of misses on the intermediate node db cache and write buffer",
+		}),
+		intermediateNodeBufferHit: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "intermediate_node_write_buffer_hit",
+			Help:      "cumulative amount of hits on the intermediate node db write buffer",
 		}),
 		viewNodeCacheHit: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
@@ -206,6 +221,7 @@ func newMetrics(namespace string, reg prometheus.Registerer) (merkleMetrics, err
 		reg.Register(m.valueNodeCacheMiss),
 		reg.Register(m.intermediateNodeCacheHit),
 		reg.Register(m.intermediateNodeCacheMiss),
+		reg.Register(m.intermediateNodeBufferHit),
 		reg.Register(m.viewNodeCacheHit),
 		reg.Register(m.viewNodeCacheMiss),
 		reg.Register(m.viewValueCacheHit),
@@ -250,6 +266,10 @@ func (m *metrics) IntermediateNodeCacheMiss() {
 	m.intermediateNodeCacheMiss.Inc()
 }
 
+func (m *metrics) IntermediateNodeWriteBufferHit() {
+	m.intermediateNodeBufferHit.Inc()
+}
+
 func (m *metrics) ValueNodeCacheHit() {
 	m.valueNodeCacheHit.Inc()
 }
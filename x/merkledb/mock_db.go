@@ -82,6 +82,34 @@ func (mr *MockMerkleDBMockRecorder) CommitChangeProof(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitChangeProof", reflect.TypeOf((*MockMerkleDB)(nil).CommitChangeProof), arg0, arg1)
 }
 
+// CommitChangeProofWithPolicy mocks base method.
+func (m *MockMerkleDB) CommitChangeProofWithPolicy(arg0 context.Context, arg1 ids.ID, arg2 *ChangeProof, arg3 ConflictPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitChangeProofWithPolicy", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitChangeProofWithPolicy indicates an expected call of CommitChangeProofWithPolicy.
+func (mr *MockMerkleDBMockRecorder) CommitChangeProofWithPolicy(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitChangeProofWithPolicy", reflect.TypeOf((*MockMerkleDB)(nil).CommitChangeProofWithPolicy), arg0, arg1, arg2, arg3)
+}
+
+// CommitEpoch mocks base method.
+func (m *MockMerkleDB) CommitEpoch() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitEpoch")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// CommitEpoch indicates an expected call of CommitEpoch.
+func (mr *MockMerkleDBMockRecorder) CommitEpoch() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitEpoch", reflect.TypeOf((*MockMerkleDB)(nil).CommitEpoch))
+}
+
 // CommitRangeProof mocks base method.
 func (m *MockMerkleDB) CommitRangeProof(arg0 context.Context, arg1, arg2 maybe.Maybe[[]uint8], arg3 *RangeProof) error {
 	m.ctrl.T.Helper()
@@ -96,6 +124,20 @@ func (mr *MockMerkleDBMockRecorder) CommitRangeProof(arg0, arg1, arg2, arg3 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitRangeProof", reflect.TypeOf((*MockMerkleDB)(nil).CommitRangeProof), arg0, arg1, arg2, arg3)
 }
 
+// CommitViewStack mocks base method.
+func (m *MockMerkleDB) CommitViewStack(arg0 context.Context, arg1 []TrieView) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitViewStack", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitViewStack indicates an expected call of CommitViewStack.
+func (mr *MockMerkleDBMockRecorder) CommitViewStack(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitViewStack", reflect.TypeOf((*MockMerkleDB)(nil).CommitViewStack), arg0, arg1)
+}
+
 // Compact mocks base method.
 func (m *MockMerkleDB) Compact(arg0, arg1 []byte) error {
 	m.ctrl.T.Helper()
@@ -154,6 +196,36 @@ func (mr *MockMerkleDBMockRecorder) GetChangeProof(arg0, arg1, arg2, arg3, arg4,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangeProof", reflect.TypeOf((*MockMerkleDB)(nil).GetChangeProof), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// GetChangeProofByHeights mocks base method.
+func (m *MockMerkleDB) GetChangeProofByHeights(arg0 context.Context, arg1, arg2 uint64, arg3, arg4 maybe.Maybe[[]uint8], arg5 int) (*ChangeProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChangeProofByHeights", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*ChangeProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChangeProofByHeights indicates an expected call of GetChangeProofByHeights.
+func (mr *MockMerkleDBMockRecorder) GetChangeProofByHeights(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangeProofByHeights", reflect.TypeOf((*MockMerkleDB)(nil).GetChangeProofByHeights), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// GetKeyEpoch mocks base method.
+func (m *MockMerkleDB) GetKeyEpoch(arg0 []byte) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyEpoch", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyEpoch indicates an expected call of GetKeyEpoch.
+func (mr *MockMerkleDBMockRecorder) GetKeyEpoch(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyEpoch", reflect.TypeOf((*MockMerkleDB)(nil).GetKeyEpoch), arg0)
+}
+
 // GetMerkleRoot mocks base method.
 func (m *MockMerkleDB) GetMerkleRoot(arg0 context.Context) (ids.ID, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +241,21 @@ func (mr *MockMerkleDBMockRecorder) GetMerkleRoot(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerkleRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetMerkleRoot), arg0)
 }
 
+// GetPrefixUsage mocks base method.
+func (m *MockMerkleDB) GetPrefixUsage(arg0 []byte) (PrefixUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrefixUsage", arg0)
+	ret0, _ := ret[0].(PrefixUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrefixUsage indicates an expected call of GetPrefixUsage.
+func (mr *MockMerkleDBMockRecorder) GetPrefixUsage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrefixUsage", reflect.TypeOf((*MockMerkleDB)(nil).GetPrefixUsage), arg0)
+}
+
 // GetProof mocks base method.
 func (m *MockMerkleDB) GetProof(arg0 context.Context, arg1 []byte) (*Proof, error) {
 	m.ctrl.T.Helper()
@@ -185,33 +272,33 @@ func (mr *MockMerkleDBMockRecorder) GetProof(arg0, arg1 interface{}) *gomock.Cal
 }
 
 // GetRangeProof mocks base method.
-func (m *MockMerkleDB) GetRangeProof(arg0 context.Context, arg1, arg2 maybe.Maybe[[]uint8], arg3 int) (*RangeProof, error) {
+func (m *MockMerkleDB) GetRangeProof(arg0 context.Context, arg1, arg2 maybe.Maybe[[]uint8], arg3, arg4 int) (*RangeProof, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRangeProof", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "GetRangeProof", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(*RangeProof)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRangeProof indicates an expected call of GetRangeProof.
-func (mr *MockMerkleDBMockRecorder) GetRangeProof(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockMerkleDBMockRecorder) GetRangeProof(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRangeProof", reflect.TypeOf((*MockMerkleDB)(nil).GetRangeProof), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRangeProof", reflect.TypeOf((*MockMerkleDB)(nil).GetRangeProof), arg0, arg1, arg2, arg3, arg4)
 }
 
 // GetRangeProofAtRoot mocks base method.
-func (m *MockMerkleDB) GetRangeProofAtRoot(arg0 context.Context, arg1 ids.ID, arg2, arg3 maybe.Maybe[[]uint8], arg4 int) (*RangeProof, error) {
+func (m *MockMerkleDB) GetRangeProofAtRoot(arg0 context.Context, arg1 ids.ID, arg2, arg3 maybe.Maybe[[]uint8], arg4, arg5 int) (*RangeProof, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRangeProofAtRoot", arg0, arg1, arg2, arg3, arg4)
+	ret := m.ctrl.Call(m, "GetRangeProofAtRoot", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(*RangeProof)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRangeProofAtRoot indicates an expected call of GetRangeProofAtRoot.
-func (mr *MockMerkleDBMockRecorder) GetRangeProofAtRoot(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+func (mr *MockMerkleDBMockRecorder) GetRangeProofAtRoot(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRangeProofAtRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetRangeProofAtRoot), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRangeProofAtRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetRangeProofAtRoot), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
 // GetValue mocks base method.
@@ -274,6 +361,21 @@ func (mr *MockMerkleDBMockRecorder) HealthCheck(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthCheck", reflect.TypeOf((*MockMerkleDB)(nil).HealthCheck), arg0)
 }
 
+// LockRange mocks base method.
+func (m *MockMerkleDB) LockRange(arg0 context.Context, arg1, arg2 maybe.Maybe[[]uint8]) (*RangeLock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockRange", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*RangeLock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockRange indicates an expected call of LockRange.
+func (mr *MockMerkleDBMockRecorder) LockRange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockRange", reflect.TypeOf((*MockMerkleDB)(nil).LockRange), arg0, arg1, arg2)
+}
+
 // NewBatch mocks base method.
 func (m *MockMerkleDB) NewBatch() database.Batch {
 	m.ctrl.T.Helper()
@@ -344,6 +446,34 @@ func (mr *MockMerkleDBMockRecorder) NewIteratorWithStartAndPrefix(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewIteratorWithStartAndPrefix", reflect.TypeOf((*MockMerkleDB)(nil).NewIteratorWithStartAndPrefix), arg0, arg1)
 }
 
+// NewKeyEpochIteratorBefore mocks base method.
+func (m *MockMerkleDB) NewKeyEpochIteratorBefore(arg0 uint64) database.Iterator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewKeyEpochIteratorBefore", arg0)
+	ret0, _ := ret[0].(database.Iterator)
+	return ret0
+}
+
+// NewKeyEpochIteratorBefore indicates an expected call of NewKeyEpochIteratorBefore.
+func (mr *MockMerkleDBMockRecorder) NewKeyEpochIteratorBefore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewKeyEpochIteratorBefore", reflect.TypeOf((*MockMerkleDB)(nil).NewKeyEpochIteratorBefore), arg0)
+}
+
+// NewProofIteratorWithStartAndPrefix mocks base method.
+func (m *MockMerkleDB) NewProofIteratorWithStartAndPrefix(arg0, arg1 []byte) ProofIterator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewProofIteratorWithStartAndPrefix", arg0, arg1)
+	ret0, _ := ret[0].(ProofIterator)
+	return ret0
+}
+
+// NewProofIteratorWithStartAndPrefix indicates an expected call of NewProofIteratorWithStartAndPrefix.
+func (mr *MockMerkleDBMockRecorder) NewProofIteratorWithStartAndPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewProofIteratorWithStartAndPrefix", reflect.TypeOf((*MockMerkleDB)(nil).NewProofIteratorWithStartAndPrefix), arg0, arg1)
+}
+
 // NewView mocks base method.
 func (m *MockMerkleDB) NewView(arg0 context.Context, arg1 ViewChanges) (TrieView, error) {
 	m.ctrl.T.Helper()
@@ -401,6 +531,73 @@ func (mr *MockMerkleDBMockRecorder) Put(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockMerkleDB)(nil).Put), arg0, arg1)
 }
 
+// RegisterOnCommit mocks base method.
+func (m *MockMerkleDB) RegisterOnCommit(arg0 func(ids.ID, ChangeSummary)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegisterOnCommit", arg0)
+}
+
+// RegisterOnCommit indicates an expected call of RegisterOnCommit.
+func (mr *MockMerkleDBMockRecorder) RegisterOnCommit(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterOnCommit", reflect.TypeOf((*MockMerkleDB)(nil).RegisterOnCommit), arg0)
+}
+
+// RootAtHeight mocks base method.
+func (m *MockMerkleDB) RootAtHeight(arg0 context.Context, arg1 uint64) (ids.ID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RootAtHeight", arg0, arg1)
+	ret0, _ := ret[0].(ids.ID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RootAtHeight indicates an expected call of RootAtHeight.
+func (mr *MockMerkleDBMockRecorder) RootAtHeight(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RootAtHeight", reflect.TypeOf((*MockMerkleDB)(nil).RootAtHeight), arg0, arg1)
+}
+
+// SetEpoch mocks base method.
+func (m *MockMerkleDB) SetEpoch(arg0 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetEpoch", arg0)
+}
+
+// SetEpoch indicates an expected call of SetEpoch.
+func (mr *MockMerkleDBMockRecorder) SetEpoch(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEpoch", reflect.TypeOf((*MockMerkleDB)(nil).SetEpoch), arg0)
+}
+
+// SetHeight mocks base method.
+func (m *MockMerkleDB) SetHeight(arg0 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeight", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeight indicates an expected call of SetHeight.
+func (mr *MockMerkleDBMockRecorder) SetHeight(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeight", reflect.TypeOf((*MockMerkleDB)(nil).SetHeight), arg0)
+}
+
+// Subscribe mocks base method.
+func (m *MockMerkleDB) Subscribe() <-chan CommitEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe")
+	ret0, _ := ret[0].(<-chan CommitEvent)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockMerkleDBMockRecorder) Subscribe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockMerkleDB)(nil).Subscribe))
+}
+
 // VerifyChangeProof mocks base method.
 func (m *MockMerkleDB) VerifyChangeProof(arg0 context.Context, arg1 *ChangeProof, arg2, arg3 maybe.Maybe[[]uint8], arg4 ids.ID) error {
 	m.ctrl.T.Helper()
@@ -415,6 +612,34 @@ func (mr *MockMerkleDBMockRecorder) VerifyChangeProof(arg0, arg1, arg2, arg3, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChangeProof", reflect.TypeOf((*MockMerkleDB)(nil).VerifyChangeProof), arg0, arg1, arg2, arg3, arg4)
 }
 
+// WarmKeys mocks base method.
+func (m *MockMerkleDB) WarmKeys(arg0 context.Context, arg1 [][]byte) <-chan error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WarmKeys", arg0, arg1)
+	ret0, _ := ret[0].(<-chan error)
+	return ret0
+}
+
+// WarmKeys indicates an expected call of WarmKeys.
+func (mr *MockMerkleDBMockRecorder) WarmKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarmKeys", reflect.TypeOf((*MockMerkleDB)(nil).WarmKeys), arg0, arg1)
+}
+
+// WarmPrefix mocks base method.
+func (m *MockMerkleDB) WarmPrefix(arg0 context.Context, arg1 []byte) <-chan error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WarmPrefix", arg0, arg1)
+	ret0, _ := ret[0].(<-chan error)
+	return ret0
+}
+
+// WarmPrefix indicates an expected call of WarmPrefix.
+func (mr *MockMerkleDBMockRecorder) WarmPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarmPrefix", reflect.TypeOf((*MockMerkleDB)(nil).WarmPrefix), arg0, arg1)
+}
+
 // getEditableNode mocks base method.
 func (m *MockMerkleDB) getEditableNode(arg0 Key, arg1 bool) (*node, error) {
 	m.ctrl.T.Helper()
@@ -4,6 +4,8 @@
 package merkledb
 
 import (
+	"sync"
+
 	"golang.org/x/exp/slices"
 
 	"github.com/ava-labs/avalanchego/ids"
@@ -13,6 +15,37 @@ import (
 
 const HashLength = 32
 
+var (
+	// nodePool and childPool hold nodes and child entries that are no longer
+	// referenced so that their memory can be reused rather than garbage
+	// collected. A pooled node keeps its (empty) children map.
+	//
+	// Ownership rules:
+	//   - A node returned by [newNode], [node.clone] or getEditableNode is
+	//     owned by the caller, as are its children map and child entries.
+	//     So is a child entry returned by [newChild].
+	//   - Ownership is given up once the node is recorded in a view's changes,
+	//     written to the database or cached. Views, the trie history and the
+	//     caches may reference such a node at any time, so it must never be
+	//     released.
+	//   - A node that is still owned may be released with [releaseNode] once
+	//     nothing references it, its children map or its child entries.
+	//     The nodes a view deletes while it's built are still owned by the
+	//     view, as only their previous version is recorded.
+	//   - A child entry may be released with [releaseChild] once it's been
+	//     removed from an owned node and nothing else references it.
+	nodePool = sync.Pool{
+		New: func() interface{} {
+			return &node{}
+		},
+	}
+	childPool = sync.Pool{
+		New: func() interface{} {
+			return &child{}
+		},
+	}
+)
+
 // Representation of a node stored in the database.
 type dbNode struct {
 	value    maybe.Maybe[[]byte]
@@ -35,12 +68,51 @@ type node struct {
 
 // Returns a new node with the given [key] and no value.
 func newNode(key Key) *node {
-	return &node{
+	return newNodeWithChildren(key, 2)
+}
+
+// Returns a new node with the given [key] and no value whose children map
+// has room for at least [numChildren] children if it had to be allocated.
+func newNodeWithChildren(key Key, numChildren int) *node {
+	n := nodePool.Get().(*node)
+	if n.children == nil {
+		n.children = make(map[byte]*child, numChildren)
+	}
+	n.key = key
+	return n
+}
+
+// Returns a new child entry from [childPool].
+func newChild(compressedKey Key, id ids.ID, hasValue bool) *child {
+	entry := childPool.Get().(*child)
+	entry.compressedKey = compressedKey
+	entry.id = id
+	entry.hasValue = hasValue
+	return entry
+}
+
+// releaseChild returns [entry] to the pool.
+// [entry] must not be referenced by any node and must not be used after this
+// call.
+func releaseChild(entry *child) {
+	*entry = child{}
+	childPool.Put(entry)
+}
+
+// releaseNode returns [n], and its child entries, to the pool.
+// [n] must be owned by the caller and must not be used after this call.
+func releaseNode(n *node) {
+	children := n.children
+	for index, entry := range children {
+		delete(children, index)
+		releaseChild(entry)
+	}
+	*n = node{
 		dbNode: dbNode{
-			children: make(map[byte]*child, 2),
+			children: children,
 		},
-		key: key,
 	}
+	nodePool.Put(n)
 }
 
 // Parse [nodeBytes] to a node and set its key to [key].
@@ -50,9 +122,13 @@ func parseNode(key Key, nodeBytes []byte) (*node, error) {
 		return nil, err
 	}
 	result := &node{
-		dbNode:    n,
-		key:       key,
-		nodeBytes: nodeBytes,
+		dbNode: n,
+		key:    key,
+	}
+	// Nodes serialized with an outdated format are migrated to the current
+	// format the next time they are written.
+	if dbNodeVersion(nodeBytes) == currentDBNodeVersion {
+		result.nodeBytes = nodeBytes
 	}
 
 	result.setValueDigest()
@@ -82,8 +158,7 @@ func (n *node) onNodeChanged() {
 // Returns and caches the ID of this node.
 func (n *node) calculateID(metrics merkleMetrics) ids.ID {
 	metrics.HashCalculated()
-	bytes := codec.encodeHashValues(n)
-	return hashing.ComputeHash256Array(bytes)
+	return codec.hashValues(n)
 }
 
 // Set [n]'s value to [val].
@@ -107,10 +182,11 @@ func (n *node) setValueDigest() {
 func (n *node) addChild(childNode *node, tokenSize int) {
 	n.setChildEntry(
 		childNode.key.Token(n.key.length, tokenSize),
-		&child{
-			compressedKey: childNode.key.Skip(n.key.length + tokenSize),
-			hasValue:      childNode.hasValue(),
-		},
+		newChild(
+			childNode.key.Skip(n.key.length+tokenSize),
+			ids.Empty,
+			childNode.hasValue(),
+		),
 	)
 }
 
@@ -120,10 +196,15 @@ func (n *node) setChildEntry(index byte, childEntry *child) {
 	n.children[index] = childEntry
 }
 
-// Removes [child] from [n]'s children.
+// Removes [child] from [n]'s children and releases its child entry.
+// [n] must be owned by the caller.
 func (n *node) removeChild(child *node, tokenSize int) {
 	n.onNodeChanged()
-	delete(n.children, child.key.Token(n.key.length, tokenSize))
+	index := child.key.Token(n.key.length, tokenSize)
+	if entry, ok := n.children[index]; ok {
+		delete(n.children, index)
+		releaseChild(entry)
+	}
 }
 
 // clone Returns a copy of [n].
@@ -131,21 +212,19 @@ func (n *node) removeChild(child *node, tokenSize int) {
 // if this ever changes, value will need to be copied as well
 // it is safe to clone all fields because they are only written/read while one or both of the db locks are held
 func (n *node) clone() *node {
-	result := &node{
-		key: n.key,
-		dbNode: dbNode{
-			value:    n.value,
-			children: make(map[byte]*child, len(n.children)),
-		},
-		valueDigest: n.valueDigest,
-		nodeBytes:   n.nodeBytes,
-	}
+	result := newNodeWithChildren(n.key, len(n.children))
+	result.value = n.value
+	result.valueDigest = n.valueDigest
+	result.nodeBytes = n.nodeBytes
+	// Most cloned nodes are recorded in a view's changes and never released,
+	// so their child entries are allocated together rather than taken from
+	// [childPool] one at a time. Each entry may still be released on its own.
+	entries := make([]child, len(n.children))
+	i := 0
 	for key, existing := range n.children {
-		result.children[key] = &child{
-			compressedKey: existing.compressedKey,
-			id:            existing.id,
-			hasValue:      existing.hasValue,
-		}
+		entries[i] = *existing
+		result.children[key] = &entries[i]
+		i++
 	}
 	return result
 }
@@ -67,3 +67,54 @@ func Test_Node_Marshal_Errors(t *testing.T) {
 		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
 	}
 }
+
+func Test_Node_Parse_Unversioned(t *testing.T) {
+	require := require.New(t)
+
+	n := newNode(ToKey([]byte{1}))
+	n.setValue(maybe.Some([]byte("value")))
+	n.addChild(newNode(ToKey([]byte{1, 2})), 4)
+	nodeBytes := n.bytes()
+	require.Equal(currentDBNodeVersion, nodeBytes[0])
+
+	// Strip the version to get the serialization used before versioning.
+	unversionedBytes := nodeBytes[1:]
+	require.Equal(unversionedDBNode, dbNodeVersion(unversionedBytes))
+
+	parsed, err := parseNode(n.key, unversionedBytes)
+	require.NoError(err)
+	require.Equal(n.dbNode, parsed.dbNode)
+
+	// The node is migrated to the current version when it's serialized.
+	require.Equal(nodeBytes, parsed.bytes())
+}
+
+func Test_Node_Release(t *testing.T) {
+	require := require.New(t)
+
+	parent := newNode(ToKey([]byte{0}))
+	parent.setValue(maybe.Some([]byte("value")))
+	parent.addChild(newNode(ToKey([]byte{0, 1})), 4)
+	_ = parent.bytes()
+
+	clone := parent.clone()
+	require.Equal(parent, clone)
+
+	releaseNode(clone)
+
+	// Releasing the clone must not modify the original.
+	require.Len(parent.children, 1)
+	require.Equal(maybe.Some([]byte("value")), parent.value)
+
+	// A released node is reset before it is reused.
+	for i := 0; i < 10; i++ {
+		n := newNode(ToKey([]byte{1}))
+		require.Equal(ToKey([]byte{1}), n.key)
+		require.Empty(n.children)
+		require.NotNil(n.children)
+		require.True(n.value.IsNothing())
+		require.True(n.valueDigest.IsNothing())
+		require.Nil(n.nodeBytes)
+		releaseNode(n)
+	}
+}
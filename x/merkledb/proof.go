@@ -28,6 +28,8 @@ const (
 var (
 	ErrInvalidProof                = errors.New("proof obtained an invalid root ID")
 	ErrInvalidMaxLength            = errors.New("expected max length to be > 0")
+	ErrInvalidMaxBytes             = errors.New("expected max bytes to be >= 0")
+	ErrMinProofTooLarge            = errors.New("smallest possible proof exceeds max bytes")
 	ErrNonIncreasingValues         = errors.New("keys sent are not in increasing order")
 	ErrStateFromOutsideOfRange     = errors.New("state key falls outside of the start->end range")
 	ErrNonIncreasingProofNodes     = errors.New("each proof node key must be a strict prefix of the next")
@@ -0,0 +1,81 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+var (
+	ErrProofIteratorStale = errors.New("trie no longer holds the iterator's current key/value pair")
+
+	errNoCurrentKey = errors.New("iterator isn't positioned at a key")
+
+	_ ProofIterator = (*proofIterator)(nil)
+)
+
+type ProofIteratee interface {
+	// NewProofIteratorWithStartAndPrefix returns an iterator over the
+	// key/value pairs with keys >= [start] that have [prefix], which can also
+	// produce a proof of the current key/value pair.
+	NewProofIteratorWithStartAndPrefix(start, prefix []byte) ProofIterator
+}
+
+// ProofIterator is an iterator that can produce a proof of each key/value
+// pair it returns.
+type ProofIterator interface {
+	database.Iterator
+
+	// Proof returns a proof of the current key/value pair.
+	// The proof is only generated when this is called, so callers that only
+	// need proofs of some key/value pairs don't pay for the rest.
+	// The proof is against the trie's root at the time of this call, which may
+	// differ from the root when the iteration started if the trie has since
+	// been modified. Returns [ErrProofIteratorStale] if the trie no longer
+	// holds the current key/value pair.
+	Proof(ctx context.Context) (*Proof, error)
+}
+
+type proofIterator struct {
+	database.Iterator
+
+	proofGetter ProofGetter
+	hasCurrent  bool
+}
+
+func newProofIterator(it database.Iterator, proofGetter ProofGetter) *proofIterator {
+	return &proofIterator{
+		Iterator:    it,
+		proofGetter: proofGetter,
+	}
+}
+
+func (it *proofIterator) Next() bool {
+	it.hasCurrent = it.Iterator.Next()
+	return it.hasCurrent
+}
+
+func (it *proofIterator) Proof(ctx context.Context) (*Proof, error) {
+	if !it.hasCurrent {
+		return nil, errNoCurrentKey
+	}
+
+	proof, err := it.proofGetter.GetProof(ctx, it.Key())
+	if err != nil {
+		return nil, err
+	}
+	if proof.Value.IsNothing() || !bytes.Equal(proof.Value.Value(), it.Value()) {
+		return nil, ErrProofIteratorStale
+	}
+	return proof, nil
+}
+
+func (it *proofIterator) Release() {
+	it.hasCurrent = false
+	it.Iterator.Release()
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+func Test_ProofIterator(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("a"), []byte("1")))
+	require.NoError(db.Put([]byte("b1"), []byte("2")))
+	require.NoError(db.Put([]byte("b2"), []byte("3")))
+	require.NoError(db.Put([]byte("c"), []byte("4")))
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	it := db.NewProofIteratorWithStartAndPrefix(nil, []byte("b"))
+	defer it.Release()
+
+	_, err = it.Proof(context.Background())
+	require.ErrorIs(err, errNoCurrentKey)
+
+	for _, expectedKey := range []string{"b1", "b2"} {
+		require.True(it.Next())
+		require.Equal([]byte(expectedKey), it.Key())
+
+		proof, err := it.Proof(context.Background())
+		require.NoError(err)
+		require.Equal(ToKey(it.Key()), proof.Key)
+		require.Equal(maybe.Some(it.Value()), proof.Value)
+		require.NoError(proof.Verify(context.Background(), root, db.tokenSize))
+	}
+
+	require.False(it.Next())
+	require.NoError(it.Error())
+
+	_, err = it.Proof(context.Background())
+	require.ErrorIs(err, errNoCurrentKey)
+}
+
+func Test_ProofIterator_Stale(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	it := db.NewProofIteratorWithStartAndPrefix(nil, nil)
+	defer it.Release()
+
+	require.True(it.Next())
+	require.NoError(db.Put([]byte("key"), []byte("other value")))
+
+	_, err = it.Proof(context.Background())
+	require.ErrorIs(err, ErrProofIteratorStale)
+}
+
+func Test_TrieView_ProofIterator(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key1"), []byte("value1")))
+
+	view, err := db.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: []database.BatchOp{
+				{Key: []byte("key2"), Value: []byte("value2")},
+			},
+		},
+	)
+	require.NoError(err)
+
+	root, err := view.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	it := view.NewProofIteratorWithStartAndPrefix([]byte("key2"), nil)
+	defer it.Release()
+
+	require.True(it.Next())
+	require.Equal([]byte("key2"), it.Key())
+
+	proof, err := it.Proof(context.Background())
+	require.NoError(err)
+	require.Equal(maybe.Some([]byte("value2")), proof.Value)
+	require.NoError(proof.Verify(context.Background(), root, db.tokenSize))
+
+	require.False(it.Next())
+	require.NoError(it.Error())
+}
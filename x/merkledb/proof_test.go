@@ -12,6 +12,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"google.golang.org/protobuf/proto"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/hashing"
@@ -142,7 +144,7 @@ func Test_RangeProof_Extra_Value(t *testing.T) {
 	require.NoError(err)
 	require.Equal([]byte{2}, val)
 
-	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{5, 5}), 10)
+	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{5, 5}), 10, 0)
 	require.NoError(err)
 	require.NotNil(proof)
 
@@ -217,7 +219,7 @@ func Test_RangeProof_Verify_Bad_Data(t *testing.T) {
 			require.NoError(err)
 			writeBasicBatch(t, db)
 
-			proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{2}), maybe.Some([]byte{3, 0}), 50)
+			proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{2}), maybe.Some([]byte{3, 0}), 50, 0)
 			require.NoError(err)
 			require.NotNil(proof)
 
@@ -238,13 +240,58 @@ func Test_RangeProof_MaxLength(t *testing.T) {
 	trie, err := dbTrie.NewView(context.Background(), ViewChanges{})
 	require.NoError(err)
 
-	_, err = trie.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), -1)
+	_, err = trie.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), -1, 0)
 	require.ErrorIs(err, ErrInvalidMaxLength)
 
-	_, err = trie.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 0)
+	_, err = trie.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 0, 0)
 	require.ErrorIs(err, ErrInvalidMaxLength)
 }
 
+func Test_RangeProof_MaxBytes(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := 0; i < 100; i++ {
+		require.NoError(db.Put([]byte{byte(i)}, make([]byte, 100)))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	_, err = db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100, -1)
+	require.ErrorIs(err, ErrInvalidMaxBytes)
+
+	// A max bytes of 0 doesn't limit the proof.
+	proof, err := db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100, 0)
+	require.NoError(err)
+	require.Len(proof.KeyValues, 100)
+
+	for _, maxBytes := range []int{4_000, 6_000, 8_000} {
+		proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Nothing[[]byte](), 100, maxBytes)
+		require.NoError(err)
+		require.NotEmpty(proof.KeyValues)
+		require.Less(len(proof.KeyValues), 99)
+		require.LessOrEqual(proto.Size(proof.ToProto()), maxBytes)
+
+		require.NoError(proof.Verify(
+			context.Background(),
+			maybe.Some([]byte{1}),
+			maybe.Some(proof.KeyValues[len(proof.KeyValues)-1].Key),
+			root,
+			db.tokenSize,
+		))
+	}
+
+	_, err = db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Nothing[[]byte](), 100, 10)
+	require.ErrorIs(err, ErrMinProofTooLarge)
+
+	// A non-empty range must not be returned as a proof without key-value
+	// pairs, as that would claim the range is empty.
+	require.NoError(db.Put([]byte{1}, make([]byte, 10_000)))
+	_, err = db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Nothing[[]byte](), 100, 8_000)
+	require.ErrorIs(err, ErrMinProofTooLarge)
+}
+
 func Test_Proof(t *testing.T) {
 	require := require.New(t)
 
@@ -488,7 +535,7 @@ func Test_RangeProof(t *testing.T) {
 	require.NoError(err)
 	writeBasicBatch(t, db)
 
-	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{3, 5}), 10)
+	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{3, 5}), 10, 0)
 	require.NoError(err)
 	require.NotNil(proof)
 	require.Len(proof.KeyValues, 3)
@@ -523,7 +570,7 @@ func Test_RangeProof_BadBounds(t *testing.T) {
 	require.NoError(err)
 
 	// non-nil start/end
-	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{4}), maybe.Some([]byte{3}), 50)
+	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{4}), maybe.Some([]byte{3}), 50, 0)
 	require.ErrorIs(err, ErrStartAfterEnd)
 	require.Nil(proof)
 }
@@ -544,7 +591,7 @@ func Test_RangeProof_NilStart(t *testing.T) {
 	require.NoError(err)
 	require.Equal([]byte("value1"), val)
 
-	proof, err := db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Some([]byte("key35")), 2)
+	proof, err := db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Some([]byte("key35")), 2, 0)
 	require.NoError(err)
 	require.NotNil(proof)
 
@@ -576,7 +623,7 @@ func Test_RangeProof_NilEnd(t *testing.T) {
 	writeBasicBatch(t, db)
 	require.NoError(err)
 
-	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Nothing[[]byte](), 2)
+	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{1}), maybe.Nothing[[]byte](), 2, 0)
 	require.NoError(err)
 	require.NotNil(proof)
 
@@ -617,7 +664,7 @@ func Test_RangeProof_EmptyValues(t *testing.T) {
 	require.NoError(err)
 	require.Equal([]byte("value1"), val)
 
-	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte("key1")), maybe.Some([]byte("key2")), 10)
+	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte("key1")), maybe.Some([]byte("key2")), 10, 0)
 	require.NoError(err)
 	require.NotNil(proof)
 
@@ -1661,6 +1708,7 @@ func FuzzRangeProofInvariants(f *testing.F) {
 			start,
 			end,
 			int(maxProofLen),
+			0,
 		)
 		require.NoError(err)
 
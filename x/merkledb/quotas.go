@@ -0,0 +1,224 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+var (
+	_ QuotaTracker = (*merkleDB)(nil)
+	_ QuotaTracker = (*trieView)(nil)
+
+	ErrKeyQuotaExceeded  = errors.New("key quota exceeded")
+	ErrByteQuotaExceeded = errors.New("byte quota exceeded")
+	ErrNoQuota           = errors.New("no quota for prefix")
+
+	errEmptyQuotaPrefix       = errors.New("quota prefix is empty")
+	errOverlappingQuotaPrefix = errors.New("quota prefixes overlap")
+)
+
+// PrefixQuota bounds the state stored under a key prefix.
+type PrefixQuota struct {
+	// Keys with this prefix count towards the quota.
+	Prefix []byte
+	// The maximum number of keys with [Prefix].
+	// If 0 is specified, the number of keys isn't bounded.
+	MaxKeys uint64
+	// The maximum number of key and value bytes of the keys with [Prefix].
+	// If 0 is specified, the number of bytes isn't bounded.
+	MaxBytes uint64
+}
+
+// PrefixUsage is the state stored under the prefix of a quota.
+type PrefixUsage struct {
+	// The number of keys with the prefix.
+	Keys uint64
+	// The total length of the keys with the prefix and their values.
+	Bytes uint64
+}
+
+// QuotaExceededError is returned when recording a change in a view would
+// result in the usage of a quota exceeding it. It wraps
+// [ErrKeyQuotaExceeded] or [ErrByteQuotaExceeded].
+type QuotaExceededError struct {
+	Quota PrefixQuota
+	// The usage the change would have resulted in.
+	Usage PrefixUsage
+	Err   error
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%v: prefix 0x%x would hold %d keys with %d bytes, the quota is %d keys with %d bytes",
+		e.Err,
+		e.Quota.Prefix,
+		e.Usage.Keys,
+		e.Usage.Bytes,
+		e.Quota.MaxKeys,
+		e.Quota.MaxBytes,
+	)
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return e.Err
+}
+
+type QuotaTracker interface {
+	// GetPrefixUsage returns the usage of the quota whose prefix is [prefix].
+	// Returns [ErrNoQuota] if there is no such quota.
+	GetPrefixUsage(prefix []byte) (PrefixUsage, error)
+}
+
+// quotas are the prefix quotas of a database, sorted by prefix.
+// Since prefixes don't overlap, a key has at most one quota.
+type quotas []PrefixQuota
+
+func newQuotas(configQuotas []PrefixQuota) (quotas, error) {
+	q := make(quotas, len(configQuotas))
+	for i, quota := range configQuotas {
+		if len(quota.Prefix) == 0 {
+			return nil, errEmptyQuotaPrefix
+		}
+		quota.Prefix = slices.Clone(quota.Prefix)
+		q[i] = quota
+	}
+	slices.SortFunc(q, func(a, b PrefixQuota) bool {
+		return bytes.Compare(a.Prefix, b.Prefix) < 0
+	})
+	// If a prefix is a prefix of another, it's also a prefix of the prefix
+	// sorted directly after it.
+	for i := 1; i < len(q); i++ {
+		if bytes.HasPrefix(q[i].Prefix, q[i-1].Prefix) {
+			return nil, fmt.Errorf("%w: 0x%x and 0x%x", errOverlappingQuotaPrefix, q[i-1].Prefix, q[i].Prefix)
+		}
+	}
+	return q, nil
+}
+
+// index returns the index of the quota of [key], or false if [key] has no
+// quota.
+func (q quotas) index(key []byte) (int, bool) {
+	// Find the last prefix <= [key]. It's the only prefix that [key] may
+	// have, since prefixes don't overlap.
+	i, found := slices.BinarySearchFunc(q, key, func(quota PrefixQuota, target []byte) int {
+		return bytes.Compare(quota.Prefix, target)
+	})
+	if found {
+		return i, true
+	}
+	if i == 0 || !bytes.HasPrefix(key, q[i-1].Prefix) {
+		return 0, false
+	}
+	return i - 1, true
+}
+
+// Returns the usage of [q] by the values of the database.
+// Assumes the values of [db] aren't being modified.
+func (db *merkleDB) calculateQuotaUsage() ([]PrefixUsage, error) {
+	usage := make([]PrefixUsage, len(db.quotas))
+	for i, quota := range db.quotas {
+		it := db.NewIteratorWithPrefix(quota.Prefix)
+		for it.Next() {
+			usage[i].add(it.Key(), maybe.Some(it.Value()))
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return usage, nil
+}
+
+func (db *merkleDB) GetPrefixUsage(prefix []byte) (PrefixUsage, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return getPrefixUsage(db.quotas, db.quotaUsage, prefix)
+}
+
+// getQuotaUsage returns the usage of the quotas of [db] by the committed
+// values.
+func (db *merkleDB) getQuotaUsage() []PrefixUsage {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return slices.Clone(db.quotaUsage)
+}
+
+// GetPrefixUsage returns the usage of the quota whose prefix is [prefix],
+// including the changes made by [t] and its uncommitted ancestors.
+// Returns [ErrNoQuota] if there is no such quota, or if [t] is a historical
+// view, which doesn't track usage.
+func (t *trieView) GetPrefixUsage(prefix []byte) (PrefixUsage, error) {
+	return getPrefixUsage(t.db.quotas, t.quotaUsage, prefix)
+}
+
+func getPrefixUsage(q quotas, usage []PrefixUsage, prefix []byte) (PrefixUsage, error) {
+	i, ok := q.index(prefix)
+	if !ok || usage == nil || !bytes.Equal(q[i].Prefix, prefix) {
+		return PrefixUsage{}, fmt.Errorf("%w: 0x%x", ErrNoQuota, prefix)
+	}
+	return usage[i], nil
+}
+
+// Records, in [t.quotaUsage], that the value of [key] changed from [before] to
+// [after]. Returns a [*QuotaExceededError] if the change increases the usage of
+// the quota of [key] past it, in which case the usage isn't changed.
+func (t *trieView) recordQuotaUsage(key Key, before, after maybe.Maybe[[]byte]) error {
+	if t.quotaUsage == nil {
+		return nil
+	}
+	keyBytes := key.Bytes()
+	i, ok := t.db.quotas.index(keyBytes)
+	if !ok {
+		return nil
+	}
+
+	usage := t.quotaUsage[i]
+	usage.remove(keyBytes, before)
+	usage.add(keyBytes, after)
+
+	quota := t.db.quotas[i]
+	switch {
+	case quota.MaxKeys != 0 && usage.Keys > quota.MaxKeys && usage.Keys > t.quotaUsage[i].Keys:
+		return &QuotaExceededError{
+			Quota: quota,
+			Usage: usage,
+			Err:   ErrKeyQuotaExceeded,
+		}
+	case quota.MaxBytes != 0 && usage.Bytes > quota.MaxBytes && usage.Bytes > t.quotaUsage[i].Bytes:
+		return &QuotaExceededError{
+			Quota: quota,
+			Usage: usage,
+			Err:   ErrByteQuotaExceeded,
+		}
+	}
+	t.quotaUsage[i] = usage
+	return nil
+}
+
+// add records that [key] has [value].
+func (u *PrefixUsage) add(key []byte, value maybe.Maybe[[]byte]) {
+	if value.IsNothing() {
+		return
+	}
+	u.Keys++
+	u.Bytes += uint64(len(key) + len(value.Value()))
+}
+
+// remove records that [key] no longer has [value].
+func (u *PrefixUsage) remove(key []byte, value maybe.Maybe[[]byte]) {
+	if value.IsNothing() {
+		return
+	}
+	u.Keys--
+	u.Bytes -= uint64(len(key) + len(value.Value()))
+}
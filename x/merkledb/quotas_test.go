@@ -0,0 +1,211 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+func newQuotaTestDB(t *testing.T, baseDB database.Database, prefixQuotas ...PrefixQuota) *merkleDB {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.PrefixQuotas = prefixQuotas
+	db, err := newDatabase(
+		context.Background(),
+		baseDB,
+		config,
+		&mockMetrics{},
+	)
+	require.NoError(err)
+	return db
+}
+
+func TestNewQuotas(t *testing.T) {
+	tests := []struct {
+		name        string
+		quotas      []PrefixQuota
+		expectedErr error
+	}{
+		{
+			name: "disjoint",
+			quotas: []PrefixQuota{
+				{Prefix: []byte("b/")},
+				{Prefix: []byte("a/")},
+				{Prefix: []byte("ab/")},
+			},
+		},
+		{
+			name: "empty prefix",
+			quotas: []PrefixQuota{
+				{Prefix: []byte{}},
+			},
+			expectedErr: errEmptyQuotaPrefix,
+		},
+		{
+			name: "duplicate prefix",
+			quotas: []PrefixQuota{
+				{Prefix: []byte("a/")},
+				{Prefix: []byte("a/")},
+			},
+			expectedErr: errOverlappingQuotaPrefix,
+		},
+		{
+			name: "prefix of another prefix",
+			quotas: []PrefixQuota{
+				{Prefix: []byte("app1/")},
+				{Prefix: []byte("app2")},
+				{Prefix: []byte("app1")},
+			},
+			expectedErr: errOverlappingQuotaPrefix,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newQuotas(test.quotas)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestQuotasIndex(t *testing.T) {
+	require := require.New(t)
+
+	q, err := newQuotas([]PrefixQuota{
+		{Prefix: []byte("app2/")},
+		{Prefix: []byte("app1/")},
+	})
+	require.NoError(err)
+
+	i, ok := q.index([]byte("app1/key"))
+	require.True(ok)
+	require.Equal([]byte("app1/"), q[i].Prefix)
+
+	i, ok = q.index([]byte("app2/"))
+	require.True(ok)
+	require.Equal([]byte("app2/"), q[i].Prefix)
+
+	for _, key := range [][]byte{nil, []byte("app"), []byte("app1"), []byte("app3/key")} {
+		_, ok := q.index(key)
+		require.False(ok)
+	}
+}
+
+func TestPrefixQuotas(t *testing.T) {
+	require := require.New(t)
+
+	db := newQuotaTestDB(
+		t,
+		memdb.New(),
+		PrefixQuota{
+			Prefix:  []byte("a/"),
+			MaxKeys: 2,
+		},
+		PrefixQuota{
+			Prefix:   []byte("b/"),
+			MaxBytes: 10,
+		},
+	)
+
+	require.NoError(db.Put([]byte("a/1"), []byte("x")))
+	require.NoError(db.Put([]byte("a/2"), []byte("y")))
+	usage, err := db.GetPrefixUsage([]byte("a/"))
+	require.NoError(err)
+	require.Equal(PrefixUsage{Keys: 2, Bytes: 8}, usage)
+
+	// Keys without a quota aren't bounded.
+	require.NoError(db.Put([]byte("c"), make([]byte, 100)))
+	_, err = db.GetPrefixUsage([]byte("c"))
+	require.ErrorIs(err, ErrNoQuota)
+
+	err = db.Put([]byte("a/3"), []byte("z"))
+	require.ErrorIs(err, ErrKeyQuotaExceeded)
+	var quotaErr *QuotaExceededError
+	require.ErrorAs(err, &quotaErr)
+	require.Equal([]byte("a/"), quotaErr.Quota.Prefix)
+	require.Equal(PrefixUsage{Keys: 3, Bytes: 12}, quotaErr.Usage)
+
+	// Overwriting a key doesn't add a key.
+	require.NoError(db.Put([]byte("a/1"), []byte("xx")))
+
+	err = db.Put([]byte("b/1"), make([]byte, 8))
+	require.ErrorIs(err, ErrByteQuotaExceeded)
+	require.NoError(db.Put([]byte("b/1"), make([]byte, 7)))
+
+	// A key can be added to a quota in the same view that another key is
+	// removed from it.
+	view, err := db.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{
+			{Key: []byte("a/1"), Delete: true},
+			{Key: []byte("a/3"), Value: []byte("z")},
+		},
+	})
+	require.NoError(err)
+	tracker, ok := view.(QuotaTracker)
+	require.True(ok)
+	usage, err = tracker.GetPrefixUsage([]byte("a/"))
+	require.NoError(err)
+	require.Equal(PrefixUsage{Keys: 2, Bytes: 8}, usage)
+
+	// The usage of a view includes the changes of its ancestors.
+	childView, err := view.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{
+			{Key: []byte("a/4"), Value: []byte("w")},
+		},
+	})
+	require.ErrorIs(err, ErrKeyQuotaExceeded)
+	require.Nil(childView)
+
+	// The usage of the database only changes once the view is committed.
+	usage, err = db.GetPrefixUsage([]byte("a/"))
+	require.NoError(err)
+	require.Equal(PrefixUsage{Keys: 2, Bytes: 9}, usage)
+	require.NoError(view.CommitToDB(context.Background()))
+	usage, err = db.GetPrefixUsage([]byte("a/"))
+	require.NoError(err)
+	require.Equal(PrefixUsage{Keys: 2, Bytes: 8}, usage)
+
+	require.NoError(db.Clear())
+	usage, err = db.GetPrefixUsage([]byte("b/"))
+	require.NoError(err)
+	require.Zero(usage)
+}
+
+func TestPrefixQuotasReopen(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	quota := PrefixQuota{
+		Prefix:  []byte("a/"),
+		MaxKeys: 2,
+	}
+	db := newQuotaTestDB(t, baseDB, quota)
+	require.NoError(db.Put([]byte("a/1"), []byte("x")))
+	require.NoError(db.Put([]byte("a/2"), []byte("y")))
+	require.NoError(db.Close())
+
+	// The usage is calculated from the values of the database when it's
+	// opened.
+	db = newQuotaTestDB(t, baseDB, quota)
+	usage, err := db.GetPrefixUsage([]byte("a/"))
+	require.NoError(err)
+	require.Equal(PrefixUsage{Keys: 2, Bytes: 8}, usage)
+
+	err = db.Put([]byte("a/3"), []byte("z"))
+	require.ErrorIs(err, ErrKeyQuotaExceeded)
+
+	// Lowering a quota below its usage doesn't prevent the usage from
+	// decreasing.
+	require.NoError(db.Close())
+	quota.MaxKeys = 1
+	db = newQuotaTestDB(t, baseDB, quota)
+	require.NoError(db.Put([]byte("a/1"), []byte("xyz")))
+	require.NoError(db.Delete([]byte("a/2")))
+}
@@ -0,0 +1,204 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+var (
+	ErrKeyOutsideLockedRange = errors.New("key is outside of the locked range")
+	ErrRangeUnlocked         = errors.New("range lock has been released")
+	ErrUnsupportedView       = errors.New("view wasn't created by merkledb")
+)
+
+type RangeLocker interface {
+	// LockRange blocks until no other held range lock overlaps [start, end]
+	// and then acquires a lock on the range.
+	// If [start] is Nothing, there's no lower bound on the range.
+	// If [end] is Nothing, there's no upper bound on the range.
+	// Range locks are advisory; they only coordinate writers that use them.
+	LockRange(ctx context.Context, start, end maybe.Maybe[[]byte]) (*RangeLock, error)
+}
+
+// RangeLock is a held lock on a range of keys.
+type RangeLock struct {
+	locker *rangeLocker
+	start  maybe.Maybe[[]byte]
+	end    maybe.Maybe[[]byte]
+
+	// Protects [released].
+	lock     sync.Mutex
+	released bool
+}
+
+// Contains returns true iff [key] is in the locked range.
+func (l *RangeLock) Contains(key []byte) bool {
+	return (l.start.IsNothing() || bytes.Compare(key, l.start.Value()) >= 0) &&
+		(l.end.IsNothing() || bytes.Compare(key, l.end.Value()) <= 0)
+}
+
+// Commit commits [view] and its uncommitted ancestors to the database and
+// then releases the lock.
+// Returns [ErrKeyOutsideLockedRange] if [view] or one of its uncommitted
+// ancestors changes a key outside of the locked range, in which case nothing
+// is committed and the lock is still held.
+func (l *RangeLock) Commit(ctx context.Context, view TrieView) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.released {
+		return ErrRangeUnlocked
+	}
+
+	tv, ok := view.(*trieView)
+	if !ok {
+		return ErrUnsupportedView
+	}
+
+	// Gather [view] and its uncommitted ancestors, from [view] to the oldest
+	// ancestor.
+	var chain []*trieView
+	for current := tv; ; {
+		if err := l.checkChanges(current); err != nil {
+			return err
+		}
+		chain = append(chain, current)
+
+		parent := current.getParentTrie()
+		if parent == current.db {
+			break
+		}
+		parentView, ok := parent.(*trieView)
+		if !ok {
+			return ErrUnsupportedView
+		}
+		current = parentView
+	}
+
+	if len(chain) == 1 {
+		if err := view.CommitToDB(ctx); err != nil {
+			return err
+		}
+	} else {
+		views := make([]TrieView, len(chain))
+		for i, chainView := range chain {
+			views[len(chain)-1-i] = chainView
+		}
+		if err := tv.db.CommitViewStack(ctx, views); err != nil {
+			return err
+		}
+	}
+
+	l.released = true
+	l.locker.release(l)
+	return nil
+}
+
+// Returns [ErrKeyOutsideLockedRange] if [view] changes a key outside of the
+// locked range.
+func (l *RangeLock) checkChanges(view *trieView) error {
+	for key := range view.changes.values {
+		keyBytes := key.Bytes()
+		// Epoch records are written on behalf of the keys they belong to.
+		if view.db.isHashedKeyEpochRecord(keyBytes) {
+			continue
+		}
+		if !l.Contains(keyBytes) {
+			return ErrKeyOutsideLockedRange
+		}
+	}
+	return nil
+}
+
+// Unlock releases the lock without committing anything.
+// Calling Unlock on a released lock is a no-op.
+func (l *RangeLock) Unlock() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.released {
+		return
+	}
+	l.released = true
+	l.locker.release(l)
+}
+
+// overlaps returns true iff the range of [l] overlaps the range [start, end].
+func (l *RangeLock) overlaps(start, end maybe.Maybe[[]byte]) bool {
+	startsAfterEnd := start.HasValue() && l.end.HasValue() && bytes.Compare(start.Value(), l.end.Value()) > 0
+	endsBeforeStart := end.HasValue() && l.start.HasValue() && bytes.Compare(end.Value(), l.start.Value()) < 0
+	return !startsAfterEnd && !endsBeforeStart
+}
+
+type rangeLocker struct {
+	lock sync.Mutex
+	held []*RangeLock
+	// Closed and replaced whenever a lock is released.
+	released chan struct{}
+}
+
+func newRangeLocker() *rangeLocker {
+	return &rangeLocker{
+		released: make(chan struct{}),
+	}
+}
+
+func (r *rangeLocker) lockRange(ctx context.Context, start, end maybe.Maybe[[]byte]) (*RangeLock, error) {
+	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) > 0 {
+		return nil, ErrStartAfterEnd
+	}
+
+	for {
+		r.lock.Lock()
+		if !r.isHeld(start, end) {
+			rangeLock := &RangeLock{
+				locker: r,
+				start:  start,
+				end:    end,
+			}
+			r.held = append(r.held, rangeLock)
+			r.lock.Unlock()
+			return rangeLock, nil
+		}
+		released := r.released
+		r.lock.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Returns true iff a held lock overlaps [start, end].
+// Assumes [r.lock] is held.
+func (r *rangeLocker) isHeld(start, end maybe.Maybe[[]byte]) bool {
+	for _, held := range r.held {
+		if held.overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rangeLocker) release(rangeLock *RangeLock) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, held := range r.held {
+		if held == rangeLock {
+			r.held = append(r.held[:i], r.held[i+1:]...)
+			break
+		}
+	}
+	close(r.released)
+	r.released = make(chan struct{})
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+func TestLockRangeDisjointRanges(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	lock1, err := db.LockRange(context.Background(), maybe.Some([]byte{0}), maybe.Some([]byte{1}))
+	require.NoError(err)
+	lock2, err := db.LockRange(context.Background(), maybe.Some([]byte{2}), maybe.Nothing[[]byte]())
+	require.NoError(err)
+
+	view1, err := db.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{{Key: []byte{1}, Value: []byte{1}}},
+	})
+	require.NoError(err)
+	view2, err := view1.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{{Key: []byte{2}, Value: []byte{2}}},
+	})
+	require.NoError(err)
+
+	// [view2] changes a key outside of [lock1].
+	require.ErrorIs(lock1.Commit(context.Background(), view2), ErrKeyOutsideLockedRange)
+	// [view1] is committed along with [view2] and changes a key outside of
+	// [lock2].
+	require.ErrorIs(lock2.Commit(context.Background(), view2), ErrKeyOutsideLockedRange)
+
+	require.NoError(lock1.Commit(context.Background(), view1))
+	require.NoError(lock2.Commit(context.Background(), view2))
+
+	value, err := db.Get([]byte{2})
+	require.NoError(err)
+	require.Equal([]byte{2}, value)
+
+	require.ErrorIs(lock1.Commit(context.Background(), view1), ErrRangeUnlocked)
+}
+
+func TestLockRangeBlocksOverlappingRanges(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	lock, err := db.LockRange(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{3}))
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = db.LockRange(ctx, maybe.Nothing[[]byte](), maybe.Some([]byte{1}))
+	require.ErrorIs(err, context.DeadlineExceeded)
+
+	acquired := make(chan *RangeLock, 1)
+	errs := make(chan error, 1)
+	go func() {
+		lock, err := db.LockRange(context.Background(), maybe.Some([]byte{3}), maybe.Nothing[[]byte]())
+		if err != nil {
+			errs <- err
+			return
+		}
+		acquired <- lock
+	}()
+
+	lock.Unlock()
+	select {
+	case lock := <-acquired:
+		lock.Unlock()
+	case err := <-errs:
+		require.NoError(err)
+	}
+
+	_, err = db.LockRange(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{0}))
+	require.ErrorIs(err, ErrStartAfterEnd)
+}
+
+func TestLockRangeCommitsUncommittedAncestors(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	lock, err := db.LockRange(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{3}))
+	require.NoError(err)
+
+	view1, err := db.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{{Key: []byte{1}, Value: []byte{1}}},
+	})
+	require.NoError(err)
+	view2, err := view1.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{{Key: []byte{2}, Value: []byte{2}}},
+	})
+	require.NoError(err)
+	view3, err := view2.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{{Key: []byte{3}, Value: []byte{3}}},
+	})
+	require.NoError(err)
+
+	require.NoError(lock.Commit(context.Background(), view3))
+
+	for i := byte(1); i <= 3; i++ {
+		value, err := db.Get([]byte{i})
+		require.NoError(err)
+		require.Equal([]byte{i}, value)
+	}
+	expectedRoot, err := view3.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+}
+
+func TestLockRangeRejectsAncestorOutsideRange(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	lock, err := db.LockRange(context.Background(), maybe.Some([]byte{2}), maybe.Nothing[[]byte]())
+	require.NoError(err)
+
+	view1, err := db.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{{Key: []byte{1}, Value: []byte{1}}},
+	})
+	require.NoError(err)
+	view2, err := view1.NewView(context.Background(), ViewChanges{
+		BatchOps: []database.BatchOp{{Key: []byte{2}, Value: []byte{2}}},
+	})
+	require.NoError(err)
+
+	// [view1] changes a key outside of the locked range, so neither view is
+	// committed.
+	require.ErrorIs(lock.Commit(context.Background(), view2), ErrKeyOutsideLockedRange)
+
+	_, err = db.Get([]byte{1})
+	require.ErrorIs(err, database.ErrNotFound)
+	_, err = db.Get([]byte{2})
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// The lock is still held.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = db.LockRange(ctx, maybe.Some([]byte{3}), maybe.Some([]byte{3}))
+	require.ErrorIs(err, context.DeadlineExceeded)
+}
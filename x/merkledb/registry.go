@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+var (
+	ErrNamespaceExists   = errors.New("namespace already registered")
+	ErrNamespaceNotFound = errors.New("namespace not registered")
+)
+
+// Registry manages multiple independent merkle databases over a single
+// underlying database. Each database is stored under its own namespace and
+// has its own root and change history.
+type Registry struct {
+	lock   sync.Mutex
+	baseDB database.Database
+	closed bool
+	// namespace --> database stored under that namespace
+	dbs map[string]MerkleDB
+}
+
+// NewRegistry returns a registry that stores the databases it creates in [db].
+func NewRegistry(db database.Database) *Registry {
+	return &Registry{
+		baseDB: db,
+		dbs:    make(map[string]MerkleDB),
+	}
+}
+
+// New creates a merkle database stored under [namespace].
+// Each database must be given its own [config.Reg] since the metrics of
+// databases in the same registry would otherwise conflict.
+// Returns [ErrNamespaceExists] if [namespace] is already in use.
+func (r *Registry) New(ctx context.Context, namespace []byte, config Config) (MerkleDB, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil, database.ErrClosed
+	}
+
+	key := string(namespace)
+	if _, ok := r.dbs[key]; ok {
+		return nil, fmt.Errorf("%w: %x", ErrNamespaceExists, namespace)
+	}
+
+	db, err := New(ctx, prefixdb.New(namespace, r.baseDB), config)
+	if err != nil {
+		return nil, err
+	}
+	r.dbs[key] = db
+	return db, nil
+}
+
+// Get returns the database stored under [namespace].
+// Returns [ErrNamespaceNotFound] if no such database was created.
+func (r *Registry) Get(namespace []byte) (MerkleDB, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return nil, database.ErrClosed
+	}
+
+	db, ok := r.dbs[string(namespace)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %x", ErrNamespaceNotFound, namespace)
+	}
+	return db, nil
+}
+
+// Namespaces returns the registered namespaces in sorted order.
+func (r *Registry) Namespaces() [][]byte {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	namespaces := maps.Keys(r.dbs)
+	slices.Sort(namespaces)
+
+	result := make([][]byte, len(namespaces))
+	for i, namespace := range namespaces {
+		result[i] = []byte(namespace)
+	}
+	return result
+}
+
+// Close closes every database in the registry.
+// The underlying database is not closed.
+func (r *Registry) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return database.ErrClosed
+	}
+	r.closed = true
+
+	errs := wrappers.Errs{}
+	for _, db := range r.dbs {
+		errs.Add(db.Close())
+	}
+	return errs.Err
+}
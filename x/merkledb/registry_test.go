@@ -0,0 +1,82 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+func TestRegistryIndependentNamespaces(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	registry := NewRegistry(baseDB)
+
+	db1, err := registry.New(context.Background(), []byte("db1"), newDefaultConfig())
+	require.NoError(err)
+	db2, err := registry.New(context.Background(), []byte("db2"), newDefaultConfig())
+	require.NoError(err)
+
+	emptyRoot, err := db2.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	require.NoError(db1.Put([]byte("key"), []byte("value")))
+
+	_, err = db2.Get([]byte("key"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	root1, err := db1.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	root2, err := db2.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.NotEqual(root1, root2)
+	require.Equal(emptyRoot, root2)
+
+	got, err := registry.Get([]byte("db1"))
+	require.NoError(err)
+	require.Equal(db1, got)
+
+	require.Equal([][]byte{[]byte("db1"), []byte("db2")}, registry.Namespaces())
+
+	require.NoError(registry.Close())
+
+	// Reopening the namespace should restore its state.
+	registry = NewRegistry(baseDB)
+	db1, err = registry.New(context.Background(), []byte("db1"), newDefaultConfig())
+	require.NoError(err)
+	value, err := db1.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+}
+
+func TestRegistryErrors(t *testing.T) {
+	require := require.New(t)
+
+	registry := NewRegistry(memdb.New())
+
+	_, err := registry.Get([]byte("db"))
+	require.ErrorIs(err, ErrNamespaceNotFound)
+
+	_, err = registry.New(context.Background(), []byte("db"), newDefaultConfig())
+	require.NoError(err)
+
+	config := newDefaultConfig()
+	config.Reg = prometheus.NewRegistry()
+	_, err = registry.New(context.Background(), []byte("db"), config)
+	require.ErrorIs(err, ErrNamespaceExists)
+
+	require.NoError(registry.Close())
+
+	_, err = registry.New(context.Background(), []byte("other"), newDefaultConfig())
+	require.ErrorIs(err, database.ErrClosed)
+	require.ErrorIs(registry.Close(), database.ErrClosed)
+}
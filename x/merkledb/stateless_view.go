@@ -0,0 +1,468 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+var (
+	_ MerkleRootGetter = (*StatelessView)(nil)
+	_ ProofGetter      = (*StatelessView)(nil)
+	_ nodeSource       = (*Witness)(nil)
+
+	ErrMissingWitnessNode = errors.New("node is missing from the witness")
+	ErrNodeIDMismatch     = errors.New("node doesn't match the ID expected by its parent")
+)
+
+// nodeSource provides the nodes of a trie to a [StatelessView].
+type nodeSource interface {
+	// get a copy of the node with the given key path
+	// hasValue indicates which db to look in (value or intermediate)
+	getEditableNode(key Key, hasValue bool) (*node, error)
+}
+
+// StatelessView is a read-only view of a trie with a fixed root.
+//
+// Every node read by the view is recorded and verified against the ID that
+// its parent expects it to have. The recorded nodes can be exported with
+// [GetWitness] so that a verifier can re-execute the same reads against only
+// the witness, using [NewStatelessViewFromWitness].
+type StatelessView struct {
+	rootID    ids.ID
+	tokenSize int
+	source    nodeSource
+
+	// The nil key node
+	// It is either the root of the trie or the root of the trie is its single child node
+	sentinelNode *node
+
+	// Protects [readNodes].
+	lock sync.Mutex
+	// Key --> Node for every node read by this view
+	readNodes map[Key]*node
+}
+
+// NewStatelessView returns a StatelessView of the current state of [trie].
+// [trie] must not be modified while the returned view is in use.
+func NewStatelessView(
+	ctx context.Context,
+	trie ReadOnlyTrie,
+	branchFactor BranchFactor,
+) (*StatelessView, error) {
+	rootID, err := trie.GetMerkleRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newStatelessView(rootID, trie, branchFactor)
+}
+
+// NewStatelessViewFromWitness returns a StatelessView of the trie with root
+// [rootID] that can only read the nodes included in [witness].
+// Reads that require a node that isn't in [witness] return
+// [ErrMissingWitnessNode].
+func NewStatelessViewFromWitness(
+	rootID ids.ID,
+	witness *Witness,
+	branchFactor BranchFactor,
+) (*StatelessView, error) {
+	return newStatelessView(rootID, witness, branchFactor)
+}
+
+func newStatelessView(
+	rootID ids.ID,
+	source nodeSource,
+	branchFactor BranchFactor,
+) (*StatelessView, error) {
+	if err := branchFactor.Valid(); err != nil {
+		return nil, err
+	}
+
+	v := &StatelessView{
+		rootID:    rootID,
+		tokenSize: BranchFactorToTokenSize[branchFactor],
+		source:    source,
+		readNodes: make(map[Key]*node),
+	}
+
+	sentinelNode, err := source.getEditableNode(Key{}, false /* hasValue */)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the sentinel node is not the root, the trie's root is the sentinel
+	// node's only child. The child is verified once it is read.
+	sentinelID := rootID
+	if isSentinelNodeTheRoot(sentinelNode) {
+		sentinelID = codec.hashValues(sentinelNode)
+	} else {
+		for _, childEntry := range sentinelNode.children {
+			sentinelID = childEntry.id
+		}
+	}
+	if sentinelID != rootID {
+		return nil, fmt.Errorf("%w: %s != %s", ErrNodeIDMismatch, sentinelID, rootID)
+	}
+
+	v.sentinelNode = sentinelNode
+	v.readNodes[Key{}] = sentinelNode
+	return v, nil
+}
+
+func (v *StatelessView) GetMerkleRoot(context.Context) (ids.ID, error) {
+	return v.rootID, nil
+}
+
+// GetValue returns the value for the given [key].
+// Returns database.ErrNotFound if it doesn't exist.
+func (v *StatelessView) GetValue(_ context.Context, key []byte) ([]byte, error) {
+	return v.getValueCopy(ToKey(key))
+}
+
+// GetValues returns the values for the given [keys].
+// The error for a key is database.ErrNotFound if it doesn't exist.
+func (v *StatelessView) GetValues(_ context.Context, keys [][]byte) ([][]byte, []error) {
+	results := make([][]byte, len(keys))
+	valueErrors := make([]error, len(keys))
+	for i, key := range keys {
+		results[i], valueErrors[i] = v.getValueCopy(ToKey(key))
+	}
+	return results, valueErrors
+}
+
+// GetWitness returns the nodes read by this view so far.
+// The returned witness is sufficient to re-execute the same reads with a view
+// returned by [NewStatelessViewFromWitness].
+func (v *StatelessView) GetWitness() *Witness {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	return &Witness{
+		nodes: maps.Clone(v.readNodes),
+	}
+}
+
+// GetProof returns a proof that [key] is in or not in the trie.
+//
+// If the view was created from a witness that doesn't include every node
+// needed to generate the proof, returns [ErrMissingWitnessNode].
+func (v *StatelessView) GetProof(_ context.Context, key []byte) (*Proof, error) {
+	return v.getProof(key)
+}
+
+// GetRangeProof returns a range proof for (at least part of) the key range
+// [start, end]. The returned proof's [KeyValues] has at most [maxLength]
+// values. [maxLength] must be > 0.
+//
+// The nodes read to generate the proof are recorded in the view's witness.
+func (v *StatelessView) GetRangeProof(
+	_ context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) (*RangeProof, error) {
+	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) == 1 {
+		return nil, ErrStartAfterEnd
+	}
+
+	if maxLength <= 0 {
+		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxLength)
+	}
+
+	var result RangeProof
+
+	result.KeyValues = make([]KeyValue, 0, initKeyValuesSize)
+	if _, err := v.iterateFrom(v.sentinelNode, ToKey(start.Value()), func(n *node) bool {
+		key := n.key.Bytes()
+		if end.HasValue() && bytes.Compare(key, end.Value()) > 0 {
+			return false
+		}
+		// clone the value to prevent editing of the values stored within the trie
+		result.KeyValues = append(result.KeyValues, KeyValue{
+			Key:   key,
+			Value: slices.Clone(n.value.Value()),
+		})
+		return len(result.KeyValues) < maxLength
+	}); err != nil {
+		return nil, err
+	}
+
+	// This proof may not contain all key-value pairs in [start, end] due to size limitations.
+	// The end proof we provide should be for the last key-value pair in the proof, not for
+	// the last key-value pair requested, which may not be in this proof.
+	var (
+		endProof *Proof
+		err      error
+	)
+	if len(result.KeyValues) > 0 {
+		greatestKey := result.KeyValues[len(result.KeyValues)-1].Key
+		endProof, err = v.getProof(greatestKey)
+		if err != nil {
+			return nil, err
+		}
+	} else if end.HasValue() {
+		endProof, err = v.getProof(end.Value())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if endProof != nil {
+		result.EndProof = endProof.Path
+	}
+
+	if start.HasValue() {
+		startProof, err := v.getProof(start.Value())
+		if err != nil {
+			return nil, err
+		}
+		result.StartProof = startProof.Path
+
+		// strip out any common nodes to reduce proof size
+		i := 0
+		for ; i < len(result.StartProof) &&
+			i < len(result.EndProof) &&
+			result.StartProof[i].Key == result.EndProof[i].Key; i++ {
+		}
+		result.StartProof = result.StartProof[i:]
+	}
+
+	if len(result.StartProof) == 0 && len(result.EndProof) == 0 && len(result.KeyValues) == 0 {
+		// If the range is empty, return the root proof.
+		root, err := v.getRoot()
+		if err != nil {
+			return nil, err
+		}
+		rootProof, err := v.getProof(root.key.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		result.EndProof = rootProof.Path
+	}
+	return &result, nil
+}
+
+// Returns a proof that [key] is in or not in the trie.
+func (v *StatelessView) getProof(key []byte) (*Proof, error) {
+	proof := &Proof{
+		Key: ToKey(key),
+	}
+
+	var closestNode *node
+	if err := v.visitPathToKey(proof.Key, func(n *node) error {
+		closestNode = n
+		proof.Path = append(proof.Path, n.asProofNode())
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// The sentinel node is always the first node in the path.
+	// If the sentinel node is not the root, remove it from the proofPath.
+	if !isSentinelNodeTheRoot(v.sentinelNode) {
+		proof.Path = proof.Path[1:]
+
+		// if there are no nodes in the proof path, add the root to serve as an exclusion proof
+		if len(proof.Path) == 0 {
+			root, err := v.getRoot()
+			if err != nil {
+				return nil, err
+			}
+			proof.Path = []ProofNode{root.asProofNode()}
+			return proof, nil
+		}
+	}
+
+	if closestNode.key == proof.Key {
+		// There is a node with the given [key].
+		proof.Value = maybe.Bind(closestNode.value, slices.Clone[[]byte])
+		return proof, nil
+	}
+
+	// There is no node with the given [key].
+	// If there is a child at the index where the node would be
+	// if it existed, include that child in the proof.
+	nextIndex := proof.Key.Token(closestNode.key.length, v.tokenSize)
+	childEntry, ok := closestNode.children[nextIndex]
+	if !ok {
+		return proof, nil
+	}
+
+	childNode, err := v.getChild(
+		closestNode.key.Extend(ToToken(nextIndex, v.tokenSize), childEntry.compressedKey),
+		childEntry,
+	)
+	if err != nil {
+		return nil, err
+	}
+	proof.Path = append(proof.Path, childNode.asProofNode())
+	return proof, nil
+}
+
+// Returns the root of the trie, which is either the sentinel node or its only
+// child.
+func (v *StatelessView) getRoot() (*node, error) {
+	if isSentinelNodeTheRoot(v.sentinelNode) {
+		return v.sentinelNode, nil
+	}
+	for index, childEntry := range v.sentinelNode.children {
+		return v.getChild(
+			v.sentinelNode.key.Extend(ToToken(index, v.tokenSize), childEntry.compressedKey),
+			childEntry,
+		)
+	}
+	return v.sentinelNode, nil
+}
+
+// Calls [visitValue], in key order, on every node in the subtrie rooted at [n]
+// that has a value and whose key is >= [start], until [visitValue] returns
+// false. Only the nodes that are needed to find the visited values are read.
+//
+// Returns false if [visitValue] returned false.
+func (v *StatelessView) iterateFrom(n *node, start Key, visitValue func(*node) bool) (bool, error) {
+	// A node's value precedes the values of its descendants.
+	if n.hasValue() && !n.key.Less(start) {
+		if !visitValue(n) {
+			return false, nil
+		}
+	}
+
+	indices := maps.Keys(n.children)
+	slices.Sort(indices)
+	for _, index := range indices {
+		childEntry := n.children[index]
+		childKey := n.key.Extend(ToToken(index, v.tokenSize), childEntry.compressedKey)
+
+		// Every key in the child's subtrie has [childKey] as a prefix, so the
+		// subtrie can be skipped if all of those keys are before [start].
+		if childKey.Less(start) && !start.HasPrefix(childKey) {
+			continue
+		}
+
+		childNode, err := v.getChild(childKey, childEntry)
+		if err != nil {
+			return false, err
+		}
+		if shouldContinue, err := v.iterateFrom(childNode, start, visitValue); err != nil || !shouldContinue {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (v *StatelessView) getValueCopy(key Key) ([]byte, error) {
+	var closestNode *node
+	if err := v.visitPathToKey(key, func(n *node) error {
+		closestNode = n
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if closestNode.key != key || !closestNode.hasValue() {
+		return nil, database.ErrNotFound
+	}
+	return slices.Clone(closestNode.value.Value()), nil
+}
+
+// Calls [visitNode] on the nodes along the path to [key].
+// The first node is the sentinel node, and the last node is either the node
+// with the given [key], if it's in the trie, or the node with the largest
+// prefix of the [key] if it isn't in the trie.
+func (v *StatelessView) visitPathToKey(key Key, visitNode func(*node) error) error {
+	// all node paths start at the sentinelNode since its nil key is a prefix of all keys
+	currentNode := v.sentinelNode
+	if err := visitNode(currentNode); err != nil {
+		return err
+	}
+	// while the entire path hasn't been matched
+	for currentNode.key.length < key.length {
+		// confirm that a child exists and grab its ID before attempting to load it
+		nextChildEntry, hasChild := currentNode.children[key.Token(currentNode.key.length, v.tokenSize)]
+
+		if !hasChild || !key.iteratedHasPrefix(nextChildEntry.compressedKey, currentNode.key.length+v.tokenSize, v.tokenSize) {
+			// there was no child along the path or the child that was there doesn't match the remaining path
+			return nil
+		}
+
+		// grab the next node along the path
+		var err error
+		currentNode, err = v.getChild(
+			key.Take(currentNode.key.length+v.tokenSize+nextChildEntry.compressedKey.length),
+			nextChildEntry,
+		)
+		if err != nil {
+			return err
+		}
+		if err := visitNode(currentNode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getChild returns the node with the given [key] that is referenced by
+// [childEntry] and records it as read.
+func (v *StatelessView) getChild(key Key, childEntry *child) (*node, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if n, ok := v.readNodes[key]; ok {
+		return n, nil
+	}
+
+	n, err := v.source.getEditableNode(key, childEntry.hasValue)
+	if err != nil {
+		return nil, err
+	}
+	if id := codec.hashValues(n); id != childEntry.id {
+		return nil, fmt.Errorf("%w: %s != %s", ErrNodeIDMismatch, id, childEntry.id)
+	}
+
+	v.readNodes[key] = n
+	return n, nil
+}
+
+// Witness is a set of trie nodes that is sufficient to re-execute a sequence
+// of reads against a trie with a known root.
+type Witness struct {
+	// Key --> Node
+	nodes map[Key]*node
+}
+
+// ParseWitness parses a witness serialized with [Witness.Bytes].
+func ParseWitness(b []byte) (*Witness, error) {
+	w := &Witness{}
+	if err := codec.decodeWitness(b, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Bytes returns the deterministic serialization of the witness.
+func (w *Witness) Bytes() []byte {
+	return codec.encodeWitness(w)
+}
+
+// Len returns the number of nodes in the witness.
+func (w *Witness) Len() int {
+	return len(w.nodes)
+}
+
+func (w *Witness) getEditableNode(key Key, _ bool) (*node, error) {
+	n, ok := w.nodes[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %x", ErrMissingWitnessNode, key.Bytes())
+	}
+	return n.clone(), nil
+}
@@ -0,0 +1,255 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+// Writes []byte{i} -> []byte{i, i} for i in [0, 10)
+func newStatelessViewTestDB(t *testing.T) *merkleDB {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	batch := db.NewBatch()
+	for i := byte(0); i < 10; i++ {
+		require.NoError(batch.Put([]byte{i}, []byte{i, i}))
+	}
+	require.NoError(batch.Write())
+	return db
+}
+
+func Test_StatelessView_Witness(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db := newStatelessViewTestDB(t)
+	rootID, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	view, err := NewStatelessView(ctx, db, BranchFactor16)
+	require.NoError(err)
+
+	readKeys := [][]byte{{1}, {2}, {0xff}}
+	expectedValues, expectedErrs := view.GetValues(ctx, readKeys)
+	require.Equal([][]byte{{1, 1}, {2, 2}, nil}, expectedValues)
+	require.Equal([]error{nil, nil, database.ErrNotFound}, expectedErrs)
+
+	witness := view.GetWitness()
+	witnessBytes := witness.Bytes()
+
+	parsedWitness, err := ParseWitness(witnessBytes)
+	require.NoError(err)
+	require.Equal(witness.Len(), parsedWitness.Len())
+	require.Equal(witnessBytes, parsedWitness.Bytes())
+
+	verifierView, err := NewStatelessViewFromWitness(rootID, parsedWitness, BranchFactor16)
+	require.NoError(err)
+
+	values, errs := verifierView.GetValues(ctx, readKeys)
+	require.Equal(expectedValues, values)
+	require.Equal(expectedErrs, errs)
+
+	// Reading a key whose path wasn't read by [view] isn't possible.
+	_, err = verifierView.GetValue(ctx, []byte{7})
+	require.ErrorIs(err, ErrMissingWitnessNode)
+
+	// Re-executing the reads against the witness only reads the witness.
+	require.Equal(witnessBytes, verifierView.GetWitness().Bytes())
+}
+
+func Test_StatelessView_Witness_WrongRoot(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db := newStatelessViewTestDB(t)
+	view, err := NewStatelessView(ctx, db, BranchFactor16)
+	require.NoError(err)
+
+	_, err = view.GetValue(ctx, []byte{1})
+	require.NoError(err)
+
+	_, err = NewStatelessViewFromWitness(ids.GenerateTestID(), view.GetWitness(), BranchFactor16)
+	require.ErrorIs(err, ErrNodeIDMismatch)
+}
+
+func Test_StatelessView_Witness_View(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db := newStatelessViewTestDB(t)
+	trieView, err := db.NewView(ctx, ViewChanges{
+		BatchOps: []database.BatchOp{
+			{Key: []byte{1}, Value: []byte{3}},
+			{Key: []byte{2}, Delete: true},
+		},
+	})
+	require.NoError(err)
+	rootID, err := trieView.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	view, err := NewStatelessView(ctx, trieView, BranchFactor16)
+	require.NoError(err)
+
+	readKeys := [][]byte{{1}, {2}}
+	expectedValues, expectedErrs := view.GetValues(ctx, readKeys)
+	require.Equal([][]byte{{3}, nil}, expectedValues)
+	require.Equal([]error{nil, database.ErrNotFound}, expectedErrs)
+
+	verifierView, err := NewStatelessViewFromWitness(rootID, view.GetWitness(), BranchFactor16)
+	require.NoError(err)
+
+	values, errs := verifierView.GetValues(ctx, readKeys)
+	require.Equal(expectedValues, values)
+	require.Equal(expectedErrs, errs)
+}
+
+func Test_Witness_Parse_Unsorted(t *testing.T) {
+	require := require.New(t)
+
+	n := newNode(ToKey([]byte{1}))
+	w := &Witness{
+		nodes: map[Key]*node{
+			n.key: n,
+		},
+	}
+	nodeBytes := w.Bytes()[1:]
+
+	// Encode the same node twice
+	witnessBytes := append([]byte{2}, nodeBytes...)
+	witnessBytes = append(witnessBytes, nodeBytes...)
+
+	_, err := ParseWitness(witnessBytes)
+	require.ErrorIs(err, errWitnessKeysUnsorted)
+}
+
+func Test_StatelessView_GetRangeProof(t *testing.T) {
+	ctx := context.Background()
+
+	db := newStatelessViewTestDB(t)
+	rootID, err := db.GetMerkleRoot(ctx)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		start     maybe.Maybe[[]byte]
+		end       maybe.Maybe[[]byte]
+		maxLength int
+	}{
+		{
+			name:      "full range",
+			start:     maybe.Nothing[[]byte](),
+			end:       maybe.Nothing[[]byte](),
+			maxLength: 100,
+		},
+		{
+			name:      "bounded range",
+			start:     maybe.Some([]byte{2}),
+			end:       maybe.Some([]byte{6}),
+			maxLength: 100,
+		},
+		{
+			name:      "truncated range",
+			start:     maybe.Some([]byte{1}),
+			end:       maybe.Nothing[[]byte](),
+			maxLength: 3,
+		},
+		{
+			name:      "empty range",
+			start:     maybe.Some([]byte{0x20}),
+			end:       maybe.Some([]byte{0x30}),
+			maxLength: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			expectedProof, err := db.GetRangeProof(ctx, tt.start, tt.end, tt.maxLength, 0)
+			require.NoError(err)
+
+			view, err := NewStatelessView(ctx, db, BranchFactor16)
+			require.NoError(err)
+
+			proof, err := view.GetRangeProof(ctx, tt.start, tt.end, tt.maxLength)
+			require.NoError(err)
+			require.Equal(expectedProof.KeyValues, proof.KeyValues)
+			require.Equal(expectedProof.StartProof, proof.StartProof)
+			require.Equal(expectedProof.EndProof, proof.EndProof)
+			require.NoError(proof.Verify(ctx, tt.start, tt.end, rootID, view.tokenSize))
+
+			// The witness is sufficient to regenerate the proof.
+			verifierView, err := NewStatelessViewFromWitness(rootID, view.GetWitness(), BranchFactor16)
+			require.NoError(err)
+
+			verifierProof, err := verifierView.GetRangeProof(ctx, tt.start, tt.end, tt.maxLength)
+			require.NoError(err)
+			require.Equal(proof, verifierProof)
+		})
+	}
+}
+
+func Test_StatelessView_GetRangeProof_InvalidArgs(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db := newStatelessViewTestDB(t)
+	view, err := NewStatelessView(ctx, db, BranchFactor16)
+	require.NoError(err)
+
+	_, err = view.GetRangeProof(ctx, maybe.Some([]byte{1}), maybe.Some([]byte{0}), 10)
+	require.ErrorIs(err, ErrStartAfterEnd)
+
+	_, err = view.GetRangeProof(ctx, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 0)
+	require.ErrorIs(err, ErrInvalidMaxLength)
+}
+
+func Test_StatelessView_GetProof(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	db := newStatelessViewTestDB(t)
+	rootID, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	view, err := NewStatelessView(ctx, db, BranchFactor16)
+	require.NoError(err)
+
+	// Inclusion and exclusion proofs
+	keys := [][]byte{{1}, {0x10}}
+	for _, key := range keys {
+		expectedProof, err := db.GetProof(ctx, key)
+		require.NoError(err)
+
+		proof, err := view.GetProof(ctx, key)
+		require.NoError(err)
+		require.Equal(expectedProof, proof)
+		require.NoError(proof.Verify(ctx, rootID, view.tokenSize))
+	}
+
+	verifierView, err := NewStatelessViewFromWitness(rootID, view.GetWitness(), BranchFactor16)
+	require.NoError(err)
+
+	for _, key := range keys {
+		expectedProof, err := view.GetProof(ctx, key)
+		require.NoError(err)
+
+		proof, err := verifierView.GetProof(ctx, key)
+		require.NoError(err)
+		require.Equal(expectedProof, proof)
+	}
+
+	// The witness doesn't include the node for this key.
+	_, err = verifierView.GetProof(ctx, []byte{5})
+	require.ErrorIs(err, ErrMissingWitnessNode)
+}
@@ -46,17 +46,45 @@ type ReadOnlyTrie interface {
 	// keys in range [start, end].
 	// If [start] is Nothing, there's no lower bound on the range.
 	// If [end] is Nothing, there's no upper bound on the range.
-	GetRangeProof(ctx context.Context, start maybe.Maybe[[]byte], end maybe.Maybe[[]byte], maxLength int) (*RangeProof, error)
+	// If [maxBytes] > 0, the greatest key-value pairs are omitted as needed
+	// for the proof's protobuf encoding, including its keys, values and proof
+	// nodes, to be at most [maxBytes] bytes. Returns [ErrMinProofTooLarge] if
+	// the proof exceeds [maxBytes] without any key-value pairs.
+	GetRangeProof(ctx context.Context, start maybe.Maybe[[]byte], end maybe.Maybe[[]byte], maxLength int, maxBytes int) (*RangeProof, error)
 
 	database.Iteratee
+	ProofIteratee
 }
 
+// ViewPriority determines how a view competes with other views for the
+// goroutines used to calculate node IDs.
+type ViewPriority uint8
+
+const (
+	// NormalPriority views wait for a goroutine to be available before
+	// calculating their node IDs.
+	NormalPriority ViewPriority = iota
+	// HighPriority views calculate their node IDs on the calling goroutine
+	// without waiting for other views, and use additional goroutines only if
+	// they're available. This is intended for latency sensitive work, such as
+	// proof generation, that shouldn't be queued behind large views.
+	HighPriority
+)
+
 type ViewChanges struct {
 	BatchOps []database.BatchOp
 	MapOps   map[string]maybe.Maybe[[]byte]
 	// ConsumeBytes when set to true will skip copying of bytes and assume
 	// ownership of the provided bytes.
 	ConsumeBytes bool
+	// Priority of the view when calculating its node IDs.
+	Priority ViewPriority
+	// RootGenConcurrency is the maximum number of goroutines the view uses to
+	// calculate its node IDs. Goroutines are still limited by the database's
+	// [Config.RootGenConcurrency].
+	//
+	// If 0 is specified, [Config.ViewRootGenConcurrency] will be used.
+	RootGenConcurrency uint
 }
 
 type Trie interface {
@@ -76,4 +104,12 @@ type TrieView interface {
 	// CommitToDB writes the changes in this view to the database.
 	// Takes the DB commit lock.
 	CommitToDB(ctx context.Context) error
+
+	// Squash returns a view with the same contents as this view whose reads
+	// don't walk this view's uncommitted ancestors, so that reads from deeply
+	// nested views don't take time proportional to their depth.
+	// The returned view is invalidated whenever this view is. It can only be
+	// committed after this view has been committed.
+	// Takes the DB commit lock.
+	Squash(ctx context.Context) (TrieView, error)
 }
@@ -12,10 +12,13 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"golang.org/x/sync/semaphore"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/utils/maybe"
 )
 
 func getNodeValue(t ReadOnlyTrie, key string) ([]byte, error) {
@@ -1073,7 +1076,7 @@ func TestNewViewOnCommittedView(t *testing.T) {
 	//  db
 
 	// Note that view2 being committed invalidates view1
-	require.True(view1.invalidated)
+	require.True(view1.isInvalid())
 	require.Contains(db.childViews, view2)
 	require.Contains(db.childViews, view3)
 	require.Len(db.childViews, 2)
@@ -1083,7 +1086,7 @@ func TestNewViewOnCommittedView(t *testing.T) {
 	require.NoError(view3.CommitToDB(context.Background()))
 
 	// view3 being committed invalidates view2
-	require.True(view2.invalidated)
+	require.True(view2.isInvalid())
 	require.Contains(db.childViews, view3)
 	require.Len(db.childViews, 1)
 	require.Equal(db, view3.parentTrie)
@@ -1141,7 +1144,8 @@ func Test_TrieView_NewView(t *testing.T) {
 	require.NotContains(view1.childViews, view3)
 
 	// Assert that NewPreallocatedView on an invalid view fails
-	invalidView := &trieView{invalidated: true}
+	invalidView := &trieView{}
+	invalidView.invalidatedEpoch.Store(1)
 	_, err = invalidView.NewView(context.Background(), ViewChanges{})
 	require.ErrorIs(err, ErrInvalid)
 }
@@ -1176,12 +1180,12 @@ func TestTrieViewInvalidate(t *testing.T) {
 	//     db
 
 	// Invalidate view1
-	view1.invalidate()
+	view1.invalidate(1)
 
 	require.Empty(view1.childViews)
-	require.True(view1.invalidated)
-	require.True(view2.invalidated)
-	require.True(view3.invalidated)
+	require.True(view1.isInvalid())
+	require.True(view2.isInvalid())
+	require.True(view3.isInvalid())
 }
 
 func Test_Trie_ConcurrentNewViewAndCommit(t *testing.T) {
@@ -1245,7 +1249,7 @@ func TestTrieCommitToDB(t *testing.T) {
 				r.NoError(err)
 
 				// Invalidate the view
-				view.(*trieView).invalidate()
+				view.(*trieView).invalidate(1)
 
 				return view
 			},
@@ -1324,3 +1328,310 @@ func TestTrieCommitToDB(t *testing.T) {
 	r.NoError(err)
 	r.Equal(value3, got)
 }
+
+func TestTrieViewPriority(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	ops := make([]database.BatchOp, 0, 100)
+	for i := 0; i < 100; i++ {
+		ops = append(ops, database.BatchOp{
+			Key:   []byte(strconv.Itoa(i)),
+			Value: []byte(strconv.Itoa(i)),
+		})
+	}
+
+	normalView, err := db.NewView(context.Background(), ViewChanges{BatchOps: ops})
+	require.NoError(err)
+	expectedRoot, err := normalView.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Simulate other views using every goroutine.
+	rootGenConcurrency := db.calculateNodeIDsLimiter.Limit()
+	for i := 0; i < rootGenConcurrency; i++ {
+		db.calculateNodeIDsLimiter.Acquire()
+	}
+
+	// A high priority view doesn't wait for the goroutines to be released.
+	highPriorityView, err := db.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: ops,
+			Priority: HighPriority,
+		},
+	)
+	require.NoError(err)
+	root, err := highPriorityView.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+
+	for i := 0; i < rootGenConcurrency; i++ {
+		db.calculateNodeIDsLimiter.Release()
+	}
+
+	// Limiting the view's goroutines doesn't change its root.
+	singleGoroutineView, err := db.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps:           ops,
+			RootGenConcurrency: 1,
+		},
+	)
+	require.NoError(err)
+	root, err = singleGoroutineView.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+}
+
+func TestDeterministicHashing(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	config := newDefaultConfig()
+	config.DeterministicHashing = true
+	deterministicDB, err := newDatabase(
+		context.Background(),
+		memdb.New(),
+		config,
+		&mockMetrics{},
+	)
+	require.NoError(err)
+
+	ops := make([]database.BatchOp, 0, 100)
+	for i := 0; i < 100; i++ {
+		ops = append(ops, database.BatchOp{
+			Key:   []byte(strconv.Itoa(i)),
+			Value: []byte(strconv.Itoa(i)),
+		})
+	}
+
+	// Hashing deterministically doesn't change the root.
+	for _, d := range []*merkleDB{db, deterministicDB} {
+		view, err := d.NewView(context.Background(), ViewChanges{BatchOps: ops})
+		require.NoError(err)
+		require.NoError(view.CommitToDB(context.Background()))
+	}
+	expectedRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	root, err := deterministicDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+
+	// No goroutines are used to hash a deterministic view.
+	view, err := deterministicDB.NewView(context.Background(), ViewChanges{})
+	require.NoError(err)
+	require.False(view.(*trieView).tryAcquireGoroutine(semaphore.NewWeighted(1)))
+}
+
+func TestTrieViewSquash(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte{0}, []byte{0}))
+
+	view1, err := db.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: []database.BatchOp{
+				{Key: []byte{1}, Value: []byte{1}},
+				{Key: []byte{2}, Value: []byte{2}},
+			},
+		},
+	)
+	require.NoError(err)
+
+	// Squashing a view whose parent is the db is a no-op.
+	squashed1, err := view1.Squash(context.Background())
+	require.NoError(err)
+	require.Equal(view1, squashed1)
+
+	view2, err := view1.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: []database.BatchOp{
+				{Key: []byte{1}, Delete: true},
+				{Key: []byte{3}, Value: []byte{3}},
+			},
+		},
+	)
+	require.NoError(err)
+
+	view3, err := view2.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: []database.BatchOp{
+				{Key: []byte{2}, Value: []byte{4}},
+			},
+		},
+	)
+	require.NoError(err)
+
+	squashedIntf, err := view3.Squash(context.Background())
+	require.NoError(err)
+	require.IsType(&trieView{}, squashedIntf)
+	squashed := squashedIntf.(*trieView)
+	require.Equal(db, squashed.getParentTrie())
+
+	expectedRoot, err := view3.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	root, err := squashed.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+
+	for _, kv := range []struct {
+		key   []byte
+		value []byte
+	}{
+		{key: []byte{0}, value: []byte{0}},
+		{key: []byte{2}, value: []byte{4}},
+		{key: []byte{3}, value: []byte{3}},
+	} {
+		value, err := squashed.GetValue(context.Background(), kv.key)
+		require.NoError(err)
+		require.Equal(kv.value, value)
+	}
+	_, err = squashed.GetValue(context.Background(), []byte{1})
+	require.ErrorIs(err, database.ErrNotFound)
+
+	proof, err := squashed.GetProof(context.Background(), []byte{3})
+	require.NoError(err)
+	require.NoError(proof.Verify(context.Background(), expectedRoot, db.tokenSize))
+
+	// The squashed view can't be committed before [view3].
+	require.ErrorIs(squashed.CommitToDB(context.Background()), ErrSquashedViewUncommitted)
+	require.ErrorIs(db.CommitViewStack(context.Background(), []TrieView{squashed}), ErrSquashedViewInStack)
+
+	// Committing the ancestors of [view3] doesn't invalidate the squashed
+	// view.
+	require.NoError(view1.CommitToDB(context.Background()))
+	require.NoError(view2.CommitToDB(context.Background()))
+	value, err := squashed.GetValue(context.Background(), []byte{2})
+	require.NoError(err)
+	require.Equal([]byte{4}, value)
+
+	// Views built on the squashed view can be committed once [view3] is.
+	view4, err := squashed.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: []database.BatchOp{
+				{Key: []byte{4}, Value: []byte{4}},
+			},
+		},
+	)
+	require.NoError(err)
+	expectedRoot, err = view4.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	require.NoError(view3.CommitToDB(context.Background()))
+	require.NoError(squashed.CommitToDB(context.Background()))
+	require.NoError(view4.CommitToDB(context.Background()))
+	require.Equal(expectedRoot, db.getMerkleRoot())
+}
+
+func TestTrieViewSquashInvalidated(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	view1, err := db.NewView(context.Background(), ViewChanges{})
+	require.NoError(err)
+	view2, err := view1.NewView(context.Background(), ViewChanges{})
+	require.NoError(err)
+
+	squashed, err := view2.Squash(context.Background())
+	require.NoError(err)
+
+	// Committing a sibling of [view1] invalidates [view1], [view2] and the
+	// squashed view.
+	sibling, err := db.NewView(
+		context.Background(),
+		ViewChanges{
+			BatchOps: []database.BatchOp{
+				{Key: []byte{1}, Value: []byte{1}},
+			},
+		},
+	)
+	require.NoError(err)
+	require.NoError(sibling.CommitToDB(context.Background()))
+
+	_, err = squashed.GetValue(context.Background(), []byte{1})
+	require.ErrorIs(err, ErrInvalid)
+	_, err = view2.Squash(context.Background())
+	require.ErrorIs(err, ErrInvalid)
+}
+
+// Returns a database with [numKeys] random keys and the keys, so that views
+// of it can be benchmarked.
+func getBenchmarkDB(b *testing.B, numKeys int) (*merkleDB, [][]byte) {
+	require := require.New(b)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	keys := make([][]byte, numKeys)
+	ops := make([]database.BatchOp, numKeys)
+	for i := range keys {
+		keys[i] = make([]byte, 32)
+		_, _ = r.Read(keys[i])
+		ops[i] = database.BatchOp{
+			Key:   keys[i],
+			Value: keys[i],
+		}
+	}
+	view, err := db.NewView(context.Background(), ViewChanges{BatchOps: ops})
+	require.NoError(err)
+	require.NoError(view.CommitToDB(context.Background()))
+	return db, keys
+}
+
+func Benchmark_TrieView_Insert(b *testing.B) {
+	require := require.New(b)
+
+	db, _ := getBenchmarkDB(b, 10_000)
+	r := rand.New(rand.NewSource(1)) // #nosec G404
+	keys := make([]Key, 100)
+	for i := range keys {
+		key := make([]byte, 32)
+		_, _ = r.Read(key)
+		keys[i] = ToKey(key)
+	}
+	value := maybe.Some([]byte{1})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		view, err := db.NewView(context.Background(), ViewChanges{})
+		require.NoError(err)
+		for _, key := range keys {
+			_, err := view.(*trieView).insert(key, value)
+			require.NoError(err)
+		}
+	}
+}
+
+func Benchmark_TrieView_Remove(b *testing.B) {
+	require := require.New(b)
+
+	db, dbKeys := getBenchmarkDB(b, 10_000)
+	keys := make([]Key, 100)
+	for i := range keys {
+		keys[i] = ToKey(dbKeys[i])
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		view, err := db.NewView(context.Background(), ViewChanges{})
+		require.NoError(err)
+		for _, key := range keys {
+			require.NoError(view.(*trieView).remove(key))
+		}
+	}
+}
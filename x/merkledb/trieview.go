@@ -9,12 +9,18 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 
 	oteltrace "go.opentelemetry.io/otel/trace"
 
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
+	"golang.org/x/sync/semaphore"
+
+	"google.golang.org/protobuf/proto"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
@@ -35,11 +41,12 @@ var (
 	ErrPartialByteLengthWithValue = errors.New(
 		"the underlying db only supports whole number of byte keys, so cannot record changes with partial byte lengths",
 	)
-	ErrVisitPathToKey         = errors.New("failed to visit expected node during insertion")
-	ErrStartAfterEnd          = errors.New("start key > end key")
-	ErrNoValidRoot            = errors.New("a valid root was not provided to the trieView constructor")
-	ErrParentNotDatabase      = errors.New("parent trie is not database")
-	ErrNodesAlreadyCalculated = errors.New("cannot modify the trie after the node changes have been calculated")
+	ErrVisitPathToKey          = errors.New("failed to visit expected node during insertion")
+	ErrStartAfterEnd           = errors.New("start key > end key")
+	ErrNoValidRoot             = errors.New("a valid root was not provided to the trieView constructor")
+	ErrParentNotDatabase       = errors.New("parent trie is not database")
+	ErrNodesAlreadyCalculated  = errors.New("cannot modify the trie after the node changes have been calculated")
+	ErrSquashedViewUncommitted = errors.New("cannot commit a squashed view before the view it was squashed from")
 )
 
 type trieView struct {
@@ -55,12 +62,17 @@ type trieView struct {
 	calculateNodesOnce sync.Once
 
 	// Controls the trie's validity related fields.
-	// Must be held while reading/writing [childViews], [invalidated], and [parentTrie].
+	// Must be held while writing [invalidatedEpoch] and while reading/writing
+	// [childViews] and [parentTrie].
 	// Only use to lock current trieView or descendants of the current trieView
 	// DO NOT grab the [validityTrackingLock] of any ancestor trie while this is held.
 	validityTrackingLock sync.RWMutex
 
-	// If true, this view has been invalidated and can't be used.
+	// The commit epoch of [db] when this view was created.
+	// Note that this is unrelated to [epoch].
+	createdEpoch uint64
+	// If non-zero, this view has been invalidated by the commit that started
+	// this commit epoch of [db], and can't be used.
 	//
 	// Invariant: This view is marked as invalid before any of its ancestors change.
 	// Since we ensure that all subviews are marked invalid before making an invalidating change
@@ -70,16 +82,17 @@ type trieView struct {
 	//
 	// *Code Accessing Ancestor State*
 	//
-	// if t.isInvalid() {
-	//     return ErrInvalid
-	//  }
+	// if err := t.checkValid(); err != nil {
+	//     return err
+	// }
 	// return [result]
 	//
-	// If the invalidated check passes, then we're guaranteed that no ancestor changes occurred
-	// during the code that accessed ancestor state and the result of that work is still valid
+	// If the validity check passes, then we're guaranteed that no ancestor changes occurred
+	// during the code that accessed ancestor state and the result of that work is still valid.
 	//
-	// [validityTrackingLock] must be held when reading/writing this field.
-	invalidated bool
+	// Reading this field doesn't require [validityTrackingLock], so checking
+	// validity is a single atomic load.
+	invalidatedEpoch atomic.Uint64
 
 	// the uncommitted parent trie of this view
 	// [validityTrackingLock] must be held when reading/writing this field.
@@ -96,11 +109,34 @@ type trieView struct {
 
 	db *merkleDB
 
+	// The epoch recorded as the last-write epoch of the keys changed by this
+	// view. Only used if [db] tracks key epochs.
+	epoch uint64
+
+	// The usage of the quotas of [db], indexed like [db.quotas], including
+	// the changes made by this view and its uncommitted ancestors.
+	// Nil if usage isn't tracked, which is the case for historical views.
+	quotaUsage []PrefixUsage
+
 	// The nil key node
 	// It is either the root of the trie or the root of the trie is its single child node
 	sentinelNode *node
 
 	tokenSize int
+
+	// Determines how this view competes with other views for the goroutines
+	// used to calculate node IDs.
+	priority ViewPriority
+	// The maximum number of goroutines used to calculate this view's node
+	// IDs.
+	rootGenConcurrency uint
+
+	// The view this view was squashed from, if any.
+	// A squashed view reads directly from [db] but is tracked as a child of
+	// [squashedFrom], so it's invalidated whenever [squashedFrom] is. The
+	// changes of a squashed view are written to [db] by committing
+	// [squashedFrom] and its ancestors.
+	squashedFrom *trieView
 }
 
 // NewView returns a new view on top of this Trie where the passed changes
@@ -111,8 +147,8 @@ func (t *trieView) NewView(
 	ctx context.Context,
 	changes ViewChanges,
 ) (TrieView, error) {
-	if t.isInvalid() {
-		return nil, ErrInvalid
+	if err := t.checkValid(); err != nil {
+		return nil, err
 	}
 	t.commitLock.RLock()
 	defer t.commitLock.RUnlock()
@@ -133,14 +169,94 @@ func (t *trieView) NewView(
 	t.validityTrackingLock.Lock()
 	defer t.validityTrackingLock.Unlock()
 
-	if t.invalidated {
-		return nil, ErrInvalid
+	if err := t.checkValid(); err != nil {
+		return nil, err
 	}
 	t.childViews = append(t.childViews, newView)
 
 	return newView, nil
 }
 
+// Squash returns a view with the same contents as [t] whose parent is the
+// database. Its changes are the combined changes of [t] and its uncommitted
+// ancestors, so reads of keys that aren't in the view go directly to the
+// database rather than walking [t]'s ancestors.
+// If [t]'s parent is already the database, [t] is returned.
+// Assumes [t.db.commitLock] and [t.commitLock] aren't held.
+func (t *trieView) Squash(ctx context.Context) (TrieView, error) {
+	ctx, span := t.db.infoTracer.Start(ctx, "MerkleDB.trieview.Squash")
+	defer span.End()
+
+	// Prevent [t]'s ancestors from being committed while they're squashed.
+	t.db.commitLock.RLock()
+	defer t.db.commitLock.RUnlock()
+
+	if err := t.checkValid(); err != nil {
+		return nil, err
+	}
+	t.commitLock.RLock()
+	defer t.commitLock.RUnlock()
+
+	if t.committed {
+		return t.getParentTrie().Squash(ctx)
+	}
+
+	// Gather [t] and its uncommitted ancestors, from the oldest ancestor to
+	// [t].
+	var views []*trieView
+	for view := t; ; {
+		views = append(views, view)
+		parent := view.getParentTrie()
+		if parent == t.db {
+			break
+		}
+		parentView, ok := parent.(*trieView)
+		if !ok {
+			return nil, ErrUnsupportedView
+		}
+		view = parentView
+	}
+	if len(views) == 1 {
+		return t, nil
+	}
+
+	changes := make([]*changeSummary, len(views))
+	for i, view := range views {
+		if err := view.calculateNodeIDs(ctx); err != nil {
+			return nil, err
+		}
+		changes[len(views)-1-i] = view.changes
+	}
+
+	// The squashed view shares nodes with [views]. This is safe because nodes
+	// aren't modified after their IDs have been calculated.
+	squashedView := &trieView{
+		sentinelNode:       t.sentinelNode,
+		db:                 t.db,
+		parentTrie:         t.db,
+		changes:            mergeChangeSummaries(changes),
+		tokenSize:          t.tokenSize,
+		epoch:              t.epoch,
+		quotaUsage:         slices.Clone(t.quotaUsage),
+		createdEpoch:       t.db.commitEpoch.Load(),
+		priority:           t.priority,
+		rootGenConcurrency: t.rootGenConcurrency,
+		squashedFrom:       t,
+	}
+	// The node IDs of the combined changes have already been calculated.
+	squashedView.calculateNodesOnce.Do(func() {})
+	squashedView.nodesAlreadyCalculated.Set(true)
+
+	t.validityTrackingLock.Lock()
+	defer t.validityTrackingLock.Unlock()
+
+	if err := t.checkValid(); err != nil {
+		return nil, err
+	}
+	t.childViews = append(t.childViews, squashedView)
+	return squashedView, nil
+}
+
 // Creates a new view with the given [parentTrie].
 // Assumes [parentTrie] isn't locked.
 func newTrieView(
@@ -156,12 +272,31 @@ func newTrieView(
 		return nil, err
 	}
 
+	rootGenConcurrency := db.viewRootGenConcurrency
+	if changes.RootGenConcurrency != 0 {
+		rootGenConcurrency = changes.RootGenConcurrency
+	}
+
 	newView := &trieView{
-		sentinelNode: sentinelNode,
-		db:           db,
-		parentTrie:   parentTrie,
-		changes:      newChangeSummary(len(changes.BatchOps) + len(changes.MapOps)),
-		tokenSize:    db.tokenSize,
+		sentinelNode:       sentinelNode,
+		db:                 db,
+		parentTrie:         parentTrie,
+		changes:            newChangeSummary(len(changes.BatchOps) + len(changes.MapOps)),
+		tokenSize:          db.tokenSize,
+		epoch:              db.epoch.Get(),
+		createdEpoch:       db.commitEpoch.Load(),
+		priority:           changes.Priority,
+		rootGenConcurrency: rootGenConcurrency,
+	}
+	if len(db.quotas) > 0 {
+		switch parent := parentTrie.(type) {
+		case *merkleDB:
+			newView.quotaUsage = parent.getQuotaUsage()
+		case *trieView:
+			// The usage of [parent] doesn't change, since its node IDs have
+			// been calculated.
+			newView.quotaUsage = slices.Clone(parent.quotaUsage)
+		}
 	}
 
 	for _, op := range changes.BatchOps {
@@ -189,9 +324,34 @@ func newTrieView(
 			return nil, err
 		}
 	}
+	if db.hashKeyEpochs() {
+		if err := newView.recordKeyEpochs(); err != nil {
+			return nil, err
+		}
+	}
 	return newView, nil
 }
 
+// Records, in the trie, the epoch of each key changed by [t].
+// Assumes [t.db] hashes key epochs.
+func (t *trieView) recordKeyEpochs() error {
+	// Gather the keys first since recording epochs modifies [t.changes.values].
+	for _, key := range maps.Keys(t.changes.values) {
+		epochKey, ok := t.db.hashedKeyEpochKey(key.Bytes())
+		if !ok {
+			continue
+		}
+		epoch := maybe.Some(keyEpochBytes(t.epoch))
+		if t.changes.values[key].after.IsNothing() {
+			epoch = maybe.Nothing[[]byte]()
+		}
+		if err := t.recordValueChange(toKey(epochKey), epoch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Creates a view of the db at a historical root using the provided changes
 func newHistoricalTrieView(
 	db *merkleDB,
@@ -212,6 +372,7 @@ func newHistoricalTrieView(
 		parentTrie:   db,
 		changes:      changes,
 		tokenSize:    db.tokenSize,
+		createdEpoch: db.commitEpoch.Load(),
 	}
 	// since this is a set of historical changes, all nodes have already been calculated
 	// since no new changes have occurred, no new calculations need to be done
@@ -225,8 +386,7 @@ func newHistoricalTrieView(
 func (t *trieView) calculateNodeIDs(ctx context.Context) error {
 	var err error
 	t.calculateNodesOnce.Do(func() {
-		if t.isInvalid() {
-			err = ErrInvalid
+		if err = t.checkValid(); err != nil {
 			return
 		}
 		defer t.nodesAlreadyCalculated.Set(true)
@@ -238,21 +398,43 @@ func (t *trieView) calculateNodeIDs(ctx context.Context) error {
 		defer span.End()
 
 		// add all the changed key/values to the nodes of the trie
-		for key, change := range t.changes.values {
-			if change.after.IsNothing() {
+		applyChange := func(key Key, valueChange *change[maybe.Maybe[[]byte]]) error {
+			if valueChange.after.IsNothing() {
+				return t.remove(key)
+			}
+			_, err := t.insert(key, valueChange.after)
+			return err
+		}
+		if t.db.deterministicHashing {
+			for _, key := range sortedKeys(t.changes.values) {
 				// Note we're setting [err] defined outside this function.
-				if err = t.remove(key); err != nil {
+				if err = applyChange(key, t.changes.values[key]); err != nil {
 					return
 				}
+			}
+		} else {
+			for key, change := range t.changes.values {
 				// Note we're setting [err] defined outside this function.
-			} else if _, err = t.insert(key, change.after); err != nil {
-				return
+				if err = applyChange(key, change); err != nil {
+					return
+				}
 			}
 		}
 
-		_ = t.db.calculateNodeIDsSema.Acquire(context.Background(), 1)
-		t.changes.rootID = t.calculateNodeIDsHelper(t.sentinelNode)
-		t.db.calculateNodeIDsSema.Release(1)
+		// [viewSema] limits the number of goroutines used by this view. The
+		// calling goroutine is one of them.
+		viewSema := semaphore.NewWeighted(int64(t.rootGenConcurrency))
+		_ = viewSema.Acquire(context.Background(), 1)
+		if t.priority == HighPriority {
+			// Don't wait for other views to release their goroutines.
+			t.changes.rootID = t.calculateNodeIDsHelper(t.sentinelNode, viewSema)
+		} else {
+			t.db.calculateNodeIDsLimiter.Acquire()
+			startTime := time.Now()
+			t.changes.rootID = t.calculateNodeIDsHelper(t.sentinelNode, viewSema)
+			t.db.calculateNodeIDsLimiter.Observe(len(t.changes.nodes), time.Since(startTime))
+			t.db.calculateNodeIDsLimiter.Release()
+		}
 
 		// If the sentinel node is not the root, the trie's root is the sentinel node's only child
 		if !isSentinelNodeTheRoot(t.sentinelNode) {
@@ -262,8 +444,7 @@ func (t *trieView) calculateNodeIDs(ctx context.Context) error {
 		}
 
 		// ensure no ancestor changes occurred during execution
-		if t.isInvalid() {
-			err = ErrInvalid
+		if err = t.checkValid(); err != nil {
 			return
 		}
 	})
@@ -272,32 +453,43 @@ func (t *trieView) calculateNodeIDs(ctx context.Context) error {
 
 // Calculates the ID of all descendants of [n] which need to be recalculated,
 // and then calculates the ID of [n] itself.
-func (t *trieView) calculateNodeIDsHelper(n *node) ids.ID {
+func (t *trieView) calculateNodeIDsHelper(n *node, viewSema *semaphore.Weighted) ids.ID {
 	// We use [wg] to wait until all descendants of [n] have been updated.
 	var wg sync.WaitGroup
 
-	for childIndex := range n.children {
-		childEntry := n.children[childIndex]
+	updateChild := func(childIndex byte, childEntry *child) {
 		childKey := n.key.Extend(ToToken(childIndex, t.tokenSize), childEntry.compressedKey)
 		childNodeChange, ok := t.changes.nodes[childKey]
 		if !ok {
 			// This child wasn't changed.
-			continue
+			return
 		}
 		n.onNodeChanged()
 		childEntry.hasValue = childNodeChange.after.hasValue()
 
 		// Try updating the child and its descendants in a goroutine.
-		if ok := t.db.calculateNodeIDsSema.TryAcquire(1); ok {
+		if ok := t.tryAcquireGoroutine(viewSema); ok {
 			wg.Add(1)
 			go func() {
-				childEntry.id = t.calculateNodeIDsHelper(childNodeChange.after)
-				t.db.calculateNodeIDsSema.Release(1)
+				childEntry.id = t.calculateNodeIDsHelper(childNodeChange.after, viewSema)
+				t.db.calculateNodeIDsLimiter.Release()
+				viewSema.Release(1)
 				wg.Done()
 			}()
 		} else {
 			// We're at the goroutine limit; do the work in this goroutine.
-			childEntry.id = t.calculateNodeIDsHelper(childNodeChange.after)
+			childEntry.id = t.calculateNodeIDsHelper(childNodeChange.after, viewSema)
+		}
+	}
+	if t.db.deterministicHashing {
+		childIndices := maps.Keys(n.children)
+		slices.Sort(childIndices)
+		for _, childIndex := range childIndices {
+			updateChild(childIndex, n.children[childIndex])
+		}
+	} else {
+		for childIndex, childEntry := range n.children {
+			updateChild(childIndex, childEntry)
 		}
 	}
 
@@ -308,6 +500,23 @@ func (t *trieView) calculateNodeIDsHelper(n *node) ids.ID {
 	return n.calculateID(t.db.metrics)
 }
 
+// tryAcquireGoroutine returns true iff a goroutine is available to this view
+// without exceeding either this view's or the database's limit. Always returns
+// false if the database hashes deterministically.
+func (t *trieView) tryAcquireGoroutine(viewSema *semaphore.Weighted) bool {
+	if t.db.deterministicHashing {
+		return false
+	}
+	if !viewSema.TryAcquire(1) {
+		return false
+	}
+	if !t.db.calculateNodeIDsLimiter.TryAcquire() {
+		viewSema.Release(1)
+		return false
+	}
+	return true
+}
+
 // GetProof returns a proof that [bytesPath] is in or not in trie [t].
 func (t *trieView) GetProof(ctx context.Context, key []byte) (*Proof, error) {
 	_, span := t.db.infoTracer.Start(ctx, "MerkleDB.trieview.GetProof")
@@ -329,9 +538,21 @@ func (t *trieView) getProof(ctx context.Context, key []byte) (*Proof, error) {
 		Key: ToKey(key),
 	}
 
-	var closestNode *node
+	var (
+		closestNode *node
+		// The nodes fetched while generating the proof. They're released once
+		// the proof has been generated.
+		fetched []*node
+	)
+	defer func() {
+		for _, n := range fetched {
+			t.releaseIfUnrecorded(n)
+		}
+	}()
+
 	if err := t.visitPathToKey(proof.Key, func(n *node) error {
 		closestNode = n
+		fetched = append(fetched, n)
 		proof.Path = append(proof.Path, n.asProofNode())
 		return nil
 	}); err != nil {
@@ -341,6 +562,7 @@ func (t *trieView) getProof(ctx context.Context, key []byte) (*Proof, error) {
 	if err != nil {
 		return nil, err
 	}
+	fetched = append(fetched, root)
 
 	// The sentinel node is always the first node in the path.
 	// If the sentinel node is not the root, remove it from the proofPath.
@@ -376,9 +598,10 @@ func (t *trieView) getProof(ctx context.Context, key []byte) (*Proof, error) {
 	if err != nil {
 		return nil, err
 	}
+	fetched = append(fetched, childNode)
 	proof.Path = append(proof.Path, childNode.asProofNode())
-	if t.isInvalid() {
-		return nil, ErrInvalid
+	if err := t.checkValid(); err != nil {
+		return nil, err
 	}
 	return proof, nil
 }
@@ -391,6 +614,7 @@ func (t *trieView) GetRangeProof(
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
+	maxBytes int,
 ) (*RangeProof, error) {
 	ctx, span := t.db.infoTracer.Start(ctx, "MerkleDB.trieview.GetRangeProof")
 	defer span.End()
@@ -402,16 +626,35 @@ func (t *trieView) GetRangeProof(
 	if maxLength <= 0 {
 		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxLength)
 	}
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxBytes, maxBytes)
+	}
 
 	if err := t.calculateNodeIDs(ctx); err != nil {
 		return nil, err
 	}
 
-	var result RangeProof
+	var (
+		result RangeProof
+		// The sum of the lengths of the keys and values in [result].
+		keyValuesSize int
+		// True if key-value pairs in the range were left out of [result]
+		// because of [maxBytes].
+		truncated bool
+	)
 
 	result.KeyValues = make([]KeyValue, 0, initKeyValuesSize)
 	it := t.NewIteratorWithStart(start.Value())
 	for it.Next() && len(result.KeyValues) < maxLength && (end.IsNothing() || bytes.Compare(it.Key(), end.Value()) <= 0) {
+		keyValueSize := len(it.Key()) + len(it.Value())
+		if maxBytes > 0 && keyValuesSize+keyValueSize > maxBytes {
+			// The proof can't include this key-value pair without exceeding
+			// [maxBytes], regardless of the size of the proof nodes.
+			truncated = true
+			break
+		}
+		keyValuesSize += keyValueSize
+
 		// clone the value to prevent editing of the values stored within the trie
 		result.KeyValues = append(result.KeyValues, KeyValue{
 			Key:   it.Key(),
@@ -423,6 +666,56 @@ func (t *trieView) GetRangeProof(
 		return nil, err
 	}
 
+	for {
+		// A proof without key-value pairs would claim that the range is
+		// empty.
+		if truncated && len(result.KeyValues) == 0 {
+			return nil, ErrMinProofTooLarge
+		}
+		if err := t.setRangeProofPaths(ctx, start, end, &result); err != nil {
+			return nil, err
+		}
+		if maxBytes == 0 {
+			break
+		}
+
+		size := proto.Size(result.ToProto())
+		if size <= maxBytes {
+			break
+		}
+		if len(result.KeyValues) == 0 {
+			return nil, fmt.Errorf("%w: %d > %d", ErrMinProofTooLarge, size, maxBytes)
+		}
+
+		// Remove the greatest key-value pairs until their size covers the
+		// excess. Since the end proof changes when key-value pairs are
+		// removed, the size of the proof must then be re-evaluated.
+		excess := size - maxBytes
+		truncated = true
+		for excess > 0 && len(result.KeyValues) > 0 {
+			lastIndex := len(result.KeyValues) - 1
+			last := result.KeyValues[lastIndex]
+			excess -= len(last.Key) + len(last.Value)
+			result.KeyValues = result.KeyValues[:lastIndex]
+		}
+	}
+
+	if err := t.checkValid(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Sets the start and end proofs of [result] for its key-value pairs.
+func (t *trieView) setRangeProofPaths(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	result *RangeProof,
+) error {
+	result.StartProof = nil
+	result.EndProof = nil
+
 	// This proof may not contain all key-value pairs in [start, end] due to size limitations.
 	// The end proof we provide should be for the last key-value pair in the proof, not for
 	// the last key-value pair requested, which may not be in this proof.
@@ -434,12 +727,12 @@ func (t *trieView) GetRangeProof(
 		greatestKey := result.KeyValues[len(result.KeyValues)-1].Key
 		endProof, err = t.getProof(ctx, greatestKey)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	} else if end.HasValue() {
 		endProof, err = t.getProof(ctx, end.Value())
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 	if endProof != nil {
@@ -449,7 +742,7 @@ func (t *trieView) GetRangeProof(
 	if start.HasValue() {
 		startProof, err := t.getProof(ctx, start.Value())
 		if err != nil {
-			return nil, err
+			return err
 		}
 		result.StartProof = startProof.Path
 
@@ -466,19 +759,15 @@ func (t *trieView) GetRangeProof(
 		// If the range is empty, return the root proof.
 		root, err := t.getRoot()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		rootProof, err := t.getProof(ctx, root.key.Bytes())
 		if err != nil {
-			return nil, err
+			return err
 		}
 		result.EndProof = rootProof.Path
 	}
-
-	if t.isInvalid() {
-		return nil, ErrInvalid
-	}
-	return &result, nil
+	return nil
 }
 
 // CommitToDB commits changes from this trie to the underlying DB.
@@ -504,6 +793,10 @@ func (t *trieView) commitToDB(ctx context.Context) error {
 	))
 	defer span.End()
 
+	if t.squashedFrom != nil {
+		return t.commitSquashed()
+	}
+
 	// Call this here instead of in [t.db.commitChanges]
 	// because doing so there would be a deadlock.
 	if err := t.calculateNodeIDs(ctx); err != nil {
@@ -515,34 +808,95 @@ func (t *trieView) commitToDB(ctx context.Context) error {
 	}
 
 	t.committed = true
+	t.db.commitNotifier.notify(t.changes.rootID, len(t.changes.values))
 
 	return nil
 }
 
-// Assumes [t.validityTrackingLock] isn't held.
+// Marks the squashed view [t] as committed. Its changes were written to the
+// database when [t.squashedFrom] was committed, so nothing is written.
+// Assumes [t.db.commitLock] and [t.commitLock] are held.
+func (t *trieView) commitSquashed() error {
+	t.squashedFrom.commitLock.RLock()
+	squashedFromCommitted := t.squashedFrom.committed
+	t.squashedFrom.commitLock.RUnlock()
+	if !squashedFromCommitted {
+		return ErrSquashedViewUncommitted
+	}
+
+	t.db.lock.Lock()
+	defer t.db.lock.Unlock()
+
+	if t.db.closed {
+		return database.ErrClosed
+	}
+	if err := t.checkValid(); err != nil {
+		return err
+	}
+
+	t.db.moveChildViewsToDB(t)
+	t.committed = true
+	return nil
+}
+
 func (t *trieView) isInvalid() bool {
-	t.validityTrackingLock.RLock()
-	defer t.validityTrackingLock.RUnlock()
+	return t.invalidatedEpoch.Load() != 0
+}
 
-	return t.invalidated
+// checkValid returns [ErrInvalid] if this view has been invalidated. The
+// error records the commit epochs in which the view was created and
+// invalidated, which can be compared to [merkleDB.CommitEpoch] to debug
+// stale views.
+func (t *trieView) checkValid() error {
+	if epoch := t.invalidatedEpoch.Load(); epoch != 0 {
+		return fmt.Errorf("%w: view created in commit epoch %d was invalidated in commit epoch %d",
+			ErrInvalid,
+			t.createdEpoch,
+			epoch,
+		)
+	}
+	return nil
 }
 
-// Invalidates this view and all descendants.
+// Invalidates this view and all descendants because of the commit that
+// started commit epoch [epoch].
 // Assumes [t.validityTrackingLock] isn't held.
-func (t *trieView) invalidate() {
+func (t *trieView) invalidate(epoch uint64) {
 	t.validityTrackingLock.Lock()
 	defer t.validityTrackingLock.Unlock()
 
-	t.invalidated = true
+	// Only the first invalidation is recorded.
+	t.invalidatedEpoch.CompareAndSwap(0, epoch)
 
 	for _, childView := range t.childViews {
-		childView.invalidate()
+		childView.invalidate(epoch)
 	}
 
 	// after invalidating the children, they no longer need to be tracked
 	t.childViews = make([]*trieView, 0, defaultPreallocationSize)
 }
 
+// Invalidates and removes any child views that aren't [exception] because of
+// the commit that started commit epoch [epoch].
+// Assumes [t.validityTrackingLock] isn't held.
+func (t *trieView) invalidateChildrenExcept(exception *trieView, epoch uint64) {
+	t.validityTrackingLock.Lock()
+	defer t.validityTrackingLock.Unlock()
+
+	isTrackedView := false
+	for _, childView := range t.childViews {
+		if childView != exception {
+			childView.invalidate(epoch)
+		} else {
+			isTrackedView = true
+		}
+	}
+	t.childViews = make([]*trieView, 0, defaultPreallocationSize)
+	if isTrackedView {
+		t.childViews = append(t.childViews, exception)
+	}
+}
+
 func (t *trieView) updateParent(newParent TrieView) {
 	t.validityTrackingLock.Lock()
 	defer t.validityTrackingLock.Unlock()
@@ -593,8 +947,8 @@ func (t *trieView) getValueCopy(key Key) ([]byte, error) {
 }
 
 func (t *trieView) getValue(key Key) ([]byte, error) {
-	if t.isInvalid() {
-		return nil, ErrInvalid
+	if err := t.checkValid(); err != nil {
+		return nil, err
 	}
 
 	if change, ok := t.changes.values[key]; ok {
@@ -613,8 +967,8 @@ func (t *trieView) getValue(key Key) ([]byte, error) {
 	}
 
 	// ensure no ancestor changes occurred during execution
-	if t.isInvalid() {
-		return nil, ErrInvalid
+	if err := t.checkValid(); err != nil {
+		return nil, err
 	}
 
 	return value, nil
@@ -636,8 +990,12 @@ func (t *trieView) remove(key Key) error {
 		return err
 	}
 
+	// [keyNode] is only needed to check for a value.
+	hasValue := keyNode.hasValue()
+	t.releaseIfUnrecorded(keyNode)
+
 	// node doesn't contain a value
-	if !keyNode.hasValue() {
+	if !hasValue {
 		return nil
 	}
 
@@ -666,6 +1024,9 @@ func (t *trieView) remove(key Key) error {
 	}
 	if parent != nil {
 		parent.removeChild(nodeToDelete, t.tokenSize)
+		// Only the previous version of [nodeToDelete] was recorded, so
+		// nothing references it anymore.
+		releaseNode(nodeToDelete)
 
 		// merge the parent node and its child into a single node if possible
 		return t.compressNodePath(grandParent, parent)
@@ -695,6 +1056,10 @@ func (t *trieView) compressNodePath(parent, node *node) error {
 		return err
 	}
 
+	// [childKey] is only needed to build the child entry of [parent].
+	buffer := keyBufferPool.Get().(*[]byte)
+	defer keyBufferPool.Put(buffer)
+
 	var (
 		childEntry *child
 		childKey   Key
@@ -703,18 +1068,25 @@ func (t *trieView) compressNodePath(parent, node *node) error {
 	// "Cycle" over the key/values to find the only child.
 	// Note this iteration once because len(node.children) == 1.
 	for index, entry := range node.children {
-		childKey = node.key.Extend(ToToken(index, t.tokenSize), entry.compressedKey)
+		childKey = node.key.extendInto(buffer, ToToken(index, t.tokenSize), entry.compressedKey)
 		childEntry = entry
 	}
 
+	bitsToSkip := parent.key.length + t.tokenSize
+	compressedKey := childKey.Skip(bitsToSkip)
+	if bitsToSkip%8 == 0 {
+		// [compressedKey] references [buffer]
+		compressedKey = compressedKey.clone()
+	}
+
 	// [node] is the first node with multiple children.
 	// combine it with the [node] passed in.
 	parent.setChildEntry(childKey.Token(parent.key.length, t.tokenSize),
-		&child{
-			compressedKey: childKey.Skip(parent.key.length + t.tokenSize),
-			id:            childEntry.id,
-			hasValue:      childEntry.hasValue,
-		})
+		newChild(compressedKey, childEntry.id, childEntry.hasValue))
+
+	// Only the previous version of [node] was recorded, and the entry of
+	// [node] in [parent] was just replaced, so nothing references it anymore.
+	releaseNode(node)
 	return t.recordNodeChange(parent)
 }
 
@@ -732,6 +1104,10 @@ func (t *trieView) visitPathToKey(key Key, visitNode func(*node) error) error {
 	if err := visitNode(currentNode); err != nil {
 		return err
 	}
+	// The keys of the nodes along the path are only needed to look them up.
+	buffer := keyBufferPool.Get().(*[]byte)
+	defer keyBufferPool.Put(buffer)
+
 	// while the entire path hasn't been matched
 	for currentNode.key.length < key.length {
 		// confirm that a child exists and grab its ID before attempting to load it
@@ -742,7 +1118,7 @@ func (t *trieView) visitPathToKey(key Key, visitNode func(*node) error) error {
 			return nil
 		}
 		// grab the next node along the path
-		currentNode, err = t.getNode(key.Take(currentNode.key.length+t.tokenSize+nextChildEntry.compressedKey.length), nextChildEntry.hasValue)
+		currentNode, err = t.getNode(key.takeInto(buffer, currentNode.key.length+t.tokenSize+nextChildEntry.compressedKey.length), nextChildEntry.hasValue)
 		if err != nil {
 			return err
 		}
@@ -756,8 +1132,8 @@ func (t *trieView) visitPathToKey(key Key, visitNode func(*node) error) error {
 // Get a copy of the node matching the passed key from the trie.
 // Used by views to get nodes from their ancestors.
 func (t *trieView) getEditableNode(key Key, hadValue bool) (*node, error) {
-	if t.isInvalid() {
-		return nil, ErrInvalid
+	if err := t.checkValid(); err != nil {
+		return nil, err
 	}
 
 	// grab the node in question
@@ -767,14 +1143,29 @@ func (t *trieView) getEditableNode(key Key, hadValue bool) (*node, error) {
 	}
 
 	// ensure no ancestor changes occurred during execution
-	if t.isInvalid() {
-		return nil, ErrInvalid
+	if err := t.checkValid(); err != nil {
+		return nil, err
 	}
 
 	// return a clone of the node, so it can be edited without affecting this trie
 	return n.clone(), nil
 }
 
+// releaseIfUnrecorded releases [n] if it was fetched from the parent trie by
+// [getNode] and hasn't been recorded in [t.changes], as nothing else can
+// reference it.
+// [n] must have been returned by [getNode] and must not be used after this
+// call.
+func (t *trieView) releaseIfUnrecorded(n *node) {
+	if n == t.sentinelNode {
+		return
+	}
+	if nodeChange, ok := t.changes.nodes[n.key]; ok && nodeChange.after == n {
+		return
+	}
+	releaseNode(n)
+}
+
 // insert a key/value pair into the correct node of the trie.
 // Must not be called after [calculateNodeIDs] has returned.
 func (t *trieView) insert(
@@ -857,11 +1248,14 @@ func (t *trieView) insert(
 	// add the existing child onto the branch node
 	branchNode.setChildEntry(
 		existingChildEntry.compressedKey.Token(commonPrefixLength, t.tokenSize),
-		&child{
-			compressedKey: existingChildEntry.compressedKey.Skip(commonPrefixLength + t.tokenSize),
-			id:            existingChildEntry.id,
-			hasValue:      existingChildEntry.hasValue,
-		})
+		newChild(
+			existingChildEntry.compressedKey.Skip(commonPrefixLength+t.tokenSize),
+			existingChildEntry.id,
+			existingChildEntry.hasValue,
+		))
+	// [existingChildEntry] was replaced by the entry of [branchNode] in
+	// [closestNode].
+	releaseChild(existingChildEntry)
 
 	return nodeWithValue, t.recordNewNode(branchNode)
 }
@@ -952,6 +1346,9 @@ func (t *trieView) recordValueChange(key Key, value maybe.Maybe[[]byte]) error {
 
 	// update the existing change if it exists
 	if existing, ok := t.changes.values[key]; ok {
+		if err := t.recordQuotaUsage(key, existing.after, value); err != nil {
+			return err
+		}
 		existing.after = value
 		return nil
 	}
@@ -967,6 +1364,9 @@ func (t *trieView) recordValueChange(key Key, value maybe.Maybe[[]byte]) error {
 	default:
 		return err
 	}
+	if err := t.recordQuotaUsage(key, beforeMaybe, value); err != nil {
+		return err
+	}
 
 	t.changes.values[key] = &change[maybe.Maybe[[]byte]]{
 		before: beforeMaybe,
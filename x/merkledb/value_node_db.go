@@ -26,6 +26,13 @@ type valueNodeDB struct {
 	// Paths in [nodeCache] aren't prefixed with [valueNodePrefix].
 	nodeCache cache.Cacher[Key, *node]
 	metrics   merkleMetrics
+	// If true, nodes written to [baseDB] are followed by their checksum,
+	// which is verified when they're read.
+	checksums bool
+	// If non-nil, values longer than [blobValueThreshold] are stored in
+	// [blobStore] and the nodes written to [baseDB] only reference them.
+	blobStore          database.KeyValueReaderWriter
+	blobValueThreshold int
 
 	closed utils.Atomic[bool]
 }
@@ -35,12 +42,18 @@ func newValueNodeDB(
 	bufferPool *sync.Pool,
 	metrics merkleMetrics,
 	cacheSize int,
+	checksums bool,
+	blobStore database.KeyValueReaderWriter,
+	blobValueThreshold int,
 ) *valueNodeDB {
 	return &valueNodeDB{
-		metrics:    metrics,
-		baseDB:     db,
-		bufferPool: bufferPool,
-		nodeCache:  cache.NewSizedLRU(cacheSize, cacheEntrySize),
+		metrics:            metrics,
+		baseDB:             db,
+		bufferPool:         bufferPool,
+		nodeCache:          cache.NewSizedLRU(cacheSize, cacheEntrySize),
+		checksums:          checksums,
+		blobStore:          blobStore,
+		blobValueThreshold: blobValueThreshold,
 	}
 }
 
@@ -67,6 +80,8 @@ func (db *valueNodeDB) NewBatch() *valueNodeBatch {
 	}
 }
 
+// Get returns the node with the given [key].
+// [key] isn't retained, so it may reference a temporary buffer.
 func (db *valueNodeDB) Get(key Key) (*node, error) {
 	if cachedValue, isCached := db.nodeCache.Get(key); isCached {
 		db.metrics.ValueNodeCacheHit()
@@ -86,7 +101,47 @@ func (db *valueNodeDB) Get(key Key) (*node, error) {
 		return nil, err
 	}
 
-	return parseNode(key, nodeBytes)
+	// [key] may reference a temporary buffer, but it's retained by the parsed
+	// node.
+	return db.parseNode(key.clone(), nodeBytes)
+}
+
+// Returns the bytes [n] is written to [db.baseDB] as.
+// If the value of [n] is stored as a blob, the blob is written.
+func (db *valueNodeDB) nodeBytes(n *node) ([]byte, error) {
+	nodeBytes := n.bytes()
+	if isBlobValue(n, db.blobStore, db.blobValueThreshold) {
+		var err error
+		nodeBytes, err = putBlobValue(n, db.blobStore)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if db.checksums {
+		return appendChecksum(nodeBytes), nil
+	}
+	return nodeBytes, nil
+}
+
+// Parses [nodeBytes], as read from [db.baseDB], to a node.
+func (db *valueNodeDB) parseNode(key Key, nodeBytes []byte) (*node, error) {
+	if db.checksums {
+		var err error
+		nodeBytes, err = verifyChecksum(nodeBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	n, err := parseNode(key, nodeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if dbNodeVersion(nodeBytes) == blobValueDBNodeVersion {
+		if err := resolveBlobValue(n, db.blobStore); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
 }
 
 func (db *valueNodeDB) Clear() error {
@@ -111,22 +166,43 @@ func (b *valueNodeBatch) Delete(key Key) {
 // Write flushes any accumulated data to the underlying database.
 func (b *valueNodeBatch) Write() error {
 	dbBatch := b.db.baseDB.NewBatch()
+	if err := b.writeTo(dbBatch); err != nil {
+		return err
+	}
+	return dbBatch.Write()
+}
+
+// writeTo adds the accumulated data to [dbBatch], which must be a batch of
+// the underlying database, so that it's written atomically with the other
+// operations in [dbBatch].
+func (b *valueNodeBatch) writeTo(dbBatch database.Batch) error {
 	for key, n := range b.ops {
 		b.db.metrics.DatabaseNodeWrite()
-		b.db.nodeCache.Put(key, n)
+		if isBlobValue(n, b.db.blobStore, b.db.blobValueThreshold) {
+			// Large values are kept out of the cache so that they don't
+			// evict many small nodes.
+			b.db.nodeCache.Evict(key)
+		} else {
+			b.db.nodeCache.Put(key, n)
+		}
 		prefixedKey := addPrefixToKey(b.db.bufferPool, valueNodePrefix, key.Bytes())
 		if n == nil {
 			if err := dbBatch.Delete(prefixedKey); err != nil {
 				return err
 			}
-		} else if err := dbBatch.Put(prefixedKey, n.bytes()); err != nil {
-			return err
+		} else {
+			nodeBytes, err := b.db.nodeBytes(n)
+			if err != nil {
+				return err
+			}
+			if err := dbBatch.Put(prefixedKey, nodeBytes); err != nil {
+				return err
+			}
 		}
 
 		b.db.bufferPool.Put(prefixedKey)
 	}
-
-	return dbBatch.Write()
+	return nil
 }
 
 type iterator struct {
@@ -172,7 +248,7 @@ func (i *iterator) Next() bool {
 	i.db.metrics.DatabaseNodeRead()
 	key := i.nodeIter.Key()
 	key = key[valueNodePrefixLen:]
-	n, err := parseNode(ToKey(key), i.nodeIter.Value())
+	n, err := i.db.parseNode(ToKey(key), i.nodeIter.Value())
 	if err != nil {
 		i.err = err
 		return false
@@ -20,7 +20,7 @@ func TestValueNodeDB(t *testing.T) {
 
 	baseDB := memdb.New()
 
-	size := 10
+	size := 20
 	db := newValueNodeDB(
 		baseDB,
 		&sync.Pool{
@@ -28,6 +28,9 @@ func TestValueNodeDB(t *testing.T) {
 		},
 		&mockMetrics{},
 		size,
+		false,
+		nil,
+		0,
 	)
 
 	// Getting a key that doesn't exist should return an error.
@@ -123,6 +126,9 @@ func TestValueNodeDBIterator(t *testing.T) {
 		},
 		&mockMetrics{},
 		cacheSize,
+		false,
+		nil,
+		0,
 	)
 
 	// Put key-node pairs.
@@ -230,6 +236,9 @@ func TestValueNodeDBClear(t *testing.T) {
 		},
 		&mockMetrics{},
 		cacheSize,
+		false,
+		nil,
+		0,
 	)
 
 	batch := db.NewBatch()
@@ -52,6 +52,10 @@ func (t *trieView) NewIteratorWithStartAndPrefix(start, prefix []byte) database.
 	}
 }
 
+func (t *trieView) NewProofIteratorWithStartAndPrefix(start, prefix []byte) ProofIterator {
+	return newProofIterator(t.NewIteratorWithStartAndPrefix(start, prefix), t)
+}
+
 // viewIterator walks over both the in memory database and the underlying database
 // at the same time.
 type viewIterator struct {
@@ -77,10 +81,10 @@ func (it *viewIterator) Next() bool {
 		it.value = nil
 		it.err = database.ErrClosed
 		return false
-	case it.view.invalidated:
+	case it.view.isInvalid():
 		it.key = nil
 		it.value = nil
-		it.err = ErrInvalid
+		it.err = it.view.checkValid()
 		return false
 	case !it.initialized:
 		it.parentIterExhausted = !it.parentIter.Next()
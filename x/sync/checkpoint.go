@@ -0,0 +1,248 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
+)
+
+const defaultCheckpointInterval = 30 * time.Second
+
+var (
+	checkpointKey = []byte("syncCheckpoint")
+
+	ErrInvalidCheckpoint = errors.New("invalid sync checkpoint")
+)
+
+// Checkpoint is the progress of a sync, which allows a sync to be resumed
+// after a restart rather than starting from scratch.
+type Checkpoint struct {
+	// The root that was being synced to when the checkpoint was written.
+	TargetRoot ids.ID
+	// Key ranges whose key-value pairs are in the local database, in order of
+	// increasing start. Ranges don't overlap, except that a range may start
+	// at the end of the previous range.
+	CompletedRanges []CompletedRange
+}
+
+// CompletedRange records that the local database contains all the key-value
+// pairs in the range [Start, End] of the trie with root [RootID].
+// A Nothing [Start] means there is no lower bound.
+// A Nothing [End] means there is no upper bound.
+type CompletedRange struct {
+	Start  maybe.Maybe[[]byte]
+	End    maybe.Maybe[[]byte]
+	RootID ids.ID
+}
+
+// RestoreCheckpoint returns the checkpoint that a Manager wrote to [db].
+// Returns [database.ErrNotFound] if there is no checkpoint, which is the case
+// if the sync that wrote checkpoints to [db] completed.
+func RestoreCheckpoint(db database.KeyValueReader) (*Checkpoint, error) {
+	checkpointBytes, err := db.Get(checkpointKey)
+	if err != nil {
+		return nil, err
+	}
+	return parseCheckpoint(checkpointBytes)
+}
+
+func (c *Checkpoint) bytes() []byte {
+	p := wrappers.Packer{
+		MaxSize: math.MaxInt32,
+	}
+	p.PackFixedBytes(c.TargetRoot[:])
+	p.PackInt(uint32(len(c.CompletedRanges)))
+	for _, r := range c.CompletedRanges {
+		packMaybeBytes(&p, r.Start)
+		packMaybeBytes(&p, r.End)
+		p.PackFixedBytes(r.RootID[:])
+	}
+	return p.Bytes
+}
+
+func parseCheckpoint(checkpointBytes []byte) (*Checkpoint, error) {
+	p := wrappers.Packer{
+		Bytes: checkpointBytes,
+	}
+	checkpoint := &Checkpoint{}
+	copy(checkpoint.TargetRoot[:], p.UnpackFixedBytes(ids.IDLen))
+	numRanges := p.UnpackInt()
+	if p.Errored() {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCheckpoint, p.Err)
+	}
+
+	for i := uint32(0); i < numRanges; i++ {
+		r := CompletedRange{
+			Start: unpackMaybeBytes(&p),
+			End:   unpackMaybeBytes(&p),
+		}
+		copy(r.RootID[:], p.UnpackFixedBytes(ids.IDLen))
+		if p.Errored() {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCheckpoint, p.Err)
+		}
+		checkpoint.CompletedRanges = append(checkpoint.CompletedRanges, r)
+	}
+	if p.Offset != len(checkpointBytes) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidCheckpoint, len(checkpointBytes)-p.Offset)
+	}
+	if err := checkpoint.verify(); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// verify returns nil iff the ranges in [c] are sorted and don't overlap.
+func (c *Checkpoint) verify() error {
+	for i, r := range c.CompletedRanges {
+		if r.Start.HasValue() && r.End.HasValue() && bytes.Compare(r.Start.Value(), r.End.Value()) > 0 {
+			return fmt.Errorf("%w: range %d starts after it ends", ErrInvalidCheckpoint, i)
+		}
+		if i == 0 {
+			continue
+		}
+		prevEnd := c.CompletedRanges[i-1].End
+		if prevEnd.IsNothing() || r.Start.IsNothing() || bytes.Compare(prevEnd.Value(), r.Start.Value()) > 0 {
+			return fmt.Errorf("%w: range %d overlaps the previous range", ErrInvalidCheckpoint, i)
+		}
+	}
+	return nil
+}
+
+func packMaybeBytes(p *wrappers.Packer, b maybe.Maybe[[]byte]) {
+	p.PackBool(b.HasValue())
+	if b.HasValue() {
+		p.PackBytes(b.Value())
+	}
+}
+
+func unpackMaybeBytes(p *wrappers.Packer) maybe.Maybe[[]byte] {
+	if !p.UnpackBool() {
+		return maybe.Nothing[[]byte]()
+	}
+	return maybe.Some(p.UnpackBytes())
+}
+
+// checkpoint returns the current progress of the sync.
+// Assumes [m.syncTargetLock] and [m.workLock] are held.
+func (m *Manager) checkpoint() *Checkpoint {
+	// Unprocessed items with a local root were completed for a previous
+	// target root. Their key-value pairs are still in the local database.
+	items := m.processedWork.Items()
+	for _, item := range m.unprocessedWork.Items() {
+		if item.localRootID != ids.Empty {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a, b *workItem) bool {
+		if a.start.IsNothing() || b.start.IsNothing() {
+			return a.start.IsNothing() && b.start.HasValue()
+		}
+		return bytes.Compare(a.start.Value(), b.start.Value()) < 0
+	})
+
+	checkpoint := &Checkpoint{
+		TargetRoot:      m.config.TargetRoot,
+		CompletedRanges: make([]CompletedRange, len(items)),
+	}
+	for i, item := range items {
+		checkpoint.CompletedRanges[i] = CompletedRange{
+			Start:  item.start,
+			End:    item.end,
+			RootID: item.localRootID,
+		}
+	}
+	return checkpoint
+}
+
+// restoreCheckpoint adds the work needed to resume the sync from [checkpoint].
+// Ranges completed for the current target root don't need to be fetched
+// again. Ranges completed for other roots are updated with change proofs.
+// All other ranges are fetched with range proofs.
+// Assumes [m.workLock] is held.
+func (m *Manager) restoreCheckpoint(checkpoint *Checkpoint) {
+	// Start of the range that hasn't been checked for gaps yet.
+	gapStart := maybe.Nothing[[]byte]()
+	for i, r := range checkpoint.CompletedRanges {
+		if i > 0 || r.Start.HasValue() {
+			if !maybe.Equal(gapStart, r.Start, bytes.Equal) {
+				m.insertPrioritizedWork(newWorkItem(ids.Empty, gapStart, r.Start, lowPriority))
+			}
+		}
+		gapStart = r.End
+
+		if r.RootID == m.config.TargetRoot {
+			m.processedWork.MergeInsert(newWorkItem(r.RootID, r.Start, r.End, lowPriority))
+		} else {
+			m.unprocessedWork.Insert(newWorkItem(r.RootID, r.Start, r.End, highPriority))
+		}
+	}
+
+	numRanges := len(checkpoint.CompletedRanges)
+	if numRanges == 0 || checkpoint.CompletedRanges[numRanges-1].End.HasValue() {
+		m.insertPrioritizedWork(newWorkItem(ids.Empty, gapStart, maybe.Nothing[[]byte](), lowPriority))
+	}
+
+	m.config.Log.Info("restored sync checkpoint",
+		zap.Stringer("checkpointTargetRoot", checkpoint.TargetRoot),
+		zap.Int("numCompletedRanges", numRanges),
+	)
+}
+
+// writeCheckpoints periodically writes the progress of the sync to
+// [m.config.CheckpointDB] until the sync stops. Once the sync completes, the
+// checkpoint is deleted.
+func (m *Manager) writeCheckpoints() {
+	ticker := time.NewTicker(m.config.CheckpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.writeCheckpoint(); err != nil {
+				m.config.Log.Warn("failed to write sync checkpoint", zap.Error(err))
+			}
+		case <-m.doneChan:
+			var err error
+			if m.isCompleted() {
+				err = m.config.CheckpointDB.Delete(checkpointKey)
+			} else {
+				err = m.writeCheckpoint()
+			}
+			if err != nil {
+				m.config.Log.Warn("failed to update sync checkpoint", zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+func (m *Manager) writeCheckpoint() error {
+	m.syncTargetLock.RLock()
+	m.workLock.Lock()
+	checkpoint := m.checkpoint()
+	m.workLock.Unlock()
+	m.syncTargetLock.RUnlock()
+
+	return m.config.CheckpointDB.Put(checkpointKey, checkpoint.bytes())
+}
+
+func (m *Manager) isCompleted() bool {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	return m.completed
+}
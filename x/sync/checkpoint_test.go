@@ -0,0 +1,213 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+func TestCheckpointBytes(t *testing.T) {
+	tests := []struct {
+		name        string
+		checkpoint  *Checkpoint
+		expectedErr error
+	}{
+		{
+			name: "no ranges",
+			checkpoint: &Checkpoint{
+				TargetRoot: ids.GenerateTestID(),
+			},
+		},
+		{
+			name: "adjacent ranges",
+			checkpoint: &Checkpoint{
+				TargetRoot: ids.GenerateTestID(),
+				CompletedRanges: []CompletedRange{
+					{
+						Start:  maybe.Nothing[[]byte](),
+						End:    maybe.Some([]byte{1}),
+						RootID: ids.GenerateTestID(),
+					},
+					{
+						Start:  maybe.Some([]byte{1}),
+						End:    maybe.Some([]byte{2}),
+						RootID: ids.GenerateTestID(),
+					},
+					{
+						Start:  maybe.Some([]byte{3}),
+						End:    maybe.Nothing[[]byte](),
+						RootID: ids.GenerateTestID(),
+					},
+				},
+			},
+		},
+		{
+			name: "overlapping ranges",
+			checkpoint: &Checkpoint{
+				CompletedRanges: []CompletedRange{
+					{
+						Start: maybe.Some([]byte{1}),
+						End:   maybe.Some([]byte{3}),
+					},
+					{
+						Start: maybe.Some([]byte{2}),
+						End:   maybe.Some([]byte{4}),
+					},
+				},
+			},
+			expectedErr: ErrInvalidCheckpoint,
+		},
+		{
+			name: "range starts after it ends",
+			checkpoint: &Checkpoint{
+				CompletedRanges: []CompletedRange{
+					{
+						Start: maybe.Some([]byte{2}),
+						End:   maybe.Some([]byte{1}),
+					},
+				},
+			},
+			expectedErr: ErrInvalidCheckpoint,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db := memdb.New()
+			require.NoError(db.Put(checkpointKey, tt.checkpoint.bytes()))
+
+			checkpoint, err := RestoreCheckpoint(db)
+			require.ErrorIs(err, tt.expectedErr)
+			if tt.expectedErr == nil {
+				require.Equal(tt.checkpoint, checkpoint)
+			}
+		})
+	}
+}
+
+func TestRestoreCheckpointTruncated(t *testing.T) {
+	require := require.New(t)
+
+	checkpoint := &Checkpoint{
+		TargetRoot: ids.GenerateTestID(),
+		CompletedRanges: []CompletedRange{
+			{
+				Start: maybe.Some([]byte{1}),
+				End:   maybe.Some([]byte{2}),
+			},
+		},
+	}
+	checkpointBytes := checkpoint.bytes()
+
+	db := memdb.New()
+	require.NoError(db.Put(checkpointKey, checkpointBytes[:len(checkpointBytes)-1]))
+	_, err := RestoreCheckpoint(db)
+	require.ErrorIs(err, ErrInvalidCheckpoint)
+
+	require.NoError(db.Delete(checkpointKey))
+	_, err = RestoreCheckpoint(db)
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestSyncResumesFromCheckpoint(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+	checkpointDB := memdb.New()
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newCallthroughSyncClient(ctrl, dbToSync),
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		CheckpointDB:          checkpointDB,
+	})
+	require.NoError(err)
+	require.NoError(syncer.Start(context.Background()))
+
+	// Wait until we've processed some work before stopping the sync.
+	require.Eventually(
+		func() bool {
+			syncer.workLock.Lock()
+			defer syncer.workLock.Unlock()
+
+			return syncer.processedWork.Len() > 0
+		},
+		5*time.Second,
+		5*time.Millisecond,
+	)
+	syncer.Close()
+
+	// The checkpoint is written once the sync stops.
+	var checkpoint *Checkpoint
+	require.Eventually(
+		func() bool {
+			checkpoint, err = RestoreCheckpoint(checkpointDB)
+			return err == nil
+		},
+		5*time.Second,
+		5*time.Millisecond,
+	)
+	require.Equal(syncRoot, checkpoint.TargetRoot)
+	require.NotEmpty(checkpoint.CompletedRanges)
+
+	newSyncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newCallthroughSyncClient(ctrl, dbToSync),
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		CheckpointDB:          checkpointDB,
+		Checkpoint:            checkpoint,
+	})
+	require.NoError(err)
+	require.NoError(newSyncer.Start(context.Background()))
+	require.NoError(newSyncer.Wait(context.Background()))
+
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(syncRoot, newRoot)
+
+	// The checkpoint is deleted once the sync completes.
+	require.Eventually(
+		func() bool {
+			_, err := RestoreCheckpoint(checkpointDB)
+			return err == database.ErrNotFound
+		},
+		5*time.Second,
+		5*time.Millisecond,
+	)
+}
@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -16,6 +15,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 	"github.com/ava-labs/avalanchego/version"
@@ -67,35 +67,63 @@ type Client interface {
 }
 
 type client struct {
-	networkClient       NetworkClient
-	stateSyncNodes      []ids.NodeID
-	stateSyncNodeIdx    uint32
-	stateSyncMinVersion *version.Application
-	log                 logging.Logger
-	metrics             SyncMetrics
-	tokenSize           int
+	transport Transport
+	log       logging.Logger
+	metrics   SyncMetrics
+	tokenSize int
+	verifier  *verifier
+	// May be nil.
+	validators validators.Manager
+	subnetID   ids.ID
 }
 
 type ClientConfig struct {
+	// Used to send requests to peers if [Transport] is nil.
 	NetworkClient       NetworkClient
 	StateSyncNodeIDs    []ids.NodeID
 	StateSyncMinVersion *version.Application
-	Log                 logging.Logger
-	Metrics             SyncMetrics
-	BranchFactor        merkledb.BranchFactor
+	// If non-nil, requests are sent using [Transport] instead of
+	// [NetworkClient], and [StateSyncNodeIDs] and [StateSyncMinVersion]
+	// are ignored.
+	Transport    Transport
+	Log          logging.Logger
+	Metrics      SyncMetrics
+	BranchFactor merkledb.BranchFactor
+	// The maximum number of responses that are parsed and verified at once.
+	// Responses are verified on a bounded number of workers so that bursts
+	// of proofs don't starve other work, such as consensus message handling,
+	// of CPU. Defaults to half the number of CPUs, and at least 1.
+	VerificationWorkers int
+	// If non-nil, the metrics of each response are labeled with the stake
+	// tier of the responding peer in [SubnetID].
+	Validators validators.Manager
+	SubnetID   ids.ID
 }
 
 func NewClient(config *ClientConfig) (Client, error) {
 	if err := config.BranchFactor.Valid(); err != nil {
 		return nil, err
 	}
+	transport := config.Transport
+	if transport == nil {
+		transport = &p2pTransport{
+			networkClient:       config.NetworkClient,
+			stateSyncNodes:      config.StateSyncNodeIDs,
+			stateSyncMinVersion: config.StateSyncMinVersion,
+		}
+	}
+	verificationWorkers := config.VerificationWorkers
+	if verificationWorkers <= 0 {
+		verificationWorkers = defaultVerificationWorkers()
+	}
 	return &client{
-		networkClient:       config.NetworkClient,
-		stateSyncNodes:      config.StateSyncNodeIDs,
-		stateSyncMinVersion: config.StateSyncMinVersion,
-		log:                 config.Log,
-		metrics:             config.Metrics,
-		tokenSize:           merkledb.BranchFactorToTokenSize[config.BranchFactor],
+		transport:  transport,
+		log:        config.Log,
+		metrics:    config.Metrics,
+		tokenSize:  merkledb.BranchFactorToTokenSize[config.BranchFactor],
+		verifier:   newVerifier(verificationWorkers),
+		validators: config.Validators,
+		subnetID:   config.SubnetID,
 	}, nil
 }
 
@@ -289,7 +317,8 @@ func (c *client) GetRangeProof(
 
 // getAndParse uses [client] to send [request] to an arbitrary peer.
 // Returns the response to the request.
-// [parseFn] parses the raw response.
+// [parseFn] parses the raw response. At most [ClientConfig.VerificationWorkers]
+// responses are parsed at once.
 // If the request is unsuccessful or the response can't be parsed,
 // retries the request to a different peer until [ctx] expires.
 // Returns [errAppSendFailed] if we fail to send an AppRequest/AppResponse.
@@ -308,9 +337,16 @@ func getAndParse[T any](
 	for attempt := 1; ; attempt++ {
 		nodeID, responseBytes, err := client.get(ctx, request)
 		if err == nil {
-			if response, err = parseFn(ctx, responseBytes); err == nil {
+			response, err = verify(ctx, client.verifier, func(ctx context.Context) (*T, error) {
+				return parseFn(ctx, responseBytes)
+			})
+			client.transport.TrackResponse(nodeID, err)
+			if err == nil {
 				return response, nil
 			}
+			if err != ctx.Err() {
+				client.metrics.VerificationFailed(client.stakeTier(nodeID))
+			}
 		}
 
 		if errors.Is(err, errAppSendFailed) {
@@ -328,6 +364,8 @@ func getAndParse[T any](
 			lastErr = err
 		}
 
+		client.metrics.RequestRetried(client.stakeTier(nodeID))
+
 		retryWait := initialRetryWait * time.Duration(math.Pow(retryWaitFactor, float64(attempt)))
 		if retryWait > maxRetryWait || retryWait < 0 { // Handle overflows with negative check.
 			retryWait = maxRetryWait
@@ -348,37 +386,28 @@ func getAndParse[T any](
 	}
 }
 
-// get sends [request] to an arbitrary peer and blocks
+// get sends [request] using [c.transport] and blocks
 // until the node receives a response, failure notification
 // or [ctx] is canceled.
-// Returns the peer's NodeID and response.
+// Returns the responder's NodeID and response.
 // Returns [errAppSendFailed] if we failed to send an AppRequest/AppResponse.
 // This should be treated as fatal.
 // It's safe to call this method multiple times concurrently.
 func (c *client) get(ctx context.Context, request []byte) (ids.NodeID, []byte, error) {
-	var (
-		response []byte
-		nodeID   ids.NodeID
-		err      error
-	)
-
 	c.metrics.RequestMade()
 
-	if len(c.stateSyncNodes) == 0 {
-		nodeID, response, err = c.networkClient.RequestAny(ctx, c.stateSyncMinVersion, request)
-	} else {
-		// Get the next nodeID to query using the [nodeIdx] offset.
-		// If we're out of nodes, loop back to 0.
-		// We do this try to query a different node each time if possible.
-		nodeIdx := atomic.AddUint32(&c.stateSyncNodeIdx, 1)
-		nodeID = c.stateSyncNodes[nodeIdx%uint32(len(c.stateSyncNodes))]
-		response, err = c.networkClient.Request(ctx, nodeID, request)
-	}
+	start := time.Now()
+	nodeID, response, err := c.transport.Send(ctx, request)
 	if err != nil {
 		c.metrics.RequestFailed()
 		return nodeID, response, err
 	}
 
 	c.metrics.RequestSucceeded()
+	c.metrics.ResponseReceived(c.stakeTier(nodeID), time.Since(start), len(response))
 	return nodeID, response, nil
 }
+
+func (c *client) stakeTier(nodeID ids.NodeID) string {
+	return stakeTier(c.validators, c.subnetID, nodeID)
+}
@@ -124,6 +124,7 @@ func sendRangeProofRequest(
 
 	// Handle bandwidth tracking calls from client.
 	networkClient.EXPECT().TrackBandwidth(gomock.Any(), gomock.Any()).AnyTimes()
+	networkClient.EXPECT().TrackResponse(gomock.Any(), gomock.Any()).AnyTimes()
 
 	// The server should expect to "send" a response to the client.
 	sender.EXPECT().SendAppResponse(
@@ -274,7 +275,7 @@ func TestGetRangeProof(t *testing.T) {
 				start := maybe.Some(response.KeyValues[1].Key)
 				rootID, err := largeTrieDB.GetMerkleRoot(context.Background())
 				require.NoError(t, err)
-				proof, err := largeTrieDB.GetRangeProofAtRoot(context.Background(), rootID, start, maybe.Nothing[[]byte](), defaultRequestKeyLimit)
+				proof, err := largeTrieDB.GetRangeProofAtRoot(context.Background(), rootID, start, maybe.Nothing[[]byte](), defaultRequestKeyLimit, 0)
 				require.NoError(t, err)
 				response.KeyValues = proof.KeyValues
 				response.StartProof = proof.StartProof
@@ -441,6 +442,8 @@ func sendChangeProofRequest(
 		},
 	).AnyTimes()
 
+	networkClient.EXPECT().TrackResponse(gomock.Any(), gomock.Any()).AnyTimes()
+
 	// Expect server (serverDB) to send app response to client (clientDB)
 	sender.EXPECT().SendAppResponse(
 		gomock.Any(), // ctx
@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// Once the synced root is at most this many blocks behind the tip, the sync
+// stops following the tip and completes.
+const maxBlocksBehindTip = 1
+
+// FollowTipFunc returns the root of the tip of the chain being synced, and the
+// number of blocks that [syncedRoot] is behind the tip.
+type FollowTipFunc func(ctx context.Context, syncedRoot ids.ID) (tipRoot ids.ID, blocksBehind uint64, err error)
+
+// followTip is called once the database is synced to the target root. If the
+// target root is more than [maxBlocksBehindTip] blocks behind the tip returned
+// by [m.config.FollowTip], the target root is updated to the tip, so that the
+// changes since the target root are fetched with change proofs.
+//
+// Returns true if the target root was updated.
+// Assumes [m.workLock] isn't held.
+func (m *Manager) followTip(ctx context.Context) bool {
+	syncedRoot := m.getTargetRoot()
+	tipRoot, blocksBehind, err := m.config.FollowTip(ctx, syncedRoot)
+	if err != nil {
+		if ctx.Err() == nil {
+			m.config.Log.Warn("failed to get tip, not following it",
+				zap.Stringer("syncedRoot", syncedRoot),
+				zap.Error(err),
+			)
+		}
+		return false
+	}
+
+	if tipRoot == syncedRoot || blocksBehind <= maxBlocksBehindTip {
+		m.config.Log.Info("caught up to tip",
+			zap.Stringer("syncedRoot", syncedRoot),
+			zap.Stringer("tipRoot", tipRoot),
+			zap.Uint64("blocksBehind", blocksBehind),
+		)
+		return false
+	}
+
+	m.config.Log.Info("following tip",
+		zap.Stringer("syncedRoot", syncedRoot),
+		zap.Stringer("tipRoot", tipRoot),
+		zap.Uint64("blocksBehind", blocksBehind),
+	)
+	return m.UpdateSyncTarget(tipRoot) == nil
+}
@@ -0,0 +1,202 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+
+	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
+)
+
+// Returns the roots of [dbToSync] after each of 3 blocks are written to it,
+// starting with its current root.
+func writeFollowTipBlocks(t *testing.T, r *rand.Rand, dbToSync merkledb.MerkleDB) []ids.ID {
+	require := require.New(t)
+
+	roots := make([]ids.ID, 4)
+	for i := range roots {
+		if i > 0 {
+			for j := 0; j < 10; j++ {
+				key := make([]byte, r.Intn(50))
+				_, err := r.Read(key)
+				require.NoError(err)
+
+				val := make([]byte, r.Intn(50))
+				_, err = r.Read(val)
+				require.NoError(err)
+
+				require.NoError(dbToSync.Put(key, val))
+			}
+		}
+		var err error
+		roots[i], err = dbToSync.GetMerkleRoot(context.Background())
+		require.NoError(err)
+	}
+	return roots
+}
+
+// Returns a client that serves proofs from [dbToSync].
+func newFollowTipTestClient(t *testing.T, ctrl *gomock.Controller, dbToSync merkledb.MerkleDB) *MockClient {
+	require := require.New(t)
+
+	client := NewMockClient(ctrl)
+	client.EXPECT().GetRangeProof(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetRangeProofRequest) (*merkledb.RangeProof, error) {
+			root, err := ids.ToID(request.RootHash)
+			require.NoError(err)
+			return dbToSync.GetRangeProofAtRoot(ctx, root, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit), 0)
+		},
+	).AnyTimes()
+	client.EXPECT().GetChangeProof(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetChangeProofRequest, _ DB) (*merkledb.ChangeOrRangeProof, error) {
+			startRoot, err := ids.ToID(request.StartRootHash)
+			require.NoError(err)
+
+			endRoot, err := ids.ToID(request.EndRootHash)
+			require.NoError(err)
+
+			changeProof, err := dbToSync.GetChangeProof(ctx, startRoot, endRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit))
+			if err != nil {
+				return nil, err
+			}
+			return &merkledb.ChangeOrRangeProof{
+				ChangeProof: changeProof,
+			}, nil
+		},
+	).AnyTimes()
+	return client
+}
+
+func TestSyncFollowTip(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+
+	// Each root is the state after a block, and [roots] ends at the tip.
+	roots := writeFollowTipBlocks(t, r, dbToSync)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+
+	client := newFollowTipTestClient(t, ctrl, dbToSync)
+
+	// The first call reports that the tip is [roots[2]], and later calls
+	// report that the tip is [roots[3]].
+	var syncedRoots []ids.ID
+	followTip := func(_ context.Context, syncedRoot ids.ID) (ids.ID, uint64, error) {
+		syncedRoots = append(syncedRoots, syncedRoot)
+		tip := len(roots) - 1
+		if len(syncedRoots) == 1 {
+			tip--
+		}
+		for i, root := range roots {
+			if root == syncedRoot {
+				return roots[tip], uint64(tip - i), nil
+			}
+		}
+		return ids.Empty, 0, database.ErrNotFound
+	}
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            roots[0],
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		FollowTip:             followTip,
+	})
+	require.NoError(err)
+
+	require.NoError(syncer.Start(context.Background()))
+	require.NoError(syncer.Wait(context.Background()))
+
+	// The sync follows the tip from [roots[0]] to [roots[2]], and then stops
+	// because [roots[2]] is one block behind the tip.
+	require.Equal([]ids.ID{roots[0], roots[2]}, syncedRoots)
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(roots[2], root)
+}
+
+func TestSyncFollowTipTargetUpdatedConcurrently(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	roots := writeFollowTipBlocks(t, r, dbToSync)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+
+	// The first call updates the target while the tip is being followed and
+	// reports that the sync is caught up, so the sync must not complete
+	// before it syncs to the updated target.
+	var (
+		syncer      *Manager
+		syncedRoots []ids.ID
+	)
+	followTip := func(_ context.Context, syncedRoot ids.ID) (ids.ID, uint64, error) {
+		syncedRoots = append(syncedRoots, syncedRoot)
+		if len(syncedRoots) == 1 {
+			if err := syncer.UpdateSyncTarget(roots[1]); err != nil {
+				return ids.Empty, 0, err
+			}
+		}
+		return syncedRoot, 0, nil
+	}
+
+	syncer, err = NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newFollowTipTestClient(t, ctrl, dbToSync),
+		TargetRoot:            roots[0],
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		FollowTip:             followTip,
+	})
+	require.NoError(err)
+
+	require.NoError(syncer.Start(context.Background()))
+	require.NoError(syncer.Wait(context.Background()))
+
+	require.Equal([]ids.ID{roots[0], roots[1]}, syncedRoots)
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(roots[1], root)
+}
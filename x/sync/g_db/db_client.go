@@ -6,6 +6,7 @@ package gdb
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"google.golang.org/protobuf/types/known/emptypb"
 
@@ -132,7 +133,15 @@ func (c *DBClient) GetRangeProofAtRoot(
 	startKey maybe.Maybe[[]byte],
 	endKey maybe.Maybe[[]byte],
 	keyLimit int,
+	bytesLimit int,
 ) (*merkledb.RangeProof, error) {
+	if keyLimit <= 0 {
+		return nil, fmt.Errorf("%w but was %d", merkledb.ErrInvalidMaxLength, keyLimit)
+	}
+	if bytesLimit < 0 {
+		return nil, fmt.Errorf("%w but was %d", merkledb.ErrInvalidMaxBytes, bytesLimit)
+	}
+
 	resp, err := c.client.GetRangeProof(ctx, &pb.GetRangeProofRequest{
 		RootHash: rootID[:],
 		StartKey: &pb.MaybeBytes{
@@ -143,7 +152,8 @@ func (c *DBClient) GetRangeProofAtRoot(
 			IsNothing: endKey.IsNothing(),
 			Value:     endKey.Value(),
 		},
-		KeyLimit: uint32(keyLimit),
+		KeyLimit:   uint32(keyLimit),
+		BytesLimit: uint32(bytesLimit),
 	})
 	if err != nil {
 		return nil, err
@@ -166,7 +166,7 @@ func (s *DBServer) GetRangeProof(
 	if req.EndKey != nil && !req.EndKey.IsNothing {
 		end = maybe.Some(req.EndKey.Value)
 	}
-	proof, err := s.db.GetRangeProofAtRoot(ctx, rootID, start, end, int(req.KeyLimit))
+	proof, err := s.db.GetRangeProofAtRoot(ctx, rootID, start, end, int(req.KeyLimit), int(req.BytesLimit))
 	if err != nil {
 		return nil, err
 	}
@@ -9,12 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"golang.org/x/exp/maps"
 
 	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/maybe"
@@ -41,11 +43,13 @@ var (
 
 type priority byte
 
-// Note that [highPriority] > [medPriority] > [lowPriority].
+// Note that [prioritizedPriority] > [highPriority] > [medPriority] > [lowPriority].
 const (
 	lowPriority priority = iota + 1
 	medPriority
 	highPriority
+	// Given to ranges with the prefixes in [ManagerConfig.PrioritizedPrefixes].
+	prioritizedPriority
 )
 
 // Signifies that we should sync the range [start, end].
@@ -105,18 +109,83 @@ type Manager struct {
 	cancelCtx context.CancelFunc
 
 	// Set to true when StartSyncing is called.
-	syncing   bool
+	syncing bool
+	// Set to true when there is no more work to do.
+	// [workLock] must be held when accessing [completed].
+	completed bool
+	// Set to true while no new work items should be started.
+	// [workLock] must be held when accessing [paused].
+	paused    bool
 	closeOnce sync.Once
 	tokenSize int
+	// Adjusts the number of work items processed at once.
+	workLimiter *workLimiter
+
+	// When [Start] was called.
+	startTime time.Time
+	// Fraction of the keyspace that was completed when [Start] was called.
+	completedAtStart float64
+	// Number of bytes of keys and values received in proofs.
+	// Must be accessed atomically.
+	bytesDownloaded uint64
+
+	// [workLock] must be held before [progressLock] when both are held.
+	progressLock sync.Mutex
+	// Channels that receive the progress of the sync.
+	// [progressLock] must be held when accessing [progressSubscribers].
+	progressSubscribers set.Set[chan Progress]
+	// Set to true once [progressSubscribers] are closed.
+	// [progressLock] must be held when accessing [progressClosed].
+	progressClosed bool
+
+	// The key ranges with the prefixes in [config.PrioritizedPrefixes].
+	prioritizedRanges []keyRange
+	// Closed once [prioritizedRanges] are synced to the target root.
+	// [syncTargetLock] and [workLock] must be held when closing it.
+	prioritizedReady chan struct{}
 }
 
 type ManagerConfig struct {
-	DB                    DB
-	Client                Client
+	DB     DB
+	Client Client
+	// The maximum number of work items processed at once. The number of work
+	// items processed at once is adjusted between 1 and this limit based on
+	// the latency of fetching proofs and the number of proofs waiting to be
+	// committed.
 	SimultaneousWorkLimit int
 	Log                   logging.Logger
 	TargetRoot            ids.ID
 	BranchFactor          merkledb.BranchFactor
+	// If non-nil, the progress of the sync is periodically written to
+	// [CheckpointDB] so that it can be resumed with [RestoreCheckpoint]
+	// after a restart. It must not be [DB].
+	CheckpointDB database.Database
+	// How often the progress of the sync is written to [CheckpointDB].
+	// If 0, defaultCheckpointInterval is used.
+	CheckpointInterval time.Duration
+	// If non-nil, the sync resumes from [Checkpoint] rather than fetching
+	// the entire key range. The key-value pairs recorded as completed in
+	// [Checkpoint] must be in [DB].
+	Checkpoint *Checkpoint
+	// If non-nil, once the database is synced to the target root, the target
+	// root is repeatedly updated to the tip returned by [FollowTip], and the
+	// changes are fetched with change proofs, until the synced root is at
+	// most one block behind the tip. Otherwise, the sync completes as soon as
+	// the database is synced to the target root.
+	FollowTip FollowTipFunc
+	// The maximum number of keys and bytes the client asks for in each
+	// response. Servers honor smaller limits and cap larger ones at their own
+	// maximum, so these can be lowered for constrained links without
+	// coordinating with the servers. If 0, the defaults are used.
+	RequestKeyLimit      uint32
+	RequestByteSizeLimit uint32
+	// Keys with these prefixes are fetched before all other keys. Once they're
+	// synced to the target root, [Manager.PrioritizedRangesReady] is closed.
+	PrioritizedPrefixes [][]byte
+	// If non-nil, a work slot is acquired from [Scheduler] before each work
+	// item is processed, so that the work slots are shared fairly with the
+	// other managers using [Scheduler].
+	Scheduler *Scheduler
 }
 
 func NewManager(config ManagerConfig) (*Manager, error) {
@@ -133,6 +202,24 @@ func NewManager(config ManagerConfig) (*Manager, error) {
 	if err := config.BranchFactor.Valid(); err != nil {
 		return nil, err
 	}
+	if config.Checkpoint != nil {
+		if err := config.Checkpoint.verify(); err != nil {
+			return nil, err
+		}
+	}
+	if config.CheckpointInterval == 0 {
+		config.CheckpointInterval = defaultCheckpointInterval
+	}
+	if config.RequestKeyLimit == 0 {
+		config.RequestKeyLimit = defaultRequestKeyLimit
+	}
+	if config.RequestByteSizeLimit == 0 {
+		config.RequestByteSizeLimit = defaultRequestByteSizeLimit
+	}
+	prioritizedRanges, err := newPrioritizedRanges(config.PrioritizedPrefixes)
+	if err != nil {
+		return nil, err
+	}
 
 	m := &Manager{
 		config:          config,
@@ -140,6 +227,10 @@ func NewManager(config ManagerConfig) (*Manager, error) {
 		unprocessedWork: newWorkHeap(),
 		processedWork:   newWorkHeap(),
 		tokenSize:       merkledb.BranchFactorToTokenSize[config.BranchFactor],
+		workLimiter:     newWorkLimiter(config.SimultaneousWorkLimit),
+
+		prioritizedRanges: prioritizedRanges,
+		prioritizedReady:  make(chan struct{}),
 	}
 	m.unprocessedWorkCond.L = &m.workLock
 
@@ -147,6 +238,9 @@ func NewManager(config ManagerConfig) (*Manager, error) {
 }
 
 func (m *Manager) Start(ctx context.Context) error {
+	m.syncTargetLock.RLock()
+	defer m.syncTargetLock.RUnlock()
+
 	m.workLock.Lock()
 	defer m.workLock.Unlock()
 
@@ -156,14 +250,29 @@ func (m *Manager) Start(ctx context.Context) error {
 
 	m.config.Log.Info("starting sync", zap.Stringer("target root", m.config.TargetRoot))
 
-	// Add work item to fetch the entire key range.
-	// Note that this will be the first work item to be processed.
-	m.unprocessedWork.Insert(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority))
+	if m.config.Checkpoint != nil {
+		m.restoreCheckpoint(m.config.Checkpoint)
+	} else {
+		// Add work item to fetch the entire key range.
+		// Note that this will be the first work item to be processed, unless
+		// there are prioritized ranges.
+		m.insertPrioritizedWork(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority))
+	}
+	m.checkPrioritizedRangesReady()
 
 	m.syncing = true
+	m.startTime = time.Now()
+	m.completedAtStart = m.completedFraction()
 	ctx, m.cancelCtx = context.WithCancel(ctx)
 
+	if m.config.Scheduler != nil {
+		m.config.Scheduler.register(m)
+	}
+
 	go m.sync(ctx)
+	if m.config.CheckpointDB != nil {
+		go m.writeCheckpoints()
+	}
 	return nil
 }
 
@@ -184,14 +293,34 @@ func (m *Manager) sync(ctx context.Context) {
 		switch {
 		case ctx.Err() != nil:
 			return // [m.workLock] released by defer.
-		case m.processingWorkItems >= m.config.SimultaneousWorkLimit:
+		case m.paused:
+			// Wait until Resume() or Close() is called.
+			m.unprocessedWorkCond.Wait()
+		case m.processingWorkItems >= m.workLimiter.Limit():
 			// We're already processing the maximum number of work items.
 			// Wait until one of them finishes.
 			m.unprocessedWorkCond.Wait()
 		case m.unprocessedWork.Len() == 0:
 			if m.processingWorkItems == 0 {
 				// There's no work to do, and there are no work items being processed
-				// which could cause work to be added, so we're done.
+				// which could cause work to be added, so we're synced to the
+				// target root.
+				if m.config.FollowTip != nil {
+					m.workLock.Unlock()
+					followed := m.followTip(ctx)
+					m.workLock.Lock()
+					if followed || ctx.Err() != nil {
+						continue
+					}
+					// [m.workLock] was released while following the tip, so
+					// the target may have been updated concurrently, adding
+					// work that must be done before we're synced.
+					if m.unprocessedWork.Len() != 0 || m.processingWorkItems != 0 {
+						continue
+					}
+				}
+				// We're done.
+				m.completed = true
 				return // [m.workLock] released by defer.
 			}
 			// There's no work to do.
@@ -200,6 +329,12 @@ func (m *Manager) sync(ctx context.Context) {
 			// which will cause Wait() to return, and this goroutine to exit.
 			m.unprocessedWorkCond.Wait()
 		default:
+			if m.config.Scheduler != nil && !m.config.Scheduler.tryAcquire(m) {
+				// Other managers are using the shared work slots. Wait until
+				// the scheduler wakes us up.
+				m.unprocessedWorkCond.Wait()
+				continue
+			}
 			m.processingWorkItems++
 			work := m.unprocessedWork.GetWork()
 			go m.doWork(ctx, work)
@@ -207,6 +342,47 @@ func (m *Manager) sync(ctx context.Context) {
 	}
 }
 
+// Pause stops new work items from being started until Resume is called.
+// Work items that are being processed are completed, so no progress is lost.
+// Pause may be called before Start.
+func (m *Manager) Pause() {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	if m.paused {
+		return
+	}
+	m.paused = true
+	if m.config.Scheduler != nil {
+		// Don't hold other managers to their fair share while paused.
+		m.config.Scheduler.stopWaiting(m)
+	}
+	m.config.Log.Info("pausing sync",
+		zap.Int("processingWorkItems", m.processingWorkItems),
+	)
+}
+
+// Resume starts work items again after Pause was called.
+func (m *Manager) Resume() {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	if !m.paused {
+		return
+	}
+	m.paused = false
+	m.config.Log.Info("resuming sync")
+	m.unprocessedWorkCond.Signal()
+}
+
+// Paused returns true if the sync is paused.
+func (m *Manager) Paused() bool {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	return m.paused
+}
+
 // Close will stop the syncing process
 func (m *Manager) Close() {
 	m.workLock.Lock()
@@ -230,11 +406,26 @@ func (m *Manager) close() {
 		m.unprocessedWorkCond.Signal()
 		m.processedWork.Close()
 
+		if m.config.Scheduler != nil {
+			m.config.Scheduler.unregister(m)
+		}
+
 		// signal all code waiting on the sync to complete
 		close(m.doneChan)
+		m.closeProgress()
 	})
 }
 
+// wake wakes up the sync loop, so that it retries acquiring a work slot from
+// [m.config.Scheduler].
+// Assumes [m.workLock] is not held.
+func (m *Manager) wake() {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	m.unprocessedWorkCond.Signal()
+}
+
 // Processes [item] by fetching and applying a change or range proof.
 // Assumes [m.workLock] is not held.
 func (m *Manager) doWork(ctx context.Context, work *workItem) {
@@ -243,6 +434,9 @@ func (m *Manager) doWork(ctx context.Context, work *workItem) {
 		defer m.workLock.Unlock()
 
 		m.processingWorkItems--
+		if m.config.Scheduler != nil {
+			m.config.Scheduler.release(m)
+		}
 		m.unprocessedWorkCond.Signal()
 	}()
 
@@ -266,6 +460,7 @@ func (m *Manager) getAndApplyChangeProof(ctx context.Context, work *workItem) {
 		return
 	}
 
+	fetchStart := time.Now()
 	changeOrRangeProof, err := m.config.Client.GetChangeProof(
 		ctx,
 		&pb.SyncGetChangeProofRequest{
@@ -279,8 +474,8 @@ func (m *Manager) getAndApplyChangeProof(ctx context.Context, work *workItem) {
 				Value:     work.end.Value(),
 				IsNothing: work.end.IsNothing(),
 			},
-			KeyLimit:   defaultRequestKeyLimit,
-			BytesLimit: defaultRequestByteSizeLimit,
+			KeyLimit:   m.config.RequestKeyLimit,
+			BytesLimit: m.config.RequestByteSizeLimit,
 		},
 		m.config.DB,
 	)
@@ -288,6 +483,7 @@ func (m *Manager) getAndApplyChangeProof(ctx context.Context, work *workItem) {
 		m.setError(err)
 		return
 	}
+	m.workLimiter.FetchCompleted(time.Since(fetchStart))
 
 	select {
 	case <-m.doneChan:
@@ -296,13 +492,19 @@ func (m *Manager) getAndApplyChangeProof(ctx context.Context, work *workItem) {
 	default:
 	}
 
+	if changeOrRangeProof.ChangeProof != nil {
+		m.trackDownloaded(nil, changeOrRangeProof.ChangeProof.KeyChanges)
+	} else {
+		m.trackDownloaded(changeOrRangeProof.RangeProof.KeyValues, nil)
+	}
+
 	if changeOrRangeProof.ChangeProof != nil {
 		// The server had sufficient history to respond with a change proof.
 		changeProof := changeOrRangeProof.ChangeProof
 		largestHandledKey := work.end
 		// if the proof wasn't empty, apply changes to the sync DB
 		if len(changeProof.KeyChanges) > 0 {
-			if err := m.config.DB.CommitChangeProof(ctx, changeProof); err != nil {
+			if err := m.commitChangeProof(ctx, changeProof); err != nil {
 				m.setError(err)
 				return
 			}
@@ -318,7 +520,7 @@ func (m *Manager) getAndApplyChangeProof(ctx context.Context, work *workItem) {
 	largestHandledKey := work.end
 	if len(rangeProof.KeyValues) > 0 {
 		// Add all the key-value pairs we got to the database.
-		if err := m.config.DB.CommitRangeProof(ctx, work.start, work.end, rangeProof); err != nil {
+		if err := m.commitRangeProof(ctx, work.start, work.end, rangeProof); err != nil {
 			m.setError(err)
 			return
 		}
@@ -332,6 +534,7 @@ func (m *Manager) getAndApplyChangeProof(ctx context.Context, work *workItem) {
 // Assumes [m.workLock] is not held.
 func (m *Manager) getAndApplyRangeProof(ctx context.Context, work *workItem) {
 	targetRootID := m.getTargetRoot()
+	fetchStart := time.Now()
 	proof, err := m.config.Client.GetRangeProof(ctx,
 		&pb.SyncGetRangeProofRequest{
 			RootHash: targetRootID[:],
@@ -343,14 +546,15 @@ func (m *Manager) getAndApplyRangeProof(ctx context.Context, work *workItem) {
 				Value:     work.end.Value(),
 				IsNothing: work.end.IsNothing(),
 			},
-			KeyLimit:   defaultRequestKeyLimit,
-			BytesLimit: defaultRequestByteSizeLimit,
+			KeyLimit:   m.config.RequestKeyLimit,
+			BytesLimit: m.config.RequestByteSizeLimit,
 		},
 	)
 	if err != nil {
 		m.setError(err)
 		return
 	}
+	m.workLimiter.FetchCompleted(time.Since(fetchStart))
 
 	select {
 	case <-m.doneChan:
@@ -359,10 +563,11 @@ func (m *Manager) getAndApplyRangeProof(ctx context.Context, work *workItem) {
 	default:
 	}
 
+	m.trackDownloaded(proof.KeyValues, nil)
 	largestHandledKey := work.end
 
 	// Replace all the key-value pairs in the DB from start to end with values from the response.
-	if err := m.config.DB.CommitRangeProof(ctx, work.start, work.end, proof); err != nil {
+	if err := m.commitRangeProof(ctx, work.start, work.end, proof); err != nil {
 		m.setError(err)
 		return
 	}
@@ -374,6 +579,22 @@ func (m *Manager) getAndApplyRangeProof(ctx context.Context, work *workItem) {
 	m.completeWorkItem(ctx, work, largestHandledKey, targetRootID, proof.EndProof)
 }
 
+// Commits [proof] to the database, tracking the commit in [m.workLimiter].
+func (m *Manager) commitRangeProof(ctx context.Context, start, end maybe.Maybe[[]byte], proof *merkledb.RangeProof) error {
+	m.workLimiter.CommitStarted()
+	defer m.workLimiter.CommitFinished()
+
+	return m.config.DB.CommitRangeProof(ctx, start, end, proof)
+}
+
+// Commits [proof] to the database, tracking the commit in [m.workLimiter].
+func (m *Manager) commitChangeProof(ctx context.Context, proof *merkledb.ChangeProof) error {
+	m.workLimiter.CommitStarted()
+	defer m.workLimiter.CommitFinished()
+
+	return m.config.DB.CommitChangeProof(ctx, proof)
+}
+
 // findNextKey returns the start of the key range that should be fetched next
 // given that we just received a range/change proof that proved a range of
 // key-value pairs ending at [lastReceivedKey].
@@ -583,6 +804,9 @@ func (m *Manager) Wait(ctx context.Context) error {
 }
 
 func (m *Manager) UpdateSyncTarget(syncTargetRoot ids.ID) error {
+	// Called after the locks are released.
+	defer m.notifyProgress()
+
 	m.syncTargetLock.Lock()
 	defer m.syncTargetLock.Unlock()
 
@@ -600,20 +824,15 @@ func (m *Manager) UpdateSyncTarget(syncTargetRoot ids.ID) error {
 		return nil
 	}
 
-	m.config.Log.Debug("updated sync target", zap.Stringer("target", syncTargetRoot))
 	m.config.TargetRoot = syncTargetRoot
 
-	// move all completed ranges into the work heap with high priority
-	shouldSignal := m.processedWork.Len() > 0
-	for m.processedWork.Len() > 0 {
-		// Note that [m.processedWork].Close() hasn't
-		// been called because we have [m.workLock]
-		// and we checked that [m.closed] is false.
-		currentItem := m.processedWork.GetWork()
-		currentItem.priority = highPriority
-		m.unprocessedWork.Insert(currentItem)
-	}
-	if shouldSignal {
+	numStaleRanges, numRevalidatedRanges := m.reconcileStaleRanges()
+	m.config.Log.Debug("updated sync target",
+		zap.Stringer("target", syncTargetRoot),
+		zap.Int("numStaleRanges", numStaleRanges),
+		zap.Int("numRevalidatedRanges", numRevalidatedRanges),
+	)
+	if numStaleRanges > 0 || numRevalidatedRanges > 0 {
 		// Only signal once because we only have 1 goroutine
 		// waiting on [m.unprocessedWorkCond].
 		m.unprocessedWorkCond.Signal()
@@ -621,6 +840,61 @@ func (m *Manager) UpdateSyncTarget(syncTargetRoot ids.ID) error {
 	return nil
 }
 
+// reconcileStaleRanges is called after the target root changes. Ranges that
+// were completed for a previous target root are queued to be revalidated with
+// change proofs, rather than fetched again with range proofs. Adjacent stale
+// ranges that were completed for the same root are merged, so that each root's
+// changes are fetched with as few change proofs as possible. Queued ranges that
+// were completed for the new target root, which happens if the target reverts
+// to a previous root, are already up to date and are marked as processed.
+//
+// Returns the number of ranges that need to be revalidated and the number of
+// ranges that are already up to date.
+// Assumes [m.syncTargetLock] and [m.workLock] are held.
+func (m *Manager) reconcileStaleRanges() (int, int) {
+	// Note that [m.processedWork].Close() hasn't
+	// been called because we have [m.workLock]
+	// and we checked that [m.closed] is false.
+	var fetchedItems []*workItem
+	for m.processedWork.Len() > 0 {
+		fetchedItems = append(fetchedItems, m.processedWork.GetWork())
+	}
+	for _, item := range m.unprocessedWork.Items() {
+		// Ranges with an empty root haven't been fetched yet.
+		if item.localRootID != ids.Empty {
+			m.unprocessedWork.remove(item)
+			fetchedItems = append(fetchedItems, item)
+		}
+	}
+
+	numRevalidatedRanges := 0
+	for _, item := range fetchedItems {
+		if item.localRootID == m.config.TargetRoot {
+			m.processedWork.MergeInsert(item)
+			numRevalidatedRanges++
+		} else {
+			m.insertStaleWork(item)
+		}
+	}
+
+	numStaleRanges := 0
+	for _, item := range m.unprocessedWork.Items() {
+		if item.localRootID != ids.Empty {
+			numStaleRanges++
+		}
+	}
+	return numStaleRanges, numRevalidatedRanges
+}
+
+// insertStaleWork queues [work], a range that was completed for a previous
+// target root, to be revalidated with a change proof. The range isn't split
+// because the change proof is expected to be much smaller than the range.
+// Assumes [m.workLock] is held.
+func (m *Manager) insertStaleWork(work *workItem) {
+	work.priority = highPriority
+	m.unprocessedWork.MergeInsert(work)
+}
+
 func (m *Manager) getTargetRoot() ids.ID {
 	m.syncTargetLock.RLock()
 	defer m.syncTargetLock.RUnlock()
@@ -654,6 +928,9 @@ func (m *Manager) setError(err error) {
 //
 // Assumes [m.workLock] is not held.
 func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestHandledKey maybe.Maybe[[]byte], rootID ids.ID, proofOfLargestKey []merkledb.ProofNode) {
+	// Called after the locks are released.
+	defer m.notifyProgress()
+
 	if !maybe.Equal(largestHandledKey, work.end, bytes.Equal) {
 		// The largest handled key isn't equal to the end of the work item.
 		// Find the start of the next key range to fetch.
@@ -685,13 +962,17 @@ func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestH
 
 	stale := m.config.TargetRoot != rootID
 	if stale {
-		// the root has changed, so reinsert with high priority
-		m.enqueueWork(newWorkItem(rootID, work.start, largestHandledKey, highPriority))
+		// the root has changed, so revalidate the range with a change proof
+		m.workLock.Lock()
+		m.insertStaleWork(newWorkItem(rootID, work.start, largestHandledKey, highPriority))
+		m.workLock.Unlock()
+		m.unprocessedWorkCond.Signal()
 	} else {
 		m.workLock.Lock()
 		defer m.workLock.Unlock()
 
 		m.processedWork.MergeInsert(newWorkItem(rootID, work.start, largestHandledKey, work.priority))
+		m.checkPrioritizedRangesReady()
 	}
 
 	// completed the range [work.start, lastKey], log and record in the completed work heap
@@ -738,6 +1019,11 @@ func (m *Manager) enqueueWork(work *workItem) {
 	// rather than start a new range that is not contiguous with existing completed ranges
 	first := newWorkItem(work.localRootID, work.start, mid, medPriority)
 	second := newWorkItem(work.localRootID, mid, work.end, lowPriority)
+	if work.priority == prioritizedPriority {
+		// Both halves are still prioritized.
+		first.priority = prioritizedPriority
+		second.priority = prioritizedPriority
+	}
 
 	m.unprocessedWork.Insert(first)
 	m.unprocessedWork.Insert(second)
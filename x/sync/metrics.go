@@ -5,10 +5,30 @@ package sync
 
 import (
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/units"
+)
+
+const (
+	tierLabel = "tier"
+
+	// Peers that aren't validators of the subnet.
+	StakeTierNone = "none"
+	// Validators with less than 0.1% of the subnet's stake.
+	StakeTierLow = "low"
+	// Validators with at least 0.1% and less than 1% of the subnet's stake.
+	StakeTierMedium = "medium"
+	// Validators with at least 1% of the subnet's stake.
+	StakeTierHigh = "high"
+	// Peers whose stake isn't known because the client wasn't given a
+	// validator set.
+	StakeTierUnknown = "unknown"
 )
 
 var (
@@ -20,13 +40,51 @@ type SyncMetrics interface {
 	RequestFailed()
 	RequestMade()
 	RequestSucceeded()
+	// ResponseReceived records that a peer in [tier] responded to a proof
+	// request with [numBytes] bytes after [latency].
+	ResponseReceived(tier string, latency time.Duration, numBytes int)
+	// VerificationFailed records that a response from a peer in [tier]
+	// couldn't be parsed or verified.
+	VerificationFailed(tier string)
+	// RequestRetried records that a proof request was retried after the
+	// request to a peer in [tier] failed.
+	RequestRetried(tier string)
+}
+
+// stakeTier returns the stake tier of [nodeID] in [subnetID] according to
+// [vdrs]. Returns [StakeTierUnknown] if [vdrs] is nil.
+func stakeTier(vdrs validators.Manager, subnetID ids.ID, nodeID ids.NodeID) string {
+	if vdrs == nil {
+		return StakeTierUnknown
+	}
+	weight := vdrs.GetWeight(subnetID, nodeID)
+	if weight == 0 {
+		return StakeTierNone
+	}
+	totalWeight, err := vdrs.TotalWeight(subnetID)
+	if err != nil || totalWeight == 0 {
+		return StakeTierUnknown
+	}
+	// Divide [totalWeight] rather than multiplying [weight] to avoid
+	// overflows.
+	switch {
+	case weight < totalWeight/1_000:
+		return StakeTierLow
+	case weight < totalWeight/100:
+		return StakeTierMedium
+	default:
+		return StakeTierHigh
+	}
 }
 
 type mockMetrics struct {
-	lock              sync.Mutex
-	requestsFailed    int
-	requestsMade      int
-	requestsSucceeded int
+	lock                sync.Mutex
+	requestsFailed      int
+	requestsMade        int
+	requestsSucceeded   int
+	responsesReceived   map[string]int
+	verificationsFailed map[string]int
+	requestsRetried     map[string]int
 }
 
 func (m *mockMetrics) RequestFailed() {
@@ -50,10 +108,44 @@ func (m *mockMetrics) RequestSucceeded() {
 	m.requestsSucceeded++
 }
 
+func (m *mockMetrics) ResponseReceived(tier string, _ time.Duration, _ int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.responsesReceived == nil {
+		m.responsesReceived = make(map[string]int)
+	}
+	m.responsesReceived[tier]++
+}
+
+func (m *mockMetrics) VerificationFailed(tier string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.verificationsFailed == nil {
+		m.verificationsFailed = make(map[string]int)
+	}
+	m.verificationsFailed[tier]++
+}
+
+func (m *mockMetrics) RequestRetried(tier string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.requestsRetried == nil {
+		m.requestsRetried = make(map[string]int)
+	}
+	m.requestsRetried[tier]++
+}
+
 type metrics struct {
-	requestsFailed    prometheus.Counter
-	requestsMade      prometheus.Counter
-	requestsSucceeded prometheus.Counter
+	requestsFailed      prometheus.Counter
+	requestsMade        prometheus.Counter
+	requestsSucceeded   prometheus.Counter
+	responseLatency     *prometheus.HistogramVec
+	responseBytes       *prometheus.HistogramVec
+	verificationsFailed *prometheus.CounterVec
+	requestsRetried     *prometheus.CounterVec
 }
 
 func NewMetrics(namespace string, reg prometheus.Registerer) (SyncMetrics, error) {
@@ -73,11 +165,49 @@ func NewMetrics(namespace string, reg prometheus.Registerer) (SyncMetrics, error
 			Name:      "requests_succeeded",
 			Help:      "cumulative amount of proof requests that were successful",
 		}),
+		responseLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "response_latency",
+				Help:      "time (in seconds) peers took to respond to proof requests, by peer stake tier",
+				Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+			},
+			[]string{tierLabel},
+		),
+		responseBytes: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "response_bytes",
+				Help:      "size (in bytes) of the responses to proof requests, by peer stake tier",
+				Buckets:   prometheus.ExponentialBuckets(units.KiB, 4, 8),
+			},
+			[]string{tierLabel},
+		),
+		verificationsFailed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "verifications_failed",
+				Help:      "cumulative amount of proof responses that failed verification, by peer stake tier",
+			},
+			[]string{tierLabel},
+		),
+		requestsRetried: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "requests_retried",
+				Help:      "cumulative amount of proof requests retried after the request to a peer failed, by peer stake tier",
+			},
+			[]string{tierLabel},
+		),
 	}
 	err := utils.Err(
 		reg.Register(m.requestsFailed),
 		reg.Register(m.requestsMade),
 		reg.Register(m.requestsSucceeded),
+		reg.Register(m.responseLatency),
+		reg.Register(m.responseBytes),
+		reg.Register(m.verificationsFailed),
+		reg.Register(m.requestsRetried),
 	)
 	return &m, err
 }
@@ -93,3 +223,16 @@ func (m *metrics) RequestMade() {
 func (m *metrics) RequestSucceeded() {
 	m.requestsSucceeded.Inc()
 }
+
+func (m *metrics) ResponseReceived(tier string, latency time.Duration, numBytes int) {
+	m.responseLatency.WithLabelValues(tier).Observe(latency.Seconds())
+	m.responseBytes.WithLabelValues(tier).Observe(float64(numBytes))
+}
+
+func (m *metrics) VerificationFailed(tier string) {
+	m.verificationsFailed.WithLabelValues(tier).Inc()
+}
+
+func (m *metrics) RequestRetried(tier string) {
+	m.requestsRetried.WithLabelValues(tier).Inc()
+}
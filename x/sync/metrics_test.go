@@ -0,0 +1,49 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/validators"
+)
+
+func TestStakeTier(t *testing.T) {
+	require := require.New(t)
+
+	subnetID := ids.GenerateTestID()
+	require.Equal(StakeTierUnknown, stakeTier(nil, subnetID, ids.GenerateTestNodeID()))
+
+	vdrs := validators.NewManager()
+	tests := []struct {
+		weight       uint64
+		expectedTier string
+	}{
+		{
+			weight:       1,
+			expectedTier: StakeTierLow,
+		},
+		{
+			weight:       1_000,
+			expectedTier: StakeTierMedium,
+		},
+		{
+			weight:       998_999,
+			expectedTier: StakeTierHigh,
+		},
+	}
+	nodeIDs := make([]ids.NodeID, len(tests))
+	for i, test := range tests {
+		nodeIDs[i] = ids.GenerateTestNodeID()
+		require.NoError(vdrs.AddStaker(subnetID, nodeIDs[i], nil, ids.Empty, test.weight))
+	}
+
+	require.Equal(StakeTierNone, stakeTier(vdrs, subnetID, ids.GenerateTestNodeID()))
+	for i, test := range tests {
+		require.Equal(test.expectedTier, stakeTier(vdrs, subnetID, nodeIDs[i]))
+	}
+}
@@ -134,3 +134,15 @@ func (mr *MockNetworkClientMockRecorder) TrackBandwidth(nodeID, bandwidth interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrackBandwidth", reflect.TypeOf((*MockNetworkClient)(nil).TrackBandwidth), nodeID, bandwidth)
 }
+
+// TrackResponse mocks base method.
+func (m *MockNetworkClient) TrackResponse(nodeID ids.NodeID, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TrackResponse", nodeID, err)
+}
+
+// TrackResponse indicates an expected call of TrackResponse.
+func (mr *MockNetworkClientMockRecorder) TrackResponse(nodeID, err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrackResponse", reflect.TypeOf((*MockNetworkClient)(nil).TrackResponse), nodeID, err)
+}
@@ -33,6 +33,7 @@ var (
 	errAcquiringSemaphore = errors.New("error acquiring semaphore")
 	errRequestFailed      = errors.New("request failed")
 	errAppSendFailed      = errors.New("failed to send app message")
+	errPeerBanned         = errors.New("peer is banned")
 )
 
 // NetworkClient defines ability to send request / response through the Network
@@ -59,6 +60,12 @@ type NetworkClient interface {
 	// The following declarations allow this interface to be embedded in the VM
 	// to handle incoming responses from peers.
 
+	// Records whether the response from [nodeID] was valid.
+	// [err] is the error returned when parsing or verifying the
+	// response, or nil if the response was valid.
+	// Peers that repeatedly send invalid responses are banned.
+	TrackResponse(nodeID ids.NodeID, err error)
+
 	// Always returns nil because the engine considers errors
 	// returned from this function as fatal.
 	AppResponse(context.Context, ids.NodeID, uint32, []byte) error
@@ -89,6 +96,12 @@ type networkClient struct {
 	activeRequests *semaphore.Weighted
 	// tracking of peers & bandwidth usage
 	peers *p2p.PeerTracker
+	// scoring and banning of peers that send invalid responses
+	scores *peerScores
+	// nodeID -> version of each connected peer, including banned peers
+	connectedPeers map[ids.NodeID]*version.Application
+	// limits the rate of requests and response bytes
+	throttler *throttler
 	// For sending messages to peers
 	appSender common.AppSender
 }
@@ -97,6 +110,27 @@ func NewNetworkClient(
 	appSender common.AppSender,
 	myNodeID ids.NodeID,
 	maxActiveRequests int64,
+	throttlerConfig ThrottlerConfig,
+	log logging.Logger,
+	metricsNamespace string,
+	registerer prometheus.Registerer,
+) (NetworkClient, error) {
+	return newNetworkClient(
+		appSender,
+		myNodeID,
+		semaphore.NewWeighted(maxActiveRequests),
+		newThrottler(throttlerConfig),
+		log,
+		metricsNamespace,
+		registerer,
+	)
+}
+
+func newNetworkClient(
+	appSender common.AppSender,
+	myNodeID ids.NodeID,
+	activeRequests *semaphore.Weighted,
+	throttler *throttler,
 	log logging.Logger,
 	metricsNamespace string,
 	registerer prometheus.Registerer,
@@ -106,12 +140,20 @@ func NewNetworkClient(
 		return nil, fmt.Errorf("failed to create peer tracker: %w", err)
 	}
 
+	scores, err := newPeerScores(metricsNamespace, registerer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create peer scores: %w", err)
+	}
+
 	return &networkClient{
 		appSender:                  appSender,
 		myNodeID:                   myNodeID,
 		outstandingRequestHandlers: make(map[uint32]ResponseHandler),
-		activeRequests:             semaphore.NewWeighted(maxActiveRequests),
+		activeRequests:             activeRequests,
 		peers:                      peerTracker,
+		scores:                     scores,
+		connectedPeers:             make(map[ids.NodeID]*version.Application),
+		throttler:                  throttler,
 		log:                        log,
 	}, nil
 }
@@ -202,6 +244,8 @@ func (c *networkClient) RequestAny(
 	}
 	defer c.activeRequests.Release(1)
 
+	c.unbanExpiredPeers()
+
 	nodeID, ok := c.peers.GetAnyPeer(minVersion)
 	if !ok {
 		return ids.EmptyNodeID, nil, fmt.Errorf(
@@ -209,6 +253,11 @@ func (c *networkClient) RequestAny(
 			minVersion, c.peers.Size(),
 		)
 	}
+	if c.scores.IsBanned(nodeID) {
+		// A banned peer may still be returned if a request to it was
+		// outstanding when it was banned.
+		return nodeID, nil, fmt.Errorf("%w: %s", errPeerBanned, nodeID)
+	}
 
 	response, err := c.request(ctx, nodeID, request)
 	return nodeID, response, err
@@ -227,13 +276,17 @@ func (c *networkClient) Request(
 	}
 	defer c.activeRequests.Release(1)
 
+	if c.scores.IsBanned(nodeID) {
+		return nil, fmt.Errorf("%w: %s", errPeerBanned, nodeID)
+	}
 	return c.request(ctx, nodeID, request)
 }
 
 // Sends [request] to [nodeID] and returns the response.
 // Returns an error if the request failed or [ctx] is canceled.
 // If [errAppSendFailed] is returned this should be considered fatal.
-// Blocks until a response is received or the [ctx] is canceled fails.
+// Blocks until [c.throttler] allows the request to be sent and then until a
+// response is received or the [ctx] is canceled fails.
 // Releases active requests semaphore if there was an error in sending the request.
 // Assumes [nodeID] is never [c.myNodeID] since we guarantee
 // [c.myNodeID] will not be added to [c.peers].
@@ -243,6 +296,10 @@ func (c *networkClient) request(
 	nodeID ids.NodeID,
 	request []byte,
 ) ([]byte, error) {
+	if err := c.throttler.Acquire(ctx, nodeID); err != nil {
+		return nil, err
+	}
+
 	c.lock.Lock()
 	c.log.Debug("sending request to peer",
 		zap.Stringer("nodeID", nodeID),
@@ -282,14 +339,17 @@ func (c *networkClient) request(
 		c.peers.TrackBandwidth(nodeID, 0)
 		return nil, ctx.Err()
 	case response = <-handler.responseChan:
+		c.throttler.Consume(nodeID, len(response))
 		elapsedSeconds := time.Since(startTime).Seconds()
 		bandwidth := float64(len(response))/elapsedSeconds + epsilon
 		c.peers.TrackBandwidth(nodeID, bandwidth)
 	}
 	if handler.failed {
 		c.peers.TrackBandwidth(nodeID, 0)
+		c.trackOutcome(nodeID, failedRequest)
 		return nil, errRequestFailed
 	}
+	c.scores.ObserveLatency(nodeID, time.Since(startTime))
 
 	c.log.Debug("received response from peer",
 		zap.Stringer("nodeID", nodeID),
@@ -299,6 +359,67 @@ func (c *networkClient) request(
 	return response, nil
 }
 
+func (c *networkClient) TrackResponse(nodeID ids.NodeID, err error) {
+	var outcome requestOutcome
+	switch {
+	case err == nil:
+		outcome = validResponse
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// Verification was interrupted, which isn't the peer's fault.
+		return
+	case errors.Is(err, errInvalidRangeProof):
+		outcome = invalidProof
+	default:
+		outcome = malformedResponse
+	}
+
+	if outcome != validResponse {
+		// Deprioritize the peer even if it isn't banned.
+		c.peers.TrackBandwidth(nodeID, 0)
+	}
+	c.trackOutcome(nodeID, outcome)
+}
+
+// Records the [outcome] of a request sent to [nodeID] and stops sending
+// requests to [nodeID] if it's banned as a result.
+// Assumes [c.lock] isn't held.
+func (c *networkClient) trackOutcome(nodeID ids.NodeID, outcome requestOutcome) {
+	// [c.lock] is held while banning the peer so that it isn't re-added to
+	// [c.peers] by a concurrent call to Connected or unbanExpiredPeers.
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.scores.Observe(nodeID, outcome) {
+		return
+	}
+
+	c.log.Info("banning peer",
+		zap.Stringer("nodeID", nodeID),
+		zap.Duration("duration", banDuration),
+	)
+	c.peers.Disconnected(nodeID)
+}
+
+// Lets the peers whose bans expired receive requests again.
+func (c *networkClient) unbanExpiredPeers() {
+	unbanned := c.scores.Unban(time.Now())
+	if len(unbanned) == 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, nodeID := range unbanned {
+		nodeVersion, ok := c.connectedPeers[nodeID]
+		if !ok {
+			continue
+		}
+		c.log.Debug("unbanning peer", zap.Stringer("nodeID", nodeID))
+		c.peers.Connected(nodeID, nodeVersion)
+	}
+}
+
 func (c *networkClient) Connected(
 	_ context.Context,
 	nodeID ids.NodeID,
@@ -310,6 +431,15 @@ func (c *networkClient) Connected(
 	}
 
 	c.log.Debug("adding new peer", zap.Stringer("nodeID", nodeID))
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.connectedPeers[nodeID] = nodeVersion
+	if c.scores.IsBanned(nodeID) {
+		// The peer is added to [c.peers] once its ban expires.
+		return nil
+	}
 	c.peers.Connected(nodeID, nodeVersion)
 	return nil
 }
@@ -321,6 +451,14 @@ func (c *networkClient) Disconnected(_ context.Context, nodeID ids.NodeID) error
 	}
 
 	c.log.Debug("disconnecting peer", zap.Stringer("nodeID", nodeID))
+	c.lock.Lock()
+	delete(c.connectedPeers, nodeID)
+	// Removed while [c.lock] is held so that a concurrent reconnection isn't
+	// undone.
 	c.peers.Disconnected(nodeID)
+	c.scores.Disconnected(nodeID)
+	c.lock.Unlock()
+
+	c.throttler.Disconnected(nodeID)
 	return nil
 }
@@ -202,7 +202,8 @@ func (s *NetworkServer) HandleChangeProofRequest(
 			}
 
 			// [s.db] doesn't have sufficient history to generate change proof.
-			// Generate a range proof for the end root ID instead.
+			// Generate a range proof for the end root ID instead, honoring
+			// the same limits as the change proof.
 			proofBytes, err := getRangeProof(
 				ctx,
 				s.db,
@@ -210,8 +211,8 @@ func (s *NetworkServer) HandleChangeProofRequest(
 					RootHash:   req.EndRootHash,
 					StartKey:   req.StartKey,
 					EndKey:     req.EndKey,
-					KeyLimit:   req.KeyLimit,
-					BytesLimit: req.BytesLimit,
+					KeyLimit:   keyLimit,
+					BytesLimit: uint32(bytesLimit),
 				},
 				func(rangeProof *merkledb.RangeProof) ([]byte, error) {
 					return proto.Marshal(&pb.SyncGetChangeProofResponse{
@@ -316,13 +317,12 @@ func (s *NetworkServer) HandleRangeProofRequest(
 }
 
 // Get the range proof specified by [req].
-// If the generated proof is too large, the key limit is reduced
+// The proof is generated to be smaller than [req.BytesLimit]. If the bytes
+// returned by [marshalFunc] are still too large, the key limit is reduced
 // and the proof is regenerated. This process is repeated until
 // the proof is smaller than [req.BytesLimit].
 // When a sufficiently small proof is generated, returns it.
 // If no sufficiently small proof can be generated, returns [ErrMinProofSizeIsTooLarge].
-// TODO improve range proof generation so we don't need to iteratively
-// reduce the key limit.
 func getRangeProof(
 	ctx context.Context,
 	db DB,
@@ -334,7 +334,11 @@ func getRangeProof(
 		return nil, err
 	}
 
-	keyLimit := int(req.KeyLimit)
+	var (
+		keyLimit = int(req.KeyLimit)
+		// The proof must be strictly smaller than [req.BytesLimit].
+		maxBytes = math.Max(int(req.BytesLimit)-1, 1)
+	)
 
 	for keyLimit > 0 {
 		rangeProof, err := db.GetRangeProofAtRoot(
@@ -343,11 +347,14 @@ func getRangeProof(
 			maybeBytesToMaybe(req.StartKey),
 			maybeBytesToMaybe(req.EndKey),
 			keyLimit,
+			maxBytes,
 		)
-		if err != nil {
-			if errors.Is(err, merkledb.ErrInsufficientHistory) {
-				return nil, nil // drop request
-			}
+		switch {
+		case errors.Is(err, merkledb.ErrInsufficientHistory):
+			return nil, nil // drop request
+		case errors.Is(err, merkledb.ErrMinProofTooLarge):
+			return nil, ErrMinProofSizeIsTooLarge
+		case err != nil:
 			return nil, err
 		}
 
@@ -19,6 +19,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/engine/common"
 	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
 	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
@@ -260,6 +261,26 @@ func Test_Server_GetChangeProof(t *testing.T) {
 			expectedMaxResponseBytes: defaultRequestByteSizeLimit,
 			expectRangeProof:         true,
 		},
+		"insufficient history for change proof; range proof honors bytes limit": {
+			request: &pb.SyncGetChangeProofRequest{
+				StartRootHash: ids.Empty[:],
+				EndRootHash:   endRoot[:],
+				KeyLimit:      defaultRequestKeyLimit,
+				BytesLimit:    10 * units.KiB,
+			},
+			expectRangeProof: true,
+		},
+		"insufficient history for change proof; range proof bytes limit too large": {
+			request: &pb.SyncGetChangeProofRequest{
+				StartRootHash: ids.Empty[:],
+				EndRootHash:   endRoot[:],
+				KeyLimit:      2 * defaultRequestKeyLimit,
+				BytesLimit:    2 * defaultRequestByteSizeLimit,
+			},
+			expectedResponseLen:      defaultRequestKeyLimit,
+			expectedMaxResponseBytes: defaultRequestByteSizeLimit,
+			expectRangeProof:         true,
+		},
 		"insufficient history for change proof or range proof": {
 			request: &pb.SyncGetChangeProofRequest{
 				// These roots don't exist so server has insufficient history
@@ -421,6 +442,7 @@ func TestAppRequestErrAppSendFailed(t *testing.T) {
 					gomock.Any(),
 					gomock.Any(),
 					gomock.Any(),
+					gomock.Any(),
 				).Return(&merkledb.RangeProof{}, nil).Times(1)
 
 				return NewNetworkServer(sender, db, logging.NoLog{})
@@ -0,0 +1,256 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+)
+
+const (
+	peerScoreHalflife = 5 * time.Minute
+
+	// A peer is banned if its score drops below [banScoreThreshold] once it
+	// has been sent at least [minRequestsToBan] requests, so that a single bad
+	// response from a new peer doesn't get it banned.
+	banScoreThreshold = 0.5
+	minRequestsToBan  = 5
+	banDuration       = 10 * time.Minute
+
+	nodeIDLabel = "nodeID"
+)
+
+// The result of a request sent to a peer.
+type requestOutcome int
+
+const (
+	validResponse requestOutcome = iota
+	// The response contained a proof that failed verification.
+	invalidProof
+	// The response couldn't be parsed or exceeded the request's limits.
+	malformedResponse
+	// The peer didn't respond.
+	failedRequest
+)
+
+type peerScore struct {
+	// Exponentially decaying average of the outcomes of requests sent to the
+	// peer, where a valid response is 1 and any other outcome is 0.
+	score       safemath.Averager
+	numRequests int
+	// Exponentially decaying average of the peer's response latency in
+	// seconds.
+	latency safemath.Averager
+}
+
+// peerScores scores peers based on the outcomes of the requests sent to them
+// and bans the peers whose score is too low.
+// It's safe for concurrent use.
+type peerScores struct {
+	lock sync.Mutex
+	// nodeID -> score of that peer.
+	// Banned peers are removed, so they start over once they're unbanned.
+	scores map[ids.NodeID]*peerScore
+	// nodeID -> time the peer's ban expires
+	bannedUntil map[ids.NodeID]time.Time
+
+	scoreMetric        *prometheus.GaugeVec
+	latencyMetric      *prometheus.GaugeVec
+	invalidProofs      *prometheus.CounterVec
+	malformedResponses *prometheus.CounterVec
+	failedRequests     *prometheus.CounterVec
+	numBannedPeers     prometheus.Gauge
+}
+
+func newPeerScores(
+	metricsNamespace string,
+	registerer prometheus.Registerer,
+) (*peerScores, error) {
+	s := &peerScores{
+		scores:      make(map[ids.NodeID]*peerScore),
+		bannedUntil: make(map[ids.NodeID]time.Time),
+		scoreMetric: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "peer_score",
+				Help:      "fraction of recent requests to a peer that returned a valid response",
+			},
+			[]string{nodeIDLabel},
+		),
+		latencyMetric: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "peer_latency",
+				Help:      "average time (in seconds) a peer takes to respond to a request",
+			},
+			[]string{nodeIDLabel},
+		),
+		invalidProofs: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "peer_invalid_proofs",
+				Help:      "number of responses from a peer that contained an invalid proof",
+			},
+			[]string{nodeIDLabel},
+		),
+		malformedResponses: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "peer_malformed_responses",
+				Help:      "number of responses from a peer that couldn't be parsed",
+			},
+			[]string{nodeIDLabel},
+		),
+		failedRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: metricsNamespace,
+				Name:      "peer_failed_requests",
+				Help:      "number of requests to a peer that failed",
+			},
+			[]string{nodeIDLabel},
+		),
+		numBannedPeers: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: metricsNamespace,
+				Name:      "num_banned_peers",
+				Help:      "number of peers banned for sending invalid responses",
+			},
+		),
+	}
+
+	err := utils.Err(
+		registerer.Register(s.scoreMetric),
+		registerer.Register(s.latencyMetric),
+		registerer.Register(s.invalidProofs),
+		registerer.Register(s.malformedResponses),
+		registerer.Register(s.failedRequests),
+		registerer.Register(s.numBannedPeers),
+	)
+	return s, err
+}
+
+// Observe records the [outcome] of a request sent to [nodeID].
+// Returns true if [nodeID] was banned as a result.
+func (s *peerScores) Observe(nodeID ids.NodeID, outcome requestOutcome) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.bannedUntil[nodeID]; ok {
+		// The outcome of a request sent before the ban is ignored.
+		return false
+	}
+
+	nodeIDStr := nodeID.String()
+	value := 0.
+	switch outcome {
+	case validResponse:
+		value = 1
+	case invalidProof:
+		s.invalidProofs.WithLabelValues(nodeIDStr).Inc()
+	case malformedResponse:
+		s.malformedResponses.WithLabelValues(nodeIDStr).Inc()
+	case failedRequest:
+		s.failedRequests.WithLabelValues(nodeIDStr).Inc()
+	}
+
+	now := time.Now()
+	score := s.getScore(nodeID)
+	if score.score == nil {
+		score.score = safemath.NewAverager(value, peerScoreHalflife, now)
+	} else {
+		score.score.Observe(value, now)
+	}
+	score.numRequests++
+	s.scoreMetric.WithLabelValues(nodeIDStr).Set(score.score.Read())
+
+	if score.numRequests < minRequestsToBan || score.score.Read() >= banScoreThreshold {
+		return false
+	}
+
+	delete(s.scores, nodeID)
+	s.bannedUntil[nodeID] = now.Add(banDuration)
+	s.numBannedPeers.Set(float64(len(s.bannedUntil)))
+	return true
+}
+
+// ObserveLatency records that [nodeID] took [latency] to respond to a
+// request.
+func (s *peerScores) ObserveLatency(nodeID ids.NodeID, latency time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.bannedUntil[nodeID]; ok {
+		return
+	}
+
+	now := time.Now()
+	score := s.getScore(nodeID)
+	if score.latency == nil {
+		score.latency = safemath.NewAverager(latency.Seconds(), peerScoreHalflife, now)
+	} else {
+		score.latency.Observe(latency.Seconds(), now)
+	}
+	s.latencyMetric.WithLabelValues(nodeID.String()).Set(score.latency.Read())
+}
+
+// Returns the score of [nodeID], creating it if needed.
+// Assumes [s.lock] is held.
+func (s *peerScores) getScore(nodeID ids.NodeID) *peerScore {
+	score, ok := s.scores[nodeID]
+	if !ok {
+		score = &peerScore{}
+		s.scores[nodeID] = score
+	}
+	return score
+}
+
+// IsBanned returns true if [nodeID] is banned.
+func (s *peerScores) IsBanned(nodeID ids.NodeID) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.bannedUntil[nodeID]
+	return ok
+}
+
+// Unban lifts the bans that expired before [now].
+// Returns the peers that were unbanned.
+func (s *peerScores) Unban(now time.Time) []ids.NodeID {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var unbanned []ids.NodeID
+	for nodeID, bannedUntil := range s.bannedUntil {
+		if now.Before(bannedUntil) {
+			continue
+		}
+		delete(s.bannedUntil, nodeID)
+		unbanned = append(unbanned, nodeID)
+	}
+	s.numBannedPeers.Set(float64(len(s.bannedUntil)))
+	return unbanned
+}
+
+// Disconnected removes the score and metrics of [nodeID].
+// A banned peer stays banned, so that it can't reconnect to evade its ban.
+func (s *peerScores) Disconnected(nodeID ids.NodeID) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.scores, nodeID)
+
+	nodeIDStr := nodeID.String()
+	s.scoreMetric.DeleteLabelValues(nodeIDStr)
+	s.latencyMetric.DeleteLabelValues(nodeIDStr)
+	s.invalidProofs.DeleteLabelValues(nodeIDStr)
+	s.malformedResponses.DeleteLabelValues(nodeIDStr)
+	s.failedRequests.DeleteLabelValues(nodeIDStr)
+}
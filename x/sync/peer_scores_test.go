@@ -0,0 +1,90 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func Test_PeerScores_Ban(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []requestOutcome
+		banned   bool
+	}{
+		{
+			name:     "valid responses",
+			outcomes: []requestOutcome{validResponse, validResponse, validResponse, validResponse, validResponse},
+			banned:   false,
+		},
+		{
+			name:     "too few requests",
+			outcomes: []requestOutcome{invalidProof, invalidProof, invalidProof, invalidProof},
+			banned:   false,
+		},
+		{
+			name:     "invalid proofs",
+			outcomes: []requestOutcome{validResponse, invalidProof, invalidProof, invalidProof, invalidProof},
+			banned:   true,
+		},
+		{
+			name:     "mixed failures",
+			outcomes: []requestOutcome{validResponse, malformedResponse, failedRequest, invalidProof, malformedResponse},
+			banned:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			scores, err := newPeerScores("", prometheus.NewRegistry())
+			require.NoError(err)
+
+			nodeID := ids.GenerateTestNodeID()
+			var banned bool
+			for _, outcome := range tt.outcomes {
+				banned = scores.Observe(nodeID, outcome)
+			}
+			require.Equal(tt.banned, banned)
+			require.Equal(tt.banned, scores.IsBanned(nodeID))
+
+			// Other peers aren't affected.
+			require.False(scores.IsBanned(ids.GenerateTestNodeID()))
+		})
+	}
+}
+
+func Test_PeerScores_Unban(t *testing.T) {
+	require := require.New(t)
+	scores, err := newPeerScores("", prometheus.NewRegistry())
+	require.NoError(err)
+
+	nodeID := ids.GenerateTestNodeID()
+	for i := 0; i < minRequestsToBan; i++ {
+		scores.Observe(nodeID, invalidProof)
+	}
+	require.True(scores.IsBanned(nodeID))
+
+	// Outcomes observed while banned are ignored.
+	require.False(scores.Observe(nodeID, invalidProof))
+
+	// Disconnecting doesn't lift the ban.
+	scores.Disconnected(nodeID)
+	require.True(scores.IsBanned(nodeID))
+
+	require.Empty(scores.Unban(time.Now()))
+	require.True(scores.IsBanned(nodeID))
+
+	require.Equal([]ids.NodeID{nodeID}, scores.Unban(time.Now().Add(banDuration)))
+	require.False(scores.IsBanned(nodeID))
+
+	// The peer's score starts over once it's unbanned.
+	require.False(scores.Observe(nodeID, invalidProof))
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"bytes"
+	"errors"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+var ErrEmptyPrioritizedPrefix = errors.New("prioritized prefix must not be empty")
+
+// keyRange is the range of keys [start, end].
+// Nothing [end] means there is no upper bound.
+type keyRange struct {
+	start []byte
+	end   maybe.Maybe[[]byte]
+}
+
+// newPrioritizedRanges returns the key ranges covered by [prefixes], sorted
+// by start key. Prefixes covered by another prefix are dropped, so the
+// returned ranges don't overlap.
+func newPrioritizedRanges(prefixes [][]byte) ([]keyRange, error) {
+	sortedPrefixes := make([][]byte, len(prefixes))
+	for i, prefix := range prefixes {
+		if len(prefix) == 0 {
+			return nil, ErrEmptyPrioritizedPrefix
+		}
+		sortedPrefixes[i] = slices.Clone(prefix)
+	}
+	slices.SortFunc(sortedPrefixes, func(a, b []byte) bool {
+		return bytes.Compare(a, b) < 0
+	})
+
+	ranges := make([]keyRange, 0, len(sortedPrefixes))
+	for _, prefix := range sortedPrefixes {
+		// A prefix sorts before all the keys it covers, so if [prefix] is
+		// covered by another prefix, it's covered by the last one added.
+		if len(ranges) > 0 && bytes.HasPrefix(prefix, ranges[len(ranges)-1].start) {
+			continue
+		}
+		ranges = append(ranges, keyRange{
+			start: prefix,
+			end:   prefixEnd(prefix),
+		})
+	}
+	return ranges, nil
+}
+
+// prefixEnd returns the smallest key greater than all the keys with
+// [prefix], or Nothing if there is no such key.
+func prefixEnd(prefix []byte) maybe.Maybe[[]byte] {
+	end := slices.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return maybe.Some(end[:i+1])
+		}
+	}
+	// [prefix] is all 0xff bytes.
+	return maybe.Nothing[[]byte]()
+}
+
+// insertPrioritizedWork queues [work] to be fetched, splitting it so that the
+// parts of it that overlap the prioritized ranges are fetched first.
+// Assumes [m.workLock] is held.
+func (m *Manager) insertPrioritizedWork(work *workItem) {
+	// Start of the part of [work] that hasn't been queued yet.
+	start := work.start
+	for _, r := range m.prioritizedRanges {
+		// The overlap of [work] and [r] is [overlapStart, overlapEnd].
+		overlapStart := r.start
+		if start.HasValue() && bytes.Compare(start.Value(), r.start) > 0 {
+			overlapStart = start.Value()
+		}
+		overlapEnd := r.end
+		if work.end.HasValue() && (r.end.IsNothing() || bytes.Compare(work.end.Value(), r.end.Value()) < 0) {
+			overlapEnd = work.end
+		}
+		if overlapEnd.HasValue() && bytes.Compare(overlapStart, overlapEnd.Value()) >= 0 {
+			// [work] doesn't overlap [r].
+			continue
+		}
+
+		if start.IsNothing() || bytes.Compare(start.Value(), overlapStart) < 0 {
+			m.unprocessedWork.Insert(newWorkItem(work.localRootID, start, maybe.Some(overlapStart), work.priority))
+		}
+		m.unprocessedWork.Insert(newWorkItem(work.localRootID, maybe.Some(overlapStart), overlapEnd, prioritizedPriority))
+		if overlapEnd.IsNothing() {
+			return
+		}
+		start = overlapEnd
+	}
+
+	// Nothing [start] is the lowest key while Nothing [work.end] is unbounded,
+	// so [work] only ends at [start] if [start] has a value.
+	if start.HasValue() && maybe.Equal(start, work.end, bytes.Equal) {
+		return
+	}
+	m.unprocessedWork.Insert(newWorkItem(work.localRootID, start, work.end, work.priority))
+}
+
+// PrioritizedRangesReady returns a channel that is closed once the keys with
+// the prefixes in [ManagerConfig.PrioritizedPrefixes] are synced to the
+// target root, so that subsystems that only depend on those keys can start
+// before the sync completes. Note that the keys are updated if the target
+// root changes after the channel is closed.
+// The channel is never closed if the sync fails before then.
+func (m *Manager) PrioritizedRangesReady() <-chan struct{} {
+	return m.prioritizedReady
+}
+
+// checkPrioritizedRangesReady closes [m.prioritizedReady] if every
+// prioritized range has been completed for the target root.
+// Assumes [m.syncTargetLock] and [m.workLock] are held.
+func (m *Manager) checkPrioritizedRangesReady() {
+	select {
+	case <-m.prioritizedReady:
+		return
+	default:
+	}
+
+	for _, r := range m.prioritizedRanges {
+		if !m.processedWork.Covers(maybe.Some(r.start), r.end, m.config.TargetRoot) {
+			return
+		}
+	}
+
+	m.config.Log.Info("prioritized ranges ready",
+		zap.Stringer("root", m.config.TargetRoot),
+		zap.Int("numPrioritizedRanges", len(m.prioritizedRanges)),
+	)
+	close(m.prioritizedReady)
+}
@@ -0,0 +1,172 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+
+	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
+)
+
+func TestNewPrioritizedRanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		prefixes    [][]byte
+		expected    []keyRange
+		expectedErr error
+	}{
+		{
+			name:     "no prefixes",
+			expected: []keyRange{},
+		},
+		{
+			name:        "empty prefix",
+			prefixes:    [][]byte{{1}, {}},
+			expectedErr: ErrEmptyPrioritizedPrefix,
+		},
+		{
+			name:     "sorted",
+			prefixes: [][]byte{{3}, {1, 2}},
+			expected: []keyRange{
+				{start: []byte{1, 2}, end: maybe.Some([]byte{1, 3})},
+				{start: []byte{3}, end: maybe.Some([]byte{4})},
+			},
+		},
+		{
+			name:     "covered prefixes dropped",
+			prefixes: [][]byte{{1, 2, 3}, {1, 2}, {1, 2}, {1, 3}},
+			expected: []keyRange{
+				{start: []byte{1, 2}, end: maybe.Some([]byte{1, 3})},
+				{start: []byte{1, 3}, end: maybe.Some([]byte{1, 4})},
+			},
+		},
+		{
+			name:     "trailing 0xff",
+			prefixes: [][]byte{{1, 0xff, 0xff}, {0xff}},
+			expected: []keyRange{
+				{start: []byte{1, 0xff, 0xff}, end: maybe.Some([]byte{2})},
+				{start: []byte{0xff}, end: maybe.Nothing[[]byte]()},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			ranges, err := newPrioritizedRanges(tt.prefixes)
+			require.ErrorIs(err, tt.expectedErr)
+			if tt.expectedErr != nil {
+				return
+			}
+			require.Equal(tt.expected, ranges)
+		})
+	}
+}
+
+func TestInsertPrioritizedWork(t *testing.T) {
+	require := require.New(t)
+
+	prioritizedRanges, err := newPrioritizedRanges([][]byte{{1}, {3}, {0xff}})
+	require.NoError(err)
+	m := &Manager{
+		unprocessedWork:   newWorkHeap(),
+		prioritizedRanges: prioritizedRanges,
+	}
+
+	// Splits [Nothing, 5] around the prioritized ranges it overlaps.
+	m.insertPrioritizedWork(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Some([]byte{5}), lowPriority))
+	// Only the end of [0xfe, Nothing] is prioritized.
+	m.insertPrioritizedWork(newWorkItem(ids.Empty, maybe.Some([]byte{0xfe}), maybe.Nothing[[]byte](), lowPriority))
+
+	expected := []*workItem{
+		newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Some([]byte{1}), lowPriority),
+		newWorkItem(ids.Empty, maybe.Some([]byte{1}), maybe.Some([]byte{2}), prioritizedPriority),
+		newWorkItem(ids.Empty, maybe.Some([]byte{2}), maybe.Some([]byte{3}), lowPriority),
+		newWorkItem(ids.Empty, maybe.Some([]byte{3}), maybe.Some([]byte{4}), prioritizedPriority),
+		newWorkItem(ids.Empty, maybe.Some([]byte{4}), maybe.Some([]byte{5}), lowPriority),
+		newWorkItem(ids.Empty, maybe.Some([]byte{0xfe}), maybe.Some([]byte{0xff}), lowPriority),
+		newWorkItem(ids.Empty, maybe.Some([]byte{0xff}), maybe.Nothing[[]byte](), prioritizedPriority),
+	}
+	require.Equal(expected, m.unprocessedWork.Items())
+}
+
+func TestInsertPrioritizedWorkWithoutPrefixes(t *testing.T) {
+	require := require.New(t)
+
+	m := &Manager{
+		unprocessedWork: newWorkHeap(),
+	}
+
+	// The entire key range is queued as is.
+	work := newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority)
+	m.insertPrioritizedWork(work)
+	require.Equal([]*workItem{work}, m.unprocessedWork.Items())
+}
+
+func TestSyncPrioritizedPrefixes(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+
+	prefix := []byte{0x80}
+	var requests []*pb.SyncGetRangeProofRequest
+	client := NewMockClient(ctrl)
+	client.EXPECT().GetRangeProof(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetRangeProofRequest) (*merkledb.RangeProof, error) {
+			requests = append(requests, request)
+			return dbToSync.GetRangeProofAtRoot(ctx, syncRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit), 0)
+		},
+	).MinTimes(1)
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 1,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		PrioritizedPrefixes:   [][]byte{prefix},
+	})
+	require.NoError(err)
+	require.NoError(syncer.Start(context.Background()))
+	require.NoError(syncer.Wait(context.Background()))
+
+	// The prioritized range is fetched first.
+	require.Equal(prefix, requests[0].StartKey.Value)
+	require.Equal([]byte{0x81}, requests[0].EndKey.Value)
+
+	select {
+	case <-syncer.PrioritizedRangesReady():
+	default:
+		require.FailNow("prioritized ranges should be ready")
+	}
+}
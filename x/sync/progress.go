@@ -0,0 +1,184 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"encoding/binary"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+// Progress is a snapshot of the progress of a sync.
+type Progress struct {
+	// Fraction, in [0, 1], of the keyspace whose key-value pairs are up to
+	// date with the target root. Decreases when the target root changes,
+	// until the ranges that were up to date are revalidated.
+	Completed float64
+	// Number of bytes of keys and values received in proofs.
+	// Proof nodes and message overhead aren't counted.
+	BytesDownloaded uint64
+	// Number of key ranges that are queued or being fetched.
+	RangesOutstanding int
+	// Estimated time until the sync completes, based on the rate at which
+	// the keyspace has been completed since the sync started.
+	// 0 if there hasn't been any progress to estimate from.
+	ETA time.Duration
+	// True if the sync isn't running anymore, because it completed, failed or
+	// was closed.
+	Done bool
+}
+
+// Progress returns the current progress of the sync.
+func (m *Manager) Progress() Progress {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	return m.progress()
+}
+
+// SubscribeProgress returns a channel that receives the progress of the sync
+// each time a key range is completed or the target root changes, along with a
+// function that ends the subscription.
+//
+// The channel holds only the latest progress, so a slow reader skips updates
+// rather than blocking the sync. The channel is closed once the sync is done,
+// after the final progress is sent.
+func (m *Manager) SubscribeProgress() (<-chan Progress, func()) {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	progressChan := make(chan Progress, 1)
+	progressChan <- m.progress()
+	if m.progressClosed {
+		close(progressChan)
+		return progressChan, func() {}
+	}
+
+	m.progressSubscribers.Add(progressChan)
+	return progressChan, func() {
+		m.progressLock.Lock()
+		defer m.progressLock.Unlock()
+
+		if m.progressSubscribers.Contains(progressChan) {
+			m.progressSubscribers.Remove(progressChan)
+			close(progressChan)
+		}
+	}
+}
+
+// Sends the current progress to the subscribers.
+// Assumes [m.workLock] isn't held.
+func (m *Manager) notifyProgress() {
+	m.workLock.Lock()
+	defer m.workLock.Unlock()
+
+	m.publishProgress(m.progress())
+}
+
+// Sends [progress] to the subscribers, replacing any progress they haven't
+// read yet.
+// Assumes [m.workLock] is held.
+func (m *Manager) publishProgress(progress Progress) {
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	for progressChan := range m.progressSubscribers {
+		select {
+		case <-progressChan:
+		default:
+		}
+		progressChan <- progress
+	}
+}
+
+// Sends the final progress to the subscribers and closes their channels.
+// Assumes [m.workLock] is held.
+func (m *Manager) closeProgress() {
+	m.publishProgress(m.progress())
+
+	m.progressLock.Lock()
+	defer m.progressLock.Unlock()
+
+	for progressChan := range m.progressSubscribers {
+		close(progressChan)
+	}
+	m.progressSubscribers.Clear()
+	m.progressClosed = true
+}
+
+// Assumes [m.workLock] is held.
+func (m *Manager) progress() Progress {
+	progress := Progress{
+		Completed:         m.completedFraction(),
+		BytesDownloaded:   atomic.LoadUint64(&m.bytesDownloaded),
+		RangesOutstanding: m.unprocessedWork.Len() + m.processingWorkItems,
+	}
+
+	select {
+	case <-m.doneChan:
+		progress.Done = true
+		return progress
+	default:
+	}
+
+	if !m.syncing {
+		return progress
+	}
+
+	// Only the progress made since the sync started, which excludes the
+	// ranges restored from a checkpoint, is used to estimate the rate.
+	completedSinceStart := progress.Completed - m.completedAtStart
+	if completedSinceStart > 0 {
+		elapsed := time.Since(m.startTime)
+		remaining := float64(elapsed) * (1 - progress.Completed) / completedSinceStart
+		if remaining < math.MaxInt64 {
+			progress.ETA = time.Duration(remaining)
+		} else {
+			progress.ETA = math.MaxInt64
+		}
+	}
+	return progress
+}
+
+// Returns the fraction of the keyspace covered by [m.processedWork].
+// Every range in [m.processedWork] was completed for the current target root.
+// Assumes [m.workLock] is held.
+func (m *Manager) completedFraction() float64 {
+	completed := 0.
+	for _, work := range m.processedWork.Items() {
+		completed += keyspacePosition(work.end, 1) - keyspacePosition(work.start, 0)
+	}
+	return math.Max(0, math.Min(completed, 1))
+}
+
+// Returns the approximate position, in [0, 1), of [key] in the keyspace,
+// based on its first 8 bytes. Returns [nothing] if [key] is Nothing.
+func keyspacePosition(key maybe.Maybe[[]byte], nothing float64) float64 {
+	if key.IsNothing() {
+		return nothing
+	}
+
+	var prefix [8]byte
+	copy(prefix[:], key.Value())
+	return float64(binary.BigEndian.Uint64(prefix[:])) / (1 << 64)
+}
+
+// Records that a proof with [keyValues] and [keyChanges] was received.
+func (m *Manager) trackDownloaded(keyValues []merkledb.KeyValue, keyChanges []merkledb.KeyChange) {
+	var numBytes int
+	for _, kv := range keyValues {
+		numBytes += len(kv.Key) + len(kv.Value)
+	}
+	for _, change := range keyChanges {
+		numBytes += len(change.Key) + len(change.Value.Value())
+	}
+	atomic.AddUint64(&m.bytesDownloaded, uint64(numBytes))
+}
@@ -0,0 +1,122 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+func TestKeyspacePosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      maybe.Maybe[[]byte]
+		nothing  float64
+		expected float64
+	}{
+		{
+			name:     "nothing start",
+			key:      maybe.Nothing[[]byte](),
+			nothing:  0,
+			expected: 0,
+		},
+		{
+			name:     "nothing end",
+			key:      maybe.Nothing[[]byte](),
+			nothing:  1,
+			expected: 1,
+		},
+		{
+			name:     "empty key",
+			key:      maybe.Some([]byte{}),
+			nothing:  1,
+			expected: 0,
+		},
+		{
+			name:     "half",
+			key:      maybe.Some([]byte{0x80}),
+			nothing:  1,
+			expected: 0.5,
+		},
+		{
+			name:     "quarter",
+			key:      maybe.Some([]byte{0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff}),
+			nothing:  1,
+			expected: 0.25,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, keyspacePosition(tt.key, tt.nothing))
+		})
+	}
+}
+
+func TestSyncProgress(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newCallthroughSyncClient(ctrl, dbToSync),
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+	})
+	require.NoError(err)
+
+	require.Equal(Progress{}, syncer.Progress())
+
+	progressChan, unsubscribe := syncer.SubscribeProgress()
+	defer unsubscribe()
+
+	require.NoError(syncer.Start(context.Background()))
+
+	// Progress is reported until the channel is closed.
+	var lastProgress Progress
+	for progress := range progressChan {
+		require.GreaterOrEqual(progress.BytesDownloaded, lastProgress.BytesDownloaded)
+		lastProgress = progress
+	}
+
+	require.NoError(syncer.Wait(context.Background()))
+	require.True(lastProgress.Done)
+	require.InDelta(1, lastProgress.Completed, 1e-9)
+	require.Positive(lastProgress.BytesDownloaded)
+	require.Zero(lastProgress.RangesOutstanding)
+	require.Equal(lastProgress, syncer.Progress())
+
+	// Subscribing once the sync is done returns the final progress.
+	progressChan, _ = syncer.SubscribeProgress()
+	require.Equal(lastProgress, <-progressChan)
+	_, ok := <-progressChan
+	require.False(ok)
+}
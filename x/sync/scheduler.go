@@ -0,0 +1,185 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+)
+
+var ErrZeroMaxActiveRequests = errors.New("max active requests must be greater than 0")
+
+type SchedulerConfig struct {
+	// The maximum number of work items processed at once by all the managers
+	// that share the scheduler. Each manager still processes at most its own
+	// [ManagerConfig.SimultaneousWorkLimit] work items at once.
+	SimultaneousWorkLimit int
+	// The maximum number of outstanding requests sent by all the network
+	// clients created by the scheduler.
+	MaxActiveRequests int64
+	// Limits the rate at which all the network clients created by the
+	// scheduler download from their peers.
+	Throttler ThrottlerConfig
+}
+
+// Scheduler shares work slots, outstanding requests and bandwidth between the
+// managers syncing several databases at once, such as the databases of
+// multiple chains, so that they don't compete for them blindly.
+//
+// A manager whose [ManagerConfig.Scheduler] is set must acquire a work slot
+// from the scheduler before processing each work item. While other managers
+// are waiting for a slot, a manager only gets a slot if it's processing fewer
+// than its fair share of the slots, which is the work limit divided by the
+// number of syncing managers. Otherwise, managers are free to use the slots
+// other managers don't need.
+//
+// Requests and bandwidth are shared by sending requests through network
+// clients created with [Scheduler.NewNetworkClient].
+type Scheduler struct {
+	workLimit      int
+	activeRequests *semaphore.Weighted
+	throttler      *throttler
+
+	lock sync.Mutex
+	// Managers that are syncing.
+	managers set.Set[*Manager]
+	// Managers that failed to acquire a work slot since they last acquired
+	// one.
+	waiting set.Set[*Manager]
+	// manager -> number of work slots it holds. Managers without slots are
+	// removed.
+	processing map[*Manager]int
+	// The number of work slots held by all managers.
+	inUse int
+}
+
+func NewScheduler(config SchedulerConfig) (*Scheduler, error) {
+	if config.SimultaneousWorkLimit <= 0 {
+		return nil, ErrZeroWorkLimit
+	}
+	if config.MaxActiveRequests <= 0 {
+		return nil, ErrZeroMaxActiveRequests
+	}
+	return &Scheduler{
+		workLimit:      config.SimultaneousWorkLimit,
+		activeRequests: semaphore.NewWeighted(config.MaxActiveRequests),
+		throttler:      newThrottler(config.Throttler),
+		processing:     make(map[*Manager]int),
+	}, nil
+}
+
+// NewNetworkClient returns a network client whose outstanding requests and
+// bandwidth are limited together with those of every other network client
+// created by [s]. See [NewNetworkClient].
+func (s *Scheduler) NewNetworkClient(
+	appSender common.AppSender,
+	myNodeID ids.NodeID,
+	log logging.Logger,
+	metricsNamespace string,
+	registerer prometheus.Registerer,
+) (NetworkClient, error) {
+	return newNetworkClient(
+		appSender,
+		myNodeID,
+		s.activeRequests,
+		s.throttler,
+		log,
+		metricsNamespace,
+		registerer,
+	)
+}
+
+// register records that [m] started syncing.
+func (s *Scheduler) register(m *Manager) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.managers.Add(m)
+}
+
+// unregister records that [m] stopped syncing. The slots held by [m] are
+// still held until they're released.
+func (s *Scheduler) unregister(m *Manager) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.managers.Remove(m)
+	s.waiting.Remove(m)
+	// The fair share of the other managers grew.
+	s.wakeWaiting()
+}
+
+// stopWaiting records that [m] isn't waiting for a work slot, even if its last
+// attempt to acquire one failed.
+func (s *Scheduler) stopWaiting(m *Manager) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.waiting.Remove(m)
+}
+
+// tryAcquire returns true iff [m] acquired a work slot. If it returns false,
+// [m] is woken up once it should try again.
+// Assumes [m.workLock] is held.
+func (s *Scheduler) tryAcquire(m *Manager) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	othersWaiting := s.waiting.Len() > 1 || (s.waiting.Len() == 1 && !s.waiting.Contains(m))
+	if s.inUse >= s.workLimit || (othersWaiting && s.processing[m] >= s.fairShare()) {
+		s.waiting.Add(m)
+		return false
+	}
+
+	s.waiting.Remove(m)
+	s.processing[m]++
+	s.inUse++
+	return true
+}
+
+// release returns a work slot acquired by [m].
+func (s *Scheduler) release(m *Manager) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.processing[m]--
+	if s.processing[m] <= 0 {
+		delete(s.processing, m)
+	}
+	s.inUse--
+	s.wakeWaiting()
+}
+
+// Returns the number of work slots each syncing manager is entitled to while
+// other managers are waiting for a slot.
+// Assumes [s.lock] is held.
+func (s *Scheduler) fairShare() int {
+	if s.managers.Len() == 0 {
+		return s.workLimit
+	}
+	share := s.workLimit / s.managers.Len()
+	if share < 1 {
+		return 1
+	}
+	return share
+}
+
+// Wakes up the managers waiting for a work slot so they try again.
+// The managers are woken up asynchronously because waking a manager requires
+// its [workLock], and the caller holds the [workLock] of some manager.
+// Assumes [s.lock] is held.
+func (s *Scheduler) wakeWaiting() {
+	for m := range s.waiting {
+		go m.wake()
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchedulerZeroWorkLimit(t *testing.T) {
+	_, err := NewScheduler(SchedulerConfig{})
+	require.ErrorIs(t, err, ErrZeroWorkLimit)
+}
+
+func TestNewSchedulerZeroMaxActiveRequests(t *testing.T) {
+	_, err := NewScheduler(SchedulerConfig{
+		SimultaneousWorkLimit: 1,
+	})
+	require.ErrorIs(t, err, ErrZeroMaxActiveRequests)
+}
+
+func TestSchedulerFairShare(t *testing.T) {
+	require := require.New(t)
+
+	s, err := NewScheduler(SchedulerConfig{
+		SimultaneousWorkLimit: 4,
+		MaxActiveRequests:     1,
+	})
+	require.NoError(err)
+
+	m1 := &Manager{}
+	m2 := &Manager{}
+	s.register(m1)
+	s.register(m2)
+
+	// While no one else is waiting, a manager may use more than its fair
+	// share.
+	for i := 0; i < 3; i++ {
+		require.True(s.tryAcquire(m1))
+	}
+	require.True(s.tryAcquire(m2))
+
+	// Every slot is in use.
+	require.False(s.tryAcquire(m1))
+	require.False(s.tryAcquire(m2))
+
+	// [m1] is using more than its fair share while [m2] is waiting, so the
+	// released slot goes to [m2].
+	s.release(m1)
+	require.False(s.tryAcquire(m1))
+	require.True(s.tryAcquire(m2))
+
+	// Once [m2] stops syncing, [m1]'s fair share is every slot.
+	s.release(m2)
+	s.release(m2)
+	s.unregister(m2)
+	require.True(s.tryAcquire(m1))
+	require.True(s.tryAcquire(m1))
+	require.Equal(4, s.inUse)
+	require.Equal(4, s.processing[m1])
+	// [m1] and [m2] are deeply equal, so look [m2] up by pointer rather
+	// than with require.NotContains.
+	_, ok := s.processing[m2]
+	require.False(ok)
+}
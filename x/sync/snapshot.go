@@ -0,0 +1,190 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+const (
+	snapshotVersion uint16 = 0
+	// Number of bytes of key-value pairs written to the database at once when
+	// importing a snapshot.
+	snapshotBatchSize = units.MiB
+)
+
+var (
+	ErrInvalidSnapshot      = errors.New("invalid snapshot")
+	ErrSnapshotRootMismatch = errors.New("snapshot root mismatch")
+
+	errSnapshotDBChanged = errors.New("database changed while the snapshot was exported")
+)
+
+// ExportSnapshot writes the key-value pairs in [db] to [w], along with the
+// root of [db], so that they can be imported with [ImportSnapshot].
+// [db] must not be modified while the snapshot is exported.
+//
+// The snapshot is encoded as:
+//   - The snapshot version, as a big endian uint16.
+//   - The root of [db].
+//   - Each key-value pair, in order of increasing key, as the uvarint length
+//     of the key, the key, the uvarint length of the value, and the value.
+func ExportSnapshot(ctx context.Context, db merkledb.MerkleDB, w io.Writer) error {
+	root, err := db.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+	if err := binary.Write(writer, binary.BigEndian, snapshotVersion); err != nil {
+		return err
+	}
+	if _, err := writer.Write(root[:]); err != nil {
+		return err
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	var lenBytes [binary.MaxVarintLen64]byte
+	for it.Next() {
+		for _, b := range [][]byte{it.Key(), it.Value()} {
+			n := binary.PutUvarint(lenBytes[:], uint64(len(b)))
+			if _, err := writer.Write(lenBytes[:n]); err != nil {
+				return err
+			}
+			if _, err := writer.Write(b); err != nil {
+				return err
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	// The iterator doesn't read from a consistent view of [db], so make sure
+	// that the exported key-value pairs match [root].
+	newRoot, err := db.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if newRoot != root {
+		return fmt.Errorf("%w: root changed from %s to %s", errSnapshotDBChanged, root, newRoot)
+	}
+	return writer.Flush()
+}
+
+// ImportSnapshot writes the key-value pairs in the snapshot read from [r],
+// which was written by [ExportSnapshot], to [db], which should be empty.
+// Returns [ErrSnapshotRootMismatch] if the root of [db] doesn't match the
+// root recorded in the snapshot once the key-value pairs are written.
+//
+// Returns a checkpoint recording that [db] contains the trie at the root of
+// the snapshot. Passing it as [ManagerConfig.Checkpoint] seeds the sync with
+// the snapshot, so that only the key ranges that differ between the snapshot
+// and the target root are fetched.
+func ImportSnapshot(ctx context.Context, db merkledb.MerkleDB, r io.Reader, log logging.Logger) (*Checkpoint, error) {
+	reader := bufio.NewReader(r)
+
+	var version uint16
+	if err := binary.Read(reader, binary.BigEndian, &version); err != nil {
+		return nil, fmt.Errorf("%w: couldn't read version: %w", ErrInvalidSnapshot, err)
+	}
+	if version != snapshotVersion {
+		return nil, fmt.Errorf("%w: unknown version %d", ErrInvalidSnapshot, version)
+	}
+	var root ids.ID
+	if _, err := io.ReadFull(reader, root[:]); err != nil {
+		return nil, fmt.Errorf("%w: couldn't read root: %w", ErrInvalidSnapshot, err)
+	}
+
+	var (
+		batch   = db.NewBatch()
+		numKeys int
+	)
+	for {
+		key, err := readSnapshotBytes(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: couldn't read key %d: %w", ErrInvalidSnapshot, numKeys, err)
+		}
+		value, err := readSnapshotBytes(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%w: couldn't read value %d: %w", ErrInvalidSnapshot, numKeys, err)
+		}
+
+		if err := batch.Put(key, value); err != nil {
+			return nil, err
+		}
+		numKeys++
+		if batch.Size() < snapshotBatchSize {
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			return nil, err
+		}
+		batch.Reset()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+
+	dbRoot, err := db.GetMerkleRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dbRoot != root {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrSnapshotRootMismatch, root, dbRoot)
+	}
+
+	log.Info("imported sync snapshot",
+		zap.Stringer("root", root),
+		zap.Int("numKeys", numKeys),
+	)
+	return &Checkpoint{
+		TargetRoot: root,
+		CompletedRanges: []CompletedRange{{
+			Start:  maybe.Nothing[[]byte](),
+			End:    maybe.Nothing[[]byte](),
+			RootID: root,
+		}},
+	}, nil
+}
+
+// Reads a length-prefixed byte slice written by [ExportSnapshot].
+// Returns [io.EOF] if there are no more bytes to read.
+func readSnapshotBytes(reader *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	if length > constants.DefaultMaxMessageSize {
+		return nil, fmt.Errorf("length %d exceeds maximum of %d", length, constants.DefaultMaxMessageSize)
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
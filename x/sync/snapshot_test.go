@@ -0,0 +1,127 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+func TestSnapshotSeedsSync(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	snapshotRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	snapshot := &bytes.Buffer{}
+	require.NoError(ExportSnapshot(context.Background(), dbToSync, snapshot))
+
+	// Modify [dbToSync] after the snapshot was exported.
+	for i := 0; i < 10; i++ {
+		key := make([]byte, r.Intn(50))
+		_, err = r.Read(key)
+		require.NoError(err)
+
+		val := make([]byte, r.Intn(50))
+		_, err = r.Read(val)
+		require.NoError(err)
+
+		require.NoError(dbToSync.Put(key, val))
+	}
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+	checkpoint, err := ImportSnapshot(context.Background(), db, snapshot, logging.NoLog{})
+	require.NoError(err)
+	require.Equal(snapshotRoot, checkpoint.TargetRoot)
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(snapshotRoot, root)
+
+	// Only the changes since the snapshot are fetched.
+	client := NewMockClient(ctrl)
+	client.EXPECT().GetChangeProof(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		newCallthroughSyncClient(ctrl, dbToSync).GetChangeProof,
+	).MinTimes(1)
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		Checkpoint:            checkpoint,
+	})
+	require.NoError(err)
+	require.NoError(syncer.Start(context.Background()))
+	require.NoError(syncer.Wait(context.Background()))
+
+	root, err = db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(syncRoot, root)
+}
+
+func TestImportInvalidSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(1)) // #nosec G404
+	dbToSync, err := generateTrie(t, r, 100)
+	require.NoError(err)
+
+	snapshot := &bytes.Buffer{}
+	require.NoError(ExportSnapshot(context.Background(), dbToSync, snapshot))
+	snapshotBytes := snapshot.Bytes()
+
+	newDB := func() merkledb.MerkleDB {
+		db, err := merkledb.New(
+			context.Background(),
+			memdb.New(),
+			newDefaultDBConfig(),
+		)
+		require.NoError(err)
+		return db
+	}
+
+	// Changing the recorded root makes the snapshot fail verification.
+	modifiedSnapshot := bytes.Clone(snapshotBytes)
+	modifiedSnapshot[2] ^= 1
+	_, err = ImportSnapshot(context.Background(), newDB(), bytes.NewReader(modifiedSnapshot), logging.NoLog{})
+	require.ErrorIs(err, ErrSnapshotRootMismatch)
+
+	// A truncated snapshot is invalid.
+	_, err = ImportSnapshot(context.Background(), newDB(), bytes.NewReader(snapshotBytes[:len(snapshotBytes)-1]), logging.NoLog{})
+	require.ErrorIs(err, ErrInvalidSnapshot)
+
+	// A snapshot with an unknown version is invalid.
+	modifiedSnapshot = bytes.Clone(snapshotBytes)
+	modifiedSnapshot[1] = 1
+	_, err = ImportSnapshot(context.Background(), newDB(), bytes.NewReader(modifiedSnapshot), logging.NoLog{})
+	require.ErrorIs(err, ErrInvalidSnapshot)
+}
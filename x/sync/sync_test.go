@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"math/rand"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/x/merkledb"
 
 	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
@@ -35,6 +37,7 @@ func newCallthroughSyncClient(ctrl *gomock.Controller, db merkledb.MerkleDB) *Mo
 				maybeBytesToMaybe(request.StartKey),
 				maybeBytesToMaybe(request.EndKey),
 				int(request.KeyLimit),
+				0,
 			)
 		}).AnyTimes()
 	syncClient.EXPECT().GetChangeProof(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
@@ -227,7 +230,7 @@ func Test_Sync_FindNextKey_InSync(t *testing.T) {
 	require.NoError(syncer.Start(context.Background()))
 	require.NoError(syncer.Wait(context.Background()))
 
-	proof, err := dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 500)
+	proof, err := dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 500, 0)
 	require.NoError(err)
 
 	// the two dbs should be in sync, so next key should be nil
@@ -421,7 +424,7 @@ func Test_Sync_FindNextKey_ExtraValues(t *testing.T) {
 	require.NoError(syncer.Start(context.Background()))
 	require.NoError(syncer.Wait(context.Background()))
 
-	proof, err := dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 500)
+	proof, err := dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 500, 0)
 	require.NoError(err)
 
 	// add an extra value to local db
@@ -442,7 +445,7 @@ func Test_Sync_FindNextKey_ExtraValues(t *testing.T) {
 
 	require.NoError(dbToSync.Put(midPointVal, []byte{1}))
 
-	proof, err = dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Some(lastKey), 500)
+	proof, err = dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Some(lastKey), 500, 0)
 	require.NoError(err)
 
 	// next key at prefix of newly added point
@@ -550,7 +553,7 @@ func Test_Sync_FindNextKey_DifferentChild(t *testing.T) {
 	require.NoError(syncer.Start(context.Background()))
 	require.NoError(syncer.Wait(context.Background()))
 
-	proof, err := dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+	proof, err := dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100, 0)
 	require.NoError(err)
 	lastKey := proof.KeyValues[len(proof.KeyValues)-1].Key
 
@@ -560,7 +563,7 @@ func Test_Sync_FindNextKey_DifferentChild(t *testing.T) {
 
 	require.NoError(dbToSync.Put(lastKey, []byte{2}))
 
-	proof, err = dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Some(proof.KeyValues[len(proof.KeyValues)-1].Key), 100)
+	proof, err = dbToSync.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Some(proof.KeyValues[len(proof.KeyValues)-1].Key), 100, 0)
 	require.NoError(err)
 
 	nextKey, err := syncer.findNextKey(context.Background(), proof.KeyValues[len(proof.KeyValues)-1].Key, maybe.Nothing[[]byte](), proof.EndProof)
@@ -645,6 +648,7 @@ func TestFindNextKeyRandom(t *testing.T) {
 			startKey,
 			endKey,
 			rand.Intn(maxProofLen)+1,
+			0,
 		)
 		require.NoError(err)
 
@@ -846,6 +850,58 @@ func Test_Sync_Result_Correct_Root(t *testing.T) {
 	require.Equal(syncRoot, newRoot)
 }
 
+func TestSyncRequestLimits(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+	dbToSync, err := generateTrie(t, r, 1000)
+	require.NoError(err)
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+
+	const (
+		keyLimit   = 100
+		bytesLimit = 10 * units.KiB
+	)
+	client := NewMockClient(ctrl)
+	client.EXPECT().GetRangeProof(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetRangeProofRequest) (*merkledb.RangeProof, error) {
+			// The preferred limits are sent with every request.
+			require.Equal(uint32(keyLimit), request.KeyLimit)
+			require.Equal(uint32(bytesLimit), request.BytesLimit)
+			return dbToSync.GetRangeProofAtRoot(ctx, syncRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit), 0)
+		},
+	).MinTimes(1)
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		RequestKeyLimit:       keyLimit,
+		RequestByteSizeLimit:  bytesLimit,
+	})
+	require.NoError(err)
+	require.NoError(syncer.Start(context.Background()))
+	require.NoError(syncer.Wait(context.Background()))
+
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(syncRoot, newRoot)
+}
+
 func Test_Sync_Result_Correct_Root_With_Sync_Restart(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -1030,7 +1086,7 @@ func Test_Sync_Result_Correct_Root_Update_Root_During(t *testing.T) {
 			<-updatedRootChan
 			root, err := ids.ToID(request.RootHash)
 			require.NoError(err)
-			return dbToSync.GetRangeProofAtRoot(ctx, root, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit))
+			return dbToSync.GetRangeProofAtRoot(ctx, root, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit), 0)
 		},
 	).AnyTimes()
 	client.EXPECT().GetChangeProof(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
@@ -1136,6 +1192,56 @@ func Test_Sync_UpdateSyncTarget(t *testing.T) {
 	require.Equal(1, m.unprocessedWork.Len())
 }
 
+func Test_Sync_UpdateSyncTarget_ReconcilesStaleRanges(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	m, err := NewManager(ManagerConfig{
+		DB:                    merkledb.NewMockMerkleDB(ctrl), // Not used
+		Client:                NewMockClient(ctrl),            // Not used
+		TargetRoot:            ids.GenerateTestID(),
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+	})
+	require.NoError(err)
+
+	var (
+		oldSyncRoot = m.config.TargetRoot
+		staleRoot   = ids.GenerateTestID()
+		newSyncRoot = ids.GenerateTestID()
+	)
+
+	// Completed for the current target root.
+	m.processedWork.Insert(newWorkItem(oldSyncRoot, maybe.Nothing[[]byte](), maybe.Some([]byte{1}), lowPriority))
+	// Adjacent ranges queued to be revalidated from the same stale root.
+	m.unprocessedWork.Insert(newWorkItem(staleRoot, maybe.Some([]byte{1}), maybe.Some([]byte{2}), medPriority))
+	m.unprocessedWork.Insert(newWorkItem(staleRoot, maybe.Some([]byte{2}), maybe.Some([]byte{3}), lowPriority))
+	// Queued to be revalidated from the new target root.
+	m.unprocessedWork.Insert(newWorkItem(newSyncRoot, maybe.Some([]byte{3}), maybe.Some([]byte{4}), highPriority))
+	// Not fetched yet.
+	m.unprocessedWork.Insert(newWorkItem(ids.Empty, maybe.Some([]byte{4}), maybe.Nothing[[]byte](), lowPriority))
+
+	require.NoError(m.UpdateSyncTarget(newSyncRoot))
+
+	// The range for the new target root is already up to date.
+	require.Equal(
+		[]*workItem{
+			newWorkItem(newSyncRoot, maybe.Some([]byte{3}), maybe.Some([]byte{4}), highPriority),
+		},
+		m.processedWork.Items(),
+	)
+	// The stale ranges are revalidated with one change proof per root.
+	require.Equal(
+		[]*workItem{
+			newWorkItem(oldSyncRoot, maybe.Nothing[[]byte](), maybe.Some([]byte{1}), highPriority),
+			newWorkItem(staleRoot, maybe.Some([]byte{1}), maybe.Some([]byte{3}), highPriority),
+			newWorkItem(ids.Empty, maybe.Some([]byte{4}), maybe.Nothing[[]byte](), lowPriority),
+		},
+		m.unprocessedWork.Items(),
+	)
+}
+
 func generateTrie(t *testing.T, r *rand.Rand, count int) (merkledb.MerkleDB, error) {
 	db, _, err := generateTrieWithMinKeyLen(t, r, count, 0)
 	return db, err
@@ -1199,3 +1305,62 @@ func generateTrieWithMinKeyLen(t *testing.T, r *rand.Rand, count int, minKeyLen
 	})
 	return db, allKeys, batch.Write()
 }
+
+func Test_Sync_PauseResume(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	dbToSync, err := generateTrie(t, r, 1000)
+	require.NoError(err)
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+
+	var numRequests atomic.Int64
+	client := NewMockClient(ctrl)
+	client.EXPECT().GetRangeProof(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetRangeProofRequest) (*merkledb.RangeProof, error) {
+			numRequests.Add(1)
+			return dbToSync.GetRangeProofAtRoot(ctx, syncRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit), 0)
+		},
+	).AnyTimes()
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+	})
+	require.NoError(err)
+
+	syncer.Pause()
+	require.True(syncer.Paused())
+	require.NoError(syncer.Start(context.Background()))
+
+	// No work is started while the sync is paused.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(syncer.Wait(ctx), context.DeadlineExceeded)
+	require.Zero(numRequests.Load())
+
+	syncer.Resume()
+	require.False(syncer.Paused())
+	require.NoError(syncer.Wait(context.Background()))
+	require.Positive(numRequests.Load())
+
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(syncRoot, newRoot)
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/math"
+)
+
+// ThrottlerConfig limits the rate at which the sync client downloads from its
+// peers, so that syncing doesn't saturate links shared with consensus traffic.
+// A limit of 0 means that the rate is unlimited.
+type ThrottlerConfig struct {
+	// Maximum number of response bytes per second received from all peers.
+	BytesPerSec uint64
+	// Maximum number of response bytes per second received from any one peer.
+	PeerBytesPerSec uint64
+	// Maximum number of requests per second sent to all peers.
+	RequestsPerSec float64
+	// Maximum number of requests per second sent to any one peer.
+	PeerRequestsPerSec float64
+}
+
+// throttler enforces a [ThrottlerConfig].
+//
+// The size of a response isn't known until it's received, so bytes are
+// charged after the fact. A request isn't sent until the bytes charged for
+// previous responses have been paid off.
+type throttler struct {
+	config ThrottlerConfig
+
+	// nil if the corresponding limit is unlimited
+	requestLimiter *rate.Limiter
+	byteLimiter    *rate.Limiter
+
+	lock sync.Mutex
+	// nodeID -> limiter for that peer
+	peerRequestLimiters map[ids.NodeID]*rate.Limiter
+	peerByteLimiters    map[ids.NodeID]*rate.Limiter
+}
+
+func newThrottler(config ThrottlerConfig) *throttler {
+	return &throttler{
+		config:              config,
+		requestLimiter:      newRequestLimiter(config.RequestsPerSec),
+		byteLimiter:         newByteLimiter(config.BytesPerSec),
+		peerRequestLimiters: make(map[ids.NodeID]*rate.Limiter),
+		peerByteLimiters:    make(map[ids.NodeID]*rate.Limiter),
+	}
+}
+
+func newRequestLimiter(requestsPerSec float64) *rate.Limiter {
+	if requestsPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSec), 1)
+}
+
+// The burst must be at least as large as the largest response, since a
+// response can't be charged for more than the burst.
+//
+// The limiter starts empty so that the bytes charged for a response must be
+// paid off before the next request is sent, rather than being absorbed by the
+// initial burst.
+func newByteLimiter(bytesPerSec uint64) *rate.Limiter {
+	if bytesPerSec == 0 {
+		return nil
+	}
+	burst := int(math.Max(bytesPerSec, constants.DefaultMaxMessageSize))
+	limiter := rate.NewLimiter(rate.Limit(bytesPerSec), burst)
+	limiter.ReserveN(time.Now(), burst)
+	return limiter
+}
+
+// Acquire blocks until a request can be sent to [nodeID].
+// Returns an error if [ctx] is canceled first.
+func (t *throttler) Acquire(ctx context.Context, nodeID ids.NodeID) error {
+	peerRequestLimiter, peerByteLimiter := t.getPeerLimiters(nodeID)
+
+	// Waiting for 0 bytes blocks until the bytes charged for previous
+	// responses have been paid off.
+	for _, wait := range []struct {
+		limiter *rate.Limiter
+		n       int
+	}{
+		{limiter: t.byteLimiter, n: 0},
+		{limiter: peerByteLimiter, n: 0},
+		{limiter: t.requestLimiter, n: 1},
+		{limiter: peerRequestLimiter, n: 1},
+	} {
+		if wait.limiter == nil {
+			continue
+		}
+		if err := wait.limiter.WaitN(ctx, wait.n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Consume charges [numBytes] received from [nodeID] against the byte limits.
+func (t *throttler) Consume(nodeID ids.NodeID, numBytes int) {
+	_, peerByteLimiter := t.getPeerLimiters(nodeID)
+
+	now := time.Now()
+	for _, limiter := range []*rate.Limiter{t.byteLimiter, peerByteLimiter} {
+		if limiter == nil {
+			continue
+		}
+		limiter.ReserveN(now, math.Min(numBytes, limiter.Burst()))
+	}
+}
+
+// Disconnected removes the limiters of [nodeID].
+func (t *throttler) Disconnected(nodeID ids.NodeID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.peerRequestLimiters, nodeID)
+	delete(t.peerByteLimiters, nodeID)
+}
+
+// Returns the request and byte limiters of [nodeID], creating them if needed.
+// Either limiter is nil if the corresponding limit is unlimited.
+func (t *throttler) getPeerLimiters(nodeID ids.NodeID) (*rate.Limiter, *rate.Limiter) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	requestLimiter, ok := t.peerRequestLimiters[nodeID]
+	if !ok {
+		requestLimiter = newRequestLimiter(t.config.PeerRequestsPerSec)
+		t.peerRequestLimiters[nodeID] = requestLimiter
+	}
+	byteLimiter, ok := t.peerByteLimiters[nodeID]
+	if !ok {
+		byteLimiter = newByteLimiter(t.config.PeerBytesPerSec)
+		t.peerByteLimiters[nodeID] = byteLimiter
+	}
+	return requestLimiter, byteLimiter
+}
@@ -0,0 +1,138 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+)
+
+// Returns a context that is canceled long before a throttled request could
+// be sent.
+func shortContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func Test_Throttler_Unlimited(t *testing.T) {
+	require := require.New(t)
+	throttler := newThrottler(ThrottlerConfig{})
+
+	nodeID := ids.GenerateTestNodeID()
+	for i := 0; i < 100; i++ {
+		require.NoError(throttler.Acquire(shortContext(t), nodeID))
+		throttler.Consume(nodeID, constants.DefaultMaxMessageSize)
+	}
+}
+
+func Test_Throttler_Requests(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ThrottlerConfig
+		// true if a request to another peer is throttled
+		globallyThrottled bool
+	}{
+		{
+			name: "global",
+			config: ThrottlerConfig{
+				RequestsPerSec: 1,
+			},
+			globallyThrottled: true,
+		},
+		{
+			name: "per peer",
+			config: ThrottlerConfig{
+				PeerRequestsPerSec: 1,
+			},
+			globallyThrottled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			throttler := newThrottler(tt.config)
+
+			nodeID := ids.GenerateTestNodeID()
+			require.NoError(throttler.Acquire(shortContext(t), nodeID))
+			require.Error(throttler.Acquire(shortContext(t), nodeID)) //nolint:forbidigo // the error is returned by the rate package
+
+			err := throttler.Acquire(shortContext(t), ids.GenerateTestNodeID())
+			if tt.globallyThrottled {
+				require.Error(err) //nolint:forbidigo // the error is returned by the rate package
+			} else {
+				require.NoError(err)
+			}
+		})
+	}
+}
+
+func Test_Throttler_Bytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ThrottlerConfig
+		// true if a request to another peer is throttled
+		globallyThrottled bool
+	}{
+		{
+			name: "global",
+			config: ThrottlerConfig{
+				BytesPerSec: 1024,
+			},
+			globallyThrottled: true,
+		},
+		{
+			name: "per peer",
+			config: ThrottlerConfig{
+				PeerBytesPerSec: 1024,
+			},
+			globallyThrottled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			throttler := newThrottler(tt.config)
+
+			// The first response can't be throttled before it's received.
+			nodeID := ids.GenerateTestNodeID()
+			require.NoError(throttler.Acquire(shortContext(t), nodeID))
+			throttler.Consume(nodeID, constants.DefaultMaxMessageSize)
+
+			// Following requests wait until the response is paid off.
+			require.Error(throttler.Acquire(shortContext(t), nodeID)) //nolint:forbidigo // the error is returned by the rate package
+
+			err := throttler.Acquire(shortContext(t), ids.GenerateTestNodeID())
+			if tt.globallyThrottled {
+				require.Error(err) //nolint:forbidigo // the error is returned by the rate package
+			} else {
+				require.NoError(err)
+			}
+		})
+	}
+}
+
+func Test_Throttler_Disconnected(t *testing.T) {
+	require := require.New(t)
+	throttler := newThrottler(ThrottlerConfig{
+		PeerRequestsPerSec: 1,
+	})
+
+	nodeID := ids.GenerateTestNodeID()
+	require.NoError(throttler.Acquire(shortContext(t), nodeID))
+	require.Len(throttler.peerRequestLimiters, 1)
+
+	throttler.Disconnected(nodeID)
+	require.Empty(throttler.peerRequestLimiters)
+	require.Empty(throttler.peerByteLimiters)
+
+	// The peer's limit is reset once it's disconnected.
+	require.NoError(throttler.Acquire(shortContext(t), nodeID))
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/version"
+)
+
+const protobufContentType = "application/x-protobuf"
+
+var (
+	_ Transport = (*p2pTransport)(nil)
+	_ Transport = (*HTTPTransport)(nil)
+
+	errUnexpectedStatusCode = errors.New("unexpected status code")
+	errResponseTooLarge     = errors.New("response too large")
+)
+
+// Transport sends proof requests to a server and returns its responses.
+// Implementations must be safe to call concurrently.
+type Transport interface {
+	// Send sends [request], a serialized [pb.Request], and blocks until
+	// a response is received or [ctx] is canceled.
+	// Returns the ID of the node that responded and the serialized
+	// [pb.SyncGetRangeProofResponse] or [pb.SyncGetChangeProofResponse].
+	// The request is retried if an error is returned.
+	Send(ctx context.Context, request []byte) (ids.NodeID, []byte, error)
+
+	// TrackResponse records whether the response from [nodeID] was valid.
+	// [err] is the error returned when parsing or verifying the
+	// response, or nil if the response was valid.
+	TrackResponse(nodeID ids.NodeID, err error)
+}
+
+// p2pTransport sends requests to peers as AppRequests.
+type p2pTransport struct {
+	networkClient       NetworkClient
+	stateSyncNodes      []ids.NodeID
+	stateSyncNodeIdx    uint32
+	stateSyncMinVersion *version.Application
+}
+
+// Sends [request] to the next of [t.stateSyncNodes] if any were provided.
+// Otherwise, sends [request] to an arbitrary peer.
+func (t *p2pTransport) Send(ctx context.Context, request []byte) (ids.NodeID, []byte, error) {
+	if len(t.stateSyncNodes) == 0 {
+		return t.networkClient.RequestAny(ctx, t.stateSyncMinVersion, request)
+	}
+
+	// Get the next nodeID to query using the [nodeIdx] offset.
+	// If we're out of nodes, loop back to 0.
+	// We do this try to query a different node each time if possible.
+	nodeIdx := atomic.AddUint32(&t.stateSyncNodeIdx, 1)
+	nodeID := t.stateSyncNodes[nodeIdx%uint32(len(t.stateSyncNodes))]
+	response, err := t.networkClient.Request(ctx, nodeID, request)
+	return nodeID, response, err
+}
+
+func (t *p2pTransport) TrackResponse(nodeID ids.NodeID, err error) {
+	t.networkClient.TrackResponse(nodeID, err)
+}
+
+// HTTPTransport sends requests to a server, such as a snapshot service,
+// over HTTP(S).
+//
+// Each request is POSTed to the server's URL with the serialized [pb.Request]
+// as the body, and the server responds with the serialized proof, exactly as
+// a peer would respond to an AppRequest.
+type HTTPTransport struct {
+	url    string
+	client *http.Client
+	// The ID reported as the responder of each response
+	nodeID ids.NodeID
+}
+
+// NewHTTPTransport returns a transport that sends requests to [url] using
+// [client]. If [client] is nil, [http.DefaultClient] is used.
+// Responses are attributed to [nodeID], which may be [ids.EmptyNodeID].
+func NewHTTPTransport(url string, client *http.Client, nodeID ids.NodeID) *HTTPTransport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPTransport{
+		url:    url,
+		client: client,
+		nodeID: nodeID,
+	}
+}
+
+func (t *HTTPTransport) Send(ctx context.Context, request []byte) (ids.NodeID, []byte, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(request))
+	if err != nil {
+		return t.nodeID, nil, err
+	}
+	httpRequest.Header.Set("Content-Type", protobufContentType)
+
+	httpResponse, err := t.client.Do(httpRequest)
+	if err != nil {
+		return t.nodeID, nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return t.nodeID, nil, fmt.Errorf("%w: %d", errUnexpectedStatusCode, httpResponse.StatusCode)
+	}
+
+	// Responses are limited to the size of a p2p message, so that the
+	// server can't make us read an arbitrarily large response.
+	response, err := io.ReadAll(io.LimitReader(httpResponse.Body, constants.DefaultMaxMessageSize+1))
+	if err != nil {
+		return t.nodeID, nil, err
+	}
+	if len(response) > constants.DefaultMaxMessageSize {
+		return t.nodeID, nil, fmt.Errorf("%w: > %d bytes", errResponseTooLarge, constants.DefaultMaxMessageSize)
+	}
+	return t.nodeID, response, nil
+}
+
+// TrackResponse is a no-op because there's only one server to request from.
+func (*HTTPTransport) TrackResponse(ids.NodeID, error) {}
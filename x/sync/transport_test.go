@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/common"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+
+	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
+)
+
+func TestHTTPTransportGetRangeProof(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	r := rand.New(rand.NewSource(1)) // #nosec G404
+	serverDB, err := generateTrie(t, r, defaultRequestKeyLimit)
+	require.NoError(err)
+	serverRoot, err := serverDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Serves proofs over HTTP by passing each request to [server] and
+	// writing the AppResponse it sends.
+	responseChan := make(chan []byte, 1)
+	sender := common.NewMockSender(ctrl)
+	sender.EXPECT().SendAppResponse(
+		gomock.Any(), // ctx
+		gomock.Any(), // nodeID
+		gomock.Any(), // requestID
+		gomock.Any(), // responseBytes
+	).DoAndReturn(
+		func(_ context.Context, _ ids.NodeID, _ uint32, responseBytes []byte) error {
+			responseChan <- responseBytes
+			return nil
+		},
+	).AnyTimes()
+	server := NewNetworkServer(sender, serverDB, logging.NoLog{})
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		request, err := io.ReadAll(req.Body)
+		require.NoError(err)
+		require.NoError(server.AppRequest(req.Context(), ids.EmptyNodeID, 0, time.Now().Add(time.Hour), request))
+		_, err = w.Write(<-responseChan)
+		require.NoError(err)
+	}))
+	defer httpServer.Close()
+
+	client, err := NewClient(&ClientConfig{
+		Transport:    NewHTTPTransport(httpServer.URL, httpServer.Client(), ids.EmptyNodeID),
+		Metrics:      &mockMetrics{},
+		Log:          logging.NoLog{},
+		BranchFactor: merkledb.BranchFactor16,
+	})
+	require.NoError(err)
+
+	proof, err := client.GetRangeProof(context.Background(), &pb.SyncGetRangeProofRequest{
+		RootHash:   serverRoot[:],
+		KeyLimit:   defaultRequestKeyLimit,
+		BytesLimit: defaultRequestByteSizeLimit,
+	})
+	require.NoError(err)
+	require.NotEmpty(proof.KeyValues)
+}
+
+func TestHTTPTransportErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		expectedErr error
+	}{
+		{
+			name: "unexpected status code",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expectedErr: errUnexpectedStatusCode,
+		},
+		{
+			name: "response too large",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write(make([]byte, constants.DefaultMaxMessageSize+1))
+			},
+			expectedErr: errResponseTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			httpServer := httptest.NewServer(tt.handler)
+			defer httpServer.Close()
+
+			nodeID := ids.GenerateTestNodeID()
+			transport := NewHTTPTransport(httpServer.URL, httpServer.Client(), nodeID)
+			responder, _, err := transport.Send(context.Background(), []byte{1})
+			require.ErrorIs(err, tt.expectedErr)
+			require.Equal(nodeID, responder)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"runtime"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// defaultVerificationWorkers returns the default number of proofs that are
+// parsed and verified at once, which leaves half of the CPUs for other work.
+func defaultVerificationWorkers() int {
+	workers := runtime.NumCPU() / 2
+	if workers < 1 {
+		return 1
+	}
+	return workers
+}
+
+// verifier bounds the number of proofs that are parsed and verified at once,
+// so that bursts of responses don't starve the rest of the node of CPU.
+// It's safe for concurrent use.
+type verifier struct {
+	workers *semaphore.Weighted
+}
+
+func newVerifier(workers int) *verifier {
+	return &verifier{
+		workers: semaphore.NewWeighted(int64(workers)),
+	}
+}
+
+// verify blocks until a worker is available or [ctx] is canceled, then
+// returns the result of [verifyFn].
+func verify[T any](
+	ctx context.Context,
+	v *verifier,
+	verifyFn func(context.Context) (*T, error),
+) (*T, error) {
+	if err := v.workers.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	defer v.workers.Release(1)
+
+	return verifyFn(ctx)
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifierBoundsWorkers(t *testing.T) {
+	require := require.New(t)
+
+	const (
+		workers = 2
+		calls   = 10
+	)
+	v := newVerifier(workers)
+
+	var (
+		active    atomic.Int64
+		maxActive atomic.Int64
+		wg        sync.WaitGroup
+	)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := verify(context.Background(), v, func(context.Context) (*struct{}, error) {
+				n := active.Add(1)
+				for {
+					m := maxActive.Load()
+					if n <= m || maxActive.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				active.Add(-1)
+				return &struct{}{}, nil
+			})
+			require.NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	require.LessOrEqual(maxActive.Load(), int64(workers))
+}
+
+func TestVerifierContextCanceled(t *testing.T) {
+	require := require.New(t)
+
+	v := newVerifier(1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = verify(context.Background(), v, func(context.Context) (*struct{}, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := verify(ctx, v, func(context.Context) (*struct{}, error) {
+		require.FailNow("shouldn't be called")
+		return nil, nil
+	})
+	require.ErrorIs(err, context.Canceled)
+	close(release)
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// If the smoothed latency of fetching proofs exceeds the baseline latency
+	// by more than this factor, peers are assumed to be overloaded, so the
+	// work limit is decreased.
+	latencyTolerance = 2
+	// If more than this many proofs are being committed, or waiting to be
+	// committed, the local database can't keep up with the proofs being
+	// fetched, so the work limit is decreased.
+	maxCommitBacklog = 2
+)
+
+// workLimiter adjusts the number of work items that are processed at once.
+//
+// The limit is increased by 1 each time a proof is fetched while peers
+// respond quickly and the local database keeps up with committing proofs.
+// Otherwise, it's decreased by a quarter. The limit is always in [1, max].
+// It's safe for concurrent use.
+type workLimiter struct {
+	lock sync.Mutex
+	max  int
+	// The current limit on the number of work items processed at once.
+	limit int
+	// Exponentially weighted moving average of the latency of fetching a
+	// proof.
+	latency time.Duration
+	// The lowest recent latency of fetching a proof. Increases slowly, so
+	// that the limiter adapts if peers become permanently slower.
+	baselineLatency time.Duration
+	// The number of proofs being committed or waiting to be committed.
+	committing int
+}
+
+// Returns a limiter whose limit starts at [max].
+func newWorkLimiter(max int) *workLimiter {
+	return &workLimiter{
+		max:   max,
+		limit: max,
+	}
+}
+
+// Limit returns the maximum number of work items to process at once.
+func (l *workLimiter) Limit() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.limit
+}
+
+// FetchCompleted records that fetching a proof took [latency], and adjusts
+// the limit.
+func (l *workLimiter) FetchCompleted(latency time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.baselineLatency == 0 || latency < l.baselineLatency {
+		l.baselineLatency = latency
+	} else {
+		l.baselineLatency += l.baselineLatency / 100
+	}
+	if l.latency == 0 {
+		l.latency = latency
+	} else {
+		l.latency = (7*l.latency + latency) / 8
+	}
+
+	if l.committing > maxCommitBacklog || l.latency > latencyTolerance*l.baselineLatency {
+		l.limit -= (l.limit + 3) / 4
+		if l.limit < 1 {
+			l.limit = 1
+		}
+		return
+	}
+	if l.limit < l.max {
+		l.limit++
+	}
+}
+
+// CommitStarted records that a proof is being committed.
+func (l *workLimiter) CommitStarted() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.committing++
+}
+
+// CommitFinished records that a proof is done being committed.
+func (l *workLimiter) CommitFinished() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.committing--
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WorkLimiter_LatencyIncrease(t *testing.T) {
+	require := require.New(t)
+	limiter := newWorkLimiter(8)
+	require.Equal(8, limiter.Limit())
+
+	// Steady latency doesn't decrease the limit.
+	for i := 0; i < 10; i++ {
+		limiter.FetchCompleted(10 * time.Millisecond)
+	}
+	require.Equal(8, limiter.Limit())
+
+	// The limit decreases once the smoothed latency exceeds the tolerance.
+	for limiter.Limit() == 8 {
+		limiter.FetchCompleted(time.Second)
+	}
+	require.Equal(6, limiter.Limit())
+
+	// Sustained high latency decreases the limit to 1.
+	for i := 0; i < 10; i++ {
+		limiter.FetchCompleted(time.Second)
+	}
+	require.Equal(1, limiter.Limit())
+
+	// The limit increases back to the maximum once latency recovers.
+	for i := 0; i < 100; i++ {
+		limiter.FetchCompleted(10 * time.Millisecond)
+	}
+	require.Equal(8, limiter.Limit())
+}
+
+func Test_WorkLimiter_CommitBacklog(t *testing.T) {
+	require := require.New(t)
+	limiter := newWorkLimiter(4)
+
+	for i := 0; i < maxCommitBacklog+1; i++ {
+		limiter.CommitStarted()
+	}
+	limiter.FetchCompleted(10 * time.Millisecond)
+	require.Equal(3, limiter.Limit())
+
+	limiter.FetchCompleted(10 * time.Millisecond)
+	require.Equal(2, limiter.Limit())
+
+	// Once the backlog clears, the limit increases.
+	limiter.CommitFinished()
+	limiter.FetchCompleted(10 * time.Millisecond)
+	require.Equal(3, limiter.Limit())
+}
@@ -6,6 +6,7 @@ package sync
 import (
 	"bytes"
 
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/heap"
 	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/utils/maybe"
@@ -155,6 +156,32 @@ func (wh *workHeap) remove(item *workItem) {
 	wh.sortedItems.Delete(item)
 }
 
+// Items returns the items in the heap in order of increasing range start.
+func (wh *workHeap) Items() []*workItem {
+	items := make([]*workItem, 0, wh.Len())
+	wh.sortedItems.Ascend(func(item *workItem) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// Covers returns true if a single item in the heap, with local root [rootID],
+// contains the range [start, end].
+func (wh *workHeap) Covers(start, end maybe.Maybe[[]byte], rootID ids.ID) bool {
+	covers := false
+	// Find the item with the greatest start which is at most [start].
+	// Note that the iterator function will run at most once, since it always returns false.
+	wh.sortedItems.DescendLessOrEqual(
+		&workItem{start: start},
+		func(item *workItem) bool {
+			covers = item.localRootID == rootID &&
+				(item.end.IsNothing() || (end.HasValue() && bytes.Compare(item.end.Value(), end.Value()) >= 0))
+			return false
+		})
+	return covers
+}
+
 func (wh *workHeap) Len() int {
 	return wh.innerHeap.Len()
 }